@@ -0,0 +1,377 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flare-tlc/client/config"
+	"flare-tlc/logger"
+	"flare-tlc/utils/chain"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	shutdownTimeout = 5 * time.Second
+	defaultTxsLimit = 1000
+)
+
+// Runtime inspection and control of the finalizer client
+type Finalizer interface {
+	SigningPolicies() []SigningPolicyInfo
+	PendingItems() []QueueItemInfo
+	Submitters() []SubmitterInfo
+	LastProcessedVotingRound() uint32
+
+	Pause()
+	Resume()
+	Paused() bool
+
+	// Fetch signing policies missing in the storage from the indexer
+	RefetchSigningPolicies() (int, error)
+
+	// Process the submitSignatures txs again from the timestamp on, e.g. after the
+	// indexer re-indexed a range
+	RescanSubmissions(from time.Time)
+
+	// Queue finalizations of the voting round and protocol again, the relay tx is
+	// sent immediately, regardless of the finalizer selection
+	Resend(votingRoundId uint32, protocolId byte) (int, error)
+
+	// Last finalized voting rounds of the protocols queried from the Relay contract,
+	// of the configured protocols if protocolIds is empty
+	Finalizations(protocolIds []byte) ([]FinalizationInfo, error)
+}
+
+// Reward epoch lifecycle states of the epoch client
+type EpochClient interface {
+	RewardEpochs() []RewardEpochInfo
+}
+
+// Audit log of the txs sent by the clients
+type TxAudit interface {
+	// Txs sent in the time range [from, to), of the operation if not empty, at most
+	// limit txs
+	Transactions(from, to time.Time, operation string, limit int) ([]TxInfo, error)
+}
+
+type RewardEpochInfo struct {
+	RewardEpochId int64     `json:"reward_epoch_id"`
+	Identity      string    `json:"identity,omitempty"` // voter identity address
+	State         string    `json:"state"`
+	Pending       string    `json:"pending,omitempty"` // target state of the running action
+	LastError     string    `json:"last_error,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type SigningPolicyInfo struct {
+	RewardEpochId      int64  `json:"reward_epoch_id"`
+	StartVotingRoundId uint32 `json:"start_voting_round_id"`
+	Threshold          uint16 `json:"threshold"`
+	Voters             int    `json:"voters"`
+	Seed               string `json:"seed"`
+}
+
+type QueueItemInfo struct {
+	VotingRoundId uint32     `json:"voting_round_id"`
+	ProtocolId    byte       `json:"protocol_id"`
+	MessageHash   string     `json:"message_hash"`
+	ScheduledAt   *time.Time `json:"scheduled_at,omitempty"` // set for items waiting for the grace period end
+	Secondary     bool       `json:"secondary,omitempty"`    // message of a secure random protocol that is not secure random
+}
+
+type FinalizationInfo struct {
+	ProtocolId byte `json:"protocol_id"`
+	// false if no recent voting round of the protocol is finalized
+	Found         bool       `json:"found"`
+	VotingRoundId uint32     `json:"voting_round_id,omitempty"`
+	MerkleRoot    string     `json:"merkle_root,omitempty"`
+	FinalizedAt   *time.Time `json:"finalized_at,omitempty"` // block timestamp of the relay tx, omitted if not indexed
+}
+
+type TxInfo struct {
+	Operation    string    `json:"operation"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	TxHash       string    `json:"tx_hash"` // the mined replacement tx if the tx was replaced
+	CalldataHash string    `json:"calldata_hash"`
+	Nonce        uint64    `json:"nonce"`
+	GasLimit     uint64    `json:"gas_limit"`
+	GasPrice     string    `json:"gas_price"`
+	GasUsed      uint64    `json:"gas_used,omitempty"`
+	Status       string    `json:"status"`
+	BlockNumber  uint64    `json:"block_number,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	SentAt       time.Time `json:"sent_at"`
+}
+
+type SubmitterInfo struct {
+	Address           string         `json:"address"`
+	InvalidSignatures map[string]int `json:"invalid_signatures"` // by reason
+	BannedUntil       *time.Time     `json:"banned_until,omitempty"`
+}
+
+type finalizerStatus struct {
+	Paused                   bool   `json:"paused"`
+	LastProcessedVotingRound uint32 `json:"last_processed_voting_round"`
+	PendingItems             int    `json:"pending_items"`
+}
+
+type statusResponse struct {
+	Finalizer *finalizerStatus `json:"finalizer,omitempty"`
+	// most recent reward epoch of the epoch client
+	RewardEpoch *RewardEpochInfo `json:"reward_epoch,omitempty"`
+	// another instance holds the leader lease and sends the txs
+	Standby bool `json:"standby,omitempty"`
+}
+
+type countResponse struct {
+	Count int `json:"count"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Local admin API, should only be exposed on a trusted interface
+type Server struct {
+	srv         *http.Server
+	finalizer   Finalizer
+	epochClient EpochClient
+	txAudit     TxAudit
+}
+
+// Returns nil if the admin API is disabled. Finalizer and epochClient may be nil if
+// the clients are not enabled, txAudit if the tx audit log is disabled.
+func NewServer(cfg *config.AdminConfig, finalizer Finalizer, epochClient EpochClient, txAudit TxAudit) *Server {
+	if len(cfg.Address) == 0 {
+		return nil
+	}
+	s := &Server{finalizer: finalizer, epochClient: epochClient, txAudit: txAudit}
+	s.srv = &http.Server{
+		Addr:              cfg.Address,
+		Handler:           s.router(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+func (s *Server) router() *mux.Router {
+	r := mux.NewRouter()
+	r.Path("/status").Methods(http.MethodGet).HandlerFunc(s.statusHandler)
+	r.Path("/spend").Methods(http.MethodGet).HandlerFunc(s.spendHandler)
+	r.Path("/epochs").Methods(http.MethodGet).HandlerFunc(s.epochsHandler)
+	r.Path("/txs").Methods(http.MethodGet).HandlerFunc(s.txsHandler)
+
+	f := r.PathPrefix("/finalizer").Subrouter()
+	f.Use(s.requireFinalizer)
+	f.Path("/signing-policies").Methods(http.MethodGet).HandlerFunc(s.signingPoliciesHandler)
+	f.Path("/signing-policies/refetch").Methods(http.MethodPost).HandlerFunc(s.refetchHandler)
+	f.Path("/submissions/rescan").Methods(http.MethodPost).HandlerFunc(s.rescanHandler)
+	f.Path("/queue").Methods(http.MethodGet).HandlerFunc(s.queueHandler)
+	f.Path("/submitters").Methods(http.MethodGet).HandlerFunc(s.submittersHandler)
+	f.Path("/finalizations").Methods(http.MethodGet).HandlerFunc(s.finalizationsHandler)
+	f.Path("/pause").Methods(http.MethodPost).HandlerFunc(s.pauseHandler)
+	f.Path("/resume").Methods(http.MethodPost).HandlerFunc(s.resumeHandler)
+	f.Path("/resend").Methods(http.MethodPost).HandlerFunc(s.resendHandler)
+	return r
+}
+
+// Serves the admin API until the context is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	errChan := make(chan error, 1)
+	go func() {
+		logger.Info("Admin API listening on %s", s.srv.Addr)
+		errChan <- s.srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errChan:
+		return err
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := s.srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Warn("Error shutting down admin API: %v", err)
+		}
+		return ctx.Err()
+	}
+}
+
+func (s *Server) requireFinalizer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.finalizer == nil {
+			writeError(w, http.StatusNotFound, errors.New("finalizer client is not enabled"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) statusHandler(w http.ResponseWriter, r *http.Request) {
+	status := statusResponse{Standby: chain.Standby()}
+	if s.finalizer != nil {
+		status.Finalizer = &finalizerStatus{
+			Paused:                   s.finalizer.Paused(),
+			LastProcessedVotingRound: s.finalizer.LastProcessedVotingRound(),
+			PendingItems:             len(s.finalizer.PendingItems()),
+		}
+	}
+	if s.epochClient != nil {
+		if epochs := s.epochClient.RewardEpochs(); len(epochs) > 0 {
+			status.RewardEpoch = &epochs[0]
+		}
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// Lifecycle states of the recent reward epochs, by reward epoch id descending
+func (s *Server) epochsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.epochClient == nil {
+		writeError(w, http.StatusNotFound, errors.New("epoch client is not enabled"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.epochClient.RewardEpochs())
+}
+
+// Fees paid for the txs sent by all clients, per operation type
+func (s *Server) spendHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, chain.Spend())
+}
+
+// GET /txs?from=<unix timestamp>[&to=<unix timestamp>][&operation=<operation>][&limit=<n>]
+func (s *Server) txsHandler(w http.ResponseWriter, r *http.Request) {
+	if s.txAudit == nil {
+		writeError(w, http.StatusNotFound, errors.New("tx audit log is not enabled"))
+		return
+	}
+	query := r.URL.Query()
+	from, err := strconv.ParseInt(query.Get("from"), 10, 64)
+	if err != nil || from < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("invalid from"))
+		return
+	}
+	to := time.Now()
+	if value := query.Get("to"); len(value) > 0 {
+		timestamp, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || timestamp < from {
+			writeError(w, http.StatusBadRequest, errors.New("invalid to"))
+			return
+		}
+		to = time.Unix(timestamp, 0)
+	}
+	limit := defaultTxsLimit
+	if value := query.Get("limit"); len(value) > 0 {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+			return
+		}
+	}
+	txs, err := s.txAudit.Transactions(time.Unix(from, 0), to, query.Get("operation"), limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, txs)
+}
+
+func (s *Server) signingPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.finalizer.SigningPolicies())
+}
+
+func (s *Server) refetchHandler(w http.ResponseWriter, r *http.Request) {
+	count, err := s.finalizer.RefetchSigningPolicies()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	logger.Info("Admin API: added %d signing policies", count)
+	writeJSON(w, http.StatusOK, countResponse{Count: count})
+}
+
+// POST /finalizer/submissions/rescan?from=<unix timestamp>
+func (s *Server) rescanHandler(w http.ResponseWriter, r *http.Request) {
+	from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+	if err != nil || from < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("invalid from"))
+		return
+	}
+	s.finalizer.RescanSubmissions(time.Unix(from, 0))
+	logger.Info("Admin API: re-scanning submissions from %s", time.Unix(from, 0))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) queueHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.finalizer.PendingItems())
+}
+
+func (s *Server) submittersHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.finalizer.Submitters())
+}
+
+// GET /finalizer/finalizations[?protocol_id=<id>]
+func (s *Server) finalizationsHandler(w http.ResponseWriter, r *http.Request) {
+	var protocolIds []byte
+	if value := r.URL.Query().Get("protocol_id"); len(value) > 0 {
+		protocolId, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid protocol_id"))
+			return
+		}
+		protocolIds = []byte{byte(protocolId)}
+	}
+	finalizations, err := s.finalizer.Finalizations(protocolIds)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, finalizations)
+}
+
+func (s *Server) pauseHandler(w http.ResponseWriter, r *http.Request) {
+	s.finalizer.Pause()
+	logger.Info("Admin API: finalizer paused")
+	s.statusHandler(w, r)
+}
+
+func (s *Server) resumeHandler(w http.ResponseWriter, r *http.Request) {
+	s.finalizer.Resume()
+	logger.Info("Admin API: finalizer resumed")
+	s.statusHandler(w, r)
+}
+
+// POST /finalizer/resend?voting_round_id=<id>&protocol_id=<id>
+func (s *Server) resendHandler(w http.ResponseWriter, r *http.Request) {
+	votingRoundId, err := strconv.ParseUint(r.URL.Query().Get("voting_round_id"), 10, 32)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid voting_round_id"))
+		return
+	}
+	protocolId, err := strconv.ParseUint(r.URL.Query().Get("protocol_id"), 10, 8)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.New("invalid protocol_id"))
+		return
+	}
+	count, err := s.finalizer.Resend(uint32(votingRoundId), byte(protocolId))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	logger.Info("Admin API: resending %d finalizations for voting round %d, protocol %d", count, votingRoundId, protocolId)
+	writeJSON(w, http.StatusAccepted, countResponse{Count: count})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,185 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminFinalizerEndpoints(t *testing.T) {
+	finalizer := &testFinalizer{
+		items: []QueueItemInfo{{VotingRoundId: 10, ProtocolId: 100, MessageHash: "0x01"}},
+	}
+	s := &Server{finalizer: finalizer}
+	router := s.router()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/finalizer/pause", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.True(t, finalizer.paused)
+
+	var status statusResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	require.True(t, status.Finalizer.Paused)
+	require.Equal(t, 1, status.Finalizer.PendingItems)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/finalizer/resend?voting_round_id=10&protocol_id=100", nil))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Equal(t, []uint32{10}, finalizer.resent)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/finalizer/resend?voting_round_id=10&protocol_id=300", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/finalizer/submissions/rescan?from=1000", nil))
+	require.Equal(t, http.StatusAccepted, rec.Code)
+	require.Equal(t, time.Unix(1000, 0), finalizer.rescan)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/finalizer/finalizations?protocol_id=100", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var finalizations []FinalizationInfo
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&finalizations))
+	require.Equal(t, []FinalizationInfo{{ProtocolId: 100, Found: true, VotingRoundId: 9}}, finalizations)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/finalizer/finalizations?protocol_id=x", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/finalizer/pause", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestAdminEpochs(t *testing.T) {
+	epochClient := &testEpochClient{epochs: []RewardEpochInfo{
+		{RewardEpochId: 11, State: "registered"},
+		{RewardEpochId: 10, State: "rewards_signed"},
+	}}
+	router := (&Server{epochClient: epochClient}).router()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/epochs", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var epochs []RewardEpochInfo
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&epochs))
+	require.Len(t, epochs, 2)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	var status statusResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	require.Nil(t, status.Finalizer)
+	require.EqualValues(t, 11, status.RewardEpoch.RewardEpochId)
+	require.Equal(t, "registered", status.RewardEpoch.State)
+
+	rec = httptest.NewRecorder()
+	(&Server{}).router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/epochs", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdminFinalizerDisabled(t *testing.T) {
+	router := (&Server{}).router()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/finalizer/queue", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/spend", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestAdminTxs(t *testing.T) {
+	txAudit := &testTxAudit{txs: []TxInfo{{Operation: "relay", Status: "mined"}}}
+	router := (&Server{txAudit: txAudit}).router()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/txs?from=1000&to=2000&operation=relay", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var txs []TxInfo
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&txs))
+	require.Equal(t, txAudit.txs, txs)
+	require.Equal(t, time.Unix(1000, 0), txAudit.from)
+	require.Equal(t, time.Unix(2000, 0), txAudit.to)
+	require.Equal(t, "relay", txAudit.operation)
+	require.Equal(t, defaultTxsLimit, txAudit.limit)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/txs?from=1000&to=900", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/txs?from=1000&limit=0", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	(&Server{}).router().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/txs?from=1000", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+type testTxAudit struct {
+	txs       []TxInfo
+	from, to  time.Time
+	operation string
+	limit     int
+}
+
+func (a *testTxAudit) Transactions(from, to time.Time, operation string, limit int) ([]TxInfo, error) {
+	a.from, a.to, a.operation, a.limit = from, to, operation, limit
+	return a.txs, nil
+}
+
+type testEpochClient struct {
+	epochs []RewardEpochInfo
+}
+
+func (c *testEpochClient) RewardEpochs() []RewardEpochInfo { return c.epochs }
+
+type testFinalizer struct {
+	paused bool
+	items  []QueueItemInfo
+	resent []uint32
+	rescan time.Time
+}
+
+func (f *testFinalizer) SigningPolicies() []SigningPolicyInfo { return nil }
+
+func (f *testFinalizer) PendingItems() []QueueItemInfo { return f.items }
+
+func (f *testFinalizer) Submitters() []SubmitterInfo { return nil }
+
+func (f *testFinalizer) LastProcessedVotingRound() uint32 { return 0 }
+
+func (f *testFinalizer) Pause() { f.paused = true }
+
+func (f *testFinalizer) Resume() { f.paused = false }
+
+func (f *testFinalizer) Paused() bool { return f.paused }
+
+func (f *testFinalizer) RefetchSigningPolicies() (int, error) { return 0, nil }
+
+func (f *testFinalizer) RescanSubmissions(from time.Time) { f.rescan = from }
+
+func (f *testFinalizer) Finalizations(protocolIds []byte) ([]FinalizationInfo, error) {
+	result := make([]FinalizationInfo, len(protocolIds))
+	for i, protocolId := range protocolIds {
+		result[i] = FinalizationInfo{ProtocolId: protocolId, Found: true, VotingRoundId: 9}
+	}
+	return result, nil
+}
+
+func (f *testFinalizer) Resend(votingRoundId uint32, protocolId byte) (int, error) {
+	f.resent = append(f.resent, votingRoundId)
+	return 1, nil
+}
@@ -3,23 +3,35 @@ package config
 import (
 	"errors"
 	"flare-tlc/config"
+	"flare-tlc/database"
+	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 type ClientConfig struct {
+	// Name of the network preset, see NetworkPreset
+	Network string `toml:"network" envconfig:"NETWORK"`
+
 	DB      config.DBConfig     `toml:"db"`
 	Logger  config.LoggerConfig `toml:"logger"`
 	Chain   config.ChainConfig  `toml:"chain"`
 	Metrics MetricsConfig       `toml:"metrics"`
+	Tracing TracingConfig       `toml:"tracing"`
+	Admin   AdminConfig         `toml:"admin"`
 
 	Clients ClientsConfig `toml:"clients"`
 
 	ContractAddresses config.ContractAddresses `toml:"contract_addresses"`
+	ContractRegistry  ContractRegistryConfig   `toml:"contract_registry"`
 	Identity          IdentityConfig           `toml:"identity"`
 	Credentials       CredentialsConfig        `toml:"credentials"`
+	Identities        []EntityConfig           `toml:"identities"`
+	Secrets           SecretsConfig            `toml:"secrets"`
 
 	Protocol map[string]ProtocolConfig `toml:"protocol"`
 
@@ -29,37 +41,192 @@ type ClientConfig struct {
 
 	Finalizer FinalizerConfig `toml:"finalizer"`
 
+	FastUpdates FastUpdatesConfig `toml:"fast_updates"`
+
 	SubmitGas   GasConfig `toml:"gas_submit"`
 	RegisterGas GasConfig `toml:"gas_register"`
+	RelayGas    GasConfig `toml:"gas_relay"`
 
 	Uptime  UptimeConfig  `toml:"uptime"`
 	Rewards RewardsConfig `toml:"rewards"`
+
+	PolicySigning PolicySigningConfig `toml:"policy_signing"`
+
+	Listeners ListenersConfig `toml:"listeners"`
+
+	Retry RetryConfig `toml:"retry"`
+
+	TxTimeout TxTimeoutConfig `toml:"tx_timeout"`
+
+	TxVerification TxVerificationConfig `toml:"tx_verification"`
+
+	TxAudit TxAuditConfig `toml:"tx_audit"`
+
+	LeaderElection LeaderElectionConfig `toml:"leader_election"`
+
+	Balance BalanceConfig `toml:"balance"`
+
+	Spend SpendConfig `toml:"spend"`
+
+	Alerts AlertsConfig `toml:"alerts"`
+}
+
+// The native token balances of the sender accounts of the enabled clients are checked
+// every Interval (default 1m). Below MinBalance (in wei) an alert is logged and no new
+// transactions are sent from the account until it is funded again.
+type BalanceConfig struct {
+	Enabled    bool          `toml:"enabled"`
+	Interval   time.Duration `toml:"interval"`
+	MinBalance *big.Int      `toml:"min_balance"`
+}
+
+// Fees paid for the txs sent by the client are accounted per operation type and UTC
+// day. If the fees of the current day exceed DailyBudget (in wei), non-critical txs,
+// e.g. backup finalizations, are not sent until the next day. Empty or 0 disables
+// the budget.
+type SpendConfig struct {
+	DailyBudget *big.Int `toml:"daily_budget"`
+}
+
+// Alerts of critical conditions are sent to every configured notifier: a generic JSON
+// webhook, a Slack incoming webhook and PagerDuty (Events API v2). Alerts are disabled
+// without notifiers.
+type AlertsConfig struct {
+	WebhookURL          string `toml:"webhook_url" envconfig:"ALERTS_WEBHOOK_URL"`
+	SlackWebhookURL     string `toml:"slack_webhook_url" envconfig:"ALERTS_SLACK_WEBHOOK_URL"`
+	PagerDutyRoutingKey string `toml:"pagerduty_routing_key" envconfig:"ALERTS_PAGERDUTY_ROUTING_KEY"`
+
+	// An alert that is still firing is sent again after RepeatInterval (default 1h)
+	RepeatInterval time.Duration `toml:"repeat_interval"`
+	// Timeout of a notification request (default 10s)
+	Timeout time.Duration `toml:"timeout"`
+
+	// Number of consecutive failed transactions that fire the tx_failures alert (default 3)
+	TxFailures int `toml:"tx_failures"`
+	// The registration_window_closing alert fires if the voter is not registered
+	// RegistrationTimeout after the vote power block of the next reward epoch was
+	// selected (default 15m, half of the minimum registration duration on Flare)
+	RegistrationTimeout time.Duration `toml:"registration_timeout"`
+
+	// Message templates (text/template) by alert kind, replace the defaults
+	Templates map[string]string `toml:"templates"`
+}
+
+func (c *AlertsConfig) Enabled() bool {
+	return len(c.WebhookURL) > 0 || len(c.SlackWebhookURL) > 0 || len(c.PagerDutyRoutingKey) > 0
+}
+
+type AdminConfig struct {
+	// Address of the admin API, e.g. localhost:2113. Empty value disables the API.
+	Address string `toml:"address" envconfig:"ADMIN_ADDRESS"`
 }
 
 type MetricsConfig struct {
 	PrometheusAddress string `toml:"prometheus_address" envconfig:"PROMETHEUS_ADDRESS"`
 }
 
+// Spans of the finalizer are exported with OTLP over HTTP, e.g. to Jaeger or Tempo
+type TracingConfig struct {
+	// OTLP HTTP endpoint of the collector, e.g. localhost:4318. Empty value disables tracing.
+	Endpoint string `toml:"endpoint" envconfig:"TRACING_ENDPOINT"`
+	// Send the spans over HTTP instead of HTTPS
+	Insecure bool `toml:"insecure"`
+	// Fraction of the voting rounds traced, 0 traces all voting rounds
+	SampleRatio float64 `toml:"sample_ratio"`
+	// Name of the service in the exported spans, default flare-tlc
+	ServiceName string `toml:"service_name"`
+}
+
+// Contract addresses not set in contract_addresses are resolved from the
+// FlareContractRegistry
+type ContractRegistryConfig struct {
+	// Address of the FlareContractRegistry, zero address disables the discovery
+	Address common.Address `toml:"address" envconfig:"CONTRACT_REGISTRY_ADDRESS"`
+	// How often the addresses are resolved again, the clients are restarted if an
+	// address changed. 0 resolves the addresses only on startup.
+	RefreshInterval time.Duration `toml:"refresh_interval"`
+}
+
+func (c *ContractRegistryConfig) Enabled() bool {
+	return c.Address != (common.Address{})
+}
+
 type IdentityConfig struct {
 	Address common.Address `toml:"address"`
 }
 
+// Additional voter identity run by the epoch client next to the identity above. Only
+// the signing policy, system client sender and sortition keys of the credentials are
+// used, the identity registers, signs the signing policies, uptime votes and rewards
+// with its own keys.
+type EntityConfig struct {
+	Address     common.Address    `toml:"address"`
+	Credentials CredentialsConfig `toml:"credentials"`
+}
+
 type CredentialsConfig struct {
 	// Sign all data
-	SigningPolicyPrivateKeyFile string `toml:"signing_policy_private_key_file"`
+	SigningPolicyPrivateKeyFile string `toml:"signing_policy_private_key_file" envconfig:"SIGNING_POLICY_PRIVATE_KEY_FILE"`
 	SigningPolicyPrivateKey     string `toml:"-" envconfig:"SIGNING_POLICY_PRIVATE_KEY"`
 
 	// Send RegisterVoter and SignNewSigningPolicy transactions
-	SystemClientSenderPrivateKeyFile string `toml:"system_client_sender_private_key_file"`
+	SystemClientSenderPrivateKeyFile string `toml:"system_client_sender_private_key_file" envconfig:"SYSTEM_CLIENT_SENDER_PRIVATE_KEY_FILE"`
 	SystemClientSenderPrivateKey     string `toml:"-" envconfig:"SYSTEM_CLIENT_SENDER_PRIVATE_KEY"`
 
 	// Submit protocol data (submit1, submit2, submit3)
-	ProtocolManagerSubmitPrivateKeyFile string `toml:"protocol_manager_submit_private_key_file"`
+	ProtocolManagerSubmitPrivateKeyFile string `toml:"protocol_manager_submit_private_key_file" envconfig:"PROTOCOL_MANAGER_SUBMIT_PRIVATE_KEY_FILE"`
 	ProtocolManagerSubmitPrivateKey     string `toml:"-" envconfig:"PROTOCOL_MANAGER_SUBMIT_PRIVATE_KEY"`
 
 	// Submit protocol signatures
-	ProtocolManagerSubmitSignaturesPrivateKeyFile string `toml:"protocol_manager_submit_signatures_private_key_file"`
+	ProtocolManagerSubmitSignaturesPrivateKeyFile string `toml:"protocol_manager_submit_signatures_private_key_file" envconfig:"PROTOCOL_MANAGER_SUBMIT_SIGNATURES_PRIVATE_KEY_FILE"`
 	ProtocolManagerSubmitSignaturesPrivateKey     string `toml:"-" envconfig:"PROTOCOL_MANAGER_SUBMIT_SIGNATURES_PRIVATE_KEY"`
+
+	// Compute the sortition credentials of fast updates, hex encoded secret scalar
+	SortitionPrivateKeyFile string `toml:"sortition_private_key_file" envconfig:"SORTITION_PRIVATE_KEY_FILE"`
+	SortitionPrivateKey     string `toml:"-" envconfig:"SORTITION_PRIVATE_KEY"`
+
+	// Propose the voter addresses and register the sortition public key in the
+	// EntityManager, only used by the entity commands
+	IdentityPrivateKeyFile string `toml:"identity_private_key_file" envconfig:"IDENTITY_PRIVATE_KEY_FILE"`
+	IdentityPrivateKey     string `toml:"-" envconfig:"IDENTITY_PRIVATE_KEY"`
+
+	// Staged signing policy key, replaces the signing policy key from the reward epoch
+	// NextSigningPolicyRewardEpoch on. The address has to be registered in the
+	// EntityManager before the vote power block of that reward epoch is selected.
+	NextSigningPolicyPrivateKeyFile string `toml:"next_signing_policy_private_key_file" envconfig:"NEXT_SIGNING_POLICY_PRIVATE_KEY_FILE"`
+	NextSigningPolicyPrivateKey     string `toml:"-" envconfig:"NEXT_SIGNING_POLICY_PRIVATE_KEY"`
+	NextSigningPolicyRewardEpoch    int64  `toml:"next_signing_policy_reward_epoch"`
+
+	// Optional external signers (KMS, remote signer), used instead of the private keys above
+	SigningPolicySigner                   config.SignerConfig `toml:"signing_policy_signer"`
+	SystemClientSenderSigner              config.SignerConfig `toml:"system_client_sender_signer"`
+	ProtocolManagerSubmitSigner           config.SignerConfig `toml:"protocol_manager_submit_signer"`
+	ProtocolManagerSubmitSignaturesSigner config.SignerConfig `toml:"protocol_manager_submit_signatures_signer"`
+	IdentitySigner                        config.SignerConfig `toml:"identity_signer"`
+	NextSigningPolicySigner               config.SignerConfig `toml:"next_signing_policy_signer"`
+}
+
+// NextSigningPolicyKeyConfigured returns true if a signing policy key rotation is staged
+func (c *CredentialsConfig) NextSigningPolicyKeyConfigured() bool {
+	return len(c.NextSigningPolicyPrivateKeyFile) > 0 || len(c.NextSigningPolicyPrivateKey) > 0 ||
+		len(c.NextSigningPolicySigner.Type) > 0
+}
+
+// SystemClientSenderKeyConfigured returns true if the sender key or signer is set
+func (c *CredentialsConfig) SystemClientSenderKeyConfigured() bool {
+	return len(c.SystemClientSenderPrivateKeyFile) > 0 || len(c.SystemClientSenderPrivateKey) > 0 ||
+		len(c.SystemClientSenderSigner.Type) > 0
+}
+
+// SigningPolicyKeyConfigured returns true if the signing policy key or signer is set
+func (c *CredentialsConfig) SigningPolicyKeyConfigured() bool {
+	return len(c.SigningPolicyPrivateKeyFile) > 0 || len(c.SigningPolicyPrivateKey) > 0 ||
+		len(c.SigningPolicySigner.Type) > 0
+}
+
+// SortitionKeyConfigured returns true if the sortition key of fast updates is set
+func (c *CredentialsConfig) SortitionKeyConfigured() bool {
+	return len(strings.TrimSpace(c.SortitionPrivateKey)) > 0 || len(strings.TrimSpace(c.SortitionPrivateKeyFile)) > 0
 }
 
 var defaultSubmitConfig = SubmitConfig{
@@ -71,6 +238,7 @@ var defaultSubmitConfig = SubmitConfig{
 type SubmitConfig struct {
 	Enabled          bool          `toml:"enabled"`
 	StartOffset      time.Duration `toml:"start_offset"` // offset from the start of the epoch
+	Deadline         time.Duration `toml:"deadline"`     // offset from the start of the epoch after which txs are no longer sent, 0 for no deadline
 	TxSubmitRetries  int           `toml:"tx_submit_retries"`
 	DataFetchRetries int           `toml:"data_fetch_retries"`
 	DataFetchTimeout time.Duration `toml:"data_fetch_timeout"`
@@ -88,6 +256,7 @@ type ClientsConfig struct {
 	EnabledRewardSigning  bool `toml:"enabled_reward_signing"`
 	EnabledProtocolVoting bool `toml:"enabled_protocol_voting"`
 	EnabledFinalizer      bool `toml:"enabled_finalizer"`
+	EnabledFastUpdates    bool `toml:"enabled_fast_updates"`
 }
 
 func (c *ClientsConfig) EpochClientEnabled() bool {
@@ -102,20 +271,572 @@ type FinalizerConfig struct {
 	// default is 7 days
 	StartOffset time.Duration `toml:"start_offset"`
 
+	// Determine the start offset from the last voting round finalized on chain
+	// instead of using StartOffset, optionally limited by MaxStartOffset
+	AutoStartOffset bool          `toml:"auto_start_offset"`
+	MaxStartOffset  time.Duration `toml:"max_start_offset"`
+
 	VoterThresholdBIPS uint16 `toml:"voter_threshold_bips"`
 
+	// Threshold of the collected signature weight in BIPS of the total weight of the
+	// signing policy. Default is 0 (the threshold of the signing policy). The Relay
+	// contract does not accept less than the threshold of the signing policy, lower
+	// values use the threshold of the signing policy.
+	ThresholdBIPS uint16 `toml:"threshold_bips"`
+
+	// Threshold in BIPS of the total weight after the reward epoch of the last signing
+	// policy ended, until the next policy is initialized. Default is 6000.
+	ExpiredPolicyThresholdBIPS uint16 `toml:"expired_policy_threshold_bips"`
+
 	// Offset from the start of the voting round
 	GracePeriodEndOffset time.Duration `toml:"grace_period_end_offset"`
+
+	// Max random delay added to the grace period end before a client that was
+	// not selected sends the relay tx as a backup. Default is 0 (no delay).
+	BackupRandomDelay time.Duration `toml:"backup_random_delay"`
+
+	// Number of parallel finalization workers. Items of the same protocol are
+	// always finalized in order. Default is 1 (sequential processing).
+	QueueWorkers int `toml:"queue_workers"`
+
+	// Persist pending finalizations to the database so they are retried after a restart
+	PersistentQueue bool `toml:"persistent_queue"`
+
+	// Max number of voting rounds with collected signatures kept in memory, the
+	// oldest rounds are evicted first. Default is 0 (no limit, rounds are removed
+	// after 2 * StartOffset).
+	MaxRetainedRounds int `toml:"max_retained_rounds"`
+
+	// Persist signing policies to the database, so they are loaded on restart
+	// instead of re-scanning the logs
+	PersistentSigningPolicies bool `toml:"persistent_signing_policies"`
+
+	// Number of latest reward epochs for which signing policies are kept, older
+	// policies are removed from memory and the database. Default is 0 (policies
+	// are removed after 2 * StartOffset).
+	RetainedRewardEpochs int64 `toml:"retained_reward_epochs"`
+
+	// Only finalize voting rounds for which the client was selected as a
+	// finalization provider, never after the grace period
+	OnlyWhenSelected bool `toml:"only_when_selected"`
+
+	// Selection of the collected signatures included in the relay tx until the
+	// threshold is reached: largest_weight (default, fewest signatures),
+	// first_come (order of arrival) or smallest_calldata (lowest calldata gas)
+	SignatureSelection string `toml:"signature_selection"`
+
+	// Verify the signers of submitted signatures against the voters registered in
+	// the VoterRegistry, fetched when the signing policy of a reward epoch is initialized
+	VerifyVoterRegistry bool `toml:"verify_voter_registry"`
+
+	// Verify the signed messages before their signatures are counted: protocol and
+	// voting round of the payload item, message length and, for the configured
+	// protocols without SecureRandom, random quality score 0
+	VerifyMessages bool `toml:"verify_messages"`
+
+	SubmitterBan SubmitterBanConfig `toml:"submitter_ban"`
+
+	Submitters SubmitterFilterConfig `toml:"submitters"`
+
+	// External sources of finalization data (messages and signatures), queried in
+	// order for the last voting round of their protocols if the locally collected
+	// signatures did not reach the threshold by the end of the grace period
+	FinalizationProviders []FinalizationProviderConfig `toml:"finalization_providers"`
+
+	// Protocols finalized by the client, e.g. FTSO (100) and FDC (200). Signatures
+	// of other protocols are ignored. If empty, all protocols with submitted
+	// signatures are finalized with the settings above.
+	Protocols []FinalizerProtocolConfig `toml:"protocols"`
+
+	// Sender accounts of the relay txs of the protocols, the relay txs of the other
+	// protocols are sent from the signing policy address
+	Senders []FinalizerSenderConfig `toml:"senders"`
+}
+
+// The fast updates client checks the sortition eligibility of every new block
+// (polled every PollInterval, default 1s) and submits the deltas of the feed value
+// provider for each eligible replicate while the block is in the submission window.
+// GET <ApiEndpoint>/<blockNumber> returns {"status": "OK", "data": "0x..."}, the
+// deltas encoded as expected by FastUpdater.submitUpdates. The X-API-KEY header is
+// read from FAST_UPDATES_X_API_KEY. Default timeout is 1s.
+type FastUpdatesConfig struct {
+	ApiEndpoint  string        `toml:"api_endpoint"`
+	Timeout      time.Duration `toml:"timeout"`
+	PollInterval time.Duration `toml:"poll_interval"`
+}
+
+// Submitters of submitSignatures txs with MaxInvalidSignatures invalid signatures
+// (undecodable payloads, signers not in the signing policy, spoofed signatures) are
+// banned, their submissions are not processed for Period. 0 disables banning.
+// Duplicate signatures are counted but not toward the ban, a tx processed again
+// after a listener retry, rescan or reorg has duplicate signatures.
+type SubmitterBanConfig struct {
+	MaxInvalidSignatures int           `toml:"max_invalid_signatures"`
+	Period               time.Duration `toml:"period"`
+}
+
+// Submissions of senders not in Tracked (if set) or in Ignored are dropped before
+// their signatures are verified. At most one of the lists may be set.
+type SubmitterFilterConfig struct {
+	Tracked []common.Address `toml:"tracked"`
+	Ignored []common.Address `toml:"ignored"`
+}
+
+// The X-API-KEY header is read from FINALIZATION_PROVIDER_X_API_KEY_<n>, where n is
+// the position of the provider in the list, starting with 1. Default timeout is 10s.
+type FinalizationProviderConfig struct {
+	ApiEndpoint string        `toml:"api_endpoint"`
+	Protocols   []uint8       `toml:"protocols"`
+	Timeout     time.Duration `toml:"timeout"`
+}
+
+// Relay txs of the protocols are sent from the account of the key, so that their gas
+// and nonces are separate from the relay txs of the other protocols. The finalizer is
+// still selected by the signing policy address. The key is read from PrivateKeyFile,
+// FINALIZER_SENDER_PRIVATE_KEY_<n>, where n is the position of the sender in the list,
+// starting with 1, or the Signer.
+type FinalizerSenderConfig struct {
+	Protocols      []uint8             `toml:"protocols"`
+	PrivateKeyFile string              `toml:"private_key_file"`
+	Signer         config.SignerConfig `toml:"signer"`
+}
+
+// Zero values use the finalizer settings. Messages of a SecureRandom protocol (the
+// random number protocol of the Relay contract) with random quality score 0 are
+// relayed only after the grace period, if no secure message of the voting round
+// was relayed. Secure and not secure messages of a voting round have different
+// hashes, their signatures are collected separately.
+type FinalizerProtocolConfig struct {
+	Id                         uint8         `toml:"id"`
+	VoterThresholdBIPS         uint16        `toml:"voter_threshold_bips"`
+	ThresholdBIPS              uint16        `toml:"threshold_bips"`
+	ExpiredPolicyThresholdBIPS uint16        `toml:"expired_policy_threshold_bips"`
+	GracePeriodEndOffset       time.Duration `toml:"grace_period_end_offset"`
+	SecureRandom               bool          `toml:"secure_random"`
+}
+
+const (
+	SignatureSelectionFirstCome        = "first_come"
+	SignatureSelectionLargestWeight    = "largest_weight"
+	SignatureSelectionSmallestCalldata = "smallest_calldata"
+)
+
+const (
+	ListenerSourceIndexer = "indexer"
+	ListenerSourceRPC     = "rpc"
+)
+
+type ListenersConfig struct {
+	// Source of the logs and transactions of the listeners: indexer (default, the
+	// indexer db) or rpc (eth_getLogs and eth_getBlockByNumber on chain.eth_rpc_url).
+	// The rpc source does not use a database.
+	Source string          `toml:"source"`
+	RPC    RPCSourceConfig `toml:"rpc"`
+
+	// Only for the indexer source, switches to the rpc source while the indexer lags
+	IndexerFallback IndexerFallbackConfig `toml:"indexer_fallback"`
+
+	VotePowerBlockSelected   ListenerConfig `toml:"vote_power_block_selected"`
+	SigningPolicyInitialized ListenerConfig `toml:"signing_policy_initialized"`
+	SignUptimeVoteEnabled    ListenerConfig `toml:"sign_uptime_vote_enabled"`
+	UptimeVoteSigned         ListenerConfig `toml:"uptime_vote_signed"`
+	SubmitSignatures         ListenerConfig `toml:"submit_signatures"`
+	ProtocolMessageRelayed   ListenerConfig `toml:"protocol_message_relayed"`
+
+	// Persist the timestamp of the last processed event of each epoch client listener,
+	// so that the listeners resume after it on restart instead of re-emitting old events
+	PersistentCheckpoints bool `toml:"persistent_checkpoints"`
+
+	// Limits of the indexer db queries of the listeners
+	Fetch FetchConfig `toml:"fetch"`
+
+	// Reorg detection of the finalizer listeners
+	Reorg ReorgConfig `toml:"reorg"`
+}
+
+// The blocks of the events processed by the finalizer listeners are checked against
+// the canonical chain until they are Depth blocks deep (default 32). On a reorg the
+// signing policies and submissions from the reorged block on are dropped and fetched again.
+type ReorgConfig struct {
+	Enabled bool   `toml:"enabled"`
+	Depth   uint64 `toml:"depth"`
+}
+
+// The timestamp range of a listener tick is fetched in chunks of ChunkSize, each
+// chunk in pages of PageSize rows. At most MaxRowsPerTick rows are processed per
+// tick, the listener continues with the rest on the next tick. The page queries of
+// all listeners share one limiter of MaxConcurrentQueries running queries and
+// QueriesPerSecond. Zero values disable the limits. The logs fetched by the listeners
+// are cached for CacheTTL, zero disables the cache, see database.LogCache.
+type FetchConfig struct {
+	ChunkSize            time.Duration `toml:"chunk_size"`
+	PageSize             int           `toml:"page_size"`
+	MaxRowsPerTick       int           `toml:"max_rows_per_tick"`
+	MaxConcurrentQueries int           `toml:"max_concurrent_queries"`
+	QueriesPerSecond     float64       `toml:"queries_per_second"`
+	CacheTTL             time.Duration `toml:"cache_ttl"`
+}
+
+func (c *FetchConfig) FetchOptions() database.FetchOptions {
+	return database.FetchOptions{
+		ChunkSize: c.ChunkSize,
+		PageSize:  c.PageSize,
+		MaxRows:   c.MaxRowsPerTick,
+		Limiter:   database.SharedQueryLimiter(c.MaxConcurrentQueries, c.QueriesPerSecond),
+		LogCache:  database.SharedLogCache(c.CacheTTL),
+	}
+}
+
+func (c *ListenersConfig) RPCSource() bool {
+	return c.Source == ListenerSourceRPC
+}
+
+// For the rpc source and the indexer fallback. Block ranges are fetched in chunks of ChunkBlocks blocks
+// (default 1000), only blocks with at least Confirmations confirmations are fetched.
+type RPCSourceConfig struct {
+	ChunkBlocks   uint64 `toml:"chunk_blocks"`
+	Confirmations uint64 `toml:"confirmations"`
+}
+
+// The latest indexed block is compared with the chain head every CheckInterval
+// (default 10s). While it is more than MaxLag (default 1m) behind, or the indexer db
+// cannot be queried, the listeners read from the rpc node with the settings of
+// RPCSourceConfig, and switch back to the indexer db when it catches up.
+type IndexerFallbackConfig struct {
+	Enabled       bool          `toml:"enabled"`
+	MaxLag        time.Duration `toml:"max_lag"`
+	CheckInterval time.Duration `toml:"check_interval"`
+}
+
+type ListenerConfig struct {
+	// Receive events via websocket subscription (chain.eth_ws_url) instead of polling
+	// the indexer db. Falls back to db polling while the subscription is down. Only
+	// for vote_power_block_selected and signing_policy_initialized.
+	Websocket bool `toml:"websocket"`
+
+	// Polling interval of the listener, 0 uses the default of the network
+	Interval time.Duration `toml:"interval"`
+
+	// Events buffered for the consumer, so that a slow consumer does not stall the
+	// polling. Default 0, an unbuffered channel (10 for the signing policies of the
+	// finalizer). If the buffer is full, Overflow "block" (default) waits for the
+	// consumer, "drop_oldest" drops the oldest buffered event. Not for submit_signatures
+	// and protocol_message_relayed, which are processed by the listener. "drop_oldest"
+	// is rejected for the listeners whose events carry state that later events depend
+	// on, see stateListeners.
+	BufferSize int    `toml:"buffer_size"`
+	Overflow   string `toml:"overflow"`
+}
+
+// Overflow policies of the listener buffers
+const (
+	ListenerOverflowBlock      = "block"
+	ListenerOverflowDropOldest = "drop_oldest"
+)
+
+// Listeners whose dropped events would be lost for good: the signing policies are
+// needed to verify the signatures of their reward epoch, and the vote power block
+// selection and the uptime vote enablement start actions of the epoch client.
+var stateListeners = map[string]bool{
+	ListenerVotePowerBlockSelected:   true,
+	ListenerSigningPolicyInitialized: true,
+	ListenerSignUptimeVoteEnabled:    true,
+}
+
+// Buffer of the event channel of a listener, see ListenerConfig
+type ListenerBuffer struct {
+	Size       int
+	DropOldest bool
+}
+
+// Names of the listeners, the keys of their settings in [listeners]
+const (
+	ListenerVotePowerBlockSelected   = "vote_power_block_selected"
+	ListenerSigningPolicyInitialized = "signing_policy_initialized"
+	ListenerSignUptimeVoteEnabled    = "sign_uptime_vote_enabled"
+	ListenerUptimeVoteSigned         = "uptime_vote_signed"
+	ListenerSubmitSignatures         = "submit_signatures"
+	ListenerProtocolMessageRelayed   = "protocol_message_relayed"
+)
+
+const (
+	flareChainID    = 14
+	songbirdChainID = 19
+	costonChainID   = 16
+	coston2ChainID  = 114
+)
+
+func (c *ListenersConfig) listeners() map[string]*ListenerConfig {
+	return map[string]*ListenerConfig{
+		ListenerVotePowerBlockSelected:   &c.VotePowerBlockSelected,
+		ListenerSigningPolicyInitialized: &c.SigningPolicyInitialized,
+		ListenerSignUptimeVoteEnabled:    &c.SignUptimeVoteEnabled,
+		ListenerUptimeVoteSigned:         &c.UptimeVoteSigned,
+		ListenerSubmitSignatures:         &c.SubmitSignatures,
+		ListenerProtocolMessageRelayed:   &c.ProtocolMessageRelayed,
+	}
+}
+
+// Intervals returns the polling interval of each listener, the configured one or the
+// default of the network with the chain id. On Flare and Songbird the submitted
+// signatures are polled every 500ms, the VotePowerBlockSelected and
+// SigningPolicyInitialized events, emitted once per reward epoch, every 30s.
+func (c *ListenersConfig) Intervals(chainID int) map[string]time.Duration {
+	intervals := map[string]time.Duration{
+		ListenerVotePowerBlockSelected:   5 * time.Second,
+		ListenerSigningPolicyInitialized: 5 * time.Second,
+		ListenerSignUptimeVoteEnabled:    5 * time.Second,
+		ListenerUptimeVoteSigned:         5 * time.Second,
+		ListenerSubmitSignatures:         2 * time.Second,
+		ListenerProtocolMessageRelayed:   5 * time.Second,
+	}
+	if preset, ok := NetworkPresetByChainID(chainID); ok {
+		for listener, interval := range preset.ListenerIntervals {
+			intervals[listener] = interval
+		}
+	}
+	for listener, cfg := range c.listeners() {
+		if cfg.Interval > 0 {
+			intervals[listener] = cfg.Interval
+		}
+	}
+	return intervals
+}
+
+// Buffers returns the buffer of the event channel of each listener
+func (c *ListenersConfig) Buffers() map[string]ListenerBuffer {
+	buffers := make(map[string]ListenerBuffer)
+	for listener, cfg := range c.listeners() {
+		buffers[listener] = ListenerBuffer{
+			Size:       cfg.BufferSize,
+			DropOldest: cfg.Overflow == ListenerOverflowDropOldest,
+		}
+	}
+	return buffers
 }
 
 type GasConfig struct {
 	GasPriceMultiplier float32  `toml:"gas_price_multiplier"`
 	GasPriceFixed      *big.Int `toml:"gas_price_fixed"`
 	GasLimit           int      `toml:"gas_limit"`
+
+	// Without a fixed GasLimit, the gas limit is the estimated gas * GasLimitMultiplier (default 1),
+	// limited by the cap of the operation in GasLimitCaps, or GasLimitCap if not set. Txs with
+	// the estimated gas above the cap are not sent.
+	GasLimitMultiplier float32           `toml:"gas_limit_multiplier"`
+	GasLimitCap        uint64            `toml:"gas_limit_cap"`
+	GasLimitCaps       map[string]uint64 `toml:"gas_limit_caps"`
+
+	// If a tx is not included after BumpAfterBlocks blocks, it is replaced by a tx
+	// with the gas price increased by GasPriceBumpPercent (at least 10), up to GasPriceCap.
+	// Escalation is disabled if BumpAfterBlocks is 0.
+	BumpAfterBlocks     uint64   `toml:"bump_after_blocks"`
+	GasPriceBumpPercent uint64   `toml:"gas_price_bump_percent"`
+	GasPriceCap         *big.Int `toml:"gas_price_cap"`
+
+	// Transaction type, 0 for legacy (default) or 2 for EIP-1559 dynamic fee transactions.
+	// For dynamic fee transactions the fee cap is BaseFeeMultiplier (default 2) * base fee
+	// of the latest block + tip, limited by MaxFeePerGas if nonzero. The node's suggested
+	// tip is used if MaxPriorityFeePerGas is 0.
+	TxType               uint8    `toml:"tx_type"`
+	MaxFeePerGas         *big.Int `toml:"max_fee_per_gas"`
+	MaxPriorityFeePerGas *big.Int `toml:"max_priority_fee_per_gas"`
+	BaseFeeMultiplier    float32  `toml:"base_fee_multiplier"`
+
+	// Gas price strategy: static (legacy txs with GasPriceFixed or the node's suggested
+	// gas price * GasPriceMultiplier), oracle (legacy txs with the GasOraclePercentile
+	// percentile of the gas prices paid in the last GasOracleBlocks blocks *
+	// GasPriceMultiplier) or eip1559 (dynamic fee txs as above). If not set, the default
+	// strategy of the network is used unless TxType or GasPriceFixed is set, see
+	// NetworkPreset.GasStrategy.
+	GasStrategy         string `toml:"gas_strategy"`
+	GasOracleBlocks     int    `toml:"gas_oracle_blocks"`
+	GasOraclePercentile int    `toml:"gas_oracle_percentile"`
+}
+
+const (
+	GasStrategyStatic  = "static"
+	GasStrategyOracle  = "oracle"
+	GasStrategyEIP1559 = "eip1559"
+
+	defaultGasOracleBlocks     = 20
+	defaultGasOraclePercentile = 60
+)
+
+// Strategy returns the gas price strategy, static or eip1559 by TxType if not set
+func (c *GasConfig) Strategy() string {
+	if len(c.GasStrategy) > 0 {
+		return c.GasStrategy
+	}
+	if c.TxType == types.DynamicFeeTxType {
+		return GasStrategyEIP1559
+	}
+	return GasStrategyStatic
+}
+
+// OracleSettings returns the number of sampled blocks and the percentile of the oracle strategy
+func (c *GasConfig) OracleSettings() (int, int) {
+	blocks, percentile := c.GasOracleBlocks, c.GasOraclePercentile
+	if blocks == 0 {
+		blocks = defaultGasOracleBlocks
+	}
+	if percentile == 0 {
+		percentile = defaultGasOraclePercentile
+	}
+	return blocks, percentile
+}
+
+// Snapshot returns a copy of the gas config, the copy is not changed by config reloads
+func (c *GasConfig) Snapshot() *GasConfig {
+	liveConfigMutex.RLock()
+	defer liveConfigMutex.RUnlock()
+
+	snapshot := *c
+	return &snapshot
+}
+
+// GasLimitCapFor returns the gas limit cap of the operation, 0 if there is no cap.
+func (c *GasConfig) GasLimitCapFor(operation string) uint64 {
+	if limitCap, ok := c.GasLimitCaps[operation]; ok {
+		return limitCap
+	}
+	return c.GasLimitCap
+}
+
+// Operations executed with a retry policy, used as keys of RetryConfig.Operations.
+// Transaction operations are also keys of GasConfig.GasLimitCaps.
+const (
+	RetryOpRegisterVoter        = "register_voter"
+	RetryOpSignNewSigningPolicy = "sign_new_signing_policy"
+	RetryOpSignUptimeVote       = "sign_uptime_vote"
+	RetryOpSignRewards          = "sign_rewards"
+	RetryOpRelay                = "relay"
+	RetryOpSubmit               = "submit"
+	RetryOpSubmitUpdates        = "submit_updates"
+	RetryOpEntityRegistration   = "entity_registration"
+	RetryOpSelfCheck            = "self_check"
+	RetryOpFetchRewardsHash     = "fetch_rewards_hash"
+	RetryOpFetchUptimeVoteHash  = "fetch_uptime_vote_hash"
+)
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:   4,
+	InitialDelay: 5 * time.Second,
+	MaxDelay:     time.Minute,
+	Multiplier:   1,
+}
+
+// Retry policy of an operation. The delay before retry n (starting with 0) is
+// InitialDelay * Multiplier^n, limited by MaxDelay and randomized by +-Jitter
+// (fraction of the delay). Errors containing one of AlreadyDoneErrors are treated
+// as success, errors containing one of FatalErrors are not retried.
+type RetryPolicy struct {
+	MaxRetries        int           `toml:"max_retries"`
+	InitialDelay      time.Duration `toml:"initial_delay"`
+	MaxDelay          time.Duration `toml:"max_delay"`
+	Multiplier        float64       `toml:"multiplier"`
+	Jitter            float64       `toml:"jitter"`
+	AlreadyDoneErrors []string      `toml:"already_done_errors"`
+	FatalErrors       []string      `toml:"fatal_errors"`
+}
+
+type RetryConfig struct {
+	// Default policy for all operations
+	RetryPolicy
+
+	// Per operation overrides, unset values are taken from the default policy
+	Operations map[string]RetryPolicy `toml:"operations"`
+}
+
+// Policy returns the retry policy of the operation.
+func (c *RetryConfig) Policy(operation string) RetryPolicy {
+	if c == nil {
+		return DefaultRetryPolicy
+	}
+	liveConfigMutex.RLock()
+	defer liveConfigMutex.RUnlock()
+
+	policy := c.RetryPolicy.withDefaults(DefaultRetryPolicy)
+	if override, ok := c.Operations[operation]; ok {
+		policy = override.withDefaults(policy)
+	}
+	return policy
+}
+
+func (p RetryPolicy) withDefaults(defaults RetryPolicy) RetryPolicy {
+	if p.MaxRetries == 0 {
+		p.MaxRetries = defaults.MaxRetries
+	}
+	if p.InitialDelay == 0 {
+		p.InitialDelay = defaults.InitialDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = defaults.MaxDelay
+	}
+	if p.Multiplier == 0 {
+		p.Multiplier = defaults.Multiplier
+	}
+	if p.Jitter == 0 {
+		p.Jitter = defaults.Jitter
+	}
+	p.AlreadyDoneErrors = append(append([]string{}, defaults.AlreadyDoneErrors...), p.AlreadyDoneErrors...)
+	p.FatalErrors = append(append([]string{}, defaults.FatalErrors...), p.FatalErrors...)
+	return p
+}
+
+// Time to wait for a tx to be mined, including the replacement txs of the gas price
+// escalation. Operations without an override use Default, if Default is not set the
+// timeout of the client (60s) is used.
+type TxTimeoutConfig struct {
+	Default time.Duration `toml:"default"`
+
+	// Per operation overrides, the keys are the transaction retry operations
+	Operations map[string]time.Duration `toml:"operations"`
+}
+
+// Timeout returns the configured timeout of the operation, 0 if not configured.
+func (c *TxTimeoutConfig) Timeout(operation string) time.Duration {
+	if c == nil {
+		return 0
+	}
+	if timeout, ok := c.Operations[operation]; ok && timeout > 0 {
+		return timeout
+	}
+	return c.Default
+}
+
+// With ReceiptLogs the receipt of a mined tx is checked for the event the tx is expected
+// to emit, e.g. VoterRegistered of registerVoter. A tx without the event is treated as
+// failed and retried according to the retry policy of the operation.
+type TxVerificationConfig struct {
+	ReceiptLogs bool `toml:"receipt_logs"`
+}
+
+// Audit log of the sent txs in the database, entries older than Retention are deleted
+// on startup, 0 keeps all entries
+type TxAuditConfig struct {
+	Enabled   bool          `toml:"enabled"`
+	Retention time.Duration `toml:"retention"`
+}
+
+// Leader election of redundant instances of the same identity with a lease in the
+// database. Only the holder of the lease sends transactions, the other instances run
+// on standby and take over once the lease expires. The lease is renewed every
+// RenewInterval and expires LeaseDuration after the last renewal. The leader stops
+// sending a tenth of LeaseDuration before the lease expires if it was not renewed.
+type LeaderElectionConfig struct {
+	Enabled bool `toml:"enabled"`
+	// name of the lease shared by the instances, default: the identity address
+	Name string `toml:"name"`
+	// unique id of the instance, default: hostname and process id
+	InstanceId    string        `toml:"instance_id" envconfig:"LEADER_ELECTION_INSTANCE_ID"`
+	LeaseDuration time.Duration `toml:"lease_duration"`
+	RenewInterval time.Duration `toml:"renew_interval"`
 }
 
 type UptimeConfig struct {
-	SigningWindow int64 `toml:"signing_window"`
+	PathPrefix    string `toml:"hash_path_prefix"`
+	SigningWindow int64  `toml:"signing_window"`
 }
 
 type RewardsConfig struct {
@@ -123,14 +844,47 @@ type RewardsConfig struct {
 	SigningWindow int64  `toml:"signing_window"`
 }
 
+// The new signing policy is signed in the window from its initialization to the expected
+// start of its reward epoch, earlier signatures may be rewarded more. If less than
+// UrgentBefore (default 30m) of the window is left, the signing tx is sent with the gas
+// price of gas_register multiplied by UrgentGasPriceMultiplier (default 2) and replaced
+// every block until it is mined. The signing_policy_deadline alert fires if the policy
+// is not signed AlertBefore (default 15m) the end of the window.
+type PolicySigningConfig struct {
+	UrgentBefore             time.Duration `toml:"urgent_before"`
+	UrgentGasPriceMultiplier float32       `toml:"urgent_gas_price_multiplier"`
+	AlertBefore              time.Duration `toml:"alert_before"`
+}
+
 func newConfig() *ClientConfig {
 	return &ClientConfig{
 		Chain: config.ChainConfig{
 			EthRPCURL: "http://localhost:9650/ext/C/rpc",
 		},
+		DB: config.DBConfig{
+			ReconnectMinBackoff: time.Second,
+			ReconnectMaxBackoff: time.Minute,
+		},
 		Finalizer: FinalizerConfig{
 			StartOffset:        7 * 24 * time.Hour,
 			VoterThresholdBIPS: 500,
+			QueueWorkers:       1,
+		},
+		Listeners: ListenersConfig{
+			Reorg: ReorgConfig{Depth: 32},
+			IndexerFallback: IndexerFallbackConfig{
+				MaxLag:        time.Minute,
+				CheckInterval: 10 * time.Second,
+			},
+		},
+		Balance: BalanceConfig{
+			Interval: time.Minute,
+		},
+		Alerts: AlertsConfig{
+			RepeatInterval:      time.Hour,
+			Timeout:             10 * time.Second,
+			TxFailures:          3,
+			RegistrationTimeout: 15 * time.Minute,
 		},
 		Submit1: defaultSubmitConfig,
 		Submit2: defaultSubmitConfig,
@@ -143,6 +897,18 @@ func newConfig() *ClientConfig {
 		Rewards: RewardsConfig{
 			SigningWindow: 2,
 		},
+		PolicySigning: PolicySigningConfig{
+			UrgentBefore:             30 * time.Minute,
+			UrgentGasPriceMultiplier: 2,
+			AlertBefore:              15 * time.Minute,
+		},
+		RelayGas: GasConfig{
+			GasPriceFixed: big.NewInt(0),
+		},
+		LeaderElection: LeaderElectionConfig{
+			LeaseDuration: 30 * time.Second,
+			RenewInterval: 10 * time.Second,
+		},
 	}
 }
 
@@ -160,10 +926,20 @@ func BuildConfig(cfgFileName string) (*ClientConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	// values from env variables override secrets, secrets override the config file
+	err = applySecrets(cfg)
+	if err != nil {
+		return nil, err
+	}
 	err = config.ReadEnv(cfg)
 	if err != nil {
 		return nil, err
 	}
+	err = applyNetworkPreset(cfg)
+	if err != nil {
+		return nil, err
+	}
+	applyGasPreset(cfg)
 	err = validateConfig(cfg)
 	if err != nil {
 		return nil, err
@@ -180,6 +956,283 @@ func validateConfig(cfg *ClientConfig) error {
 	if err != nil {
 		return err
 	}
+	err = validateGasConfig(&cfg.RelayGas)
+	if err != nil {
+		return err
+	}
+	if cfg.Finalizer.StartOffset <= 0 && !cfg.Finalizer.AutoStartOffset {
+		return errors.New("finalizer start_offset must be positive")
+	}
+	if cfg.Finalizer.RetainedRewardEpochs != 0 && cfg.Finalizer.RetainedRewardEpochs < 2 {
+		return errors.New("finalizer retained_reward_epochs must be 0 or at least 2")
+	}
+	if cfg.Finalizer.BackupRandomDelay < 0 {
+		return errors.New("finalizer backup_random_delay must not be negative")
+	}
+	if cfg.Finalizer.ThresholdBIPS > 10000 || cfg.Finalizer.ExpiredPolicyThresholdBIPS > 10000 {
+		return errors.New("finalizer threshold_bips and expired_policy_threshold_bips must be at most 10000")
+	}
+	err = validateFinalizerProtocols(cfg.Finalizer.Protocols)
+	if err != nil {
+		return err
+	}
+	err = validateFinalizerSenders(cfg.Finalizer.Senders)
+	if err != nil {
+		return err
+	}
+	if cfg.Finalizer.SubmitterBan.MaxInvalidSignatures < 0 {
+		return errors.New("finalizer submitter_ban max_invalid_signatures must not be negative")
+	}
+	if cfg.Finalizer.SubmitterBan.MaxInvalidSignatures > 0 && cfg.Finalizer.SubmitterBan.Period <= 0 {
+		return errors.New("finalizer submitter_ban period must be positive")
+	}
+	if len(cfg.Finalizer.Submitters.Tracked) > 0 && len(cfg.Finalizer.Submitters.Ignored) > 0 {
+		return errors.New("finalizer submitters tracked and ignored must not both be set")
+	}
+	for i, provider := range cfg.Finalizer.FinalizationProviders {
+		if len(provider.ApiEndpoint) == 0 || len(provider.Protocols) == 0 {
+			return fmt.Errorf("finalizer finalization_providers[%d] requires api_endpoint and protocols", i)
+		}
+		if provider.Timeout < 0 {
+			return fmt.Errorf("finalizer finalization_providers[%d] timeout must not be negative", i)
+		}
+	}
+	switch cfg.Finalizer.SignatureSelection {
+	case "", SignatureSelectionFirstCome, SignatureSelectionLargestWeight, SignatureSelectionSmallestCalldata:
+	default:
+		return fmt.Errorf("unknown finalizer signature_selection %s, valid values are %s, %s and %s",
+			cfg.Finalizer.SignatureSelection, SignatureSelectionLargestWeight, SignatureSelectionFirstCome,
+			SignatureSelectionSmallestCalldata)
+	}
+	for name, protocol := range cfg.Protocol {
+		switch protocol.Type {
+		case ProtocolTypeGeneric, ProtocolTypeFDC:
+		default:
+			return fmt.Errorf("unknown type %s of protocol %s, valid values are empty and %s", protocol.Type, name, ProtocolTypeFDC)
+		}
+	}
+	if cfg.Clients.EnabledFastUpdates && len(cfg.FastUpdates.ApiEndpoint) == 0 {
+		return errors.New("fast_updates api_endpoint is required if fast updates are enabled")
+	}
+	if cfg.FastUpdates.Timeout < 0 || cfg.FastUpdates.PollInterval < 0 {
+		return errors.New("fast_updates timeout and poll_interval must not be negative")
+	}
+	if cfg.Listeners.Fetch.ChunkSize < 0 || cfg.Listeners.Fetch.PageSize < 0 || cfg.Listeners.Fetch.MaxRowsPerTick < 0 ||
+		cfg.Listeners.Fetch.MaxConcurrentQueries < 0 || cfg.Listeners.Fetch.QueriesPerSecond < 0 || cfg.Listeners.Fetch.CacheTTL < 0 {
+		return errors.New("listeners fetch limits must not be negative")
+	}
+	if cfg.Listeners.Fetch.ChunkSize%time.Second != 0 {
+		return errors.New("listeners fetch chunk_size must be a whole number of seconds")
+	}
+	for listener, listenerCfg := range cfg.Listeners.listeners() {
+		if listenerCfg.Interval < 0 {
+			return fmt.Errorf("listeners %s interval must not be negative", listener)
+		}
+		if listenerCfg.BufferSize < 0 {
+			return fmt.Errorf("listeners %s buffer_size must not be negative", listener)
+		}
+		switch listenerCfg.Overflow {
+		case "", ListenerOverflowBlock:
+		case ListenerOverflowDropOldest:
+			if stateListeners[listener] {
+				return fmt.Errorf("listeners %s overflow %s would drop events the client depends on", listener, ListenerOverflowDropOldest)
+			}
+			if listenerCfg.BufferSize == 0 {
+				return fmt.Errorf("listeners %s overflow %s requires a buffer_size", listener, ListenerOverflowDropOldest)
+			}
+		default:
+			return fmt.Errorf("unknown listeners %s overflow %s, valid values are %s and %s",
+				listener, listenerCfg.Overflow, ListenerOverflowBlock, ListenerOverflowDropOldest)
+		}
+	}
+	if cfg.Listeners.Reorg.Enabled && cfg.Listeners.Reorg.Depth == 0 {
+		return errors.New("listeners reorg depth must be positive")
+	}
+	err = validateListenerSource(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.TxAudit.Retention < 0 {
+		return errors.New("tx_audit retention must not be negative")
+	}
+	if cfg.LeaderElection.Enabled {
+		if cfg.LeaderElection.RenewInterval <= 0 || cfg.LeaderElection.RenewInterval*2 > cfg.LeaderElection.LeaseDuration {
+			return errors.New("leader_election renew_interval must be positive and at most half of lease_duration")
+		}
+		if len(cfg.LeaderElection.Name) == 0 && cfg.Identity.Address == (common.Address{}) {
+			return errors.New("leader_election name is required without the identity address")
+		}
+	}
+	if cfg.Credentials.NextSigningPolicyKeyConfigured() && cfg.Credentials.NextSigningPolicyRewardEpoch <= 0 {
+		return errors.New("credentials next_signing_policy_reward_epoch is required for the next signing policy key")
+	}
+	err = validateIdentities(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.TxTimeout.Default < 0 {
+		return errors.New("tx_timeout default must not be negative")
+	}
+	for operation, timeout := range cfg.TxTimeout.Operations {
+		if timeout < 0 {
+			return fmt.Errorf("tx_timeout operations.%s must not be negative", operation)
+		}
+	}
+	if cfg.Balance.Enabled && cfg.Balance.Interval <= 0 {
+		return errors.New("balance interval must be positive")
+	}
+	if cfg.Balance.MinBalance != nil && cfg.Balance.MinBalance.Sign() < 0 {
+		return errors.New("balance min_balance must not be negative")
+	}
+	if cfg.Alerts.Enabled() && (cfg.Alerts.RepeatInterval <= 0 || cfg.Alerts.Timeout <= 0 || cfg.Alerts.TxFailures <= 0 || cfg.Alerts.RegistrationTimeout <= 0) {
+		return errors.New("alerts repeat_interval, timeout, tx_failures and registration_timeout must be positive")
+	}
+	if cfg.PolicySigning.UrgentBefore < 0 || cfg.PolicySigning.AlertBefore < 0 {
+		return errors.New("policy_signing urgent_before and alert_before must not be negative")
+	}
+	if cfg.PolicySigning.UrgentGasPriceMultiplier < 1 {
+		return errors.New("policy_signing urgent_gas_price_multiplier must be at least 1")
+	}
+	if cfg.Spend.DailyBudget != nil && cfg.Spend.DailyBudget.Sign() < 0 {
+		return errors.New("spend daily_budget must not be negative")
+	}
+	switch cfg.DB.Driver {
+	case "", config.DBDriverMySQL, config.DBDriverPostgres:
+	default:
+		return fmt.Errorf("unknown db driver %s, valid values are %s and %s", cfg.DB.Driver, config.DBDriverMySQL, config.DBDriverPostgres)
+	}
+	if cfg.DB.MaxOpenConns < 0 || cfg.DB.MaxIdleConns < 0 || cfg.DB.ConnMaxLifetime < 0 {
+		return errors.New("db max_open_conns, max_idle_conns and conn_max_lifetime must not be negative")
+	}
+	if cfg.DB.ReconnectMinBackoff <= 0 || cfg.DB.ReconnectMaxBackoff < cfg.DB.ReconnectMinBackoff {
+		return errors.New("db reconnect_min_backoff must be positive and reconnect_max_backoff at least reconnect_min_backoff")
+	}
+	if cfg.ContractRegistry.RefreshInterval < 0 {
+		return errors.New("contract_registry refresh_interval must not be negative")
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		return errors.New("tracing sample_ratio must be between 0 and 1")
+	}
+	err = validateRetryPolicy(&cfg.Retry.RetryPolicy)
+	if err != nil {
+		return err
+	}
+	for operation, policy := range cfg.Retry.Operations {
+		err = validateRetryPolicy(&policy)
+		if err != nil {
+			return fmt.Errorf("retry operation %s: %w", operation, err)
+		}
+	}
+	return nil
+}
+
+func validateIdentities(cfg *ClientConfig) error {
+	addresses := map[common.Address]bool{cfg.Identity.Address: true}
+	for i, identity := range cfg.Identities {
+		if identity.Address == (common.Address{}) {
+			return fmt.Errorf("identities[%d] address is required", i)
+		}
+		if addresses[identity.Address] {
+			return fmt.Errorf("identities[%d] address %s is configured more than once", i, identity.Address.Hex())
+		}
+		addresses[identity.Address] = true
+		if !identity.Credentials.SystemClientSenderKeyConfigured() || !identity.Credentials.SigningPolicyKeyConfigured() {
+			return fmt.Errorf("identities[%d] requires the system client sender and signing policy keys", i)
+		}
+		if identity.Credentials.NextSigningPolicyKeyConfigured() {
+			return fmt.Errorf("identities[%d] next signing policy key is not supported", i)
+		}
+	}
+	return nil
+}
+
+func validateFinalizerProtocols(protocols []FinalizerProtocolConfig) error {
+	ids := make(map[uint8]bool)
+	for _, protocol := range protocols {
+		if ids[protocol.Id] {
+			return fmt.Errorf("finalizer protocol %d is configured more than once", protocol.Id)
+		}
+		ids[protocol.Id] = true
+		if protocol.VoterThresholdBIPS > 10000 {
+			return fmt.Errorf("finalizer protocol %d voter_threshold_bips must be at most 10000", protocol.Id)
+		}
+		if protocol.ThresholdBIPS > 10000 {
+			return fmt.Errorf("finalizer protocol %d threshold_bips must be at most 10000", protocol.Id)
+		}
+		if protocol.ExpiredPolicyThresholdBIPS > 10000 {
+			return fmt.Errorf("finalizer protocol %d expired_policy_threshold_bips must be at most 10000", protocol.Id)
+		}
+		if protocol.GracePeriodEndOffset < 0 {
+			return fmt.Errorf("finalizer protocol %d grace_period_end_offset must not be negative", protocol.Id)
+		}
+	}
+	return nil
+}
+
+func validateFinalizerSenders(senders []FinalizerSenderConfig) error {
+	protocols := make(map[uint8]bool)
+	for i, sender := range senders {
+		if len(sender.Protocols) == 0 {
+			return fmt.Errorf("finalizer senders[%d] requires protocols", i)
+		}
+		for _, protocolId := range sender.Protocols {
+			if protocols[protocolId] {
+				return fmt.Errorf("finalizer protocol %d has more than one sender", protocolId)
+			}
+			protocols[protocolId] = true
+		}
+		if len(sender.PrivateKeyFile) == 0 && len(FinalizerSenderPrivateKey(i)) == 0 && len(sender.Signer.Type) == 0 {
+			return fmt.Errorf("finalizer senders[%d] requires private_key_file, FINALIZER_SENDER_PRIVATE_KEY_%d or signer", i, i+1)
+		}
+	}
+	return nil
+}
+
+func validateListenerSource(cfg *ClientConfig) error {
+	switch cfg.Listeners.Source {
+	case "", ListenerSourceIndexer:
+		fallback := &cfg.Listeners.IndexerFallback
+		if fallback.Enabled && (fallback.MaxLag <= 0 || fallback.CheckInterval <= 0) {
+			return errors.New("listeners indexer_fallback max_lag and check_interval must be positive")
+		}
+		return nil
+	case ListenerSourceRPC:
+	default:
+		return fmt.Errorf("unknown listeners source %s, valid values are %s and %s",
+			cfg.Listeners.Source, ListenerSourceIndexer, ListenerSourceRPC)
+	}
+	if cfg.Listeners.IndexerFallback.Enabled {
+		return errors.New("listeners indexer_fallback requires the indexer source")
+	}
+	// the rpc source runs without a database
+	if cfg.Listeners.PersistentCheckpoints {
+		return errors.New("listeners persistent_checkpoints requires the indexer source")
+	}
+	if cfg.Finalizer.PersistentQueue {
+		return errors.New("finalizer persistent_queue requires the indexer source")
+	}
+	if cfg.Finalizer.PersistentSigningPolicies {
+		return errors.New("finalizer persistent_signing_policies requires the indexer source")
+	}
+	if cfg.TxAudit.Enabled {
+		return errors.New("tx_audit requires the indexer source")
+	}
+	if cfg.LeaderElection.Enabled {
+		return errors.New("leader_election requires the indexer source")
+	}
+	return nil
+}
+
+func validateRetryPolicy(cfg *RetryPolicy) error {
+	if cfg.MaxRetries < 0 {
+		return errors.New("max_retries must not be negative")
+	}
+	if cfg.Multiplier != 0 && cfg.Multiplier < 1 {
+		return errors.New("multiplier must be at least 1")
+	}
+	if cfg.Jitter < 0 || cfg.Jitter > 1 {
+		return errors.New("jitter must be between 0 and 1")
+	}
 	return nil
 }
 
@@ -187,5 +1240,30 @@ func validateGasConfig(cfg *GasConfig) error {
 	if cfg.GasPriceFixed.Cmp(common.Big0) != 0 && cfg.GasPriceMultiplier != 0.0 {
 		return errors.New("only one of gas_price_fixed and gas_price_multiplier can be set to a non-zero value")
 	}
+	if cfg.TxType != types.LegacyTxType && cfg.TxType != types.DynamicFeeTxType {
+		return fmt.Errorf("unsupported tx_type %d, valid values are 0 (legacy) and 2 (dynamic fee)", cfg.TxType)
+	}
+	if cfg.GasLimitMultiplier != 0 && cfg.GasLimitMultiplier < 1 {
+		return errors.New("gas_limit_multiplier must be at least 1")
+	}
+	switch cfg.GasStrategy {
+	case "", GasStrategyEIP1559:
+	case GasStrategyStatic, GasStrategyOracle:
+		if cfg.TxType == types.DynamicFeeTxType {
+			return fmt.Errorf("tx_type 2 requires gas_strategy %s", GasStrategyEIP1559)
+		}
+	default:
+		return fmt.Errorf("unknown gas_strategy %s, valid values are %s, %s and %s",
+			cfg.GasStrategy, GasStrategyStatic, GasStrategyOracle, GasStrategyEIP1559)
+	}
+	if cfg.GasStrategy == GasStrategyOracle && cfg.GasPriceFixed.Cmp(common.Big0) != 0 {
+		return fmt.Errorf("gas_price_fixed can not be set with gas_strategy %s", GasStrategyOracle)
+	}
+	if cfg.GasOracleBlocks < 0 {
+		return errors.New("gas_oracle_blocks must not be negative")
+	}
+	if cfg.GasOraclePercentile < 0 || cfg.GasOraclePercentile > 100 {
+		return errors.New("gas_oracle_percentile must be between 0 and 100")
+	}
 	return nil
 }
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Built-in settings of a network, selected with network = "<name>". Values set in
+// the config file or env variables override the preset.
+type NetworkPreset struct {
+	Name    string
+	ChainID int
+
+	// The contract addresses are resolved from the FlareContractRegistry
+	ContractRegistry common.Address
+
+	// Expected epoch settings of the network, checked by validate-config
+	VotingEpochDuration     time.Duration
+	RewardEpochVotingEpochs int64
+
+	// Default polling intervals of the listeners, see ListenersConfig.Intervals
+	ListenerIntervals map[string]time.Duration
+
+	// Default gas price strategy of the txs, see GasConfig.GasStrategy. The gas price
+	// of Flare follows the base fee, the gas prices of Songbird spike with the load,
+	// the testnets use the node's suggested gas price.
+	GasStrategy string
+}
+
+// The FlareContractRegistry has the same address on all networks
+var flareContractRegistry = common.HexToAddress("0xaD67FE66660Fb8dFE9d6b1b4240d8650e30F6019")
+
+var mainnetListenerIntervals = map[string]time.Duration{
+	ListenerSubmitSignatures:         500 * time.Millisecond,
+	ListenerVotePowerBlockSelected:   30 * time.Second,
+	ListenerSigningPolicyInitialized: 30 * time.Second,
+}
+
+var testnetListenerIntervals = map[string]time.Duration{
+	ListenerSubmitSignatures: time.Second,
+}
+
+var networkPresets = []NetworkPreset{
+	{
+		Name:                    "flare",
+		ChainID:                 flareChainID,
+		ContractRegistry:        flareContractRegistry,
+		VotingEpochDuration:     90 * time.Second,
+		RewardEpochVotingEpochs: 3360,
+		ListenerIntervals:       mainnetListenerIntervals,
+		GasStrategy:             GasStrategyEIP1559,
+	},
+	{
+		Name:                    "songbird",
+		ChainID:                 songbirdChainID,
+		ContractRegistry:        flareContractRegistry,
+		VotingEpochDuration:     90 * time.Second,
+		RewardEpochVotingEpochs: 3360,
+		ListenerIntervals:       mainnetListenerIntervals,
+		GasStrategy:             GasStrategyOracle,
+	},
+	{
+		Name:                    "coston",
+		ChainID:                 costonChainID,
+		ContractRegistry:        flareContractRegistry,
+		VotingEpochDuration:     90 * time.Second,
+		RewardEpochVotingEpochs: 240,
+		ListenerIntervals:       testnetListenerIntervals,
+		GasStrategy:             GasStrategyStatic,
+	},
+	{
+		Name:                    "coston2",
+		ChainID:                 coston2ChainID,
+		ContractRegistry:        flareContractRegistry,
+		VotingEpochDuration:     90 * time.Second,
+		RewardEpochVotingEpochs: 240,
+		ListenerIntervals:       testnetListenerIntervals,
+		GasStrategy:             GasStrategyStatic,
+	},
+}
+
+// NetworkPresetByName returns the preset of the network, the name is case insensitive
+func NetworkPresetByName(name string) (*NetworkPreset, bool) {
+	for i := range networkPresets {
+		if strings.EqualFold(networkPresets[i].Name, name) {
+			return &networkPresets[i], true
+		}
+	}
+	return nil, false
+}
+
+// NetworkPresetByChainID returns the preset of the network with the chain id
+func NetworkPresetByChainID(chainID int) (*NetworkPreset, bool) {
+	for i := range networkPresets {
+		if networkPresets[i].ChainID == chainID {
+			return &networkPresets[i], true
+		}
+	}
+	return nil, false
+}
+
+// NetworkNames returns the names of the presets, sorted
+func NetworkNames() []string {
+	names := make([]string, len(networkPresets))
+	for i, preset := range networkPresets {
+		names[i] = preset.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Sets the chain id and the contract registry of the network if they are not
+// configured. A configured chain id must be the one of the network.
+func applyNetworkPreset(cfg *ClientConfig) error {
+	if len(cfg.Network) == 0 {
+		return nil
+	}
+	preset, ok := NetworkPresetByName(cfg.Network)
+	if !ok {
+		return fmt.Errorf("unknown network %s, valid values are %s", cfg.Network, strings.Join(NetworkNames(), ", "))
+	}
+	if cfg.Chain.ChainID == 0 {
+		cfg.Chain.ChainID = preset.ChainID
+	} else if cfg.Chain.ChainID != preset.ChainID {
+		return fmt.Errorf("chain chain_id %d is not the chain id %d of network %s", cfg.Chain.ChainID, preset.ChainID, preset.Name)
+	}
+	if !cfg.ContractRegistry.Enabled() {
+		cfg.ContractRegistry.Address = preset.ContractRegistry
+	}
+	return nil
+}
+
+// Sets the gas strategy of the network of the chain id in the gas configs without a
+// strategy, tx type and fixed gas price
+func applyGasPreset(cfg *ClientConfig) {
+	preset, ok := NetworkPresetByChainID(cfg.Chain.ChainID)
+	if !ok {
+		return
+	}
+	for _, gasCfg := range []*GasConfig{&cfg.SubmitGas, &cfg.RegisterGas, &cfg.RelayGas} {
+		if len(gasCfg.GasStrategy) > 0 || gasCfg.TxType != 0 || (gasCfg.GasPriceFixed != nil && gasCfg.GasPriceFixed.Sign() != 0) {
+			continue
+		}
+		gasCfg.GasStrategy = preset.GasStrategy
+	}
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfigNetwork(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.toml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(fileName, []byte(content), 0o600))
+	}
+
+	write(`network = "Songbird"`)
+	cfg, err := BuildConfig(fileName)
+	require.NoError(t, err)
+	require.Equal(t, songbirdChainID, cfg.Chain.ChainID)
+	require.Equal(t, flareContractRegistry, cfg.ContractRegistry.Address)
+	require.Equal(t, 500*time.Millisecond, cfg.Listeners.Intervals(cfg.Chain.ChainID)[ListenerSubmitSignatures])
+
+	// configured values override the preset
+	write(`
+network = "coston2"
+
+[chain]
+chain_id = 114
+
+[contract_registry]
+address = "0x0000000000000000000000000000000000001234"
+
+[listeners.submit_signatures]
+interval = "3s"
+`)
+	cfg, err = BuildConfig(fileName)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0x1234"), cfg.ContractRegistry.Address)
+	require.Equal(t, 3*time.Second, cfg.Listeners.Intervals(cfg.Chain.ChainID)[ListenerSubmitSignatures])
+
+	write(`
+network = "flare"
+
+[chain]
+chain_id = 19
+`)
+	_, err = BuildConfig(fileName)
+	require.ErrorContains(t, err, "chain chain_id 19 is not the chain id 14 of network flare")
+
+	write(`network = "mainnet"`)
+	_, err = BuildConfig(fileName)
+	require.ErrorContains(t, err, "unknown network mainnet, valid values are coston, coston2, flare, songbird")
+}
+
+func TestBuildConfigGasStrategy(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.toml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(fileName, []byte(content), 0o600))
+	}
+
+	write(`network = "songbird"`)
+	cfg, err := BuildConfig(fileName)
+	require.NoError(t, err)
+	require.Equal(t, GasStrategyOracle, cfg.SubmitGas.Strategy())
+	require.Equal(t, GasStrategyOracle, cfg.RelayGas.Strategy())
+	blocks, percentile := cfg.SubmitGas.OracleSettings()
+	require.Equal(t, 20, blocks)
+	require.Equal(t, 60, percentile)
+
+	// the configured strategy, tx type and fixed gas price override the preset
+	write(`
+network = "flare"
+
+[gas_submit]
+gas_strategy = "oracle"
+gas_oracle_percentile = 80
+
+[gas_register]
+gas_price_fixed = 50000000000
+
+[gas_relay]
+tx_type = 2
+`)
+	cfg, err = BuildConfig(fileName)
+	require.NoError(t, err)
+	require.Equal(t, GasStrategyOracle, cfg.SubmitGas.Strategy())
+	_, percentile = cfg.SubmitGas.OracleSettings()
+	require.Equal(t, 80, percentile)
+	require.Equal(t, GasStrategyStatic, cfg.RegisterGas.Strategy())
+	require.Equal(t, GasStrategyEIP1559, cfg.RelayGas.Strategy())
+
+	write(`
+[gas_submit]
+gas_strategy = "oracle"
+gas_price_fixed = 50000000000
+`)
+	_, err = BuildConfig(fileName)
+	require.ErrorContains(t, err, "gas_price_fixed can not be set with gas_strategy oracle")
+
+	write(`
+[gas_submit]
+gas_strategy = "percentile"
+`)
+	_, err = BuildConfig(fileName)
+	require.ErrorContains(t, err, "unknown gas_strategy percentile")
+}
@@ -5,12 +5,49 @@ import (
 	"os"
 )
 
+const (
+	ProtocolTypeGeneric = ""
+	ProtocolTypeFDC     = "fdc"
+)
+
 type ProtocolConfig struct {
 	Id          uint8  `toml:"id"`
 	ApiEndpoint string `toml:"api_endpoint"`
+
+	// Data provider API of the protocol: empty for providers returning the encoded
+	// submit payloads, fdc for FDC providers returning the bit vectors and the
+	// consensus attestation responses
+	Type string `toml:"type"`
+
+	// Data providers queried in order if the previous provider returns an
+	// error or no data for a voting round
+	FallbackApiEndpoints []string `toml:"fallback_api_endpoints"`
 }
 
 func (cfg ProtocolConfig) XApiKey() string {
 	envVar := fmt.Sprintf("PROTOCOL_X_API_KEY_%d", cfg.Id)
 	return os.Getenv(envVar)
 }
+
+// FallbackXApiKey returns the API key of the i-th (starting with 0) fallback endpoint.
+func (cfg ProtocolConfig) FallbackXApiKey(i int) string {
+	envVar := fmt.Sprintf("PROTOCOL_X_API_KEY_%d_FALLBACK_%d", cfg.Id, i+1)
+	return os.Getenv(envVar)
+}
+
+// FinalizationProviderXApiKey returns the API key of the i-th (starting with 0) finalization provider.
+func FinalizationProviderXApiKey(i int) string {
+	envVar := fmt.Sprintf("FINALIZATION_PROVIDER_X_API_KEY_%d", i+1)
+	return os.Getenv(envVar)
+}
+
+// FinalizerSenderPrivateKey returns the private key of the i-th (starting with 0) finalizer sender.
+func FinalizerSenderPrivateKey(i int) string {
+	envVar := fmt.Sprintf("FINALIZER_SENDER_PRIVATE_KEY_%d", i+1)
+	return os.Getenv(envVar)
+}
+
+// FastUpdatesXApiKey returns the API key of the fast updates feed value provider.
+func FastUpdatesXApiKey() string {
+	return os.Getenv("FAST_UPDATES_X_API_KEY")
+}
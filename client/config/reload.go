@@ -0,0 +1,251 @@
+package config
+
+import (
+	"flare-tlc/config"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Called with the live config after changes of a reloaded config file were applied
+var ReloadCallback = config.ConfigCallback[*ClientConfig]{}
+
+// Guards the values of the live config changed by ApplyReload. Maps of the config are
+// not changed in place, they are replaced by an updated copy, so a map read under the
+// read lock can be used after it is released. The reload callbacks are called by the
+// config watcher, the only writer, and read the config without the lock.
+var liveConfigMutex sync.RWMutex
+
+// Keys (toml paths) that can be changed without restart, * matches any map key.
+// A key matches if it is equal to or nested in one of the listed keys. Only the
+// protocol endpoints are reloadable, the fast_updates and finalizer.finalization_providers
+// endpoints are read when the clients are created and require restart.
+var reloadableKeys = []string{
+	"logger",
+	"gas_submit",
+	"gas_register",
+	"gas_relay",
+	"retry",
+	"tx_timeout",
+	"protocol.*.api_endpoint",
+	"protocol.*.fallback_api_endpoints",
+}
+
+// Values of these keys are not logged
+var secretKeys = []string{
+	"credentials",
+	"db.password",
+	"chain.api_key",
+}
+
+// Change of a config value, Key is the toml path, e.g. gas_submit.gas_limit_multiplier
+type ConfigChange struct {
+	Key string
+	Old string
+	New string
+
+	path []reflect.Value // map keys or field indexes of the value
+}
+
+func (c ConfigChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, c.Old, c.New)
+}
+
+// Applies the changes between baseline and updated that do not require restart to cfg.
+// Returns the applied changes and the changes rejected because they require restart.
+// The live gas and retry values are read with GasConfig.Snapshot and RetryConfig.Policy.
+func ApplyReload(cfg, baseline, updated *ClientConfig) (applied, rejected []ConfigChange) {
+	liveConfigMutex.Lock()
+	defer liveConfigMutex.Unlock()
+
+	for _, change := range DiffConfig(baseline, updated) {
+		if !matchesKey(change.Key, reloadableKeys) {
+			rejected = append(rejected, change)
+			continue
+		}
+		setValue(reflect.ValueOf(cfg).Elem(), change.path, valueAt(reflect.ValueOf(updated).Elem(), change.path))
+		applied = append(applied, change)
+	}
+	return applied, rejected
+}
+
+// Returns the changed values between the configs, ordered as in the config
+// struct (map keys are not ordered)
+func DiffConfig(old, new *ClientConfig) []ConfigChange {
+	var changes []ConfigChange
+	diffValues("", nil, reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem(), &changes)
+	return changes
+}
+
+func diffValues(key string, path []reflect.Value, old, new reflect.Value, changes *[]ConfigChange) {
+	switch {
+	case isConfigStruct(old.Type()):
+		for i := 0; i < old.NumField(); i++ {
+			field := old.Type().Field(i)
+			tag := strings.Split(field.Tag.Get("toml"), ",")[0]
+			if tag == "-" || !field.IsExported() {
+				continue
+			}
+			if tag == "" {
+				tag = field.Name
+			}
+			fieldKey := key
+			if !field.Anonymous {
+				fieldKey = joinKey(key, tag)
+			}
+			fieldPath := append(append([]reflect.Value{}, path...), reflect.ValueOf(i))
+			diffValues(fieldKey, fieldPath, old.Field(i), new.Field(i), changes)
+		}
+
+	case old.Kind() == reflect.Map && old.Type().Key().Kind() == reflect.String:
+		keys := make(map[string]reflect.Value)
+		for _, k := range append(old.MapKeys(), new.MapKeys()...) {
+			keys[k.String()] = k
+		}
+		for name, k := range keys {
+			entryPath := append(append([]reflect.Value{}, path...), k)
+			oldEntry, newEntry := old.MapIndex(k), new.MapIndex(k)
+			if !oldEntry.IsValid() || !newEntry.IsValid() {
+				// added or removed entries are a single change
+				*changes = append(*changes, newChange(joinKey(key, name), entryPath, oldEntry, newEntry))
+				continue
+			}
+			diffValues(joinKey(key, name), entryPath, oldEntry, newEntry, changes)
+		}
+
+	default:
+		if !valuesEqual(old, new) {
+			*changes = append(*changes, newChange(key, path, old, new))
+		}
+	}
+}
+
+func newChange(key string, path []reflect.Value, old, new reflect.Value) ConfigChange {
+	change := ConfigChange{Key: key, Old: formatValue(old), New: formatValue(new), path: path}
+	if matchesKey(key, secretKeys) {
+		change.Old, change.New = "***", "***"
+	}
+	return change
+}
+
+// Structs with exported fields are compared field by field, other structs
+// (e.g. big.Int) are compared as values
+func isConfigStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func valuesEqual(a, b reflect.Value) bool {
+	if x, ok := a.Interface().(*big.Int); ok {
+		y := b.Interface().(*big.Int)
+		if x == nil || y == nil {
+			return x == y
+		}
+		return x.Cmp(y) == 0
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+func formatValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<none>"
+	}
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func matchesKey(key string, patterns []string) bool {
+	keyParts := strings.Split(key, ".")
+	for _, pattern := range patterns {
+		patternParts := strings.Split(pattern, ".")
+		if len(patternParts) > len(keyParts) {
+			continue
+		}
+		matches := true
+		for i, part := range patternParts {
+			if part != "*" && part != keyParts[i] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func valueAt(v reflect.Value, path []reflect.Value) reflect.Value {
+	for _, step := range path {
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.Field(int(step.Int()))
+		case reflect.Map:
+			if v = v.MapIndex(step); !v.IsValid() {
+				return reflect.Value{}
+			}
+		}
+	}
+	return v
+}
+
+// Sets the value at path, an invalid value deletes the map entry. Map entries
+// are not addressable, they are copied, updated and stored back. The map is replaced
+// by an updated copy, so readers holding the old map are not affected.
+func setValue(v reflect.Value, path []reflect.Value, value reflect.Value) {
+	if len(path) == 0 {
+		v.Set(value)
+		return
+	}
+	step := path[0]
+	switch v.Kind() {
+	case reflect.Struct:
+		setValue(v.Field(int(step.Int())), path[1:], value)
+
+	case reflect.Map:
+		if len(path) == 1 {
+			if v.IsNil() && !value.IsValid() {
+				return
+			}
+			updated := copyMap(v)
+			updated.SetMapIndex(step, value)
+			v.Set(updated)
+			return
+		}
+		entry := reflect.New(v.Type().Elem()).Elem()
+		if current := v.MapIndex(step); current.IsValid() {
+			entry.Set(current)
+		}
+		setValue(entry, path[1:], value)
+		updated := copyMap(v)
+		updated.SetMapIndex(step, entry)
+		v.Set(updated)
+	}
+}
+
+func copyMap(v reflect.Value) reflect.Value {
+	copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		copied.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return copied
+}
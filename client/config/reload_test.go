@@ -0,0 +1,164 @@
+package config
+
+import (
+	"context"
+	"flare-tlc/config"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testReloadConfig() *ClientConfig {
+	cfg := newConfig()
+	cfg.Logger.Level = "INFO"
+	cfg.Chain.ChainID = 162
+	cfg.Credentials.SigningPolicyPrivateKeyFile = "key.txt"
+	cfg.Protocol = map[string]ProtocolConfig{
+		"ftso": {Id: 100, ApiEndpoint: "http://localhost:3000"},
+	}
+	cfg.SubmitGas.GasPriceFixed = big.NewInt(0)
+	return cfg
+}
+
+func changeKeys(changes []ConfigChange) []string {
+	keys := make([]string, len(changes))
+	for i, change := range changes {
+		keys[i] = change.Key
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestApplyReload(t *testing.T) {
+	cfg := testReloadConfig()
+	baseline := testReloadConfig()
+
+	updated := testReloadConfig()
+	updated.Logger.Level = "DEBUG"
+	updated.SubmitGas.GasLimitMultiplier = 1.5
+	updated.SubmitGas.GasLimitCaps = map[string]uint64{RetryOpSubmit: 1000000}
+	updated.Retry.Operations = map[string]RetryPolicy{RetryOpRelay: {MaxRetries: 10}}
+	updated.Protocol["ftso"] = ProtocolConfig{
+		Id: 100, ApiEndpoint: "http://localhost:3001", FallbackApiEndpoints: []string{"http://localhost:3002"},
+	}
+	updated.Protocol["fdc"] = ProtocolConfig{Id: 200, ApiEndpoint: "http://localhost:4000"}
+	updated.FastUpdates.ApiEndpoint = "http://localhost:3100"
+	updated.Finalizer.FinalizationProviders = []FinalizationProviderConfig{{ApiEndpoint: "http://localhost:3200"}}
+	updated.Chain.ChainID = 14
+	updated.Credentials.SigningPolicyPrivateKeyFile = "other.txt"
+
+	applied, rejected := ApplyReload(cfg, baseline, updated)
+	require.Equal(t, []string{
+		"gas_submit.gas_limit_caps.submit",
+		"gas_submit.gas_limit_multiplier",
+		"logger.level",
+		"protocol.ftso.api_endpoint",
+		"protocol.ftso.fallback_api_endpoints",
+		"retry.operations.relay",
+	}, changeKeys(applied))
+	require.Equal(t, []string{
+		"chain.chain_id",
+		"credentials.signing_policy_private_key_file",
+		"fast_updates.api_endpoint",
+		"finalizer.finalization_providers",
+		"protocol.fdc",
+	}, changeKeys(rejected))
+
+	require.Equal(t, "DEBUG", cfg.Logger.Level)
+	require.EqualValues(t, 1.5, cfg.SubmitGas.GasLimitMultiplier)
+	require.EqualValues(t, 1000000, cfg.SubmitGas.GasLimitCapFor(RetryOpSubmit))
+	require.Equal(t, 10, cfg.Retry.Policy(RetryOpRelay).MaxRetries)
+	require.Equal(t, "http://localhost:3001", cfg.Protocol["ftso"].ApiEndpoint)
+	require.Equal(t, []string{"http://localhost:3002"}, cfg.Protocol["ftso"].FallbackApiEndpoints)
+	require.NotContains(t, cfg.Protocol, "fdc")
+	require.EqualValues(t, 162, cfg.Chain.ChainID)
+	require.Equal(t, "key.txt", cfg.Credentials.SigningPolicyPrivateKeyFile)
+
+	for _, change := range rejected {
+		if change.Key == "credentials.signing_policy_private_key_file" {
+			require.Equal(t, "***", change.New)
+		}
+	}
+
+	// removed map entries are applied as removals, the snapshot keeps the old map
+	snapshot := cfg.SubmitGas.Snapshot()
+	baseline = testReloadConfig()
+	baseline.SubmitGas.GasLimitCaps = map[string]uint64{RetryOpSubmit: 1000000}
+	applied, rejected = ApplyReload(cfg, baseline, testReloadConfig())
+	require.Contains(t, changeKeys(applied), "gas_submit.gas_limit_caps.submit")
+	require.Empty(t, rejected)
+	require.NotContains(t, cfg.SubmitGas.GasLimitCaps, RetryOpSubmit)
+	require.EqualValues(t, 1000000, snapshot.GasLimitCapFor(RetryOpSubmit))
+}
+
+func TestDiffConfigBigInt(t *testing.T) {
+	baseline := testReloadConfig()
+	updated := testReloadConfig()
+	// equal values with a different internal representation
+	updated.SubmitGas.GasPriceFixed = new(big.Int).Sub(big.NewInt(1), big.NewInt(1))
+	require.Empty(t, DiffConfig(baseline, updated))
+
+	updated.SubmitGas.GasPriceFixed = big.NewInt(25)
+	require.Equal(t, []string{"gas_submit.gas_price_fixed"}, changeKeys(DiffConfig(baseline, updated)))
+}
+
+func TestReloadCallbackRemove(t *testing.T) {
+	var callback config.ConfigCallback[int]
+	var calls []string
+	removeA := callback.AddCallback(func(int) { calls = append(calls, "a") })
+	removeB := callback.AddCallback(func(int) { calls = append(calls, "b") })
+
+	callback.Call(1)
+	removeA()
+	callback.Call(2)
+	removeA()
+	removeB()
+	callback.Call(3)
+	require.Equal(t, []string{"a", "b", "b"}, calls)
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.toml")
+	require.NoError(t, os.WriteFile(fileName, []byte("[gas_submit]\ngas_limit_multiplier = 1\n"), 0o600))
+	cfg, err := BuildConfig(fileName)
+	require.NoError(t, err)
+
+	reloaded := make(chan *ClientConfig, 1)
+	remove := ReloadCallback.AddCallback(func(cfg *ClientConfig) {
+		select {
+		case reloaded <- cfg:
+		default:
+		}
+	})
+	defer remove()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- WatchConfigFile(ctx, fileName, cfg)
+	}()
+
+	// the watcher may not be started yet, the file is written until it is reloaded
+	content := []byte("[gas_submit]\ngas_limit_multiplier = 2\n\n[chain]\nchain_id = 14\n")
+	require.Eventually(t, func() bool {
+		require.NoError(t, os.WriteFile(fileName, content, 0o600))
+		select {
+		case <-reloaded:
+			return true
+		case <-time.After(2 * configReloadDelay):
+			return false
+		}
+	}, 10*time.Second, 10*time.Millisecond)
+
+	require.EqualValues(t, 2, cfg.SubmitGas.GasLimitMultiplier)
+	require.EqualValues(t, 0, cfg.Chain.ChainID)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"context"
+	"flare-tlc/config"
+	"flare-tlc/utils/secrets"
+	"fmt"
+	"time"
+)
+
+const (
+	SecretsBackendVault             = "vault"
+	SecretsBackendAWSSecretsManager = "aws_secrets_manager"
+
+	secretsFetchTimeout = 30 * time.Second
+)
+
+// Credentials, DB and RPC settings can be read from a secrets backend. The secret
+// holds the values by the names of their env variables, e.g. DB_PASSWORD.
+type SecretsConfig struct {
+	// vault, aws_secrets_manager or empty (disabled)
+	Backend string `toml:"backend" envconfig:"SECRETS_BACKEND"`
+
+	// Vault KV secret path, e.g. secret/data/flare-system-client, or AWS secret name or ARN
+	Path string `toml:"path" envconfig:"SECRETS_PATH"`
+
+	// Vault address, the token is read from the VAULT_TOKEN env variable only
+	VaultAddress string `toml:"vault_address" envconfig:"VAULT_ADDR"`
+	VaultToken   string `toml:"-" envconfig:"VAULT_TOKEN"`
+
+	// AWS region, optional
+	Region string `toml:"region" envconfig:"AWS_REGION"`
+}
+
+// Config values that can be read from the secrets backend, by the names of
+// their env variables
+func secretFields(cfg *ClientConfig) map[string]*string {
+	return map[string]*string{
+		"DB_USERNAME": &cfg.DB.Username,
+		"DB_PASSWORD": &cfg.DB.Password,
+
+		"ETH_RPC_URL": &cfg.Chain.EthRPCURL,
+		"ETH_WS_URL":  &cfg.Chain.EthWSURL,
+		"API_KEY":     &cfg.Chain.ApiKey,
+
+		"SIGNING_POLICY_PRIVATE_KEY":                          &cfg.Credentials.SigningPolicyPrivateKey,
+		"SIGNING_POLICY_PRIVATE_KEY_FILE":                     &cfg.Credentials.SigningPolicyPrivateKeyFile,
+		"SYSTEM_CLIENT_SENDER_PRIVATE_KEY":                    &cfg.Credentials.SystemClientSenderPrivateKey,
+		"SYSTEM_CLIENT_SENDER_PRIVATE_KEY_FILE":               &cfg.Credentials.SystemClientSenderPrivateKeyFile,
+		"PROTOCOL_MANAGER_SUBMIT_PRIVATE_KEY":                 &cfg.Credentials.ProtocolManagerSubmitPrivateKey,
+		"PROTOCOL_MANAGER_SUBMIT_PRIVATE_KEY_FILE":            &cfg.Credentials.ProtocolManagerSubmitPrivateKeyFile,
+		"PROTOCOL_MANAGER_SUBMIT_SIGNATURES_PRIVATE_KEY":      &cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKey,
+		"PROTOCOL_MANAGER_SUBMIT_SIGNATURES_PRIVATE_KEY_FILE": &cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKeyFile,
+		"SORTITION_PRIVATE_KEY":                               &cfg.Credentials.SortitionPrivateKey,
+		"SORTITION_PRIVATE_KEY_FILE":                          &cfg.Credentials.SortitionPrivateKeyFile,
+		"IDENTITY_PRIVATE_KEY":                                &cfg.Credentials.IdentityPrivateKey,
+		"IDENTITY_PRIVATE_KEY_FILE":                           &cfg.Credentials.IdentityPrivateKeyFile,
+		"NEXT_SIGNING_POLICY_PRIVATE_KEY":                     &cfg.Credentials.NextSigningPolicyPrivateKey,
+		"NEXT_SIGNING_POLICY_PRIVATE_KEY_FILE":                &cfg.Credentials.NextSigningPolicyPrivateKeyFile,
+
+		"ALERTS_WEBHOOK_URL":           &cfg.Alerts.WebhookURL,
+		"ALERTS_SLACK_WEBHOOK_URL":     &cfg.Alerts.SlackWebhookURL,
+		"ALERTS_PAGERDUTY_ROUTING_KEY": &cfg.Alerts.PagerDutyRoutingKey,
+	}
+}
+
+// Reads the secrets config from the env variables and sets the config values
+// held by the secrets backend
+func applySecrets(cfg *ClientConfig) error {
+	err := config.ReadEnv(&cfg.Secrets)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Secrets.Backend) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), secretsFetchTimeout)
+	defer cancel()
+
+	store, err := newSecretsStore(ctx, &cfg.Secrets)
+	if err != nil {
+		return err
+	}
+	values, err := store.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("error fetching secrets from %s: %w", cfg.Secrets.Backend, err)
+	}
+	return setSecrets(cfg, values)
+}
+
+func setSecrets(cfg *ClientConfig, values map[string]string) error {
+	fields := secretFields(cfg)
+	for name, value := range values {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("secret %s is not a supported config value", name)
+		}
+		*field = value
+	}
+	return nil
+}
+
+func newSecretsStore(ctx context.Context, cfg *SecretsConfig) (secrets.Store, error) {
+	switch cfg.Backend {
+	case SecretsBackendVault:
+		return secrets.NewVaultStore(cfg.VaultAddress, cfg.VaultToken, cfg.Path)
+	case SecretsBackendAWSSecretsManager:
+		return secrets.NewAWSSecretsManagerStore(ctx, cfg.Path, cfg.Region)
+	default:
+		return nil, fmt.Errorf("unknown secrets backend %s", cfg.Backend)
+	}
+}
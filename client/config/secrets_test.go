@@ -0,0 +1,47 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildConfigSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data": {"data": {"DB_PASSWORD": "vault", "ETH_RPC_URL": "http://vault:9650"}, "metadata": {}}}`))
+	}))
+	defer server.Close()
+
+	fileName := filepath.Join(t.TempDir(), "config.toml")
+	content := `
+[db]
+username = "file"
+password = "file"
+
+[chain]
+eth_rpc_url = "http://file:9650"
+
+[secrets]
+backend = "vault"
+path = "secret/data/client"
+vault_address = "` + server.URL + `"
+`
+	require.NoError(t, os.WriteFile(fileName, []byte(content), 0o600))
+	t.Setenv("VAULT_TOKEN", "token")
+	t.Setenv("ETH_RPC_URL", "http://env:9650")
+
+	cfg, err := BuildConfig(fileName)
+	require.NoError(t, err)
+	require.Equal(t, "file", cfg.DB.Username)
+	require.Equal(t, "vault", cfg.DB.Password)
+	require.Equal(t, "http://env:9650", cfg.Chain.EthRPCURL)
+}
+
+func TestSetSecretsUnsupported(t *testing.T) {
+	err := setSecrets(newConfig(), map[string]string{"DB_HOST": "localhost"})
+	require.ErrorContains(t, err, "secret DB_HOST is not a supported config value")
+}
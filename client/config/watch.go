@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"flare-tlc/config"
+	flarelogger "flare-tlc/logger"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+var logger = flarelogger.Module("config")
+
+// Editors often write the file in several steps, the config is reloaded after
+// there are no changes for this delay
+const configReloadDelay = 500 * time.Millisecond
+
+// WatchConfigFile watches the config file and applies the changes that do not
+// require restart to cfg, until ctx is done. Every applied and rejected change
+// is logged.
+func WatchConfigFile(ctx context.Context, fileName string, cfg *ClientConfig) error {
+	baseline, err := BuildConfig(fileName)
+	if err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "error creating config file watcher")
+	}
+	defer watcher.Close()
+
+	// the directory is watched, the file may be replaced instead of written to
+	if err := watcher.Add(filepath.Dir(fileName)); err != nil {
+		return errors.Wrap(err, "error watching config file")
+	}
+	logger.Info("Watching config file %s for changes", fileName)
+
+	name := filepath.Clean(fileName)
+	var reload <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == name && event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+				reload = time.After(configReloadDelay)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logger.Warn("Config file watcher error: %v", err)
+
+		case <-reload:
+			reload = nil
+			baseline = reloadConfig(fileName, cfg, baseline)
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Applies the changes of the config file since baseline to cfg, returns the new
+// baseline. Changes that require restart are not added to the baseline, so they
+// are reported again on the next reload.
+func reloadConfig(fileName string, cfg, baseline *ClientConfig) *ClientConfig {
+	updated, err := BuildConfig(fileName)
+	if err != nil {
+		logger.Error("Error reloading config file %s, keeping the current config: %v", fileName, err)
+		return baseline
+	}
+
+	applied, rejected := ApplyReload(cfg, baseline, updated)
+	ApplyReload(baseline, baseline, updated)
+
+	for _, change := range rejected {
+		logger.Warn("Config change requires restart, not applied: %s", change)
+	}
+	loggerChanged := false
+	for _, change := range applied {
+		logger.Info("Config change applied: %s", change)
+		loggerChanged = loggerChanged || strings.HasPrefix(change.Key, "logger.")
+	}
+	if loggerChanged {
+		config.GlobalConfigCallback.Call(cfg)
+	}
+	if len(applied) > 0 {
+		ReloadCallback.Call(cfg)
+	}
+	return baseline
+}
@@ -0,0 +1,77 @@
+package context
+
+import (
+	globalConfig "flare-tlc/config"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Chain connections shared by all clients of the process. The connections are dialed
+// on first use and closed with the context, after the clients are stopped, so that
+// clients restarted on a contract address change keep using them.
+type connectionPool struct {
+	chainCfg globalConfig.ChainConfig
+
+	mu  sync.Mutex
+	rpc *rpc.Client
+	eth *ethclient.Client
+	ws  *ethclient.Client
+}
+
+func newConnectionPool(chainCfg globalConfig.ChainConfig) *connectionPool {
+	return &connectionPool{chainCfg: chainCfg}
+}
+
+func (p *connectionPool) rpcClient() (*rpc.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.rpc == nil {
+		rpcClient, err := p.chainCfg.DialRPC()
+		if err != nil {
+			return nil, err
+		}
+		p.rpc = rpcClient
+		p.eth = ethclient.NewClient(rpcClient)
+	}
+	return p.rpc, nil
+}
+
+func (p *connectionPool) ethClient() (*ethclient.Client, error) {
+	if _, err := p.rpcClient(); err != nil {
+		return nil, err
+	}
+	return p.eth, nil
+}
+
+// Subscriptions of all listeners share the websocket connection
+func (p *connectionPool) wsClient() (*ethclient.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ws == nil {
+		wsClient, err := p.chainCfg.DialWS()
+		if err != nil {
+			return nil, err
+		}
+		p.ws = wsClient
+	}
+	return p.ws, nil
+}
+
+func (p *connectionPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ws != nil {
+		p.ws.Close()
+		p.ws = nil
+	}
+	if p.rpc != nil {
+		p.rpc.Close()
+		p.rpc = nil
+		p.eth = nil
+	}
+}
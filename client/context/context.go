@@ -1,62 +1,299 @@
 package context
 
 import (
+	"context"
 	"flag"
 	"flare-tlc/client/config"
+	"flare-tlc/client/shared"
+	"flare-tlc/client/shared/alerts"
+	"flare-tlc/client/shared/rpcsource"
 	globalConfig "flare-tlc/config"
 	"flare-tlc/database"
+	"flare-tlc/logger"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/credentials"
+	"sync"
+	"time"
 
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
 
+const contractResolveTimeout = 30 * time.Second
+
 type ClientContext interface {
 	Config() *config.ClientConfig
+	// Nil if the listeners use the rpc source
 	DB() *gorm.DB
+	// Nil if the listeners use the indexer db
+	RPCSource() *rpcsource.Source
+	// Nil if the indexer fallback is disabled, switches the listeners of the indexer
+	// db to the rpc node while the indexer lags
+	IndexerMonitor() *rpcsource.IndexerMonitor
 	Flags() *ClientFlags
+	// Nil if the contract addresses are not resolved from the registry
+	ContractResolver() *shared.ContractResolver
+
+	// Chain connections shared by the clients, dialed on first use. The clients must
+	// not close them, they are closed by Close.
+	EthClient() (*ethclient.Client, error)
+	RPCClient() (*rpc.Client, error)
+	// Websocket connection for the event subscriptions
+	WSClient() (*ethclient.Client, error)
+	// Nonce manager of the sender accounts, used by chain.TransactWithNonce
+	NonceManager() *chain.NonceManager
+	// Audit log of the sent txs, nil if tx_audit is disabled
+	TxAuditLog() *shared.TxAuditLogDB
+
+	// Signing policy signer shared by the clients, so that all of them switch to the
+	// staged next key at the same time
+	SigningPolicySigner() (*credentials.RotatingSigner, error)
+
+	// Closes the shared connections and the database handle, called after the
+	// clients are stopped
+	Close()
 }
 
 type ClientFlags struct {
 	ConfigFileName string
+	DryRun         bool
+	// Run without sending transactions, see chain.SetObserver
+	Observer bool
 	// Add additional flags here
 }
 
 type clientContext struct {
-	config *config.ClientConfig
-	db     *gorm.DB
-	flags  *ClientFlags
+	config    *config.ClientConfig
+	db        *gorm.DB
+	rpcSource *rpcsource.Source
+	monitor   *rpcsource.IndexerMonitor
+	flags     *ClientFlags
+	resolver  *shared.ContractResolver
+	pool      *connectionPool
+	txAudit   *shared.TxAuditLogDB
+
+	// stops the reconnection monitor of the db, nil without the db
+	stopDBMonitor context.CancelFunc
+
+	signerMu            sync.Mutex
+	signingPolicySigner *credentials.RotatingSigner
 }
 
 func BuildContext() (ClientContext, error) {
-	flags := parseFlags()
+	return BuildContextWithFlags(parseFlags())
+}
+
+// Builds the context with flags parsed by the caller, e.g. by a subcommand
+func BuildContextWithFlags(flags *ClientFlags) (ClientContext, error) {
 	cfg, err := config.BuildConfig(flags.ConfigFileName)
 	if err != nil {
 		return nil, err
 	}
 	globalConfig.GlobalConfigCallback.Call(cfg)
 
-	db, err := database.Connect(&cfg.DB)
+	if flags.DryRun && flags.Observer {
+		return nil, errors.New("-dry-run and -observer cannot be combined")
+	}
+	chain.SetDryRun(flags.DryRun)
+	if flags.DryRun {
+		logger.Warn("Dry run mode: transactions are simulated and not broadcast")
+	}
+	chain.SetObserver(flags.Observer)
+	if flags.Observer {
+		logger.Warn("Observer mode: transactions are not sent, only logged and counted in tx_observed_total")
+	}
+	chain.SetDailyBudget(cfg.Spend.DailyBudget)
+	chain.SetTxTimeouts(&cfg.TxTimeout)
+	config.ReloadCallback.AddCallback(func(cfg *config.ClientConfig) {
+		chain.SetTxTimeouts(&cfg.TxTimeout)
+	})
+	if cfg.Alerts.Enabled() {
+		chain.SetTxFailureHook(alerts.TxFailuresHook(cfg.Alerts.TxFailures))
+	}
+	chain.SetVerifyReceiptLogs(cfg.TxVerification.ReceiptLogs)
+
+	pool := newConnectionPool(cfg.ChainConfig())
+	var resolver *shared.ContractResolver
+	if cfg.ContractRegistry.Enabled() {
+		resolver, err = resolveContractAddresses(cfg, pool)
+		if err != nil {
+			pool.close()
+			return nil, err
+		}
+	}
+
+	clientCtx := &clientContext{
+		config:   cfg,
+		flags:    flags,
+		resolver: resolver,
+		pool:     pool,
+	}
+	if cfg.Listeners.RPCSource() {
+		rpcClient, err := pool.rpcClient()
+		if err != nil {
+			pool.close()
+			return nil, err
+		}
+		clientCtx.rpcSource = rpcsource.NewSource(rpcClient, &cfg.Listeners.RPC)
+		logger.Info("Listeners read logs and transactions from the rpc node, the indexer db is not used")
+	} else {
+		clientCtx.db, err = database.Connect(&cfg.DB)
+		if err != nil {
+			pool.close()
+			return nil, err
+		}
+		dbMonitor, err := database.NewConnectionMonitor(clientCtx.db, &cfg.DB)
+		if err != nil {
+			pool.close()
+			return nil, err
+		}
+		var monitorCtx context.Context
+		monitorCtx, clientCtx.stopDBMonitor = context.WithCancel(context.Background())
+		go dbMonitor.Run(monitorCtx)
+		if cfg.TxAudit.Enabled {
+			clientCtx.txAudit, err = shared.NewTxAuditLogDB(clientCtx.db, &cfg.TxAudit)
+			if err != nil {
+				clientCtx.stopDBMonitor()
+				pool.close()
+				return nil, err
+			}
+			chain.SetTxAuditLog(clientCtx.txAudit)
+		}
+		if cfg.Listeners.IndexerFallback.Enabled {
+			rpcClient, err := pool.rpcClient()
+			if err != nil {
+				pool.close()
+				return nil, err
+			}
+			clientCtx.monitor = rpcsource.NewIndexerMonitor(
+				rpcsource.NewSource(rpcClient, &cfg.Listeners.RPC),
+				clientCtx.latestIndexedTimestamp,
+				&cfg.Listeners.IndexerFallback,
+			)
+		}
+	}
+	return clientCtx, nil
+}
+
+// Timestamp of the latest indexed transaction, 0 if there are none
+func (c *clientContext) latestIndexedTimestamp() (int64, error) {
+	latest, err := database.FetchLatestTransaction(c.db)
+	if err != nil || latest == nil {
+		return 0, err
+	}
+	return int64(latest.Timestamp), nil
+}
+
+// Sets the contract addresses missing in the config to the addresses from the registry
+func resolveContractAddresses(cfg *config.ClientConfig, pool *connectionPool) (*shared.ContractResolver, error) {
+	ethClient, err := pool.ethClient()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := shared.NewContractResolver(ethClient, cfg.ContractRegistry.Address, cfg.ContractAddresses)
 	if err != nil {
 		return nil, err
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), contractResolveTimeout)
+	defer cancel()
 
-	return &clientContext{
-		config: cfg,
-		db:     db,
-		flags:  flags,
-	}, nil
+	addresses, err := resolver.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Contract addresses resolved from the registry %s: %+v", cfg.ContractRegistry.Address.Hex(), addresses)
+	cfg.ContractAddresses = addresses
+	return resolver, nil
 }
 
 func (c *clientContext) Config() *config.ClientConfig { return c.config }
 
 func (c *clientContext) DB() *gorm.DB { return c.db }
 
+func (c *clientContext) RPCSource() *rpcsource.Source { return c.rpcSource }
+
+func (c *clientContext) IndexerMonitor() *rpcsource.IndexerMonitor { return c.monitor }
+
 func (c *clientContext) Flags() *ClientFlags { return c.flags }
 
+func (c *clientContext) ContractResolver() *shared.ContractResolver { return c.resolver }
+
+func (c *clientContext) EthClient() (*ethclient.Client, error) { return c.pool.ethClient() }
+
+func (c *clientContext) RPCClient() (*rpc.Client, error) { return c.pool.rpcClient() }
+
+func (c *clientContext) WSClient() (*ethclient.Client, error) { return c.pool.wsClient() }
+
+func (c *clientContext) NonceManager() *chain.NonceManager { return chain.DefaultNonceManager() }
+
+func (c *clientContext) TxAuditLog() *shared.TxAuditLogDB { return c.txAudit }
+
+func (c *clientContext) SigningPolicySigner() (*credentials.RotatingSigner, error) {
+	c.signerMu.Lock()
+	defer c.signerMu.Unlock()
+
+	if c.signingPolicySigner != nil {
+		return c.signingPolicySigner, nil
+	}
+	signer, err := NewSigningPolicySigner(&c.config.Credentials)
+	if err != nil {
+		return nil, err
+	}
+	c.signingPolicySigner = signer
+	return c.signingPolicySigner, nil
+}
+
+// NewSigningPolicySigner creates the signing policy signer of the credentials, rotated
+// to the next signing policy key if one is staged
+func NewSigningPolicySigner(creds *config.CredentialsConfig) (*credentials.RotatingSigner, error) {
+	current, err := globalConfig.SignerFromConfig(&creds.SigningPolicySigner,
+		creds.SigningPolicyPrivateKeyFile, creds.SigningPolicyPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	var next credentials.Signer
+	if creds.NextSigningPolicyKeyConfigured() {
+		next, err = globalConfig.SignerFromConfig(&creds.NextSigningPolicySigner,
+			creds.NextSigningPolicyPrivateKeyFile, creds.NextSigningPolicyPrivateKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating next signing policy signer")
+		}
+		logger.Info("Signing policy key %s is replaced by %s from reward epoch %d",
+			current.Address().Hex(), next.Address().Hex(), creds.NextSigningPolicyRewardEpoch)
+	}
+	return credentials.NewRotatingSigner(current, next, creds.NextSigningPolicyRewardEpoch), nil
+}
+
+func (c *clientContext) Close() {
+	c.pool.close()
+	if c.db == nil {
+		return
+	}
+	c.stopDBMonitor()
+	if c.txAudit != nil {
+		chain.SetTxAuditLog(nil)
+	}
+	if sqlDB, err := c.db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			logger.Warn("Error closing database connection: %v", err)
+		}
+	}
+}
+
 func parseFlags() *ClientFlags {
-	cfgFlag := flag.String("config", globalConfig.CONFIG_FILE, "Configuration file (toml format)")
+	flags := RegisterFlags(flag.CommandLine)
 	flag.Parse()
+	return flags
+}
 
-	return &ClientFlags{
-		ConfigFileName: *cfgFlag,
-	}
+// Registers the client flags to the flag set, the returned flags are set when
+// the flag set is parsed
+func RegisterFlags(fs *flag.FlagSet) *ClientFlags {
+	flags := &ClientFlags{}
+	fs.StringVar(&flags.ConfigFileName, "config", globalConfig.CONFIG_FILE, "Configuration file (toml format)")
+	fs.BoolVar(&flags.DryRun, "dry-run", false, "Simulate transactions with eth_call and eth_estimateGas instead of broadcasting them")
+	fs.BoolVar(&flags.Observer, "observer", false, "Run the listeners, signature verification and finalization detection without sending transactions")
+	return flags
 }
@@ -0,0 +1,232 @@
+package diagnostics
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/database"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/fastupdater"
+	"flare-tlc/utils/contracts/registry"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/credentials"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const DefaultMaxIndexerLag = time.Minute
+
+// Test networks on which the self-check sends transactions
+var testnetChainIDs = map[int64]string{
+	16:  "Coston",
+	114: "Coston2",
+}
+
+// View method called on each configured contract, by config key of the contract
+var contractCalls = map[string]struct {
+	metaData *bind.MetaData
+	method   string
+}{
+	"submission":      {submission.SubmissionMetaData, "getCurrentRandom"},
+	"systems_manager": {system.FlareSystemsManagerMetaData, "getCurrentRewardEpochId"},
+	"voter_registry":  {registry.RegistryMetaData, "maxVoters"},
+	"relay":           {relay.RelayMetaData, "getRandomNumber"},
+	"fast_updater":    {fastupdater.FastUpdaterMetaData, "currentRewardEpochId"},
+}
+
+type doctorChainClient interface {
+	chainClient
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Doctor runs an end-to-end self-check of the client on a test network: it sends a
+// zero-value transaction from each account to itself, calls each contract and
+// measures the lag of the indexer database behind the chain head
+type Doctor struct {
+	cfg           *config.ClientConfig
+	chain         doctorChainClient
+	connectDB     func(cfg *globalConfig.DBConfig) (*gorm.DB, error)
+	sendSelfTx    func(signer credentials.Signer) error
+	maxIndexerLag time.Duration
+}
+
+func NewDoctor(cfg *config.ClientConfig, maxIndexerLag time.Duration) (*Doctor, error) {
+	chainCfg := cfg.ChainConfig()
+	ethClient, err := chainCfg.DialETH()
+	if err != nil {
+		return nil, errors.Wrap(err, "error dialing eth rpc")
+	}
+	sendSelfTx := func(signer credentials.Signer) error {
+		return chain.SendRawTx(ethClient, signer, signer.Address(), nil, false, &cfg.SubmitGas, config.RetryOpSelfCheck)
+	}
+	return &Doctor{
+		cfg:           cfg,
+		chain:         ethClient,
+		connectDB:     database.Connect,
+		sendSelfTx:    sendSelfTx,
+		maxIndexerLag: maxIndexerLag,
+	}, nil
+}
+
+// Runs all checks. No transactions are sent unless the rpc node is a test network
+// with the configured chain id.
+func (d *Doctor) Run(ctx context.Context) *Report {
+	report := &Report{}
+
+	network, err := d.checkNetwork(ctx)
+	if err != nil {
+		report.add("network", err, "doctor only runs on Coston (chain id 16) and Coston2 (chain id 114), check chain.chain_id and chain.eth_rpc_url")
+		return report
+	}
+	report.addOk("network", network)
+
+	if d.cfg.ContractRegistry.Enabled() {
+		report.add("contract registry", resolveContractAddresses(ctx, d.chain, d.cfg),
+			"check contract_registry.address or set the addresses in contract_addresses")
+	}
+	for _, contract := range configuredContracts(d.cfg) {
+		name := "eth_call " + contract.name
+		result, err := d.callContract(ctx, contract)
+		if err == nil {
+			report.addOk(name, result)
+		} else {
+			report.add(name, err, "check contract_addresses."+contract.key)
+		}
+	}
+
+	senders := make(map[common.Address]string)
+	for _, check := range signerChecks(d.cfg) {
+		name := "self tx " + check.name
+		signer, err := check.signer(&d.cfg.Credentials)
+		if err != nil {
+			report.add(name, err, "check credentials."+check.key)
+			continue
+		}
+		if other, ok := senders[signer.Address()]; ok {
+			report.skip(name, "same account as the "+other)
+			continue
+		}
+		senders[signer.Address()] = check.name
+
+		start := time.Now()
+		if err := d.sendSelfTx(signer); err != nil {
+			report.add(name, errors.Wrapf(err, "account %s", signer.Address().Hex()),
+				"check the balance of the account, fund it from the testnet faucet")
+		} else {
+			report.addOk(name, fmt.Sprintf("account %s, mined in %s", signer.Address().Hex(), time.Since(start).Round(time.Millisecond)))
+		}
+	}
+
+	if d.cfg.Listeners.RPCSource() {
+		report.skip("indexer lag", "listeners use the rpc source")
+	} else {
+		lag, err := d.indexerLag(ctx)
+		if err == nil {
+			report.addOk("indexer lag", lag)
+		} else {
+			report.add("indexer lag", err, "check the db section and that the indexer is running and synced")
+		}
+	}
+	return report
+}
+
+// Returns the name of the test network, an error if the rpc node is not a test
+// network or its chain id is not the configured one
+func (d *Doctor) checkNetwork(ctx context.Context) (string, error) {
+	chainID, err := d.chain.ChainID(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "error fetching chain id")
+	}
+	if chainID.Cmp(big.NewInt(int64(d.cfg.Chain.ChainID))) != 0 {
+		return "", errors.Errorf("configured chain id %d, rpc chain id %s", d.cfg.Chain.ChainID, chainID)
+	}
+	network, ok := testnetChainIDs[chainID.Int64()]
+	if !ok {
+		return "", errors.Errorf("chain id %s is not a test network", chainID)
+	}
+	return fmt.Sprintf("%s (chain id %s)", network, chainID), nil
+}
+
+// Calls the view method of the contract, returns the method and the result values
+func (d *Doctor) callContract(ctx context.Context, contract configuredContract) (string, error) {
+	if contract.address == (common.Address{}) {
+		return "", errors.New("address is not set")
+	}
+	call, ok := contractCalls[contract.key]
+	if !ok {
+		return "", errors.Errorf("no view method for contract %s", contract.name)
+	}
+	abi, err := call.metaData.GetAbi()
+	if err != nil {
+		return "", err
+	}
+	input, err := abi.Pack(call.method)
+	if err != nil {
+		return "", err
+	}
+	output, err := d.chain.CallContract(ctx, ethereum.CallMsg{To: &contract.address, Data: input}, nil)
+	if err != nil {
+		return "", errors.Wrapf(err, "error calling %s", call.method)
+	}
+	values, err := abi.Unpack(call.method, output)
+	if err != nil {
+		return "", errors.Wrapf(err, "error unpacking %s result", call.method)
+	}
+	results := make([]string, len(values))
+	for i, value := range values {
+		results[i] = fmt.Sprint(value)
+	}
+	return fmt.Sprintf("%s() = %s", call.method, strings.Join(results, ", ")), nil
+}
+
+// Compares the latest indexed transaction with the chain head, an error if the
+// indexer is more than maxIndexerLag behind
+func (d *Doctor) indexerLag(ctx context.Context) (string, error) {
+	db, err := d.connectDB(&d.cfg.DB)
+	if err != nil {
+		return "", errors.Wrap(err, "error connecting to the database")
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	head, err := d.chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "error fetching chain head")
+	}
+	latest, err := database.FetchLatestTransaction(db)
+	if err != nil {
+		return "", errors.Wrap(err, "error fetching latest indexed transaction")
+	}
+	if latest == nil {
+		return "", errors.New("no indexed transactions")
+	}
+	return indexerLag(head, latest, d.maxIndexerLag)
+}
+
+func indexerLag(head *types.Header, latest *database.Transaction, maxLag time.Duration) (string, error) {
+	var blocks uint64
+	if head.Number.Uint64() > latest.BlockNumber {
+		blocks = head.Number.Uint64() - latest.BlockNumber
+	}
+	var lag time.Duration
+	if head.Time > latest.Timestamp {
+		lag = time.Duration(head.Time-latest.Timestamp) * time.Second
+	}
+	detail := fmt.Sprintf("%d blocks, %s behind chain head %d", blocks, lag, head.Number)
+	if maxLag > 0 && lag > maxLag {
+		return "", errors.Errorf("%s, more than %s", detail, maxLag)
+	}
+	return detail, nil
+}
@@ -0,0 +1,87 @@
+package diagnostics
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/database"
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func testDoctor(t *testing.T) (*Doctor, *testChainClient, *[]common.Address) {
+	validator, chain := testValidator(t)
+	validator.cfg.Chain.ChainID = 114
+	chain.chainID = 114
+	chain.callResults = make(map[common.Address][]byte)
+	for _, contract := range configuredContracts(validator.cfg) {
+		chain.callResults[contract.address] = make([]byte, 96)
+	}
+	chain.head = &types.Header{Number: big.NewInt(1000), Time: 2000}
+
+	var sent []common.Address
+	doctor := &Doctor{
+		cfg:       validator.cfg,
+		chain:     chain,
+		connectDB: validator.connectDB,
+		sendSelfTx: func(signer credentials.Signer) error {
+			sent = append(sent, signer.Address())
+			return nil
+		},
+		maxIndexerLag: DefaultMaxIndexerLag,
+	}
+	return doctor, chain, &sent
+}
+
+func TestDoctor(t *testing.T) {
+	doctor, chain, sent := testDoctor(t)
+	report := doctor.Run(context.Background())
+	require.Equal(t, []string{"indexer lag"}, failedChecks(report))
+	require.Equal(t, CheckResult{Name: "network", Status: statusOk, Detail: "Coston2 (chain id 114)"}, report.Results[0])
+	require.Equal(t, "getCurrentRewardEpochId() = 0", report.Results[2].Detail)
+	require.Len(t, *sent, 3)
+
+	// the same account is sent from once, failed txs are reported
+	doctor.cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKey = doctor.cfg.Credentials.ProtocolManagerSubmitPrivateKey
+	doctor.cfg.Listeners.Source = config.ListenerSourceRPC
+	doctor.sendSelfTx = func(signer credentials.Signer) error {
+		return errors.New("insufficient funds")
+	}
+	delete(chain.callResults, common.HexToAddress("0x04"))
+	report = doctor.Run(context.Background())
+	require.Equal(t, []string{
+		"eth_call Relay",
+		"self tx signing policy key",
+		"self tx submit key",
+	}, failedChecks(report))
+	require.Equal(t, CheckResult{Name: "self tx submit signatures key", Status: statusSkipped, Detail: "same account as the submit key"},
+		report.Results[len(report.Results)-2])
+}
+
+func TestDoctorMainnet(t *testing.T) {
+	doctor, chain, sent := testDoctor(t)
+	doctor.cfg.Chain.ChainID = 14
+	chain.chainID = 14
+
+	report := doctor.Run(context.Background())
+	require.Equal(t, []string{"network"}, failedChecks(report))
+	require.Len(t, report.Results, 1)
+	require.Empty(t, *sent)
+}
+
+func TestIndexerLag(t *testing.T) {
+	head := &types.Header{Number: big.NewInt(1000), Time: 2000}
+
+	lag, err := indexerLag(head, &database.Transaction{BlockNumber: 990, Timestamp: 1980}, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "10 blocks, 20s behind chain head 1000", lag)
+
+	_, err = indexerLag(head, &database.Transaction{BlockNumber: 900, Timestamp: 1800}, time.Minute)
+	require.EqualError(t, err, "100 blocks, 3m20s behind chain head 1000, more than 1m0s")
+}
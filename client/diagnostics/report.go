@@ -0,0 +1,65 @@
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	statusOk      = "OK"
+	statusFail    = "FAIL"
+	statusSkipped = "SKIP"
+)
+
+type CheckResult struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string // what to check in the config if the check failed
+}
+
+type Report struct {
+	Results []CheckResult
+}
+
+func (r *Report) add(name string, err error, hint string) {
+	if err != nil {
+		r.Results = append(r.Results, CheckResult{Name: name, Status: statusFail, Detail: err.Error(), Hint: hint})
+	} else {
+		r.addOk(name, "")
+	}
+}
+
+func (r *Report) addOk(name string, detail string) {
+	r.Results = append(r.Results, CheckResult{Name: name, Status: statusOk, Detail: detail})
+}
+
+func (r *Report) skip(name string, reason string) {
+	r.Results = append(r.Results, CheckResult{Name: name, Status: statusSkipped, Detail: reason})
+}
+
+// Failed returns the number of failed checks
+func (r *Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if result.Status == statusFail {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Print writes one line per check, with the hint for failed checks
+func (r *Report) Print(w io.Writer) {
+	for _, result := range r.Results {
+		line := fmt.Sprintf("[%s] %s", result.Status, result.Name)
+		if len(result.Detail) > 0 {
+			line += ": " + result.Detail
+		}
+		fmt.Fprintln(w, line)
+		if len(result.Hint) > 0 {
+			fmt.Fprintf(w, "       hint: %s\n", result.Hint)
+		}
+	}
+	fmt.Fprintf(w, "\n%d checks, %d failed\n", len(r.Results), r.Failed())
+}
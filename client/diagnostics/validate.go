@@ -0,0 +1,323 @@
+package diagnostics
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/database"
+	"flare-tlc/utils/contracts/entitymanager"
+	"flare-tlc/utils/contracts/registry"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+type chainClient interface {
+	bind.ContractCaller
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Validator checks the config against the chain, the contracts and the database
+type Validator struct {
+	cfg       *config.ClientConfig
+	chain     chainClient
+	connectDB func(cfg *globalConfig.DBConfig) (*gorm.DB, error)
+}
+
+func NewValidator(cfg *config.ClientConfig) (*Validator, error) {
+	chainCfg := cfg.ChainConfig()
+	ethClient, err := chainCfg.DialETH()
+	if err != nil {
+		return nil, errors.Wrap(err, "error dialing eth rpc")
+	}
+	return &Validator{cfg: cfg, chain: ethClient, connectDB: database.Connect}, nil
+}
+
+// Key of the config and the voter address registered for it in the EntityManager,
+// address is nil for keys that are not registered
+type signerCheck struct {
+	name    string
+	key     string // config key of the credentials
+	address func(addresses *entitymanager.IEntityManagerVoterAddresses) common.Address
+	signer  func(cfg *config.CredentialsConfig) (credentials.Signer, error)
+}
+
+// Runs all checks, a failed check does not stop the following checks unless
+// they depend on it
+func (v *Validator) Validate(ctx context.Context) *Report {
+	report := &Report{}
+
+	report.add("chain id", v.checkChainID(ctx), "check chain.chain_id and chain.eth_rpc_url")
+	report.add("contract bindings", shared.ValidateContractABIs(&v.cfg.Clients), "regenerate the bindings with make bindings")
+
+	if v.cfg.ContractRegistry.Enabled() {
+		report.add("contract registry", resolveContractAddresses(ctx, v.chain, v.cfg),
+			"check contract_registry.address or set the addresses in contract_addresses")
+	}
+	contractsOk := true
+	for _, contract := range configuredContracts(v.cfg) {
+		err := v.checkContract(ctx, contract.address)
+		contractsOk = contractsOk && err == nil
+		report.add("contract "+contract.name+" "+contract.address.Hex(), err, "check contract_addresses."+contract.key)
+	}
+
+	if preset, ok := config.NetworkPresetByName(v.cfg.Network); ok {
+		name := "network " + preset.Name + " epochs"
+		if contractsOk {
+			report.add(name, v.checkNetworkEpochs(ctx, preset), "check network and chain.eth_rpc_url")
+		} else {
+			report.skip(name, "contract checks failed")
+		}
+	}
+
+	signers := make(map[string]credentials.Signer)
+	for _, check := range signerChecks(v.cfg) {
+		signer, err := check.signer(&v.cfg.Credentials)
+		if err == nil {
+			signers[check.name] = signer
+			report.addOk(check.name, signer.Address().Hex())
+		} else {
+			report.add(check.name, err, "check credentials."+check.key)
+		}
+	}
+
+	if v.cfg.Clients.EnabledFastUpdates {
+		key, err := globalConfig.SortitionKeyFromConfig(v.cfg.Credentials.SortitionPrivateKeyFile, v.cfg.Credentials.SortitionPrivateKey)
+		if err == nil {
+			part1, part2 := key.PublicKeyParts()
+			report.addOk("sortition key", hexutil.Encode(part1[:])+" "+hexutil.Encode(part2[:]))
+		} else {
+			report.add("sortition key", err, "check credentials.sortition_private_key_file")
+		}
+	}
+
+	if v.cfg.Identity.Address == (common.Address{}) {
+		report.add("voter identity", errors.New("identity address is not set"), "check identity.address")
+	} else if !contractsOk {
+		report.skip("voter identity", "contract checks failed")
+	} else {
+		v.checkVoterAddresses(ctx, report, signers)
+	}
+
+	if v.cfg.Listeners.RPCSource() {
+		report.skip("database", "listeners use the rpc source")
+	} else {
+		report.add("database", v.checkDatabase(), "check the db section and that the indexer is running")
+	}
+	return report
+}
+
+func (v *Validator) checkChainID(ctx context.Context) error {
+	chainID, err := v.chain.ChainID(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error fetching chain id")
+	}
+	if chainID.Cmp(big.NewInt(int64(v.cfg.Chain.ChainID))) != 0 {
+		return errors.Errorf("configured chain id %d, rpc chain id %s", v.cfg.Chain.ChainID, chainID)
+	}
+	return nil
+}
+
+// Compares the epoch settings of the Relay contract with the network preset
+func (v *Validator) checkNetworkEpochs(ctx context.Context, preset *config.NetworkPreset) error {
+	relayCaller, err := relay.NewRelayCaller(v.cfg.ContractAddresses.Relay, v.chain)
+	if err != nil {
+		return err
+	}
+	stateData, err := relayCaller.StateData(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return errors.Wrap(err, "error fetching relay state data")
+	}
+	votingEpochDuration := time.Duration(stateData.VotingEpochDurationSeconds) * time.Second
+	if votingEpochDuration != preset.VotingEpochDuration {
+		return errors.Errorf("voting epoch duration %v, expected %v", votingEpochDuration, preset.VotingEpochDuration)
+	}
+	if int64(stateData.RewardEpochDurationInVotingEpochs) != preset.RewardEpochVotingEpochs {
+		return errors.Errorf("reward epoch duration %d voting epochs, expected %d",
+			stateData.RewardEpochDurationInVotingEpochs, preset.RewardEpochVotingEpochs)
+	}
+	return nil
+}
+
+func resolveContractAddresses(ctx context.Context, caller bind.ContractCaller, cfg *config.ClientConfig) error {
+	resolver, err := shared.NewContractResolver(caller, cfg.ContractRegistry.Address, cfg.ContractAddresses)
+	if err != nil {
+		return err
+	}
+	addresses, err := resolver.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	cfg.ContractAddresses = addresses
+	return nil
+}
+
+type configuredContract struct {
+	name    string
+	key     string
+	address common.Address
+}
+
+func configuredContracts(cfg *config.ClientConfig) []configuredContract {
+	addresses := cfg.ContractAddresses
+	contracts := []configuredContract{
+		{shared.SubmissionContractName, "submission", addresses.Submission},
+		{shared.SystemsManagerContractName, "systems_manager", addresses.SystemsManager},
+		{shared.VoterRegistryContractName, "voter_registry", addresses.VoterRegistry},
+		{shared.RelayContractName, "relay", addresses.Relay},
+	}
+	if cfg.Clients.EnabledFastUpdates {
+		contracts = append(contracts, configuredContract{"FastUpdater", "fast_updater", addresses.FastUpdater})
+	}
+	return contracts
+}
+
+func (v *Validator) checkContract(ctx context.Context, address common.Address) error {
+	if address == (common.Address{}) {
+		return errors.New("address is not set")
+	}
+	code, err := v.chain.CodeAt(ctx, address, nil)
+	if err != nil {
+		return errors.Wrap(err, "error fetching contract code")
+	}
+	if len(code) == 0 {
+		return errors.New("no contract code at the address")
+	}
+	return nil
+}
+
+// Keys used by the enabled clients
+func signerChecks(cfg *config.ClientConfig) []signerCheck {
+	clients := &cfg.Clients
+	var checks []signerCheck
+	if clients.EpochClientEnabled() || clients.EnabledProtocolVoting || clients.EnabledFinalizer || clients.EnabledFastUpdates {
+		checks = append(checks, signerCheck{
+			name: "signing policy key",
+			key:  "signing_policy_private_key_file",
+			address: func(a *entitymanager.IEntityManagerVoterAddresses) common.Address {
+				return a.SigningPolicyAddress
+			},
+			signer: func(c *config.CredentialsConfig) (credentials.Signer, error) {
+				return globalConfig.SignerFromConfig(&c.SigningPolicySigner, c.SigningPolicyPrivateKeyFile, c.SigningPolicyPrivateKey)
+			},
+		})
+	}
+	if clients.EpochClientEnabled() {
+		checks = append(checks, signerCheck{
+			name: "system client sender key",
+			key:  "system_client_sender_private_key_file",
+			signer: func(c *config.CredentialsConfig) (credentials.Signer, error) {
+				return globalConfig.SignerFromConfig(&c.SystemClientSenderSigner, c.SystemClientSenderPrivateKeyFile, c.SystemClientSenderPrivateKey)
+			},
+		})
+	}
+	if clients.EnabledProtocolVoting || clients.EnabledFastUpdates {
+		checks = append(checks, signerCheck{
+			name: "submit key",
+			key:  "protocol_manager_submit_private_key_file",
+			address: func(a *entitymanager.IEntityManagerVoterAddresses) common.Address {
+				return a.SubmitAddress
+			},
+			signer: func(c *config.CredentialsConfig) (credentials.Signer, error) {
+				return globalConfig.SignerFromConfig(&c.ProtocolManagerSubmitSigner,
+					c.ProtocolManagerSubmitPrivateKeyFile, c.ProtocolManagerSubmitPrivateKey)
+			},
+		})
+	}
+	if clients.EnabledProtocolVoting {
+		checks = append(checks, signerCheck{
+			name: "submit signatures key",
+			key:  "protocol_manager_submit_signatures_private_key_file",
+			address: func(a *entitymanager.IEntityManagerVoterAddresses) common.Address {
+				return a.SubmitSignaturesAddress
+			},
+			signer: func(c *config.CredentialsConfig) (credentials.Signer, error) {
+				return globalConfig.SignerFromConfig(&c.ProtocolManagerSubmitSignaturesSigner,
+					c.ProtocolManagerSubmitSignaturesPrivateKeyFile, c.ProtocolManagerSubmitSignaturesPrivateKey)
+			},
+		})
+	}
+	return checks
+}
+
+// Checks that the keys match the addresses registered for the identity in the EntityManager
+func (v *Validator) checkVoterAddresses(ctx context.Context, report *Report, signers map[string]credentials.Signer) {
+	addresses, err := v.voterAddresses(ctx)
+	if err != nil {
+		report.add("voter identity", err, "check contract_addresses.voter_registry")
+		return
+	}
+	report.addOk("voter identity", v.cfg.Identity.Address.Hex())
+
+	for _, check := range signerChecks(v.cfg) {
+		if check.address == nil {
+			continue
+		}
+		name := check.name + " address"
+		signer, ok := signers[check.name]
+		if !ok {
+			report.skip(name, "key is not valid")
+			continue
+		}
+		registered := check.address(addresses)
+		if signer.Address() != registered {
+			report.add(name, errors.Errorf("key address %s, registered address %s", signer.Address().Hex(), registered.Hex()),
+				"check credentials."+check.key+" and identity.address")
+		} else {
+			report.addOk(name, registered.Hex())
+		}
+	}
+}
+
+func (v *Validator) voterAddresses(ctx context.Context) (*entitymanager.IEntityManagerVoterAddresses, error) {
+	voterRegistry, err := registry.NewRegistryCaller(v.cfg.ContractAddresses.VoterRegistry, v.chain)
+	if err != nil {
+		return nil, err
+	}
+	entityManagerAddress, err := voterRegistry.EntityManager(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching entity manager address")
+	}
+	entityManager, err := entitymanager.NewEntityManagerCaller(entityManagerAddress, v.chain)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := entityManager.GetVoterAddresses(&bind.CallOpts{Context: ctx}, v.cfg.Identity.Address)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching voter addresses")
+	}
+	if addresses == (entitymanager.IEntityManagerVoterAddresses{}) {
+		return nil, errors.Errorf("voter %s is not registered in the EntityManager", v.cfg.Identity.Address.Hex())
+	}
+	return &addresses, nil
+}
+
+// Checks that the database is reachable and has the indexer tables
+func (v *Validator) checkDatabase() error {
+	db, err := v.connectDB(&v.cfg.DB)
+	if err != nil {
+		return errors.Wrap(err, "error connecting to the database")
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	for _, table := range []interface{}{&database.Transaction{}, &database.Log{}} {
+		if !db.Migrator().HasTable(table) {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(table); err != nil {
+				return err
+			}
+			return errors.Errorf("table %s does not exist", stmt.Schema.Table)
+		}
+	}
+	return nil
+}
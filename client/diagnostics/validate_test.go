@@ -0,0 +1,207 @@
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"flare-tlc/client/config"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/utils/contracts/entitymanager"
+	"flare-tlc/utils/contracts/registry"
+	"flare-tlc/utils/contracts/relay"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var (
+	testEntityManagerAddress = common.HexToAddress("0x1000")
+	testVoterRegistryAddress = common.HexToAddress("0x03")
+)
+
+type testChainClient struct {
+	chainID        int64
+	code           map[common.Address][]byte
+	voterAddresses entitymanager.IEntityManagerVoterAddresses
+	callResults    map[common.Address][]byte
+	head           *types.Header
+}
+
+func (c *testChainClient) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(c.chainID), nil
+}
+
+func (c *testChainClient) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.code[contract], nil
+}
+
+func (c *testChainClient) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	switch *call.To {
+	case testVoterRegistryAddress:
+		abi, err := registry.RegistryMetaData.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		return abi.Methods["entityManager"].Outputs.Pack(testEntityManagerAddress)
+	case testEntityManagerAddress:
+		abi, err := entitymanager.EntityManagerMetaData.GetAbi()
+		if err != nil {
+			return nil, err
+		}
+		return abi.Methods["getVoterAddresses"].Outputs.Pack(c.voterAddresses)
+	}
+	if result, ok := c.callResults[*call.To]; ok {
+		return result, nil
+	}
+	return nil, errors.Errorf("unexpected call to %s", call.To.Hex())
+}
+
+func (c *testChainClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.head, nil
+}
+
+func testKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return pk, hex.EncodeToString(crypto.FromECDSA(pk))
+}
+
+func testValidator(t *testing.T) (*Validator, *testChainClient) {
+	signingPolicyKey, signingPolicyKeyHex := testKey(t)
+	submitKey, submitKeyHex := testKey(t)
+	submitSignaturesKey, submitSignaturesKeyHex := testKey(t)
+
+	cfg := &config.ClientConfig{
+		Chain: globalConfig.ChainConfig{ChainID: 162},
+		ContractAddresses: globalConfig.ContractAddresses{
+			Submission:     common.HexToAddress("0x01"),
+			SystemsManager: common.HexToAddress("0x02"),
+			VoterRegistry:  testVoterRegistryAddress,
+			Relay:          common.HexToAddress("0x04"),
+		},
+		Identity: config.IdentityConfig{Address: common.HexToAddress("0x2000")},
+		Credentials: config.CredentialsConfig{
+			SigningPolicyPrivateKey:                   signingPolicyKeyHex,
+			ProtocolManagerSubmitPrivateKey:           submitKeyHex,
+			ProtocolManagerSubmitSignaturesPrivateKey: submitSignaturesKeyHex,
+		},
+		Clients: config.ClientsConfig{EnabledProtocolVoting: true},
+	}
+	chain := &testChainClient{
+		chainID: 162,
+		code: map[common.Address][]byte{
+			common.HexToAddress("0x01"): {1},
+			common.HexToAddress("0x02"): {1},
+			testVoterRegistryAddress:    {1},
+			common.HexToAddress("0x04"): {1},
+		},
+		voterAddresses: entitymanager.IEntityManagerVoterAddresses{
+			SubmitAddress:           crypto.PubkeyToAddress(submitKey.PublicKey),
+			SubmitSignaturesAddress: crypto.PubkeyToAddress(submitSignaturesKey.PublicKey),
+			SigningPolicyAddress:    crypto.PubkeyToAddress(signingPolicyKey.PublicKey),
+		},
+	}
+	validator := &Validator{
+		cfg:   cfg,
+		chain: chain,
+		connectDB: func(cfg *globalConfig.DBConfig) (*gorm.DB, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+	return validator, chain
+}
+
+func failedChecks(report *Report) []string {
+	var names []string
+	for _, result := range report.Results {
+		if result.Status == statusFail {
+			names = append(names, result.Name)
+		}
+	}
+	return names
+}
+
+func TestValidate(t *testing.T) {
+	validator, _ := testValidator(t)
+	report := validator.Validate(context.Background())
+	require.Equal(t, []string{"database"}, failedChecks(report))
+	require.Len(t, report.Results, 14)
+
+	var out bytes.Buffer
+	report.Print(&out)
+	require.Contains(t, out.String(), "[OK] submit key address")
+	require.Contains(t, out.String(), "[FAIL] database: error connecting to the database: connection refused\n       hint: ")
+	require.True(t, strings.HasSuffix(out.String(), "14 checks, 1 failed\n"))
+}
+
+func TestValidateMismatch(t *testing.T) {
+	validator, chain := testValidator(t)
+	chain.chainID = 14
+	delete(chain.code, common.HexToAddress("0x04"))
+	validator.cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKey = "invalid"
+
+	report := validator.Validate(context.Background())
+	require.Equal(t, []string{
+		"chain id",
+		"contract Relay 0x0000000000000000000000000000000000000004",
+		"submit signatures key",
+		"database",
+	}, failedChecks(report))
+
+	// voter addresses are checked only if the contracts are valid
+	chain.code[common.HexToAddress("0x04")] = []byte{1}
+	chain.voterAddresses.SubmitAddress = common.HexToAddress("0x05")
+	report = validator.Validate(context.Background())
+	require.Equal(t, []string{
+		"chain id",
+		"submit signatures key",
+		"submit key address",
+		"database",
+	}, failedChecks(report))
+}
+
+func TestValidateRPCSource(t *testing.T) {
+	validator, _ := testValidator(t)
+	validator.cfg.Listeners.Source = config.ListenerSourceRPC
+
+	report := validator.Validate(context.Background())
+	require.Empty(t, failedChecks(report))
+	require.Equal(t, CheckResult{Name: "database", Status: statusSkipped, Detail: "listeners use the rpc source"},
+		report.Results[len(report.Results)-1])
+}
+
+func TestValidateNetwork(t *testing.T) {
+	validator, chain := testValidator(t)
+	validator.cfg.Network = "coston2"
+	validator.cfg.Chain.ChainID = 114
+	chain.chainID = 114
+
+	relayABI, err := relay.RelayMetaData.GetAbi()
+	require.NoError(t, err)
+	stateData := func(votingEpochDurationSeconds uint8, rewardEpochDurationInVotingEpochs uint16) []byte {
+		data, err := relayABI.Methods["stateData"].Outputs.Pack(
+			uint8(15), uint32(1658430000), votingEpochDurationSeconds, uint32(0), rewardEpochDurationInVotingEpochs,
+			uint16(0), uint32(0), false, uint32(0), false, uint32(0),
+		)
+		require.NoError(t, err)
+		return data
+	}
+
+	chain.callResults = map[common.Address][]byte{common.HexToAddress("0x04"): stateData(90, 240)}
+	report := validator.Validate(context.Background())
+	require.Equal(t, []string{"database"}, failedChecks(report))
+	require.Contains(t, report.Results, CheckResult{Name: "network coston2 epochs", Status: statusOk})
+
+	chain.callResults[common.HexToAddress("0x04")] = stateData(90, 3360)
+	report = validator.Validate(context.Background())
+	require.Equal(t, []string{"network coston2 epochs", "database"}, failedChecks(report))
+}
@@ -0,0 +1,267 @@
+package entity
+
+import (
+	"context"
+	clientContext "flare-tlc/client/context"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/sortition"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+const ensureRegisteredTimeout = 30 * time.Second
+
+// Registration of the voter addresses and the sortition public key of the identity
+// in the EntityManager, run by the entity subcommands. The identity proposes the
+// addresses and registers the public key, each address confirms its registration.
+type entityClient struct {
+	ctx      clientContext.ClientContext
+	contract entityManagerContractClient
+	identity common.Address
+}
+
+func newEntityClient(ctx context.Context, clientCtx clientContext.ClientContext) (*entityClient, error) {
+	cfg := clientCtx.Config()
+	if cfg.Identity.Address == (common.Address{}) {
+		return nil, errors.New("identity.address is not set")
+	}
+	cl, err := clientCtx.EthClient()
+	if err != nil {
+		return nil, err
+	}
+	contract, err := newEntityManagerContractClient(ctx, cl, cfg.ContractAddresses.VoterRegistry, &cfg.RegisterGas)
+	if err != nil {
+		return nil, err
+	}
+	return &entityClient{
+		ctx:      clientCtx,
+		contract: contract,
+		identity: cfg.Identity.Address,
+	}, nil
+}
+
+// Addresses of the configured keys of the roles. Keys that cannot be loaded and,
+// if interactive is false, keystore keys with a passphrase prompt are skipped.
+func (c *entityClient) localAddresses(roles []*Role, interactive bool) map[*Role]common.Address {
+	creds := &c.ctx.Config().Credentials
+	addresses := make(map[*Role]common.Address)
+	for _, role := range roles {
+		signerCfg, _, _ := role.signer(creds)
+		if !interactive && promptsPassphrase(signerCfg) {
+			logger.Debug("Skipping %s address, the keystore passphrase is prompted for", role.Name)
+			continue
+		}
+		signer, err := role.Signer(creds)
+		if err != nil {
+			logger.Debug("Skipping %s address: %v", role.Name, err)
+			continue
+		}
+		addresses[role] = signer.Address()
+	}
+	return addresses
+}
+
+func promptsPassphrase(signerCfg *globalConfig.SignerConfig) bool {
+	return signerCfg.Type == globalConfig.SignerTypeKeystore &&
+		len(signerCfg.PassphraseFile) == 0 && len(signerCfg.PassphraseEnv) == 0
+}
+
+// Nil if the sortition key is not configured
+func (c *entityClient) sortitionKey() (*sortition.Key, error) {
+	creds := &c.ctx.Config().Credentials
+	if !creds.SortitionKeyConfigured() {
+		return nil, nil
+	}
+	key, err := globalConfig.SortitionKeyFromConfig(creds.SortitionPrivateKeyFile, creds.SortitionPrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading sortition key")
+	}
+	return key, nil
+}
+
+// Transact opts of the identity key, which must be the key of identity.address
+func (c *entityClient) identityTxOpts() (*bind.TransactOpts, error) {
+	cfg := c.ctx.Config()
+	signer, err := globalConfig.SignerFromConfig(&cfg.Credentials.IdentitySigner,
+		cfg.Credentials.IdentityPrivateKeyFile, cfg.Credentials.IdentityPrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating identity signer")
+	}
+	if signer.Address() != c.identity {
+		return nil, errors.Errorf("identity key address %s is not identity.address %s", signer.Address().Hex(), c.identity.Hex())
+	}
+	return credentials.TransactOptsFromSigner(signer, cfg.ChainConfig().ChainID), nil
+}
+
+// Registration status of the roles with a configured key and of the sortition key
+func FetchStatus(clientCtx clientContext.ClientContext, roles []*Role) (*Status, error) {
+	ctx := context.Background()
+	c, err := newEntityClient(ctx, clientCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	sortitionKey, err := c.sortitionKey()
+	if err != nil {
+		return nil, err
+	}
+	return fetchStatus(ctx, c.contract, c.identity, c.localAddresses(roles, true), sortitionKey)
+}
+
+// Propose the addresses of the configured keys of the roles, sent by the identity
+func Propose(clientCtx clientContext.ClientContext, roles []*Role) error {
+	ctx := context.Background()
+	c, err := newEntityClient(ctx, clientCtx)
+	if err != nil {
+		return err
+	}
+
+	status, _, err := c.rolesStatus(ctx, roles)
+	if err != nil {
+		return err
+	}
+	sender, err := c.identityTxOpts()
+	if err != nil {
+		return err
+	}
+	for _, role := range status.Roles {
+		if role.Ok() || role.Proposed == role.Local {
+			logger.Info("%s address %s is already proposed", role.Role.Name, role.Local.Hex())
+			continue
+		}
+		if err := c.contract.Propose(ctx, role.Role, sender, role.Local); err != nil {
+			return errors.Wrapf(err, "error proposing %s address", role.Role.Name)
+		}
+		logger.Info("Proposed %s address %s for identity %s", role.Role.Name, role.Local.Hex(), c.identity.Hex())
+	}
+	return nil
+}
+
+// Confirm the registration of the proposed addresses, sent by the key of each role
+func Confirm(clientCtx clientContext.ClientContext, roles []*Role) error {
+	ctx := context.Background()
+	c, err := newEntityClient(ctx, clientCtx)
+	if err != nil {
+		return err
+	}
+
+	status, signers, err := c.rolesStatus(ctx, roles)
+	if err != nil {
+		return err
+	}
+	chainID := clientCtx.Config().ChainConfig().ChainID
+	for _, role := range status.Roles {
+		if role.Ok() {
+			logger.Info("%s address %s is already registered", role.Role.Name, role.Local.Hex())
+			continue
+		}
+		if role.Proposed != role.Local {
+			return errors.Errorf("%s address %s is not proposed by identity %s, run entity-propose first",
+				role.Role.Name, role.Local.Hex(), c.identity.Hex())
+		}
+		sender := credentials.TransactOptsFromSigner(signers[role.Role], chainID)
+		if err := c.contract.Confirm(ctx, role.Role, sender, c.identity); err != nil {
+			return errors.Wrapf(err, "error confirming %s address", role.Role.Name)
+		}
+		logger.Info("Confirmed %s address %s for identity %s", role.Role.Name, role.Local.Hex(), c.identity.Hex())
+	}
+	return nil
+}
+
+// Register the public key of the sortition key with its proof of possession, sent
+// by the identity
+func RegisterPublicKey(clientCtx clientContext.ClientContext) error {
+	ctx := context.Background()
+	c, err := newEntityClient(ctx, clientCtx)
+	if err != nil {
+		return err
+	}
+
+	sortitionKey, err := c.sortitionKey()
+	if err != nil {
+		return err
+	}
+	if sortitionKey == nil {
+		return errors.New("sortition key is not configured")
+	}
+	status, err := fetchStatus(ctx, c.contract, c.identity, nil, sortitionKey)
+	if err != nil {
+		return err
+	}
+	if status.PublicKey.Ok() {
+		logger.Info("Sortition public key is already registered")
+		return nil
+	}
+	verificationData, err := sortition.ProofOfPossession(sortitionKey, c.identity)
+	if err != nil {
+		return err
+	}
+	sender, err := c.identityTxOpts()
+	if err != nil {
+		return err
+	}
+	part1, part2 := sortitionKey.PublicKeyParts()
+	if err := c.contract.RegisterPublicKey(ctx, sender, part1, part2, verificationData); err != nil {
+		return errors.Wrap(err, "error registering public key")
+	}
+	logger.Info("Registered sortition public key for identity %s", c.identity.Hex())
+	return nil
+}
+
+// Status of the roles and their signers, all of them must have a configured key
+func (c *entityClient) rolesStatus(ctx context.Context, roles []*Role) (*Status, map[*Role]credentials.Signer, error) {
+	signers := make(map[*Role]credentials.Signer)
+	local := make(map[*Role]common.Address)
+	for _, role := range roles {
+		signer, err := role.Signer(&c.ctx.Config().Credentials)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "error creating %s signer, check credentials.%s", role.Name, role.Key)
+		}
+		signers[role] = signer
+		local[role] = signer.Address()
+	}
+	status, err := fetchStatus(ctx, c.contract, c.identity, local, nil)
+	return status, signers, err
+}
+
+// EnsureRegistered compares the configured keys with the chain state of the identity
+// and logs a warning for each divergence, it does not fail the startup
+func EnsureRegistered(clientCtx clientContext.ClientContext) {
+	ctx, cancel := context.WithTimeout(context.Background(), ensureRegisteredTimeout)
+	defer cancel()
+
+	c, err := newEntityClient(ctx, clientCtx)
+	if err != nil {
+		logger.Warn("Unable to check the EntityManager registration: %v", err)
+		return
+	}
+
+	sortitionKey, err := c.sortitionKey()
+	if err != nil {
+		logger.Warn("Unable to check the sortition public key registration: %v", err)
+	}
+	status, err := fetchStatus(ctx, c.contract, c.identity, c.localAddresses(Roles, false), sortitionKey)
+	if err != nil {
+		logger.Warn("Unable to check the EntityManager registration: %v", err)
+		return
+	}
+	logRegistrationStatus(status)
+}
+
+func logRegistrationStatus(status *Status) {
+	for _, role := range status.Roles {
+		if !role.Ok() {
+			logger.Warn("EntityManager registration of identity %s diverges from the config: %s", status.Identity.Hex(), role)
+		}
+	}
+	if status.PublicKey != nil && !status.PublicKey.Ok() {
+		logger.Warn("EntityManager registration of identity %s diverges from the config: %s", status.Identity.Hex(), status.PublicKey)
+	}
+	if status.Ok() {
+		logger.Info("EntityManager registration of identity %s matches the config", status.Identity.Hex())
+	}
+}
@@ -0,0 +1,258 @@
+package entity
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/utils/contracts/entitymanager"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/sortition"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	RoleSubmit           = "submit"
+	RoleSubmitSignatures = "submit_signatures"
+	RoleSigningPolicy    = "signing_policy"
+	// staged signing policy key of the key rotation, registered as the signing policy address
+	RoleNextSigningPolicy = "next_signing_policy"
+)
+
+// Address of a voter registered in the EntityManager in two steps: the identity
+// proposes the address, then the address confirms the registration for the identity.
+type Role struct {
+	Name string
+	// config key of the private key of the address
+	Key string
+
+	proposeMethod string
+	confirmMethod string
+	registered    func(addresses *entitymanager.IEntityManagerVoterAddresses) common.Address
+	signer        func(c *config.CredentialsConfig) (*globalConfig.SignerConfig, string, string)
+}
+
+var Roles = []*Role{
+	{
+		Name:          RoleSubmit,
+		Key:           "protocol_manager_submit_private_key_file",
+		proposeMethod: "proposeSubmitAddress",
+		confirmMethod: "confirmSubmitAddressRegistration",
+		registered: func(a *entitymanager.IEntityManagerVoterAddresses) common.Address {
+			return a.SubmitAddress
+		},
+		signer: func(c *config.CredentialsConfig) (*globalConfig.SignerConfig, string, string) {
+			return &c.ProtocolManagerSubmitSigner, c.ProtocolManagerSubmitPrivateKeyFile, c.ProtocolManagerSubmitPrivateKey
+		},
+	},
+	{
+		Name:          RoleSubmitSignatures,
+		Key:           "protocol_manager_submit_signatures_private_key_file",
+		proposeMethod: "proposeSubmitSignaturesAddress",
+		confirmMethod: "confirmSubmitSignaturesAddressRegistration",
+		registered: func(a *entitymanager.IEntityManagerVoterAddresses) common.Address {
+			return a.SubmitSignaturesAddress
+		},
+		signer: func(c *config.CredentialsConfig) (*globalConfig.SignerConfig, string, string) {
+			return &c.ProtocolManagerSubmitSignaturesSigner,
+				c.ProtocolManagerSubmitSignaturesPrivateKeyFile, c.ProtocolManagerSubmitSignaturesPrivateKey
+		},
+	},
+	{
+		Name:          RoleSigningPolicy,
+		Key:           "signing_policy_private_key_file",
+		proposeMethod: "proposeSigningPolicyAddress",
+		confirmMethod: "confirmSigningPolicyAddressRegistration",
+		registered: func(a *entitymanager.IEntityManagerVoterAddresses) common.Address {
+			return a.SigningPolicyAddress
+		},
+		signer: func(c *config.CredentialsConfig) (*globalConfig.SignerConfig, string, string) {
+			return &c.SigningPolicySigner, c.SigningPolicyPrivateKeyFile, c.SigningPolicyPrivateKey
+		},
+	},
+	{
+		Name:          RoleNextSigningPolicy,
+		Key:           "next_signing_policy_private_key_file",
+		proposeMethod: "proposeSigningPolicyAddress",
+		confirmMethod: "confirmSigningPolicyAddressRegistration",
+		registered: func(a *entitymanager.IEntityManagerVoterAddresses) common.Address {
+			return a.SigningPolicyAddress
+		},
+		signer: func(c *config.CredentialsConfig) (*globalConfig.SignerConfig, string, string) {
+			return &c.NextSigningPolicySigner, c.NextSigningPolicyPrivateKeyFile, c.NextSigningPolicyPrivateKey
+		},
+	},
+}
+
+// ParseRoles parses a comma separated list of role names, all roles if names is empty
+func ParseRoles(names string) ([]*Role, error) {
+	if len(strings.TrimSpace(names)) == 0 {
+		return Roles, nil
+	}
+	var roles []*Role
+	for _, name := range strings.Split(names, ",") {
+		role := findRole(strings.TrimSpace(name))
+		if role == nil {
+			return nil, errors.Errorf("unknown role %q, expected one of %s, %s, %s, %s",
+				name, RoleSubmit, RoleSubmitSignatures, RoleSigningPolicy, RoleNextSigningPolicy)
+		}
+		roles = append(roles, role)
+	}
+	return roles, nil
+}
+
+func findRole(name string) *Role {
+	for _, role := range Roles {
+		if role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+func (r *Role) Signer(c *config.CredentialsConfig) (credentials.Signer, error) {
+	signerCfg, fileName, envString := r.signer(c)
+	return globalConfig.SignerFromConfig(signerCfg, fileName, envString)
+}
+
+// Registration state of a role of the identity
+type RoleStatus struct {
+	Role *Role
+	// address of the configured key
+	Local      common.Address
+	Registered common.Address
+	// proposed by the identity, not yet confirmed
+	Proposed common.Address
+	// address of the next signing policy key if it is already registered in place of
+	// the signing policy key, which is still used until the rotation reward epoch
+	ReplacedBy common.Address
+}
+
+func (s *RoleStatus) Ok() bool {
+	return s.Local == s.Registered || (s.ReplacedBy != (common.Address{}) && s.ReplacedBy == s.Registered)
+}
+
+// Next step of the registration of the local address
+func (s *RoleStatus) Hint() string {
+	switch {
+	case s.Local != s.Registered && s.Ok():
+		return fmt.Sprintf("replaced by the registered %s key", RoleNextSigningPolicy)
+	case s.Ok():
+		return ""
+	case s.Proposed == s.Local:
+		return fmt.Sprintf("proposed, confirm with: entity-confirm -role %s", s.Role.Name)
+	default:
+		return fmt.Sprintf("propose with: entity-propose -role %s", s.Role.Name)
+	}
+}
+
+func (s *RoleStatus) String() string {
+	status := fmt.Sprintf("%s address: local %s, registered %s", s.Role.Name, s.Local.Hex(), s.Registered.Hex())
+	if s.Proposed != (common.Address{}) {
+		status += ", proposed " + s.Proposed.Hex()
+	}
+	if hint := s.Hint(); len(hint) > 0 {
+		status += " - " + hint
+	}
+	return status
+}
+
+// Sortition public key of the identity, nil if the sortition key is not configured
+type PublicKeyStatus struct {
+	Local      [2][32]byte
+	Registered [2][32]byte
+}
+
+func (s *PublicKeyStatus) Ok() bool {
+	return s.Local == s.Registered
+}
+
+func (s *PublicKeyStatus) String() string {
+	status := fmt.Sprintf("sortition public key: local (%s, %s), registered (%s, %s)",
+		hexutil.Encode(s.Local[0][:]), hexutil.Encode(s.Local[1][:]),
+		hexutil.Encode(s.Registered[0][:]), hexutil.Encode(s.Registered[1][:]))
+	if !s.Ok() {
+		status += " - register with: entity-register-public-key"
+	}
+	return status
+}
+
+type Status struct {
+	Identity  common.Address
+	Roles     []*RoleStatus
+	PublicKey *PublicKeyStatus
+}
+
+func (s *Status) Ok() bool {
+	for _, role := range s.Roles {
+		if !role.Ok() {
+			return false
+		}
+	}
+	return s.PublicKey == nil || s.PublicKey.Ok()
+}
+
+// The signing policy key is replaced by the next key once the next key is registered
+func markReplacedSigningPolicy(roles []*RoleStatus) {
+	var current, next *RoleStatus
+	for _, role := range roles {
+		switch role.Role.Name {
+		case RoleSigningPolicy:
+			current = role
+		case RoleNextSigningPolicy:
+			next = role
+		}
+	}
+	if current != nil && next != nil {
+		current.ReplacedBy = next.Local
+	}
+}
+
+// Compares the local addresses of the roles and the sortition key with the chain
+// state of the identity
+func fetchStatus(
+	ctx context.Context,
+	contract entityManagerContractClient,
+	identity common.Address,
+	local map[*Role]common.Address,
+	sortitionKey *sortition.Key,
+) (*Status, error) {
+	addresses, err := contract.VoterAddresses(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Identity: identity}
+	for _, role := range Roles {
+		address, ok := local[role]
+		if !ok {
+			continue
+		}
+		proposed, err := contract.ProposedAddress(ctx, role, identity)
+		if err != nil {
+			return nil, err
+		}
+		status.Roles = append(status.Roles, &RoleStatus{
+			Role:       role,
+			Local:      address,
+			Registered: role.registered(addresses),
+			Proposed:   proposed,
+		})
+	}
+	markReplacedSigningPolicy(status.Roles)
+	if sortitionKey != nil {
+		part1, part2, err := contract.PublicKey(ctx, identity)
+		if err != nil {
+			return nil, err
+		}
+		localPart1, localPart2 := sortitionKey.PublicKeyParts()
+		status.PublicKey = &PublicKeyStatus{
+			Local:      [2][32]byte{localPart1, localPart2},
+			Registered: [2][32]byte{part1, part2},
+		}
+	}
+	return status, nil
+}
@@ -0,0 +1,176 @@
+package entity
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/entitymanager"
+	"flare-tlc/utils/contracts/registry"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+)
+
+type entityManagerContractClient interface {
+	VoterAddresses(ctx context.Context, voter common.Address) (*entitymanager.IEntityManagerVoterAddresses, error)
+	// Address proposed by the voter and not yet confirmed, zero if there is none
+	ProposedAddress(ctx context.Context, role *Role, voter common.Address) (common.Address, error)
+	PublicKey(ctx context.Context, voter common.Address) ([32]byte, [32]byte, error)
+
+	Propose(ctx context.Context, role *Role, sender *bind.TransactOpts, address common.Address) error
+	Confirm(ctx context.Context, role *Role, sender *bind.TransactOpts, voter common.Address) error
+	RegisterPublicKey(ctx context.Context, sender *bind.TransactOpts, part1, part2 [32]byte, verificationData []byte) error
+}
+
+type entityManagerContractClientImpl struct {
+	ethClient     *ethclient.Client
+	address       common.Address
+	entityManager *entitymanager.EntityManager
+	gasCfg        *config.GasConfig
+	txVerifier    *chain.TxVerifier
+}
+
+// The EntityManager address is read from the VoterRegistry
+func newEntityManagerContractClient(
+	ctx context.Context, ethClient *ethclient.Client, voterRegistryAddress common.Address, gasCfg *config.GasConfig,
+) (*entityManagerContractClientImpl, error) {
+	voterRegistry, err := registry.NewRegistryCaller(voterRegistryAddress, ethClient)
+	if err != nil {
+		return nil, err
+	}
+	address, err := voterRegistry.EntityManager(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching entity manager address")
+	}
+	entityManager, err := entitymanager.NewEntityManager(address, ethClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating entity manager contract")
+	}
+	return &entityManagerContractClientImpl{
+		ethClient:     ethClient,
+		address:       address,
+		entityManager: entityManager,
+		gasCfg:        gasCfg,
+		txVerifier:    chain.NewTxVerifier(ethClient),
+	}, nil
+}
+
+func (c *entityManagerContractClientImpl) VoterAddresses(
+	ctx context.Context, voter common.Address,
+) (*entitymanager.IEntityManagerVoterAddresses, error) {
+	addresses, err := c.entityManager.GetVoterAddresses(&bind.CallOpts{Context: ctx}, voter)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching voter addresses")
+	}
+	return &addresses, nil
+}
+
+func (c *entityManagerContractClientImpl) ProposedAddress(
+	ctx context.Context, role *Role, voter common.Address,
+) (common.Address, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	var address common.Address
+	var err error
+	switch role.Name {
+	case RoleSubmit:
+		address, err = c.entityManager.SubmitAddressRegistrationQueue(opts, voter)
+	case RoleSubmitSignatures:
+		address, err = c.entityManager.SubmitSignaturesAddressRegistrationQueue(opts, voter)
+	case RoleSigningPolicy, RoleNextSigningPolicy:
+		address, err = c.entityManager.SigningPolicyAddressRegistrationQueue(opts, voter)
+	default:
+		return common.Address{}, errors.Errorf("unknown role %s", role.Name)
+	}
+	if err != nil {
+		return common.Address{}, errors.Wrapf(err, "error fetching proposed %s address", role.Name)
+	}
+	return address, nil
+}
+
+func (c *entityManagerContractClientImpl) PublicKey(ctx context.Context, voter common.Address) ([32]byte, [32]byte, error) {
+	part1, part2, err := c.entityManager.GetPublicKeyOf(&bind.CallOpts{Context: ctx}, voter)
+	if err != nil {
+		return part1, part2, errors.Wrap(err, "error fetching public key")
+	}
+	return part1, part2, nil
+}
+
+func (c *entityManagerContractClientImpl) Propose(
+	ctx context.Context, role *Role, sender *bind.TransactOpts, address common.Address,
+) error {
+	return c.transact(ctx, sender, role.proposeMethod, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		switch role.Name {
+		case RoleSubmit:
+			return c.entityManager.ProposeSubmitAddress(opts, address)
+		case RoleSubmitSignatures:
+			return c.entityManager.ProposeSubmitSignaturesAddress(opts, address)
+		default:
+			return c.entityManager.ProposeSigningPolicyAddress(opts, address)
+		}
+	}, address)
+}
+
+func (c *entityManagerContractClientImpl) Confirm(
+	ctx context.Context, role *Role, sender *bind.TransactOpts, voter common.Address,
+) error {
+	return c.transact(ctx, sender, role.confirmMethod, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		switch role.Name {
+		case RoleSubmit:
+			return c.entityManager.ConfirmSubmitAddressRegistration(opts, voter)
+		case RoleSubmitSignatures:
+			return c.entityManager.ConfirmSubmitSignaturesAddressRegistration(opts, voter)
+		default:
+			return c.entityManager.ConfirmSigningPolicyAddressRegistration(opts, voter)
+		}
+	}, voter)
+}
+
+func (c *entityManagerContractClientImpl) RegisterPublicKey(
+	ctx context.Context, sender *bind.TransactOpts, part1, part2 [32]byte, verificationData []byte,
+) error {
+	return c.transact(ctx, sender, "registerPublicKey", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.entityManager.RegisterPublicKey(opts, part1, part2, verificationData)
+	}, part1, part2, verificationData)
+}
+
+// Sends the tx of the method and waits until it is mined, args are the method
+// arguments for the gas estimation
+func (c *entityManagerContractClientImpl) transact(
+	ctx context.Context,
+	sender *bind.TransactOpts,
+	method string,
+	send func(opts *bind.TransactOpts) (*types.Transaction, error),
+	args ...interface{},
+) error {
+	fees, err := chain.GetTxFees(c.gasCfg, c.ethClient)
+	if err != nil {
+		return errors.Wrap(err, "error obtaining gas price")
+	}
+
+	opts := *sender
+	opts.Context = ctx
+	tx, err := chain.TransactWithNonce(c.ethClient, &opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if opts.GasLimit == 0 {
+			gasLimit, err := chain.ContractGasLimit(
+				ctx, c.ethClient, c.gasCfg, config.RetryOpEntityRegistration,
+				opts.From, c.address, entitymanager.EntityManagerMetaData, method, args...,
+			)
+			if err != nil {
+				return nil, err
+			}
+			opts.GasLimit = gasLimit
+		}
+		fees.Apply(opts)
+		return send(opts)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "error sending %s", method)
+	}
+	logger.Info("Sent %s tx %s", method, tx.Hash().Hex())
+	_, err = c.txVerifier.WaitUntilMinedWithEscalation(
+		opts.From, tx, opts.Signer, c.gasCfg, config.RetryOpEntityRegistration, chain.DefaultTxTimeout)
+	return err
+}
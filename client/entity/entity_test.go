@@ -0,0 +1,135 @@
+package entity
+
+import (
+	"context"
+	"crypto/rand"
+	"flare-tlc/utils/contracts/entitymanager"
+	"flare-tlc/utils/sortition"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntityManager struct {
+	addresses entitymanager.IEntityManagerVoterAddresses
+	proposed  map[string]common.Address
+	publicKey [2][32]byte
+}
+
+func (c *testEntityManager) VoterAddresses(context.Context, common.Address) (*entitymanager.IEntityManagerVoterAddresses, error) {
+	return &c.addresses, nil
+}
+
+func (c *testEntityManager) ProposedAddress(_ context.Context, role *Role, _ common.Address) (common.Address, error) {
+	return c.proposed[role.Name], nil
+}
+
+func (c *testEntityManager) PublicKey(context.Context, common.Address) ([32]byte, [32]byte, error) {
+	return c.publicKey[0], c.publicKey[1], nil
+}
+
+func (c *testEntityManager) Propose(context.Context, *Role, *bind.TransactOpts, common.Address) error {
+	return nil
+}
+
+func (c *testEntityManager) Confirm(context.Context, *Role, *bind.TransactOpts, common.Address) error {
+	return nil
+}
+
+func (c *testEntityManager) RegisterPublicKey(context.Context, *bind.TransactOpts, [32]byte, [32]byte, []byte) error {
+	return nil
+}
+
+func TestFetchStatus(t *testing.T) {
+	submit := common.HexToAddress("0x01")
+	submitSignatures := common.HexToAddress("0x02")
+	signingPolicy := common.HexToAddress("0x03")
+	contract := &testEntityManager{
+		addresses: entitymanager.IEntityManagerVoterAddresses{
+			SubmitAddress:        submit,
+			SigningPolicyAddress: common.HexToAddress("0x13"),
+		},
+		proposed: map[string]common.Address{RoleSigningPolicy: signingPolicy},
+	}
+	local := map[*Role]common.Address{
+		findRole(RoleSubmit):           submit,
+		findRole(RoleSubmitSignatures): submitSignatures,
+		findRole(RoleSigningPolicy):    signingPolicy,
+	}
+
+	status, err := fetchStatus(context.Background(), contract, common.HexToAddress("0x10"), local, nil)
+	require.NoError(t, err)
+	require.False(t, status.Ok())
+	require.Nil(t, status.PublicKey)
+	require.Len(t, status.Roles, 3)
+
+	require.True(t, status.Roles[0].Ok())
+	require.Empty(t, status.Roles[0].Hint())
+	require.False(t, status.Roles[1].Ok())
+	require.Contains(t, status.Roles[1].Hint(), "entity-propose -role submit_signatures")
+	require.False(t, status.Roles[2].Ok())
+	require.Contains(t, status.Roles[2].Hint(), "entity-confirm -role signing_policy")
+
+	// only the roles with a local key are compared
+	status, err = fetchStatus(context.Background(), contract, common.HexToAddress("0x10"),
+		map[*Role]common.Address{findRole(RoleSubmit): submit}, nil)
+	require.NoError(t, err)
+	require.True(t, status.Ok())
+}
+
+func TestFetchStatusKeyRotation(t *testing.T) {
+	signingPolicy := common.HexToAddress("0x03")
+	nextSigningPolicy := common.HexToAddress("0x04")
+	contract := &testEntityManager{
+		addresses: entitymanager.IEntityManagerVoterAddresses{SigningPolicyAddress: signingPolicy},
+	}
+	local := map[*Role]common.Address{
+		findRole(RoleSigningPolicy):     signingPolicy,
+		findRole(RoleNextSigningPolicy): nextSigningPolicy,
+	}
+
+	status, err := fetchStatus(context.Background(), contract, common.HexToAddress("0x10"), local, nil)
+	require.NoError(t, err)
+	require.True(t, status.Roles[0].Ok())
+	require.False(t, status.Roles[1].Ok())
+	require.Contains(t, status.Roles[1].Hint(), "entity-propose -role next_signing_policy")
+
+	// the signing policy key is still used until the rotation reward epoch
+	contract.addresses.SigningPolicyAddress = nextSigningPolicy
+	status, err = fetchStatus(context.Background(), contract, common.HexToAddress("0x10"), local, nil)
+	require.NoError(t, err)
+	require.True(t, status.Ok())
+	require.Contains(t, status.Roles[0].Hint(), "replaced by")
+}
+
+func TestFetchStatusPublicKey(t *testing.T) {
+	key, err := sortition.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	contract := &testEntityManager{}
+
+	status, err := fetchStatus(context.Background(), contract, common.HexToAddress("0x10"), nil, key)
+	require.NoError(t, err)
+	require.False(t, status.Ok())
+	require.Contains(t, status.PublicKey.String(), "entity-register-public-key")
+
+	part1, part2 := key.PublicKeyParts()
+	contract.publicKey = [2][32]byte{part1, part2}
+	status, err = fetchStatus(context.Background(), contract, common.HexToAddress("0x10"), nil, key)
+	require.NoError(t, err)
+	require.True(t, status.Ok())
+}
+
+func TestParseRoles(t *testing.T) {
+	roles, err := ParseRoles("")
+	require.NoError(t, err)
+	require.Equal(t, Roles, roles)
+
+	roles, err = ParseRoles("signing_policy, submit")
+	require.NoError(t, err)
+	require.Equal(t, []*Role{findRole(RoleSigningPolicy), findRole(RoleSubmit)}, roles)
+
+	_, err = ParseRoles("submit,identity")
+	require.Error(t, err)
+}
@@ -0,0 +1,5 @@
+package entity
+
+import flarelogger "flare-tlc/logger"
+
+var logger = flarelogger.Module("entity")
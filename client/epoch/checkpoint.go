@@ -0,0 +1,60 @@
+package epoch
+
+import (
+	"flare-tlc/client/config"
+	"sync"
+)
+
+// Listener names, used for the checkpoints and the listener event metrics
+const (
+	listenerVotePowerBlockSelected   = config.ListenerVotePowerBlockSelected
+	listenerSigningPolicyInitialized = config.ListenerSigningPolicyInitialized
+	listenerSignUptimeVoteEnabled    = config.ListenerSignUptimeVoteEnabled
+	listenerUptimeVoteSigned         = config.ListenerUptimeVoteSigned
+)
+
+// Returns the start of the event range of the listener, the checkpoint if it is
+// after defaultStart. Events up to and including the checkpoint are not emitted again.
+func listenerRangeStart(db EpochClientDB, listener string, defaultStart int64) int64 {
+	checkpoint, err := db.FetchListenerCheckpoint(listener)
+	if err != nil {
+		logger.Warn("Error fetching %s listener checkpoint, starting from %d: %v", listener, defaultStart, err)
+		return defaultStart
+	}
+	if checkpoint > defaultStart {
+		logger.Info("Resuming %s listener from checkpoint %d", listener, checkpoint)
+		return checkpoint
+	}
+	return defaultStart
+}
+
+// Checkpoints of the listeners, saved by the consumer once an event was handled, so
+// that an event is emitted again after a restart if its action did not complete.
+// An event is handled if its action completed or was skipped, e.g. for an epoch in
+// the past or already in the state of the action. Events handled out of order, e.g.
+// by the rewards signing running in the background, do not move a checkpoint back.
+type listenerCheckpoints struct {
+	db EpochClientDB
+
+	mu    sync.Mutex
+	saved map[string]int64
+}
+
+func newListenerCheckpoints(db EpochClientDB) *listenerCheckpoints {
+	return &listenerCheckpoints{db: db, saved: make(map[string]int64)}
+}
+
+// Saves the timestamp of the handled event of the listener
+func (c *listenerCheckpoints) handled(listener string, timestamp uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if int64(timestamp) <= c.saved[listener] {
+		return
+	}
+	if err := c.db.SaveListenerCheckpoint(listener, int64(timestamp)); err != nil {
+		logger.Warn("Error saving %s listener checkpoint: %v", listener, err)
+		return
+	}
+	c.saved[listener] = int64(timestamp)
+}
@@ -0,0 +1,60 @@
+package epoch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCheckpointDB struct {
+	testDB
+	checkpoints map[string]int64
+	err         error
+}
+
+func (db *testCheckpointDB) FetchListenerCheckpoint(listener string) (int64, error) {
+	return db.checkpoints[listener], db.err
+}
+
+func (db *testCheckpointDB) SaveListenerCheckpoint(listener string, timestamp int64) error {
+	if db.err != nil {
+		return db.err
+	}
+	db.checkpoints[listener] = timestamp
+	return nil
+}
+
+func TestListenerRangeStart(t *testing.T) {
+	db := &testCheckpointDB{checkpoints: make(map[string]int64)}
+	require.EqualValues(t, 100, listenerRangeStart(db, listenerVotePowerBlockSelected, 100))
+
+	require.NoError(t, db.SaveListenerCheckpoint(listenerVotePowerBlockSelected, 150))
+	require.EqualValues(t, 150, listenerRangeStart(db, listenerVotePowerBlockSelected, 100))
+	require.EqualValues(t, 100, listenerRangeStart(db, listenerUptimeVoteSigned, 100))
+
+	// checkpoints before the default range are ignored
+	require.EqualValues(t, 200, listenerRangeStart(db, listenerVotePowerBlockSelected, 200))
+
+	db.err = errors.New("db error")
+	require.EqualValues(t, 100, listenerRangeStart(db, listenerVotePowerBlockSelected, 100))
+}
+
+func TestListenerCheckpointsHandled(t *testing.T) {
+	db := &testCheckpointDB{checkpoints: make(map[string]int64)}
+	checkpoints := newListenerCheckpoints(db)
+
+	checkpoints.handled(listenerVotePowerBlockSelected, 150)
+	require.EqualValues(t, 150, db.checkpoints[listenerVotePowerBlockSelected])
+
+	// events handled out of order do not move the checkpoint back
+	checkpoints.handled(listenerVotePowerBlockSelected, 120)
+	require.EqualValues(t, 150, db.checkpoints[listenerVotePowerBlockSelected])
+
+	// saved again after an error
+	db.err = errors.New("db error")
+	checkpoints.handled(listenerVotePowerBlockSelected, 200)
+	db.err = nil
+	checkpoints.handled(listenerVotePowerBlockSelected, 200)
+	require.EqualValues(t, 200, db.checkpoints[listenerVotePowerBlockSelected])
+}
@@ -0,0 +1,59 @@
+package epoch
+
+import (
+	flarectx "flare-tlc/client/context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Manual operations run by the client subcommands. The clients are created
+// regardless of the enabled clients in the config.
+
+// Register the identity as a voter for the reward epoch
+func RegisterVoter(ctx flarectx.ClientContext, rewardEpochId *big.Int) error {
+	c, err := newEpochClient(ctx)
+	if err != nil {
+		return err
+	}
+	result := <-c.registryClient.RegisterVoter(rewardEpochId, c.identityAddress)
+	if !result.Success {
+		return errors.Errorf("RegisterVoter failed: %s", result.Message)
+	}
+	c.lifecycle.Advance(rewardEpochId.Int64(), stateRegistered)
+	return nil
+}
+
+// Sign the signing policy of the reward epoch, the policy is read from the
+// SigningPolicyInitialized event in the indexer database
+func SignPolicy(ctx flarectx.ClientContext, rewardEpochId *big.Int) error {
+	c, err := newEpochClient(ctx)
+	if err != nil {
+		return err
+	}
+	epoch, err := c.systemsManagerClient.RewardEpochFromChain()
+	if err != nil {
+		return err
+	}
+	// the policy is initialized in the previous reward epoch
+	from := epoch.StartTime(rewardEpochId.Int64() - 1).Unix()
+	policy, err := c.relayClient.FetchSigningPolicy(c.db, rewardEpochId, from-1, time.Now().Unix())
+	if err != nil {
+		return errors.Wrap(err, "error fetching signing policy")
+	}
+	if policy == nil {
+		return fmt.Errorf("signing policy for reward epoch %v not found", rewardEpochId)
+	}
+
+	if err := c.verifySigningPolicy(policy); err != nil {
+		return errors.Wrap(err, "refusing to sign signing policy")
+	}
+	result := <-c.systemsManagerClient.SignNewSigningPolicy(rewardEpochId, policy.SigningPolicyBytes, policySigningDeadline(epoch, rewardEpochId))
+	if !result.Success {
+		return errors.Errorf("SignNewSigningPolicy failed: %s", result.Message)
+	}
+	c.lifecycle.Advance(rewardEpochId.Int64(), statePolicySigned)
+	return nil
+}
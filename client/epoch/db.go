@@ -1,21 +1,153 @@
 package epoch
 
 import (
+	"flare-tlc/client/shared/rpcsource"
 	"flare-tlc/database"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 )
 
-type epochClientDB interface {
+// Indexer logs and the persisted listener state of the epoch client, exported so that
+// the client can be run against an in-memory database, see NewEpochClientWithClients
+type EpochClientDB interface {
 	FetchLogsByAddressAndTopic0(common.Address, string, int64, int64) ([]database.Log, error)
+
+	// Returns the timestamp of the last event processed by the listener, 0 if there is none
+	FetchListenerCheckpoint(listener string) (int64, error)
+	SaveListenerCheckpoint(listener string, timestamp int64) error
+
+	// States of the most recent reward epochs, see epochLifecycle
+	FetchRewardEpochStates(limit int) ([]database.RewardEpochState, error)
+	SaveRewardEpochState(state *database.RewardEpochState) error
 }
 
 type epochClientDBGorm struct {
-	db *gorm.DB
+	db        *gorm.DB
+	fetchOpts database.FetchOptions
+
+	// Checkpoints and reward epoch states are not persisted if false
+	checkpoints bool
+
+	// listener checkpoints and reward epoch states are persisted per identity, empty
+	// for identity.address
+	identity string
+}
+
+func newEpochClientDBGorm(db *gorm.DB, fetchOpts database.FetchOptions, checkpoints bool) (epochClientDBGorm, error) {
+	if checkpoints {
+		// the checkpoints were unique by listener before the additional identities
+		if db.Migrator().HasIndex(&database.ListenerCheckpoint{}, "idx_listener_checkpoints_listener") {
+			if err := db.Migrator().DropIndex(&database.ListenerCheckpoint{}, "idx_listener_checkpoints_listener"); err != nil {
+				return epochClientDBGorm{}, errors.Wrap(err, "error dropping listener checkpoint index")
+			}
+		}
+		if err := db.AutoMigrate(&database.ListenerCheckpoint{}); err != nil {
+			return epochClientDBGorm{}, errors.Wrap(err, "error migrating listener checkpoint table")
+		}
+		// the states were unique by reward epoch before the additional identities
+		if db.Migrator().HasIndex(&database.RewardEpochState{}, "idx_reward_epoch_states_reward_epoch_id") {
+			if err := db.Migrator().DropIndex(&database.RewardEpochState{}, "idx_reward_epoch_states_reward_epoch_id"); err != nil {
+				return epochClientDBGorm{}, errors.Wrap(err, "error dropping reward epoch state index")
+			}
+		}
+		if err := db.AutoMigrate(&database.RewardEpochState{}); err != nil {
+			return epochClientDBGorm{}, errors.Wrap(err, "error migrating reward epoch state table")
+		}
+	}
+	return epochClientDBGorm{db: db, fetchOpts: fetchOpts, checkpoints: checkpoints}, nil
+}
+
+// NewEpochClientDB returns the indexer database of the epoch client, the listener
+// checkpoints and reward epoch states are persisted if checkpoints is set
+func NewEpochClientDB(db *gorm.DB, fetchOpts database.FetchOptions, checkpoints bool) (EpochClientDB, error) {
+	return newEpochClientDBGorm(db, fetchOpts, checkpoints)
 }
 
 func (g epochClientDBGorm) FetchLogsByAddressAndTopic0(
 	address common.Address, topic0 string, fromBlock int64, toBlock int64,
 ) ([]database.Log, error) {
-	return database.FetchLogsByAddressAndTopic0(g.db, address.Hex(), topic0, fromBlock, toBlock)
+	return database.FetchLogsByAddressAndTopic0(g.db, address.Hex(), topic0, fromBlock, toBlock, g.fetchOpts)
+}
+
+func (g epochClientDBGorm) FetchListenerCheckpoint(listener string) (int64, error) {
+	if !g.checkpoints {
+		return 0, nil
+	}
+	checkpoint, err := database.FetchListenerCheckpoint(g.db, listener, g.identity)
+	if err != nil || checkpoint == nil {
+		return 0, err
+	}
+	return int64(checkpoint.Timestamp), nil
+}
+
+func (g epochClientDBGorm) SaveListenerCheckpoint(listener string, timestamp int64) error {
+	if !g.checkpoints {
+		return nil
+	}
+	return database.SaveListenerCheckpoint(g.db, listener, g.identity, uint64(timestamp))
+}
+
+func (g epochClientDBGorm) FetchRewardEpochStates(limit int) ([]database.RewardEpochState, error) {
+	if !g.checkpoints {
+		return nil, nil
+	}
+	return database.FetchRewardEpochStates(g.db, g.identity, limit)
+}
+
+func (g epochClientDBGorm) SaveRewardEpochState(state *database.RewardEpochState) error {
+	if !g.checkpoints {
+		return nil
+	}
+	state.Identity = g.identity
+	return database.SaveRewardEpochState(g.db, state)
+}
+
+// Returns the database persisting the listener checkpoints and reward epoch states of
+// the additional identity, each identity runs its own listeners
+func (g epochClientDBGorm) withIdentity(identity common.Address) epochClientDBGorm {
+	g.identity = identity.Hex()
+	return g
+}
+
+// Reads the logs from the rpc node, checkpoints and reward epoch states are not
+// persisted without a database
+type epochClientDBRPC struct {
+	*rpcsource.Source
+}
+
+func (epochClientDBRPC) FetchListenerCheckpoint(listener string) (int64, error) { return 0, nil }
+
+func (epochClientDBRPC) SaveListenerCheckpoint(listener string, timestamp int64) error { return nil }
+
+func (epochClientDBRPC) FetchRewardEpochStates(limit int) ([]database.RewardEpochState, error) {
+	return nil, nil
+}
+
+func (epochClientDBRPC) SaveRewardEpochState(state *database.RewardEpochState) error { return nil }
+
+// Reads the logs from the rpc node instead of the indexer db while the indexer lags,
+// checkpoints and reward epoch states stay in the database
+type epochClientDBFallback struct {
+	EpochClientDB
+	monitor *rpcsource.IndexerMonitor
+}
+
+func (f epochClientDBFallback) FetchLogsByAddressAndTopic0(
+	address common.Address, topic0 string, fromBlock int64, toBlock int64,
+) ([]database.Log, error) {
+	if f.monitor.Degraded() {
+		return f.monitor.Source().FetchLogsByAddressAndTopic0(address, topic0, fromBlock, toBlock)
+	}
+	return f.EpochClientDB.FetchLogsByAddressAndTopic0(address, topic0, fromBlock, toBlock)
+}
+
+// ResetListenerCheckpoints deletes the checkpoints of the epoch client listeners,
+// the listeners start from the default range on the next start
+func ResetListenerCheckpoints(db *gorm.DB) error {
+	if err := db.AutoMigrate(&database.ListenerCheckpoint{}); err != nil {
+		return errors.Wrap(err, "error migrating listener checkpoint table")
+	}
+	return database.DeleteListenerCheckpoints(db)
 }
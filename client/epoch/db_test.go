@@ -0,0 +1,108 @@
+package epoch
+
+import (
+	"flare-tlc/database"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Reward epoch states table before the additional identities, unique by reward epoch
+type rewardEpochStateV1 struct {
+	database.BaseEntity
+	RewardEpochId uint32 `gorm:"uniqueIndex"`
+	State         string `gorm:"type:varchar(32)"`
+	LastError     string `gorm:"type:text"`
+	UpdatedAt     uint64
+}
+
+func (rewardEpochStateV1) TableName() string { return "reward_epoch_states" }
+
+func TestRewardEpochStatesPerIdentity(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, db.AutoMigrate(&rewardEpochStateV1{}))
+	require.NoError(t, db.Create(&rewardEpochStateV1{RewardEpochId: 5, State: stateRegistered}).Error)
+
+	primary, err := newEpochClientDBGorm(db, database.FetchOptions{}, true)
+	require.NoError(t, err)
+	identity := common.HexToAddress("0x1234")
+	additional := primary.withIdentity(identity)
+
+	// existing states belong to identity.address
+	states, err := primary.FetchRewardEpochStates(lifecycleEpochs)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.Equal(t, stateRegistered, states[0].State)
+	states, err = additional.FetchRewardEpochStates(lifecycleEpochs)
+	require.NoError(t, err)
+	require.Empty(t, states)
+
+	// the same reward epoch is stored per identity
+	require.NoError(t, additional.SaveRewardEpochState(&database.RewardEpochState{RewardEpochId: 5, State: stateVotePowerBlockSelected}))
+	require.NoError(t, primary.SaveRewardEpochState(&database.RewardEpochState{RewardEpochId: 5, State: statePolicySigned}))
+
+	states, err = additional.FetchRewardEpochStates(lifecycleEpochs)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.Equal(t, stateVotePowerBlockSelected, states[0].State)
+	require.Equal(t, identity.Hex(), states[0].Identity)
+	states, err = primary.FetchRewardEpochStates(lifecycleEpochs)
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	require.Equal(t, statePolicySigned, states[0].State)
+}
+
+// Listener checkpoints table before the additional identities, unique by listener
+type listenerCheckpointV1 struct {
+	database.BaseEntity
+	Listener  string `gorm:"type:varchar(64);uniqueIndex"`
+	Timestamp uint64
+}
+
+func (listenerCheckpointV1) TableName() string { return "listener_checkpoints" }
+
+func TestListenerCheckpointsPerIdentity(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, db.AutoMigrate(&listenerCheckpointV1{}))
+	require.NoError(t, db.Create(&listenerCheckpointV1{Listener: listenerVotePowerBlockSelected, Timestamp: 100}).Error)
+
+	primary, err := newEpochClientDBGorm(db, database.FetchOptions{}, true)
+	require.NoError(t, err)
+	additional := primary.withIdentity(common.HexToAddress("0x1234"))
+
+	// existing checkpoints belong to identity.address
+	checkpoint, err := primary.FetchListenerCheckpoint(listenerVotePowerBlockSelected)
+	require.NoError(t, err)
+	require.EqualValues(t, 100, checkpoint)
+	checkpoint, err = additional.FetchListenerCheckpoint(listenerVotePowerBlockSelected)
+	require.NoError(t, err)
+	require.Zero(t, checkpoint)
+
+	// a faster identity does not move the checkpoint of the other one
+	require.NoError(t, additional.SaveListenerCheckpoint(listenerVotePowerBlockSelected, 300))
+	require.NoError(t, primary.SaveListenerCheckpoint(listenerVotePowerBlockSelected, 200))
+	checkpoint, err = primary.FetchListenerCheckpoint(listenerVotePowerBlockSelected)
+	require.NoError(t, err)
+	require.EqualValues(t, 200, checkpoint)
+	checkpoint, err = additional.FetchListenerCheckpoint(listenerVotePowerBlockSelected)
+	require.NoError(t, err)
+	require.EqualValues(t, 300, checkpoint)
+}
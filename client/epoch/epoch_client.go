@@ -2,17 +2,23 @@ package epoch
 
 import (
 	"context"
+	"flare-tlc/client/admin"
 	clientConfig "flare-tlc/client/config"
 	flarectx "flare-tlc/client/context"
+	"flare-tlc/client/shared/alerts"
 	"flare-tlc/config"
-	"flare-tlc/logger"
+	"flare-tlc/utils"
 	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/relay"
 	"flare-tlc/utils/contracts/system"
 	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/sortition"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"math/big"
+	"sort"
+	"sync"
+	"time"
 )
 
 // EpochClient performs reward epoch registration and signing actions, triggered on SystemsManager contract events:
@@ -21,11 +27,11 @@ import (
 // - Signing uptime vote (on SignUptimeVoteEnabled)
 // - Signing rewards (on UptimeVoteSigned with threshold reached)
 type EpochClient struct {
-	db epochClientDB
+	db EpochClientDB
 
-	systemsManagerClient systemsManagerContractClient
-	relayClient          relayContractClient
-	registryClient       registryContractClient
+	systemsManagerClient SystemsManagerContractClient
+	relayClient          RelayContractClient
+	registryClient       RegistryContractClient
 
 	identityAddress common.Address
 
@@ -35,39 +41,103 @@ type EpochClient struct {
 
 	rewardsConfig *clientConfig.RewardsConfig
 	uptimeConfig  *clientConfig.UptimeConfig
+	retryConfig   *clientConfig.RetryConfig
+
+	// the registration_window_closing alert fires if the voter is not registered this
+	// long after the vote power block was selected
+	registrationAlertAfter time.Duration
+	// the signing_policy_deadline alert fires if the signing policy is not signed this
+	// long before the end of the signing window
+	policySigningAlertBefore time.Duration
+
+	lifecycle   *epochLifecycle
+	checkpoints *listenerCheckpoints
+
+	// rewards signing running in the background, waited for on shutdown
+	background sync.WaitGroup
 }
 
-func NewEpochClient(ctx flarectx.ClientContext) (*EpochClient, error) {
-	cfg := ctx.Config()
-	if !cfg.Clients.EpochClientEnabled() {
+// NewEpochClients creates the epoch clients of identity.address and of the additional
+// identities, nil if the epoch client is disabled
+func NewEpochClients(ctx flarectx.ClientContext) (EpochClients, error) {
+	if !ctx.Config().Clients.EpochClientEnabled() {
 		return nil, nil
 	}
+	return newEpochClients(ctx)
+}
 
-	chainCfg := cfg.ChainConfig()
-	ethClient, err := chainCfg.DialETH()
+func newEpochClients(ctx flarectx.ClientContext) (EpochClients, error) {
+	cfg := ctx.Config()
+	signer, err := ctx.SigningPolicySigner()
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating signing policy signer")
+	}
+	c, sender, err := newIdentityEpochClient(ctx, cfg.Identity.Address, &cfg.Credentials, signer, false)
 	if err != nil {
 		return nil, err
 	}
+	clients := EpochClients{c}
+
+	// nonces are assigned per sender, a shared sender would order the txs of the identities
+	senders := map[common.Address]common.Address{sender: cfg.Identity.Address}
+	for i := range cfg.Identities {
+		identity := &cfg.Identities[i]
+		signer, err := flarectx.NewSigningPolicySigner(&identity.Credentials)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating signing policy signer of identity %s", identity.Address.Hex())
+		}
+		c, sender, err := newIdentityEpochClient(ctx, identity.Address, &identity.Credentials, signer, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "identity %s", identity.Address.Hex())
+		}
+		if other, ok := senders[sender]; ok {
+			return nil, errors.Errorf("identities %s and %s use the same system client sender %s",
+				other.Hex(), identity.Address.Hex(), sender.Hex())
+		}
+		senders[sender] = identity.Address
+		clients = append(clients, c)
+	}
+	return clients, nil
+}
 
-	senderPk, err := config.PrivateKeyFromConfig(cfg.Credentials.SystemClientSenderPrivateKeyFile,
-		cfg.Credentials.SystemClientSenderPrivateKey)
+// Creates the client of identity.address regardless of the enabled clients, used for manual operations
+func newEpochClient(ctx flarectx.ClientContext) (*EpochClient, error) {
+	signer, err := ctx.SigningPolicySigner()
 	if err != nil {
-		return nil, errors.Wrap(err, "error reading sender private key")
+		return nil, errors.Wrap(err, "error creating signing policy signer")
 	}
-	senderTxOpts, _, err := credentials.CredentialsFromPrivateKey(senderPk, chainCfg.ChainID)
+	c, _, err := newIdentityEpochClient(ctx, ctx.Config().Identity.Address, &ctx.Config().Credentials, signer, false)
+	return c, err
+}
+
+// Creates the client of the identity with the keys of the credentials, additional is set
+// for the identities after identity.address. Returns the client and its sender address.
+func newIdentityEpochClient(
+	ctx flarectx.ClientContext,
+	identityAddress common.Address,
+	creds *clientConfig.CredentialsConfig,
+	signer *credentials.RotatingSigner,
+	additional bool,
+) (*EpochClient, common.Address, error) {
+	cfg := ctx.Config()
+	chainCfg := cfg.ChainConfig()
+	ethClient, err := ctx.EthClient()
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating sender register tx opts")
+		return nil, common.Address{}, err
 	}
 
-	signerPk, err := config.PrivateKeyFromConfig(cfg.Credentials.SigningPolicyPrivateKeyFile,
-		cfg.Credentials.SigningPolicyPrivateKey)
+	senderSigner, err := config.SignerFromConfig(&creds.SystemClientSenderSigner,
+		creds.SystemClientSenderPrivateKeyFile, creds.SystemClientSenderPrivateKey)
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating signer private key")
+		return nil, common.Address{}, errors.Wrap(err, "error creating sender signer")
 	}
+	senderTxOpts := credentials.TransactOptsFromSigner(senderSigner, chainCfg.ChainID)
 
-	systemsManagerClient, err := NewSystemsManagerClient(ethClient, cfg.ContractAddresses.SystemsManager, senderTxOpts, signerPk, chainCfg.ChainID)
+	systemsManagerClient, err := NewSystemsManagerClient(
+		ethClient, cfg.ContractAddresses.SystemsManager, senderTxOpts, &cfg.RegisterGas, signer, chainCfg.ChainID, &cfg.Retry,
+	)
 	if err != nil {
-		return nil, err
+		return nil, common.Address{}, err
 	}
 
 	relayClient, err := NewRelayContractClient(
@@ -75,7 +145,40 @@ func NewEpochClient(ctx flarectx.ClientContext) (*EpochClient, error) {
 		cfg.ContractAddresses.Relay,
 	)
 	if err != nil {
-		return nil, err
+		return nil, common.Address{}, err
+	}
+
+	listenerIntervals := cfg.Listeners.Intervals(chainCfg.ChainID)
+	systemsManagerClient.listenerIntervals = listenerIntervals
+	systemsManagerClient.voter = identityAddress
+	systemsManagerClient.policySigningCfg = &cfg.PolicySigning
+	relayClient.listenerIntervals = listenerIntervals
+	listenerBuffers := cfg.Listeners.Buffers()
+	systemsManagerClient.listenerBuffers = listenerBuffers
+	relayClient.listenerBuffers = listenerBuffers
+
+	if cfg.Listeners.VotePowerBlockSelected.Websocket {
+		wsClient, err := ctx.WSClient()
+		if err != nil {
+			return nil, common.Address{}, errors.Wrap(err, "error dialing websocket for VotePowerBlockSelected listener")
+		}
+		systemsManagerClient.vpbsSubscriber = wsClient
+	}
+	if cfg.Listeners.SigningPolicyInitialized.Websocket {
+		wsClient, err := ctx.WSClient()
+		if err != nil {
+			return nil, common.Address{}, errors.Wrap(err, "error dialing websocket for SigningPolicyInitialized listener")
+		}
+		relayClient.spiSubscriber = wsClient
+	}
+
+	var sortitionKey *sortition.Key
+	if creds.SortitionKeyConfigured() {
+		sortitionKey, err = config.SortitionKeyFromConfig(
+			creds.SortitionPrivateKeyFile, creds.SortitionPrivateKey)
+		if err != nil {
+			return nil, common.Address{}, errors.Wrap(err, "error reading sortition key")
+		}
 	}
 
 	registryClient, err := NewRegistryContractClient(
@@ -83,31 +186,79 @@ func NewEpochClient(ctx flarectx.ClientContext) (*EpochClient, error) {
 		&cfg.RegisterGas,
 		cfg.ContractAddresses.VoterRegistry,
 		senderTxOpts,
-		signerPk,
+		signer,
+		&cfg.Retry,
+		sortitionKey,
 	)
 	if err != nil {
-		return nil, err
+		return nil, common.Address{}, err
 	}
 
-	identityAddress := cfg.Identity.Address
 	if identityAddress == chain.EmptyAddress {
-		return nil, errors.New("no identity address provided")
+		return nil, common.Address{}, errors.New("no identity address provided")
 	}
 	logger.Debug("Identity addr %v", identityAddress)
 
-	db := epochClientDBGorm{db: ctx.DB()}
+	var db EpochClientDB
+	if source := ctx.RPCSource(); source != nil {
+		db = epochClientDBRPC{Source: source}
+	} else {
+		gormDB, err := newEpochClientDBGorm(ctx.DB(), cfg.Listeners.Fetch.FetchOptions(), cfg.Listeners.PersistentCheckpoints)
+		if err != nil {
+			return nil, common.Address{}, err
+		}
+		if additional {
+			gormDB = gormDB.withIdentity(identityAddress)
+		}
+		db = gormDB
+		if monitor := ctx.IndexerMonitor(); monitor != nil {
+			db = epochClientDBFallback{EpochClientDB: db, monitor: monitor}
+		}
+	}
+	return NewEpochClientWithClients(cfg, Clients{
+		DB:             db,
+		SystemsManager: systemsManagerClient,
+		Relay:          relayClient,
+		Registry:       registryClient,
+		Identity:       identityAddress,
+	}), senderSigner.Address(), nil
+}
+
+// Contract clients and database of the epoch client, see NewEpochClientWithClients
+type Clients struct {
+	DB             EpochClientDB
+	SystemsManager SystemsManagerContractClient
+	Relay          RelayContractClient
+	Registry       RegistryContractClient
+
+	// voter identity of the client, default: identity.address
+	Identity common.Address
+}
+
+// NewEpochClientWithClients creates the epoch client with the given clients instead
+// of the chain and the indexer, e.g. mocks for simulations
+func NewEpochClientWithClients(cfg *clientConfig.ClientConfig, clients Clients) *EpochClient {
+	identity := clients.Identity
+	if identity == (common.Address{}) {
+		identity = cfg.Identity.Address
+	}
 	return &EpochClient{
-		db:                    db,
-		systemsManagerClient:  systemsManagerClient,
-		relayClient:           relayClient,
-		registryClient:        registryClient,
-		identityAddress:       identityAddress,
-		registrationEnabled:   cfg.Clients.EnabledRegistration,
-		uptimeVotingEnabled:   cfg.Clients.EnabledUptimeVoting,
-		rewardsSigningEnabled: cfg.Clients.EnabledRewardSigning,
-		rewardsConfig:         &cfg.Rewards,
-		uptimeConfig:          &cfg.Uptime,
-	}, nil
+		db:                       clients.DB,
+		systemsManagerClient:     clients.SystemsManager,
+		relayClient:              clients.Relay,
+		registryClient:           clients.Registry,
+		identityAddress:          identity,
+		registrationEnabled:      cfg.Clients.EnabledRegistration,
+		uptimeVotingEnabled:      cfg.Clients.EnabledUptimeVoting,
+		rewardsSigningEnabled:    cfg.Clients.EnabledRewardSigning,
+		rewardsConfig:            &cfg.Rewards,
+		uptimeConfig:             &cfg.Uptime,
+		retryConfig:              &cfg.Retry,
+		registrationAlertAfter:   cfg.Alerts.RegistrationTimeout,
+		policySigningAlertBefore: cfg.PolicySigning.AlertBefore,
+		lifecycle:                newEpochLifecycle(clients.DB, identity),
+		checkpoints:              newListenerCheckpoints(clients.DB),
+	}
 }
 
 // Run runs the  client, should be called in a goroutine
@@ -130,106 +281,294 @@ func (c *EpochClient) RunContext(ctx context.Context) error {
 
 	if c.registrationEnabled {
 		logger.Info("Waiting for VotePowerBlockSelected event to start registration")
-		vpbsListener = c.systemsManagerClient.VotePowerBlockSelectedListener(c.db, epoch)
-		policyListener = c.relayClient.SigningPolicyInitializedListener(c.db, epoch)
+		vpbsListener = c.systemsManagerClient.VotePowerBlockSelectedListener(ctx, c.db, epoch)
+		policyListener = c.relayClient.SigningPolicyInitializedListener(ctx, c.db, epoch)
 	}
 	if c.uptimeVotingEnabled {
 		logger.Info("Waiting for SignUptimeVoteEnabled event to start uptime vote signing")
-		uptimeEnabledListener = c.systemsManagerClient.SignUptimeVoteEnabledListener(c.db, epoch, c.uptimeConfig.SigningWindow)
+		uptimeEnabledListener = c.systemsManagerClient.SignUptimeVoteEnabledListener(ctx, c.db, epoch, c.uptimeConfig.SigningWindow)
 	}
 	if c.rewardsSigningEnabled {
 		logger.Info("Waiting for UptimeVoteSigned event to start rewards signing")
-		uptimeSignedListener = c.systemsManagerClient.UptimeVoteSignedListener(c.db, epoch, c.rewardsConfig.SigningWindow)
+		uptimeSignedListener = c.systemsManagerClient.UptimeVoteSignedListener(ctx, c.db, epoch, c.rewardsConfig.SigningWindow)
 	}
 
 	for {
 		select {
 		case powerBlockData := <-vpbsListener:
 			logger.Debug("VotePowerBlockSelected event emitted for epoch %v", powerBlockData.RewardEpochId)
-			c.registerVoter(powerBlockData.RewardEpochId)
+			c.lifecycle.Advance(powerBlockData.RewardEpochId.Int64(), stateVotePowerBlockSelected)
+			c.refreshEpoch(epoch)
+			c.watchRegistration(ctx, powerBlockData)
+			if c.registerVoter(powerBlockData.RewardEpochId) {
+				c.checkpoints.handled(listenerVotePowerBlockSelected, powerBlockData.Timestamp)
+			}
 		case signingPolicy := <-policyListener:
 			logger.Debug("SigningPolicyInitialized event emitted for epoch %v", signingPolicy.RewardEpochId)
-			c.signPolicy(signingPolicy.RewardEpochId, signingPolicy.SigningPolicyBytes)
+			if c.signPolicy(ctx, epoch, signingPolicy) {
+				c.checkpoints.handled(listenerSigningPolicyInitialized, signingPolicy.Timestamp)
+			}
 		case uptimeVoteEnabled := <-uptimeEnabledListener:
 			logger.Debug("SignUptimeVoteEnabled event emitted for epoch %v", uptimeVoteEnabled.RewardEpochId)
-			c.signUptimeVote(uptimeVoteEnabled.RewardEpochId)
+			if c.signUptimeVote(ctx, uptimeVoteEnabled.RewardEpochId) {
+				c.checkpoints.handled(listenerSignUptimeVoteEnabled, uptimeVoteEnabled.Timestamp)
+			}
 		case uptimeVoteSigned := <-uptimeSignedListener:
 			logger.Info("Uptime vote threshold reached for epoch %v, signing rewards", uptimeVoteSigned.RewardEpochId)
-			c.signRewards(uptimeVoteSigned.RewardEpochId)
+			epochId := uptimeVoteSigned.RewardEpochId
+			// the rewards hash may be published later, fetching it must not block other events
+			if c.lifecycle.Begin(epochId.Int64(), stateRewardsSigned) {
+				c.background.Add(1)
+				go func() {
+					defer c.background.Done()
+					c.signRewards(ctx, epochId)
+					if c.reached(epochId, stateRewardsSigned) {
+						c.checkpoints.handled(listenerUptimeVoteSigned, uptimeVoteSigned.Timestamp)
+					}
+				}()
+			} else if c.reached(epochId, stateRewardsSigned) {
+				c.checkpoints.handled(listenerUptimeVoteSigned, uptimeVoteSigned.Timestamp)
+			}
 
 		case <-ctx.Done():
+			c.background.Wait()
 			return ctx.Err()
 		}
 	}
 }
 
-func (c *EpochClient) registerVoter(epochId *big.Int) {
-	if !c.isFutureEpoch(epochId) {
+// Adds the duration of the next reward epoch if it was changed
+func (c *EpochClient) refreshEpoch(epoch *utils.Epoch) {
+	changed, err := c.systemsManagerClient.RefreshRewardEpoch(epoch)
+	if err != nil {
+		logger.Warn("Error refreshing reward epoch settings: %v", err)
+	} else if changed {
+		logger.Info("Reward epoch schedule changed: %+v", epoch.Changes())
+	}
+}
+
+// Returns true if the event was handled: the voter is registered or the epoch is
+// in the past. Otherwise the event is emitted again after a restart.
+func (c *EpochClient) registerVoter(epochId *big.Int) bool {
+	future, err := c.isFutureEpoch(epochId)
+	if err != nil {
+		return false
+	}
+	if !future {
 		logger.Debug("Skipping registration process for old epoch %v", epochId)
-		return
+		return true
+	}
+
+	if !c.lifecycle.Begin(epochId.Int64(), stateRegistered) {
+		return c.reached(epochId, stateRegistered)
 	}
 
 	logger.Info("VotePowerBlockSelected event emitted for next epoch %v, starting registration", epochId)
 	registerResult := <-c.registryClient.RegisterVoter(epochId, c.identityAddress)
 	if registerResult.Success {
-		logger.Info("RegisterVoter success")
-	} else {
-		logger.Error("RegisterVoter failed %s", registerResult.Message)
+		logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Info("RegisterVoter success")
+		c.lifecycle.Advance(epochId.Int64(), stateRegistered)
+		alerts.Resolve(alerts.RegistrationWindowClosing, epochId.String(), c.registrationAlertFields(epochId))
+		return true
 	}
+	logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Error("RegisterVoter failed %s", registerResult.Message)
+	c.lifecycle.Fail(epochId.Int64(), stateRegistered, registerResult.Message)
+	return false
 }
 
-func (c *EpochClient) signPolicy(epochId *big.Int, policy []byte) {
-	if !c.isFutureEpoch(epochId) {
-		logger.Debug("Skipping policy signing for old epoch %v", epochId)
+// Fires the registration_window_closing alert if the voter is not registered for the
+// next reward epoch registrationAlertAfter after its vote power block was selected
+func (c *EpochClient) watchRegistration(ctx context.Context, event *system.FlareSystemsManagerVotePowerBlockSelected) {
+	if !alerts.Enabled() {
 		return
 	}
+	if future, err := c.isFutureEpoch(event.RewardEpochId); err != nil || !future {
+		return
+	}
+	epochId := event.RewardEpochId
+	deadline := time.Unix(int64(event.Timestamp), 0).Add(c.registrationAlertAfter)
+	go func() {
+		select {
+		case <-time.After(time.Until(deadline)):
+		case <-ctx.Done():
+			return
+		}
+		if stateRank(c.lifecycle.State(epochId.Int64())) < stateRank(stateRegistered) {
+			alerts.Fire(alerts.RegistrationWindowClosing, epochId.String(), c.registrationAlertFields(epochId))
+		}
+	}()
+}
+
+func (c *EpochClient) registrationAlertFields(epochId *big.Int) map[string]string {
+	return map[string]string{
+		"voter":        c.identityAddress.Hex(),
+		"reward_epoch": epochId.String(),
+		"elapsed":      c.registrationAlertAfter.String(),
+	}
+}
+
+// Returns true if the event was handled: the policy is signed or the epoch is in
+// the past
+func (c *EpochClient) signPolicy(ctx context.Context, epoch *utils.Epoch, policy *relay.RelaySigningPolicyInitialized) bool {
+	epochId := policy.RewardEpochId
+	future, err := c.isFutureEpoch(epochId)
+	if err != nil {
+		return false
+	}
+	if !future {
+		logger.Debug("Skipping policy signing for old epoch %v", epochId)
+		return true
+	}
+	if !c.lifecycle.Begin(epochId.Int64(), statePolicySigned) {
+		return c.reached(epochId, statePolicySigned)
+	}
 
-	logger.Info("SigningPolicyInitialized event emitted for next epoch %v, signing new policy", epochId)
-	signingResult := <-c.systemsManagerClient.SignNewSigningPolicy(epochId, policy)
+	deadline := policySigningDeadline(epoch, epochId)
+	logger.Info("SigningPolicyInitialized event emitted for next epoch %v, signing new policy, the signing window closes at %v", epochId, deadline)
+	c.watchPolicySigning(ctx, epochId, deadline)
+	if err := c.verifySigningPolicy(policy); err != nil {
+		logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Error("Refusing to sign signing policy: %v", err)
+		c.lifecycle.Fail(epochId.Int64(), statePolicySigned, err.Error())
+		return false
+	}
+	signingResult := <-c.systemsManagerClient.SignNewSigningPolicy(epochId, policy.SigningPolicyBytes, deadline)
 	if signingResult.Success {
-		logger.Info("SignNewSigningPolicy success")
-	} else {
-		logger.Error("SignNewSigningPolicy failed %s", signingResult.Message)
+		logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Info("SignNewSigningPolicy success")
+		c.lifecycle.Advance(epochId.Int64(), statePolicySigned)
+		policySigningTimeLeft.WithLabelValues(c.identityAddress.Hex()).Set(time.Until(deadline).Seconds())
+		alerts.Resolve(alerts.SigningPolicyDeadline, epochId.String(), c.policySigningAlertFields(epochId, deadline))
+		return true
+	}
+	logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Error("SignNewSigningPolicy failed %s", signingResult.Message)
+	c.lifecycle.Fail(epochId.Int64(), statePolicySigned, signingResult.Message)
+	return false
+}
+
+// End of the signing window of the signing policy, the expected start of its reward epoch
+func policySigningDeadline(epoch *utils.Epoch, rewardEpochId *big.Int) time.Time {
+	return epoch.StartTime(rewardEpochId.Int64())
+}
+
+// Fires the signing_policy_deadline alert if the signing policy of the reward epoch is
+// not signed policySigningAlertBefore the end of the signing window
+func (c *EpochClient) watchPolicySigning(ctx context.Context, epochId *big.Int, deadline time.Time) {
+	if !alerts.Enabled() {
 		return
 	}
+	go func() {
+		select {
+		case <-time.After(time.Until(deadline.Add(-c.policySigningAlertBefore))):
+		case <-ctx.Done():
+			return
+		}
+		if stateRank(c.lifecycle.State(epochId.Int64())) < stateRank(statePolicySigned) {
+			alerts.Fire(alerts.SigningPolicyDeadline, epochId.String(), c.policySigningAlertFields(epochId, deadline))
+		}
+	}()
+}
+
+func (c *EpochClient) policySigningAlertFields(epochId *big.Int, deadline time.Time) map[string]string {
+	return map[string]string{
+		"voter":        c.identityAddress.Hex(),
+		"reward_epoch": epochId.String(),
+		"deadline":     deadline.UTC().Format(time.RFC3339),
+	}
+}
+
+// Verifies the signing policy against its fields and the signing policy hash on chain
+func (c *EpochClient) verifySigningPolicy(policy *relay.RelaySigningPolicyInitialized) error {
+	hashResult := <-c.relayClient.SigningPolicyHash(policy.RewardEpochId)
+	if !hashResult.Success {
+		return errors.Errorf("error fetching signing policy hash: %s", hashResult.Message)
+	}
+	return verifySigningPolicy(policy, hashResult.Value)
 }
 
-func (c *EpochClient) signUptimeVote(epochId *big.Int) {
+// Returns true if the event was handled, the uptime vote is signed
+func (c *EpochClient) signUptimeVote(ctx context.Context, epochId *big.Int) bool {
+	if !c.lifecycle.Begin(epochId.Int64(), stateUptimeSigned) {
+		return c.reached(epochId, stateUptimeSigned)
+	}
+
 	logger.Info("SignUptimeVoteEnabled event emitted for epoch %v, signing uptime vote", epochId)
-	signUptimeVoteResult := <-c.systemsManagerClient.SignUptimeVote(epochId)
+	hash, err := getUptimeVoteHash(ctx, epochId, c.uptimeConfig, c.retryConfig.Policy(clientConfig.RetryOpFetchUptimeVoteHash))
+	if err != nil {
+		logger.Error("error obtaining uptime vote hash for epoch %v, restart client to retry: %s", epochId, err)
+		c.lifecycle.Fail(epochId.Int64(), stateUptimeSigned, err.Error())
+		return false
+	}
+	signUptimeVoteResult := <-c.systemsManagerClient.SignUptimeVote(epochId, hash)
 	if signUptimeVoteResult.Success {
-		logger.Info("SignUptimeVote completed")
-	} else {
-		logger.Error("SignUptimeVote failed %s", signUptimeVoteResult.Message)
-		return
+		logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Info("SignUptimeVote completed")
+		c.lifecycle.Advance(epochId.Int64(), stateUptimeSigned)
+		return true
 	}
+	logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Error("SignUptimeVote failed %s", signUptimeVoteResult.Message)
+	c.lifecycle.Fail(epochId.Int64(), stateUptimeSigned, signUptimeVoteResult.Message)
+	return false
 }
 
-func (c *EpochClient) isFutureEpoch(epochId *big.Int) bool {
+// Returns true if the reward epoch reached the state
+func (c *EpochClient) reached(epochId *big.Int, state string) bool {
+	return stateRank(c.lifecycle.State(epochId.Int64())) >= stateRank(state)
+}
+
+func (c *EpochClient) isFutureEpoch(epochId *big.Int) (bool, error) {
 	epochIdResult := <-c.systemsManagerClient.GetCurrentRewardEpochId()
 	if !epochIdResult.Success {
 		logger.Error("GetCurrentRewardEpochId failed %s", epochIdResult.Message)
-		return false
+		return false, errors.New(epochIdResult.Message)
 	}
 	currentEpochId := epochIdResult.Value
 	if epochId.Cmp(currentEpochId) <= 0 {
 		logger.Debug("Epoch in the past: current %v >= next %v", currentEpochId, epochId)
-		return false
+		return false, nil
 	}
-	return true
+	return true, nil
 }
 
-func (c *EpochClient) signRewards(epochId *big.Int) {
+func (c *EpochClient) signRewards(ctx context.Context, epochId *big.Int) {
 	logger.Info("Signing rewards for epoch %v", epochId)
-	hash, weightClaims, err := getRewardsHash(epochId, c.rewardsConfig)
+	hash, weightClaims, err := getRewardsHash(ctx, epochId, c.rewardsConfig, c.retryConfig.Policy(clientConfig.RetryOpFetchRewardsHash))
+	if ctx.Err() != nil {
+		logger.Info("Rewards signing for epoch %v stopped", epochId)
+		return
+	}
 	if err != nil {
 		logger.Error("error obtaining reward hash data for epoch %v, restart client to retry: %s", epochId, err)
+		c.lifecycle.Fail(epochId.Int64(), stateRewardsSigned, err.Error())
+		return
+	}
+	signingResult := <-c.systemsManagerClient.SignRewards(ctx, epochId, hash, weightClaims)
+	if !signingResult.Success && ctx.Err() != nil {
+		logger.Info("Rewards signing for epoch %v stopped", epochId)
 		return
 	}
-	signingResult := <-c.systemsManagerClient.SignRewards(epochId, hash, weightClaims)
 	if signingResult.Success {
-		logger.Info("SignRewards completed")
+		logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Info("SignRewards completed")
+		c.lifecycle.Advance(epochId.Int64(), stateRewardsSigned)
 	} else {
-		logger.Error("SignRewards failed %s", signingResult.Message)
+		logger.With("rewardEpochId", epochId, "identity", c.identityAddress.Hex()).Error("SignRewards failed %s", signingResult.Message)
+		c.lifecycle.Fail(epochId.Int64(), stateRewardsSigned, signingResult.Message)
+	}
+}
+
+// Implementation of admin.EpochClient
+func (c *EpochClient) RewardEpochs() []admin.RewardEpochInfo {
+	return c.lifecycle.RewardEpochs()
+}
+
+// Epoch clients of identity.address and of the additional identities, each registers
+// and signs with the keys of its identity
+type EpochClients []*EpochClient
+
+// Implementation of admin.EpochClient, the reward epochs of all identities by reward
+// epoch id descending
+func (c EpochClients) RewardEpochs() []admin.RewardEpochInfo {
+	var result []admin.RewardEpochInfo
+	for _, client := range c {
+		result = append(result, client.RewardEpochs()...)
 	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].RewardEpochId > result[j].RewardEpochId })
+	return result
 }
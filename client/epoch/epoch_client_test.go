@@ -2,15 +2,18 @@ package epoch
 
 import (
 	"context"
+	clientConfig "flare-tlc/client/config"
 	"flare-tlc/client/shared"
 	"flare-tlc/config"
 	"flare-tlc/database"
-	"flare-tlc/logger"
+	flarelogger "flare-tlc/logger"
 	"flare-tlc/utils"
 	"flare-tlc/utils/contracts/relay"
 	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/signingpolicy"
 	"math/big"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -22,7 +25,7 @@ import (
 )
 
 func TestMain(m *testing.M) {
-	logger.Configure(config.LoggerConfig{
+	flarelogger.Configure(config.LoggerConfig{
 		Level:   "DEBUG",
 		Console: true,
 	})
@@ -37,6 +40,8 @@ func TestEpochClientMainline(t *testing.T) {
 
 	c := &EpochClient{
 		db:                   testDB{},
+		lifecycle:            newEpochLifecycle(testDB{}, common.Address{}),
+		checkpoints:          newListenerCheckpoints(testDB{}),
 		systemsManagerClient: systemsManagerClient,
 		relayClient:          relayClient,
 		registryClient:       registryClient,
@@ -52,7 +57,8 @@ func TestEpochClientMainline(t *testing.T) {
 	})
 
 	rewardEpochID := big.NewInt(2)
-	signingPolicyBytes := []byte{1, 2, 3}
+	policy := newTestSigningPolicy(t, rewardEpochID)
+	signingPolicyBytes := policy.SigningPolicyBytes
 
 	t.Log("sending test VPBS")
 	systemsManagerClient.sendTestVPBS(&system.FlareSystemsManagerVotePowerBlockSelected{
@@ -60,11 +66,7 @@ func TestEpochClientMainline(t *testing.T) {
 	})
 
 	t.Log("sending test policy")
-	relayClient.sendTestPolicy(&relay.RelaySigningPolicyInitialized{
-		RewardEpochId:      rewardEpochID,
-		Seed:               big.NewInt(1),
-		SigningPolicyBytes: signingPolicyBytes,
-	})
+	relayClient.sendTestPolicy(policy)
 
 	t.Log("stopping runner")
 	cancel()
@@ -88,9 +90,12 @@ func TestEpochClientInvalidEpoch(t *testing.T) {
 	systemsManagerClient := newTestSystemsManagerClient()
 	relayClient := newTestRelayClient()
 	registryClient := newTestRegistryClient()
+	db := &testCheckpointDB{checkpoints: make(map[string]int64)}
 
 	c := &EpochClient{
 		db:                   testDB{},
+		lifecycle:            newEpochLifecycle(testDB{}, common.Address{}),
+		checkpoints:          newListenerCheckpoints(db),
 		systemsManagerClient: systemsManagerClient,
 		relayClient:          relayClient,
 		registryClient:       registryClient,
@@ -111,6 +116,7 @@ func TestEpochClientInvalidEpoch(t *testing.T) {
 	t.Log("sending test VPBS")
 	systemsManagerClient.sendTestVPBS(&system.FlareSystemsManagerVotePowerBlockSelected{
 		RewardEpochId: rewardEpochID,
+		Timestamp:     100,
 	})
 
 	t.Log("stopping runner")
@@ -123,6 +129,51 @@ func TestEpochClientInvalidEpoch(t *testing.T) {
 
 	t.Logf("signed policies: %v", systemsManagerClient.signedPolicies)
 	require.Empty(t, systemsManagerClient.signedPolicies)
+
+	// the skipped event is handled, it is not emitted again after a restart
+	require.EqualValues(t, 100, db.checkpoints[listenerVotePowerBlockSelected])
+}
+
+func TestEpochClientRegistrationDisabled(t *testing.T) {
+	systemsManagerClient := newTestSystemsManagerClient()
+	db := &testCheckpointDB{checkpoints: make(map[string]int64)}
+
+	c := &EpochClient{
+		db:                   testDB{},
+		lifecycle:            newEpochLifecycle(testDB{}, common.Address{}),
+		checkpoints:          newListenerCheckpoints(db),
+		systemsManagerClient: systemsManagerClient,
+		relayClient:          newTestRelayClient(),
+		registryClient:       newTestRegistryClient(),
+		identityAddress:      common.HexToAddress("0x123456"),
+		uptimeVotingEnabled:  true,
+		uptimeConfig:         &clientConfig.UptimeConfig{},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		return c.RunContext(ctx)
+	})
+
+	systemsManagerClient.uptimeEnabledChan <- &system.FlareSystemsManagerSignUptimeVoteEnabled{
+		RewardEpochId: big.NewInt(1),
+		Timestamp:     100,
+	}
+	// the uptime vote of the epoch is signed, the event is skipped
+	systemsManagerClient.uptimeEnabledChan <- &system.FlareSystemsManagerSignUptimeVoteEnabled{
+		RewardEpochId: big.NewInt(1),
+		Timestamp:     110,
+	}
+
+	cancel()
+	err := eg.Wait()
+	require.True(t, errors.Is(err, context.Canceled), "unexpected error: %s", err.Error())
+
+	require.Equal(t, stateUptimeSigned, c.lifecycle.State(1))
+	require.EqualValues(t, 110, db.checkpoints[listenerSignUptimeVoteEnabled])
+	require.NotContains(t, db.checkpoints, listenerVotePowerBlockSelected)
 }
 
 func TestEpochClientSigningErr(t *testing.T) {
@@ -134,6 +185,8 @@ func TestEpochClientSigningErr(t *testing.T) {
 
 	c := &EpochClient{
 		db:                   testDB{},
+		lifecycle:            newEpochLifecycle(testDB{}, common.Address{}),
+		checkpoints:          newListenerCheckpoints(testDB{}),
 		systemsManagerClient: systemsManagerClient,
 		relayClient:          relayClient,
 		registryClient:       registryClient,
@@ -149,7 +202,6 @@ func TestEpochClientSigningErr(t *testing.T) {
 	})
 
 	rewardEpochID := big.NewInt(2)
-	signingPolicyBytes := []byte{1, 2, 3}
 
 	t.Log("sending test VPBS")
 	systemsManagerClient.sendTestVPBS(&system.FlareSystemsManagerVotePowerBlockSelected{
@@ -157,11 +209,7 @@ func TestEpochClientSigningErr(t *testing.T) {
 	})
 
 	t.Log("sending test policy")
-	relayClient.sendTestPolicy(&relay.RelaySigningPolicyInitialized{
-		RewardEpochId:      rewardEpochID,
-		Seed:               big.NewInt(1),
-		SigningPolicyBytes: signingPolicyBytes,
-	})
+	relayClient.sendTestPolicy(newTestSigningPolicy(t, rewardEpochID))
 
 	t.Log("stopping runner")
 	cancel()
@@ -176,6 +224,44 @@ func TestEpochClientSigningErr(t *testing.T) {
 	require.Empty(t, systemsManagerClient.signedPolicies)
 }
 
+func TestEpochClientInvalidSigningPolicy(t *testing.T) {
+	systemsManagerClient := newTestSystemsManagerClient()
+	relayClient := newTestRelayClient()
+
+	c := &EpochClient{
+		db:                   testDB{},
+		lifecycle:            newEpochLifecycle(testDB{}, common.Address{}),
+		checkpoints:          newListenerCheckpoints(testDB{}),
+		systemsManagerClient: systemsManagerClient,
+		relayClient:          relayClient,
+		registryClient:       newTestRegistryClient(),
+		identityAddress:      common.HexToAddress("0x123456"),
+		registrationEnabled:  true,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	eg, ctx := errgroup.WithContext(ctx)
+
+	eg.Go(func() error {
+		return c.RunContext(ctx)
+	})
+
+	// the policy bytes do not match the event fields
+	t.Log("sending test policy")
+	relayClient.sendTestPolicy(&relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      big.NewInt(2),
+		Seed:               big.NewInt(1),
+		SigningPolicyBytes: []byte{1, 2, 3},
+	})
+
+	t.Log("stopping runner")
+	cancel()
+	err := eg.Wait()
+	require.True(t, errors.Is(err, context.Canceled), "unexpected error: %s", err.Error())
+
+	require.Empty(t, systemsManagerClient.signedPolicies)
+}
+
 func TestEpochClientRewardEpochErr(t *testing.T) {
 	systemsManagerClient := newTestSystemsManagerClient()
 	systemsManagerClient.rewardEpochErr = errors.New("reward epoch error")
@@ -185,6 +271,8 @@ func TestEpochClientRewardEpochErr(t *testing.T) {
 
 	c := &EpochClient{
 		db:                   testDB{},
+		lifecycle:            newEpochLifecycle(testDB{}, common.Address{}),
+		checkpoints:          newListenerCheckpoints(testDB{}),
 		systemsManagerClient: systemsManagerClient,
 		relayClient:          relayClient,
 		registryClient:       registryClient,
@@ -226,6 +314,8 @@ func TestEpochClientRegisterErr(t *testing.T) {
 
 	c := &EpochClient{
 		db:                   testDB{},
+		lifecycle:            newEpochLifecycle(testDB{}, common.Address{}),
+		checkpoints:          newListenerCheckpoints(testDB{}),
 		systemsManagerClient: systemsManagerClient,
 		relayClient:          relayClient,
 		registryClient:       registryClient,
@@ -267,12 +357,30 @@ func (db testDB) FetchLogsByAddressAndTopic0(
 	return nil, errors.New("not implemented")
 }
 
+func (db testDB) FetchListenerCheckpoint(listener string) (int64, error) {
+	return 0, nil
+}
+
+func (db testDB) SaveListenerCheckpoint(listener string, timestamp int64) error {
+	return nil
+}
+
+func (db testDB) FetchRewardEpochStates(limit int) ([]database.RewardEpochState, error) {
+	return nil, nil
+}
+
+func (db testDB) SaveRewardEpochState(state *database.RewardEpochState) error {
+	return nil
+}
+
 type testSystemsManagerClient struct {
 	rewardEpoch    *utils.Epoch
 	rewardEpochErr error
 	vpbsChan       chan *system.FlareSystemsManagerVotePowerBlockSelected
 	signedPolicies map[string][]byte
-	signingErr     error
+
+	uptimeEnabledChan chan *system.FlareSystemsManagerSignUptimeVoteEnabled
+	signingErr        error
 }
 
 func newTestSystemsManagerClient() testSystemsManagerClient {
@@ -283,6 +391,8 @@ func newTestSystemsManagerClient() testSystemsManagerClient {
 		},
 		vpbsChan:       make(chan *system.FlareSystemsManagerVotePowerBlockSelected),
 		signedPolicies: make(map[string][]byte),
+
+		uptimeEnabledChan: make(chan *system.FlareSystemsManagerSignUptimeVoteEnabled),
 	}
 }
 
@@ -294,14 +404,18 @@ func (c testSystemsManagerClient) RewardEpochFromChain() (*utils.Epoch, error) {
 	return c.rewardEpoch, nil
 }
 
+func (c testSystemsManagerClient) RefreshRewardEpoch(epoch *utils.Epoch) (bool, error) {
+	return false, nil
+}
+
 func (c testSystemsManagerClient) VotePowerBlockSelectedListener(
-	db epochClientDB, epoch *utils.Epoch,
+	ctx context.Context, db EpochClientDB, epoch *utils.Epoch,
 ) <-chan *system.FlareSystemsManagerVotePowerBlockSelected {
 	return c.vpbsChan
 }
 
 func (c testSystemsManagerClient) SignNewSigningPolicy(
-	epochID *big.Int, policy []byte,
+	epochID *big.Int, policy []byte, _ time.Time,
 ) <-chan shared.ExecuteStatus[any] {
 	return shared.ExecuteWithRetry(func() (any, error) {
 		if c.signingErr != nil {
@@ -331,21 +445,60 @@ func (c testSystemsManagerClient) GetCurrentRewardEpochId() <-chan shared.Execut
 }
 
 type testRelayClient struct {
-	policyChan chan *relay.RelaySigningPolicyInitialized
+	policyChan   chan *relay.RelaySigningPolicyInitialized
+	policyHashes map[string]common.Hash
+	mu           *sync.Mutex
 }
 
 func newTestRelayClient() testRelayClient {
 	return testRelayClient{
-		policyChan: make(chan *relay.RelaySigningPolicyInitialized),
+		policyChan:   make(chan *relay.RelaySigningPolicyInitialized),
+		policyHashes: make(map[string]common.Hash),
+		mu:           &sync.Mutex{},
 	}
 }
 
+// The hash of the sent policy is stored as on the Relay contract
 func (c testRelayClient) sendTestPolicy(policy *relay.RelaySigningPolicyInitialized) {
+	c.mu.Lock()
+	c.policyHashes[policy.RewardEpochId.String()] = signingpolicy.Hash(policy.SigningPolicyBytes)
+	c.mu.Unlock()
+
 	c.policyChan <- policy
 }
 
+func (c testRelayClient) SigningPolicyHash(rewardEpochId *big.Int) <-chan shared.ExecuteStatus[common.Hash] {
+	return shared.ExecuteWithRetry(func() (common.Hash, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return c.policyHashes[rewardEpochId.String()], nil
+	}, 1, 0)
+}
+
+func newTestSigningPolicy(t *testing.T, rewardEpochId *big.Int) *relay.RelaySigningPolicyInitialized {
+	policy := &relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      rewardEpochId,
+		StartVotingRoundId: 1000,
+		Threshold:          500,
+		Seed:               big.NewInt(1),
+		Voters:             []common.Address{common.HexToAddress("0x123456"), common.HexToAddress("0xabcdef")},
+		Weights:            []uint16{600, 400},
+	}
+	policyBytes, err := signingpolicy.EncodeEvent(policy)
+	require.NoError(t, err)
+	policy.SigningPolicyBytes = policyBytes
+	return policy
+}
+
+func (c testRelayClient) FetchSigningPolicy(
+	db EpochClientDB, rewardEpochId *big.Int, from, to int64,
+) (*relay.RelaySigningPolicyInitialized, error) {
+	return nil, nil
+}
+
 func (c testRelayClient) SigningPolicyInitializedListener(
-	db epochClientDB, epoch *utils.Epoch,
+	ctx context.Context, db EpochClientDB, epoch *utils.Epoch,
 ) <-chan *relay.RelaySigningPolicyInitialized {
 	return c.policyChan
 }
@@ -385,21 +538,21 @@ func (c testRegistryClient) RegisterVoter(
 	}, 1, 0)
 }
 
-func (c testSystemsManagerClient) SignUptimeVoteEnabledListener(db epochClientDB, epoch *utils.Epoch, i int64) <-chan *system.FlareSystemsManagerSignUptimeVoteEnabled {
-	return make(chan *system.FlareSystemsManagerSignUptimeVoteEnabled)
+func (c testSystemsManagerClient) SignUptimeVoteEnabledListener(ctx context.Context, db EpochClientDB, epoch *utils.Epoch, i int64) <-chan *system.FlareSystemsManagerSignUptimeVoteEnabled {
+	return c.uptimeEnabledChan
 }
 
-func (c testSystemsManagerClient) SignUptimeVote(b *big.Int) <-chan shared.ExecuteStatus[any] {
+func (c testSystemsManagerClient) SignUptimeVote(b *big.Int, hash common.Hash) <-chan shared.ExecuteStatus[any] {
 	return shared.ExecuteWithRetry(func() (any, error) {
 		return nil, nil
 	}, 1, 0)
 }
 
-func (c testSystemsManagerClient) UptimeVoteSignedListener(db epochClientDB, epoch *utils.Epoch, window int64) <-chan *system.FlareSystemsManagerUptimeVoteSigned {
+func (c testSystemsManagerClient) UptimeVoteSignedListener(ctx context.Context, db EpochClientDB, epoch *utils.Epoch, window int64) <-chan *system.FlareSystemsManagerUptimeVoteSigned {
 	return make(chan *system.FlareSystemsManagerUptimeVoteSigned)
 }
 
-func (c testSystemsManagerClient) SignRewards(b *big.Int, hash *common.Hash, claims int) <-chan shared.ExecuteStatus[any] {
+func (c testSystemsManagerClient) SignRewards(ctx context.Context, b *big.Int, hash *common.Hash, claims int) <-chan shared.ExecuteStatus[any] {
 	return shared.ExecuteWithRetry(func() (any, error) {
 		return nil, nil
 	}, 1, 0)
@@ -0,0 +1,218 @@
+package epoch
+
+import (
+	"flare-tlc/client/admin"
+	"flare-tlc/database"
+	flarelogger "flare-tlc/logger"
+	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Lifecycle states of a reward epoch, in order. The registration and the signing
+// policy are states of the next reward epoch, the uptime vote and the rewards of
+// the ended one. States of disabled clients are skipped.
+const (
+	stateWaiting                = "waiting"
+	stateVotePowerBlockSelected = "vote_power_block_selected"
+	stateRegistered             = "registered"
+	statePolicySigned           = "policy_signed"
+	stateUptimeSigned           = "uptime_signed"
+	stateRewardsSigned          = "rewards_signed"
+)
+
+var epochStates = []string{
+	stateWaiting,
+	stateVotePowerBlockSelected,
+	stateRegistered,
+	statePolicySigned,
+	stateUptimeSigned,
+	stateRewardsSigned,
+}
+
+// Number of the most recent reward epochs kept in memory and loaded on start
+const lifecycleEpochs = 16
+
+func stateRank(state string) int {
+	for i, s := range epochStates {
+		if s == state {
+			return i
+		}
+	}
+	return 0
+}
+
+type epochStatus struct {
+	state     string
+	updatedAt time.Time
+	// target state of the running action, empty if there is none
+	pending   string
+	lastError string
+}
+
+// State machine of the reward epochs. An epoch only moves forward: actions are
+// started for a state after the current one and the epoch advances to the state
+// when the action succeeds. Events re-emitted after a restart do not repeat
+// finished actions, the states are persisted with the listener checkpoints.
+type epochLifecycle struct {
+	mu     sync.Mutex
+	db     EpochClientDB
+	clock  utils.Clock
+	epochs map[int64]*epochStatus
+
+	// voter identity of the epochs, the label of the metrics
+	identity string
+	logger   *flarelogger.Logger
+}
+
+func newEpochLifecycle(db EpochClientDB, identity common.Address) *epochLifecycle {
+	l := &epochLifecycle{
+		db:       db,
+		clock:    utils.RealClock,
+		epochs:   make(map[int64]*epochStatus),
+		identity: identity.Hex(),
+		logger:   logger.With("identity", identity.Hex()),
+	}
+	states, err := db.FetchRewardEpochStates(lifecycleEpochs)
+	if err != nil {
+		l.logger.Warn("Error fetching reward epoch states, starting from %s: %v", stateWaiting, err)
+		return l
+	}
+	for _, s := range states {
+		l.epochs[int64(s.RewardEpochId)] = &epochStatus{
+			state:     s.State,
+			updatedAt: time.Unix(int64(s.UpdatedAt), 0),
+			lastError: s.LastError,
+		}
+		l.logger.Info("Reward epoch %d resumed in state %s", s.RewardEpochId, s.State)
+		rewardEpochStateGauge.WithLabelValues(l.identity, s.State).Set(float64(s.RewardEpochId))
+	}
+	return l
+}
+
+func (l *epochLifecycle) status(epochId int64) *epochStatus {
+	status, ok := l.epochs[epochId]
+	if !ok {
+		status = &epochStatus{state: stateWaiting, updatedAt: l.clock.Now()}
+		l.epochs[epochId] = status
+		l.prune()
+	}
+	return status
+}
+
+// Drops the oldest epochs from memory
+func (l *epochLifecycle) prune() {
+	if len(l.epochs) <= lifecycleEpochs {
+		return
+	}
+	ids := make([]int64, 0, len(l.epochs))
+	for id := range l.epochs {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids[:len(ids)-lifecycleEpochs] {
+		delete(l.epochs, id)
+	}
+}
+
+func (l *epochLifecycle) State(epochId int64) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.status(epochId).state
+}
+
+// Begin returns true if the action reaching the target state should run: the epoch
+// is before the state and no action for it is running
+func (l *epochLifecycle) Begin(epochId int64, target string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := l.status(epochId)
+	if stateRank(status.state) >= stateRank(target) {
+		l.logger.Info("Reward epoch %d is in state %s, skipping %s", epochId, status.state, target)
+		return false
+	}
+	if status.pending == target {
+		l.logger.Info("Reward epoch %d: %s is already in progress", epochId, target)
+		return false
+	}
+	status.pending = target
+	return true
+}
+
+// Advance moves the epoch to the state, earlier states are ignored
+func (l *epochLifecycle) Advance(epochId int64, state string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := l.status(epochId)
+	if status.pending == state {
+		status.pending = ""
+	}
+	if stateRank(status.state) >= stateRank(state) {
+		return
+	}
+	l.logger.Info("Reward epoch %d: %s -> %s", epochId, status.state, state)
+	status.state = state
+	status.updatedAt = l.clock.Now()
+	status.lastError = ""
+	rewardEpochStateGauge.WithLabelValues(l.identity, state).Set(float64(epochId))
+	rewardEpochTransitions.WithLabelValues(l.identity, state).Inc()
+	l.save(epochId, status)
+}
+
+// Fail records the failed action, it can be started again
+func (l *epochLifecycle) Fail(epochId int64, target string, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := l.status(epochId)
+	if status.pending == target {
+		status.pending = ""
+	}
+	l.logger.Warn("Reward epoch %d: %s failed in state %s", epochId, target, status.state)
+	status.lastError = target + ": " + message
+	status.updatedAt = l.clock.Now()
+	rewardEpochFailures.WithLabelValues(l.identity, target).Inc()
+	l.save(epochId, status)
+}
+
+func (l *epochLifecycle) save(epochId int64, status *epochStatus) {
+	if chain.Standby() {
+		// the states are persisted by the leader instance sending the txs
+		return
+	}
+	err := l.db.SaveRewardEpochState(&database.RewardEpochState{
+		RewardEpochId: uint32(epochId),
+		State:         status.state,
+		LastError:     status.lastError,
+		UpdatedAt:     uint64(status.updatedAt.Unix()),
+	})
+	if err != nil {
+		l.logger.Warn("Error saving state of reward epoch %d: %v", epochId, err)
+	}
+}
+
+// States of the epochs, by reward epoch id descending
+func (l *epochLifecycle) RewardEpochs() []admin.RewardEpochInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]admin.RewardEpochInfo, 0, len(l.epochs))
+	for id, status := range l.epochs {
+		result = append(result, admin.RewardEpochInfo{
+			RewardEpochId: id,
+			Identity:      l.identity,
+			State:         status.state,
+			Pending:       status.pending,
+			LastError:     status.lastError,
+			UpdatedAt:     status.updatedAt,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RewardEpochId > result[j].RewardEpochId })
+	return result
+}
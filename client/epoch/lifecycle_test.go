@@ -0,0 +1,97 @@
+package epoch
+
+import (
+	"flare-tlc/database"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testStateDB struct {
+	testDB
+	states map[uint32]database.RewardEpochState
+}
+
+func (db *testStateDB) FetchRewardEpochStates(limit int) ([]database.RewardEpochState, error) {
+	var states []database.RewardEpochState
+	for _, state := range db.states {
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+func (db *testStateDB) SaveRewardEpochState(state *database.RewardEpochState) error {
+	db.states[state.RewardEpochId] = *state
+	return nil
+}
+
+func TestEpochLifecycle(t *testing.T) {
+	db := &testStateDB{states: make(map[uint32]database.RewardEpochState)}
+	l := newEpochLifecycle(db, common.Address{})
+	require.Equal(t, stateWaiting, l.State(10))
+
+	l.Advance(10, stateVotePowerBlockSelected)
+	require.True(t, l.Begin(10, stateRegistered))
+	// the running action is not started again
+	require.False(t, l.Begin(10, stateRegistered))
+	l.Fail(10, stateRegistered, "tx reverted")
+	require.Equal(t, stateVotePowerBlockSelected, l.State(10))
+	require.Equal(t, "registered: tx reverted", db.states[10].LastError)
+
+	// failed actions can be started again
+	require.True(t, l.Begin(10, stateRegistered))
+	l.Advance(10, stateRegistered)
+	require.Equal(t, stateRegistered, l.State(10))
+	require.Empty(t, db.states[10].LastError)
+	require.False(t, l.Begin(10, stateRegistered))
+
+	// the state does not move back
+	l.Advance(10, stateVotePowerBlockSelected)
+	require.Equal(t, stateRegistered, l.State(10))
+
+	// states of disabled clients are skipped
+	require.True(t, l.Begin(10, stateUptimeSigned))
+	l.Advance(10, stateUptimeSigned)
+	require.False(t, l.Begin(10, statePolicySigned))
+
+	epochs := l.RewardEpochs()
+	require.Len(t, epochs, 1)
+	require.Equal(t, stateUptimeSigned, epochs[0].State)
+
+	// resumed after a restart
+	l = newEpochLifecycle(db, common.Address{})
+	require.Equal(t, stateUptimeSigned, l.State(10))
+	require.False(t, l.Begin(10, stateRegistered))
+	require.True(t, l.Begin(10, stateRewardsSigned))
+}
+
+func TestEpochLifecyclePrune(t *testing.T) {
+	l := newEpochLifecycle(testDB{}, common.Address{})
+	for id := int64(1); id <= lifecycleEpochs+5; id++ {
+		l.Advance(id, stateRegistered)
+	}
+	epochs := l.RewardEpochs()
+	require.Len(t, epochs, lifecycleEpochs)
+	require.EqualValues(t, lifecycleEpochs+5, epochs[0].RewardEpochId)
+	require.EqualValues(t, 6, epochs[len(epochs)-1].RewardEpochId)
+}
+
+func TestEpochClientsRewardEpochs(t *testing.T) {
+	a, b := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	clients := EpochClients{
+		{lifecycle: newEpochLifecycle(testDB{}, a)},
+		{lifecycle: newEpochLifecycle(testDB{}, b)},
+	}
+	clients[0].lifecycle.Advance(10, stateRegistered)
+	clients[1].lifecycle.Advance(11, stateRegistered)
+	clients[1].lifecycle.Advance(10, statePolicySigned)
+
+	epochs := clients.RewardEpochs()
+	require.Len(t, epochs, 3)
+	require.EqualValues(t, 11, epochs[0].RewardEpochId)
+	require.Equal(t, b.Hex(), epochs[0].Identity)
+	// the states are kept per identity
+	require.Equal(t, stateRegistered, clients[0].lifecycle.State(10))
+	require.Equal(t, statePolicySigned, clients[1].lifecycle.State(10))
+}
@@ -0,0 +1,5 @@
+package epoch
+
+import flarelogger "flare-tlc/logger"
+
+var logger = flarelogger.Module("system_manager")
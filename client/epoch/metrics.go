@@ -0,0 +1,35 @@
+package epoch
+
+import (
+	"flare-tlc/utils/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	rewardEpochStateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "epoch",
+		Name:      "last_reward_epoch_id",
+		Help:      "Last reward epoch that reached the lifecycle state",
+	}, []string{"identity", "state"})
+	rewardEpochTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "epoch",
+		Name:      "transitions_total",
+		Help:      "Reward epoch lifecycle transitions by the reached state",
+	}, []string{"identity", "state"})
+	rewardEpochFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "epoch",
+		Name:      "failures_total",
+		Help:      "Failed reward epoch lifecycle actions by the target state",
+	}, []string{"identity", "state"})
+	policySigningTimeLeft = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "epoch",
+		Name:      "policy_signing_time_left_seconds",
+		Help:      "Time left in the signing window when the last signing policy was signed",
+	}, []string{"identity"})
+)
@@ -0,0 +1,250 @@
+package epoch
+
+import (
+	"context"
+	"flare-tlc/utils/contracts/calculator"
+	"flare-tlc/utils/contracts/entitymanager"
+	"flare-tlc/utils/contracts/pchainstakemirror"
+	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/contracts/wnat"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// The preview must not delay the registration for long
+const registrationPreviewTimeout = 30 * time.Second
+
+var ppmDenominator = big.NewInt(1e6)
+
+// Expected registration weight of a voter for a reward epoch, read at the vote power
+// block of the epoch, and the registration weights of the already registered voters
+type registrationPreview struct {
+	VotePowerBlock uint64
+
+	DelegationAddress common.Address
+	WNatVotePower     *big.Int
+	// WNat vote power capped to wNatCapPPM of the total WNat vote power
+	WNatWeight  *big.Int
+	NodeIds     int
+	PChainStake *big.Int
+	// as computed by the FlareSystemsCalculator on registerVoter
+	RegistrationWeight *big.Int
+
+	MaxVoters         int
+	RegisteredWeights []*big.Int
+	AlreadyRegistered bool
+}
+
+// MakesCutoff returns true if the voter is registered with the registration weight.
+// If the voter list is full, the voter with the lowest weight is replaced by a voter
+// with a higher weight, otherwise the registration is ignored.
+func (p *registrationPreview) MakesCutoff() bool {
+	if p.AlreadyRegistered || len(p.RegisteredWeights) < p.MaxVoters {
+		return true
+	}
+	lowest := p.MinRegisteredWeight()
+	return lowest != nil && p.RegistrationWeight.Cmp(lowest) > 0
+}
+
+// Nil if no voters are registered
+func (p *registrationPreview) MinRegisteredWeight() *big.Int {
+	var lowest *big.Int
+	for _, weight := range p.RegisteredWeights {
+		if lowest == nil || weight.Cmp(lowest) < 0 {
+			lowest = weight
+		}
+	}
+	return lowest
+}
+
+func (r *registryContractClientImpl) logRegistrationPreview(nextRewardEpochId *big.Int, address common.Address) {
+	preview, err := r.previewRegistration(nextRewardEpochId, address)
+	if err != nil {
+		logger.Warn("Unable to preview the registration weight of voter %s for epoch %v: %v", address, nextRewardEpochId, err)
+		return
+	}
+	logger.Info("Expected registration weight of voter %s for epoch %v is %v (vote power block %d): WNat vote power %v "+
+		"of delegation address %s, capped to %v, P-chain stake %v of %d node ids",
+		address, nextRewardEpochId, preview.RegistrationWeight, preview.VotePowerBlock, preview.WNatVotePower,
+		preview.DelegationAddress.Hex(), preview.WNatWeight, preview.PChainStake, preview.NodeIds)
+
+	lowest := preview.MinRegisteredWeight()
+	switch {
+	case preview.AlreadyRegistered:
+		logger.Info("Voter %s is already registered for epoch %v", address, nextRewardEpochId)
+	case preview.MakesCutoff():
+		logger.Info("Voter %s makes the top %d voter cutoff for epoch %v, %d voters registered, lowest registered weight %v",
+			address, preview.MaxVoters, nextRewardEpochId, len(preview.RegisteredWeights), lowest)
+	default:
+		logger.Warn("Voter %s does not make the top %d voter cutoff for epoch %v, registration weight %v "+
+			"is not above the lowest registered weight %v, the registration is ignored",
+			address, preview.MaxVoters, nextRewardEpochId, preview.RegistrationWeight, lowest)
+	}
+}
+
+func (r *registryContractClientImpl) previewRegistration(
+	nextRewardEpochId *big.Int, address common.Address,
+) (*registrationPreview, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), registrationPreviewTimeout)
+	defer cancel()
+	opts := &bind.CallOpts{Context: ctx}
+	preview := &registrationPreview{}
+
+	systemsManagerAddress, err := r.registry.FlareSystemsManager(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching systems manager address")
+	}
+	systemsManager, err := system.NewFlareSystemsManagerCaller(systemsManagerAddress, r.ethClient)
+	if err != nil {
+		return nil, err
+	}
+	preview.VotePowerBlock, err = systemsManager.GetVotePowerBlock(opts, nextRewardEpochId)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching vote power block")
+	}
+	votePowerBlock := new(big.Int).SetUint64(preview.VotePowerBlock)
+
+	calculatorAddress, err := r.registry.FlareSystemsCalculator(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching calculator address")
+	}
+	calc, err := calculator.NewFlareSystemsCalculatorCaller(calculatorAddress, r.ethClient)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.previewWNatWeight(opts, preview, calc, address, votePowerBlock); err != nil {
+		return nil, err
+	}
+	if err := r.previewRegistrationWeight(opts, preview, calc, nextRewardEpochId, address, votePowerBlock); err != nil {
+		return nil, err
+	}
+
+	maxVoters, err := r.registry.MaxVoters(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching max voters")
+	}
+	preview.MaxVoters = int(maxVoters.Int64())
+	voters, err := r.registry.GetRegisteredVoters(opts, nextRewardEpochId)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching registered voters")
+	}
+	for _, voter := range voters {
+		if voter == address {
+			preview.AlreadyRegistered = true
+		}
+		weight, err := r.registry.GetVoterRegistrationWeight(opts, voter, nextRewardEpochId)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error fetching registration weight of voter %s", voter)
+		}
+		preview.RegisteredWeights = append(preview.RegisteredWeights, weight)
+	}
+	return preview, nil
+}
+
+// WNat vote power of the delegation address and P-chain stake of the node ids of the voter
+func (r *registryContractClientImpl) previewWNatWeight(
+	opts *bind.CallOpts,
+	preview *registrationPreview,
+	calc *calculator.FlareSystemsCalculatorCaller,
+	address common.Address,
+	votePowerBlock *big.Int,
+) error {
+	entityManagerAddress, err := r.registry.EntityManager(opts)
+	if err != nil {
+		return errors.Wrap(err, "error fetching entity manager address")
+	}
+	entityManager, err := entitymanager.NewEntityManagerCaller(entityManagerAddress, r.ethClient)
+	if err != nil {
+		return err
+	}
+	preview.DelegationAddress, err = entityManager.GetDelegationAddressOfAt(opts, address, votePowerBlock)
+	if err != nil {
+		return errors.Wrap(err, "error fetching delegation address")
+	}
+	wNatAddress, err := calc.WNat(opts)
+	if err != nil {
+		return errors.Wrap(err, "error fetching WNat address")
+	}
+	wNat, err := wnat.NewWNatCaller(wNatAddress, r.ethClient)
+	if err != nil {
+		return err
+	}
+	preview.WNatVotePower, err = wNat.VotePowerOfAt(opts, preview.DelegationAddress, votePowerBlock)
+	if err != nil {
+		return errors.Wrap(err, "error fetching WNat vote power")
+	}
+	totalVotePower, err := wNat.TotalVotePowerAt(opts, votePowerBlock)
+	if err != nil {
+		return errors.Wrap(err, "error fetching WNat total vote power")
+	}
+	capPPM, err := calc.WNatCapPPM(opts)
+	if err != nil {
+		return errors.Wrap(err, "error fetching WNat cap")
+	}
+	preview.WNatWeight = cappedWNatWeight(preview.WNatVotePower, totalVotePower, capPPM)
+
+	preview.PChainStake = big.NewInt(0)
+	enabled, err := calc.PChainStakeEnabled(opts)
+	if err != nil {
+		return errors.Wrap(err, "error fetching P-chain stake enabled")
+	}
+	if !enabled {
+		return nil
+	}
+	nodeIds, err := entityManager.GetNodeIdsOfAt(opts, address, votePowerBlock)
+	if err != nil {
+		return errors.Wrap(err, "error fetching node ids")
+	}
+	preview.NodeIds = len(nodeIds)
+	mirrorAddress, err := calc.PChainStakeMirror(opts)
+	if err != nil {
+		return errors.Wrap(err, "error fetching P-chain stake mirror address")
+	}
+	mirror, err := pchainstakemirror.NewPChainStakeMirrorCaller(mirrorAddress, r.ethClient)
+	if err != nil {
+		return err
+	}
+	for _, nodeId := range nodeIds {
+		stake, err := mirror.VotePowerOfAt(opts, nodeId, votePowerBlock)
+		if err != nil {
+			return errors.Wrap(err, "error fetching P-chain stake")
+		}
+		preview.PChainStake.Add(preview.PChainStake, stake)
+	}
+	return nil
+}
+
+// calculateRegistrationWeight is restricted to the VoterRegistry, it is called from the
+// VoterRegistry address
+func (r *registryContractClientImpl) previewRegistrationWeight(
+	opts *bind.CallOpts,
+	preview *registrationPreview,
+	calc *calculator.FlareSystemsCalculatorCaller,
+	nextRewardEpochId *big.Int,
+	address common.Address,
+	votePowerBlock *big.Int,
+) error {
+	var out []interface{}
+	raw := &calculator.FlareSystemsCalculatorCallerRaw{Contract: calc}
+	err := raw.Call(&bind.CallOpts{Context: opts.Context, From: r.address}, &out, "calculateRegistrationWeight",
+		address, preview.DelegationAddress, nextRewardEpochId, votePowerBlock)
+	if err != nil {
+		return errors.Wrap(err, "error calculating registration weight")
+	}
+	preview.RegistrationWeight = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	return nil
+}
+
+func cappedWNatWeight(votePower *big.Int, totalVotePower *big.Int, capPPM *big.Int) *big.Int {
+	weightCap := new(big.Int).Mul(totalVotePower, capPPM)
+	weightCap.Div(weightCap, ppmDenominator)
+	if votePower.Cmp(weightCap) > 0 {
+		return weightCap
+	}
+	return new(big.Int).Set(votePower)
+}
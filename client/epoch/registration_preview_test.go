@@ -0,0 +1,36 @@
+package epoch
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrationPreviewCutoff(t *testing.T) {
+	preview := &registrationPreview{
+		RegistrationWeight: big.NewInt(50),
+		MaxVoters:          3,
+		RegisteredWeights:  []*big.Int{big.NewInt(100), big.NewInt(40)},
+	}
+	require.True(t, preview.MakesCutoff())
+	require.EqualValues(t, 40, preview.MinRegisteredWeight().Int64())
+
+	// the voter with the lowest weight is replaced
+	preview.RegisteredWeights = append(preview.RegisteredWeights, big.NewInt(60))
+	require.True(t, preview.MakesCutoff())
+
+	preview.RegistrationWeight = big.NewInt(40)
+	require.False(t, preview.MakesCutoff())
+
+	preview.AlreadyRegistered = true
+	require.True(t, preview.MakesCutoff())
+
+	require.Nil(t, (&registrationPreview{}).MinRegisteredWeight())
+}
+
+func TestCappedWNatWeight(t *testing.T) {
+	total := big.NewInt(1000000)
+	require.EqualValues(t, 20000, cappedWNatWeight(big.NewInt(30000), total, big.NewInt(20000)).Int64())
+	require.EqualValues(t, 10000, cappedWNatWeight(big.NewInt(10000), total, big.NewInt(20000)).Int64())
+}
@@ -1,18 +1,20 @@
 package epoch
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"flare-tlc/client/config"
 	"flare-tlc/client/shared"
-	"flare-tlc/logger"
 	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/registry"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/sortition"
 	"math/big"
 
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
@@ -36,18 +38,23 @@ func init() {
 	}
 }
 
-type registryContractClient interface {
+// VoterRegistry calls of the epoch client
+type RegistryContractClient interface {
 	RegisterVoter(nextRewardEpochId *big.Int, address common.Address) <-chan shared.ExecuteStatus[any]
 }
 
 type registryContractClientImpl struct {
-	ethClient        *ethclient.Client
-	address          common.Address
-	registry         *registry.Registry
-	senderTxOpts     *bind.TransactOpts
-	gasCfg           *config.GasConfig
-	txVerifier       *chain.TxVerifier
-	signerPrivateKey *ecdsa.PrivateKey
+	ethClient    *ethclient.Client
+	address      common.Address
+	registry     *registry.Registry
+	senderTxOpts *bind.TransactOpts
+	gasCfg       *config.GasConfig
+	txVerifier   *chain.TxVerifier
+	signer       credentials.Signer
+	retryCfg     *config.RetryConfig
+
+	// optional, the registered public key is checked against it
+	sortitionKey *sortition.Key
 }
 
 func NewRegistryContractClient(
@@ -55,35 +62,48 @@ func NewRegistryContractClient(
 	gasCfg *config.GasConfig,
 	address common.Address,
 	senderTxOpts *bind.TransactOpts,
-	signerPk *ecdsa.PrivateKey,
+	signer credentials.Signer,
+	retryCfg *config.RetryConfig,
+	sortitionKey *sortition.Key,
 ) (*registryContractClientImpl, error) {
 	registry, err := registry.NewRegistry(address, ethClient)
 	if err != nil {
 		return nil, err
 	}
 	return &registryContractClientImpl{
-		ethClient:        ethClient,
-		address:          address,
-		registry:         registry,
-		senderTxOpts:     senderTxOpts,
-		gasCfg:           gasCfg,
-		txVerifier:       chain.NewTxVerifier(ethClient),
-		signerPrivateKey: signerPk,
+		ethClient:    ethClient,
+		address:      address,
+		registry:     registry,
+		senderTxOpts: senderTxOpts,
+		gasCfg:       gasCfg,
+		txVerifier:   chain.NewTxVerifier(ethClient),
+		signer:       signer,
+		retryCfg:     retryCfg,
+		sortitionKey: sortitionKey,
 	}, nil
 
 }
 
 func (r *registryContractClientImpl) RegisterVoter(nextRewardEpochId *big.Int, address common.Address) <-chan shared.ExecuteStatus[any] {
-	return shared.ExecuteWithRetry(func() (any, error) {
+	r.logRegistrationPreview(nextRewardEpochId, address)
+	return shared.ExecuteWithRetryPolicy(func() (any, error) {
 		err := r.sendRegisterVoter(nextRewardEpochId, address)
 		if err != nil {
 			return nil, errors.Wrap(err, "error sending register voter")
 		}
 		return nil, nil
-	}, shared.MaxTxSendRetries, shared.TxRetryInterval)
+	}, r.retryCfg.Policy(config.RetryOpRegisterVoter))
 }
 
 func (r *registryContractClientImpl) sendRegisterVoter(nextRewardEpochId *big.Int, address common.Address) error {
+	registered, err := r.registry.IsVoterRegistered(nil, address, nextRewardEpochId)
+	if err != nil {
+		return errors.Wrap(err, "error checking voter registration")
+	}
+	if registered {
+		return shared.AlreadyDone(errors.Errorf("voter %s already registered for epoch %v", address, nextRewardEpochId))
+	}
+
 	epochId := uint32(nextRewardEpochId.Uint64())
 	signature, err := r.createSignature(epochId, address)
 	if err != nil {
@@ -95,33 +115,95 @@ func (r *registryContractClientImpl) sendRegisterVoter(nextRewardEpochId *big.In
 		V: signature[64] + 27,
 	}
 
-	gasPrice, err := chain.GetGasPrice(r.gasCfg, r.ethClient)
+	fees, err := chain.GetTxFees(r.gasCfg, r.ethClient)
 	if err != nil {
 		logger.Warn("Unable to obtain gas price: %v, using fallback %d", err, fallbackGasPrice)
-		gasPrice = fallbackGasPrice
+		fees = &chain.TxFees{GasPrice: fallbackGasPrice}
 	}
 
-	if r.gasCfg.GasLimit != 0 {
-		r.senderTxOpts.GasLimit = uint64(r.gasCfg.GasLimit)
-	}
-	r.senderTxOpts.GasPrice = gasPrice
-	tx, err := r.registry.RegisterVoter(r.senderTxOpts, address, vrsSignature)
+	// fees are set on the copy of the sender opts, which are shared with the systems manager client
+	tx, err := chain.TransactWithNonce(r.ethClient, r.senderTxOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if opts.GasLimit == 0 {
+			gasLimit, err := chain.ContractGasLimit(
+				context.Background(), r.ethClient, r.gasCfg, config.RetryOpRegisterVoter,
+				opts.From, r.address, registry.RegistryMetaData, "registerVoter", address, vrsSignature,
+			)
+			if err != nil {
+				return nil, err
+			}
+			opts.GasLimit = gasLimit
+		}
+		fees.Apply(opts)
+		return r.registry.RegisterVoter(opts, address, vrsSignature)
+	})
 	if err != nil {
 		return err
 	}
-	err = r.txVerifier.WaitUntilMined(r.senderTxOpts.From, tx, chain.DefaultTxTimeout)
+	voterRegistered := chain.ExpectedEvent{
+		Address:  r.address,
+		MetaData: registry.RegistryMetaData,
+		Name:     "VoterRegistered",
+		Match: func(log types.Log) bool {
+			event, err := r.registry.ParseVoterRegistered(log)
+			return err == nil && event.Voter == address && event.RewardEpochId.Cmp(nextRewardEpochId) == 0
+		},
+	}
+	_, err = r.txVerifier.WaitUntilMinedWithEscalation(r.senderTxOpts.From, tx, r.senderTxOpts.Signer, r.gasCfg, config.RetryOpRegisterVoter, chain.DefaultTxTimeout, voterRegistered)
 	if err != nil {
 		return err
 	}
+	if !chain.Broadcasting() {
+		// the simulated or observed registration is not on chain
+		return nil
+	}
+
+	// the tx succeeds without registering the voter if the voter list is full and the
+	// registration weight is too low, retrying does not help in that case
+	registered, err = r.registry.IsVoterRegistered(nil, address, nextRewardEpochId)
+	if err != nil {
+		return errors.Wrap(err, "error confirming voter registration")
+	}
+	if !registered {
+		return shared.Fatal(errors.Errorf("voter %s not registered for epoch %v after tx %s", address, nextRewardEpochId, tx.Hash().Hex()))
+	}
 	logger.Info("Voter %s registered for epoch %v", address, nextRewardEpochId)
+	if r.sortitionKey != nil {
+		r.checkSortitionPublicKey(nextRewardEpochId, address)
+	}
 	return nil
 }
 
+// The public key registered with the voter is the public key of the identity in the
+// EntityManager, registered by the identity with registerPublicKey. If it is not the
+// public key of the sortition key, the proof of possession of the sortition key is
+// logged for the registration.
+func (r *registryContractClientImpl) checkSortitionPublicKey(nextRewardEpochId *big.Int, address common.Address) {
+	signingPolicyAddress := credentials.SignerForRewardEpoch(r.signer, nextRewardEpochId.Int64()).Address()
+	registered, err := r.registry.GetPublicKeyAndNormalisedWeight(nil, nextRewardEpochId, signingPolicyAddress)
+	if err != nil {
+		logger.Warn("Unable to fetch the registered public key of voter %s: %v", address, err)
+		return
+	}
+	part1, part2 := r.sortitionKey.PublicKeyParts()
+	if registered.PublicKeyPart1 == part1 && registered.PublicKeyPart2 == part2 {
+		return
+	}
+	verificationData, err := sortition.ProofOfPossession(r.sortitionKey, address)
+	if err != nil {
+		logger.Error("Error computing proof of possession of the sortition key: %v", err)
+		return
+	}
+	logger.Error("Public key registered for voter %s in epoch %v is not the public key of the sortition key, "+
+		"fast updates are rejected. Register the public key in the EntityManager with registerPublicKey(%s, %s, %s)",
+		address, nextRewardEpochId, hexutil.Encode(part1[:]), hexutil.Encode(part2[:]), hexutil.Encode(verificationData))
+}
+
 func (r *registryContractClientImpl) createSignature(nextRewardEpochId uint32, address common.Address) ([]byte, error) {
 	message, err := registratorArguments.Pack(nextRewardEpochId, address)
 	if err != nil {
 		return nil, err
 	}
 	messageHash := crypto.Keccak256(message)
-	return crypto.Sign(accounts.TextHash(messageHash), r.signerPrivateKey)
+	// signed by the signing policy address registered for the next reward epoch
+	return credentials.SignerForRewardEpoch(r.signer, int64(nextRewardEpochId)).SignText(messageHash)
 }
@@ -1,75 +1,153 @@
 package epoch
 
 import (
+	"context"
 	"flare-tlc/client/shared"
 	"flare-tlc/database"
-	"flare-tlc/logger"
 	"flare-tlc/utils"
 	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/relay"
-	"time"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-type relayContractClient interface {
-	SigningPolicyInitializedListener(epochClientDB, *utils.Epoch) <-chan *relay.RelaySigningPolicyInitialized
+// Relay events and calls of the epoch client
+type RelayContractClient interface {
+	SigningPolicyInitializedListener(context.Context, EpochClientDB, *utils.Epoch) <-chan *relay.RelaySigningPolicyInitialized
+	FetchSigningPolicy(EpochClientDB, *big.Int, int64, int64) (*relay.RelaySigningPolicyInitialized, error)
+	SigningPolicyHash(*big.Int) <-chan shared.ExecuteStatus[common.Hash]
 }
 
 type relayContractClientImpl struct {
 	address    common.Address
 	relay      *relay.Relay
 	txVerifier *chain.TxVerifier
+	clock      utils.Clock
+
+	// If set, SigningPolicyInitialized events are received via websocket subscription
+	spiSubscriber shared.LogSubscriber
+
+	// polling intervals of the listeners, the defaults if not set
+	listenerIntervals shared.ListenerIntervals
+	// buffers of the event channels of the listeners, unbuffered if not set
+	listenerBuffers shared.ListenerBuffers
+
+	// topic0 of SigningPolicyInitialized, resolved by NewRelayContractClient
+	topic0SPI string
 }
 
 func NewRelayContractClient(
 	ethClient *ethclient.Client,
 	address common.Address,
 ) (*relayContractClientImpl, error) {
-	relay, err := relay.NewRelay(address, ethClient)
+	relayContract, err := relay.NewRelay(address, ethClient)
+	if err != nil {
+		return nil, err
+	}
+	topic0SPI, err := chain.EventIDFromMetadata(relay.RelayMetaData, "SigningPolicyInitialized")
 	if err != nil {
 		return nil, err
 	}
 	return &relayContractClientImpl{
 		address:    address,
-		relay:      relay,
+		relay:      relayContract,
 		txVerifier: chain.NewTxVerifier(ethClient),
+		clock:      utils.RealClock,
+		topic0SPI:  topic0SPI,
 	}, nil
 }
 
-func (r *relayContractClientImpl) SigningPolicyInitializedListener(db epochClientDB, epoch *utils.Epoch) <-chan *relay.RelaySigningPolicyInitialized {
-	topic0, err := chain.EventIDFromMetadata(relay.RelayMetaData, "SigningPolicyInitialized")
-	if err != nil {
-		// panic, this error is fatal
-		panic(err)
-	}
-	out := make(chan *relay.RelaySigningPolicyInitialized)
+func (r *relayContractClientImpl) SigningPolicyInitializedListener(ctx context.Context, db EpochClientDB, epoch *utils.Epoch) <-chan *relay.RelaySigningPolicyInitialized {
+	topic0 := r.topic0SPI
+	out := shared.NewListenerOutput[*relay.RelaySigningPolicyInitialized](listenerSigningPolicyInitialized, r.listenerBuffers.Buffer(listenerSigningPolicyInitialized, 0))
 
 	go func() {
 		randomDelay()
-		ticker := time.NewTicker(shared.EventListenerInterval)
-		eventRangeStart := epoch.StartTime(epoch.EpochIndex(time.Now()) - 1).Unix()
+		ticker := shared.NewListenerTicker(r.clock, listenerSigningPolicyInitialized, r.listenerIntervals.Interval(listenerSigningPolicyInitialized))
+		defer ticker.Stop()
+		delivered := shared.NewDeliveredEvents(listenerRangeStart(db, listenerSigningPolicyInitialized, epoch.StartTime(epoch.EpochIndex(r.clock.Now())-1).Unix()))
+		// emits the event if it was not delivered yet, returns false if ctx is done
+		deliver := func(id shared.EventID, policyData *relay.RelaySigningPolicyInitialized) bool {
+			timestamp := int64(policyData.Timestamp)
+			if !delivered.IsNew(id, timestamp) {
+				return true
+			}
+			if !out.Send(ctx, policyData) {
+				return false
+			}
+			delivered.Add(id, timestamp)
+			shared.RecordListenerEvent(listenerSigningPolicyInitialized, timestamp)
+			return true
+		}
+		wsLogs := shared.SubscribeLogsInBackground(ctx, r.clock, r.spiSubscriber, r.address, topic0, "SigningPolicyInitialized")
+		handleLog := func(log types.Log) bool {
+			policyData, err := r.relay.RelayFilterer.ParseSigningPolicyInitialized(log)
+			if err != nil {
+				logger.Error("Error parsing SigningPolicyInitialized event %v", err)
+				return true
+			}
+			return deliver(shared.EventID{TxHash: log.TxHash, LogIndex: uint64(log.Index)}, policyData)
+		}
 		for {
-			<-ticker.C
-			now := time.Now().Unix()
-			logs, err := db.FetchLogsByAddressAndTopic0(r.address, topic0, eventRangeStart, now)
+			if !ticker.WaitLogs(ctx, wsLogs, handleLog) {
+				return
+			}
+			now := r.clock.Now().Unix()
+			logs, err := db.FetchLogsByAddressAndTopic0(r.address, topic0, delivered.RangeStart(), now)
 			if err != nil {
 				logger.Error("Error fetching logs %v", err)
 				continue
 			}
+			shared.RecordListenerRowsScanned(listenerSigningPolicyInitialized, len(logs))
 			if len(logs) > 0 {
-				policyData, err := r.parseSigningPolicyInitializedEvent(logs[len(logs)-1])
+				// only the latest signing policy is emitted
+				log := logs[len(logs)-1]
+				policyData, err := r.parseSigningPolicyInitializedEvent(log)
 				if err != nil {
 					logger.Error("Error parsing SigningPolicyInitialized event %v", err)
 					continue
 				}
-				out <- policyData
-				eventRangeStart = int64(policyData.Timestamp)
+				if !deliver(shared.EventID{TxHash: common.HexToHash(log.TransactionHash), LogIndex: log.LogIndex}, policyData) {
+					return
+				}
+				delivered.Confirm(int64(log.Timestamp))
 			}
 		}
 	}()
-	return out
+	return out.C()
+}
+
+// Returns the signing policy of the reward epoch initialized in the timestamp range (from, to],
+// or nil if it was not found
+func (r *relayContractClientImpl) FetchSigningPolicy(
+	db EpochClientDB, rewardEpochId *big.Int, from, to int64,
+) (*relay.RelaySigningPolicyInitialized, error) {
+	logs, err := database.FetchAll(from, to, func(from, to int64) ([]database.Log, error) {
+		return db.FetchLogsByAddressAndTopic0(r.address, r.topic0SPI, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		policyData, err := r.parseSigningPolicyInitializedEvent(log)
+		if err != nil {
+			return nil, err
+		}
+		if policyData.RewardEpochId.Cmp(rewardEpochId) == 0 {
+			return policyData, nil
+		}
+	}
+	return nil, nil
+}
+
+// Returns the hash of the signing policy of the reward epoch stored in the Relay contract
+func (r *relayContractClientImpl) SigningPolicyHash(rewardEpochId *big.Int) <-chan shared.ExecuteStatus[common.Hash] {
+	return shared.ExecuteWithRetry(func() (common.Hash, error) {
+		return r.relay.ToSigningPolicyHash(nil, rewardEpochId)
+	}, shared.MaxTxSendRetries, shared.TxRetryInterval)
 }
 
 func (r *relayContractClientImpl) parseSigningPolicyInitializedEvent(dbLog database.Log) (*relay.RelaySigningPolicyInitialized, error) {
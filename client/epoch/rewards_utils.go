@@ -1,10 +1,10 @@
 package epoch
 
 import (
+	"context"
 	"encoding/json"
 	"flare-tlc/client/config"
 	"flare-tlc/client/shared"
-	"flare-tlc/logger"
 	"flare-tlc/utils/contracts/system"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -18,7 +18,6 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"time"
 )
 
 type rewardsHash struct {
@@ -66,28 +65,20 @@ func encodeRewardsData(epochId *big.Int, chainId int, rewardHash *common.Hash, w
 	return packed
 }
 
-func getRewardsHash(epochId *big.Int, rewardsConfig *config.RewardsConfig) (*common.Hash, int, error) {
+func getRewardsHash(ctx context.Context, epochId *big.Int, rewardsConfig *config.RewardsConfig, policy config.RetryPolicy) (*common.Hash, int, error) {
 	prefix := rewardsConfig.PathPrefix
 	if prefix == "" {
 		return nil, 0, errors.New("rewards hash path prefix not set")
 	}
 
 	path := fmt.Sprintf("%s/%d/rewards-hash.json", prefix, epochId)
-	bytes, err := fetchRewardsHashBytes(path)
+	rewardHash, err := fetchHashFileWithRetry(ctx, path, "rewards hash", policy, func(bytes []byte) (*rewardsHash, error) {
+		return decodeRewardsHash(bytes, epochId)
+	})
 	if err != nil {
 		return nil, 0, err
 	}
 
-	var rewardHash rewardsHash
-	err = json.Unmarshal(bytes, &rewardHash)
-	if err != nil {
-		return nil, 0, errors.Wrap(err, "error decoding reward hash file")
-	}
-
-	if rewardHash.RewardEpochId != int(epochId.Int64()) {
-		return nil, 0, errors.Errorf("invalid rewards hash epoch id: %d, expected: %d", rewardHash.RewardEpochId, epochId)
-	}
-
 	hashBytes, err := hexutil.Decode(rewardHash.MerkleRoot)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, "invalid rewards merkle root")
@@ -100,38 +91,70 @@ func getRewardsHash(epochId *big.Int, rewardsConfig *config.RewardsConfig) (*com
 	return &hash, rewardHash.NoOfWeightBasedClaims, nil
 }
 
-func fetchRewardsHashBytes(path string) ([]byte, error) {
+func decodeRewardsHash(bytes []byte, epochId *big.Int) (*rewardsHash, error) {
+	var rewardHash rewardsHash
+	err := json.Unmarshal(bytes, &rewardHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding reward hash file")
+	}
+
+	if rewardHash.RewardEpochId != int(epochId.Int64()) {
+		return nil, shared.Fatal(errors.Errorf("invalid rewards hash epoch id: %d, expected: %d", rewardHash.RewardEpochId, epochId))
+	}
+	if rewardHash.NoOfWeightBasedClaims < 0 {
+		return nil, shared.Fatal(errors.Errorf("invalid number of weight based claims: %d", rewardHash.NoOfWeightBasedClaims))
+	}
+	return &rewardHash, nil
+}
+
+// fetchHashFileWithRetry fetches a hash file and decodes it with decode, retrying with the policy
+// until the file is available and valid, since it may be published after the signing is enabled,
+// or ctx is done.
+func fetchHashFileWithRetry[T any](ctx context.Context, path string, name string, policy config.RetryPolicy, decode func([]byte) (T, error)) (T, error) {
+	result := <-shared.ExecuteWithRetryPolicyContext(ctx, func() (T, error) {
+		bytes, err := fetchHashFileBytes(ctx, path, name)
+		if err != nil {
+			return *new(T), err
+		}
+		return decode(bytes)
+	}, policy)
+	if !result.Success {
+		return *new(T), errors.Errorf("error fetching %s: %s", name, result.Message)
+	}
+	return result.Value, nil
+}
+
+func fetchHashFileBytes(ctx context.Context, path string, name string) ([]byte, error) {
 	var data []byte
 	_, isUrl := parseUrl(path)
 	if isUrl {
-		logger.Info("Fetching rewards hash from URL: %s", path)
-		result := <-shared.ExecuteWithRetry(func() ([]byte, error) {
-			resp, err := http.Get(path)
-			if err != nil {
-				return nil, err
-			}
-			defer resp.Body.Close()
-			bytes, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-			return bytes, nil
-		}, 3, 1*time.Second)
-
-		if !result.Success {
-			return nil, errors.Errorf("error fetching rewards hash: %s", result.Message)
+		logger.Info("Fetching %s from URL: %s", name, path)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("error fetching %s: status %s", name, resp.Status)
+		}
+		data, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
 		}
-		data = result.Value
 	} else {
-		logger.Info("Fetching rewards hash from disk: %s", path)
+		logger.Info("Fetching %s from disk: %s", name, path)
 		file, err := os.Open(path)
 		if err != nil {
-			return nil, errors.Wrap(err, "error opening reward hash file")
+			return nil, errors.Wrapf(err, "error opening %s file", name)
 		}
 		defer file.Close()
 		data, err = io.ReadAll(file)
 		if err != nil {
-			return nil, errors.Wrap(err, "error reading reward hash file")
+			return nil, errors.Wrapf(err, "error reading %s file", name)
 		}
 	}
 	return data, nil
@@ -0,0 +1,29 @@
+package epoch
+
+import (
+	"bytes"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/signingpolicy"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Checks that the signing policy bytes of the event match the event fields and
+// that their hash matches the signing policy hash stored in the Relay contract
+func verifySigningPolicy(policy *relay.RelaySigningPolicyInitialized, chainHash common.Hash) error {
+	encoded, err := signingpolicy.EncodeEvent(policy)
+	if err != nil {
+		return errors.Wrap(err, "error encoding signing policy")
+	}
+	if !bytes.Equal(encoded, policy.SigningPolicyBytes) {
+		return errors.New("signing policy bytes do not match the signing policy fields")
+	}
+	if chainHash == (common.Hash{}) {
+		return errors.New("signing policy hash not set on chain")
+	}
+	if hash := signingpolicy.Hash(policy.SigningPolicyBytes); hash != chainHash {
+		return errors.Errorf("signing policy hash %s does not match the hash on chain %s", hash.Hex(), chainHash.Hex())
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package epoch
+
+import (
+	"flare-tlc/utils/signingpolicy"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySigningPolicy(t *testing.T) {
+	policy := newTestSigningPolicy(t, big.NewInt(2))
+	chainHash := signingpolicy.Hash(policy.SigningPolicyBytes)
+
+	require.NoError(t, verifySigningPolicy(policy, chainHash))
+	require.ErrorContains(t, verifySigningPolicy(policy, common.HexToHash("0x01")), "does not match the hash on chain")
+	require.ErrorContains(t, verifySigningPolicy(policy, common.Hash{}), "not set on chain")
+
+	policy.Threshold++
+	require.ErrorContains(t, verifySigningPolicy(policy, chainHash), "do not match the signing policy fields")
+}
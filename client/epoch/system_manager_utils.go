@@ -1,16 +1,18 @@
 package epoch
 
 import (
-	"crypto/ecdsa"
+	"context"
+	"flare-tlc/client/config"
 	"flare-tlc/client/shared"
 	"flare-tlc/database"
-	"flare-tlc/logger"
 	"flare-tlc/utils"
 	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/system"
-	"github.com/ethereum/go-ethereum/accounts"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/signingpolicy"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
@@ -31,59 +33,148 @@ var (
 	}
 )
 
-type systemsManagerContractClient interface {
+// FlareSystemsManager events, calls and signing txs of the epoch client
+type SystemsManagerContractClient interface {
 	RewardEpochFromChain() (*utils.Epoch, error)
+	RefreshRewardEpoch(*utils.Epoch) (bool, error)
 
-	VotePowerBlockSelectedListener(epochClientDB, *utils.Epoch) <-chan *system.FlareSystemsManagerVotePowerBlockSelected
-	SignNewSigningPolicy(*big.Int, []byte) <-chan shared.ExecuteStatus[any]
+	VotePowerBlockSelectedListener(context.Context, EpochClientDB, *utils.Epoch) <-chan *system.FlareSystemsManagerVotePowerBlockSelected
+	SignNewSigningPolicy(*big.Int, []byte, time.Time) <-chan shared.ExecuteStatus[any]
 
-	SignUptimeVoteEnabledListener(epochClientDB, *utils.Epoch, int64) <-chan *system.FlareSystemsManagerSignUptimeVoteEnabled
-	SignUptimeVote(*big.Int) <-chan shared.ExecuteStatus[any]
+	SignUptimeVoteEnabledListener(context.Context, EpochClientDB, *utils.Epoch, int64) <-chan *system.FlareSystemsManagerSignUptimeVoteEnabled
+	SignUptimeVote(*big.Int, common.Hash) <-chan shared.ExecuteStatus[any]
 
-	UptimeVoteSignedListener(epochClientDB, *utils.Epoch, int64) <-chan *system.FlareSystemsManagerUptimeVoteSigned
-	SignRewards(*big.Int, *common.Hash, int) <-chan shared.ExecuteStatus[any]
+	UptimeVoteSignedListener(context.Context, EpochClientDB, *utils.Epoch, int64) <-chan *system.FlareSystemsManagerUptimeVoteSigned
+	SignRewards(context.Context, *big.Int, *common.Hash, int) <-chan shared.ExecuteStatus[any]
 
 	GetCurrentRewardEpochId() <-chan shared.ExecuteStatus[*big.Int]
 }
 
 type systemsManagerContractClientImpl struct {
+	ethClient           *ethclient.Client
 	address             common.Address
 	flareSystemsManager *system.FlareSystemsManager
 	senderTxOpts        *bind.TransactOpts
+	gasCfg              *config.GasConfig
 	txVerifier          *chain.TxVerifier
-	signerPrivateKey    *ecdsa.PrivateKey
+	signer              credentials.Signer
 	chainId             int
+	retryCfg            *config.RetryConfig
+	clock               utils.Clock
+
+	// If set, VotePowerBlockSelected events are received via websocket subscription
+	vpbsSubscriber shared.LogSubscriber
+
+	// polling intervals of the listeners, the defaults if not set
+	listenerIntervals shared.ListenerIntervals
+	// buffers of the event channels of the listeners, unbuffered if not set
+	listenerBuffers shared.ListenerBuffers
+
+	// Gas of the policy signing tx close to the end of the signing window
+	policySigningCfg *config.PolicySigningConfig
+
+	// Identity address of the voter, if set the signing txs are skipped if the voter
+	// already signed on chain
+	voter common.Address
+
+	// topic0 of the events of the listeners, resolved by NewSystemsManagerClient
+	topic0VotePowerBlockSelected string
+	topic0SignUptimeVoteEnabled  string
+	topic0UptimeVoteSigned       string
 }
 
-func NewSystemsManagerClient(ethClient *ethclient.Client, address common.Address, senderTxOpts *bind.TransactOpts, signerPrivateKey *ecdsa.PrivateKey, chainId int) (*systemsManagerContractClientImpl, error) {
+func NewSystemsManagerClient(
+	ethClient *ethclient.Client,
+	address common.Address,
+	senderTxOpts *bind.TransactOpts,
+	gasCfg *config.GasConfig,
+	signer credentials.Signer,
+	chainId int,
+	retryCfg *config.RetryConfig,
+) (*systemsManagerContractClientImpl, error) {
 	flareSystemsManager, err := system.NewFlareSystemsManager(address, ethClient)
 	if err != nil {
 		return nil, err
 	}
+	topic0VPBS, err := chain.EventIDFromMetadata(system.FlareSystemsManagerMetaData, "VotePowerBlockSelected")
+	if err != nil {
+		return nil, err
+	}
+	topic0SUVE, err := chain.EventIDFromMetadata(system.FlareSystemsManagerMetaData, "SignUptimeVoteEnabled")
+	if err != nil {
+		return nil, err
+	}
+	topic0UVS, err := chain.EventIDFromMetadata(system.FlareSystemsManagerMetaData, "UptimeVoteSigned")
+	if err != nil {
+		return nil, err
+	}
 
 	return &systemsManagerContractClientImpl{
+		ethClient:           ethClient,
 		address:             address,
 		flareSystemsManager: flareSystemsManager,
 		senderTxOpts:        senderTxOpts,
+		gasCfg:              gasCfg,
 		txVerifier:          chain.NewTxVerifier(ethClient),
-		signerPrivateKey:    signerPrivateKey,
+		signer:              signer,
 		chainId:             chainId,
+		retryCfg:            retryCfg,
+		clock:               utils.RealClock,
+
+		topic0VotePowerBlockSelected: topic0VPBS,
+		topic0SignUptimeVoteEnabled:  topic0SUVE,
+		topic0UptimeVoteSigned:       topic0UVS,
 	}, nil
 }
 
-func (s *systemsManagerContractClientImpl) SignNewSigningPolicy(rewardEpochId *big.Int, signingPolicy []byte) <-chan shared.ExecuteStatus[any] {
-	return shared.ExecuteWithRetry(func() (any, error) {
-		err := s.sendSignNewSigningPolicy(rewardEpochId, signingPolicy)
+// Signer of the signing policy of the reward epoch, the signatures are checked against
+// the signing policy of the current reward epoch
+func (s *systemsManagerContractClientImpl) policySigner(rewardEpochId int64) credentials.Signer {
+	return credentials.SignerForRewardEpoch(s.signer, rewardEpochId)
+}
+
+// Event of the systems manager expected in the receipt of a signing tx, parse returns
+// the reward epoch and the signing policy address of the signature
+func (s *systemsManagerContractClientImpl) signedEvent(
+	name string, rewardEpochId *big.Int, signer credentials.Signer, parse func(log types.Log) (*big.Int, common.Address, error),
+) chain.ExpectedEvent {
+	return chain.ExpectedEvent{
+		Address:  s.address,
+		MetaData: system.FlareSystemsManagerMetaData,
+		Name:     name,
+		Match: func(log types.Log) bool {
+			epochId, signingPolicyAddress, err := parse(log)
+			return err == nil && epochId.Cmp(rewardEpochId) == 0 && signingPolicyAddress == signer.Address()
+		},
+	}
+}
+
+// SignNewSigningPolicy signs the signing policy of the reward epoch, deadline is the end
+// of the signing window, zero if unknown
+func (s *systemsManagerContractClientImpl) SignNewSigningPolicy(rewardEpochId *big.Int, signingPolicy []byte, deadline time.Time) <-chan shared.ExecuteStatus[any] {
+	return shared.ExecuteWithRetryPolicy(func() (any, error) {
+		err := s.sendSignNewSigningPolicy(rewardEpochId, signingPolicy, deadline)
 		if err != nil {
 			return nil, errors.Wrap(err, "error sending sign new signing policy")
 		}
 		return nil, nil
-	}, shared.MaxTxSendRetries, shared.TxRetryInterval)
+	}, s.retryCfg.Policy(config.RetryOpSignNewSigningPolicy))
 }
 
-func (s *systemsManagerContractClientImpl) sendSignNewSigningPolicy(rewardEpochId *big.Int, signingPolicy []byte) error {
-	newSigningPolicyHash := SigningPolicyHash(signingPolicy)
-	hashSignature, err := crypto.Sign(accounts.TextHash(newSigningPolicyHash), s.signerPrivateKey)
+func (s *systemsManagerContractClientImpl) sendSignNewSigningPolicy(rewardEpochId *big.Int, signingPolicy []byte, deadline time.Time) error {
+	// after a restart the policy may be signed already, the tx would revert
+	signed, err := s.signedNewSigningPolicy(rewardEpochId)
+	if err != nil {
+		return errors.Wrap(err, "error fetching signing policy sign info")
+	}
+	if signed {
+		return shared.AlreadyDone(errors.Errorf("voter %s already signed the new signing policy", s.voter.Hex()))
+	}
+
+	// the new signing policy is signed in the previous reward epoch
+	signer := s.policySigner(rewardEpochId.Int64() - 1)
+	newSigningPolicyHash := signingpolicy.Hash(signingPolicy)
+	hashSignature, err := signer.SignText(newSigningPolicyHash.Bytes())
 	if err != nil {
 		return err
 	}
@@ -94,31 +185,96 @@ func (s *systemsManagerContractClientImpl) sendSignNewSigningPolicy(rewardEpochI
 		V: hashSignature[64] + 27,
 	}
 
-	tx, err := s.flareSystemsManager.SignNewSigningPolicy(s.senderTxOpts, rewardEpochId, [32]byte(newSigningPolicyHash), signature)
+	gasCfg := s.gasCfg
+	var fees *chain.TxFees
+	if s.urgentPolicySigning(deadline, s.clock.Now()) {
+		gasCfg, fees = s.urgentPolicySigningFees()
+		logger.With("rewardEpochId", rewardEpochId).Warn(
+			"Signing window of the signing policy closes at %v, sending with %vx gas price", deadline, s.policySigningCfg.UrgentGasPriceMultiplier,
+		)
+	}
+
+	tx, err := chain.TransactWithNonce(s.ethClient, s.senderTxOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if err := s.setGasLimit(opts, config.RetryOpSignNewSigningPolicy, "signNewSigningPolicy", rewardEpochId, newSigningPolicyHash, signature); err != nil {
+			return nil, err
+		}
+		if fees != nil {
+			fees.Apply(opts)
+		}
+		return s.flareSystemsManager.SignNewSigningPolicy(opts, rewardEpochId, newSigningPolicyHash, signature)
+	})
 	if err != nil {
 		if shared.ExistsAsSubstring(nonFatalSignNewSigningPolicyErrors, err.Error()) {
-			logger.Info("Non fatal error sending sign new signing policy: %v", err)
-			return nil
+			return shared.AlreadyDone(err)
 		}
 		return err
 	}
-	err = s.txVerifier.WaitUntilMined(s.senderTxOpts.From, tx, chain.DefaultTxTimeout)
+	_, err = s.txVerifier.WaitUntilMinedWithEscalation(s.senderTxOpts.From, tx, s.senderTxOpts.Signer, gasCfg, config.RetryOpSignNewSigningPolicy, chain.DefaultTxTimeout,
+		s.signedEvent("SigningPolicySigned", rewardEpochId, signer, func(log types.Log) (*big.Int, common.Address, error) {
+			event, err := s.flareSystemsManager.ParseSigningPolicySigned(log)
+			if err != nil {
+				return nil, common.Address{}, err
+			}
+			return event.RewardEpochId, event.SigningPolicyAddress, nil
+		}))
 	if err != nil {
 		return err
 	}
-	logger.Info("New signing policy sent for epoch %v", rewardEpochId)
+	logger.With("rewardEpochId", rewardEpochId).Info("New signing policy sent for epoch %v", rewardEpochId)
 	return nil
 }
 
-func SigningPolicyHash(signingPolicy []byte) []byte {
-	if len(signingPolicy)%32 != 0 {
-		signingPolicy = append(signingPolicy, make([]byte, 32-len(signingPolicy)%32)...)
+// Returns true if less than urgent_before of the signing window is left at now. Each
+// retry checks again, a tx sent earlier is replaced by the retry after its timeout.
+func (s *systemsManagerContractClientImpl) urgentPolicySigning(deadline time.Time, now time.Time) bool {
+	if s.policySigningCfg == nil || deadline.IsZero() {
+		return false
+	}
+	return deadline.Sub(now) < s.policySigningCfg.UrgentBefore
+}
+
+// Returns the gas config replacing the tx every block and the fees of gas_register
+// multiplied by urgent_gas_price_multiplier
+func (s *systemsManagerContractClientImpl) urgentPolicySigningFees() (*config.GasConfig, *chain.TxFees) {
+	gasCfg := *s.gasCfg.Snapshot()
+	gasCfg.BumpAfterBlocks = 1
+	if gasCfg.GasPriceFixed == nil {
+		gasCfg.GasPriceFixed = big.NewInt(0)
 	}
-	hash := crypto.Keccak256(signingPolicy[:32], signingPolicy[32:64])
-	for i := 2; i < len(signingPolicy)/32; i++ {
-		hash = crypto.Keccak256(hash, signingPolicy[i*32:(i+1)*32])
+	fees, err := chain.GetTxFees(&gasCfg, s.ethClient)
+	if err != nil {
+		logger.Warn("Unable to obtain gas price: %v, using fallback %d", err, fallbackGasPrice)
+		fees = &chain.TxFees{GasPrice: fallbackGasPrice}
 	}
-	return hash
+	return &gasCfg, fees.Scaled(s.policySigningCfg.UrgentGasPriceMultiplier, &gasCfg)
+}
+
+// Returns true if the voter already signed the signing policy of the reward epoch
+func (s *systemsManagerContractClientImpl) signedNewSigningPolicy(rewardEpochId *big.Int) (bool, error) {
+	if s.voter == chain.EmptyAddress {
+		return false, nil
+	}
+	info, err := s.flareSystemsManager.GetVoterSigningPolicySignInfo(nil, rewardEpochId, s.voter)
+	if err != nil {
+		return false, err
+	}
+	return info.SigningPolicySignBlock != 0, nil
+}
+
+// Sets the gas limit of the tx, fixed in the gas config or estimated. Not set in dry run
+// mode, where the gas is estimated by the simulation.
+func (s *systemsManagerContractClientImpl) setGasLimit(opts *bind.TransactOpts, operation string, method string, args ...interface{}) error {
+	if opts.GasLimit != 0 {
+		return nil
+	}
+	gasLimit, err := chain.ContractGasLimit(
+		context.Background(), s.ethClient, s.gasCfg, operation, opts.From, s.address, system.FlareSystemsManagerMetaData, method, args...,
+	)
+	if err != nil {
+		return err
+	}
+	opts.GasLimit = gasLimit
+	return nil
 }
 
 func (s *systemsManagerContractClientImpl) GetCurrentRewardEpochId() <-chan shared.ExecuteStatus[*big.Int] {
@@ -131,37 +287,61 @@ func (s *systemsManagerContractClientImpl) GetCurrentRewardEpochId() <-chan shar
 	}, shared.MaxTxSendRetries, shared.TxRetryInterval)
 }
 
-func (s *systemsManagerContractClientImpl) VotePowerBlockSelectedListener(db epochClientDB, epoch *utils.Epoch) <-chan *system.FlareSystemsManagerVotePowerBlockSelected {
-	out := make(chan *system.FlareSystemsManagerVotePowerBlockSelected)
-	topic0, err := chain.EventIDFromMetadata(system.FlareSystemsManagerMetaData, "VotePowerBlockSelected")
-	if err != nil {
-		// panic, this error is fatal
-		panic(err)
-	}
+func (s *systemsManagerContractClientImpl) VotePowerBlockSelectedListener(ctx context.Context, db EpochClientDB, epoch *utils.Epoch) <-chan *system.FlareSystemsManagerVotePowerBlockSelected {
+	out := shared.NewListenerOutput[*system.FlareSystemsManagerVotePowerBlockSelected](listenerVotePowerBlockSelected, s.listenerBuffers.Buffer(listenerVotePowerBlockSelected, 0))
+	topic0 := s.topic0VotePowerBlockSelected
 	go func() {
 		randomDelay()
-		ticker := time.NewTicker(shared.EventListenerInterval)
-		eventRangeStart := epoch.StartTime(epoch.EpochIndex(time.Now()) - 1).Unix()
+		ticker := shared.NewListenerTicker(s.clock, listenerVotePowerBlockSelected, s.listenerIntervals.Interval(listenerVotePowerBlockSelected))
+		defer ticker.Stop()
+		delivered := shared.NewDeliveredEvents(listenerRangeStart(db, listenerVotePowerBlockSelected, epoch.StartTime(epoch.EpochIndex(s.clock.Now())-1).Unix()))
+		// emits the event if it was not delivered yet, returns false if ctx is done
+		deliver := func(id shared.EventID, powerBlockData *system.FlareSystemsManagerVotePowerBlockSelected) bool {
+			timestamp := int64(powerBlockData.Timestamp)
+			if !delivered.IsNew(id, timestamp) {
+				return true
+			}
+			if !out.Send(ctx, powerBlockData) {
+				return false
+			}
+			delivered.Add(id, timestamp)
+			shared.RecordListenerEvent(listenerVotePowerBlockSelected, timestamp)
+			return true
+		}
+		wsLogs := shared.SubscribeLogsInBackground(ctx, s.clock, s.vpbsSubscriber, s.address, topic0, "VotePowerBlockSelected")
+		handleLog := func(log types.Log) bool {
+			powerBlockData, err := s.flareSystemsManager.FlareSystemsManagerFilterer.ParseVotePowerBlockSelected(log)
+			if err != nil {
+				logger.Error("Error parsing VotePowerBlockSelected event %v", err)
+				return true
+			}
+			return deliver(shared.EventID{TxHash: log.TxHash, LogIndex: uint64(log.Index)}, powerBlockData)
+		}
 		for {
-			<-ticker.C
-			now := time.Now().Unix()
-			logs, err := db.FetchLogsByAddressAndTopic0(s.address, topic0, eventRangeStart, now)
+			if !ticker.WaitLogs(ctx, wsLogs, handleLog) {
+				return
+			}
+			now := s.clock.Now().Unix()
+			logs, err := db.FetchLogsByAddressAndTopic0(s.address, topic0, delivered.RangeStart(), now)
 			if err != nil {
 				logger.Error("Error fetching logs %v", err)
 				continue
 			}
-			if len(logs) > 0 {
-				powerBlockData, err := s.parseVotePowerBlockSelectedEvent(logs[len(logs)-1])
+			shared.RecordListenerRowsScanned(listenerVotePowerBlockSelected, len(logs))
+			for _, log := range logs {
+				powerBlockData, err := s.parseVotePowerBlockSelectedEvent(log)
 				if err != nil {
 					logger.Error("Error parsing VotePowerBlockSelected event %v", err)
 					continue
 				}
-				out <- powerBlockData
-				eventRangeStart = int64(powerBlockData.Timestamp)
+				if !deliver(shared.EventID{TxHash: common.HexToHash(log.TransactionHash), LogIndex: log.LogIndex}, powerBlockData) {
+					return
+				}
+				delivered.Confirm(int64(log.Timestamp))
 			}
 		}
 	}()
-	return out
+	return out.C()
 }
 
 func (s *systemsManagerContractClientImpl) parseVotePowerBlockSelectedEvent(dbLog database.Log) (*system.FlareSystemsManagerVotePowerBlockSelected, error) {
@@ -176,27 +356,31 @@ func (s *systemsManagerContractClientImpl) RewardEpochFromChain() (*utils.Epoch,
 	return shared.RewardEpochFromChain(s.flareSystemsManager)
 }
 
-func (s *systemsManagerContractClientImpl) SignUptimeVoteEnabledListener(db epochClientDB, epoch *utils.Epoch, window int64) <-chan *system.FlareSystemsManagerSignUptimeVoteEnabled {
-	out := make(chan *system.FlareSystemsManagerSignUptimeVoteEnabled)
-	topic0, err := chain.EventIDFromMetadata(system.FlareSystemsManagerMetaData, "SignUptimeVoteEnabled")
-	if err != nil {
-		// panic, this error is fatal
-		panic(err)
-	}
+func (s *systemsManagerContractClientImpl) RefreshRewardEpoch(epoch *utils.Epoch) (bool, error) {
+	return shared.RefreshRewardEpoch(s.flareSystemsManager, epoch)
+}
+
+func (s *systemsManagerContractClientImpl) SignUptimeVoteEnabledListener(ctx context.Context, db EpochClientDB, epoch *utils.Epoch, window int64) <-chan *system.FlareSystemsManagerSignUptimeVoteEnabled {
+	out := shared.NewListenerOutput[*system.FlareSystemsManagerSignUptimeVoteEnabled](listenerSignUptimeVoteEnabled, s.listenerBuffers.Buffer(listenerSignUptimeVoteEnabled, 0))
+	topic0 := s.topic0SignUptimeVoteEnabled
 	go func() {
 		randomDelay()
-		ticker := time.NewTicker(shared.EventListenerInterval)
-		currentEpoch := epoch.EpochIndex(time.Now())
-		eventRangeStart := epoch.StartTime(currentEpoch - window + 1).Unix()
+		ticker := shared.NewListenerTicker(s.clock, listenerSignUptimeVoteEnabled, s.listenerIntervals.Interval(listenerSignUptimeVoteEnabled))
+		defer ticker.Stop()
+		currentEpoch := epoch.EpochIndex(s.clock.Now())
+		eventRangeStart := listenerRangeStart(db, listenerSignUptimeVoteEnabled, epoch.StartTime(currentEpoch-window+1).Unix())
 		logger.Info("Current epoch %d", currentEpoch)
 		for {
-			<-ticker.C
-			now := time.Now().Unix()
+			if !ticker.Wait(ctx) {
+				return
+			}
+			now := s.clock.Now().Unix()
 			logs, err := db.FetchLogsByAddressAndTopic0(s.address, topic0, eventRangeStart, now)
 			if err != nil {
 				logger.Error("Error fetching logs %v", err)
 				continue
 			}
+			shared.RecordListenerRowsScanned(listenerSignUptimeVoteEnabled, len(logs))
 			for _, log := range logs {
 				uptimeVoteEnabled, err := s.parseSignUptimeVoteEnabledEvent(log)
 				if err != nil {
@@ -204,13 +388,16 @@ func (s *systemsManagerContractClientImpl) SignUptimeVoteEnabledListener(db epoc
 					continue
 				}
 				if uptimeVoteEnabled.RewardEpochId.Int64() >= (currentEpoch - window) {
-					out <- uptimeVoteEnabled
+					if !out.Send(ctx, uptimeVoteEnabled) {
+						return
+					}
 				}
 				eventRangeStart = int64(uptimeVoteEnabled.Timestamp)
+				shared.RecordListenerEvent(listenerSignUptimeVoteEnabled, int64(uptimeVoteEnabled.Timestamp))
 			}
 		}
 	}()
-	return out
+	return out.C()
 }
 
 func (s *systemsManagerContractClientImpl) parseSignUptimeVoteEnabledEvent(dbLog database.Log) (*system.FlareSystemsManagerSignUptimeVoteEnabled, error) {
@@ -221,58 +408,71 @@ func (s *systemsManagerContractClientImpl) parseSignUptimeVoteEnabledEvent(dbLog
 	return s.flareSystemsManager.FlareSystemsManagerFilterer.ParseSignUptimeVoteEnabled(*contractLog)
 }
 
-func (s *systemsManagerContractClientImpl) SignUptimeVote(rewardEpochId *big.Int) <-chan shared.ExecuteStatus[any] {
-	return shared.ExecuteWithRetry(func() (any, error) {
-		err := s.sendSignUptimeVote(rewardEpochId)
+func (s *systemsManagerContractClientImpl) SignUptimeVote(rewardEpochId *big.Int, hash common.Hash) <-chan shared.ExecuteStatus[any] {
+	return shared.ExecuteWithRetryPolicy(func() (any, error) {
+		err := s.sendSignUptimeVote(rewardEpochId, hash)
 		if err != nil {
 			return nil, errors.Wrap(err, "error sending sign uptime vote")
 		}
 		return nil, nil
-	}, shared.MaxTxSendRetries, shared.TxRetryInterval)
+	}, s.retryCfg.Policy(config.RetryOpSignUptimeVote))
 }
 
-func (s *systemsManagerContractClientImpl) sendSignUptimeVote(rewardEpochId *big.Int) error {
-	hash, signature, err := getUptimeSignature(rewardEpochId, s.signerPrivateKey)
+func (s *systemsManagerContractClientImpl) sendSignUptimeVote(rewardEpochId *big.Int, hash common.Hash) error {
+	// the uptime vote is signed in the next reward epoch
+	signer := s.policySigner(rewardEpochId.Int64() + 1)
+	signature, err := getUptimeSignature(rewardEpochId, hash, signer)
 	if err != nil {
 		return err
 	}
 
-	tx, err := s.flareSystemsManager.SignUptimeVote(s.senderTxOpts, rewardEpochId, hash, *signature)
+	tx, err := chain.TransactWithNonce(s.ethClient, s.senderTxOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if err := s.setGasLimit(opts, config.RetryOpSignUptimeVote, "signUptimeVote", rewardEpochId, hash, *signature); err != nil {
+			return nil, err
+		}
+		return s.flareSystemsManager.SignUptimeVote(opts, rewardEpochId, hash, *signature)
+	})
 	if err != nil {
 		if shared.ExistsAsSubstring(nonFatalSignUptimeVoteErrors, err.Error()) {
-			logger.Info("Non fatal error sending sign uptime vote: %v", err)
-			return nil
+			return shared.AlreadyDone(err)
 		}
 		return err
 	}
-	err = s.txVerifier.WaitUntilMined(s.senderTxOpts.From, tx, chain.DefaultTxTimeout)
+	_, err = s.txVerifier.WaitUntilMinedWithEscalation(s.senderTxOpts.From, tx, s.senderTxOpts.Signer, s.gasCfg, config.RetryOpSignUptimeVote, chain.DefaultTxTimeout,
+		s.signedEvent("UptimeVoteSigned", rewardEpochId, signer, func(log types.Log) (*big.Int, common.Address, error) {
+			event, err := s.flareSystemsManager.ParseUptimeVoteSigned(log)
+			if err != nil {
+				return nil, common.Address{}, err
+			}
+			return event.RewardEpochId, event.SigningPolicyAddress, nil
+		}))
 	if err != nil {
 		return err
 	}
-	logger.Info("Uptime vote sent for epoch %v", rewardEpochId)
+	logger.With("rewardEpochId", rewardEpochId).Info("Uptime vote sent for epoch %v", rewardEpochId)
 	return nil
 }
 
-func (s *systemsManagerContractClientImpl) UptimeVoteSignedListener(db epochClientDB, epoch *utils.Epoch, window int64) <-chan *system.FlareSystemsManagerUptimeVoteSigned {
-	out := make(chan *system.FlareSystemsManagerUptimeVoteSigned)
-	topic0, err := chain.EventIDFromMetadata(system.FlareSystemsManagerMetaData, "UptimeVoteSigned")
-	if err != nil {
-		// panic, this error is fatal
-		panic(err)
-	}
+func (s *systemsManagerContractClientImpl) UptimeVoteSignedListener(ctx context.Context, db EpochClientDB, epoch *utils.Epoch, window int64) <-chan *system.FlareSystemsManagerUptimeVoteSigned {
+	out := shared.NewListenerOutput[*system.FlareSystemsManagerUptimeVoteSigned](listenerUptimeVoteSigned, s.listenerBuffers.Buffer(listenerUptimeVoteSigned, 0))
+	topic0 := s.topic0UptimeVoteSigned
 	go func() {
 		randomDelay()
-		ticker := time.NewTicker(shared.EventListenerInterval)
-		currentEpoch := epoch.EpochIndex(time.Now())
-		eventRangeStart := epoch.StartTime(currentEpoch - window + 1).Unix()
+		ticker := shared.NewListenerTicker(s.clock, listenerUptimeVoteSigned, s.listenerIntervals.Interval(listenerUptimeVoteSigned))
+		defer ticker.Stop()
+		currentEpoch := epoch.EpochIndex(s.clock.Now())
+		eventRangeStart := listenerRangeStart(db, listenerUptimeVoteSigned, epoch.StartTime(currentEpoch-window+1).Unix())
 		for {
-			<-ticker.C
-			now := time.Now().Unix()
+			if !ticker.Wait(ctx) {
+				return
+			}
+			now := s.clock.Now().Unix()
 			logs, err := db.FetchLogsByAddressAndTopic0(s.address, topic0, eventRangeStart, now)
 			if err != nil {
 				logger.Error("Error fetching logs %v", err)
 				continue
 			}
+			shared.RecordListenerRowsScanned(listenerUptimeVoteSigned, len(logs))
 
 			for _, log := range logs {
 				contractLog, err := shared.ConvertDatabaseLogToChainLog(log)
@@ -286,30 +486,38 @@ func (s *systemsManagerContractClientImpl) UptimeVoteSignedListener(db epochClie
 					continue
 				}
 				if uptimeVoteSigned.ThresholdReached && uptimeVoteSigned.RewardEpochId.Int64() >= (currentEpoch-window) {
-					out <- uptimeVoteSigned
+					if !out.Send(ctx, uptimeVoteSigned) {
+						return
+					}
 				}
 				eventRangeStart = int64(uptimeVoteSigned.Timestamp)
+				shared.RecordListenerEvent(listenerUptimeVoteSigned, int64(uptimeVoteSigned.Timestamp))
 			}
 		}
 	}()
-	return out
+	return out.C()
 }
 
-func (s *systemsManagerContractClientImpl) SignRewards(epochId *big.Int, rewardHash *common.Hash, weightClaims int) <-chan shared.ExecuteStatus[any] {
-	return shared.ExecuteWithRetry(func() (any, error) {
-		err := s.sendSignRewards(epochId, rewardHash, weightClaims)
+func (s *systemsManagerContractClientImpl) SignRewards(
+	ctx context.Context, epochId *big.Int, rewardHash *common.Hash, weightClaims int,
+) <-chan shared.ExecuteStatus[any] {
+	return shared.ExecuteWithRetryPolicyContext(ctx, func() (any, error) {
+		err := s.sendSignRewards(ctx, epochId, rewardHash, weightClaims)
 		if err != nil {
 			return nil, errors.Wrap(err, "error sending sign rewards")
 		}
 		return nil, nil
-	}, shared.MaxTxSendRetries, shared.TxRetryInterval)
+	}, s.retryCfg.Policy(config.RetryOpSignRewards))
 }
 
-func (s *systemsManagerContractClientImpl) sendSignRewards(epochId *big.Int, rewardHash *common.Hash, weightClaims int) error {
-	logger.Info("Signing rewards for epoch %v, hash: %s", epochId, rewardHash.Hex())
+func (s *systemsManagerContractClientImpl) sendSignRewards(ctx context.Context, epochId *big.Int, rewardHash *common.Hash, weightClaims int) error {
+	epochLogger := logger.With("rewardEpochId", epochId)
+	epochLogger.Info("Signing rewards for epoch %v, hash: %s", epochId, rewardHash.Hex())
 	packed := encodeRewardsData(epochId, s.chainId, rewardHash, weightClaims)
 
-	hashSignature, err := crypto.Sign(accounts.TextHash(crypto.Keccak256(packed)), s.signerPrivateKey)
+	// the rewards are signed in the next reward epoch
+	signer := s.policySigner(epochId.Int64() + 1)
+	hashSignature, err := signer.SignText(crypto.Keccak256(packed))
 	if err != nil {
 		return err
 	}
@@ -320,24 +528,38 @@ func (s *systemsManagerContractClientImpl) sendSignRewards(epochId *big.Int, rew
 		V: hashSignature[64] + 27,
 	}
 
-	tx, err := s.flareSystemsManager.SignRewards(s.senderTxOpts, epochId, []system.IFlareSystemsManagerNumberOfWeightBasedClaims{
-		{
-			RewardManagerId:       big.NewInt(int64(s.chainId)),
-			NoOfWeightBasedClaims: big.NewInt(int64(weightClaims)),
-		},
-	}, *rewardHash, signature)
+	txOpts := *s.senderTxOpts
+	txOpts.Context = ctx
+	tx, err := chain.TransactWithNonce(s.ethClient, &txOpts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		claims := []system.IFlareSystemsManagerNumberOfWeightBasedClaims{
+			{
+				RewardManagerId:       big.NewInt(int64(s.chainId)),
+				NoOfWeightBasedClaims: big.NewInt(int64(weightClaims)),
+			},
+		}
+		if err := s.setGasLimit(opts, config.RetryOpSignRewards, "signRewards", epochId, claims, *rewardHash, signature); err != nil {
+			return nil, err
+		}
+		return s.flareSystemsManager.SignRewards(opts, epochId, claims, *rewardHash, signature)
+	})
 	if err != nil {
 		if shared.ExistsAsSubstring(nonFatalSignRewardsErrors, err.Error()) {
-			logger.Info("Non fatal error sending reward signature: %v", err)
-			return nil
+			return shared.AlreadyDone(err)
 		}
 		return err
 	}
-	err = s.txVerifier.WaitUntilMined(s.senderTxOpts.From, tx, chain.DefaultTxTimeout)
+	_, err = s.txVerifier.WaitUntilMinedWithEscalationContext(ctx, s.senderTxOpts.From, tx, s.senderTxOpts.Signer, s.gasCfg, config.RetryOpSignRewards, chain.DefaultTxTimeout,
+		s.signedEvent("RewardsSigned", epochId, signer, func(log types.Log) (*big.Int, common.Address, error) {
+			event, err := s.flareSystemsManager.ParseRewardsSigned(log)
+			if err != nil {
+				return nil, common.Address{}, err
+			}
+			return event.RewardEpochId, event.SigningPolicyAddress, nil
+		}))
 	if err != nil {
 		return err
 	}
-	logger.Info("Rewards signed for epoch %v", epochId)
+	epochLogger.Info("Rewards signed for epoch %v", epochId)
 
 	return nil
 }
@@ -0,0 +1,181 @@
+package epoch
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"flare-tlc/utils/contracts/system"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns the signing policy sign block of the voters from getVoterSigningPolicySignInfo
+type testSignInfoCaller struct {
+	t          *testing.T
+	signBlocks map[common.Address]uint64
+}
+
+func (c *testSignInfoCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x01}, nil
+}
+
+func (c *testSignInfoCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	abi, err := system.FlareSystemsManagerMetaData.GetAbi()
+	require.NoError(c.t, err)
+	method := abi.Methods["getVoterSigningPolicySignInfo"]
+	require.Equal(c.t, method.ID, call.Data[:4])
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	require.NoError(c.t, err)
+	signBlock := c.signBlocks[args[1].(common.Address)]
+	return method.Outputs.Pack(signBlock*2, signBlock)
+}
+
+func TestSignNewSigningPolicyAlreadySigned(t *testing.T) {
+	voter := common.HexToAddress("0x1234")
+	caller, err := system.NewFlareSystemsManagerCaller(common.HexToAddress("0x01"), &testSignInfoCaller{
+		t:          t,
+		signBlocks: map[common.Address]uint64{voter: 100},
+	})
+	require.NoError(t, err)
+	client := &systemsManagerContractClientImpl{
+		flareSystemsManager: &system.FlareSystemsManager{FlareSystemsManagerCaller: *caller},
+		retryCfg:            &config.RetryConfig{},
+		voter:               voter,
+	}
+
+	signed, err := client.signedNewSigningPolicy(big.NewInt(5))
+	require.NoError(t, err)
+	require.True(t, signed)
+
+	// skipped without sending the tx
+	result := <-client.SignNewSigningPolicy(big.NewInt(5), nil, time.Time{})
+	require.True(t, result.Success)
+
+	client.voter = common.HexToAddress("0x5678")
+	signed, err = client.signedNewSigningPolicy(big.NewInt(5))
+	require.NoError(t, err)
+	require.False(t, signed)
+}
+
+func TestUrgentPolicySigning(t *testing.T) {
+	client := &systemsManagerContractClientImpl{
+		gasCfg: &config.GasConfig{GasPriceFixed: big.NewInt(50), GasPriceCap: big.NewInt(80)},
+		policySigningCfg: &config.PolicySigningConfig{
+			UrgentBefore:             30 * time.Minute,
+			UrgentGasPriceMultiplier: 2,
+		},
+	}
+	deadline := time.Unix(10000, 0)
+	require.False(t, client.urgentPolicySigning(deadline, deadline.Add(-time.Hour)))
+	require.True(t, client.urgentPolicySigning(deadline, deadline.Add(-10*time.Minute)))
+	require.True(t, client.urgentPolicySigning(deadline, deadline.Add(time.Minute)))
+	require.False(t, client.urgentPolicySigning(time.Time{}, deadline))
+
+	gasCfg, fees := client.urgentPolicySigningFees()
+	require.Equal(t, uint64(1), gasCfg.BumpAfterBlocks)
+	require.Equal(t, int64(80), fees.GasPrice.Int64())
+	require.Equal(t, uint64(0), client.gasCfg.BumpAfterBlocks)
+}
+
+// Indexer logs with the checkpoints of the listeners
+type testLogsDB struct {
+	testCheckpointDB
+	mu   sync.Mutex
+	logs []database.Log
+}
+
+func (db *testLogsDB) FetchLogsByAddressAndTopic0(address common.Address, topic0 string, from, to int64) ([]database.Log, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var logs []database.Log
+	for _, log := range db.logs {
+		if int64(log.Timestamp) > from && int64(log.Timestamp) <= to {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (db *testLogsDB) addLog(log database.Log) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.logs = append(db.logs, log)
+}
+
+func votePowerBlockSelectedLog(t *testing.T, rewardEpochId int64, txHash string, logIndex uint64, timestamp uint64) database.Log {
+	abi, err := system.FlareSystemsManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	event := abi.Events["VotePowerBlockSelected"]
+	data, err := event.Inputs.NonIndexed().Pack(uint64(1000), timestamp)
+	require.NoError(t, err)
+	return database.Log{
+		Data:            common.Bytes2Hex(data),
+		Topic0:          event.ID.Hex(),
+		Topic1:          common.BigToHash(big.NewInt(rewardEpochId)).Hex(),
+		Topic2:          "NULL",
+		Topic3:          "NULL",
+		TransactionHash: txHash,
+		LogIndex:        logIndex,
+		Timestamp:       timestamp,
+	}
+}
+
+func TestVotePowerBlockSelectedListenerDeliversOnce(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(10000, 0))
+	epoch := utils.NewEpoch(time.Unix(0, 0), 3600*time.Second)
+	db := &testLogsDB{testCheckpointDB: testCheckpointDB{checkpoints: make(map[string]int64)}}
+	db.addLog(votePowerBlockSelectedLog(t, 2, "01", 0, 7300))
+	db.addLog(votePowerBlockSelectedLog(t, 3, "02", 0, 9000))
+	db.addLog(votePowerBlockSelectedLog(t, 4, "02", 1, 9000))
+
+	fsm, err := system.NewFlareSystemsManager(common.HexToAddress("0x01"), nil)
+	require.NoError(t, err)
+	client := &systemsManagerContractClientImpl{flareSystemsManager: fsm, clock: clock}
+
+	// returns the reward epochs of the events emitted by the next polls
+	poll := func(events <-chan *system.FlareSystemsManagerVotePowerBlockSelected, polls int) []int64 {
+		var rewardEpochs []int64
+		for i := 0; i < polls; i++ {
+			clock.BlockUntilWaiters(1)
+			clock.Advance(client.listenerIntervals.Interval(listenerVotePowerBlockSelected))
+			timeout := time.After(100 * time.Millisecond)
+		receive:
+			for {
+				select {
+				case event := <-events:
+					rewardEpochs = append(rewardEpochs, event.RewardEpochId.Int64())
+				case <-timeout:
+					break receive
+				}
+			}
+		}
+		return rewardEpochs
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := client.VotePowerBlockSelectedListener(ctx, db, epoch)
+	require.Equal(t, []int64{2, 3, 4}, poll(events, 3))
+
+	// a later event with the timestamp of the last delivered one
+	db.addLog(votePowerBlockSelectedLog(t, 5, "03", 0, 9000))
+	require.Equal(t, []int64{5}, poll(events, 2))
+	cancel()
+
+	// the checkpoint is saved by the consumer once the events are handled
+	require.NotContains(t, db.checkpoints, listenerVotePowerBlockSelected)
+	require.NoError(t, db.SaveListenerCheckpoint(listenerVotePowerBlockSelected, 9000))
+
+	// the restarted listener resumes after the checkpoint
+	db.addLog(votePowerBlockSelectedLog(t, 6, "04", 0, 9500))
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	events = client.VotePowerBlockSelectedListener(ctx, db, epoch)
+	require.Equal(t, []int64{6}, poll(events, 2))
+}
@@ -1,13 +1,17 @@
 package epoch
 
 import (
-	"crypto/ecdsa"
+	"context"
 	"encoding/hex"
-	"flare-tlc/logger"
+	"encoding/json"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared"
 	"flare-tlc/utils/contracts/system"
-	"github.com/ethereum/go-ethereum/accounts"
+	"flare-tlc/utils/credentials"
+	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 	"math/big"
@@ -29,17 +33,56 @@ var (
 	}
 )
 
-func getUptimeSignature(rewardEpochId *big.Int, privateKey *ecdsa.PrivateKey) (common.Hash, *system.IFlareSystemsManagerSignature, error) {
-	logger.Info("Signing uptime vote for epoch %v: zero hash %s", rewardEpochId, hex.EncodeToString(zeroHash[:]))
+type uptimeVoteHash struct {
+	RewardEpochId  int    `json:"rewardEpochId"`
+	UptimeVoteHash string `json:"uptimeVoteHash"`
+}
+
+// getUptimeVoteHash returns the uptime vote hash for the reward epoch from
+// <prefix>/<epochId>/uptime-vote-hash.json, or the zero hash if the prefix is not set.
+func getUptimeVoteHash(ctx context.Context, epochId *big.Int, uptimeConfig *config.UptimeConfig, policy config.RetryPolicy) (common.Hash, error) {
+	prefix := uptimeConfig.PathPrefix
+	if prefix == "" {
+		return zeroHash, nil
+	}
+
+	path := fmt.Sprintf("%s/%d/uptime-vote-hash.json", prefix, epochId)
+	voteHash, err := fetchHashFileWithRetry(ctx, path, "uptime vote hash", policy, func(bytes []byte) (*uptimeVoteHash, error) {
+		var voteHash uptimeVoteHash
+		err := json.Unmarshal(bytes, &voteHash)
+		if err != nil {
+			return nil, errors.Wrap(err, "error decoding uptime vote hash file")
+		}
+		if voteHash.RewardEpochId != int(epochId.Int64()) {
+			return nil, shared.Fatal(errors.Errorf("invalid uptime vote hash epoch id: %d, expected: %d", voteHash.RewardEpochId, epochId))
+		}
+		return &voteHash, nil
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	hashBytes, err := hexutil.Decode(voteHash.UptimeVoteHash)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "invalid uptime vote hash")
+	}
+	if len(hashBytes) != common.HashLength {
+		return common.Hash{}, errors.Errorf("invalid uptime vote hash length: %v", len(hashBytes))
+	}
+	return common.BytesToHash(hashBytes), nil
+}
+
+func getUptimeSignature(rewardEpochId *big.Int, hash common.Hash, signer credentials.Signer) (*system.IFlareSystemsManagerSignature, error) {
+	logger.Info("Signing uptime vote for epoch %v: hash %s", rewardEpochId, hex.EncodeToString(hash[:]))
 
-	toSign, err := uptimeVoteArguments.Pack(rewardEpochId.Int64(), zeroHash)
+	toSign, err := uptimeVoteArguments.Pack(rewardEpochId.Int64(), hash)
 	if err != nil {
-		return zeroHash, nil, errors.Wrapf(err, "error packing uptime vote arguments: %v, %v", rewardEpochId, zeroHash)
+		return nil, errors.Wrapf(err, "error packing uptime vote arguments: %v, %v", rewardEpochId, hash)
 	}
 
-	hashSignature, err := crypto.Sign(accounts.TextHash(crypto.Keccak256(toSign)), privateKey)
+	hashSignature, err := signer.SignText(crypto.Keccak256(toSign))
 	if err != nil {
-		return zeroHash, nil, err
+		return nil, err
 	}
 
 	signature := system.IFlareSystemsManagerSignature{
@@ -47,5 +90,5 @@ func getUptimeSignature(rewardEpochId *big.Int, privateKey *ecdsa.PrivateKey) (c
 		S: [32]byte(hashSignature[32:64]),
 		V: hashSignature[64] + 27,
 	}
-	return zeroHash, &signature, nil
+	return &signature, nil
 }
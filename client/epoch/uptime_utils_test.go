@@ -0,0 +1,43 @@
+package epoch
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testFetchRetryPolicy = config.RetryPolicy{MaxRetries: 1}
+
+func Test_getUptimeVoteHash(t *testing.T) {
+	t.Run("zero hash if path prefix is not set", func(t *testing.T) {
+		hash, err := getUptimeVoteHash(context.Background(), big.NewInt(3), &config.UptimeConfig{}, testFetchRetryPolicy)
+		require.NoError(t, err)
+		require.Equal(t, zeroHash, hash)
+	})
+
+	t.Run("hash from file", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "3"), 0o755))
+		expected := common.HexToHash("0x9d91c7d9595969e7d21783b904f8707316d6267c656b60fad0e070e9c698a672")
+		content := `{"rewardEpochId": 3, "uptimeVoteHash": "` + expected.Hex() + `"}`
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "3", "uptime-vote-hash.json"), []byte(content), 0o644))
+
+		hash, err := getUptimeVoteHash(context.Background(), big.NewInt(3), &config.UptimeConfig{PathPrefix: dir}, testFetchRetryPolicy)
+		require.NoError(t, err)
+		require.Equal(t, expected, hash)
+
+		_, err = getUptimeVoteHash(context.Background(), big.NewInt(4), &config.UptimeConfig{PathPrefix: dir}, testFetchRetryPolicy)
+		require.Error(t, err)
+
+		// not fetched after the client was stopped
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = getUptimeVoteHash(ctx, big.NewInt(3), &config.UptimeConfig{PathPrefix: dir}, config.RetryPolicy{MaxRetries: 3})
+		require.ErrorContains(t, err, context.Canceled.Error())
+	})
+}
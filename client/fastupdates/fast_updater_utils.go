@@ -0,0 +1,141 @@
+package fastupdates
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/fastupdater"
+	"flare-tlc/utils/contracts/system"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+)
+
+type fastUpdaterContractClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	CurrentRewardEpochId(ctx context.Context) (int64, error)
+	Seed(ctx context.Context, rewardEpochId int64) (*big.Int, error)
+	SortitionWeight(ctx context.Context, signingPolicyAddress common.Address) (uint64, error)
+	BlockScoreCutoff(ctx context.Context, blockNumber uint64) (*big.Int, error)
+	SubmissionWindow(ctx context.Context) (uint64, error)
+
+	// Sends the submitUpdates tx, wait waits until it is mined
+	SubmitUpdates(ctx context.Context, updates *fastupdater.IFastUpdaterFastUpdates) (wait func() error, err error)
+}
+
+type fastUpdaterContractClientImpl struct {
+	ethClient      *ethclient.Client
+	address        common.Address
+	fastUpdater    *fastupdater.FastUpdater
+	systemsManager *system.FlareSystemsManager
+	senderTxOpts   *bind.TransactOpts
+	gasCfg         *config.GasConfig
+	txVerifier     *chain.TxVerifier
+}
+
+func newFastUpdaterContractClient(
+	ethClient *ethclient.Client,
+	address common.Address,
+	systemsManager *system.FlareSystemsManager,
+	senderTxOpts *bind.TransactOpts,
+	gasCfg *config.GasConfig,
+) (*fastUpdaterContractClientImpl, error) {
+	fastUpdater, err := fastupdater.NewFastUpdater(address, ethClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating fast updater contract")
+	}
+	return &fastUpdaterContractClientImpl{
+		ethClient:      ethClient,
+		address:        address,
+		fastUpdater:    fastUpdater,
+		systemsManager: systemsManager,
+		senderTxOpts:   senderTxOpts,
+		gasCfg:         gasCfg,
+		txVerifier:     chain.NewTxVerifier(ethClient),
+	}, nil
+}
+
+func (c *fastUpdaterContractClientImpl) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.ethClient.BlockNumber(ctx)
+}
+
+func (c *fastUpdaterContractClientImpl) CurrentRewardEpochId(ctx context.Context) (int64, error) {
+	id, err := c.fastUpdater.CurrentRewardEpochId(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, errors.Wrap(err, "error fetching current reward epoch id")
+	}
+	return id.Int64(), nil
+}
+
+func (c *fastUpdaterContractClientImpl) Seed(ctx context.Context, rewardEpochId int64) (*big.Int, error) {
+	seed, err := c.systemsManager.GetSeed(&bind.CallOpts{Context: ctx}, big.NewInt(rewardEpochId))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching seed of reward epoch %d", rewardEpochId)
+	}
+	return seed, nil
+}
+
+func (c *fastUpdaterContractClientImpl) SortitionWeight(ctx context.Context, signingPolicyAddress common.Address) (uint64, error) {
+	weight, err := c.fastUpdater.CurrentSortitionWeight(&bind.CallOpts{Context: ctx}, signingPolicyAddress)
+	if err != nil {
+		return 0, errors.Wrap(err, "error fetching sortition weight")
+	}
+	if !weight.IsUint64() {
+		return 0, errors.Errorf("invalid sortition weight %v", weight)
+	}
+	return weight.Uint64(), nil
+}
+
+func (c *fastUpdaterContractClientImpl) BlockScoreCutoff(ctx context.Context, blockNumber uint64) (*big.Int, error) {
+	cutoff, err := c.fastUpdater.BlockScoreCutoff(&bind.CallOpts{Context: ctx}, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching score cutoff of block %d", blockNumber)
+	}
+	return cutoff, nil
+}
+
+func (c *fastUpdaterContractClientImpl) SubmissionWindow(ctx context.Context) (uint64, error) {
+	window, err := c.fastUpdater.SubmissionWindow(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return 0, errors.Wrap(err, "error fetching submission window")
+	}
+	return uint64(window), nil
+}
+
+func (c *fastUpdaterContractClientImpl) SubmitUpdates(
+	ctx context.Context, updates *fastupdater.IFastUpdaterFastUpdates,
+) (func() error, error) {
+	fees, err := chain.GetTxFees(c.gasCfg, c.ethClient)
+	if err != nil {
+		return nil, errors.Wrap(err, "error obtaining gas price")
+	}
+
+	opts := *c.senderTxOpts
+	opts.Context = ctx
+	tx, err := chain.TransactWithNonce(c.ethClient, &opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		if opts.GasLimit == 0 {
+			gasLimit, err := chain.ContractGasLimit(
+				ctx, c.ethClient, c.gasCfg, config.RetryOpSubmitUpdates,
+				opts.From, c.address, fastupdater.FastUpdaterMetaData, "submitUpdates", *updates,
+			)
+			if err != nil {
+				return nil, err
+			}
+			opts.GasLimit = gasLimit
+		}
+		fees.Apply(opts)
+		return c.fastUpdater.SubmitUpdates(opts, *updates)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		_, err := c.txVerifier.WaitUntilMinedWithEscalation(
+			opts.From, tx, opts.Signer, c.gasCfg, config.RetryOpSubmitUpdates, chain.DefaultTxTimeout)
+		return err
+	}, nil
+}
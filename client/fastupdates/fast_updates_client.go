@@ -0,0 +1,319 @@
+package fastupdates
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	clientContext "flare-tlc/client/context"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/fastupdater"
+	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/sortition"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const defaultPollInterval = time.Second
+
+// FastUpdatesClient submits fast updates of the feed values. For each new block it
+// computes the sortition credentials of the replicates (up to the sortition weight
+// of the signing policy address) and submits the deltas of the feed value provider
+// for each replicate with a score below the block score cutoff, as long as the
+// block is in the submission window.
+type FastUpdatesClient struct {
+	contractClient fastUpdaterContractClient
+	provider       deltasProvider
+
+	sortitionKey *sortition.Key
+	signer       credentials.Signer // signing policy key, may be rotated
+
+	// signs the updates, signing policy key of the current reward epoch
+	epochSigner    credentials.Signer
+	signingAddress common.Address
+
+	pollInterval time.Duration
+	clock        utils.Clock
+
+	// sortition data of the current reward epoch
+	rewardEpochId int64
+	seed          *big.Int
+	weight        uint64
+
+	// last processed block
+	lastBlock uint64
+
+	// pending submitUpdates txs
+	pending sync.WaitGroup
+}
+
+func NewFastUpdatesClient(ctx clientContext.ClientContext) (*FastUpdatesClient, error) {
+	cfg := ctx.Config()
+
+	if !cfg.Clients.EnabledFastUpdates {
+		return nil, nil
+	}
+	if cfg.ContractAddresses.FastUpdater == (common.Address{}) {
+		return nil, errors.New("contract_addresses.fast_updater is required for fast updates")
+	}
+
+	sortitionKey, err := globalConfig.SortitionKeyFromConfig(
+		cfg.Credentials.SortitionPrivateKeyFile, cfg.Credentials.SortitionPrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading sortition key")
+	}
+	signer, err := ctx.SigningPolicySigner()
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating signer")
+	}
+	// submitUpdates txs are sent from the protocol submit account
+	senderSigner, err := globalConfig.SignerFromConfig(&cfg.Credentials.ProtocolManagerSubmitSigner,
+		cfg.Credentials.ProtocolManagerSubmitPrivateKeyFile, cfg.Credentials.ProtocolManagerSubmitPrivateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating submit signer")
+	}
+
+	chainCfg := cfg.ChainConfig()
+	cl, err := ctx.EthClient()
+	if err != nil {
+		return nil, err
+	}
+
+	systemsManager, err := system.NewFlareSystemsManager(cfg.ContractAddresses.SystemsManager, cl)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating system manager contract")
+	}
+	contractClient, err := newFastUpdaterContractClient(cl, cfg.ContractAddresses.FastUpdater, systemsManager,
+		credentials.TransactOptsFromSigner(senderSigner, chainCfg.ChainID), &cfg.SubmitGas)
+	if err != nil {
+		return nil, err
+	}
+
+	return newFastUpdatesClient(contractClient, newHttpDeltasProvider(&cfg.FastUpdates, config.FastUpdatesXApiKey()),
+		sortitionKey, signer, &cfg.FastUpdates), nil
+}
+
+func newFastUpdatesClient(
+	contractClient fastUpdaterContractClient,
+	provider deltasProvider,
+	sortitionKey *sortition.Key,
+	signer credentials.Signer,
+	cfg *config.FastUpdatesConfig,
+) *FastUpdatesClient {
+	pollInterval := cfg.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+	return &FastUpdatesClient{
+		contractClient: contractClient,
+		provider:       provider,
+		sortitionKey:   sortitionKey,
+		signer:         signer,
+		epochSigner:    signer,
+		signingAddress: signer.Address(),
+		pollInterval:   pollInterval,
+		clock:          utils.RealClock,
+		rewardEpochId:  -1,
+	}
+}
+
+func (c *FastUpdatesClient) Run(ctx context.Context) error {
+	defer c.pending.Wait()
+
+	logger.Info("Starting fast updates for signing policy address %s", c.signingAddress.Hex())
+
+	ticker := c.clock.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			break
+
+		case <-ctx.Done():
+			logger.Info("Fast updates client stopped")
+			return ctx.Err()
+		}
+
+		if err := c.processNewBlocks(ctx); err != nil {
+			// retried on the next tick
+			logger.Warn("Error processing fast updates: %v", err)
+		}
+	}
+}
+
+// Processes the blocks after the last processed block that are still in the
+// submission window
+func (c *FastUpdatesClient) processNewBlocks(ctx context.Context) error {
+	current, err := c.contractClient.BlockNumber(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error fetching block number")
+	}
+	if current <= c.lastBlock {
+		return nil
+	}
+	window, err := c.contractClient.SubmissionWindow(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.updateRewardEpoch(ctx); err != nil {
+		return err
+	}
+
+	// updates for a block are accepted while current < block + window
+	first := c.lastBlock + 1
+	if current+1 > window && first < current+1-window {
+		first = current + 1 - window
+	}
+	for block := first; block <= current; block++ {
+		if err := c.processBlock(ctx, block); err != nil {
+			return err
+		}
+		c.lastBlock = block
+	}
+	return nil
+}
+
+// Fetches the seed and the sortition weight when a new reward epoch starts
+func (c *FastUpdatesClient) updateRewardEpoch(ctx context.Context) error {
+	rewardEpochId, err := c.contractClient.CurrentRewardEpochId(ctx)
+	if err != nil {
+		return err
+	}
+	if rewardEpochId == c.rewardEpochId {
+		return nil
+	}
+	seed, err := c.contractClient.Seed(ctx, rewardEpochId)
+	if err != nil {
+		return err
+	}
+	epochSigner := credentials.SignerForRewardEpoch(c.signer, rewardEpochId)
+	weight, err := c.contractClient.SortitionWeight(ctx, epochSigner.Address())
+	if err != nil {
+		return err
+	}
+	logger.Info("Sortition weight of %s for reward epoch %d is %d", epochSigner.Address().Hex(), rewardEpochId, weight)
+	c.rewardEpochId = rewardEpochId
+	c.epochSigner = epochSigner
+	c.signingAddress = epochSigner.Address()
+	c.seed = seed
+	c.weight = weight
+	return nil
+}
+
+// Submits updates for each eligible replicate of the block
+func (c *FastUpdatesClient) processBlock(ctx context.Context, block uint64) error {
+	if c.weight == 0 {
+		return nil
+	}
+	cutoff, err := c.contractClient.BlockScoreCutoff(ctx, block)
+	if err != nil {
+		return err
+	}
+	blockNumber := new(big.Int).SetUint64(block)
+	proofs, replicates, err := sortition.Eligible(c.sortitionKey, c.seed, blockNumber, c.weight, cutoff)
+	if err != nil {
+		return err
+	}
+	if len(proofs) == 0 {
+		return nil
+	}
+	eligibleReplicates.Add(float64(len(proofs)))
+	if chain.Standby() {
+		// the leader instance submits the updates
+		submittedUpdates.WithLabelValues("standby").Inc()
+		return nil
+	}
+
+	deltas, err := c.provider.Deltas(ctx, block)
+	if err != nil {
+		// the block is skipped, the deltas are outdated in the next block
+		logger.Warn("Error fetching deltas for block %d: %v", block, err)
+		submittedUpdates.WithLabelValues("no_data").Inc()
+		return nil
+	}
+	if len(deltas) == 0 {
+		logger.Debug("No deltas for block %d", block)
+		submittedUpdates.WithLabelValues("no_data").Inc()
+		return nil
+	}
+
+	for i, proof := range proofs {
+		if err := c.submitUpdates(ctx, blockNumber, replicates[i], proof, deltas); err != nil {
+			logger.Warn("Error submitting updates for block %d, replicate %d: %v", block, replicates[i], err)
+			submittedUpdates.WithLabelValues("error").Inc()
+		}
+	}
+	return nil
+}
+
+// Sends the updates of the replicate, the tx is awaited in the background
+func (c *FastUpdatesClient) submitUpdates(
+	ctx context.Context, block *big.Int, replicate uint64, proof *sortition.Proof, deltas []byte,
+) error {
+	updates, err := c.fastUpdates(block, new(big.Int).SetUint64(replicate), proof, deltas)
+	if err != nil {
+		return err
+	}
+	wait, err := c.contractClient.SubmitUpdates(ctx, updates)
+	if err != nil {
+		return err
+	}
+	c.pending.Add(1)
+	go func() {
+		defer c.pending.Done()
+		if err := wait(); err != nil {
+			logger.Warn("Updates for block %v, replicate %d failed: %v", block, replicate, err)
+			submittedUpdates.WithLabelValues("error").Inc()
+			return
+		}
+		logger.Debug("Submitted updates for block %v, replicate %d", block, replicate)
+		submittedUpdates.WithLabelValues("ok").Inc()
+	}()
+	return nil
+}
+
+func (c *FastUpdatesClient) fastUpdates(
+	block *big.Int, replicate *big.Int, proof *sortition.Proof, deltas []byte,
+) (*fastupdater.IFastUpdaterFastUpdates, error) {
+	gammaX, gammaY := sortition.PointXY(proof.Gamma)
+	credential := fastupdater.SortitionCredential{
+		Replicate: replicate,
+		Gamma:     fastupdater.G1Point{X: gammaX, Y: gammaY},
+		C:         proof.C,
+		S:         proof.S,
+	}
+	signature, err := c.epochSigner.SignText(updatesHash(block, &credential, deltas))
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing updates")
+	}
+	return &fastupdater.IFastUpdaterFastUpdates{
+		SortitionBlock:      block,
+		SortitionCredential: credential,
+		Deltas:              deltas,
+		Signature: fastupdater.IFastUpdaterSignature{
+			R: [32]byte(signature[0:32]),
+			S: [32]byte(signature[32:64]),
+			V: signature[64] + 27,
+		},
+	}, nil
+}
+
+// keccak256(sortitionBlock || replicate || gamma.x || gamma.y || c || s || deltas),
+// each number as a 32 byte word
+func updatesHash(block *big.Int, credential *fastupdater.SortitionCredential, deltas []byte) []byte {
+	return crypto.Keccak256(
+		common.LeftPadBytes(block.Bytes(), 32),
+		common.LeftPadBytes(credential.Replicate.Bytes(), 32),
+		common.LeftPadBytes(credential.Gamma.X.Bytes(), 32),
+		common.LeftPadBytes(credential.Gamma.Y.Bytes(), 32),
+		common.LeftPadBytes(credential.C.Bytes(), 32),
+		common.LeftPadBytes(credential.S.Bytes(), 32),
+		deltas,
+	)
+}
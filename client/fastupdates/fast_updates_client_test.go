@@ -0,0 +1,165 @@
+package fastupdates
+
+import (
+	"context"
+	"crypto/rand"
+	"flare-tlc/client/config"
+	"flare-tlc/utils/contracts/fastupdater"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/sortition"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/stretchr/testify/require"
+)
+
+type testFastUpdater struct {
+	blockNumber uint64
+	window      uint64
+	weight      uint64
+	cutoff      *big.Int
+
+	mu        sync.Mutex
+	submitted []*fastupdater.IFastUpdaterFastUpdates
+}
+
+func (c *testFastUpdater) BlockNumber(context.Context) (uint64, error) {
+	return c.blockNumber, nil
+}
+
+func (c *testFastUpdater) CurrentRewardEpochId(context.Context) (int64, error) {
+	return 1, nil
+}
+
+func (c *testFastUpdater) Seed(context.Context, int64) (*big.Int, error) {
+	return big.NewInt(1234), nil
+}
+
+func (c *testFastUpdater) SortitionWeight(context.Context, common.Address) (uint64, error) {
+	return c.weight, nil
+}
+
+func (c *testFastUpdater) BlockScoreCutoff(context.Context, uint64) (*big.Int, error) {
+	return c.cutoff, nil
+}
+
+func (c *testFastUpdater) SubmissionWindow(context.Context) (uint64, error) {
+	return c.window, nil
+}
+
+func (c *testFastUpdater) SubmitUpdates(_ context.Context, updates *fastupdater.IFastUpdaterFastUpdates) (func() error, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.submitted = append(c.submitted, updates)
+	return func() error { return nil }, nil
+}
+
+type testDeltasProvider struct {
+	deltas []byte
+}
+
+func (p *testDeltasProvider) Deltas(context.Context, uint64) ([]byte, error) {
+	return p.deltas, nil
+}
+
+func TestFastUpdatesSubmission(t *testing.T) {
+	key, err := sortition.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	pk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := credentials.NewPrivateKeySigner(pk)
+
+	// every replicate is eligible
+	contract := &testFastUpdater{blockNumber: 10, window: 4, weight: 2, cutoff: bn256.P}
+	provider := &testDeltasProvider{deltas: []byte{0x12, 0x34}}
+	c := newFastUpdatesClient(contract, provider, key, signer, &config.FastUpdatesConfig{})
+
+	ctx := context.Background()
+	require.NoError(t, c.processNewBlocks(ctx))
+	c.pending.Wait()
+
+	// blocks 7 to 10 are in the submission window
+	require.Len(t, contract.submitted, 8)
+	require.EqualValues(t, 7, contract.submitted[0].SortitionBlock.Int64())
+	require.EqualValues(t, 10, contract.submitted[7].SortitionBlock.Int64())
+	require.EqualValues(t, 10, c.lastBlock)
+
+	for _, updates := range contract.submitted {
+		credential := updates.SortitionCredential
+		proof := &sortition.Proof{
+			Gamma: pointFromXY(t, credential.Gamma.X, credential.Gamma.Y),
+			C:     credential.C,
+			S:     credential.S,
+		}
+		require.True(t, sortition.VerifyProof(key.Public, big.NewInt(1234), updates.SortitionBlock, credential.Replicate, proof))
+		require.Equal(t, provider.deltas, updates.Deltas)
+
+		signature := append(append(updates.Signature.R[:], updates.Signature.S[:]...), updates.Signature.V-27)
+		hash := accounts.TextHash(updatesHash(updates.SortitionBlock, &credential, updates.Deltas))
+		recovered, err := crypto.SigToPub(hash, signature)
+		require.NoError(t, err)
+		require.Equal(t, signer.Address(), crypto.PubkeyToAddress(*recovered))
+	}
+
+	// only new blocks are processed
+	contract.submitted = nil
+	contract.blockNumber = 11
+	require.NoError(t, c.processNewBlocks(ctx))
+	require.Len(t, contract.submitted, 2)
+	require.EqualValues(t, 11, contract.submitted[0].SortitionBlock.Int64())
+
+	// no updates without deltas or eligible replicates
+	contract.submitted = nil
+	contract.blockNumber = 12
+	provider.deltas = nil
+	require.NoError(t, c.processNewBlocks(ctx))
+	contract.blockNumber = 13
+	provider.deltas = []byte{0x12}
+	contract.cutoff = big.NewInt(0)
+	require.NoError(t, c.processNewBlocks(ctx))
+	require.Empty(t, contract.submitted)
+	require.EqualValues(t, 13, c.lastBlock)
+}
+
+func TestHttpDeltasProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/deltas/10":
+			_, _ = w.Write([]byte(`{"status": "OK", "data": "0x1234"}`))
+		default:
+			_, _ = w.Write([]byte(`{"status": "NOT_AVAILABLE"}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := newHttpDeltasProvider(&config.FastUpdatesConfig{ApiEndpoint: server.URL + "/deltas/"}, "key")
+	deltas, err := provider.Deltas(context.Background(), 10)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x12, 0x34}, deltas)
+
+	deltas, err = provider.Deltas(context.Background(), 11)
+	require.NoError(t, err)
+	require.Nil(t, deltas)
+
+	provider.xApiKey = ""
+	_, err = provider.Deltas(context.Background(), 10)
+	require.Error(t, err)
+}
+
+func pointFromXY(t *testing.T, x, y *big.Int) *bn256.G1 {
+	point := new(bn256.G1)
+	_, err := point.Unmarshal(append(common.LeftPadBytes(x.Bytes(), 32), common.LeftPadBytes(y.Bytes(), 32)...))
+	require.NoError(t, err)
+	return point
+}
@@ -0,0 +1,5 @@
+package fastupdates
+
+import flarelogger "flare-tlc/logger"
+
+var logger = flarelogger.Module("fast_updates")
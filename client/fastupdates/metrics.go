@@ -0,0 +1,23 @@
+package fastupdates
+
+import (
+	"flare-tlc/utils/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	eligibleReplicates = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "fast_updates",
+		Name:      "eligible_replicates_total",
+		Help:      "Replicates eligible to submit fast updates",
+	})
+	submittedUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "fast_updates",
+		Name:      "submissions_total",
+		Help:      "Fast updates submissions by result",
+	}, []string{"result"})
+)
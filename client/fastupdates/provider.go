@@ -0,0 +1,84 @@
+package fastupdates
+
+import (
+	"context"
+	"encoding/json"
+	"flare-tlc/client/config"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+const defaultProviderTimeout = time.Second
+
+type deltasProvider interface {
+	// Deltas of the feed values to submit for the sortition block, nil if the
+	// provider has no updates for the block
+	Deltas(ctx context.Context, blockNumber uint64) ([]byte, error)
+}
+
+// Feed value provider API: GET <api_endpoint>/<blockNumber> returns
+// {"status": "OK", "data": "0x..."}
+type httpDeltasProvider struct {
+	apiEndpoint string
+	xApiKey     string
+	client      http.Client
+}
+
+type deltasResponse struct {
+	Status string `json:"status"`
+	Data   string `json:"data"`
+}
+
+func newHttpDeltasProvider(cfg *config.FastUpdatesConfig, xApiKey string) *httpDeltasProvider {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultProviderTimeout
+	}
+	return &httpDeltasProvider{
+		apiEndpoint: strings.TrimSuffix(cfg.ApiEndpoint, "/"),
+		xApiKey:     xApiKey,
+		client:      http.Client{Timeout: timeout},
+	}
+}
+
+func (p *httpDeltasProvider) Deltas(ctx context.Context, blockNumber uint64) ([]byte, error) {
+	url := fmt.Sprintf("%s/%d", p.apiEndpoint, blockNumber)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating feed value provider request")
+	}
+	if len(p.xApiKey) > 0 {
+		req.Header.Set("X-API-KEY", p.xApiKey)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error calling feed value provider")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed value provider returned http status %v", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading feed value provider response")
+	}
+	var response deltasResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.Wrap(err, "cannot parse feed value provider response body")
+	}
+	if response.Status != "OK" {
+		return nil, nil
+	}
+	deltas, err := hexutil.Decode(response.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode deltas of feed value provider response")
+	}
+	return deltas, nil
+}
@@ -0,0 +1,90 @@
+package finalizer
+
+import (
+	"context"
+	clientContext "flare-tlc/client/context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Finalize the voting round of the protocol once, run by the client subcommand.
+// Signing policies and signatures are read from the indexer database and the
+// relay tx is sent regardless of the finalizer selection.
+func FinalizeRound(ctx context.Context, clientCtx clientContext.ClientContext, votingRoundId uint32, protocolId byte) error {
+	c, err := newFinalizerClient(clientCtx)
+	if err != nil {
+		return err
+	}
+	c.finalizerContext.startingVotingRound = votingRoundId
+	if _, err := c.fetchExistingSigningPolicies(ctx, c.clock.Now().Add(-c.finalizerContext.startTimeOffset)); err != nil {
+		return err
+	}
+
+	// signatures for the voting round are submitted in the next voting round
+	from := c.finalizerContext.votingEpoch.StartTime(int64(votingRoundId) + 1)
+	if err := c.submissionClient.FetchSubmissions(c.db, from.Add(-time.Second), c.clock.Now(), c); err != nil {
+		return err
+	}
+
+	sp, _ := c.signingPolicyData(protocolId, votingRoundId)
+	if sp == nil {
+		return fmt.Errorf("no signing policy found for voting round %d", votingRoundId)
+	}
+	hashes := c.submissionStorage.ThresholdReached(votingRoundId, protocolId)
+	if len(hashes) == 0 {
+		return fmt.Errorf("no message reached the threshold for voting round %d and protocol %d", votingRoundId, protocolId)
+	}
+	for _, hash := range hashes {
+		item := &queueItem{
+			seed:          sp.seed,
+			votingRoundId: votingRoundId,
+			protocolId:    protocolId,
+			messageHash:   hash,
+		}
+		if !c.queueProcessor.processItem(ctx, item, false) {
+			return fmt.Errorf("relaying item %v failed", item)
+		}
+	}
+	return nil
+}
+
+// FinalizationReport returns the statistics of the messages relayed between from and
+// to, read from the indexer database. The gas used by the relay txs is fetched from
+// their receipts if receipts is set.
+func FinalizationReport(
+	ctx context.Context, clientCtx clientContext.ClientContext, from, to time.Time, receipts bool,
+) ([]FinalizationStats, error) {
+	if clientCtx.DB() == nil {
+		return nil, errors.New("the finalization report reads the indexer database, not available with the rpc listener source")
+	}
+	c, err := newFinalizerClient(clientCtx)
+	if err != nil {
+		return nil, err
+	}
+	db := NewFinalizerDB(clientCtx.DB(), clientCtx.Config().Listeners.Fetch.FetchOptions())
+	stats, err := c.relayClient.finalizationStats(db, c.finalizerContext.votingEpoch, from, to, c.relayClient.signer.Address())
+	if err != nil {
+		return nil, err
+	}
+	if !receipts {
+		return stats, nil
+	}
+	ethClient, err := clientCtx.EthClient()
+	if err != nil {
+		return nil, err
+	}
+	for i := range stats {
+		if len(stats[i].TxHash) == 0 {
+			continue
+		}
+		receipt, err := ethClient.TransactionReceipt(ctx, common.HexToHash(stats[i].TxHash))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot fetch the receipt of relay tx %s", stats[i].TxHash)
+		}
+		stats[i].GasUsed = receipt.GasUsed
+	}
+	return stats, nil
+}
@@ -0,0 +1,240 @@
+package finalizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/signingpolicy"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Calldata of relay, submit1, submit2, submit3 or submitSignatures decoded by
+// DecodeCalldata, printed as JSON by the decode subcommand
+type DecodedCalldata struct {
+	Method           string                `json:"method"`
+	SigningPolicy    *DecodedSigningPolicy `json:"signing_policy,omitempty"`     // relay
+	NewSigningPolicy *DecodedSigningPolicy `json:"new_signing_policy,omitempty"` // relay of protocol 0
+	Message          *DecodedMessage       `json:"message,omitempty"`            // relay
+	Signatures       []DecodedSignature    `json:"signatures,omitempty"`         // relay
+	Items            []DecodedItem         `json:"items,omitempty"`              // submit methods
+}
+
+type DecodedSigningPolicy struct {
+	RewardEpochId      uint32         `json:"reward_epoch_id"`
+	StartVotingRoundId uint32         `json:"start_voting_round_id"`
+	Threshold          uint16         `json:"threshold"`
+	Seed               string         `json:"seed"`
+	Hash               string         `json:"hash"`
+	Voters             []DecodedVoter `json:"voters"`
+}
+
+type DecodedVoter struct {
+	Address string `json:"address"`
+	Weight  uint16 `json:"weight"`
+}
+
+type DecodedMessage struct {
+	ProtocolId         byte   `json:"protocol_id"`
+	VotingRoundId      uint32 `json:"voting_round_id"`
+	RandomQualityScore bool   `json:"random_quality_score"`
+	MerkleRoot         string `json:"merkle_root"`
+	Hash               string `json:"hash"` // signed hash of the message
+}
+
+type DecodedSignature struct {
+	VoterIndex int    `json:"voter_index"`
+	Voter      string `json:"voter,omitempty"` // address of the voter at the index, empty if out of range
+	Signer     string `json:"signer,omitempty"`
+	Signature  string `json:"signature"` // [V || R || S]
+	Error      string `json:"error,omitempty"`
+}
+
+// Item of the submit calldata, the signed payload fields are set for submitSignatures
+type DecodedItem struct {
+	ProtocolId     byte            `json:"protocol_id"`
+	VotingRoundId  uint32          `json:"voting_round_id"`
+	Payload        string          `json:"payload,omitempty"` // submit1, submit2, submit3
+	Type           *byte           `json:"type,omitempty"`
+	Message        *DecodedMessage `json:"message,omitempty"`
+	Signer         string          `json:"signer,omitempty"`
+	Signature      string          `json:"signature,omitempty"` // [V || R || S]
+	AdditionalData string          `json:"additional_data,omitempty"`
+}
+
+// DecodeCalldata decodes the calldata of the Relay and Submission contract methods
+// with the parsers of the finalizer, the method is determined by the selector
+func DecodeCalldata(calldata []byte) (*DecodedCalldata, error) {
+	if len(calldata) < selectorLength {
+		return nil, errCalldataTooShort
+	}
+	relayABI, err := relay.RelayMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	submissionABI, err := submission.SubmissionMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	selector := calldata[:selectorLength]
+	if bytes.Equal(selector, relayABI.Methods["relay"].ID) {
+		return decodeRelayCalldata(calldata[selectorLength:])
+	}
+	if bytes.Equal(selector, submissionABI.Methods["submitSignatures"].ID) {
+		items, err := DecodeSubmitterPayload(calldata)
+		if err != nil {
+			return nil, err
+		}
+		decoded := &DecodedCalldata{Method: "submitSignatures", Items: make([]DecodedItem, len(items))}
+		for i, item := range items {
+			typeId := item.payload.typeId
+			decoded.Items[i] = DecodedItem{
+				ProtocolId:     item.protocolId,
+				VotingRoundId:  item.votingRoundId,
+				Type:           &typeId,
+				Message:        decodedMessage(item.payload.message, item.payload.rawMessage),
+				Signer:         item.payload.signer.Hex(),
+				Signature:      hexutil.Encode(item.payload.signature),
+				AdditionalData: encodeOptional(item.payload.additionalData),
+			}
+		}
+		return decoded, nil
+	}
+	for _, method := range []string{"submit1", "submit2", "submit3"} {
+		if bytes.Equal(selector, submissionABI.Methods[method].ID) {
+			items, err := decodeSubmitItems(calldata[selectorLength:])
+			if err != nil {
+				return nil, err
+			}
+			return &DecodedCalldata{Method: method, Items: items}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown selector %s", hexutil.Encode(selector))
+}
+
+// Decodes the relay calldata without the selector: the signing policy, the message
+// or, for protocol 0, the new signing policy, and the signatures
+func decodeRelayCalldata(data []byte) (*DecodedCalldata, error) {
+	policy, policyLength, err := signingpolicy.DecodePrefix(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing policy: %w", err)
+	}
+	decoded := &DecodedCalldata{Method: "relay", SigningPolicy: decodedSigningPolicy(policy, data[:policyLength])}
+	data = data[policyLength:]
+
+	var signedHash []byte
+	if len(data) > 0 && data[0] == 0 {
+		newPolicy, newPolicyLength, err := signingpolicy.DecodePrefix(data[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid new signing policy: %w", err)
+		}
+		encoded := data[1 : 1+newPolicyLength]
+		decoded.NewSigningPolicy = decodedSigningPolicy(newPolicy, encoded)
+		hash := signingpolicy.Hash(encoded)
+		signedHash = accounts.TextHash(hash[:])
+		data = data[1+newPolicyLength:]
+	} else {
+		if len(data) < relayMessageLength {
+			return nil, fmt.Errorf("%d bytes, message is %d bytes", len(data), relayMessageLength)
+		}
+		message, err := decodeSubmittedPayload(data[:relayMessageLength])
+		if err != nil {
+			return nil, err
+		}
+		decoded.Message = decodedMessage(message, data[:relayMessageLength])
+		signedHash = accounts.TextHash(crypto.Keccak256(data[:relayMessageLength]))
+		data = data[relayMessageLength:]
+	}
+
+	if len(data) < relaySignaturesHeader {
+		return nil, fmt.Errorf("missing number of signatures")
+	}
+	count := int(binary.BigEndian.Uint16(data[0:2]))
+	data = data[relaySignaturesHeader:]
+	if len(data) != count*relaySignatureLength {
+		return nil, fmt.Errorf("%d bytes, %d signatures need %d bytes", len(data), count, count*relaySignatureLength)
+	}
+	decoded.Signatures = make([]DecodedSignature, count)
+	for i := 0; i < count; i++ {
+		signature := data[i*relaySignatureLength : i*relaySignatureLength+65]
+		index := int(binary.BigEndian.Uint16(data[i*relaySignatureLength+65 : (i+1)*relaySignatureLength]))
+		result := DecodedSignature{VoterIndex: index, Signature: hexutil.Encode(signature)}
+		if index < len(policy.Voters) {
+			result.Voter = policy.Voters[index].Hex()
+		}
+		rsv := transformSignature(signature)
+		if pk, err := crypto.SigToPub(signedHash, rsv[:]); err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Signer = crypto.PubkeyToAddress(*pk).Hex()
+		}
+		decoded.Signatures[i] = result
+	}
+	return decoded, nil
+}
+
+// Decodes the items of the submit1, submit2 and submit3 calldata without the
+// selector, the payloads are protocol specific and not decoded
+func decodeSubmitItems(data []byte) ([]DecodedItem, error) {
+	var items []DecodedItem
+	for i := 0; i < len(data); {
+		if isZeroPadding(data[i:]) {
+			break
+		}
+		offset := i + selectorLength
+		if len(data)-i < itemHeaderLength {
+			return nil, &payloadError{offset: offset, err: fmt.Errorf("%w: %d of %d bytes", errTruncatedItemHeader, len(data)-i, itemHeaderLength)}
+		}
+		item := DecodedItem{
+			ProtocolId:    data[i],
+			VotingRoundId: binary.BigEndian.Uint32(data[i+1 : i+5]),
+		}
+		payloadLength := int(binary.BigEndian.Uint16(data[i+5 : i+7]))
+		i += itemHeaderLength
+		if len(data)-i < payloadLength {
+			return nil, &payloadError{offset: offset, err: fmt.Errorf("%w: %d bytes, %d left", errPayloadLengthExceeded, payloadLength, len(data)-i)}
+		}
+		item.Payload = hexutil.Encode(data[i : i+payloadLength])
+		i += payloadLength
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func decodedSigningPolicy(policy *signingpolicy.SigningPolicy, encoded []byte) *DecodedSigningPolicy {
+	decoded := &DecodedSigningPolicy{
+		RewardEpochId:      policy.RewardEpochId,
+		StartVotingRoundId: policy.StartVotingRoundId,
+		Threshold:          policy.Threshold,
+		Seed:               hexutil.Encode(common.LeftPadBytes(policy.Seed.Bytes(), 32)),
+		Hash:               signingpolicy.Hash(encoded).Hex(),
+		Voters:             make([]DecodedVoter, len(policy.Voters)),
+	}
+	for i, voter := range policy.Voters {
+		decoded.Voters[i] = DecodedVoter{Address: voter.Hex(), Weight: policy.Weights[i]}
+	}
+	return decoded
+}
+
+func decodedMessage(message *submittedPayload, rawMessage []byte) *DecodedMessage {
+	return &DecodedMessage{
+		ProtocolId:         message.protocolId,
+		VotingRoundId:      message.votingRoundId,
+		RandomQualityScore: message.randomQualityScore,
+		MerkleRoot:         hexutil.Encode(message.merkleRoot),
+		Hash:               common.BytesToHash(accounts.TextHash(crypto.Keccak256(rawMessage))).Hex(),
+	}
+}
+
+func encodeOptional(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	return hexutil.Encode(data)
+}
@@ -0,0 +1,59 @@
+package finalizer
+
+import (
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/signingpolicy"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCalldata(t *testing.T) {
+	relayABI, err := relay.RelayMetaData.GetAbi()
+	require.NoError(t, err)
+	submissionABI, err := submission.SubmissionMetaData.GetAbi()
+	require.NoError(t, err)
+
+	f := newRelayValidationFixture(t)
+	f.selector = relayABI.Methods["relay"].ID
+	decoded, err := DecodeCalldata(f.calldata(t, 0, 2))
+	require.NoError(t, err)
+	require.Equal(t, "relay", decoded.Method)
+	require.EqualValues(t, 3, decoded.SigningPolicy.RewardEpochId)
+	require.Len(t, decoded.SigningPolicy.Voters, 3)
+	require.Equal(t, "0x0000000000000000000000000000000000000000000000000000000000000007", decoded.SigningPolicy.Seed)
+	require.Equal(t, signingpolicy.Hash(f.policy.rawBytes).Hex(), decoded.SigningPolicy.Hash)
+	require.EqualValues(t, 120, decoded.Message.VotingRoundId)
+	require.Len(t, decoded.Signatures, 2)
+	for i, index := range []int{0, 2} {
+		require.Equal(t, index, decoded.Signatures[i].VoterIndex)
+		require.Equal(t, crypto.PubkeyToAddress(f.keys[index].PublicKey).Hex(), decoded.Signatures[i].Signer)
+		require.Equal(t, decoded.Signatures[i].Voter, decoded.Signatures[i].Signer)
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	calldata, err := encodeSubmitterPayload(privateKey)
+	require.NoError(t, err)
+	copy(calldata, submissionABI.Methods["submitSignatures"].ID)
+	decoded, err = DecodeCalldata(calldata)
+	require.NoError(t, err)
+	require.Equal(t, "submitSignatures", decoded.Method)
+	require.Len(t, decoded.Items, 1)
+	require.Equal(t, crypto.PubkeyToAddress(privateKey.PublicKey).Hex(), decoded.Items[0].Signer)
+	require.True(t, decoded.Items[0].Message.RandomQualityScore)
+
+	calldata = append(append([]byte{}, submissionABI.Methods["submit2"].ID...), 100, 0, 0, 0, 5, 0, 2, 0xab, 0xcd, 0, 0)
+	decoded, err = DecodeCalldata(calldata)
+	require.NoError(t, err)
+	require.Equal(t, &DecodedCalldata{Method: "submit2", Items: []DecodedItem{
+		{ProtocolId: 100, VotingRoundId: 5, Payload: "0xabcd"},
+	}}, decoded)
+
+	_, err = DecodeCalldata(append(append([]byte{}, submissionABI.Methods["submit1"].ID...), 100, 0, 0, 0, 5, 0, 3, 0xab))
+	require.ErrorIs(t, err, errPayloadLengthExceeded)
+	_, err = DecodeCalldata([]byte{1, 2, 3, 4})
+	require.ErrorContains(t, err, "unknown selector 0x01020304")
+}
@@ -0,0 +1,240 @@
+package finalizer
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flare-tlc/client/config"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	finalizationProviderInterval       = 10 * time.Second
+	defaultFinalizationProviderTimeout = 10 * time.Second
+)
+
+// Source of finalization data other than the locally collected submitSignatures
+// txs, e.g. a data availability service
+type finalizationProvider interface {
+	// Signed payloads of the messages of the protocol in the voting round, nil if
+	// the provider has no data for the round
+	FinalizationData(ctx context.Context, protocolId byte, votingRoundId uint32) ([]*signedPayload, error)
+}
+
+// Finalization data API: GET <api_endpoint>/<protocolId>/<votingRoundId> returns
+// {"status": "OK", "signatures": ["0x..."]}. Each signature is encoded as a
+// signature payload of submitSignatures, see decodeSignedPayload.
+type httpFinalizationProvider struct {
+	apiEndpoint string
+	xApiKey     string
+	client      http.Client
+}
+
+type finalizationDataResponse struct {
+	Status     string   `json:"status"`
+	Signatures []string `json:"signatures"`
+}
+
+func newHttpFinalizationProvider(cfg *config.FinalizationProviderConfig, xApiKey string) *httpFinalizationProvider {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultFinalizationProviderTimeout
+	}
+	return &httpFinalizationProvider{
+		apiEndpoint: strings.TrimSuffix(cfg.ApiEndpoint, "/"),
+		xApiKey:     xApiKey,
+		client:      http.Client{Timeout: timeout},
+	}
+}
+
+// Finalization providers of each protocol, in the configured order
+func newFinalizationProviders(cfgs []config.FinalizationProviderConfig) map[byte][]finalizationProvider {
+	if len(cfgs) == 0 {
+		return nil
+	}
+	providers := make(map[byte][]finalizationProvider)
+	for i := range cfgs {
+		provider := newHttpFinalizationProvider(&cfgs[i], config.FinalizationProviderXApiKey(i))
+		for _, protocolId := range cfgs[i].Protocols {
+			providers[protocolId] = append(providers[protocolId], provider)
+		}
+	}
+	return providers
+}
+
+func (p *httpFinalizationProvider) String() string {
+	return p.apiEndpoint
+}
+
+func (p *httpFinalizationProvider) FinalizationData(ctx context.Context, protocolId byte, votingRoundId uint32) ([]*signedPayload, error) {
+	url := fmt.Sprintf("%s/%d/%d", p.apiEndpoint, protocolId, votingRoundId)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating finalization provider request")
+	}
+	if len(p.xApiKey) > 0 {
+		req.Header.Set("X-API-KEY", p.xApiKey)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error calling finalization provider")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("finalization provider returned http status %v", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading finalization provider response")
+	}
+	var response finalizationDataResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errors.Wrap(err, "cannot parse finalization provider response body")
+	}
+	if response.Status != "OK" {
+		return nil, nil
+	}
+
+	payloads := make([]*signedPayload, 0, len(response.Signatures))
+	for i, signature := range response.Signatures {
+		data, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot decode signature %d of finalization provider response", i)
+		}
+		payload, err := decodeSignedPayload(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid signature %d of finalization provider response", i)
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, nil
+}
+
+// Queries the finalization providers for the last voting round of their protocols
+// once its grace period ended, unless the locally collected signatures reached
+// the threshold or the round was already relayed
+func (c *finalizerClient) runFinalizationProviders(ctx context.Context) error {
+	ticker := c.clock.NewTicker(finalizationProviderInterval)
+	defer ticker.Stop()
+
+	// last voting round fetched per protocol
+	fetched := make(map[byte]uint32)
+	for {
+		select {
+		case <-ticker.C():
+			break
+
+		case <-ctx.Done():
+			logger.Info("Finalization providers stopped")
+			return ctx.Err()
+		}
+
+		now := c.clock.Now()
+		currentEpoch := c.finalizerContext.votingEpoch.EpochIndex(now)
+		votingRoundId := uint32(currentEpoch - 1)
+		if votingRoundId < c.finalizerContext.startingVotingRound {
+			continue
+		}
+		for protocolId, providers := range c.finalizationProviders {
+			if fetched[protocolId] >= votingRoundId || !c.finalizerContext.protocolEnabled(protocolId) {
+				continue
+			}
+			gracePeriodEnd := c.finalizerContext.votingEpoch.StartTime(currentEpoch).Add(
+				c.finalizerContext.protocolSettings(protocolId).gracePeriodEndOffset)
+			if now.Before(gracePeriodEnd) {
+				continue
+			}
+			if err := c.fetchFinalizationData(ctx, protocolId, votingRoundId, providers); err != nil {
+				// retried on the next tick
+				logger.Warn("Error fetching finalization data of protocol %d for voting round %d: %v", protocolId, votingRoundId, err)
+				continue
+			}
+			fetched[protocolId] = votingRoundId
+		}
+	}
+}
+
+// Adds the signatures of the providers to the submission storage until the
+// threshold is reached. Returns an error if all providers failed.
+func (c *finalizerClient) fetchFinalizationData(
+	ctx context.Context, protocolId byte, votingRoundId uint32, providers []finalizationProvider,
+) error {
+	if len(c.submissionStorage.ThresholdReached(votingRoundId, protocolId)) > 0 {
+		return nil
+	}
+	relayed, err := c.relayClient.IsRelayed(protocolId, votingRoundId)
+	if err != nil {
+		return err
+	}
+	if relayed {
+		return nil
+	}
+	sp, threshold := c.signingPolicyData(protocolId, votingRoundId)
+	if sp == nil {
+		return fmt.Errorf("no signing policy found for voting round %d", votingRoundId)
+	}
+
+	protocolLabel := strconv.Itoa(int(protocolId))
+	failed := 0
+	for _, provider := range providers {
+		payloads, err := provider.FinalizationData(ctx, protocolId, votingRoundId)
+		if err != nil {
+			logger.Warn("Finalization provider %v failed for protocol %d, voting round %d: %v", provider, protocolId, votingRoundId, err)
+			finalizationProviderRequests.WithLabelValues(protocolLabel, "error").Inc()
+			failed++
+			continue
+		}
+		if c.addProvidedPayloads(protocolId, votingRoundId, payloads, sp, threshold) {
+			logger.Info("Threshold reached for protocol %d in voting round %d with signatures of finalization provider %v",
+				protocolId, votingRoundId, provider)
+			finalizationProviderRequests.WithLabelValues(protocolLabel, "queued").Inc()
+			return nil
+		}
+		finalizationProviderRequests.WithLabelValues(protocolLabel, "no_threshold").Inc()
+	}
+	if failed == len(providers) {
+		return errors.New("all finalization providers failed")
+	}
+	return nil
+}
+
+// Returns true if a message reached the threshold and was queued for finalization
+func (c *finalizerClient) addProvidedPayloads(
+	protocolId byte, votingRoundId uint32, payloads []*signedPayload, sp *signingPolicy, threshold uint16,
+) bool {
+	timestamp := c.clock.Now().Unix()
+	for _, payload := range payloads {
+		if payload.message.protocolId != protocolId || payload.message.votingRoundId != votingRoundId {
+			logger.Debug("Ignoring provided signature for protocol %d, voting round %d",
+				payload.message.protocolId, payload.message.votingRoundId)
+			continue
+		}
+		if reason := c.finalizerContext.verifyMessage(protocolId, votingRoundId, payload); reason != "" {
+			logger.Debug("Ignoring provided signature - malformed message: %s", reason)
+			malformedMessages.WithLabelValues(strconv.Itoa(int(protocolId)), reason).Inc()
+			continue
+		}
+		addResult, err := c.submissionStorage.Add(payload, sp, threshold, timestamp)
+		if err != nil {
+			logger.Debug("Ignoring provided signature: %v", err)
+			continue
+		}
+		if addResult.thresholdReached {
+			c.queueProcessor.Add(&submitterPayloadItem{
+				protocolId:    protocolId,
+				votingRoundId: votingRoundId,
+				payload:       payload,
+			}, sp.seed)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package finalizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinalizationProvider(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(testPrivateKeyHex)
+	require.NoError(t, err)
+	payload, err := encodeSignedPayload(privateKey, &signedPayload{
+		typeId: payloadTypeMessage,
+		message: &submittedPayload{
+			protocolId:    0x1,
+			votingRoundId: 1,
+			merkleRoot:    bytes.Repeat([]byte{0xff}, 32),
+		},
+	})
+	require.NoError(t, err)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		require.Equal(t, "key", r.Header.Get("X-API-KEY"))
+		response := finalizationDataResponse{Status: "NOT_AVAILABLE"}
+		if r.URL.Path == "/data/1/1" {
+			response = finalizationDataResponse{Status: "OK", Signatures: []string{"0x" + hex.EncodeToString(payload)}}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(response))
+	}))
+	defer server.Close()
+	provider := newHttpFinalizationProvider(&config.FinalizationProviderConfig{ApiEndpoint: server.URL + "/data/"}, "key")
+
+	payloads, err := provider.FinalizationData(context.Background(), 1, 2)
+	require.NoError(t, err)
+	require.Nil(t, payloads)
+
+	payloads, err = provider.FinalizationData(context.Background(), 1, 1)
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+	require.Equal(t, crypto.PubkeyToAddress(privateKey.PublicKey), payloads[0].signer)
+
+	clients, err := setupTest()
+	require.NoError(t, err)
+	c := clients.finalizer
+	spiLog, err := newSPILog(privateKey)
+	require.NoError(t, err)
+	policyData, err := shared.ParseSigningPolicyInitializedEvent(c.relayClient.relay, *spiLog)
+	require.NoError(t, err)
+	require.NoError(t, c.signingPolicyStorage.Add(newSigningPolicy(policyData)))
+
+	// already relayed
+	providers := []finalizationProvider{provider}
+	clients.eth.merkleRoots = map[relayedRoundKey]common.Hash{{votingRoundId: 1, protocolId: 1}: common.HexToHash("0x01")}
+	requests.Store(0)
+	require.NoError(t, c.fetchFinalizationData(context.Background(), 1, 1, providers))
+	require.Zero(t, requests.Load())
+
+	clients.eth.merkleRoots = nil
+	require.NoError(t, c.fetchFinalizationData(context.Background(), 1, 1, providers))
+	require.EqualValues(t, 1, requests.Load())
+	require.Len(t, c.submissionStorage.ThresholdReached(1, 1), 1)
+	require.Len(t, c.PendingItems(), 1)
+
+	// threshold reached with the provided signatures
+	require.NoError(t, c.fetchFinalizationData(context.Background(), 1, 1, providers))
+	require.EqualValues(t, 1, requests.Load())
+
+	failing := newHttpFinalizationProvider(&config.FinalizationProviderConfig{ApiEndpoint: "http://127.0.0.1:1"}, "")
+	require.ErrorContains(t, c.fetchFinalizationData(context.Background(), 1, 2, []finalizationProvider{failing}),
+		"all finalization providers failed")
+}
@@ -0,0 +1,162 @@
+package finalizer
+
+import (
+	"flare-tlc/client/admin"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Implementation of admin.Finalizer
+
+func (c *finalizerClient) SigningPolicies() []admin.SigningPolicyInfo {
+	policies := c.signingPolicyStorage.All()
+	result := make([]admin.SigningPolicyInfo, len(policies))
+	for i, sp := range policies {
+		result[i] = admin.SigningPolicyInfo{
+			RewardEpochId:      sp.rewardEpochId,
+			StartVotingRoundId: sp.startVotingRoundId,
+			Threshold:          sp.threshold,
+			Voters:             sp.voters.Count(),
+			Seed:               sp.seed.String(),
+		}
+	}
+	return result
+}
+
+func (c *finalizerClient) PendingItems() []admin.QueueItemInfo {
+	var result []admin.QueueItemInfo
+	for _, item := range c.queueProcessor.queue.Items() {
+		result = append(result, queueItemInfo(item, nil))
+	}
+	for t, items := range c.queueProcessor.delayedQueues.Items() {
+		scheduledAt := t
+		for _, item := range items {
+			result = append(result, queueItemInfo(item, &scheduledAt))
+		}
+	}
+	c.queueProcessor.pausedDelayedMutex.Lock()
+	for _, item := range c.queueProcessor.pausedDelayed {
+		result = append(result, queueItemInfo(item, nil))
+	}
+	c.queueProcessor.pausedDelayedMutex.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].VotingRoundId < result[j].VotingRoundId
+	})
+	return result
+}
+
+func queueItemInfo(item *queueItem, scheduledAt *time.Time) admin.QueueItemInfo {
+	return admin.QueueItemInfo{
+		VotingRoundId: item.votingRoundId,
+		ProtocolId:    item.protocolId,
+		MessageHash:   item.messageHash.Hex(),
+		ScheduledAt:   scheduledAt,
+		Secondary:     item.secondary,
+	}
+}
+
+func (c *finalizerClient) Submitters() []admin.SubmitterInfo {
+	return c.submitters.Submitters()
+}
+
+func (c *finalizerClient) LastProcessedVotingRound() uint32 {
+	return c.lastProcessedVotingRound.Load()
+}
+
+func (c *finalizerClient) Pause() {
+	c.queueProcessor.Pause()
+}
+
+func (c *finalizerClient) Resume() {
+	c.queueProcessor.Resume()
+}
+
+func (c *finalizerClient) Paused() bool {
+	return c.queueProcessor.Paused()
+}
+
+// Queries the last finalization of the protocols from the Relay contract, of the
+// configured protocols if protocolIds is empty
+func (c *finalizerClient) Finalizations(protocolIds []byte) ([]admin.FinalizationInfo, error) {
+	if len(protocolIds) == 0 {
+		protocolIds = c.finalizerContext.protocolIds()
+	}
+	if len(protocolIds) == 0 {
+		return nil, fmt.Errorf("no protocols configured, protocol id is required")
+	}
+	result := make([]admin.FinalizationInfo, len(protocolIds))
+	for i, protocolId := range protocolIds {
+		finalization, err := c.lastFinalization(protocolId)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = admin.FinalizationInfo{ProtocolId: protocolId}
+		if finalization == nil {
+			continue
+		}
+		result[i].Found = true
+		result[i].VotingRoundId = finalization.votingRoundId
+		result[i].MerkleRoot = finalization.merkleRoot.Hex()
+		if !finalization.timestamp.IsZero() {
+			finalizedAt := finalization.timestamp
+			result[i].FinalizedAt = &finalizedAt
+		}
+	}
+	return result, nil
+}
+
+// Fetch signing policies from start offset until now and add the ones newer
+// than the last policy in the storage. Returns the number of added policies.
+func (c *finalizerClient) RefetchSigningPolicies() (int, error) {
+	startTime := c.clock.Now().Add(-c.finalizerContext.startTimeOffset)
+	spList, err := c.relayClient.FetchSigningPolicies(c.db, startTime.Unix(), c.clock.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, sp := range spList {
+		policy := newSigningPolicy(sp.policyData)
+		if policy.rewardEpochId < c.finalizerContext.startingRewardEpoch {
+			continue
+		}
+		if last := c.signingPolicyStorage.Last(); last != nil && policy.rewardEpochId <= last.rewardEpochId {
+			continue
+		}
+		if err := c.signingPolicyStorage.Add(policy); err != nil {
+			return count, err
+		}
+		c.persistSigningPolicy(sp.policyData)
+		count++
+	}
+	return count, nil
+}
+
+// Process the submitSignatures txs again from the timestamp on
+func (c *finalizerClient) RescanSubmissions(from time.Time) {
+	c.submissionClient.Rescan(from)
+}
+
+// Queue messages of the voting round and protocol that reached the threshold,
+// returns the number of queued messages
+func (c *finalizerClient) Resend(votingRoundId uint32, protocolId byte) (int, error) {
+	sp, _ := c.signingPolicyData(protocolId, votingRoundId)
+	if sp == nil {
+		return 0, fmt.Errorf("no signing policy found for voting round %d", votingRoundId)
+	}
+	hashes := c.submissionStorage.ThresholdReached(votingRoundId, protocolId)
+	if len(hashes) == 0 {
+		return 0, fmt.Errorf("no message reached the threshold for voting round %d and protocol %d", votingRoundId, protocolId)
+	}
+	for _, hash := range hashes {
+		c.queueProcessor.queue.Add(&queueItem{
+			seed:          sp.seed,
+			votingRoundId: votingRoundId,
+			protocolId:    protocolId,
+			messageHash:   hash,
+			forced:        true,
+		})
+	}
+	return len(hashes), nil
+}
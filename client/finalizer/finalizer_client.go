@@ -4,12 +4,16 @@ import (
 	"context"
 	"encoding/hex"
 	clientContext "flare-tlc/client/context"
-	"flare-tlc/config"
+	"flare-tlc/client/shared"
+	"flare-tlc/client/shared/alerts"
+	"flare-tlc/client/shared/rpcsource"
 	"flare-tlc/database"
-	"flare-tlc/logger"
+	"flare-tlc/utils"
+	"flare-tlc/utils/contracts/registry"
 	"flare-tlc/utils/contracts/relay"
-	"flare-tlc/utils/credentials"
 	"fmt"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -19,7 +23,7 @@ import (
 )
 
 type finalizerClient struct {
-	db finalizerDB
+	db FinalizerDB
 
 	relayClient          *relayContractClient
 	submissionClient     *submissionContractClient
@@ -27,10 +31,35 @@ type finalizerClient struct {
 	submissionStorage    *submissionStorage
 	queueProcessor       *finalizerQueueProcessor
 
+	// optional durable storage of signing policies, nil if persistence is disabled
+	policyStore signingPolicyStore
+
+	// nil if reorg detection is disabled
+	reorgTracker *shared.ReorgTracker
+
+	// nil if signers are not verified against the voter registry, shared with the submission storage
+	voterRegistry *voterRegistryCache
+
+	// invalid signatures and bans of the submitters
+	submitters *submitterTracker
+	// submitters whose submissions are processed
+	submitterFilter *submitterFilter
+
+	// external sources of finalization data by protocol, nil if none are configured
+	finalizationProviders map[byte][]finalizationProvider
+
+	// highest voting round of processed submissions
+	lastProcessedVotingRound atomic.Uint32
+
+	// polling intervals of the listeners, the defaults if not set
+	listenerIntervals shared.ListenerIntervals
+
 	finalizerContext *finalizerContext
+	clock            utils.Clock
 }
 
-type finalizerDB interface {
+// Indexer transactions and logs read by the finalizer, see NewFinalizerDB
+type FinalizerDB interface {
 	FetchTransactionsByAddressAndSelector(
 		common.Address, []byte, int64, int64,
 	) ([]database.Transaction, error)
@@ -38,30 +67,64 @@ type finalizerDB interface {
 }
 
 type finalizerDBImpl struct {
-	client *gorm.DB
+	client    *gorm.DB
+	fetchOpts database.FetchOptions
 }
 
 func (db finalizerDBImpl) FetchTransactionsByAddressAndSelector(
 	address common.Address, selector []byte, from, to int64,
 ) ([]database.Transaction, error) {
 	hexSelector := hex.EncodeToString(selector)
-	return database.FetchTransactionsByAddressAndSelector(db.client, address.Hex(), hexSelector, from, to)
+	return database.FetchTransactionsByAddressAndSelector(db.client, address.Hex(), hexSelector, from, to, db.fetchOpts)
 }
 
 func (db finalizerDBImpl) FetchLogsByAddressAndTopic0(
 	address common.Address, topic0 string, from, to int64,
 ) ([]database.Log, error) {
-	return database.FetchLogsByAddressAndTopic0(db.client, address.Hex(), topic0, from, to)
+	return database.FetchLogsByAddressAndTopic0(db.client, address.Hex(), topic0, from, to, db.fetchOpts)
+}
+
+// Reads from the rpc node instead of the indexer db while the indexer lags
+type fallbackFinalizerDB struct {
+	indexer FinalizerDB
+	monitor *rpcsource.IndexerMonitor
+}
+
+func (db fallbackFinalizerDB) FetchTransactionsByAddressAndSelector(
+	address common.Address, selector []byte, from, to int64,
+) ([]database.Transaction, error) {
+	if db.monitor.Degraded() {
+		return db.monitor.Source().FetchTransactionsByAddressAndSelector(address, selector, from, to)
+	}
+	return db.indexer.FetchTransactionsByAddressAndSelector(address, selector, from, to)
+}
+
+func (db fallbackFinalizerDB) FetchLogsByAddressAndTopic0(
+	address common.Address, topic0 string, from, to int64,
+) ([]database.Log, error) {
+	if db.monitor.Degraded() {
+		return db.monitor.Source().FetchLogsByAddressAndTopic0(address, topic0, from, to)
+	}
+	return db.indexer.FetchLogsByAddressAndTopic0(address, topic0, from, to)
+}
+
+// NewFinalizerDB returns the indexer database of the finalizer
+func NewFinalizerDB(db *gorm.DB, fetchOpts database.FetchOptions) FinalizerDB {
+	return finalizerDBImpl{client: db, fetchOpts: fetchOpts}
 }
 
 func NewFinalizerClient(ctx clientContext.ClientContext) (*finalizerClient, error) {
-	cfg := ctx.Config()
-	if !cfg.Clients.EnabledFinalizer {
+	if !ctx.Config().Clients.EnabledFinalizer {
 		return nil, nil
 	}
+	return newFinalizerClient(ctx)
+}
+
+// Creates the client regardless of the enabled clients, also used for manual operations
+func newFinalizerClient(ctx clientContext.ClientContext) (*finalizerClient, error) {
+	cfg := ctx.Config()
 
-	chainCfg := cfg.ChainConfig()
-	ethClient, err := chainCfg.DialETH()
+	ethClient, err := ctx.EthClient()
 	if err != nil {
 		return nil, err
 	}
@@ -75,37 +138,103 @@ func NewFinalizerClient(ctx clientContext.ClientContext) (*finalizerClient, erro
 		return nil, err
 	}
 
-	senderPkString, err := config.PrivateKeyFromConfig(cfg.Credentials.SigningPolicyPrivateKeyFile,
-		cfg.Credentials.SigningPolicyPrivateKey)
+	signer, err := ctx.SigningPolicySigner()
 	if err != nil {
-		return nil, errors.Wrap(err, "error reading sender private key")
+		return nil, errors.Wrap(err, "error creating sender signer")
 	}
-	txOpts, senderPk, err := credentials.CredentialsFromPrivateKey(senderPkString, chainCfg.ChainID)
+	senders, err := newProtocolSenders(cfg.Finalizer.Senders)
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating sender register tx opts")
+		return nil, err
 	}
 	relayClient, err := NewRelayContractClient(
 		ethClient,
 		cfg.ContractAddresses.Relay,
-		senderPk,
-		txOpts.From,
+		&cfg.RelayGas,
+		signer,
+		senders,
+		&cfg.Retry,
 	)
 	if err != nil {
 		return nil, err
 	}
-	submissionClient := NewSubmissionContractClient(cfg.ContractAddresses.Submission)
-	submissionStorage := newSubmissionStorage()
+	if cfg.Listeners.SigningPolicyInitialized.Websocket {
+		wsClient, err := ctx.WSClient()
+		if err != nil {
+			return nil, errors.Wrap(err, "error dialing websocket for SigningPolicyInitialized listener")
+		}
+		relayClient.spiSubscriber = wsClient
+	}
+	submissionClient, err := NewSubmissionContractClient(cfg.ContractAddresses.Submission)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating submission client")
+	}
+	listenerIntervals := cfg.Listeners.Intervals(cfg.Chain.ChainID)
+	relayClient.listenerIntervals = listenerIntervals
+	relayClient.listenerBuffers = cfg.Listeners.Buffers()
+	submissionClient.listenerIntervals = listenerIntervals
+	submissionStorage := newSubmissionStorage(cfg.Finalizer.MaxRetainedRounds)
+	var voterRegistry *voterRegistryCache
+	if cfg.Finalizer.VerifyVoterRegistry {
+		registryCaller, err := registry.NewRegistryCaller(cfg.ContractAddresses.VoterRegistry, ethClient)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating voter registry contract")
+		}
+		voterRegistry = newVoterRegistryCache(registryCaller)
+		submissionStorage.voterRegistry = voterRegistry
+	}
 
-	db := finalizerDBImpl{client: ctx.DB()}
+	fetchOpts := cfg.Listeners.Fetch.FetchOptions()
+	var reorgTracker *shared.ReorgTracker
+	if cfg.Listeners.Reorg.Enabled {
+		rpcClient, err := ctx.RPCClient()
+		if err != nil {
+			return nil, errors.Wrap(err, "error dialing rpc for reorg detection")
+		}
+		reorgTracker = shared.NewReorgTracker(rpcClient, "finalizer", cfg.Listeners.Reorg.Depth)
+		relayClient.reorgTracker = reorgTracker
+		submissionClient.reorgTracker = reorgTracker
+		fetchOpts.PreloadBlocks = true
+	}
+
+	var db FinalizerDB = finalizerDBImpl{client: ctx.DB(), fetchOpts: fetchOpts}
+	if source := ctx.RPCSource(); source != nil {
+		db = source
+	} else if monitor := ctx.IndexerMonitor(); monitor != nil {
+		db = fallbackFinalizerDB{indexer: db, monitor: monitor}
+	}
+
+	queueProcessor := newFinalizerQueueProcessor(db, submissionStorage, relayClient, finalizerContext)
+	if cfg.Finalizer.PersistentQueue {
+		queueProcessor.store, err = newFinalizerQueueStoreDB(ctx.DB())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var policyStore signingPolicyStore
+	if cfg.Finalizer.PersistentSigningPolicies {
+		policyStore, err = newSigningPolicyStoreDB(ctx.DB())
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &finalizerClient{
-		db:                   db,
-		relayClient:          relayClient,
-		signingPolicyStorage: newSigningPolicyStorage(),
-		submissionStorage:    submissionStorage,
-		submissionClient:     submissionClient,
-		queueProcessor:       newFinalizerQueueProcessor(db, submissionStorage, relayClient, finalizerContext),
-		finalizerContext:     finalizerContext,
+		db:                    db,
+		policyStore:           policyStore,
+		reorgTracker:          reorgTracker,
+		voterRegistry:         voterRegistry,
+		submitters:            newSubmitterTracker(&cfg.Finalizer.SubmitterBan),
+		submitterFilter:       newSubmitterFilter(&cfg.Finalizer.Submitters),
+		finalizationProviders: newFinalizationProviders(cfg.Finalizer.FinalizationProviders),
+		relayClient:           relayClient,
+		signingPolicyStorage:  newSigningPolicyStorage(),
+		submissionStorage:     submissionStorage,
+		submissionClient:      submissionClient,
+		queueProcessor:        queueProcessor,
+		finalizerContext:      finalizerContext,
+		listenerIntervals:     listenerIntervals,
+		clock:                 utils.RealClock,
 	}, nil
 }
 
@@ -116,11 +245,15 @@ func (c *finalizerClient) Run(ctx context.Context) error {
 func (c *finalizerClient) RunContext(ctx context.Context) error {
 	eg, ctx := errgroup.WithContext(ctx)
 
-	startTime := time.Now().Add(-c.finalizerContext.startTimeOffset)
+	startTime := c.clock.Now().Add(-c.finalizerContext.startTimeOffset)
 	startTime, err := c.fetchExistingSigningPolicies(ctx, startTime)
 	if err != nil {
 		return err
 	}
+	if err := c.queueProcessor.RestorePending(); err != nil {
+		return err
+	}
+	c.skipFinalizedRounds()
 
 	eg.Go(func() error {
 		return c.runSigningPolicyInitializedListener(ctx, startTime)
@@ -128,41 +261,140 @@ func (c *finalizerClient) RunContext(ctx context.Context) error {
 	eg.Go(func() error {
 		return c.submissionClient.SubmissionTxListener(ctx, c.db, startTime, c)
 	})
+	eg.Go(func() error {
+		return c.runProtocolMessageRelayedListener(ctx, startTime)
+	})
 	eg.Go(func() error {
 		return c.queueProcessor.Run(ctx)
 	})
+	if c.voterRegistry != nil {
+		eg.Go(func() error {
+			return c.voterRegistry.Run(ctx)
+		})
+	}
+	if c.reorgTracker != nil {
+		eg.Go(func() error {
+			return c.runReorgCheck(ctx)
+		})
+	}
+	if len(c.finalizationProviders) > 0 {
+		eg.Go(func() error {
+			return c.runFinalizationProviders(ctx)
+		})
+	}
+	if alerts.Enabled() {
+		eg.Go(func() error {
+			return c.runSigningPolicyCheck(ctx)
+		})
+	}
 
 	return eg.Wait()
 }
 
+// Marks the last voting round finalized on chain of each configured protocol as
+// relayed, the messages of the round collected on startup are then not relayed again
+func (c *finalizerClient) skipFinalizedRounds() {
+	for _, protocolId := range c.finalizerContext.protocolIds() {
+		finalization, err := c.lastFinalization(protocolId)
+		if err != nil {
+			logger.Warn("Error fetching the last finalization of protocol %d: %v", protocolId, err)
+			continue
+		}
+		if finalization == nil {
+			logger.Info("Protocol %d not finalized in the last %d voting rounds", protocolId, finalizationLookbackRounds)
+			continue
+		}
+		logger.Info("Protocol %d last finalized in voting round %d", protocolId, finalization.votingRoundId)
+		c.queueProcessor.MarkRelayed([]queueItemKey{{
+			votingRoundId: finalization.votingRoundId,
+			protocolId:    protocolId,
+			messageHash:   finalization.merkleRoot,
+		}})
+	}
+}
+
+// Last finalization of the protocol up to the current voting round
+func (c *finalizerClient) lastFinalization(protocolId byte) (*relayFinalization, error) {
+	now := c.clock.Now()
+	votingRoundId := c.finalizerContext.votingEpoch.EpochIndex(now)
+	if votingRoundId < 0 {
+		return nil, nil
+	}
+	return c.relayClient.LastFinalization(c.db, protocolId, uint32(votingRoundId), c.finalizerContext.votingEpoch, now)
+}
+
 func (c *finalizerClient) fetchExistingSigningPolicies(
 	ctx context.Context, startTime time.Time,
 ) (time.Time, error) {
+	startTime = c.loadPersistedSigningPolicies(startTime)
+
 	// Read current signing policies from the database and add them to the storage
-	spList, err := c.relayClient.FetchSigningPolicies(c.db, startTime.Unix(), time.Now().Unix())
+	result, err := c.syncSigningPolicies(ctx, startTime.Unix(), c.clock.Now().Unix(), signingPolicySyncWindow)
 	if err != nil {
 		return startTime, err
 	}
-	for _, sp := range spList {
-		policy := newSigningPolicy(sp.policyData)
+	logger.Info("Added %d signing policies", result.added)
+
+	if result.fetched > 0 {
+		return time.Unix(result.lastTimestamp, 0), nil
+	}
+
+	return startTime, nil
+}
+
+// Add persisted signing policies with timestamp >= startTime to the storage.
+// Returns the timestamp of the last loaded policy, logs are then scanned only
+// for newer policies. If the persisted policies can not be used, the storage is
+// left empty and the logs are scanned from startTime.
+func (c *finalizerClient) loadPersistedSigningPolicies(startTime time.Time) time.Time {
+	if c.policyStore == nil {
+		return startTime
+	}
+	policies, err := c.policyStore.Load()
+	if err != nil {
+		logger.Warn("Error loading persisted signing policies: %v", err)
+		return startTime
+	}
+
+	loadedStartTime := startTime
+	count := 0
+	for _, policyData := range policies {
+		if int64(policyData.Timestamp) < startTime.Unix() {
+			continue
+		}
+		policy := newSigningPolicy(policyData)
 		if policy.rewardEpochId < c.finalizerContext.startingRewardEpoch {
 			continue
 		}
 		if err := c.signingPolicyStorage.Add(policy); err != nil {
-			return startTime, err
+			logger.Warn("Error adding persisted signing policy, scanning logs instead: %v", err)
+			c.signingPolicyStorage = newSigningPolicyStorage()
+			return startTime
 		}
+		c.refreshVoterRegistry(policy)
+		loadedStartTime = time.Unix(int64(policyData.Timestamp), 0)
+		count++
 	}
-	logger.Info("Added %d signing policies", len(spList))
+	logger.Info("Loaded %d persisted signing policies", count)
+	return loadedStartTime
+}
 
-	if len(spList) > 0 {
-		return time.Unix(spList[len(spList)-1].timestamp, 0), nil
+func (c *finalizerClient) persistSigningPolicy(policyData *relay.RelaySigningPolicyInitialized) {
+	if c.policyStore == nil {
+		return
+	}
+	if err := c.policyStore.Save(policyData); err != nil {
+		logger.Warn("Error persisting signing policy for reward epoch %v: %v", policyData.RewardEpochId, err)
 	}
+}
 
-	return startTime, nil
+// The voters are fetched in the background, see voterRegistryCache.Run
+func (c *finalizerClient) refreshVoterRegistry(policy *signingPolicy) {
+	c.voterRegistry.Schedule(policy)
 }
 
 func (c *finalizerClient) runSigningPolicyInitializedListener(ctx context.Context, startTime time.Time) error {
-	spListener := c.relayClient.SigningPolicyInitializedListener(c.db, startTime)
+	spListener := c.relayClient.SigningPolicyInitializedListener(ctx, c.db, startTime)
 	for {
 		var dbPolicy signingPolicyListenerResponse
 		select {
@@ -178,7 +410,7 @@ func (c *finalizerClient) runSigningPolicyInitializedListener(ctx context.Contex
 		if policy.rewardEpochId < c.finalizerContext.startingRewardEpoch {
 			continue
 		}
-		if err := c.signingPolicyStorage.Add(policy); err != nil {
+		if err := c.addSigningPolicy(policy, dbPolicy); err != nil {
 			logger.Warn("Error adding signing policy %v", err)
 		}
 		logger.Info("New signing policy received for epoch %v", policy.rewardEpochId)
@@ -186,8 +418,147 @@ func (c *finalizerClient) runSigningPolicyInitializedListener(ctx context.Contex
 	}
 }
 
+// Adds the signing policy received by the listener. If the signing policies of
+// the previous reward epochs are missing, e.g. an event was skipped while the
+// indexer was lagging, they are backfilled first.
+func (c *finalizerClient) addSigningPolicy(policy *signingPolicy, dbPolicy signingPolicyListenerResponse) error {
+	err := c.signingPolicyStorage.Add(policy)
+	var gapErr signingPolicyGapError
+	if errors.As(err, &gapErr) {
+		logger.Warn("Signing policies for reward epochs %d to %d are missing, backfilling", gapErr.last+1, gapErr.next-1)
+		if err := c.backfillSigningPolicies(gapErr, dbPolicy.timestamp); err != nil {
+			signingPolicyBackfills.WithLabelValues("failed").Inc()
+			return err
+		}
+		signingPolicyBackfills.WithLabelValues("filled").Inc()
+		err = c.signingPolicyStorage.Add(policy)
+	}
+	if err != nil {
+		return err
+	}
+	c.persistSigningPolicy(dbPolicy.policyData)
+	c.refreshVoterRegistry(policy)
+	return nil
+}
+
+// Fetches the logs of the missing signing policies between the last stored policy
+// and the policy with timestamp to. If a missing policy is not indexed, the Relay
+// contract is queried to tell an indexer gap from a policy not initialized yet.
+// The range is fetched again with the next received policy.
+func (c *finalizerClient) backfillSigningPolicies(gap signingPolicyGapError, to int64) error {
+	from := int64(c.signingPolicyStorage.Last().blockTimestamp)
+	spList, err := c.relayClient.FetchSigningPolicies(c.db, from, to)
+	if err != nil {
+		return errors.Wrap(err, "error fetching missing signing policies")
+	}
+	for _, sp := range spList {
+		policy := newSigningPolicy(sp.policyData)
+		if policy.rewardEpochId != c.signingPolicyStorage.Last().rewardEpochId+1 || policy.rewardEpochId >= gap.next {
+			continue
+		}
+		if err := c.signingPolicyStorage.Add(policy); err != nil {
+			return err
+		}
+		c.persistSigningPolicy(sp.policyData)
+		c.refreshVoterRegistry(policy)
+		logger.Info("Backfilled signing policy for reward epoch %d", policy.rewardEpochId)
+	}
+
+	missing := c.signingPolicyStorage.Last().rewardEpochId + 1
+	if missing == gap.next {
+		return nil
+	}
+	initialized, err := c.relayClient.SigningPolicyInitialized(missing)
+	if err != nil {
+		return errors.Wrapf(err, "signing policy for reward epoch %d not found in the logs", missing)
+	}
+	if initialized {
+		return errors.Errorf("signing policy for reward epoch %d is initialized on chain but not found in the logs", missing)
+	}
+	return errors.Errorf("signing policy for reward epoch %d is not initialized on chain", missing)
+}
+
+// Track rounds finalized by any finalizer, so queued items for these rounds are
+// dropped instead of sending a relay tx that would be reverted.
+func (c *finalizerClient) runProtocolMessageRelayedListener(ctx context.Context, startTime time.Time) error {
+	ticker := shared.NewListenerTicker(c.clock, listenerProtocolMessageRelayed, c.listenerIntervals.Interval(listenerProtocolMessageRelayed))
+	defer ticker.Stop()
+	eventRangeStart := startTime
+	for {
+		if !ticker.Wait(ctx) {
+			logger.Info("Protocol message relayed listener stopped")
+			return ctx.Err()
+		}
+
+		now := c.clock.Now()
+		relayed, err := c.relayClient.ProtocolMessageRelayed(c.db, eventRangeStart, now)
+		if err != nil {
+			logger.Error("Error fetching ProtocolMessageRelayed events %v", err)
+			continue
+		}
+		c.queueProcessor.MarkRelayed(relayed.ToSlice())
+		// the indexer may lag behind, start again shortly before the previous
+		// end, events seen twice are ignored
+		eventRangeStart = now.Add(-c.finalizerContext.votingEpoch.Period)
+	}
+}
+
+// Fires the no_signing_policy alert while the reward epoch of the last signing policy
+// has ended and the next policy is not received, checked every voting epoch
+func (c *finalizerClient) runSigningPolicyCheck(ctx context.Context) error {
+	ticker := c.clock.NewTicker(c.finalizerContext.votingEpoch.Period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			c.checkSigningPolicy()
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *finalizerClient) checkSigningPolicy() {
+	now := c.clock.Now()
+	last := c.signingPolicyStorage.Last()
+	if last == nil {
+		alerts.Fire(alerts.NoSigningPolicy, "", map[string]string{
+			"reward_epoch":      strconv.FormatInt(c.finalizerContext.epochs.RewardEpochAt(now), 10),
+			"last_reward_epoch": "none",
+		})
+		return
+	}
+	fields := map[string]string{
+		"reward_epoch":      strconv.FormatInt(last.rewardEpochId+1, 10),
+		"last_reward_epoch": strconv.FormatInt(last.rewardEpochId, 10),
+	}
+	if now.Before(c.finalizerContext.epochs.RewardEpochEnd(last.rewardEpochId)) {
+		fields["reward_epoch"] = fields["last_reward_epoch"]
+		alerts.Resolve(alerts.NoSigningPolicy, "", fields)
+		return
+	}
+	alerts.Fire(alerts.NoSigningPolicy, "", fields)
+}
+
 func (c *finalizerClient) ProcessSubmissionData(slr submissionListenerResponse) error {
+	if !c.submitterFilter.Allowed(slr.submitter) {
+		ignoredSubmissions.Inc()
+		return nil
+	}
+	if c.submitters.Banned(slr.submitter) {
+		logger.Debug("Ignoring submitted signatures of banned submitter %s", slr.submitter.Hex())
+		return nil
+	}
+	if slr.invalidPayload {
+		c.submitters.Report(slr.submitter, invalidSignaturePayload)
+		return nil
+	}
 	for _, payloadItem := range slr.payload {
+		if !c.finalizerContext.protocolEnabled(payloadItem.protocolId) {
+			logger.Debug("Ignoring submitted signature for protocol %d - protocol not enabled", payloadItem.protocolId)
+			continue
+		}
 		if payloadItem.votingRoundId < c.finalizerContext.startingVotingRound {
 			logger.Debug("Ignoring submitted signature for voting round %d - before startingVotingRound", payloadItem.votingRoundId)
 			continue
@@ -197,7 +568,7 @@ func (c *finalizerClient) ProcessSubmissionData(slr submissionListenerResponse)
 		if !c.checkVotingRoundTime(payloadItem.votingRoundId) {
 			continue
 		}
-		sp, threshold := c.signingPolicyData(payloadItem.votingRoundId)
+		sp, threshold := c.signingPolicyData(payloadItem.protocolId, payloadItem.votingRoundId)
 		if sp == nil {
 			first := c.signingPolicyStorage.First()
 			if first != nil && payloadItem.votingRoundId < first.startVotingRoundId {
@@ -207,12 +578,36 @@ func (c *finalizerClient) ProcessSubmissionData(slr submissionListenerResponse)
 			}
 			return fmt.Errorf("no signing policy found for voting round %d", payloadItem.votingRoundId)
 		}
-		addResult, err := c.submissionStorage.Add(payloadItem.payload, sp, threshold)
+		if payloadItem.votingRoundId > c.lastProcessedVotingRound.Load() {
+			c.lastProcessedVotingRound.Store(payloadItem.votingRoundId)
+		}
+		if reason := c.finalizerContext.verifyMessage(payloadItem.protocolId, payloadItem.votingRoundId, payloadItem.payload); reason != "" {
+			logger.Debug("Ignoring submitted signature for protocol %d, voting round %d - malformed message: %s",
+				payloadItem.protocolId, payloadItem.votingRoundId, reason)
+			malformedMessages.WithLabelValues(strconv.Itoa(int(payloadItem.protocolId)), reason).Inc()
+			continue
+		}
+		verified := c.queueProcessor.tracer.signature(relayedRoundKey{
+			votingRoundId: payloadItem.votingRoundId, protocolId: payloadItem.protocolId,
+		}, slr.submitter, slr.timestamp)
+		addResult, err := c.submissionStorage.Add(payloadItem.payload, sp, threshold, slr.timestamp)
+		verified(err)
 		if err != nil {
 			// Error is non-fatal, skip this submission
 			logger.Debug("Ignoring submitted signature: %v", err)
+			if errors.As(err, &notInPolicyError{}) {
+				c.submitters.Report(slr.submitter, invalidSignatureNotInPolicy)
+			} else if errors.As(err, &spoofedSignatureError{}) {
+				c.submitters.Report(slr.submitter, invalidSignatureSpoofed)
+			}
 			continue
 		}
+		if addResult.duplicate {
+			c.submitters.Report(slr.submitter, invalidSignatureDuplicate)
+		} else {
+			c.submitters.Accepted(slr.submitter, sp)
+		}
+		signaturesReceived.WithLabelValues(strconv.Itoa(int(payloadItem.protocolId))).Inc()
 		if addResult.thresholdReached {
 			logger.Info("Threshold reached for protocol %d in voting round %d with hash %v", payloadItem.protocolId, payloadItem.votingRoundId, payloadItem.payload.messageHash)
 			c.queueProcessor.Add(payloadItem, sp.seed)
@@ -221,39 +616,48 @@ func (c *finalizerClient) ProcessSubmissionData(slr submissionListenerResponse)
 	return nil
 }
 
-// return signing policy and voting threshold for the given voting round
-func (c *finalizerClient) signingPolicyData(votingRoundId uint32) (*signingPolicy, uint16) {
+// return signing policy and the threshold of the signature weight of the protocol for the given voting round
+func (c *finalizerClient) signingPolicyData(protocolId byte, votingRoundId uint32) (*signingPolicy, uint16) {
 	sp, last := c.signingPolicyStorage.GetForVotingRound(votingRoundId)
 	if sp == nil {
 		return nil, 0
 	}
+	settings := c.finalizerContext.protocolSettings(protocolId)
 	if !last {
-		return sp, sp.threshold
+		return sp, settings.threshold(sp, false)
 	}
-	endVotingEpoch := c.finalizerContext.rewardEpoch.EndEpoch(sp.rewardEpochId)
-	end := c.finalizerContext.votingEpoch.EndTime(endVotingEpoch)
+	end := c.finalizerContext.epochs.RewardEpochEnd(sp.rewardEpochId)
 
-	if time.Now().Before(end) {
-		return sp, sp.threshold
-	} else {
-		return sp, uint16((uint32(sp.voters.TotalWeight()) * 60) / 100)
-	}
+	// the next signing policy is not initialized after the end of the reward epoch
+	return sp, settings.threshold(sp, !c.clock.Now().Before(end))
 }
 
 // Return true if voting round is not in the future, i.e., is <= the current voting round
 func (c *finalizerClient) checkVotingRoundTime(votingRoundId uint32) bool {
-	currentEpochId := c.finalizerContext.votingEpoch.EpochIndex(time.Now())
+	currentEpochId := c.finalizerContext.votingEpoch.EpochIndex(c.clock.Now())
 	return votingRoundId <= uint32(currentEpochId)
 }
 
 func (c *finalizerClient) rewardEpochCleanup() {
-	cleanupTime := time.Now().Add(-2 * c.finalizerContext.startTimeOffset)
+	cleanupTime := c.clock.Now().Add(-2 * c.finalizerContext.startTimeOffset)
 	cleanupVotingRoundId := c.finalizerContext.votingEpoch.EpochIndex(cleanupTime)
 	if cleanupVotingRoundId < 0 {
 		return
 	}
 	removedEpochIds := c.signingPolicyStorage.RemoveByVotingRound(uint32(cleanupVotingRoundId))
-	c.submissionStorage.RemoveVotingRoundIds(removedEpochIds)
+	if retained := c.finalizerContext.retainedRewardEpochs; retained > 0 {
+		if last := c.signingPolicyStorage.Last(); last != nil {
+			removedEpochIds = append(removedEpochIds, c.signingPolicyStorage.RemoveByRewardEpoch(last.rewardEpochId-retained)...)
+		}
+	}
+	c.submissionStorage.RemoveUpTo(uint32(cleanupVotingRoundId))
+	c.voterRegistry.Remove(removedEpochIds)
+	c.queueProcessor.RemoveUpTo(uint32(cleanupVotingRoundId))
+	if len(removedEpochIds) > 0 && c.policyStore != nil {
+		if err := c.policyStore.DeleteUpTo(removedEpochIds[len(removedEpochIds)-1]); err != nil {
+			logger.Warn("Error removing persisted signing policies: %v", err)
+		}
+	}
 	if len(removedEpochIds) > 0 {
 		logger.Info("Removed signing policies and submissions with reward epoch <= %d", removedEpochIds[len(removedEpochIds)-1])
 	}
@@ -6,11 +6,16 @@ import (
 	"crypto/ecdsa"
 	"encoding/binary"
 	"encoding/hex"
+	clientConfig "flare-tlc/client/config"
 	"flare-tlc/config"
 	"flare-tlc/database"
-	"flare-tlc/logger"
+	flarelogger "flare-tlc/logger"
 	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/epoch"
+	"flare-tlc/utils/signingpolicy"
 	"math/big"
 	"os"
 	"strings"
@@ -41,7 +46,7 @@ var (
 )
 
 func TestMain(m *testing.M) {
-	logger.Configure(config.LoggerConfig{
+	flarelogger.Configure(config.LoggerConfig{
 		Level:   "DEBUG",
 		Console: true,
 	})
@@ -163,13 +168,13 @@ func setupTest() (*testClients, error) {
 		return nil, err
 	}
 
-	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
-
 	relayClient, err := NewRelayContractClient(
 		nil,
 		relayContractAddress,
-		privateKey,
-		fromAddress,
+		&clientConfig.GasConfig{GasPriceFixed: common.Big0},
+		credentials.NewPrivateKeySigner(privateKey),
+		nil,
+		nil,
 	)
 	if err != nil {
 		return nil, err
@@ -177,22 +182,25 @@ func setupTest() (*testClients, error) {
 
 	relayClient.ethClient = ethClient
 
-	submissionStorage := newSubmissionStorage()
+	submissionStorage := newSubmissionStorage(0)
 
 	db, err := newTestDB(privateKey)
 	if err != nil {
 		return nil, err
 	}
 
+	submissionClient, err := NewSubmissionContractClient(submissionContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	votingEpoch := &utils.Epoch{
+		Start:  time.Unix(0, 0),
+		Period: time.Hour,
+	}
 	fCtx := &finalizerContext{
-		votingEpoch: &utils.Epoch{
-			Start:  time.Unix(0, 0),
-			Period: time.Hour,
-		},
-		rewardEpoch: &utils.IntEpoch{
-			Start:  0,
-			Period: 100,
-		},
+		votingEpoch:        votingEpoch,
+		epochs:             epoch.NewSchedule(votingEpoch, utils.NewIntEpoch(0, 100)),
 		voterThresholdBIPS: 5000,
 	}
 
@@ -201,11 +209,12 @@ func setupTest() (*testClients, error) {
 		relayClient:          relayClient,
 		signingPolicyStorage: newSigningPolicyStorage(),
 		submissionStorage:    submissionStorage,
-		submissionClient:     NewSubmissionContractClient(submissionContractAddress),
+		submissionClient:     submissionClient,
 		queueProcessor: newFinalizerQueueProcessor(
 			db, submissionStorage, relayClient, fCtx,
 		),
 		finalizerContext: fCtx,
+		clock:            utils.RealClock,
 	}
 
 	return &testClients{
@@ -220,15 +229,20 @@ type testEthClient struct {
 	sentTxs   []*sentTxInfo
 	mu        sync.RWMutex
 	sendTxErr error
+
+	merkleRoots         map[relayedRoundKey]common.Hash
+	signingPolicyHashes map[int64]common.Hash
 }
 
 type sentTxInfo struct {
-	privateKey *ecdsa.PrivateKey
-	to         common.Address
-	data       []byte
+	from common.Address
+	to   common.Address
+	data []byte
 }
 
-func (eth *testEthClient) SendRawTx(privateKey *ecdsa.PrivateKey, to common.Address, data []byte, dryRun bool) error {
+func (eth *testEthClient) SendRawTx(
+	signer credentials.Signer, to common.Address, data []byte, preflight bool, _ ...chain.ExpectedEvent,
+) error {
 	eth.mu.Lock()
 	defer eth.mu.Unlock()
 
@@ -239,14 +253,28 @@ func (eth *testEthClient) SendRawTx(privateKey *ecdsa.PrivateKey, to common.Addr
 	}
 
 	eth.sentTxs = append(eth.sentTxs, &sentTxInfo{
-		privateKey: privateKey,
-		to:         to,
-		data:       data,
+		from: signer.Address(),
+		to:   to,
+		data: data,
 	})
 
 	return nil
 }
 
+func (eth *testEthClient) MerkleRoot(protocolId byte, votingRoundId uint32) (common.Hash, error) {
+	eth.mu.RLock()
+	defer eth.mu.RUnlock()
+
+	return eth.merkleRoots[relayedRoundKey{votingRoundId: votingRoundId, protocolId: protocolId}], nil
+}
+
+func (eth *testEthClient) SigningPolicyHash(rewardEpochId int64) (common.Hash, error) {
+	eth.mu.RLock()
+	defer eth.mu.RUnlock()
+
+	return eth.signingPolicyHashes[rewardEpochId], nil
+}
+
 func (eth *testEthClient) hasAnyCalls() bool {
 	eth.mu.RLock()
 	defer eth.mu.RUnlock()
@@ -295,10 +323,18 @@ func newSPILog(privateKey *ecdsa.PrivateKey) (*database.Log, error) {
 		Seed:               big.NewInt(1),
 		Voters:             []common.Address{voterAddress},
 		Weights:            []uint16{2}, // Weight of 2 > threshold of 1
-		SigningPolicyBytes: []byte{0x01},
 		Timestamp:          0,
 	}
+	policyBytes, err := signingpolicy.EncodeEvent(&spiLog)
+	if err != nil {
+		return nil, err
+	}
+	spiLog.SigningPolicyBytes = policyBytes
+	return encodeSPILog(&spiLog)
+}
 
+// Encodes the event as an indexer log with the timestamp of the event
+func encodeSPILog(spiLog *relay.RelaySigningPolicyInitialized) (*database.Log, error) {
 	relayABI, err := relay.RelayMetaData.GetAbi()
 	if err != nil {
 		return nil, err
@@ -344,7 +380,7 @@ func newSPILog(privateKey *ecdsa.PrivateKey) (*database.Log, error) {
 		Topic3:          "NULL",
 		TransactionHash: "0x" + strings.Repeat("ff", 32),
 		LogIndex:        0,
-		Timestamp:       0,
+		Timestamp:       spiLog.Timestamp,
 	}
 
 	// Sanity check that the log can be parsed.
@@ -529,3 +565,148 @@ func (db *testDB) FetchLogsByAddressAndTopic0(
 	db.spiLog = nil
 	return []database.Log{log}, nil
 }
+
+// Indexer logs filtered by timestamp
+type testLogsDB struct {
+	testDB
+	logs []database.Log
+}
+
+func (db *testLogsDB) FetchLogsByAddressAndTopic0(
+	address common.Address, topic string, from, to int64,
+) ([]database.Log, error) {
+	var result []database.Log
+	for _, log := range db.logs {
+		if int64(log.Timestamp) > from && int64(log.Timestamp) <= to {
+			result = append(result, log)
+		}
+	}
+	return result, nil
+}
+
+func TestSigningPolicyBackfill(t *testing.T) {
+	db := &testLogsDB{}
+	for _, rewardEpochId := range []int64{2, 3} {
+		log, err := encodeSPILog(newTestPolicyData(rewardEpochId, uint64(rewardEpochId*10)))
+		require.NoError(t, err)
+		db.logs = append(db.logs, *log)
+	}
+	relayContract, err := relay.NewRelay(relayContractAddress, nil)
+	require.NoError(t, err)
+	ethClient := &testEthClient{signingPolicyHashes: map[int64]common.Hash{5: common.HexToHash("0x05")}}
+	c := &finalizerClient{
+		db:                   db,
+		signingPolicyStorage: newSigningPolicyStorage(),
+		relayClient: &relayContractClient{
+			ethClient: ethClient,
+			relay:     relayContract,
+			address:   relayContractAddress,
+			topic0SPI: topicSPIHex,
+		},
+	}
+	require.NoError(t, c.signingPolicyStorage.Add(newSigningPolicy(newTestPolicyData(1, 10))))
+
+	// policies 2 and 3 were skipped by the listener
+	policyData := newTestPolicyData(4, 40)
+	err = c.addSigningPolicy(newSigningPolicy(policyData), signingPolicyListenerResponse{policyData, 40})
+	require.NoError(t, err)
+	var rewardEpochIds []int64
+	for _, sp := range c.signingPolicyStorage.All() {
+		rewardEpochIds = append(rewardEpochIds, sp.rewardEpochId)
+	}
+	require.Equal(t, []int64{1, 2, 3, 4}, rewardEpochIds)
+
+	// policy 5 is initialized on chain but not indexed
+	policyData = newTestPolicyData(6, 60)
+	err = c.addSigningPolicy(newSigningPolicy(policyData), signingPolicyListenerResponse{policyData, 60})
+	require.ErrorContains(t, err, "signing policy for reward epoch 5 is initialized on chain but not found in the logs")
+	require.EqualValues(t, 4, c.signingPolicyStorage.Last().rewardEpochId)
+
+	// not initialized on chain
+	delete(ethClient.signingPolicyHashes, 5)
+	err = c.addSigningPolicy(newSigningPolicy(policyData), signingPolicyListenerResponse{policyData, 60})
+	require.ErrorContains(t, err, "signing policy for reward epoch 5 is not initialized on chain")
+}
+
+func TestLastFinalization(t *testing.T) {
+	relayABI, err := relay.RelayMetaData.GetAbi()
+	require.NoError(t, err)
+	data, err := relayABI.Events["ProtocolMessageRelayed"].Inputs.NonIndexed().Pack(false, [32]byte(common.HexToHash("0x02")))
+	require.NoError(t, err)
+	topic0PMR, err := chain.EventIDFromMetadata(relay.RelayMetaData, "ProtocolMessageRelayed")
+	require.NoError(t, err)
+	db := &testLogsDB{logs: []database.Log{{
+		Data:      hex.EncodeToString(data),
+		Topic0:    topic0PMR,
+		Topic1:    common.BigToHash(big.NewInt(100)).Hex(),
+		Topic2:    common.BigToHash(big.NewInt(8)).Hex(),
+		Topic3:    "NULL",
+		Timestamp: 1000,
+	}}}
+	relayContract, err := relay.NewRelay(relayContractAddress, nil)
+	require.NoError(t, err)
+	ethClient := &testEthClient{merkleRoots: map[relayedRoundKey]common.Hash{
+		{votingRoundId: 8, protocolId: 100}:  common.HexToHash("0x02"),
+		{votingRoundId: 11, protocolId: 200}: common.HexToHash("0x03"),
+	}}
+	relayClient := &relayContractClient{ethClient: ethClient, relay: relayContract, topic0PMR: topic0PMR}
+	votingEpoch := utils.NewEpoch(time.Unix(0, 0), 90*time.Second)
+	now := time.Unix(2000, 0)
+
+	finalization, err := relayClient.LastFinalization(db, 100, 10, votingEpoch, now)
+	require.NoError(t, err)
+	require.Equal(t, &relayFinalization{
+		protocolId:    100,
+		votingRoundId: 8,
+		merkleRoot:    common.HexToHash("0x02"),
+		timestamp:     time.Unix(1000, 0),
+	}, finalization)
+
+	// finalized after the searched round
+	finalization, err = relayClient.LastFinalization(db, 200, 10, votingEpoch, now)
+	require.NoError(t, err)
+	require.Nil(t, finalization)
+}
+
+func TestSigningPolicyDataExpired(t *testing.T) {
+	votingEpoch := utils.NewEpoch(time.Unix(0, 0), 90*time.Second)
+	clock := utils.NewFakeClock(votingEpoch.StartTime(150))
+	c := &finalizerClient{
+		signingPolicyStorage: newSigningPolicyStorage(),
+		finalizerContext: &finalizerContext{
+			votingEpoch: votingEpoch,
+			epochs:      epoch.NewSchedule(votingEpoch, utils.NewIntEpoch(0, 100)),
+		},
+		clock: clock,
+	}
+	// reward epoch 1 lasts voting rounds 100 to 199, the threshold of the policy is 100 of 200
+	require.NoError(t, c.signingPolicyStorage.Add(newSigningPolicy(newTestPolicyData(1, 10))))
+
+	_, threshold := c.signingPolicyData(100, 150)
+	require.EqualValues(t, 100, threshold)
+
+	// the next signing policy is not initialized after the end of the reward epoch
+	clock.Advance(votingEpoch.StartTime(200).Sub(clock.Now()))
+	_, threshold = c.signingPolicyData(100, 150)
+	require.EqualValues(t, 120, threshold)
+}
+
+func TestProtocolSenders(t *testing.T) {
+	senderKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	t.Setenv("FINALIZER_SENDER_PRIVATE_KEY_1", hex.EncodeToString(crypto.FromECDSA(senderKey)))
+	senders, err := newProtocolSenders([]clientConfig.FinalizerSenderConfig{{Protocols: []uint8{100, 200}}})
+	require.NoError(t, err)
+
+	privateKey, err := crypto.HexToECDSA(testPrivateKeyHex)
+	require.NoError(t, err)
+	relayClient := &relayContractClient{signer: credentials.NewPrivateKeySigner(privateKey), senders: senders}
+	require.Equal(t, crypto.PubkeyToAddress(senderKey.PublicKey), relayClient.sender(100).Address())
+	require.Equal(t, crypto.PubkeyToAddress(senderKey.PublicKey), relayClient.sender(200).Address())
+	// other protocols are relayed from the signing policy address
+	require.Equal(t, crypto.PubkeyToAddress(privateKey.PublicKey), relayClient.sender(1).Address())
+
+	t.Setenv("FINALIZER_SENDER_PRIVATE_KEY_1", "")
+	_, err = newProtocolSenders([]clientConfig.FinalizerSenderConfig{{Protocols: []uint8{100}, PrivateKeyFile: "missing.txt"}})
+	require.ErrorContains(t, err, "error creating finalizer sender 1")
+}
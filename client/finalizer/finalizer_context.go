@@ -5,7 +5,11 @@ import (
 	"flare-tlc/client/shared"
 	"flare-tlc/utils"
 	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/epoch"
+	"sort"
 	"time"
+
+	"github.com/pkg/errors"
 )
 
 // Finalizer client settings
@@ -14,30 +18,217 @@ type finalizerContext struct {
 	startingVotingRound uint32
 	startTimeOffset     time.Duration // offset for fetching reward epochs at the start of the client
 
-	voterThresholdBIPS   uint16
-	gracePeriodEndOffset time.Duration
+	retainedRewardEpochs int64 // number of latest signing policies kept, 0 for time based cleanup
+
+	voterThresholdBIPS         uint16
+	thresholdBIPS              uint16 // threshold of the signature weight, 0 for the signing policy threshold
+	expiredPolicyThresholdBIPS uint16 // threshold of the signature weight after the last signing policy ended, 0 for 6000
+	gracePeriodEndOffset       time.Duration
+	backupRandomDelay          time.Duration // max random delay added to the grace period end for backup finalizations
+	queueWorkers               int           // number of parallel finalization workers, <= 1 processes the queue sequentially
+	onlyWhenSelected           bool          // do not finalize items outside the grace period
+	signatureSelection         string        // selection of the signatures included in the relay tx
+	verifyMessages             bool          // reject malformed signed messages before counting their signatures
+
+	// Settings of the finalized protocols, nil if all protocols are finalized
+	protocols map[byte]protocolSettings
 
 	votingEpoch *utils.Epoch
-	rewardEpoch *utils.IntEpoch
+	epochs      *epoch.Schedule // voting rounds of votingEpoch and reward epochs
 }
 
+type protocolSettings struct {
+	voterThresholdBIPS         uint16
+	thresholdBIPS              uint16
+	expiredPolicyThresholdBIPS uint16
+	gracePeriodEndOffset       time.Duration
+	secureRandom               bool // messages with random quality score 0 are secondary
+}
+
+const defaultExpiredPolicyThresholdBIPS = 6000
+
 // func newFinalizerContext(cfg *config.ClientConfig, systemsManager *system.FlareSystemsManager) (*finalizerContext, error) {
 func newFinalizerContext(cfg *config.ClientConfig, relay *relay.Relay) (*finalizerContext, error) {
-	votingEpoch, rewardEpoch, err := shared.EpochsFromChain(relay)
+	epochs, err := shared.ScheduleFromChain(relay)
 	if err != nil {
 		return nil, err
 	}
 	startingVotingRound := cfg.Finalizer.StartingVotingRound
+	startTimeOffset := cfg.Finalizer.StartOffset
+	if cfg.Finalizer.AutoStartOffset {
+		sd, err := relay.StateData(nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "error fetching relay state data")
+		}
+		firstVotingRound, offset := backfillBounds(
+			sd.RandomVotingRoundId, sd.MessageFinalizationWindowInRewardEpochs,
+			time.Now(), cfg.Finalizer.MaxStartOffset, epochs,
+		)
+		logger.Info("Finalizer backfills from voting round %d, start offset %v", firstVotingRound, offset)
+		startTimeOffset = offset
+		if startingVotingRound == 0 {
+			startingVotingRound = firstVotingRound
+		}
+	}
 	if startingVotingRound == 0 {
-		startingVotingRound = uint32(votingEpoch.EpochIndex(time.Now()))
+		startingVotingRound = uint32(epochs.VotingRound(time.Now()))
 	}
+	return newFinalizerContextWithEpochs(cfg, epochs, startingVotingRound, startTimeOffset), nil
+}
+
+func newFinalizerContextWithEpochs(
+	cfg *config.ClientConfig,
+	epochs *epoch.Schedule,
+	startingVotingRound uint32,
+	startTimeOffset time.Duration,
+) *finalizerContext {
 	return &finalizerContext{
-		startingRewardEpoch:  cfg.Finalizer.StartingRewardEpoch,
-		startingVotingRound:  startingVotingRound,
-		startTimeOffset:      cfg.Finalizer.StartOffset,
-		voterThresholdBIPS:   cfg.Finalizer.VoterThresholdBIPS,
-		gracePeriodEndOffset: cfg.Finalizer.GracePeriodEndOffset,
-		votingEpoch:          votingEpoch,
-		rewardEpoch:          rewardEpoch,
-	}, nil
+		startingRewardEpoch:        cfg.Finalizer.StartingRewardEpoch,
+		startingVotingRound:        startingVotingRound,
+		startTimeOffset:            startTimeOffset,
+		retainedRewardEpochs:       cfg.Finalizer.RetainedRewardEpochs,
+		voterThresholdBIPS:         cfg.Finalizer.VoterThresholdBIPS,
+		thresholdBIPS:              cfg.Finalizer.ThresholdBIPS,
+		expiredPolicyThresholdBIPS: cfg.Finalizer.ExpiredPolicyThresholdBIPS,
+		gracePeriodEndOffset:       cfg.Finalizer.GracePeriodEndOffset,
+		backupRandomDelay:          cfg.Finalizer.BackupRandomDelay,
+		queueWorkers:               cfg.Finalizer.QueueWorkers,
+		onlyWhenSelected:           cfg.Finalizer.OnlyWhenSelected,
+		signatureSelection:         cfg.Finalizer.SignatureSelection,
+		verifyMessages:             cfg.Finalizer.VerifyMessages,
+		protocols:                  newProtocolSettings(&cfg.Finalizer),
+		votingEpoch:                epochs.VotingRounds,
+		epochs:                     epochs,
+	}
+}
+
+// Returns the first voting round to finalize and the start offset for fetching
+// logs, given the last voting round finalized on chain. Logs are fetched from the
+// start of the reward epoch before the one containing the first voting round, so
+// that its signing policy is initialized. Rounds outside the message finalization
+// window can not be relayed anymore and are skipped. If maxOffset is set, the
+// offset is limited to maxOffset.
+func backfillBounds(
+	lastFinalizedVotingRound uint32,
+	finalizationWindow uint32,
+	now time.Time,
+	maxOffset time.Duration,
+	epochs *epoch.Schedule,
+) (uint32, time.Duration) {
+	firstVotingRound := int64(lastFinalizedVotingRound) + 1
+
+	if finalizationWindow > 0 {
+		windowStart := epochs.FirstVotingRound(epochs.RewardEpochAt(now) - int64(finalizationWindow))
+		if firstVotingRound < windowStart {
+			firstVotingRound = windowStart
+		}
+	}
+
+	startTime := epochs.RewardEpochStart(epochs.RewardEpoch(firstVotingRound) - 1)
+	offset := now.Sub(startTime)
+	if maxOffset > 0 && offset > maxOffset {
+		offset = maxOffset
+	}
+	return uint32(firstVotingRound), offset
+}
+
+// Returns the settings of the configured protocols, nil if no protocols are configured
+func newProtocolSettings(cfg *config.FinalizerConfig) map[byte]protocolSettings {
+	if len(cfg.Protocols) == 0 {
+		return nil
+	}
+	protocols := make(map[byte]protocolSettings, len(cfg.Protocols))
+	for _, protocol := range cfg.Protocols {
+		settings := protocolSettings{
+			voterThresholdBIPS:         cfg.VoterThresholdBIPS,
+			thresholdBIPS:              cfg.ThresholdBIPS,
+			expiredPolicyThresholdBIPS: cfg.ExpiredPolicyThresholdBIPS,
+			gracePeriodEndOffset:       cfg.GracePeriodEndOffset,
+		}
+		if protocol.VoterThresholdBIPS != 0 {
+			settings.voterThresholdBIPS = protocol.VoterThresholdBIPS
+		}
+		if protocol.ThresholdBIPS != 0 {
+			settings.thresholdBIPS = protocol.ThresholdBIPS
+		}
+		if protocol.ExpiredPolicyThresholdBIPS != 0 {
+			settings.expiredPolicyThresholdBIPS = protocol.ExpiredPolicyThresholdBIPS
+		}
+		if protocol.GracePeriodEndOffset != 0 {
+			settings.gracePeriodEndOffset = protocol.GracePeriodEndOffset
+		}
+		settings.secureRandom = protocol.SecureRandom
+		protocols[protocol.Id] = settings
+	}
+	return protocols
+}
+
+func (c *finalizerContext) protocolEnabled(protocolId byte) bool {
+	if c.protocols == nil {
+		return true
+	}
+	_, ok := c.protocols[protocolId]
+	return ok
+}
+
+// Returns the sorted ids of the configured protocols, nil if all protocols are finalized
+func (c *finalizerContext) protocolIds() []byte {
+	if c.protocols == nil {
+		return nil
+	}
+	ids := make([]byte, 0, len(c.protocols))
+	for id := range c.protocols {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Settings of the protocol, the finalizer settings if the protocol is not configured
+func (c *finalizerContext) protocolSettings(protocolId byte) protocolSettings {
+	if settings, ok := c.protocols[protocolId]; ok {
+		return settings
+	}
+	return protocolSettings{
+		voterThresholdBIPS:         c.voterThresholdBIPS,
+		thresholdBIPS:              c.thresholdBIPS,
+		expiredPolicyThresholdBIPS: c.expiredPolicyThresholdBIPS,
+		gracePeriodEndOffset:       c.gracePeriodEndOffset,
+	}
+}
+
+// Returns the reason the signed message of the payload item is malformed, empty if
+// it is valid or messages are not verified
+func (c *finalizerContext) verifyMessage(protocolId byte, votingRoundId uint32, payload *signedPayload) string {
+	if !c.verifyMessages {
+		return ""
+	}
+	if len(payload.rawMessage) != messageLength {
+		return malformedMessageLength
+	}
+	if payload.message.protocolId != protocolId {
+		return malformedMessageProtocol
+	}
+	if payload.message.votingRoundId != votingRoundId {
+		return malformedMessageVotingRound
+	}
+	// only the random number protocol signs messages with a random quality score
+	if settings, ok := c.protocols[protocolId]; ok && !settings.secureRandom && payload.message.randomQualityScore {
+		return malformedMessageRandomQuality
+	}
+	return ""
+}
+
+// Threshold of the signature weight of the protocol for the signing policy, expired
+// if the reward epoch of the policy ended and the next policy is not initialized yet.
+// The threshold is never below the threshold of the signing policy.
+func (s protocolSettings) threshold(sp *signingPolicy, expired bool) uint16 {
+	bips := s.thresholdBIPS
+	if expired {
+		bips = s.expiredPolicyThresholdBIPS
+		if bips == 0 {
+			bips = defaultExpiredPolicyThresholdBIPS
+		}
+	}
+	return max(sp.threshold, uint16(uint32(sp.voters.TotalWeight())*uint32(bips)/10000))
 }
@@ -0,0 +1,101 @@
+package finalizer
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/utils"
+	"flare-tlc/utils/epoch"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillBounds(t *testing.T) {
+	votingEpoch := utils.NewEpoch(time.Unix(0, 0), 90*time.Second)
+	epochs := epoch.NewSchedule(votingEpoch, utils.NewIntEpoch(0, 100))
+	now := votingEpoch.StartTime(1050)
+
+	// reward epoch 10, logs are fetched from the start of reward epoch 9
+	firstVotingRound, offset := backfillBounds(1020, 0, now, 0, epochs)
+	require.EqualValues(t, 1021, firstVotingRound)
+	require.Equal(t, now.Sub(votingEpoch.StartTime(900)), offset)
+
+	// rounds outside the finalization window of 3 reward epochs are skipped
+	firstVotingRound, offset = backfillBounds(120, 3, now, 0, epochs)
+	require.EqualValues(t, 700, firstVotingRound)
+	require.Equal(t, now.Sub(votingEpoch.StartTime(600)), offset)
+
+	// offset is limited by max offset
+	_, offset = backfillBounds(120, 0, now, time.Hour, epochs)
+	require.Equal(t, time.Hour, offset)
+}
+
+func TestProtocolSettings(t *testing.T) {
+	cfg := &config.FinalizerConfig{
+		VoterThresholdBIPS:   500,
+		GracePeriodEndOffset: 40 * time.Second,
+	}
+	c := &finalizerContext{voterThresholdBIPS: 500, gracePeriodEndOffset: 40 * time.Second}
+	c.protocols = newProtocolSettings(cfg)
+	require.True(t, c.protocolEnabled(100))
+	require.True(t, c.protocolEnabled(200))
+
+	cfg.Protocols = []config.FinalizerProtocolConfig{
+		{Id: 100},
+		{Id: 200, VoterThresholdBIPS: 1000, GracePeriodEndOffset: 60 * time.Second},
+	}
+	c.protocols = newProtocolSettings(cfg)
+	require.True(t, c.protocolEnabled(100))
+	require.True(t, c.protocolEnabled(200))
+	require.False(t, c.protocolEnabled(1))
+	require.Equal(t, protocolSettings{voterThresholdBIPS: 500, gracePeriodEndOffset: 40 * time.Second}, c.protocolSettings(100))
+	require.Equal(t, protocolSettings{voterThresholdBIPS: 1000, gracePeriodEndOffset: 60 * time.Second}, c.protocolSettings(200))
+}
+
+func TestProtocolThreshold(t *testing.T) {
+	cfg := &config.FinalizerConfig{
+		Protocols: []config.FinalizerProtocolConfig{
+			{Id: 100},
+			{Id: 200, ThresholdBIPS: 6000, ExpiredPolicyThresholdBIPS: 7000},
+			{Id: 201, ThresholdBIPS: 4000},
+		},
+	}
+	c := &finalizerContext{protocols: newProtocolSettings(cfg)}
+	sp := newSigningPolicy(newTestPolicyData(1, 10)) // total weight 200, threshold 100
+
+	require.EqualValues(t, 100, c.protocolSettings(100).threshold(sp, false))
+	require.EqualValues(t, 120, c.protocolSettings(100).threshold(sp, true))
+	require.EqualValues(t, 120, c.protocolSettings(200).threshold(sp, false))
+	require.EqualValues(t, 140, c.protocolSettings(200).threshold(sp, true))
+
+	// not below the threshold of the signing policy
+	require.EqualValues(t, 100, c.protocolSettings(201).threshold(sp, false))
+}
+
+func TestVerifyMessage(t *testing.T) {
+	c := &finalizerContext{verifyMessages: true}
+	c.protocols = newProtocolSettings(&config.FinalizerConfig{
+		Protocols: []config.FinalizerProtocolConfig{{Id: 100, SecureRandom: true}, {Id: 200}},
+	})
+	rawMessage := make([]byte, messageLength)
+	payload := func(protocolId byte, votingRoundId uint32, randomQualityScore bool) *signedPayload {
+		return &signedPayload{
+			message: &submittedPayload{
+				protocolId: protocolId, votingRoundId: votingRoundId, randomQualityScore: randomQualityScore,
+			},
+			rawMessage: rawMessage,
+		}
+	}
+
+	require.Empty(t, c.verifyMessage(100, 10, payload(100, 10, true)))
+	require.Empty(t, c.verifyMessage(200, 10, payload(200, 10, false)))
+	require.Equal(t, malformedMessageRandomQuality, c.verifyMessage(200, 10, payload(200, 10, true)))
+	require.Equal(t, malformedMessageProtocol, c.verifyMessage(100, 10, payload(200, 10, false)))
+	require.Equal(t, malformedMessageVotingRound, c.verifyMessage(100, 10, payload(100, 11, false)))
+	short := payload(100, 10, false)
+	short.rawMessage = rawMessage[:messageLength-1]
+	require.Equal(t, malformedMessageLength, c.verifyMessage(100, 10, short))
+
+	c.verifyMessages = false
+	require.Empty(t, c.verifyMessage(200, 10, payload(200, 11, true)))
+}
@@ -2,19 +2,25 @@ package finalizer
 
 import (
 	"context"
-	"flare-tlc/logger"
+	flarelogger "flare-tlc/logger"
 	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/credentials"
 	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"golang.org/x/exp/slices"
+	"golang.org/x/sync/errgroup"
 )
 
 const (
 	finalizerQueueProcessorInterval = 100 * time.Millisecond
+	finalizerWorkerBufferSize       = 64
 )
 
 type queueItem struct {
@@ -22,6 +28,47 @@ type queueItem struct {
 	votingRoundId uint32
 	protocolId    byte
 	messageHash   common.Hash
+
+	// true if the item was persisted before the client restarted
+	restored bool
+
+	// true if the item was queued manually, it is finalized immediately
+	forced bool
+
+	// true if the message of a secure random protocol has random quality score 0, it is
+	// relayed after the grace period if no secure message of the voting round was relayed
+	secondary bool
+}
+
+type queueItemKey struct {
+	votingRoundId uint32
+	protocolId    byte
+	messageHash   common.Hash
+}
+
+func (i *queueItem) key() queueItemKey {
+	return queueItemKey{
+		votingRoundId: i.votingRoundId,
+		protocolId:    i.protocolId,
+		messageHash:   i.messageHash,
+	}
+}
+
+type relayedRoundKey struct {
+	votingRoundId uint32
+	protocolId    byte
+}
+
+func (i *queueItem) roundKey() relayedRoundKey {
+	return relayedRoundKey{
+		votingRoundId: i.votingRoundId,
+		protocolId:    i.protocolId,
+	}
+}
+
+// Logger with the voting round and protocol of the item
+func (i *queueItem) logger() *flarelogger.Logger {
+	return logger.With("votingRoundId", i.votingRoundId, "protocolId", i.protocolId)
 }
 
 func (i *queueItem) String() string {
@@ -35,17 +82,38 @@ type finalizerQueue struct {
 }
 
 type finalizerQueueProcessor struct {
-	db            finalizerDB
+	db            FinalizerDB
 	queue         *finalizerQueue
 	delayedQueues *utils.DelayedQueueManager[*queueItem]
 
 	submissionStorage *submissionStorage
 	relayClient       *relayContractClient
 	finalizerContext  *finalizerContext
+	clock             utils.Clock
+	tracer            *roundTracer
+
+	// optional durable storage of pending items, nil if persistence is disabled
+	store finalizerQueueStore
+
+	// items loaded from the store on startup, waiting to reach the threshold again
+	restored      map[queueItemKey]bool
+	restoredMutex sync.Mutex
+
+	// rounds finalized on chain, filled by the ProtocolMessageRelayed listener
+	// and by the pre-flight checks
+	relayed      map[relayedRoundKey]bool
+	relayedMutex sync.Mutex
+
+	// if set, queued items are not processed until resumed
+	paused atomic.Bool
+
+	// delayed items whose grace period ended while paused
+	pausedDelayed      []*queueItem
+	pausedDelayedMutex sync.Mutex
 }
 
 func newFinalizerQueueProcessor(
-	db finalizerDB,
+	db FinalizerDB,
 	submissionStorage *submissionStorage,
 	relayClient *relayContractClient,
 	finalizerContext *finalizerContext,
@@ -55,13 +123,23 @@ func newFinalizerQueueProcessor(
 		submissionStorage: submissionStorage,
 		relayClient:       relayClient,
 		queue:             newFinalizerQueue(),
+		restored:          make(map[queueItemKey]bool),
+		relayed:           make(map[relayedRoundKey]bool),
 
 		finalizerContext: finalizerContext,
+		clock:            utils.RealClock,
+		tracer:           newRoundTracer(utils.RealClock),
 	}
-	qp.delayedQueues = utils.NewDelayedQueueManager[*queueItem](qp.processDelayedQueue)
+	qp.delayedQueues = utils.NewDelayedQueueManager[*queueItem](qp.processDelayedQueue, qp.clock)
 	return qp
 }
 
+func (p *finalizerQueueProcessor) setClock(clock utils.Clock) {
+	p.clock = clock
+	p.tracer.clock = clock
+	p.delayedQueues = utils.NewDelayedQueueManager[*queueItem](p.processDelayedQueue, clock)
+}
+
 func newFinalizerQueue() *finalizerQueue {
 	return &finalizerQueue{
 		queue: make([]*queueItem, 0, 256),
@@ -75,6 +153,14 @@ func (q *finalizerQueue) Add(item *queueItem) {
 	q.queue = append(q.queue, item)
 }
 
+// Returns a copy of the queued items
+func (q *finalizerQueue) Items() []*queueItem {
+	q.Lock()
+	defer q.Unlock()
+
+	return append([]*queueItem(nil), q.queue...)
+}
+
 func (q *finalizerQueue) Pop() *queueItem {
 	q.Lock()
 	defer q.Unlock()
@@ -90,26 +176,159 @@ func (q *finalizerQueue) Pop() *queueItem {
 }
 
 func (p *finalizerQueueProcessor) Add(item *submitterPayloadItem, seed *big.Int) {
-	p.queue.Add(&queueItem{
+	qItem := &queueItem{
 		seed:          seed,
 		votingRoundId: item.votingRoundId,
 		protocolId:    item.protocolId,
 		messageHash:   item.payload.messageHash,
-	})
+		secondary: p.finalizerContext.protocolSettings(item.protocolId).secureRandom &&
+			!item.payload.message.randomQualityScore,
+	}
+
+	p.restoredMutex.Lock()
+	if p.restored[qItem.key()] {
+		qItem.restored = true
+		delete(p.restored, qItem.key())
+	}
+	p.restoredMutex.Unlock()
+
+	p.queue.Add(qItem)
+	p.tracer.thresholdReached(qItem.roundKey())
+
+	if p.store != nil {
+		if err := p.store.Save(qItem); err != nil {
+			logger.Warn("Error persisting finalizer queue item %v: %v", qItem, err)
+		}
+	}
+}
+
+// Load pending items persisted before the restart. Signatures are not persisted,
+// restored items are queued again once the submission listener collects enough
+// signatures, and are then finalized even if the grace period already ended.
+func (p *finalizerQueueProcessor) RestorePending() error {
+	if p.store == nil {
+		return nil
+	}
+	items, err := p.store.Load()
+	if err != nil {
+		return err
+	}
+
+	p.restoredMutex.Lock()
+	defer p.restoredMutex.Unlock()
+
+	for _, item := range items {
+		p.restored[item.key()] = true
+	}
+	logger.Info("Restored %d pending finalizer queue items", len(items))
+	return nil
+}
+
+// Remove restored and persisted items with voting round id <= votingRoundId
+func (p *finalizerQueueProcessor) RemoveUpTo(votingRoundId uint32) {
+	p.restoredMutex.Lock()
+	for key := range p.restored {
+		if key.votingRoundId <= votingRoundId {
+			delete(p.restored, key)
+		}
+	}
+	p.restoredMutex.Unlock()
+
+	p.relayedMutex.Lock()
+	for key := range p.relayed {
+		if key.votingRoundId <= votingRoundId {
+			delete(p.relayed, key)
+		}
+	}
+	p.relayedMutex.Unlock()
+
+	p.tracer.RemoveUpTo(votingRoundId)
+
+	if p.store != nil {
+		if err := p.store.DeleteUpTo(votingRoundId); err != nil {
+			logger.Warn("Error removing persisted finalizer queue items: %v", err)
+		}
+	}
+}
+
+// Mark the voting rounds as finalized, queued items for these rounds are dropped
+func (p *finalizerQueueProcessor) MarkRelayed(keys []queueItemKey) {
+	p.relayedMutex.Lock()
+	defer p.relayedMutex.Unlock()
+
+	for _, key := range keys {
+		roundKey := relayedRoundKey{votingRoundId: key.votingRoundId, protocolId: key.protocolId}
+		p.relayed[roundKey] = true
+		p.tracer.finish(roundKey, traceOutcomeRelayedOnChain)
+	}
+}
+
+// Returns true if the item's voting round was already finalized by any finalizer.
+// Relayed events seen by the listener are checked first, then the merkle root
+// is queried from the relay contract. On query errors the item is not dropped,
+// an already relayed message is then detected when sending the relay tx.
+func (p *finalizerQueueProcessor) isRelayed(item *queueItem) bool {
+	p.relayedMutex.Lock()
+	relayed := p.relayed[item.roundKey()]
+	p.relayedMutex.Unlock()
+	if relayed {
+		return true
+	}
+
+	relayed, err := p.relayClient.IsRelayed(item.protocolId, item.votingRoundId)
+	if err != nil {
+		logger.Warn("Error checking if item %v is already relayed: %v", item, err)
+		return false
+	}
+	if relayed {
+		p.relayedMutex.Lock()
+		p.relayed[item.roundKey()] = true
+		p.relayedMutex.Unlock()
+	}
+	return relayed
+}
+
+// Drop the item if its voting round was already finalized
+func (p *finalizerQueueProcessor) dropIfRelayed(item *queueItem) bool {
+	if !p.isRelayed(item) {
+		return false
+	}
+	item.logger().Info("Voting round already finalized, dropping item %v", item)
+	p.tracer.finish(item.roundKey(), traceOutcomeRelayedOnChain)
+	finalizationsLost.WithLabelValues(strconv.Itoa(int(item.protocolId))).Inc()
+	p.removePersisted(item)
+	return true
+}
+
+func (p *finalizerQueueProcessor) removePersisted(item *queueItem) {
+	if p.store == nil {
+		return
+	}
+	if err := p.store.Delete(item); err != nil {
+		logger.Warn("Error removing persisted finalizer queue item %v: %v", item, err)
+	}
 }
 
 // Infinite loop, should be run in a goroutine
 func (p *finalizerQueueProcessor) Run(ctx context.Context) error {
-	ticker := time.NewTicker(finalizerQueueProcessorInterval)
+	if p.finalizerContext.queueWorkers > 1 {
+		return p.runWorkers(ctx, p.finalizerContext.queueWorkers)
+	}
+
+	ticker := p.clock.NewTicker(finalizerQueueProcessorInterval)
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			break
 
 		case <-ctx.Done():
+			p.flushPending()
 			logger.Info("Finalizer queue processor stopped")
 			return ctx.Err()
 		}
+		if p.paused.Load() {
+			continue
+		}
 
 		item := p.queue.Pop()
 
@@ -117,23 +336,152 @@ func (p *finalizerQueueProcessor) Run(ctx context.Context) error {
 			continue
 		}
 
-		if p.isVoterForCurrentEpoch(item) {
-			logger.Info("Finalizer with address %v was selected for item %v", p.relayClient.senderAddress, item)
+		p.handleItem(ctx, item)
+	}
+}
+
+// Process queue items with n parallel workers. Items are assigned to workers by
+// protocol id, so items of the same protocol are always processed by the same
+// worker, in the order they were added to the queue.
+func (p *finalizerQueueProcessor) runWorkers(ctx context.Context, n int) error {
+	eg, ctx := errgroup.WithContext(ctx)
+
+	workers := make([]chan *queueItem, n)
+	for i := range workers {
+		workerChan := make(chan *queueItem, finalizerWorkerBufferSize)
+		workers[i] = workerChan
+
+		eg.Go(func() error {
+			for {
+				select {
+				case item := <-workerChan:
+					p.handleItem(ctx, item)
+
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		})
+	}
+
+	eg.Go(func() error {
+		ticker := p.clock.NewTicker(finalizerQueueProcessorInterval)
+		for {
+			select {
+			case <-ticker.C():
+				break
+
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if p.paused.Load() {
+				continue
+			}
 
-			p.processItem(ctx, item, false)
-		} else {
-			logger.Info("Finalizer with address %v will send outside grace period for item %v", p.relayClient.senderAddress, item)
+			for item := p.queue.Pop(); item != nil; item = p.queue.Pop() {
+				select {
+				case workers[int(item.protocolId)%n] <- item:
+					break
 
-			data := p.submissionStorage.Get(item.votingRoundId, item.protocolId, item.messageHash)
-			if data != nil {
-				// Finalization for a votingRoundId should happen in the following voting round votingRoundId + 1
-				votingRoundStartTime := p.finalizerContext.votingEpoch.StartTime(int64(item.votingRoundId + 1))
-				st := votingRoundStartTime.Add(p.finalizerContext.gracePeriodEndOffset)
-				logger.Info("Finalizer will send item %v at %v", item, st)
-				p.delayedQueues.Add(st, item)
+				case <-ctx.Done():
+					return ctx.Err()
+				}
 			}
 		}
+	})
+
+	err := eg.Wait()
+	for _, workerChan := range workers {
+		close(workerChan)
+		for item := range workerChan {
+			p.queue.Add(item)
+		}
 	}
+	p.flushPending()
+	logger.Info("Finalizer queue processor stopped")
+	return err
+}
+
+// Called on shutdown: stops the delayed queue timers and makes sure pending items
+// are persisted, so they are retried after a restart.
+func (p *finalizerQueueProcessor) flushPending() {
+	pending := p.delayedQueues.Close()
+	for item := p.queue.Pop(); item != nil; item = p.queue.Pop() {
+		pending = append(pending, item)
+	}
+	if len(pending) == 0 {
+		return
+	}
+	if p.store == nil {
+		logger.Warn("Finalizer stopped with %d pending items, enable persistent_queue to retry them after restart", len(pending))
+		return
+	}
+	for _, item := range pending {
+		if err := p.store.Save(item); err != nil {
+			logger.Error("Error persisting finalizer queue item %v: %v", item, err)
+		}
+	}
+	logger.Info("Finalizer stopped with %d pending items persisted", len(pending))
+}
+
+// Submit the item immediately if the finalizer was selected for it,
+// otherwise schedule it for submission after the grace period (unless
+// only_when_selected is set). Secondary items are always scheduled after
+// the grace period, so that a secure message of the voting round is relayed first.
+func (p *finalizerQueueProcessor) handleItem(ctx context.Context, item *queueItem) {
+	itemLogger := item.logger()
+	if item.forced {
+		itemLogger.Info("Finalizer processes manually queued item %v", item)
+
+		p.processItem(ctx, item, false)
+		return
+	}
+	selected := p.isVoterForCurrentEpoch(item)
+	if !selected && p.finalizerContext.onlyWhenSelected {
+		itemLogger.Info("Finalizer with address %v was not selected for item %v, skipping", p.relayClient.signer.Address(), item)
+		p.tracer.finish(item.roundKey(), traceOutcomeNotSelected)
+		p.removePersisted(item)
+	} else if item.secondary {
+		itemLogger.Info("Finalizer will send secondary item %v outside grace period, message is not secure random", item)
+
+		p.scheduleDelayed(item, !selected)
+	} else if selected {
+		itemLogger.Info("Finalizer with address %v was selected for item %v", p.relayClient.signer.Address(), item)
+
+		p.processItem(ctx, item, false)
+	} else {
+		itemLogger.Info("Finalizer with address %v will send outside grace period for item %v", p.relayClient.signer.Address(), item)
+
+		p.scheduleDelayed(item, true)
+	}
+}
+
+// Schedules the item for submission at the end of the grace period, with the random
+// delay of backup finalizations if backup is set
+func (p *finalizerQueueProcessor) scheduleDelayed(item *queueItem, backup bool) {
+	itemLogger := item.logger()
+	data := p.submissionStorage.Get(item.votingRoundId, item.protocolId, item.messageHash)
+	if data == nil {
+		return
+	}
+	// Finalization for a votingRoundId should happen in the following voting round votingRoundId + 1
+	votingRoundStartTime := p.finalizerContext.votingEpoch.StartTime(int64(item.votingRoundId + 1))
+	st := votingRoundStartTime.Add(p.finalizerContext.protocolSettings(item.protocolId).gracePeriodEndOffset)
+	if backup && p.finalizerContext.backupRandomDelay > 0 {
+		// spread backup finalizers over time, so that only the first one sends
+		// the relay tx and the others drop the item as already relayed
+		st = st.Add(utils.RandomDuration(p.finalizerContext.backupRandomDelay))
+	}
+	if item.restored && st.Before(p.clock.Now()) {
+		// Grace period ended while the client was not running
+		itemLogger.Info("Finalizer processes restored item %v", item)
+		if err := p.processDelayedQueue([]*queueItem{item}); err != nil {
+			itemLogger.Error("Error processing restored item %v: %v", item, err)
+		}
+		return
+	}
+	itemLogger.Info("Finalizer will send item %v at %v", item, st)
+	p.delayedQueues.Add(st, item)
 }
 
 func (p *finalizerQueueProcessor) isVoterForCurrentEpoch(item *queueItem) bool {
@@ -145,23 +493,36 @@ func (p *finalizerQueueProcessor) isVoterForCurrentEpoch(item *queueItem) bool {
 		return false
 	}
 
-	voters, err := data.signingPolicy.voters.SelectVoters(item.seed, item.protocolId, item.votingRoundId, p.finalizerContext.voterThresholdBIPS)
+	thresholdBIPS := p.finalizerContext.protocolSettings(item.protocolId).voterThresholdBIPS
+	voters, err := data.signingPolicy.voters.SelectVoters(item.seed, item.protocolId, item.votingRoundId, thresholdBIPS)
 	if err != nil {
 		return false
 	}
 
 	logger.Debug("Finalizer voters for item %v: %v", item, voters)
 
-	return voters.Contains(p.relayClient.senderAddress)
+	// the signing policy address of the reward epoch, the key may be rotated
+	signer := credentials.SignerForRewardEpoch(p.relayClient.signer, data.signingPolicy.rewardEpochId)
+	return voters.Contains(signer.Address())
 }
 
-func (p *finalizerQueueProcessor) processItem(ctx context.Context, item *queueItem, isDelayed bool) {
+// Returns true if the relay tx was sent or the message was already relayed
+func (p *finalizerQueueProcessor) processItem(ctx context.Context, item *queueItem, isDelayed bool) bool {
 	if item == nil {
-		return
+		return false
 	}
 	data := p.submissionStorage.Get(item.votingRoundId, item.protocolId, item.messageHash)
 	if data == nil {
-		return
+		return false
+	}
+	if p.dropIfRelayed(item) {
+		return true
+	}
+	if chain.Standby() {
+		// the leader instance relays the message
+		item.logger().Debug("Instance on standby, not sending relay tx of item %v", item)
+		p.tracer.finish(item.roundKey(), traceOutcomeStandby)
+		return false
 	}
 
 	payloads := make([]*signedPayload, 0, len(data.payload))
@@ -171,32 +532,56 @@ func (p *finalizerQueueProcessor) processItem(ctx context.Context, item *queueIt
 		}
 	}
 
-	// (sort decreasing by weight)
-	slices.SortFunc(payloads, func(p, q *signedPayload) bool {
-		return data.signingPolicy.voters.VoterWeight(p.index) > data.signingPolicy.voters.VoterWeight(q.index)
-	})
+	signaturesPerVotingRound.WithLabelValues(strconv.Itoa(int(item.protocolId))).Observe(float64(len(payloads)))
 
-	// greedy select until threshold is reached
-	weight := uint16(0)
-	var selected []*signedPayload
-	for _, payload := range payloads {
-		weight += data.signingPolicy.voters.VoterWeight(payload.index)
-		selected = append(selected, payload)
-		if weight > data.signingPolicy.threshold {
-			break
-		}
+	selected := selectSignatures(p.finalizerContext.signatureSelection, payloads, data.signingPolicy, data.threshold)
+
+	item.logger().Debug("Relay tx of item %v is sent from %v", item, p.relayClient.sender(item.protocolId).Address())
+	relayed := p.tracer.relay(item.roundKey(), isDelayed, len(selected))
+	if p.relayClient.SubmitPayloads(ctx, selected, data.signingPolicy, isDelayed) {
+		relayed(true)
+		p.removePersisted(item)
+		return true
 	}
+	relayed(false)
+	return false
+}
 
-	// sort selected payloads by index
-	slices.SortFunc(selected, func(p, q *signedPayload) bool {
-		return p.index < q.index
-	})
+func (p *finalizerQueueProcessor) Pause() {
+	p.paused.Store(true)
+}
+
+// Resume processing, delayed items whose grace period ended while paused are processed immediately
+func (p *finalizerQueueProcessor) Resume() {
+	p.paused.Store(false)
+
+	p.pausedDelayedMutex.Lock()
+	items := p.pausedDelayed
+	p.pausedDelayed = nil
+	p.pausedDelayedMutex.Unlock()
+
+	if len(items) > 0 {
+		go func() {
+			if err := p.processDelayedQueue(items); err != nil {
+				logger.Error("Error processing delayed items after resume: %v", err)
+			}
+		}()
+	}
+}
 
-	p.relayClient.SubmitPayloads(ctx, selected, data.signingPolicy, isDelayed)
+func (p *finalizerQueueProcessor) Paused() bool {
+	return p.paused.Load()
 }
 
 func (p *finalizerQueueProcessor) processDelayedQueue(items []*queueItem) error {
-	now := time.Now()
+	if p.paused.Load() {
+		p.pausedDelayedMutex.Lock()
+		p.pausedDelayed = append(p.pausedDelayed, items...)
+		p.pausedDelayedMutex.Unlock()
+		return nil
+	}
+
+	now := p.clock.Now()
 	currentEpoch := p.finalizerContext.votingEpoch.EpochIndex(now)
 	startTime := p.finalizerContext.votingEpoch.StartTime(currentEpoch)
 
@@ -205,11 +590,26 @@ func (p *finalizerQueueProcessor) processDelayedQueue(items []*queueItem) error
 		return err
 	}
 
+	// secure messages of a voting round are relayed before its secondary messages
+	sort.SliceStable(items, func(i, j int) bool {
+		return !items[i].secondary && items[j].secondary
+	})
 	for _, item := range items {
-		if relayedItems.Contains(*item) {
+		if relayedItems.Contains(item.key()) {
+			p.tracer.finish(item.roundKey(), traceOutcomeRelayedOnChain)
+			finalizationsLost.WithLabelValues(strconv.Itoa(int(item.protocolId))).Inc()
+			p.removePersisted(item)
+			continue
+		}
+		if chain.DailyBudgetExceeded() {
+			// backup finalizations are not critical, the selected finalizers relay the message
+			item.logger().Warn("Daily tx budget exceeded, skipping backup finalization of item %v", item)
+			backupFinalizationsSkipped.WithLabelValues(strconv.Itoa(int(item.protocolId))).Inc()
+			p.tracer.finish(item.roundKey(), traceOutcomeBudgetExceeded)
+			p.removePersisted(item)
 			continue
 		}
-		logger.Info("Finalizer processes delayed queue item %v", item)
+		item.logger().Info("Finalizer processes delayed queue item %v", item)
 		p.processItem(context.TODO(), item, true)
 	}
 	return nil
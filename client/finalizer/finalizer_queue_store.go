@@ -0,0 +1,78 @@
+package finalizer
+
+import (
+	"flare-tlc/database"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// Durable storage for pending finalizations, so that items that reached the
+// threshold but were not yet relayed can be retried after a restart.
+type finalizerQueueStore interface {
+	Save(*queueItem) error
+	Delete(*queueItem) error
+	DeleteUpTo(votingRoundId uint32) error
+	Load() ([]*queueItem, error)
+}
+
+type finalizerQueueStoreDB struct {
+	db *gorm.DB
+}
+
+func newFinalizerQueueStoreDB(db *gorm.DB) (*finalizerQueueStoreDB, error) {
+	if err := db.AutoMigrate(&database.FinalizerQueueItem{}); err != nil {
+		return nil, fmt.Errorf("error migrating finalizer queue table: %w", err)
+	}
+	return &finalizerQueueStoreDB{db: db}, nil
+}
+
+func (s *finalizerQueueStoreDB) Save(item *queueItem) error {
+	seed := "0"
+	if item.seed != nil {
+		seed = item.seed.String()
+	}
+	return database.CreateFinalizerQueueItem(s.db, &database.FinalizerQueueItem{
+		VotingRoundId: item.votingRoundId,
+		ProtocolId:    item.protocolId,
+		MessageHash:   hashToDBString(item.messageHash),
+		Seed:          seed,
+	})
+}
+
+func (s *finalizerQueueStoreDB) Delete(item *queueItem) error {
+	return database.DeleteFinalizerQueueItem(s.db, item.votingRoundId, item.protocolId, hashToDBString(item.messageHash))
+}
+
+func (s *finalizerQueueStoreDB) DeleteUpTo(votingRoundId uint32) error {
+	return database.DeleteFinalizerQueueItemsUpTo(s.db, votingRoundId)
+}
+
+func (s *finalizerQueueStoreDB) Load() ([]*queueItem, error) {
+	dbItems, err := database.FetchFinalizerQueueItems(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*queueItem, 0, len(dbItems))
+	for _, dbItem := range dbItems {
+		seed, ok := new(big.Int).SetString(dbItem.Seed, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid seed %s for persisted finalizer queue item %d", dbItem.Seed, dbItem.ID)
+		}
+		items = append(items, &queueItem{
+			seed:          seed,
+			votingRoundId: dbItem.VotingRoundId,
+			protocolId:    dbItem.ProtocolId,
+			messageHash:   common.HexToHash(dbItem.MessageHash),
+		})
+	}
+	return items, nil
+}
+
+func hashToDBString(hash common.Hash) string {
+	return strings.TrimPrefix(hash.Hex(), "0x")
+}
@@ -0,0 +1,204 @@
+package finalizer
+
+import (
+	"bytes"
+	"context"
+	"flare-tlc/client/shared/voters"
+	"flare-tlc/utils"
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinalizerQueueRestorePending(t *testing.T) {
+	restoredItem := &queueItem{
+		seed:          big.NewInt(1),
+		votingRoundId: 10,
+		protocolId:    100,
+		messageHash:   common.HexToHash("0x01"),
+	}
+	store := newTestQueueStore(restoredItem)
+
+	p := newFinalizerQueueProcessor(nil, newSubmissionStorage(0), nil, &finalizerContext{})
+	p.store = store
+
+	err := p.RestorePending()
+	require.NoError(t, err)
+
+	p.Add(&submitterPayloadItem{
+		protocolId:    restoredItem.protocolId,
+		votingRoundId: restoredItem.votingRoundId,
+		payload:       &signedPayload{messageHash: restoredItem.messageHash},
+	}, restoredItem.seed)
+	p.Add(&submitterPayloadItem{
+		protocolId:    restoredItem.protocolId,
+		votingRoundId: restoredItem.votingRoundId + 1,
+		payload:       &signedPayload{messageHash: restoredItem.messageHash},
+	}, restoredItem.seed)
+
+	first := p.queue.Pop()
+	require.NotNil(t, first)
+	require.True(t, first.restored)
+
+	second := p.queue.Pop()
+	require.NotNil(t, second)
+	require.False(t, second.restored)
+
+	require.Len(t, store.items, 2)
+
+	p.RemoveUpTo(restoredItem.votingRoundId)
+	require.Len(t, store.items, 1)
+	require.Empty(t, p.restored)
+}
+
+type testQueueStore struct {
+	items map[queueItemKey]*queueItem
+	mu    sync.Mutex
+}
+
+func newTestQueueStore(items ...*queueItem) *testQueueStore {
+	s := &testQueueStore{items: make(map[queueItemKey]*queueItem)}
+	for _, item := range items {
+		s.items[item.key()] = item
+	}
+	return s
+}
+
+func (s *testQueueStore) Save(item *queueItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[item.key()] = item
+	return nil
+}
+
+func (s *testQueueStore) Delete(item *queueItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.items, item.key())
+	return nil
+}
+
+func (s *testQueueStore) DeleteUpTo(votingRoundId uint32) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.items {
+		if key.votingRoundId <= votingRoundId {
+			delete(s.items, key)
+		}
+	}
+	return nil
+}
+
+func (s *testQueueStore) Load() ([]*queueItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]*queueItem, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func TestFinalizerQueueIsRelayed(t *testing.T) {
+	ethClient := &testEthClient{
+		merkleRoots: map[relayedRoundKey]common.Hash{
+			{votingRoundId: 11, protocolId: 100}: common.HexToHash("0x02"),
+		},
+	}
+	relayClient := &relayContractClient{ethClient: ethClient}
+	store := newTestQueueStore()
+
+	p := newFinalizerQueueProcessor(nil, newSubmissionStorage(0), relayClient, &finalizerContext{})
+	p.store = store
+
+	notRelayed := &queueItem{votingRoundId: 10, protocolId: 100, messageHash: common.HexToHash("0x01")}
+	require.False(t, p.isRelayed(notRelayed))
+
+	// marked by the listener, with any merkle root
+	p.MarkRelayed([]queueItemKey{{votingRoundId: 10, protocolId: 100, messageHash: common.HexToHash("0x03")}})
+	require.True(t, p.isRelayed(notRelayed))
+
+	// confirmed on chain
+	relayedOnChain := &queueItem{votingRoundId: 11, protocolId: 100, messageHash: common.HexToHash("0x02")}
+	require.NoError(t, store.Save(relayedOnChain))
+	require.True(t, p.dropIfRelayed(relayedOnChain))
+	require.Empty(t, store.items)
+
+	p.RemoveUpTo(11)
+	require.Empty(t, p.relayed)
+}
+
+func TestFinalizerQueuePaused(t *testing.T) {
+	p := newFinalizerQueueProcessor(nil, newSubmissionStorage(0), nil, &finalizerContext{})
+	p.Pause()
+	require.True(t, p.Paused())
+
+	item := &queueItem{votingRoundId: 10, protocolId: 100}
+	require.NoError(t, p.processDelayedQueue([]*queueItem{item}))
+	require.Equal(t, []*queueItem{item}, p.pausedDelayed)
+}
+
+func TestFinalizerQueueSecondary(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	sp := &signingPolicy{
+		seed:   big.NewInt(1),
+		voters: voters.NewVoterSet([]common.Address{signer}, []uint16{100}),
+	}
+	fCtx := &finalizerContext{
+		votingEpoch: utils.NewEpoch(time.Now(), 90*time.Second),
+		protocols: map[byte]protocolSettings{
+			100: {voterThresholdBIPS: 5000, gracePeriodEndOffset: 40 * time.Second, secureRandom: true},
+			200: {gracePeriodEndOffset: 40 * time.Second},
+		},
+	}
+	ethClient := &testEthClient{}
+	relayClient := &relayContractClient{ethClient: ethClient, signer: credentials.NewPrivateKeySigner(privateKey)}
+	storage := newSubmissionStorage(0)
+	p := newFinalizerQueueProcessor(nil, storage, relayClient, fCtx)
+
+	add := func(protocolId byte, secure bool) *queueItem {
+		data, err := encodeSignedPayload(privateKey, &signedPayload{
+			typeId: payloadTypeMessage,
+			message: &submittedPayload{
+				protocolId:         protocolId,
+				votingRoundId:      10,
+				randomQualityScore: secure,
+				merkleRoot:         bytes.Repeat([]byte{0x02}, 32),
+			},
+		})
+		require.NoError(t, err)
+		payload, err := decodeSignedPayload(data)
+		require.NoError(t, err)
+		_, err = storage.Add(payload, sp, 50, 10)
+		require.NoError(t, err)
+		p.Add(&submitterPayloadItem{protocolId: protocolId, votingRoundId: 10, payload: payload}, sp.seed)
+		return p.queue.Pop()
+	}
+	secure := add(100, true)
+	require.False(t, secure.secondary)
+	require.False(t, add(200, false).secondary, "not a secure random protocol")
+
+	// selected, but scheduled after the grace period
+	secondary := add(100, false)
+	require.True(t, secondary.secondary)
+	require.NotEqual(t, secure.messageHash, secondary.messageHash)
+	require.True(t, p.isVoterForCurrentEpoch(secondary))
+	p.handleItem(context.Background(), secondary)
+	require.Empty(t, ethClient.sentTxs)
+	require.Equal(t, map[time.Time][]*queueItem{
+		fCtx.votingEpoch.StartTime(11).Add(40 * time.Second): {secondary},
+	}, p.delayedQueues.Items())
+	p.delayedQueues.Close()
+}
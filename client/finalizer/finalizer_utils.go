@@ -3,6 +3,7 @@ package finalizer
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"flare-tlc/client/shared"
 	"fmt"
 	"math"
@@ -12,8 +13,58 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
+// Malformations of the submitSignatures calldata. Errors of DecodeSubmitterPayload
+// are wrapped in a *payloadError with the offset of the malformed item.
 var (
-	errPayloadTooShort = fmt.Errorf("invalid payload length: too short")
+	errCalldataTooShort          = fmt.Errorf("calldata shorter than the function selector")
+	errTruncatedItemHeader       = fmt.Errorf("truncated payload item header")
+	errPayloadLengthExceeded     = fmt.Errorf("payload length exceeds the calldata")
+	errPayloadTooShort           = fmt.Errorf("invalid payload length: too short")
+	errUnknownPayloadType        = fmt.Errorf("unknown signature payload type")
+	errInvalidSignatureV         = fmt.Errorf("invalid signature v value")
+	errInvalidSignature          = fmt.Errorf("invalid signature")
+	errInvalidRandomQualityScore = fmt.Errorf("invalid random quality score value")
+	errItemHeaderMismatch        = fmt.Errorf("payload item header does not match the signed message")
+)
+
+// Length of the function selector and of the item header, protocol id (1),
+// voting round id (4) and payload length (2)
+const (
+	selectorLength   = 4
+	itemHeaderLength = 7
+	messageLength    = 38 // protocol id (1), voting round id (4), random quality score (1), merkle root (32)
+)
+
+// Reasons of malformed signed messages, see finalizerContext.verifyMessage
+const (
+	malformedMessageLength        = "length"
+	malformedMessageProtocol      = "protocol"
+	malformedMessageVotingRound   = "voting_round"
+	malformedMessageRandomQuality = "random_quality"
+)
+
+type payloadError struct {
+	offset int // of the item in the calldata
+	err    error
+}
+
+func (e *payloadError) Error() string {
+	return fmt.Sprintf("invalid submitSignatures payload at byte %d: %v", e.offset, e.err)
+}
+
+func (e *payloadError) Unwrap() error {
+	return e.err
+}
+
+// Signature payload types of the submitSignatures calldata. All types start with
+// the type (1 byte) and the message (38 bytes), followed by the signature of the
+// message (65 bytes) and optional unsigned additional data. Types 0 and 1 are
+// both used by the protocol data providers with the same layout.
+const (
+	payloadTypeMessage       byte = 0 // signature [V || R || S]
+	payloadTypeMessageV1     byte = 1 // signature [V || R || S]
+	payloadTypeMessageRSV    byte = 2 // signature [R || S || V], V is 0, 1, 27 or 28
+	payloadTypeLatestVersion      = payloadTypeMessageRSV
 )
 
 type submitterPayloadItem struct {
@@ -35,6 +86,9 @@ type signedPayload struct {
 
 	// index of voter in signing policy, updated when inserting it into storage
 	index int
+
+	// position in the order of arrival of the message signatures, updated when inserting it into storage
+	arrival int
 }
 
 type submittedPayload struct {
@@ -44,38 +98,66 @@ type submittedPayload struct {
 	merkleRoot         []byte
 }
 
+// Decodes the items of the submitSignatures calldata. Items of unknown payload types
+// are skipped and trailing zero bytes after the last item are ignored as padding.
 func DecodeSubmitterPayload(message []byte) ([]*submitterPayloadItem, error) {
 	if len(message) == 0 {
 		return nil, nil
 	}
+	if len(message) < selectorLength {
+		return nil, &payloadError{offset: 0, err: errCalldataTooShort}
+	}
 	var messages []*submitterPayloadItem
-	for i := 4; i < len(message); {
-		if len(message)-i < 7 {
-			return nil, fmt.Errorf("invalid payload length at index %d of %d", i, len(message))
+	for i := selectorLength; i < len(message); {
+		if isZeroPadding(message[i:]) {
+			break
+		}
+		offset := i
+		if len(message)-i < itemHeaderLength {
+			return nil, &payloadError{offset: offset, err: fmt.Errorf("%w: %d of %d bytes", errTruncatedItemHeader, len(message)-i, itemHeaderLength)}
 		}
 		protocolId := message[i]
-		i += 1
-		votingRoundId := binary.BigEndian.Uint32(message[i : i+4])
-		i += 4
-		payloadLength := int(binary.BigEndian.Uint16(message[i : i+2]))
-		i += 2
+		votingRoundId := binary.BigEndian.Uint32(message[i+1 : i+5])
+		payloadLength := int(binary.BigEndian.Uint16(message[i+5 : i+7]))
+		i += itemHeaderLength
 		if len(message)-i < payloadLength {
-			return nil, errPayloadTooShort
+			return nil, &payloadError{offset: offset, err: fmt.Errorf("%w: %d bytes, %d left", errPayloadLengthExceeded, payloadLength, len(message)-i)}
 		}
 		payload, err := decodeSignedPayload(message[i : i+payloadLength])
+		i += payloadLength
+		if errors.Is(err, errUnknownPayloadType) {
+			// payloads of later versions of the protocol, skip them and decode the rest
+			logger.Debug("Skipping payload of voting round %d, protocol %d: %v", votingRoundId, protocolId, err)
+			unknownPayloadTypes.Inc()
+			continue
+		}
 		if err != nil {
-			return nil, err
+			return nil, &payloadError{offset: offset, err: err}
+		}
+		if payload.message.protocolId != protocolId || payload.message.votingRoundId != votingRoundId {
+			return nil, &payloadError{offset: offset, err: fmt.Errorf(
+				"%w: protocol %d, voting round %d in the header, protocol %d, voting round %d signed", errItemHeaderMismatch,
+				protocolId, votingRoundId, payload.message.protocolId, payload.message.votingRoundId,
+			)}
 		}
 		messages = append(messages, &submitterPayloadItem{
 			protocolId:    protocolId,
 			votingRoundId: votingRoundId,
 			payload:       payload,
 		})
-		i += payloadLength
 	}
 	return messages, nil
 }
 
+func isZeroPadding(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Transform signature to be used by go-ethereum crypto.SigToPub:
 // transforms [V || R || S] to [R || S || V - 27]
 // No checks are performed, we assume that signature array has length 65
@@ -86,26 +168,43 @@ func transformSignature(signature []byte) (RSV [65]byte) {
 	return RSV
 }
 
+// Decodes the signature payload of one of the payload types, the signature is
+// normalized to [V || R || S] with V 27 or 28, as expected by the Relay contract
 func decodeSignedPayload(payload []byte) (*signedPayload, error) {
-	if len(payload) < 104 { // 104 = 1 + 38 + 65
+	if len(payload) < 1 {
 		return nil, errPayloadTooShort
 	}
+	typeId := payload[0]
+	if typeId > payloadTypeLatestVersion {
+		return nil, fmt.Errorf("%w %d", errUnknownPayloadType, typeId)
+	}
+	if len(payload) < 104 { // 104 = 1 + 38 + 65
+		return nil, fmt.Errorf("%w: %d bytes", errPayloadTooShort, len(payload))
+	}
 	rawMessage := payload[1:39]
 	message, err := decodeSubmittedPayload(rawMessage)
 	if err != nil {
 		return nil, err
 	}
 	signature := payload[39:104]
+	if typeId == payloadTypeMessageRSV {
+		signature, err = signatureFromRSV(signature)
+		if err != nil {
+			return nil, err
+		}
+	} else if signature[0] != 27 && signature[0] != 28 {
+		return nil, fmt.Errorf("%w: %d", errInvalidSignatureV, signature[0])
+	}
 
 	messageHash := accounts.TextHash(crypto.Keccak256(rawMessage))
 	transformedSignature := transformSignature(signature)
 	pk, err := crypto.SigToPub(messageHash, transformedSignature[:])
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", errInvalidSignature, err)
 	}
 	signer := crypto.PubkeyToAddress(*pk)
 	reponse := &signedPayload{
-		typeId:     payload[0],
+		typeId:     typeId,
 		message:    message,
 		rawMessage: rawMessage,
 		signature:  signature,
@@ -121,13 +220,28 @@ func decodeSignedPayload(payload []byte) (*signedPayload, error) {
 	return reponse, nil
 }
 
+// Transforms [R || S || V] with V 0, 1, 27 or 28 to [V || R || S] with V 27 or 28
+func signatureFromRSV(signature []byte) ([]byte, error) {
+	v := signature[64]
+	if v < 27 {
+		v += 27
+	}
+	if v != 27 && v != 28 {
+		return nil, fmt.Errorf("%w: %d", errInvalidSignatureV, signature[64])
+	}
+	vrs := make([]byte, 65)
+	vrs[0] = v
+	copy(vrs[1:], signature[:64])
+	return vrs, nil
+}
+
 func decodeSubmittedPayload(payload []byte) (*submittedPayload, error) {
-	if len(payload) < 38 { // 38 = 1 + 4 + 1 + 32
+	if len(payload) < messageLength {
 		return nil, errPayloadTooShort
 	}
 	rqs := payload[5]
 	if rqs != 0 && rqs != 1 {
-		return nil, fmt.Errorf("invalid random quality score value: %d", rqs)
+		return nil, fmt.Errorf("%w: %d", errInvalidRandomQualityScore, rqs)
 	}
 	return &submittedPayload{
 		protocolId:         payload[0],
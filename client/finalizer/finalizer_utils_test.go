@@ -0,0 +1,216 @@
+package finalizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Submitter payload item of the protocol and voting round with the encoded payload
+func encodeTestSubmitterItem(protocolId byte, votingRoundId uint32, payload []byte) []byte {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(protocolId)
+	_ = binary.Write(buf, binary.BigEndian, votingRoundId)
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(payload)))
+	buf.Write(payload)
+	return buf.Bytes()
+}
+
+func TestDecodeSignedPayloadTypes(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	message := &submittedPayload{
+		protocolId:    100,
+		votingRoundId: 10,
+		merkleRoot:    bytes.Repeat([]byte{0x02}, 32),
+	}
+	vrsPayload, err := encodeSignedPayload(privateKey, &signedPayload{typeId: payloadTypeMessage, message: message})
+	require.NoError(t, err)
+	vrsSignature := vrsPayload[39:104]
+
+	for _, typeId := range []byte{payloadTypeMessage, payloadTypeMessageV1} {
+		payload := append([]byte{typeId}, vrsPayload[1:]...)
+		decoded, err := decodeSignedPayload(append(payload, 0xaa))
+		require.NoError(t, err)
+		require.Equal(t, typeId, decoded.typeId)
+		require.Equal(t, signer, decoded.signer)
+		require.Equal(t, vrsSignature, decoded.signature)
+		require.Equal(t, []byte{0xaa}, decoded.additionalData)
+	}
+
+	// [R || S || V] signature with V 0 or 1 and 27 or 28
+	for _, offset := range []byte{27, 0} {
+		payload := append([]byte{payloadTypeMessageRSV}, vrsPayload[1:39]...)
+		payload = append(payload, vrsSignature[1:]...)
+		payload = append(payload, vrsSignature[0]-27+offset)
+		decoded, err := decodeSignedPayload(payload)
+		require.NoError(t, err)
+		require.Equal(t, signer, decoded.signer)
+		require.Equal(t, vrsSignature, decoded.signature, "signature is normalized to [V || R || S]")
+	}
+
+	invalid := append([]byte{payloadTypeMessage}, vrsPayload[1:]...)
+	invalid[39] = 1
+	_, err = decodeSignedPayload(invalid)
+	require.ErrorContains(t, err, "invalid signature v value")
+
+	_, err = decodeSignedPayload([]byte{payloadTypeLatestVersion + 1})
+	require.ErrorIs(t, err, errUnknownPayloadType)
+}
+
+func TestDecodeSubmitterPayloadSkipsUnknownTypes(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	payload, err := encodeSignedPayload(privateKey, &signedPayload{
+		typeId: payloadTypeMessage,
+		message: &submittedPayload{
+			protocolId:    100,
+			votingRoundId: 10,
+			merkleRoot:    bytes.Repeat([]byte{0x02}, 32),
+		},
+	})
+	require.NoError(t, err)
+
+	message := []byte{0xde, 0xad, 0xbe, 0xef}
+	message = append(message, encodeTestSubmitterItem(200, 10, []byte{0xf0, 0x01, 0x02})...)
+	message = append(message, encodeTestSubmitterItem(100, 10, payload)...)
+
+	items, err := DecodeSubmitterPayload(message)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.EqualValues(t, 100, items[0].protocolId)
+	require.Equal(t, crypto.PubkeyToAddress(privateKey.PublicKey), items[0].payload.signer)
+
+	// payloads of known types are still validated
+	message = append([]byte{0xde, 0xad, 0xbe, 0xef}, encodeTestSubmitterItem(100, 10, payload[:50])...)
+	_, err = DecodeSubmitterPayload(message)
+	require.ErrorIs(t, err, errPayloadTooShort)
+}
+
+func TestDecodeSubmitterPayloadMalformed(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	payload, err := encodeSignedPayload(privateKey, &signedPayload{
+		typeId: payloadTypeMessage,
+		message: &submittedPayload{
+			protocolId:    100,
+			votingRoundId: 10,
+			merkleRoot:    bytes.Repeat([]byte{0x02}, 32),
+		},
+	})
+	require.NoError(t, err)
+	selector := []byte{0xde, 0xad, 0xbe, 0xef}
+	item := encodeTestSubmitterItem(100, 10, payload)
+
+	invalidV := append([]byte(nil), payload...)
+	invalidV[39] = 1
+	invalidRQS := append([]byte(nil), payload...)
+	invalidRQS[6] = 2
+	invalidSignature := append([]byte(nil), payload...)
+	copy(invalidSignature[40:72], bytes.Repeat([]byte{0xff}, 32)) // r above the curve order
+
+	tests := []struct {
+		name     string
+		calldata []byte
+		offset   int
+		err      error
+	}{
+		{"selector", selector[:3], 0, errCalldataTooShort},
+		{"truncated header", append(append(selector, item...), item[:5]...), 4 + len(item), errTruncatedItemHeader},
+		{"truncated payload", append(selector, item[:len(item)-1]...), 4, errPayloadLengthExceeded},
+		{"too short", append(selector, encodeTestSubmitterItem(100, 10, payload[:103])...), 4, errPayloadTooShort},
+		{"v", append(selector, encodeTestSubmitterItem(100, 10, invalidV)...), 4, errInvalidSignatureV},
+		{"random quality score", append(selector, encodeTestSubmitterItem(100, 10, invalidRQS)...), 4, errInvalidRandomQualityScore},
+		{"signature", append(selector, encodeTestSubmitterItem(100, 10, invalidSignature)...), 4, errInvalidSignature},
+		{"protocol mismatch", append(selector, encodeTestSubmitterItem(101, 10, payload)...), 4, errItemHeaderMismatch},
+		{"voting round mismatch", append(selector, encodeTestSubmitterItem(100, 11, payload)...), 4, errItemHeaderMismatch},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := DecodeSubmitterPayload(test.calldata)
+			require.ErrorIs(t, err, test.err)
+			var perr *payloadError
+			require.True(t, errors.As(err, &perr))
+			require.Equal(t, test.offset, perr.offset)
+		})
+	}
+
+	// trailing zero bytes are padding
+	items, err := DecodeSubmitterPayload(append(append(selector, item...), make([]byte, 28)...))
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+}
+
+// Seed calldata of the fuzz targets: a valid item of each payload type, followed by
+// an unknown payload type and padding
+func fuzzSeedCalldata(f *testing.F) []byte {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(f, err)
+	calldata := []byte{0xde, 0xad, 0xbe, 0xef}
+	for _, typeId := range []byte{payloadTypeMessage, payloadTypeMessageV1} {
+		payload, err := encodeSignedPayload(privateKey, &signedPayload{
+			typeId: typeId,
+			message: &submittedPayload{
+				protocolId:    100,
+				votingRoundId: 10,
+				merkleRoot:    bytes.Repeat([]byte{typeId}, 32),
+			},
+		})
+		require.NoError(f, err)
+		calldata = append(calldata, encodeTestSubmitterItem(100, 10, payload)...)
+	}
+	calldata = append(calldata, encodeTestSubmitterItem(200, 10, []byte{0xf0, 0x01})...)
+	return append(calldata, make([]byte, 8)...)
+}
+
+func FuzzDecodeSubmitterPayload(f *testing.F) {
+	calldata := fuzzSeedCalldata(f)
+	f.Add(calldata)
+	f.Add(calldata[:20])
+	f.Add(calldata[:4])
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, calldata []byte) {
+		items, err := DecodeSubmitterPayload(calldata)
+		if err != nil {
+			var perr *payloadError
+			require.True(t, errors.As(err, &perr), "error %v is not a payload error", err)
+			require.LessOrEqual(t, perr.offset, len(calldata))
+			require.Nil(t, items)
+			return
+		}
+		for _, item := range items {
+			require.Equal(t, item.protocolId, item.payload.message.protocolId)
+			require.Equal(t, item.votingRoundId, item.payload.message.votingRoundId)
+			require.Len(t, item.payload.rawMessage, 38)
+			require.Len(t, item.payload.signature, 65)
+			require.Contains(t, []byte{27, 28}, item.payload.signature[0])
+		}
+	})
+}
+
+func FuzzDecodeSignedPayload(f *testing.F) {
+	calldata := fuzzSeedCalldata(f)
+	payload := calldata[4+7 : 4+7+104]
+	f.Add(payload)
+	f.Add(append([]byte{payloadTypeMessageRSV}, payload[1:]...))
+	f.Add(payload[:50])
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		decoded, err := decodeSignedPayload(payload)
+		if err != nil {
+			return
+		}
+		require.Equal(t, payload[1:39], decoded.rawMessage)
+		require.Len(t, decoded.signature, 65)
+		require.Equal(t, len(payload)-104, len(decoded.additionalData))
+	})
+}
@@ -0,0 +1,5 @@
+package finalizer
+
+import flarelogger "flare-tlc/logger"
+
+var logger = flarelogger.Module("finalizer")
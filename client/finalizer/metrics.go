@@ -0,0 +1,102 @@
+package finalizer
+
+import (
+	"flare-tlc/utils/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	finalizationsWon = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "finalizations_won_total",
+		Help:      "Number of finalizations relayed by this client",
+	}, []string{"protocol"})
+	finalizationsLost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "finalizations_lost_total",
+		Help:      "Number of finalizations queued by this client but relayed by another finalizer first",
+	}, []string{"protocol"})
+	signaturesPerVotingRound = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "signatures_per_voting_round",
+		Help:      "Number of signatures collected for a voting round when it was finalized",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 8),
+	}, []string{"protocol"})
+	submissionStorageRounds = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "submission_storage_rounds",
+		Help:      "Number of voting rounds with signatures held in memory",
+	})
+	evictedRounds = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "submission_storage_evicted_rounds_total",
+		Help:      "Number of voting rounds evicted from the submission storage because max_retained_rounds was reached",
+	})
+	signaturesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "signatures_received_total",
+		Help:      "Number of valid submitted signatures of the finalized protocols, including duplicates",
+	}, []string{"protocol"})
+	duplicateSignatures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "duplicate_signatures_total",
+		Help:      "Number of ignored signatures for messages already signed by the same voter",
+	})
+	unknownPayloadTypes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "unknown_payload_types_total",
+		Help:      "Number of skipped submitted signature payloads of unknown payload types",
+	})
+	invalidSignatures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "invalid_signatures_total",
+		Help:      "Number of invalid submitted signatures per submitter and reason, see submitterTracker.label",
+	}, []string{"submitter", "reason"})
+	submitterBans = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "submitter_bans_total",
+		Help:      "Number of bans of submitters after too many invalid signatures, see submitterTracker.label",
+	}, []string{"submitter"})
+	malformedMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "malformed_messages_total",
+		Help:      "Number of submitted signatures rejected because the signed message is malformed, per reason",
+	}, []string{"protocol", "reason"})
+	ignoredSubmissions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "ignored_submissions_total",
+		Help:      "Number of submitSignatures txs dropped because the submitter is not tracked or ignored",
+	})
+	backupFinalizationsSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "backup_finalizations_skipped_total",
+		Help:      "Number of backup finalizations skipped because the daily tx budget was exceeded",
+	}, []string{"protocol"})
+	signingPolicyBackfills = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "signing_policy_backfills_total",
+		Help:      "Number of detected gaps in the reward epochs of the received signing policies, by backfill result",
+	}, []string{"result"})
+	finalizationProviderRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "finalizer",
+		Name:      "finalization_provider_requests_total",
+		Help:      "Number of finalization data requests to the finalization providers per protocol and result",
+	}, []string{"protocol", "result"})
+)
@@ -3,23 +3,33 @@ package finalizer
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
+	"encoding/hex"
 	"flare-tlc/client/config"
 	"flare-tlc/client/shared"
-	"flare-tlc/logger"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/database"
+	"flare-tlc/utils"
 	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"strconv"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 )
 
 const (
 	listenerBufferSize = 10
+
+	// Voting rounds searched back for the last finalization of a protocol, the
+	// merkle root of each round is queried from the Relay contract
+	finalizationLookbackRounds = 100
 )
 
 var (
@@ -31,26 +41,66 @@ var (
 type relayContractClient struct {
 	address common.Address
 
-	ethClient     relayEthClient
-	relay         *relay.Relay
-	privateKey    *ecdsa.PrivateKey
-	senderAddress common.Address
+	ethClient RelayEthClient
+	relay     *relay.Relay
+	signer    credentials.Signer
+	retryCfg  *config.RetryConfig
+	clock     utils.Clock
+
+	// senders of the relay txs of the protocols, the relay txs of the other protocols
+	// are sent by signer
+	senders map[byte]credentials.Signer
 
 	relaySelector []byte // for relay method
 	topic0SPI     string // for SigningPolicyInitialized event
 	topic0PMR     string // for ProtocolMessageRelayed event
+
+	// If set, SigningPolicyInitialized events are received via websocket subscription
+	spiSubscriber shared.LogSubscriber
+
+	// If set, the blocks of the SigningPolicyInitialized events are checked for reorgs
+	reorgTracker *shared.ReorgTracker
+
+	// polling intervals of the listeners, the defaults if not set
+	listenerIntervals shared.ListenerIntervals
+	// buffers of the event channels of the listeners, listenerBufferSize if not set
+	listenerBuffers shared.ListenerBuffers
 }
 
-type relayEthClient interface {
-	SendRawTx(*ecdsa.PrivateKey, common.Address, []byte, bool) error
+// Chain access of the relay client: relay txs and the Relay contract state
+type RelayEthClient interface {
+	SendRawTx(credentials.Signer, common.Address, []byte, bool, ...chain.ExpectedEvent) error
+	MerkleRoot(protocolId byte, votingRoundId uint32) (common.Hash, error)
+	SigningPolicyHash(rewardEpochId int64) (common.Hash, error)
 }
 
 type relayEthClientImpl struct {
 	client *ethclient.Client
+	relay  *relay.Relay
+	gasCfg *config.GasConfig
+}
+
+func (eth relayEthClientImpl) SendRawTx(
+	signer credentials.Signer, to common.Address, data []byte, preflight bool, expected ...chain.ExpectedEvent,
+) error {
+	return chain.SendRawTx(eth.client, signer, to, data, preflight, eth.gasCfg, config.RetryOpRelay, expected...)
+}
+
+func (eth relayEthClientImpl) MerkleRoot(protocolId byte, votingRoundId uint32) (common.Hash, error) {
+	return eth.relay.MerkleRoots(nil, big.NewInt(int64(protocolId)), big.NewInt(int64(votingRoundId)))
+}
+
+func (eth relayEthClientImpl) SigningPolicyHash(rewardEpochId int64) (common.Hash, error) {
+	return eth.relay.ToSigningPolicyHash(nil, big.NewInt(rewardEpochId))
 }
 
-func (eth relayEthClientImpl) SendRawTx(privateKey *ecdsa.PrivateKey, to common.Address, data []byte, dryRun bool) error {
-	return chain.SendRawTx(eth.client, privateKey, to, data, dryRun, &config.GasConfig{GasPriceFixed: common.Big0})
+// Finalization of a voting round of a protocol on the Relay contract
+type relayFinalization struct {
+	protocolId    byte
+	votingRoundId uint32
+	merkleRoot    common.Hash
+	// block timestamp of the ProtocolMessageRelayed event, zero if the event is not indexed
+	timestamp time.Time
 }
 
 type signingPolicyListenerResponse struct {
@@ -61,45 +111,71 @@ type signingPolicyListenerResponse struct {
 func NewRelayContractClient(
 	ethClient *ethclient.Client,
 	address common.Address,
-	privateKey *ecdsa.PrivateKey,
-	senderAddress common.Address,
+	gasCfg *config.GasConfig,
+	signer credentials.Signer,
+	senders map[byte]credentials.Signer,
+	retryCfg *config.RetryConfig,
 ) (*relayContractClient, error) {
 	relayContract, err := relay.NewRelay(address, ethClient)
 	if err != nil {
 		return nil, err
 	}
 
-	relayABI, err := relay.RelayMetaData.GetAbi()
+	relaySelectorBytes, err := chain.MethodIDFromMetadata(relay.RelayMetaData, "relay")
 	if err != nil {
-		// panic, this error is fatal
-		panic(err)
+		return nil, err
 	}
-	relaySelectorBytes := relayABI.Methods["relay"].ID
 	topic0SPI, err := chain.EventIDFromMetadata(relay.RelayMetaData, "SigningPolicyInitialized")
 	if err != nil {
-		// panic, this error is fatal
-		panic(err)
+		return nil, err
 	}
 	topic0PMR, err := chain.EventIDFromMetadata(relay.RelayMetaData, "ProtocolMessageRelayed")
 	if err != nil {
-		// panic, this error is fatal
-		panic(err)
+		return nil, err
 	}
 
 	return &relayContractClient{
-		ethClient:     relayEthClientImpl{client: ethClient},
+		ethClient:     relayEthClientImpl{client: ethClient, relay: relayContract, gasCfg: gasCfg},
 		address:       address,
 		relay:         relayContract,
-		privateKey:    privateKey,
-		senderAddress: senderAddress,
+		signer:        signer,
+		senders:       senders,
+		retryCfg:      retryCfg,
+		clock:         utils.RealClock,
 		relaySelector: relaySelectorBytes,
 		topic0SPI:     topic0SPI,
 		topic0PMR:     topic0PMR,
 	}, nil
 }
 
-func (r *relayContractClient) FetchSigningPolicies(db finalizerDB, from, to int64) ([]signingPolicyListenerResponse, error) {
-	logs, err := db.FetchLogsByAddressAndTopic0(r.address, r.topic0SPI, from, to)
+// Returns the senders of the relay txs of the protocols from the finalizer config
+func newProtocolSenders(senders []config.FinalizerSenderConfig) (map[byte]credentials.Signer, error) {
+	result := make(map[byte]credentials.Signer)
+	for i := range senders {
+		sender := &senders[i]
+		signer, err := globalConfig.SignerFromConfig(&sender.Signer, sender.PrivateKeyFile, config.FinalizerSenderPrivateKey(i))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating finalizer sender %d", i+1)
+		}
+		for _, protocolId := range sender.Protocols {
+			result[protocolId] = signer
+		}
+	}
+	return result, nil
+}
+
+// Returns the sender of the relay txs of the protocol
+func (r *relayContractClient) sender(protocolId byte) credentials.Signer {
+	if sender, ok := r.senders[protocolId]; ok {
+		return sender
+	}
+	return r.signer
+}
+
+func (r *relayContractClient) FetchSigningPolicies(db FinalizerDB, from, to int64) ([]signingPolicyListenerResponse, error) {
+	logs, err := database.FetchAll(from, to, func(from, to int64) ([]database.Log, error) {
+		return db.FetchLogsByAddressAndTopic0(r.address, r.topic0SPI, from, to)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -116,38 +192,85 @@ func (r *relayContractClient) FetchSigningPolicies(db finalizerDB, from, to int6
 	return result, nil
 }
 
-func (r *relayContractClient) SigningPolicyInitializedListener(db finalizerDB, startTime time.Time) <-chan signingPolicyListenerResponse {
-	out := make(chan signingPolicyListenerResponse, listenerBufferSize)
+func (r *relayContractClient) SigningPolicyInitializedListener(ctx context.Context, db FinalizerDB, startTime time.Time) <-chan signingPolicyListenerResponse {
+	out := shared.NewListenerOutput[signingPolicyListenerResponse](listenerSigningPolicyInitialized, r.listenerBuffers.Buffer(listenerSigningPolicyInitialized, listenerBufferSize))
 	go func() {
-		ticker := time.NewTicker(shared.EventListenerInterval)
-		eventRangeStart := startTime.Unix()
+		ticker := shared.NewListenerTicker(r.clock, listenerSigningPolicyInitialized, r.listenerIntervals.Interval(listenerSigningPolicyInitialized))
+		defer ticker.Stop()
+		delivered := shared.NewDeliveredEvents(startTime.Unix())
+		// emits the event if it was not delivered yet, returns false if ctx is done
+		deliver := func(id shared.EventID, policyData *relay.RelaySigningPolicyInitialized, timestamp int64) bool {
+			if !delivered.IsNew(id, timestamp) {
+				return true
+			}
+			if !out.Send(ctx, signingPolicyListenerResponse{policyData, timestamp}) {
+				return false
+			}
+			delivered.Add(id, timestamp)
+			shared.RecordListenerEvent(listenerSigningPolicyInitialized, timestamp)
+			return true
+		}
+		wsLogs := shared.SubscribeLogsInBackground(ctx, r.clock, r.spiSubscriber, r.address, r.topic0SPI, "SigningPolicyInitialized")
+		handleLog := func(log types.Log) bool {
+			policyData, err := r.relay.RelayFilterer.ParseSigningPolicyInitialized(log)
+			if err != nil {
+				logger.Error("Error parsing SigningPolicyInitialized event %v", err)
+				return true
+			}
+			if !deliver(shared.EventID{TxHash: log.TxHash, LogIndex: uint64(log.Index)}, policyData, int64(policyData.Timestamp)) {
+				return false
+			}
+			r.reorgTracker.Track(log.BlockNumber, hex.EncodeToString(log.BlockHash[:]), int64(policyData.Timestamp))
+			return true
+		}
 		for {
-			<-ticker.C
-			now := time.Now().Unix()
-			logs, err := db.FetchLogsByAddressAndTopic0(r.address, r.topic0SPI, eventRangeStart, now)
+			if !ticker.WaitLogs(ctx, wsLogs, handleLog) {
+				return
+			}
+			if rewind, ok := r.reorgTracker.PendingRewind(listenerSigningPolicyInitialized); ok {
+				delivered.Rewind(rewind)
+			}
+			now := r.clock.Now().Unix()
+			logs, err := db.FetchLogsByAddressAndTopic0(r.address, r.topic0SPI, delivered.RangeStart(), now)
 			if err != nil {
 				logger.Error("Error fetching logs %v", err)
 				continue
 			}
+			shared.RecordListenerRowsScanned(listenerSigningPolicyInitialized, len(logs))
 			for _, log := range logs {
 				policyData, err := shared.ParseSigningPolicyInitializedEvent(r.relay, log)
 				if err != nil {
 					logger.Error("Error parsing SigningPolicyInitialized event %v", err)
 					break
 				}
-				out <- signingPolicyListenerResponse{policyData, int64(log.Timestamp)}
-				// continue with timestamps > log.Timestamp,
-				// there should be only one such log per timestamp
-				eventRangeStart = int64(log.Timestamp)
+				if !deliver(shared.EventID{TxHash: common.HexToHash(log.TransactionHash), LogIndex: log.LogIndex}, policyData, int64(log.Timestamp)) {
+					return
+				}
+				delivered.Confirm(int64(log.Timestamp))
+				r.reorgTracker.Track(log.Transaction.BlockNumber, log.Transaction.BlockHash, int64(log.Timestamp))
 			}
 		}
 	}()
-	return out
+	return out.C()
+}
+
+// ProtocolMessageRelayed event of the relayed message, expected in the relay tx receipt
+func (r *relayContractClient) protocolMessageRelayed(message *submittedPayload) chain.ExpectedEvent {
+	return chain.ExpectedEvent{
+		Address:  r.address,
+		MetaData: relay.RelayMetaData,
+		Name:     "ProtocolMessageRelayed",
+		Match: func(log types.Log) bool {
+			event, err := r.relay.ParseProtocolMessageRelayed(log)
+			return err == nil && event.ProtocolId == message.protocolId && event.VotingRoundId == message.votingRoundId
+		},
+	}
 }
 
-func (r *relayContractClient) SubmitPayloads(ctx context.Context, payloads []*signedPayload, signingPolicy *signingPolicy, dryRun bool) {
+// Returns true if the relay tx was sent successfully or the message was already relayed
+func (r *relayContractClient) SubmitPayloads(ctx context.Context, payloads []*signedPayload, signingPolicy *signingPolicy, preflight bool) bool {
 	if len(payloads) == 0 || signingPolicy == nil {
-		return
+		return false
 	}
 
 	buffer := bytes.NewBuffer(nil)
@@ -157,47 +280,129 @@ func (r *relayContractClient) SubmitPayloads(ctx context.Context, payloads []*si
 	signatureBytes, err := EncodeForRelay(payloads)
 	if err != nil {
 		logger.Error("Error encoding payloads %v", err)
-		return
+		return false
 	}
 	buffer.Write(signatureBytes)
 	payload := buffer.Bytes()
+	if err := validateRelayCalldata(payload, r.relaySelector, signingPolicy); err != nil {
+		logger.Error("Relay tx for voting round %d not sent: %v", payloads[0].message.votingRoundId, err)
+		return false
+	}
 
-	execStatusChan := shared.ExecuteWithRetry(func() (any, error) {
-		err := r.ethClient.SendRawTx(r.privateKey, r.address, payload, dryRun)
+	protocol := strconv.Itoa(int(payloads[0].message.protocolId))
+	relayed := r.protocolMessageRelayed(payloads[0].message)
+	sender := r.sender(payloads[0].message.protocolId)
+	execStatusChan := shared.ExecuteWithRetryPolicy(func() (any, error) {
+		err := r.ethClient.SendRawTx(sender, r.address, payload, preflight, relayed)
 		if err != nil {
 			if shared.ExistsAsSubstring(nonFatalRelayErrors, err.Error()) {
 				logger.Info("Non fatal error sending relay tx: %v", err)
+				finalizationsLost.WithLabelValues(protocol).Inc()
 			} else {
 				return nil, errors.Wrap(err, "Error sending relay tx")
 			}
+		} else {
+			finalizationsWon.WithLabelValues(protocol).Inc()
 		}
 		return nil, nil
-	}, shared.MaxTxSendRetries, shared.TxRetryInterval)
+	}, r.retryCfg.Policy(config.RetryOpRelay))
 
 	select {
 	case execStatus := <-execStatusChan:
 		if execStatus.Success {
 			logger.Info("Relaying finished")
 		}
+		return execStatus.Success
 
 	case <-ctx.Done():
-		return
+		return false
+	}
+}
+
+// Returns true if a merkle root for the protocol and voting round is already
+// confirmed on chain, i.e. the relay tx would be reverted
+func (r *relayContractClient) IsRelayed(protocolId byte, votingRoundId uint32) (bool, error) {
+	root, err := r.ethClient.MerkleRoot(protocolId, votingRoundId)
+	if err != nil {
+		return false, errors.Wrap(err, "Error fetching merkle root")
+	}
+	return root != (common.Hash{}), nil
+}
+
+// Returns true if the signing policy of the reward epoch is initialized on chain
+func (r *relayContractClient) SigningPolicyInitialized(rewardEpochId int64) (bool, error) {
+	hash, err := r.ethClient.SigningPolicyHash(rewardEpochId)
+	if err != nil {
+		return false, errors.Wrap(err, "Error fetching signing policy hash")
+	}
+	return hash != (common.Hash{}), nil
+}
+
+// LastFinalization returns the last voting round of the protocol, not after
+// votingRoundId, with a merkle root confirmed on the Relay contract. At most
+// finalizationLookbackRounds rounds are searched, nil is returned if none of them
+// is finalized. The finalization timestamp is read from the indexed
+// ProtocolMessageRelayed events until now.
+func (r *relayContractClient) LastFinalization(
+	db FinalizerDB, protocolId byte, votingRoundId uint32, votingEpoch *utils.Epoch, now time.Time,
+) (*relayFinalization, error) {
+	for i := uint32(0); i < finalizationLookbackRounds && i <= votingRoundId; i++ {
+		round := votingRoundId - i
+		root, err := r.ethClient.MerkleRoot(protocolId, round)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error fetching merkle root")
+		}
+		if root == (common.Hash{}) {
+			continue
+		}
+		finalization := &relayFinalization{protocolId: protocolId, votingRoundId: round, merkleRoot: root}
+		finalization.timestamp, err = r.finalizationTimestamp(db, protocolId, round, votingEpoch.EndTime(int64(round)), now)
+		if err != nil {
+			logger.Warn("Error fetching finalization timestamp of voting round %d, protocol %d: %v", round, protocolId, err)
+		}
+		return finalization, nil
+	}
+	return nil, nil
+}
+
+// Returns the block timestamp of the ProtocolMessageRelayed event of the voting
+// round between from and to, zero if the event is not found
+func (r *relayContractClient) finalizationTimestamp(
+	db FinalizerDB, protocolId byte, votingRoundId uint32, from, to time.Time,
+) (time.Time, error) {
+	logs, err := database.FetchAll(from.Unix(), to.Unix(), func(from, to int64) ([]database.Log, error) {
+		return db.FetchLogsByAddressAndTopic0(r.address, r.topic0PMR, from, to)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, log := range logs {
+		data, err := shared.ParseProtocolMessageRelayedEvent(r.relay, log)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if data.ProtocolId == protocolId && data.VotingRoundId == votingRoundId {
+			return time.Unix(int64(log.Timestamp), 0), nil
+		}
 	}
+	return time.Time{}, nil
 }
 
-func (r *relayContractClient) ProtocolMessageRelayed(db finalizerDB, from time.Time, to time.Time) (mapset.Set[queueItem], error) {
-	logs, err := db.FetchLogsByAddressAndTopic0(r.address, r.topic0PMR, from.Unix(), to.Unix())
+func (r *relayContractClient) ProtocolMessageRelayed(db FinalizerDB, from time.Time, to time.Time) (mapset.Set[queueItemKey], error) {
+	logs, err := database.FetchAll(from.Unix(), to.Unix(), func(from, to int64) ([]database.Log, error) {
+		return db.FetchLogsByAddressAndTopic0(r.address, r.topic0PMR, from, to)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	result := mapset.NewSet[queueItem]()
+	result := mapset.NewSet[queueItemKey]()
 	for _, log := range logs {
 		data, err := shared.ParseProtocolMessageRelayedEvent(r.relay, log)
 		if err != nil {
 			return nil, err
 		}
-		result.Add(queueItem{
+		result.Add(queueItemKey{
 			protocolId:    data.ProtocolId,
 			votingRoundId: data.VotingRoundId,
 			messageHash:   data.MerkleRoot,
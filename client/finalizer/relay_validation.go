@@ -0,0 +1,120 @@
+package finalizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flare-tlc/utils/signingpolicy"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Lengths of the parts of the relay calldata, as decoded by the Relay contract
+const (
+	relaySelectorLength   = 4
+	relayMessageLength    = 38 // protocol id (1), voting round id (4), random quality score (1), merkle root (32)
+	relaySignatureLength  = 67 // signature [V || R || S] (65), voter index (2)
+	relaySignaturesHeader = 2  // number of signatures
+)
+
+// Error of a failed relay calldata check, the Relay contract would revert the tx
+type relayValidationError struct {
+	check  string
+	detail string
+}
+
+func (e *relayValidationError) Error() string {
+	return fmt.Sprintf("relay calldata check %s failed: %s", e.check, e.detail)
+}
+
+func relayCheckError(check string, format string, args ...interface{}) error {
+	return &relayValidationError{check: check, detail: fmt.Sprintf(format, args...)}
+}
+
+// Validates the assembled relay calldata against the signing policy the same way as
+// the Relay contract: the encoded signing policy, the message, and the signatures,
+// which must be sorted by voter index, signed by the voters at the indices and have
+// a total weight above the threshold.
+func validateRelayCalldata(calldata []byte, selector []byte, policy *signingPolicy) error {
+	if len(calldata) < relaySelectorLength || !bytes.Equal(calldata[:relaySelectorLength], selector) {
+		return relayCheckError("selector", "calldata does not start with the relay selector")
+	}
+	data := calldata[relaySelectorLength:]
+
+	// signing policy
+	encoded, policyLength, err := signingpolicy.DecodePrefix(data)
+	if err != nil {
+		return relayCheckError("signing policy length", "%v", err)
+	}
+	size := len(encoded.Voters)
+	if size != policy.voters.Count() {
+		return relayCheckError("signing policy voters", "%d encoded voters, policy has %d", size, policy.voters.Count())
+	}
+	if rewardEpochId := int64(encoded.RewardEpochId); rewardEpochId != policy.rewardEpochId {
+		return relayCheckError("signing policy reward epoch", "encoded %d, policy %d", rewardEpochId, policy.rewardEpochId)
+	}
+	if encoded.StartVotingRoundId != policy.startVotingRoundId {
+		return relayCheckError("signing policy start voting round", "encoded %d, policy %d", encoded.StartVotingRoundId, policy.startVotingRoundId)
+	}
+	if encoded.Threshold != policy.threshold {
+		return relayCheckError("signing policy threshold", "encoded %d, policy %d", encoded.Threshold, policy.threshold)
+	}
+	for i := 0; i < size; i++ {
+		voter, weight := encoded.Voters[i], encoded.Weights[i]
+		if voter != policy.voters.VoterAddress(i) || weight != policy.voters.VoterWeight(i) {
+			return relayCheckError("signing policy voters", "voter %d is %s with weight %d, policy has %s with weight %d",
+				i, voter.Hex(), weight, policy.voters.VoterAddress(i).Hex(), policy.voters.VoterWeight(i))
+		}
+	}
+	data = data[policyLength:]
+
+	// message
+	if len(data) < relayMessageLength {
+		return relayCheckError("message length", "%d bytes, message is %d bytes", len(data), relayMessageLength)
+	}
+	message := data[:relayMessageLength]
+	votingRoundId := binary.BigEndian.Uint32(message[1:5])
+	if votingRoundId < policy.startVotingRoundId {
+		return relayCheckError("message voting round", "voting round %d is before the signing policy start %d",
+			votingRoundId, policy.startVotingRoundId)
+	}
+	messageHash := accounts.TextHash(crypto.Keccak256(message))
+	data = data[relayMessageLength:]
+
+	// signatures
+	if len(data) < relaySignaturesHeader {
+		return relayCheckError("signatures length", "missing number of signatures")
+	}
+	count := int(binary.BigEndian.Uint16(data[0:2]))
+	data = data[relaySignaturesHeader:]
+	if len(data) != count*relaySignatureLength {
+		return relayCheckError("signatures length", "%d bytes, %d signatures need %d bytes", len(data), count, count*relaySignatureLength)
+	}
+	weight := 0
+	prevIndex := -1
+	for i := 0; i < count; i++ {
+		signature := data[i*relaySignatureLength : i*relaySignatureLength+65]
+		index := int(binary.BigEndian.Uint16(data[i*relaySignatureLength+65 : (i+1)*relaySignatureLength]))
+		if index <= prevIndex {
+			return relayCheckError("signature order", "voter index %d after %d, signatures must be sorted by voter index", index, prevIndex)
+		}
+		if index >= size {
+			return relayCheckError("signature voter index", "voter index %d, policy has %d voters", index, size)
+		}
+		rsv := transformSignature(signature)
+		pk, err := crypto.SigToPub(messageHash, rsv[:])
+		if err != nil {
+			return relayCheckError("signature", "invalid signature of voter %d: %v", index, err)
+		}
+		if signer := crypto.PubkeyToAddress(*pk); signer != policy.voters.VoterAddress(index) {
+			return relayCheckError("signer", "signature of voter %d is signed by %s", index, signer.Hex())
+		}
+		weight += int(policy.voters.VoterWeight(index))
+		prevIndex = index
+	}
+	if weight <= int(policy.threshold) {
+		return relayCheckError("weight", "signature weight %d is not above the threshold %d", weight, policy.threshold)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package finalizer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/signingpolicy"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type relayValidationFixture struct {
+	selector []byte
+	policy   *signingPolicy
+	message  []byte
+	keys     []*ecdsa.PrivateKey
+}
+
+func newRelayValidationFixture(t *testing.T) *relayValidationFixture {
+	f := &relayValidationFixture{selector: []byte{0x01, 0x02, 0x03, 0x04}}
+	policyData := &relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      big.NewInt(3),
+		StartVotingRoundId: 100,
+		Threshold:          50,
+		Seed:               big.NewInt(7),
+	}
+	for i := 0; i < 3; i++ {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		f.keys = append(f.keys, key)
+		policyData.Voters = append(policyData.Voters, crypto.PubkeyToAddress(key.PublicKey))
+		policyData.Weights = append(policyData.Weights, 30)
+	}
+	policyBytes, err := signingpolicy.EncodeEvent(policyData)
+	require.NoError(t, err)
+	policyData.SigningPolicyBytes = policyBytes
+	f.policy = newSigningPolicy(policyData)
+
+	message, err := encodeSubmittedPayload(&submittedPayload{
+		protocolId:    100,
+		votingRoundId: 120,
+		merkleRoot:    common.HexToHash("0x02").Bytes(),
+	})
+	require.NoError(t, err)
+	f.message = message
+	return f
+}
+
+// Relay calldata with the signatures of the voters at the indices
+func (f *relayValidationFixture) calldata(t *testing.T, indices ...int) []byte {
+	payloads := make([]*signedPayload, len(indices))
+	for i, index := range indices {
+		signature, err := signPayload(f.keys[index], f.message)
+		require.NoError(t, err)
+		payloads[i] = &signedPayload{signature: signature, index: index}
+	}
+	signatures, err := EncodeForRelay(payloads)
+	require.NoError(t, err)
+
+	buffer := bytes.NewBuffer(nil)
+	buffer.Write(f.selector)
+	buffer.Write(f.policy.rawBytes)
+	buffer.Write(f.message)
+	buffer.Write(signatures)
+	return buffer.Bytes()
+}
+
+func TestValidateRelayCalldata(t *testing.T) {
+	f := newRelayValidationFixture(t)
+
+	require.NoError(t, validateRelayCalldata(f.calldata(t, 0, 2), f.selector, f.policy))
+	require.NoError(t, validateRelayCalldata(f.calldata(t, 0, 1, 2), f.selector, f.policy))
+}
+
+func TestValidateRelayCalldataErrors(t *testing.T) {
+	f := newRelayValidationFixture(t)
+
+	t.Run("selector", func(t *testing.T) {
+		err := validateRelayCalldata(f.calldata(t, 0, 1), []byte{0x04, 0x03, 0x02, 0x01}, f.policy)
+		require.ErrorContains(t, err, "check selector failed")
+	})
+
+	t.Run("signing policy", func(t *testing.T) {
+		policy := *f.policy
+		policy.threshold = 40
+		err := validateRelayCalldata(f.calldata(t, 0, 1), f.selector, &policy)
+		require.ErrorContains(t, err, "check signing policy threshold failed")
+	})
+
+	t.Run("truncated signing policy", func(t *testing.T) {
+		calldata := f.calldata(t, 0, 1)
+		err := validateRelayCalldata(calldata[:relaySelectorLength+50], f.selector, f.policy)
+		require.ErrorContains(t, err, "check signing policy length failed")
+	})
+
+	t.Run("order", func(t *testing.T) {
+		calldata := f.calldata(t, 0, 1)
+		// swap the two signatures
+		second := len(calldata) - relaySignatureLength
+		first := second - relaySignatureLength
+		swapped := append(append([]byte{}, calldata[second:]...), calldata[first:second]...)
+		copy(calldata[first:], swapped)
+		err := validateRelayCalldata(calldata, f.selector, f.policy)
+		require.ErrorContains(t, err, "check signature order failed")
+	})
+
+	t.Run("signer", func(t *testing.T) {
+		calldata := f.calldata(t, 0, 1)
+		// voter index 2 for the signature of voter 1
+		calldata[len(calldata)-1] = 2
+		err := validateRelayCalldata(calldata, f.selector, f.policy)
+		require.ErrorContains(t, err, "check signer failed")
+	})
+
+	t.Run("weight", func(t *testing.T) {
+		err := validateRelayCalldata(f.calldata(t, 1), f.selector, f.policy)
+		require.ErrorContains(t, err, "check weight failed")
+	})
+
+	t.Run("trailing bytes", func(t *testing.T) {
+		calldata := append(f.calldata(t, 0, 1), 0x00)
+		err := validateRelayCalldata(calldata, f.selector, f.policy)
+		require.ErrorContains(t, err, "check signatures length failed")
+	})
+}
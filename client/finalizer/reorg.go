@@ -0,0 +1,68 @@
+package finalizer
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared"
+	"time"
+)
+
+// Listener names, used for the listener metrics and the reorg rewinds
+const (
+	listenerSigningPolicyInitialized = config.ListenerSigningPolicyInitialized
+	listenerSubmitSignatures         = config.ListenerSubmitSignatures
+	listenerProtocolMessageRelayed   = config.ListenerProtocolMessageRelayed
+)
+
+const reorgCheckInterval = 10 * time.Second
+
+// Checks the blocks of the processed events for reorgs and rolls back the state
+// from the first reorged block on
+func (c *finalizerClient) runReorgCheck(ctx context.Context) error {
+	ticker := c.clock.NewTicker(reorgCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			break
+
+		case <-ctx.Done():
+			logger.Info("Reorg check stopped")
+			return ctx.Err()
+		}
+
+		reorg, err := c.reorgTracker.Check(ctx)
+		if err != nil {
+			logger.Error("Error checking for reorgs %v", err)
+			continue
+		}
+		if reorg != nil {
+			c.rollback(reorg)
+		}
+	}
+}
+
+// Drops the signing policies and the voting rounds with submissions from the reorged
+// block on, the listeners fetch them again
+func (c *finalizerClient) rollback(reorg *shared.Reorg) {
+	logger.Warn("Reorg detected at block %d, rolling back signing policies and submissions from timestamp %d",
+		reorg.BlockNumber, reorg.Timestamp)
+
+	removedEpochIds := c.signingPolicyStorage.RemoveFromTimestamp(uint64(reorg.Timestamp))
+	if len(removedEpochIds) > 0 {
+		logger.Warn("Removed signing policies of reward epochs %v", removedEpochIds)
+		c.voterRegistry.Remove(removedEpochIds)
+		if c.policyStore != nil {
+			if err := c.policyStore.DeleteFrom(removedEpochIds[len(removedEpochIds)-1]); err != nil {
+				logger.Warn("Error removing persisted signing policies: %v", err)
+			}
+		}
+	}
+	c.reorgTracker.Rewind(listenerSigningPolicyInitialized, reorg.Timestamp-1)
+
+	submissionsStart := reorg.Timestamp
+	if first, ok := c.submissionStorage.RemoveFromTimestamp(reorg.Timestamp); ok {
+		submissionsStart = first
+	}
+	c.reorgTracker.Rewind(listenerSubmitSignatures, submissionsStart-1)
+}
@@ -0,0 +1,62 @@
+package finalizer
+
+import (
+	"flare-tlc/client/shared"
+	"flare-tlc/client/shared/voters"
+	"flare-tlc/utils/contracts/relay"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollback(t *testing.T) {
+	store := &testSigningPolicyStore{}
+	c := &finalizerClient{
+		signingPolicyStorage: newSigningPolicyStorage(),
+		submissionStorage:    newSubmissionStorage(0),
+		policyStore:          store,
+		reorgTracker:         shared.NewReorgTracker(nil, "test", 8),
+	}
+	for _, policyData := range []*relay.RelaySigningPolicyInitialized{
+		newTestPolicyData(1, 10),
+		newTestPolicyData(2, 20),
+		newTestPolicyData(3, 30),
+	} {
+		require.NoError(t, c.signingPolicyStorage.Add(newSigningPolicy(policyData)))
+		c.persistSigningPolicy(policyData)
+	}
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	sp := &signingPolicy{voters: voters.NewVoterSet([]common.Address{signer}, []uint16{100})}
+	hashes := make(map[uint32]common.Hash)
+	add := func(votingRoundId uint32, timestamp int64) {
+		payload := newTestSignedPayload(t, privateKey, votingRoundId)
+		hashes[votingRoundId] = payload.messageHash
+		_, err := c.submissionStorage.Add(payload, sp, 50, timestamp)
+		require.NoError(t, err)
+	}
+	add(1, 15)
+	add(2, 18)
+	add(2, 24)
+	add(3, 28)
+
+	c.rollback(&shared.Reorg{BlockNumber: 100, Timestamp: 20})
+
+	require.EqualValues(t, 1, c.signingPolicyStorage.Last().rewardEpochId)
+	require.Len(t, store.policies, 1)
+	require.NotNil(t, c.submissionStorage.Get(1, 100, hashes[1]))
+	require.Nil(t, c.submissionStorage.Get(2, 100, hashes[2]))
+	require.Nil(t, c.submissionStorage.Get(3, 100, hashes[3]))
+
+	rewind, ok := c.reorgTracker.PendingRewind(listenerSigningPolicyInitialized)
+	require.True(t, ok)
+	require.EqualValues(t, 19, rewind)
+	// voting round 2 is fetched again from its first submission
+	rewind, ok = c.reorgTracker.PendingRewind(listenerSubmitSignatures)
+	require.True(t, ok)
+	require.EqualValues(t, 17, rewind)
+}
@@ -0,0 +1,389 @@
+package finalizer
+
+import (
+	"context"
+	"encoding/binary"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/signingpolicy"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Actions of the finalizer reported by a replay
+const (
+	ReplaySigningPolicy    = "signing_policy"    // signing policy of a reward epoch added
+	ReplayRelayedOnChain   = "relayed_on_chain"  // ProtocolMessageRelayed event of the recording
+	ReplayThresholdReached = "threshold_reached" // collected signatures reached the threshold
+	ReplayRelay            = "relay"             // relay tx of a selected finalizer
+	ReplayBackupScheduled  = "backup_scheduled"  // relay tx scheduled after the grace period
+	ReplayBackupRelay      = "backup_relay"      // relay tx after the grace period
+	ReplayNotSent          = "not_sent"          // no relay tx for a message that reached the threshold
+	ReplaySubmissionError  = "submission_error"  // submitted signatures could not be processed
+	ReplayNotFinalized     = "not_finalized"     // signatures below the threshold at the end of the replay
+)
+
+// Action the finalizer would have taken, at the time of the replay clock
+type ReplayAction struct {
+	Time          time.Time `json:"time"`
+	ProtocolId    byte      `json:"protocolId"`
+	VotingRoundId uint32    `json:"votingRoundId"`
+	Action        string    `json:"action"`
+	Detail        string    `json:"detail,omitempty"`
+}
+
+func (a ReplayAction) String() string {
+	s := fmt.Sprintf("%s %-17s protocol %3d round %d", a.Time.UTC().Format(time.RFC3339), a.Action, a.ProtocolId, a.VotingRoundId)
+	if len(a.Detail) > 0 {
+		s += ": " + a.Detail
+	}
+	return s
+}
+
+// Replayed event of the recording, applied at the block timestamp
+type replayEvent struct {
+	timestamp int64
+	apply     func() error
+}
+
+type finalizerReplay struct {
+	client *finalizerClient
+	clock  *utils.FakeClock
+	eth    *replayEthClient
+
+	actions []ReplayAction
+}
+
+// Replay re-runs the finalizer on the logs and transactions recorded in the database of
+// the simulation in the range (from, to]. The signing policies, relayed messages and
+// submitted signatures are processed in the order of their timestamps on a fake clock,
+// the queued items and the backup finalizations due before the next event are processed
+// first. Relay txs are passed to the eth client of the simulation instead of the chain
+// and reported as actions. Random backup delays are disabled, so the same recording
+// always results in the same actions.
+func Replay(ctx context.Context, cfg *config.ClientConfig, sim Simulation, from, to time.Time) ([]ReplayAction, error) {
+	clock := utils.NewFakeClock(from)
+	sim.Clock = clock
+	c, err := NewSimulatedFinalizerClient(cfg, sim)
+	if err != nil {
+		return nil, err
+	}
+	c.finalizerContext.startingVotingRound = cfg.Finalizer.StartingVotingRound
+	c.finalizerContext.backupRandomDelay = 0
+	defer c.queueProcessor.delayedQueues.Close()
+
+	r := &finalizerReplay{client: c, clock: clock}
+	r.eth = &replayEthClient{RelayEthClient: sim.EthClient, replay: r, relayed: make(map[relayedRoundKey]bool)}
+	c.relayClient.ethClient = r.eth
+
+	events, err := r.events(from.Unix(), to.Unix())
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		r.advance(time.Unix(event.timestamp, 0))
+		if err := event.apply(); err != nil {
+			return nil, err
+		}
+		r.processQueue(ctx)
+	}
+	r.advance(to)
+	r.reportNotFinalized()
+	return r.actions, nil
+}
+
+// Recorded events ordered by timestamp, at the same timestamp signing policies
+// first, then relayed messages and signatures
+func (r *finalizerReplay) events(from, to int64) ([]replayEvent, error) {
+	c := r.client
+
+	policies, err := c.relayClient.FetchSigningPolicies(c.db, from, to)
+	if err != nil {
+		return nil, err
+	}
+	relayedLogs, err := database.FetchAll(from, to, func(from, to int64) ([]database.Log, error) {
+		return c.db.FetchLogsByAddressAndTopic0(c.relayClient.address, c.relayClient.topic0PMR, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	submissionABI, err := submission.SubmissionMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	selector := submissionABI.Methods["submitSignatures"].ID
+	txs, err := database.FetchAll(from, to, func(from, to int64) ([]database.Transaction, error) {
+		return c.db.FetchTransactionsByAddressAndSelector(c.submissionClient.address, selector, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]replayEvent, 0, len(policies)+len(relayedLogs)+len(txs))
+	for _, policy := range policies {
+		policy := policy
+		events = append(events, replayEvent{policy.timestamp, func() error {
+			return r.addSigningPolicy(policy)
+		}})
+	}
+	for _, log := range relayedLogs {
+		log := log
+		data, err := shared.ParseProtocolMessageRelayedEvent(c.relayClient.relay, log)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, replayEvent{int64(log.Timestamp), func() error {
+			c.queueProcessor.MarkRelayed([]queueItemKey{{
+				protocolId: data.ProtocolId, votingRoundId: data.VotingRoundId, messageHash: data.MerkleRoot,
+			}})
+			r.record(data.ProtocolId, data.VotingRoundId, ReplayRelayedOnChain, fmt.Sprintf("merkle root %s", common.Hash(data.MerkleRoot).Hex()))
+			return nil
+		}})
+	}
+	for _, tx := range txs {
+		tx := tx
+		events = append(events, replayEvent{int64(tx.Timestamp), func() error {
+			if err := processSubmissionTx(tx, c); err != nil {
+				r.record(0, 0, ReplaySubmissionError, fmt.Sprintf("tx %s sent by %s: %v", tx.Hash, tx.FromAddress, err))
+			}
+			return nil
+		}})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].timestamp < events[j].timestamp
+	})
+	return events, nil
+}
+
+func (r *finalizerReplay) addSigningPolicy(response signingPolicyListenerResponse) error {
+	c := r.client
+	policy := newSigningPolicy(response.policyData)
+	if policy.rewardEpochId < c.finalizerContext.startingRewardEpoch {
+		return nil
+	}
+	if err := c.addSigningPolicy(policy, response); err != nil {
+		return errors.Wrapf(err, "error adding signing policy for reward epoch %d", policy.rewardEpochId)
+	}
+	r.record(0, policy.startVotingRoundId, ReplaySigningPolicy, fmt.Sprintf(
+		"reward epoch %d, %d voters, threshold %d", policy.rewardEpochId, policy.voters.Count(), policy.threshold))
+	c.rewardEpochCleanup()
+	return nil
+}
+
+// Moves the clock to t, the backup finalizations scheduled until t are processed at
+// their scheduled time
+func (r *finalizerReplay) advance(t time.Time) {
+	p := r.client.queueProcessor
+	for {
+		var next time.Time
+		for scheduledAt := range p.delayedQueues.Items() {
+			if next.IsZero() || scheduledAt.Before(next) {
+				next = scheduledAt
+			}
+		}
+		if next.IsZero() || next.After(t) {
+			break
+		}
+		// taken from the delayed queue before its timer fires
+		items := p.delayedQueues.Get(next)
+		r.advanceTo(next)
+		if err := p.processDelayedQueue(items); err != nil {
+			logger.Error("Error processing delayed queue items: %v", err)
+		}
+		for _, item := range items {
+			r.reportNotRelayed(item)
+		}
+	}
+	r.advanceTo(t)
+}
+
+func (r *finalizerReplay) advanceTo(t time.Time) {
+	if d := t.Sub(r.clock.Now()); d > 0 {
+		r.clock.Advance(d)
+	}
+}
+
+// Handles the items of the messages that reached the threshold, like the queue processor
+func (r *finalizerReplay) processQueue(ctx context.Context) {
+	p := r.client.queueProcessor
+	for item := p.queue.Pop(); item != nil; item = p.queue.Pop() {
+		r.record(item.protocolId, item.votingRoundId, ReplayThresholdReached, fmt.Sprintf("message hash %s", item.messageHash.Hex()))
+
+		selected := p.isVoterForCurrentEpoch(item)
+		sent := r.eth.sentCount()
+		p.handleItem(ctx, item)
+
+		switch {
+		case selected && !item.secondary:
+			if r.eth.sentCount() == sent {
+				r.reportNotRelayed(item)
+			}
+		case !selected && p.finalizerContext.onlyWhenSelected:
+			r.record(item.protocolId, item.votingRoundId, ReplayNotSent, "not selected, only_when_selected is set")
+		default:
+			reason := "not selected"
+			if item.secondary {
+				reason = "secondary message, random quality score 0"
+			}
+			if scheduledAt, ok := r.scheduledAt(item); ok {
+				r.record(item.protocolId, item.votingRoundId, ReplayBackupScheduled, fmt.Sprintf(
+					"%s, at %s", reason, scheduledAt.UTC().Format(time.RFC3339)))
+			} else {
+				r.record(item.protocolId, item.votingRoundId, ReplayNotSent, reason+", threshold reached after the grace period")
+			}
+		}
+	}
+}
+
+func (r *finalizerReplay) scheduledAt(item *queueItem) (time.Time, bool) {
+	for scheduledAt, items := range r.client.queueProcessor.delayedQueues.Items() {
+		for _, scheduled := range items {
+			if scheduled == item {
+				return scheduledAt, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Reports an item without a relay tx of the replay
+func (r *finalizerReplay) reportNotRelayed(item *queueItem) {
+	if r.eth.relayedRound(item.roundKey()) {
+		return
+	}
+	if r.client.queueProcessor.isRelayed(item) {
+		r.record(item.protocolId, item.votingRoundId, ReplayNotSent, "already relayed")
+	} else {
+		r.record(item.protocolId, item.votingRoundId, ReplayNotSent, "relay failed")
+	}
+}
+
+// Reports the messages with signatures below the threshold, by voting round and protocol
+func (r *finalizerReplay) reportNotFinalized() {
+	storage := r.client.submissionStorage
+	storage.Lock()
+	var actions []ReplayAction
+	for votingRoundId, vrItem := range storage.vrMap {
+		for key, message := range vrItem.msgMap {
+			if message.thresholdReached {
+				continue
+			}
+			actions = append(actions, ReplayAction{
+				Time:          r.clock.Now(),
+				ProtocolId:    key.protocolId,
+				VotingRoundId: votingRoundId,
+				Action:        ReplayNotFinalized,
+				Detail: fmt.Sprintf("message hash %s, signature weight %d, threshold %d",
+					key.messageHash.Hex(), message.weight, message.threshold),
+			})
+		}
+	}
+	storage.Unlock()
+
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].VotingRoundId != actions[j].VotingRoundId {
+			return actions[i].VotingRoundId < actions[j].VotingRoundId
+		}
+		if actions[i].ProtocolId != actions[j].ProtocolId {
+			return actions[i].ProtocolId < actions[j].ProtocolId
+		}
+		return actions[i].Detail < actions[j].Detail
+	})
+	r.actions = append(r.actions, actions...)
+}
+
+func (r *finalizerReplay) record(protocolId byte, votingRoundId uint32, action string, detail string) {
+	r.actions = append(r.actions, ReplayAction{
+		Time:          r.clock.Now(),
+		ProtocolId:    protocolId,
+		VotingRoundId: votingRoundId,
+		Action:        action,
+		Detail:        detail,
+	})
+}
+
+// Reports the relay txs of the replay, the relayed rounds are then treated as
+// finalized on chain
+type replayEthClient struct {
+	RelayEthClient
+
+	replay  *finalizerReplay
+	mu      sync.Mutex
+	sent    int
+	relayed map[relayedRoundKey]bool
+}
+
+func (eth *replayEthClient) SendRawTx(
+	signer credentials.Signer, to common.Address, data []byte, preflight bool, expected ...chain.ExpectedEvent,
+) error {
+	if err := eth.RelayEthClient.SendRawTx(signer, to, data, preflight, expected...); err != nil {
+		return err
+	}
+	key, signatures, ok := decodeRelayedRound(data)
+	if !ok {
+		return errors.New("invalid relay calldata")
+	}
+
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+
+	eth.sent++
+	eth.relayed[key] = true
+	// delayed items are sent as backup finalizations, with the pre-flight check
+	action := ReplayRelay
+	if preflight {
+		action = ReplayBackupRelay
+	}
+	eth.replay.record(key.protocolId, key.votingRoundId, action, fmt.Sprintf("%d signatures, sent by %s", signatures, signer.Address().Hex()))
+	return nil
+}
+
+func (eth *replayEthClient) MerkleRoot(protocolId byte, votingRoundId uint32) (common.Hash, error) {
+	if eth.relayedRound(relayedRoundKey{votingRoundId: votingRoundId, protocolId: protocolId}) {
+		// any non-zero root, the round is finalized
+		return common.Hash{1}, nil
+	}
+	return eth.RelayEthClient.MerkleRoot(protocolId, votingRoundId)
+}
+
+func (eth *replayEthClient) sentCount() int {
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+
+	return eth.sent
+}
+
+func (eth *replayEthClient) relayedRound(key relayedRoundKey) bool {
+	eth.mu.Lock()
+	defer eth.mu.Unlock()
+
+	return eth.relayed[key]
+}
+
+// Returns the voting round and protocol of the relay calldata and the number of signatures
+func decodeRelayedRound(calldata []byte) (relayedRoundKey, int, bool) {
+	if len(calldata) < relaySelectorLength+signingpolicy.HeaderLength {
+		return relayedRoundKey{}, 0, false
+	}
+	data := calldata[relaySelectorLength:]
+	size := int(binary.BigEndian.Uint16(data[0:2]))
+	data = data[min(len(data), signingpolicy.HeaderLength+size*signingpolicy.VoterLength):]
+	if len(data) < relayMessageLength+relaySignaturesHeader {
+		return relayedRoundKey{}, 0, false
+	}
+	key := relayedRoundKey{protocolId: data[0], votingRoundId: binary.BigEndian.Uint32(data[1:5])}
+	return key, int(binary.BigEndian.Uint16(data[relayMessageLength : relayMessageLength+2])), true
+}
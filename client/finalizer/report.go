@@ -0,0 +1,135 @@
+package finalizer
+
+import (
+	"encoding/csv"
+	"encoding/hex"
+	"flare-tlc/client/shared"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Statistics of a message relayed on chain, see FinalizationReport
+type FinalizationStats struct {
+	ProtocolId     byte      `json:"protocol_id"`
+	VotingRoundId  uint32    `json:"voting_round_id"`
+	MerkleRoot     string    `json:"merkle_root"`
+	SecureRandom   bool      `json:"secure_random"`
+	FinalizedAt    time.Time `json:"finalized_at"`
+	LatencySeconds int64     `json:"latency_seconds"` // from the end of the voting round
+	TxHash         string    `json:"tx_hash,omitempty"`
+	Finalizer      string    `json:"finalizer,omitempty"` // sender of the relay tx, empty if the tx was not sent to the Relay contract
+	GasUsed        uint64    `json:"gas_used,omitempty"`  // set if receipts are fetched
+	Won            bool      `json:"won"`                 // finalized by the relay tx of this client
+	Attempted      bool      `json:"attempted"`           // this client sent a relay tx for the voting round
+}
+
+var finalizationStatsHeader = []string{
+	"protocol_id", "voting_round_id", "merkle_root", "secure_random", "finalized_at", "latency_seconds",
+	"tx_hash", "finalizer", "gas_used", "won", "attempted",
+}
+
+// Returns the statistics of the messages relayed between from and to, read from the
+// ProtocolMessageRelayed events and the relay txs. The finalizer of a message is the
+// sender of the first successful relay tx of its voting round and protocol, sender is
+// the address of this client.
+func (r *relayContractClient) finalizationStats(
+	db FinalizerDB, votingEpoch *utils.Epoch, from, to time.Time, sender common.Address,
+) ([]FinalizationStats, error) {
+	logs, err := database.FetchAll(from.Unix(), to.Unix(), func(from, to int64) ([]database.Log, error) {
+		return db.FetchLogsByAddressAndTopic0(r.address, r.topic0PMR, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	txs, err := database.FetchAll(from.Unix(), to.Unix(), func(from, to int64) ([]database.Transaction, error) {
+		return db.FetchTransactionsByAddressAndSelector(r.address, r.relaySelector, from, to)
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(txs, func(i, j int) bool { return txPosition(&txs[i]).before(txPosition(&txs[j])) })
+
+	relayTxs := make(map[relayedRoundKey]*database.Transaction)
+	attempted := make(map[relayedRoundKey]bool)
+	for i := range txs {
+		calldata, err := hex.DecodeString(txs[i].Input)
+		if err != nil {
+			continue
+		}
+		key, _, ok := decodeRelayedRound(calldata)
+		if !ok {
+			continue
+		}
+		if common.HexToAddress(txs[i].FromAddress) == sender {
+			attempted[key] = true
+		}
+		if _, found := relayTxs[key]; !found && txs[i].Status == 1 {
+			relayTxs[key] = &txs[i]
+		}
+	}
+
+	result := make([]FinalizationStats, 0, len(logs))
+	for _, log := range logs {
+		data, err := shared.ParseProtocolMessageRelayedEvent(r.relay, log)
+		if err != nil {
+			return nil, err
+		}
+		key := relayedRoundKey{votingRoundId: data.VotingRoundId, protocolId: data.ProtocolId}
+		finalizedAt := time.Unix(int64(log.Timestamp), 0)
+		stats := FinalizationStats{
+			ProtocolId:     data.ProtocolId,
+			VotingRoundId:  data.VotingRoundId,
+			MerkleRoot:     common.Hash(data.MerkleRoot).Hex(),
+			SecureRandom:   data.IsSecureRandom,
+			FinalizedAt:    finalizedAt,
+			LatencySeconds: int64(finalizedAt.Sub(votingEpoch.EndTime(int64(data.VotingRoundId))) / time.Second),
+			Attempted:      attempted[key],
+		}
+		if tx, ok := relayTxs[key]; ok {
+			stats.TxHash = "0x" + tx.Hash
+			stats.Finalizer = common.HexToAddress(tx.FromAddress).Hex()
+			stats.Won = common.HexToAddress(tx.FromAddress) == sender
+		}
+		result = append(result, stats)
+	}
+	return result, nil
+}
+
+// WriteFinalizationStatsCSV writes the statistics as CSV with a header row
+func WriteFinalizationStatsCSV(w io.Writer, stats []FinalizationStats) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(finalizationStatsHeader); err != nil {
+		return errors.Wrap(err, "cannot write csv header")
+	}
+	for _, s := range stats {
+		gasUsed := ""
+		if s.GasUsed > 0 {
+			gasUsed = strconv.FormatUint(s.GasUsed, 10)
+		}
+		err := writer.Write([]string{
+			strconv.Itoa(int(s.ProtocolId)),
+			strconv.FormatUint(uint64(s.VotingRoundId), 10),
+			s.MerkleRoot,
+			strconv.FormatBool(s.SecureRandom),
+			s.FinalizedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(s.LatencySeconds, 10),
+			s.TxHash,
+			s.Finalizer,
+			gasUsed,
+			strconv.FormatBool(s.Won),
+			strconv.FormatBool(s.Attempted),
+		})
+		if err != nil {
+			return errors.Wrap(err, "cannot write csv row")
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
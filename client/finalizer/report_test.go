@@ -0,0 +1,101 @@
+package finalizer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/relay"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// Indexer logs and relay txs filtered by timestamp
+type testReportDB struct {
+	testLogsDB
+	txs []database.Transaction
+}
+
+func (db *testReportDB) FetchTransactionsByAddressAndSelector(
+	address common.Address, selector []byte, from, to int64,
+) ([]database.Transaction, error) {
+	var result []database.Transaction
+	for _, tx := range db.txs {
+		if int64(tx.Timestamp) > from && int64(tx.Timestamp) <= to {
+			result = append(result, tx)
+		}
+	}
+	return result, nil
+}
+
+func TestFinalizationStats(t *testing.T) {
+	relayABI, err := relay.RelayMetaData.GetAbi()
+	require.NoError(t, err)
+	topic0PMR, err := chain.EventIDFromMetadata(relay.RelayMetaData, "ProtocolMessageRelayed")
+	require.NoError(t, err)
+	relayContract, err := relay.NewRelay(relayContractAddress, nil)
+	require.NoError(t, err)
+	relayClient := &relayContractClient{relay: relayContract, topic0PMR: topic0PMR}
+
+	relayedLog := func(protocolId, votingRoundId int64, timestamp uint64) database.Log {
+		data, err := relayABI.Events["ProtocolMessageRelayed"].Inputs.NonIndexed().Pack(true, [32]byte(common.HexToHash("0x02")))
+		require.NoError(t, err)
+		return database.Log{
+			Data:      hex.EncodeToString(data),
+			Topic0:    topic0PMR,
+			Topic1:    common.BigToHash(big.NewInt(protocolId)).Hex(),
+			Topic2:    common.BigToHash(big.NewInt(votingRoundId)).Hex(),
+			Topic3:    "NULL",
+			Timestamp: timestamp,
+		}
+	}
+	f := newRelayValidationFixture(t) // relays protocol 100, voting round 120
+	calldata := hex.EncodeToString(f.calldata(t, 0, 2))
+	us, other := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+	relayTx := func(from common.Address, transactionIndex, status uint64) database.Transaction {
+		return database.Transaction{
+			Hash:             strings.Repeat(hex.EncodeToString([]byte{byte(transactionIndex)}), 32),
+			Input:            calldata,
+			BlockNumber:      10,
+			TransactionIndex: transactionIndex,
+			FromAddress:      hex.EncodeToString(from.Bytes()),
+			Status:           status,
+			Timestamp:        10900,
+		}
+	}
+	db := &testReportDB{
+		testLogsDB: testLogsDB{logs: []database.Log{relayedLog(100, 120, 10900), relayedLog(200, 120, 10950)}},
+		// our tx failed, the tx of the other finalizer came first
+		txs: []database.Transaction{relayTx(us, 2, 1), relayTx(other, 1, 1), relayTx(us, 0, 0)},
+	}
+	votingEpoch := utils.NewEpoch(time.Unix(0, 0), 90*time.Second)
+
+	stats, err := relayClient.finalizationStats(db, votingEpoch, time.Unix(10000, 0), time.Unix(11000, 0), us)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	require.Equal(t, FinalizationStats{
+		ProtocolId:     100,
+		VotingRoundId:  120,
+		MerkleRoot:     common.HexToHash("0x02").Hex(),
+		SecureRandom:   true,
+		FinalizedAt:    time.Unix(10900, 0),
+		LatencySeconds: 10900 - 121*90,
+		TxHash:         "0x" + strings.Repeat("01", 32),
+		Finalizer:      other.Hex(),
+		Attempted:      true,
+	}, stats[0])
+	require.Empty(t, stats[1].Finalizer, "relay tx of protocol 200 not found")
+	require.False(t, stats[1].Attempted)
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteFinalizationStatsCSV(&buffer, stats[:1]))
+	require.Equal(t, strings.Join(finalizationStatsHeader, ",")+"\n"+
+		"100,120,"+common.HexToHash("0x02").Hex()+",true,1970-01-01T03:01:40Z,10,0x"+strings.Repeat("01", 32)+","+other.Hex()+",,false,true\n",
+		buffer.String())
+}
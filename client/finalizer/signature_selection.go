@@ -0,0 +1,137 @@
+package finalizer
+
+import (
+	"flare-tlc/client/config"
+	"math"
+
+	"golang.org/x/exp/slices"
+)
+
+// Calldata gas of a zero and a non-zero byte (EIP-2028)
+const (
+	calldataZeroByteGas    = 4
+	calldataNonZeroByteGas = 16
+)
+
+// Selects the signatures included in the relay tx from the collected signatures,
+// so that their weight is above the threshold of the protocol. The selected
+// signatures are sorted by voter index, as required by the Relay contract.
+// If the collected weight is not above the threshold, all signatures are selected.
+func selectSignatures(strategy string, payloads []*signedPayload, policy *signingPolicy, threshold uint16) []*signedPayload {
+	var selected []*signedPayload
+	switch strategy {
+	case config.SignatureSelectionFirstCome:
+		selected = selectFirstCome(payloads, policy, threshold)
+	case config.SignatureSelectionSmallestCalldata:
+		selected = selectSmallestCalldata(payloads, policy, threshold)
+	default:
+		selected = selectLargestWeight(payloads, policy, threshold)
+	}
+
+	// sort selected payloads by index
+	slices.SortFunc(selected, func(p, q *signedPayload) bool {
+		return p.index < q.index
+	})
+	return selected
+}
+
+// Selects the signatures in the order of arrival
+func selectFirstCome(payloads []*signedPayload, policy *signingPolicy, threshold uint16) []*signedPayload {
+	sorted := slices.Clone(payloads)
+	slices.SortFunc(sorted, func(p, q *signedPayload) bool {
+		return p.arrival < q.arrival
+	})
+	return selectGreedy(sorted, policy, threshold)
+}
+
+// Selects the signatures with the largest weights first, the selection has the
+// minimal number of signatures
+func selectLargestWeight(payloads []*signedPayload, policy *signingPolicy, threshold uint16) []*signedPayload {
+	sorted := slices.Clone(payloads)
+	slices.SortStableFunc(sorted, func(p, q *signedPayload) bool {
+		return policy.voters.VoterWeight(p.index) > policy.voters.VoterWeight(q.index)
+	})
+	return selectGreedy(sorted, policy, threshold)
+}
+
+// Selects signatures in the given order until the threshold is reached
+func selectGreedy(payloads []*signedPayload, policy *signingPolicy, threshold uint16) []*signedPayload {
+	weight := 0
+	var selected []*signedPayload
+	for _, payload := range payloads {
+		weight += int(policy.voters.VoterWeight(payload.index))
+		selected = append(selected, payload)
+		if weight > int(threshold) {
+			break
+		}
+	}
+	return selected
+}
+
+// Selects the signatures with the lowest total calldata gas. Signatures differ in
+// the number of zero bytes, so this is a 0/1 knapsack over the voter weights,
+// where weights above the threshold are all counted as threshold + 1.
+func selectSmallestCalldata(payloads []*signedPayload, policy *signingPolicy, threshold uint16) []*signedPayload {
+	target := int(threshold) + 1
+	total := 0
+	for _, payload := range payloads {
+		total += int(policy.voters.VoterWeight(payload.index))
+	}
+	if total < target {
+		return slices.Clone(payloads)
+	}
+
+	// cost[w] is the lowest gas of a selection with (capped) weight w, prev[i][w]
+	// the weight of that selection before adding payload i, -1 if i is not added
+	cost := make([]int, target+1)
+	for w := 1; w <= target; w++ {
+		cost[w] = math.MaxInt
+	}
+	prev := make([][]int32, len(payloads))
+	for i, payload := range payloads {
+		weight := int(policy.voters.VoterWeight(payload.index))
+		gas := relaySignatureGas(payload)
+		prev[i] = make([]int32, target+1)
+		for w := range prev[i] {
+			prev[i][w] = -1
+		}
+		// new weights are larger than w (or capped), so iterating down uses
+		// the costs before adding payload i
+		for w := target; w >= 0; w-- {
+			if cost[w] == math.MaxInt {
+				continue
+			}
+			next := min(w+weight, target)
+			if c := cost[w] + gas; c < cost[next] {
+				cost[next] = c
+				prev[i][next] = int32(w)
+			}
+		}
+	}
+
+	var selected []*signedPayload
+	w := target
+	for i := len(payloads) - 1; i >= 0 && w > 0; i-- {
+		if prev[i][w] >= 0 {
+			selected = append(selected, payloads[i])
+			w = int(prev[i][w])
+		}
+	}
+	return selected
+}
+
+// Calldata gas of the signature and the voter index in the relay calldata
+func relaySignatureGas(payload *signedPayload) int {
+	gas := 0
+	for _, b := range payload.signature {
+		gas += calldataByteGas(b)
+	}
+	return gas + calldataByteGas(byte(payload.index>>8)) + calldataByteGas(byte(payload.index))
+}
+
+func calldataByteGas(b byte) int {
+	if b == 0 {
+		return calldataZeroByteGas
+	}
+	return calldataNonZeroByteGas
+}
@@ -0,0 +1,66 @@
+package finalizer
+
+import (
+	"bytes"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared/voters"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectSignatures(t *testing.T) {
+	weights := []uint16{10, 40, 30, 30, 20}
+	addresses := make([]common.Address, len(weights))
+	for i := range addresses {
+		addresses[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	policy := &signingPolicy{
+		threshold: 59,
+		voters:    voters.NewVoterSet(addresses, weights),
+	}
+
+	// arrival order 4, 0, 2, 3, 1
+	arrivals := []int{1, 4, 2, 3, 0}
+	payloads := make([]*signedPayload, len(weights))
+	for i := range payloads {
+		payloads[i] = &signedPayload{
+			index:     i,
+			arrival:   arrivals[i],
+			signature: bytes.Repeat([]byte{0x01}, 65),
+		}
+	}
+	// signatures of voters 2 and 3 are cheaper than the one of voter 1
+	payloads[2].signature = bytes.Repeat([]byte{0x00}, 65)
+	payloads[3].signature[1] = 0
+
+	indices := func(selected []*signedPayload) []int {
+		var result []int
+		for _, p := range selected {
+			result = append(result, p.index)
+		}
+		return result
+	}
+
+	tests := []struct {
+		strategy string
+		expected []int
+	}{
+		{config.SignatureSelectionFirstCome, []int{0, 2, 4}},
+		{config.SignatureSelectionLargestWeight, []int{1, 2}},
+		{"", []int{1, 2}},
+		{config.SignatureSelectionSmallestCalldata, []int{2, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.strategy, func(t *testing.T) {
+			require.Equal(t, test.expected, indices(selectSignatures(test.strategy, payloads, policy, policy.threshold)))
+		})
+	}
+
+	t.Run("below threshold", func(t *testing.T) {
+		selected := selectSignatures(config.SignatureSelectionSmallestCalldata, payloads[:2], policy, policy.threshold)
+		require.Equal(t, []int{0, 1}, indices(selected))
+	})
+}
@@ -39,6 +39,16 @@ func newSigningPolicy(r *relay.RelaySigningPolicyInitialized) *signingPolicy {
 	}
 }
 
+// Returned when adding a signing policy whose previous reward epochs are missing
+type signingPolicyGapError struct {
+	last int64 // reward epoch id of the last stored policy
+	next int64 // reward epoch id of the added policy
+}
+
+func (e signingPolicyGapError) Error() string {
+	return fmt.Sprintf("missing signing policy for reward epoch id %d", e.next-1)
+}
+
 type signingPolicyStorage struct {
 
 	// sorted list of signing policies, sorted by rewardEpochId (and also by startVotingRoundId)
@@ -75,7 +85,9 @@ func (s *signingPolicyStorage) Add(sp *signingPolicy) error {
 
 	if len(s.spList) > 0 {
 		// check consistency, previous epoch should be already added
-		if s.spList[len(s.spList)-1].rewardEpochId != sp.rewardEpochId-1 {
+		if last := s.spList[len(s.spList)-1].rewardEpochId; last < sp.rewardEpochId-1 {
+			return signingPolicyGapError{last: last, next: sp.rewardEpochId}
+		} else if last != sp.rewardEpochId-1 {
 			return fmt.Errorf("missing signing policy for reward epoch id %d", sp.rewardEpochId-1)
 		}
 		// should be sorted by voting round id, should not happen
@@ -102,6 +114,14 @@ func (s *signingPolicyStorage) GetForVotingRound(votingRoundId uint32) (*signing
 	return sp, sp.rewardEpochId == s.spList[len(s.spList)-1].rewardEpochId
 }
 
+// Returns a copy of the list of signing policies
+func (s *signingPolicyStorage) All() []*signingPolicy {
+	s.Lock()
+	defer s.Unlock()
+
+	return append([]*signingPolicy(nil), s.spList...)
+}
+
 func (s *signingPolicyStorage) First() *signingPolicy {
 	s.Lock()
 	defer s.Unlock()
@@ -112,6 +132,31 @@ func (s *signingPolicyStorage) First() *signingPolicy {
 	return s.spList[0]
 }
 
+func (s *signingPolicyStorage) Last() *signingPolicy {
+	s.Lock()
+	defer s.Unlock()
+
+	if len(s.spList) == 0 {
+		return nil
+	}
+	return s.spList[len(s.spList)-1]
+}
+
+// Removes all signing policies with reward epoch id <= than the provided one.
+// Returns the list of removed reward epoch ids.
+func (s *signingPolicyStorage) RemoveByRewardEpoch(rewardEpochId int64) []uint32 {
+	s.Lock()
+	defer s.Unlock()
+
+	var removedRewardEpochIds []uint32
+	for len(s.spList) > 0 && s.spList[0].rewardEpochId <= rewardEpochId {
+		removedRewardEpochIds = append(removedRewardEpochIds, uint32(s.spList[0].rewardEpochId))
+		s.spList[0] = nil
+		s.spList = s.spList[1:]
+	}
+	return removedRewardEpochIds
+}
+
 // Removes all signing policies with start voting round id <= than the provided one.
 // Returns the list of removed reward epoch ids.
 func (s *signingPolicyStorage) RemoveByVotingRound(votingRoundId uint32) []uint32 {
@@ -127,6 +172,21 @@ func (s *signingPolicyStorage) RemoveByVotingRound(votingRoundId uint32) []uint3
 	return removedRewardEpochIds
 }
 
+// Removes all signing policies with block timestamp >= than the provided one.
+// Returns the list of removed reward epoch ids.
+func (s *signingPolicyStorage) RemoveFromTimestamp(timestamp uint64) []uint32 {
+	s.Lock()
+	defer s.Unlock()
+
+	var removedRewardEpochIds []uint32
+	for len(s.spList) > 0 && s.spList[len(s.spList)-1].blockTimestamp >= timestamp {
+		removedRewardEpochIds = append(removedRewardEpochIds, uint32(s.spList[len(s.spList)-1].rewardEpochId))
+		s.spList[len(s.spList)-1] = nil
+		s.spList = s.spList[:len(s.spList)-1]
+	}
+	return removedRewardEpochIds
+}
+
 func (s *signingPolicy) Encode() ([]byte, error) {
 	buffer := bytes.NewBuffer(nil)
 
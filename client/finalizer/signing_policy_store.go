@@ -0,0 +1,130 @@
+package finalizer
+
+import (
+	"encoding/hex"
+	"flare-tlc/database"
+	"flare-tlc/utils/contracts/relay"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// Durable storage for signing policies, so that they are loaded on restart
+// instead of re-scanning the SigningPolicyInitialized logs.
+type signingPolicyStore interface {
+	Save(*relay.RelaySigningPolicyInitialized) error
+	DeleteUpTo(rewardEpochId uint32) error
+	DeleteFrom(rewardEpochId uint32) error
+	Load() ([]*relay.RelaySigningPolicyInitialized, error)
+}
+
+type signingPolicyStoreDB struct {
+	db *gorm.DB
+}
+
+func newSigningPolicyStoreDB(db *gorm.DB) (*signingPolicyStoreDB, error) {
+	if err := db.AutoMigrate(&database.SigningPolicy{}); err != nil {
+		return nil, fmt.Errorf("error migrating signing policy table: %w", err)
+	}
+	return &signingPolicyStoreDB{db: db}, nil
+}
+
+func (s *signingPolicyStoreDB) Save(policy *relay.RelaySigningPolicyInitialized) error {
+	return database.CreateSigningPolicy(s.db, signingPolicyToDB(policy))
+}
+
+func (s *signingPolicyStoreDB) DeleteUpTo(rewardEpochId uint32) error {
+	return database.DeleteSigningPoliciesUpTo(s.db, rewardEpochId)
+}
+
+func (s *signingPolicyStoreDB) DeleteFrom(rewardEpochId uint32) error {
+	return database.DeleteSigningPoliciesFrom(s.db, rewardEpochId)
+}
+
+func (s *signingPolicyStoreDB) Load() ([]*relay.RelaySigningPolicyInitialized, error) {
+	dbPolicies, err := database.FetchSigningPolicies(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make([]*relay.RelaySigningPolicyInitialized, 0, len(dbPolicies))
+	for i := range dbPolicies {
+		policy, err := signingPolicyFromDB(&dbPolicies[i])
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	return policies, nil
+}
+
+func signingPolicyToDB(policy *relay.RelaySigningPolicyInitialized) *database.SigningPolicy {
+	voters := make([]string, len(policy.Voters))
+	for i, voter := range policy.Voters {
+		voters[i] = voter.Hex()
+	}
+	weights := make([]string, len(policy.Weights))
+	for i, weight := range policy.Weights {
+		weights[i] = strconv.FormatUint(uint64(weight), 10)
+	}
+	seed := "0"
+	if policy.Seed != nil {
+		seed = policy.Seed.String()
+	}
+	return &database.SigningPolicy{
+		RewardEpochId:      uint32(policy.RewardEpochId.Uint64()),
+		StartVotingRoundId: policy.StartVotingRoundId,
+		Threshold:          policy.Threshold,
+		Seed:               seed,
+		Voters:             strings.Join(voters, ","),
+		Weights:            strings.Join(weights, ","),
+		SigningPolicyBytes: hex.EncodeToString(policy.SigningPolicyBytes),
+		Timestamp:          policy.Timestamp,
+	}
+}
+
+func signingPolicyFromDB(dbPolicy *database.SigningPolicy) (*relay.RelaySigningPolicyInitialized, error) {
+	seed, ok := new(big.Int).SetString(dbPolicy.Seed, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid seed %s for persisted signing policy %d", dbPolicy.Seed, dbPolicy.RewardEpochId)
+	}
+	rawBytes, err := hex.DecodeString(dbPolicy.SigningPolicyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing policy bytes for persisted signing policy %d: %w", dbPolicy.RewardEpochId, err)
+	}
+
+	var voters []common.Address
+	var weights []uint16
+	if dbPolicy.Voters != "" {
+		for _, voter := range strings.Split(dbPolicy.Voters, ",") {
+			voters = append(voters, common.HexToAddress(voter))
+		}
+	}
+	if dbPolicy.Weights != "" {
+		for _, weightStr := range strings.Split(dbPolicy.Weights, ",") {
+			weight, err := strconv.ParseUint(weightStr, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("invalid weight %s for persisted signing policy %d: %w", weightStr, dbPolicy.RewardEpochId, err)
+			}
+			weights = append(weights, uint16(weight))
+		}
+	}
+	if len(voters) != len(weights) {
+		return nil, fmt.Errorf("persisted signing policy %d has %d voters and %d weights", dbPolicy.RewardEpochId, len(voters), len(weights))
+	}
+
+	return &relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      new(big.Int).SetUint64(uint64(dbPolicy.RewardEpochId)),
+		StartVotingRoundId: dbPolicy.StartVotingRoundId,
+		Threshold:          dbPolicy.Threshold,
+		Seed:               seed,
+		Voters:             voters,
+		Weights:            weights,
+		SigningPolicyBytes: rawBytes,
+		Timestamp:          dbPolicy.Timestamp,
+	}, nil
+}
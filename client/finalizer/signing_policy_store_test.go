@@ -0,0 +1,98 @@
+package finalizer
+
+import (
+	"flare-tlc/utils/contracts/relay"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigningPolicyStoreConversion(t *testing.T) {
+	policy := newTestPolicyData(5, 100)
+
+	restored, err := signingPolicyFromDB(signingPolicyToDB(policy))
+	require.NoError(t, err)
+	require.Equal(t, policy, restored)
+}
+
+func TestLoadPersistedSigningPolicies(t *testing.T) {
+	store := &testSigningPolicyStore{
+		policies: []*relay.RelaySigningPolicyInitialized{
+			newTestPolicyData(1, 10),
+			newTestPolicyData(2, 20),
+			newTestPolicyData(3, 30),
+		},
+	}
+	c := &finalizerClient{
+		signingPolicyStorage: newSigningPolicyStorage(),
+		finalizerContext:     &finalizerContext{},
+		policyStore:          store,
+	}
+
+	startTime := c.loadPersistedSigningPolicies(time.Unix(15, 0))
+	require.Equal(t, time.Unix(30, 0), startTime)
+	require.EqualValues(t, 2, c.signingPolicyStorage.First().rewardEpochId)
+	require.EqualValues(t, 3, c.signingPolicyStorage.Last().rewardEpochId)
+
+	// policies with a gap are not used
+	store.policies = []*relay.RelaySigningPolicyInitialized{
+		newTestPolicyData(1, 10),
+		newTestPolicyData(3, 30),
+	}
+	c.signingPolicyStorage = newSigningPolicyStorage()
+
+	startTime = c.loadPersistedSigningPolicies(time.Unix(5, 0))
+	require.Equal(t, time.Unix(5, 0), startTime)
+	require.Nil(t, c.signingPolicyStorage.First())
+}
+
+func newTestPolicyData(rewardEpochId int64, timestamp uint64) *relay.RelaySigningPolicyInitialized {
+	return &relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      big.NewInt(rewardEpochId),
+		StartVotingRoundId: uint32(rewardEpochId * 100),
+		Threshold:          100,
+		Seed:               big.NewInt(rewardEpochId + 1000),
+		Voters:             []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")},
+		Weights:            []uint16{150, 50},
+		SigningPolicyBytes: []byte{0x01, 0x02},
+		Timestamp:          timestamp,
+	}
+}
+
+type testSigningPolicyStore struct {
+	policies []*relay.RelaySigningPolicyInitialized
+}
+
+func (s *testSigningPolicyStore) Save(policy *relay.RelaySigningPolicyInitialized) error {
+	s.policies = append(s.policies, policy)
+	return nil
+}
+
+func (s *testSigningPolicyStore) DeleteUpTo(rewardEpochId uint32) error {
+	var kept []*relay.RelaySigningPolicyInitialized
+	for _, policy := range s.policies {
+		if policy.RewardEpochId.Uint64() > uint64(rewardEpochId) {
+			kept = append(kept, policy)
+		}
+	}
+	s.policies = kept
+	return nil
+}
+
+func (s *testSigningPolicyStore) DeleteFrom(rewardEpochId uint32) error {
+	var kept []*relay.RelaySigningPolicyInitialized
+	for _, policy := range s.policies {
+		if policy.RewardEpochId.Uint64() < uint64(rewardEpochId) {
+			kept = append(kept, policy)
+		}
+	}
+	s.policies = kept
+	return nil
+}
+
+func (s *testSigningPolicyStore) Load() ([]*relay.RelaySigningPolicyInitialized, error) {
+	return s.policies, nil
+}
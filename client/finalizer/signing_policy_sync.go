@@ -0,0 +1,96 @@
+package finalizer
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// The logs of the startup sync are fetched in windows of this length, the
+	// progress is logged after each window
+	signingPolicySyncWindow = 24 * time.Hour
+
+	// windows fetched ahead while the policies of the previous ones are added
+	signingPolicySyncPrefetch = 2
+)
+
+type signingPolicySyncBatch struct {
+	policies []signingPolicyListenerResponse
+	to       int64 // end of the window
+}
+
+// Result of the startup sync of the signing policies
+type signingPolicySyncResult struct {
+	// policies fetched and added to the storage
+	fetched, added int
+	// timestamp of the last fetched policy
+	lastTimestamp int64
+}
+
+// Loads the signing policies initialized in the timestamp range (from, to] into the
+// storage before the listener starts, instead of receiving the historical policies
+// one per tick. The range is fetched in windows by one goroutine while the other adds
+// the policies of the fetched windows, policies that are already stored or of reward
+// epochs before the starting reward epoch are skipped.
+func (c *finalizerClient) syncSigningPolicies(ctx context.Context, from, to int64, window time.Duration) (signingPolicySyncResult, error) {
+	batches := make(chan signingPolicySyncBatch, signingPolicySyncPrefetch)
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		defer close(batches)
+		windowSeconds := int64(window / time.Second)
+		// the range is fetched at least once, also if it is empty
+		for start := from; ; {
+			end := min(start+windowSeconds, to)
+			policies, err := c.relayClient.FetchSigningPolicies(c.db, start, end)
+			if err != nil {
+				return err
+			}
+			select {
+			case batches <- signingPolicySyncBatch{policies: policies, to: end}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if end >= to {
+				return nil
+			}
+			start = end
+		}
+	})
+
+	var result signingPolicySyncResult
+	eg.Go(func() error {
+		started := time.Now()
+		for batch := range batches {
+			for _, sp := range batch.policies {
+				result.fetched++
+				result.lastTimestamp = sp.timestamp
+				policy := newSigningPolicy(sp.policyData)
+				if policy.rewardEpochId < c.finalizerContext.startingRewardEpoch {
+					continue
+				}
+				if last := c.signingPolicyStorage.Last(); last != nil && policy.rewardEpochId <= last.rewardEpochId {
+					// already loaded from the persisted policies
+					continue
+				}
+				if err := c.signingPolicyStorage.Add(policy); err != nil {
+					return err
+				}
+				c.persistSigningPolicy(sp.policyData)
+				c.refreshVoterRegistry(policy)
+				result.added++
+			}
+			progress := int64(100)
+			if to > from {
+				progress = (batch.to - from) * 100 / (to - from)
+			}
+			logger.Info("Signing policy sync: %d%% of the range, up to %s, %d policies added in %v",
+				progress, time.Unix(batch.to, 0).UTC().Format(time.RFC3339),
+				result.added, time.Since(started).Round(time.Millisecond))
+		}
+		return nil
+	})
+	err := eg.Wait()
+	return result, err
+}
@@ -0,0 +1,51 @@
+package finalizer
+
+import (
+	"context"
+	"flare-tlc/utils/contracts/relay"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncSigningPolicies(t *testing.T) {
+	db := &testLogsDB{}
+	for _, rewardEpochId := range []int64{1, 2, 3, 4, 5} {
+		log, err := encodeSPILog(newTestPolicyData(rewardEpochId, uint64(rewardEpochId*10)))
+		require.NoError(t, err)
+		db.logs = append(db.logs, *log)
+	}
+	relayContract, err := relay.NewRelay(relayContractAddress, nil)
+	require.NoError(t, err)
+	store := &testSigningPolicyStore{}
+	c := &finalizerClient{
+		db:                   db,
+		signingPolicyStorage: newSigningPolicyStorage(),
+		relayClient: &relayContractClient{
+			relay:     relayContract,
+			address:   relayContractAddress,
+			topic0SPI: topicSPIHex,
+		},
+		finalizerContext: &finalizerContext{startingRewardEpoch: 2},
+		policyStore:      store,
+	}
+	// policy 2 was loaded from the persisted policies
+	require.NoError(t, c.signingPolicyStorage.Add(newSigningPolicy(newTestPolicyData(2, 20))))
+
+	// windows (0, 15], (15, 30], (30, 45], (45, 55]
+	result, err := c.syncSigningPolicies(context.Background(), 0, 55, 15*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, signingPolicySyncResult{fetched: 5, added: 3, lastTimestamp: 50}, result)
+	var rewardEpochIds []int64
+	for _, sp := range c.signingPolicyStorage.All() {
+		rewardEpochIds = append(rewardEpochIds, sp.rewardEpochId)
+	}
+	require.Equal(t, []int64{2, 3, 4, 5}, rewardEpochIds)
+	require.Len(t, store.policies, 3)
+
+	// an empty range is fetched once
+	result, err = c.syncSigningPolicies(context.Background(), 60, 60, 15*time.Second)
+	require.NoError(t, err)
+	require.Equal(t, signingPolicySyncResult{}, result)
+}
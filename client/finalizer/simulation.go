@@ -0,0 +1,84 @@
+package finalizer
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/utils"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/epoch"
+
+	"github.com/pkg/errors"
+)
+
+// Dependencies of a finalizer that runs without a chain, see NewSimulatedFinalizerClient
+type Simulation struct {
+	DB        FinalizerDB
+	EthClient RelayEthClient
+	Signer    credentials.Signer
+
+	// Epoch settings of the Relay contract
+	VotingEpoch *utils.Epoch
+	RewardEpoch *utils.IntEpoch
+
+	// optional, the real clock if not set
+	Clock utils.Clock
+}
+
+// NewSimulatedFinalizerClient creates the finalizer with the database and the relay eth
+// client of the simulation, e.g. an in-memory database with recorded logs and a mock that
+// records the relay txs. The finalizer and listener settings are read from the config,
+// the settings that require the chain (auto start offset, voter registry verification,
+// reorg detection, websocket listeners and persistence) are ignored.
+func NewSimulatedFinalizerClient(cfg *config.ClientConfig, sim Simulation) (*finalizerClient, error) {
+	if sim.DB == nil || sim.EthClient == nil || sim.Signer == nil {
+		return nil, errors.New("simulation requires the database, eth client and signer")
+	}
+	if sim.VotingEpoch == nil || sim.RewardEpoch == nil {
+		return nil, errors.New("simulation requires the voting and reward epochs")
+	}
+	clock := sim.Clock
+	if clock == nil {
+		clock = utils.RealClock
+	}
+
+	relayClient, err := NewRelayContractClient(nil, cfg.ContractAddresses.Relay, &cfg.RelayGas, sim.Signer, nil, &cfg.Retry)
+	if err != nil {
+		return nil, err
+	}
+	relayClient.ethClient = sim.EthClient
+	relayClient.clock = clock
+	listenerIntervals := cfg.Listeners.Intervals(cfg.Chain.ChainID)
+	relayClient.listenerIntervals = listenerIntervals
+	relayClient.listenerBuffers = cfg.Listeners.Buffers()
+
+	startingVotingRound := cfg.Finalizer.StartingVotingRound
+	if startingVotingRound == 0 {
+		startingVotingRound = uint32(sim.VotingEpoch.EpochIndex(clock.Now()))
+	}
+	finalizerContext := newFinalizerContextWithEpochs(
+		cfg, epoch.NewSchedule(sim.VotingEpoch, sim.RewardEpoch), startingVotingRound, cfg.Finalizer.StartOffset)
+
+	submissionStorage := newSubmissionStorage(cfg.Finalizer.MaxRetainedRounds)
+	queueProcessor := newFinalizerQueueProcessor(sim.DB, submissionStorage, relayClient, finalizerContext)
+	queueProcessor.setClock(clock)
+	submissionClient, err := NewSubmissionContractClient(cfg.ContractAddresses.Submission)
+	if err != nil {
+		return nil, err
+	}
+	submissionClient.clock = clock
+	submissionClient.listenerIntervals = listenerIntervals
+
+	return &finalizerClient{
+		db:                    sim.DB,
+		submitters:            newSubmitterTracker(&cfg.Finalizer.SubmitterBan),
+		submitterFilter:       newSubmitterFilter(&cfg.Finalizer.Submitters),
+		finalizationProviders: newFinalizationProviders(cfg.Finalizer.FinalizationProviders),
+		relayClient:           relayClient,
+		signingPolicyStorage:  newSigningPolicyStorage(),
+		submissionStorage:     submissionStorage,
+		submissionClient:      submissionClient,
+		queueProcessor:        queueProcessor,
+		finalizerContext:      finalizerContext,
+		listenerIntervals:     listenerIntervals,
+		clock:                 clock,
+	}, nil
+}
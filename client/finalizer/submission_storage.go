@@ -4,14 +4,18 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type messageData struct {
 	payload          []*signedPayload
 	weight           uint16
 	signingPolicy    *signingPolicy
+	threshold        uint16 // of the protocol, the highest threshold the payloads were added with
 	thresholdReached bool
+	received         int // number of added payloads
 }
 
 type MessageThresholdProvider interface {
@@ -25,6 +29,10 @@ type votingRoundKey struct {
 
 type votingRoundItem struct {
 	msgMap map[votingRoundKey]*messageData
+
+	// Block timestamps of the first and the last submission of the voting round
+	firstTimestamp int64
+	lastTimestamp  int64
 }
 
 type submissionStorage struct {
@@ -32,6 +40,12 @@ type submissionStorage struct {
 	// We use two maps instead of one to make it easier to remove a voting round
 	vrMap map[uint32]*votingRoundItem
 
+	// Max number of voting rounds retained, the oldest rounds are evicted first. 0 means no limit.
+	maxRounds int
+
+	// If set, the signers are verified against the registered voters
+	voterRegistry *voterRegistryCache
+
 	// mutex
 	sync.Mutex
 }
@@ -39,6 +53,50 @@ type submissionStorage struct {
 type addPayloadResult struct {
 	message          *messageData
 	thresholdReached bool // true if the threshold was reached after adding this payload (and was below before)
+	duplicate        bool // true if the voter already signed the message, the payload was not added
+}
+
+// Error of a signer that is not a voter of the signing policy or the voter registry
+type notInPolicyError struct {
+	error
+}
+
+func (e notInPolicyError) Unwrap() error {
+	return e.error
+}
+
+// Error of a signature that does not recover to the signer of the payload
+type spoofedSignatureError struct {
+	error
+}
+
+func (e spoofedSignatureError) Unwrap() error {
+	return e.error
+}
+
+// Recovers the signer of the payload from the signature of the raw message. The signer
+// and the message hash of the payload are not trusted, so that a payload not decoded by
+// decodeSignedPayload can not be counted for a voter that did not sign it.
+func verifySignature(p *signedPayload) error {
+	if len(p.rawMessage) != relayMessageLength || len(p.signature) != 65 {
+		return spoofedSignatureError{fmt.Errorf("signature of signer %s is missing", p.signer.Hex())}
+	}
+	if p.signature[0] != 27 && p.signature[0] != 28 {
+		return spoofedSignatureError{fmt.Errorf("%w: %d", errInvalidSignatureV, p.signature[0])}
+	}
+	messageHash := accounts.TextHash(crypto.Keccak256(p.rawMessage))
+	if common.BytesToHash(messageHash) != p.messageHash {
+		return spoofedSignatureError{fmt.Errorf("message hash %s does not match the signed message", p.messageHash.Hex())}
+	}
+	rsv := transformSignature(p.signature)
+	pk, err := crypto.SigToPub(messageHash, rsv[:])
+	if err != nil {
+		return spoofedSignatureError{fmt.Errorf("%w: %v", errInvalidSignature, err)}
+	}
+	if signer := crypto.PubkeyToAddress(*pk); signer != p.signer {
+		return spoofedSignatureError{fmt.Errorf("signature recovers to %s, not to the signer %s", signer.Hex(), p.signer.Hex())}
+	}
+	return nil
 }
 
 func newMessageData(sp *signingPolicy) *messageData {
@@ -48,44 +106,73 @@ func newMessageData(sp *signingPolicy) *messageData {
 	}
 }
 
-func (m *messageData) addPayload(p *signedPayload, threshold uint16) error {
+// Returns true if the payload was not added since the voter already signed the message
+func (m *messageData) addPayload(p *signedPayload, threshold uint16, voterRegistry *voterRegistryCache) (bool, error) {
 	voterIndex := m.signingPolicy.voters.VoterIndex(p.signer)
 	if voterIndex < 0 {
-		return fmt.Errorf("signer %s is not a registered voter in the current reward epoch", p.signer.Hex())
+		return false, notInPolicyError{fmt.Errorf("signer %s is not a registered voter in the current reward epoch", p.signer.Hex())}
+	}
+	if p.index >= 0 && p.index != voterIndex {
+		// added before with the index of another signing policy
+		return false, notInPolicyError{fmt.Errorf("signer %s has voter index %d, not %d", p.signer.Hex(), voterIndex, p.index)}
+	}
+	if err := voterRegistry.Verify(m.signingPolicy, p.signer, voterIndex); err != nil {
+		return false, notInPolicyError{err}
 	}
 	if m.payload[voterIndex] != nil {
-		return nil // already added
+		duplicateSignatures.Inc()
+		return true, nil // already added
 	}
 	p.index = voterIndex
+	p.arrival = m.received
+	m.received++
 
 	m.payload[voterIndex] = p
 	m.weight += m.signingPolicy.voters.VoterWeight(voterIndex)
+	m.threshold = max(m.threshold, threshold)
 	if !m.thresholdReached {
-		m.thresholdReached = m.weight > threshold
+		m.thresholdReached = m.weight > m.threshold
 	}
-	return nil
+	return false, nil
 }
 
-func newSubmissionStorage() *submissionStorage {
+func newSubmissionStorage(maxRounds int) *submissionStorage {
 	return &submissionStorage{
-		vrMap: make(map[uint32]*votingRoundItem),
+		vrMap:     make(map[uint32]*votingRoundItem),
+		maxRounds: maxRounds,
 	}
 }
 
 // Add adds a signed payload to the submission storage
-// The provided signing policy must be the signing policy for the voting round
+// The provided signing policy must be the signing policy for the voting round and the
+// threshold the threshold of the protocol for the policy, see protocolSettings.threshold
+// The timestamp is the block timestamp of the submission tx
 // Returns true if the payload was added, false if it was already added
-func (s *submissionStorage) Add(p *signedPayload, sp *signingPolicy, threshold uint16) (addPayloadResult, error) {
+// The signature is verified to recover to a voter of the signing policy, only verified
+// signatures are counted toward the threshold
+func (s *submissionStorage) Add(p *signedPayload, sp *signingPolicy, threshold uint16, timestamp int64) (addPayloadResult, error) {
+	// outside of the lock, recovery is the most expensive step
+	if err := verifySignature(p); err != nil {
+		return addPayloadResult{}, err
+	}
+
 	s.Lock()
 	defer s.Unlock()
 
 	vrItem, ok := s.vrMap[p.message.votingRoundId]
 	if !ok {
 		vrItem = &votingRoundItem{
-			msgMap: make(map[votingRoundKey]*messageData),
+			msgMap:         make(map[votingRoundKey]*messageData),
+			firstTimestamp: timestamp,
 		}
 		s.vrMap[p.message.votingRoundId] = vrItem
+		if evicted, ok := s.evictOldest(); ok && evicted == p.message.votingRoundId {
+			return addPayloadResult{}, fmt.Errorf("voting round %d is older than all retained voting rounds", evicted)
+		}
+		submissionStorageRounds.Set(float64(len(s.vrMap)))
 	}
+	vrItem.firstTimestamp = min(vrItem.firstTimestamp, timestamp)
+	vrItem.lastTimestamp = max(vrItem.lastTimestamp, timestamp)
 
 	key := votingRoundKey{
 		protocolId:  p.message.protocolId,
@@ -99,13 +186,14 @@ func (s *submissionStorage) Add(p *signedPayload, sp *signingPolicy, threshold u
 
 	// Add the payload to the message
 	thresholdAlreadyReached := message.thresholdReached
-	err := message.addPayload(p, threshold)
+	duplicate, err := message.addPayload(p, threshold, s.voterRegistry)
 	if err != nil {
 		return addPayloadResult{}, err
 	}
 	return addPayloadResult{
 		message:          message,
 		thresholdReached: !thresholdAlreadyReached && message.thresholdReached,
+		duplicate:        duplicate,
 	}, nil
 }
 
@@ -129,13 +217,71 @@ func (s *submissionStorage) Get(
 	return nil
 }
 
-func (s *submissionStorage) RemoveVotingRoundIds(votingRoundIds []uint32) {
+// Returns the hashes of messages of the voting round and protocol that reached the threshold
+func (s *submissionStorage) ThresholdReached(votingRoundId uint32, protocolId byte) []common.Hash {
+	s.Lock()
+	defer s.Unlock()
+
+	var hashes []common.Hash
+	if vrItem, ok := s.vrMap[votingRoundId]; ok {
+		for key, message := range vrItem.msgMap {
+			if key.protocolId == protocolId && message.thresholdReached {
+				hashes = append(hashes, key.messageHash)
+			}
+		}
+	}
+	return hashes
+}
+
+// evictOldest removes the oldest voting round if the storage holds more than maxRounds
+// voting rounds. Returns the id of the evicted voting round and true if a round was evicted.
+func (s *submissionStorage) evictOldest() (uint32, bool) {
+	if s.maxRounds <= 0 || len(s.vrMap) <= s.maxRounds {
+		return 0, false
+	}
+	first := true
+	var oldest uint32
+	for votingRoundId := range s.vrMap {
+		if first || votingRoundId < oldest {
+			oldest = votingRoundId
+			first = false
+		}
+	}
+	delete(s.vrMap, oldest)
+	evictedRounds.Inc()
+	return oldest, true
+}
+
+// RemoveUpTo removes all voting rounds with id <= votingRoundId
+func (s *submissionStorage) RemoveUpTo(votingRoundId uint32) {
+	s.Lock()
+	defer s.Unlock()
+
+	for id := range s.vrMap {
+		if id <= votingRoundId {
+			delete(s.vrMap, id)
+		}
+	}
+	submissionStorageRounds.Set(float64(len(s.vrMap)))
+}
+
+// RemoveFromTimestamp removes all voting rounds with a submission with block timestamp
+// >= timestamp. Returns the timestamp of the first submission of the removed rounds,
+// false if no round was removed.
+func (s *submissionStorage) RemoveFromTimestamp(timestamp int64) (int64, bool) {
 	s.Lock()
 	defer s.Unlock()
 
-	for _, votingRoundId := range votingRoundIds {
-		delete(s.vrMap, votingRoundId)
+	first, removed := timestamp, false
+	for id, vrItem := range s.vrMap {
+		if vrItem.lastTimestamp >= timestamp {
+			first = min(first, vrItem.firstTimestamp)
+			removed = true
+			delete(s.vrMap, id)
+		}
 	}
+	submissionStorageRounds.Set(float64(len(s.vrMap)))
+	return first, removed
 }
 
 func (d *messageData) Copy() *messageData {
@@ -145,5 +291,7 @@ func (d *messageData) Copy() *messageData {
 		payload:       payload,
 		weight:        d.weight,
 		signingPolicy: d.signingPolicy,
+		threshold:     d.threshold,
+		received:      d.received,
 	}
 }
@@ -0,0 +1,117 @@
+package finalizer
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"flare-tlc/client/shared/voters"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Payload of the protocol 100 message of the voting round signed by the key, as decoded
+// from a submitSignatures tx
+func newTestSignedPayload(t *testing.T, privateKey *ecdsa.PrivateKey, votingRoundId uint32) *signedPayload {
+	data, err := encodeSignedPayload(privateKey, &signedPayload{
+		typeId: payloadTypeMessage,
+		message: &submittedPayload{
+			protocolId:    100,
+			votingRoundId: votingRoundId,
+			merkleRoot:    bytes.Repeat([]byte{0x02}, 32),
+		},
+	})
+	require.NoError(t, err)
+	payload, err := decodeSignedPayload(data)
+	require.NoError(t, err)
+	return payload
+}
+
+func TestSubmissionStorageMaxRounds(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	sp := &signingPolicy{voters: voters.NewVoterSet([]common.Address{signer}, []uint16{100})}
+	payload := func(votingRoundId uint32) *signedPayload {
+		return newTestSignedPayload(t, privateKey, votingRoundId)
+	}
+	hash := func(votingRoundId uint32) common.Hash {
+		return payload(votingRoundId).messageHash
+	}
+
+	s := newSubmissionStorage(2)
+	for _, votingRoundId := range []uint32{10, 11, 12} {
+		result, err := s.Add(payload(votingRoundId), sp, 50, int64(votingRoundId))
+		require.NoError(t, err)
+		require.True(t, result.thresholdReached)
+	}
+	require.Nil(t, s.Get(10, 100, hash(10)))
+	require.NotNil(t, s.Get(11, 100, hash(11)))
+	require.NotNil(t, s.Get(12, 100, hash(12)))
+
+	// older than all retained rounds
+	_, err = s.Add(payload(9), sp, 50, 9)
+	require.Error(t, err)
+	require.Len(t, s.vrMap, 2)
+
+	// duplicate signature of the same voter
+	result, err := s.Add(payload(12), sp, 50, 12)
+	require.NoError(t, err)
+	require.False(t, result.thresholdReached)
+	require.True(t, result.duplicate)
+	require.Equal(t, uint16(100), result.message.weight)
+
+	// signer not in the signing policy
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	_, err = s.Add(newTestSignedPayload(t, otherKey, 12), sp, 50, 12)
+	require.ErrorAs(t, err, &notInPolicyError{})
+
+	s.RemoveUpTo(11)
+	require.Nil(t, s.Get(11, 100, hash(11)))
+	require.NotNil(t, s.Get(12, 100, hash(12)))
+}
+
+func TestSubmissionStorageVerifiesSignature(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	voter := crypto.PubkeyToAddress(privateKey.PublicKey)
+	other := crypto.PubkeyToAddress(otherKey.PublicKey)
+	sp := &signingPolicy{voters: voters.NewVoterSet([]common.Address{other, voter}, []uint16{100, 100})}
+
+	tests := []struct {
+		name   string
+		modify func(p *signedPayload)
+	}{
+		{"signer of another voter", func(p *signedPayload) { p.signer = other }},
+		{"missing signature", func(p *signedPayload) { p.signature = nil }},
+		{"message hash", func(p *signedPayload) { p.messageHash = common.HexToHash("0x02") }},
+		{"raw message", func(p *signedPayload) { p.rawMessage = append([]byte{101}, p.rawMessage[1:]...) }},
+		{"signature", func(p *signedPayload) { p.signature = append([]byte{p.signature[0] ^ 1}, p.signature[1:]...) }},
+	}
+	s := newSubmissionStorage(0)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := newTestSignedPayload(t, privateKey, 10)
+			test.modify(p)
+			_, err := s.Add(p, sp, 50, 10)
+			require.ErrorAs(t, err, &spoofedSignatureError{})
+		})
+	}
+	require.Empty(t, s.vrMap, "spoofed signatures are not stored")
+
+	// index of another signing policy
+	p := newTestSignedPayload(t, privateKey, 10)
+	p.index = 0
+	_, err = s.Add(p, sp, 50, 10)
+	require.ErrorAs(t, err, &notInPolicyError{})
+
+	p.index = -1
+	result, err := s.Add(p, sp, 150, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.index)
+	require.False(t, result.thresholdReached)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/hex"
 	"flare-tlc/client/shared"
-	"flare-tlc/logger"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/submission"
 	"time"
 
@@ -13,11 +15,47 @@ import (
 
 type submissionContractClient struct {
 	address common.Address
+	clock   utils.Clock
+
+	// selector of submitSignatures, resolved by NewSubmissionContractClient
+	selector []byte
+
+	// If set, the blocks of the submitSignatures txs are checked for reorgs
+	reorgTracker *shared.ReorgTracker
+
+	// polling intervals of the listeners, the defaults if not set
+	listenerIntervals shared.ListenerIntervals
+
+	// start timestamps of requested re-scans of the listener
+	rescan chan int64
+}
+
+// Position of a tx in the chain, the listener processes the txs after the cursor.
+// The indexer writes the blocks in order, so a tx indexed later has a larger position.
+type txCursor struct {
+	blockNumber      uint64
+	transactionIndex uint64
+}
+
+func txPosition(tx *database.Transaction) txCursor {
+	return txCursor{blockNumber: tx.BlockNumber, transactionIndex: tx.TransactionIndex}
+}
+
+func (c txCursor) before(other txCursor) bool {
+	if c.blockNumber != other.blockNumber {
+		return c.blockNumber < other.blockNumber
+	}
+	return c.transactionIndex < other.transactionIndex
 }
 
 type submissionListenerResponse struct {
 	payload   []*submitterPayloadItem
 	timestamp int64
+
+	// sender of the submitSignatures tx
+	submitter common.Address
+	// true if the tx input could not be decoded, payload is empty
+	invalidPayload bool
 }
 
 type submitterItemProcessor interface {
@@ -26,67 +64,129 @@ type submitterItemProcessor interface {
 	ProcessSubmissionData(submissionListenerResponse) error
 }
 
-func NewSubmissionContractClient(address common.Address) *submissionContractClient {
+func NewSubmissionContractClient(address common.Address) (*submissionContractClient, error) {
+	selector, err := chain.MethodIDFromMetadata(submission.SubmissionMetaData, "submitSignatures")
+	if err != nil {
+		return nil, err
+	}
 	return &submissionContractClient{
-		address: address,
+		address:  address,
+		clock:    utils.RealClock,
+		selector: selector,
+		rescan:   make(chan int64, 1),
+	}, nil
+}
+
+// Rescan makes the listener process the submitSignatures txs again, from the
+// timestamp on. The processor handles the duplicates.
+func (s *submissionContractClient) Rescan(from time.Time) {
+	// replaces a pending request
+	select {
+	case <-s.rescan:
+	default:
+	}
+	select {
+	case s.rescan <- from.Unix():
+	default:
 	}
 }
 
-func (s *submissionContractClient) SubmissionTxListener(
-	ctx context.Context,
-	db finalizerDB,
-	startTime time.Time,
+// Process the submitSignatures txs in the timestamp range (from, to] once
+func (s *submissionContractClient) FetchSubmissions(
+	db FinalizerDB,
+	from, to time.Time,
 	processor submitterItemProcessor,
 ) error {
-	submissionABI, err := submission.SubmissionMetaData.GetAbi()
+	txs, err := database.FetchAll(from.Unix(), to.Unix(), func(from, to int64) ([]database.Transaction, error) {
+		return db.FetchTransactionsByAddressAndSelector(s.address, s.selector, from, to)
+	})
 	if err != nil {
-		// Should not happen, unhandled errors will cause a panic further up.
 		return err
 	}
+	for _, tx := range txs {
+		if err := processSubmissionTx(tx, processor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalid txs are skipped, returns an error only if the processor fails
+func processSubmissionTx(tx database.Transaction, processor submitterItemProcessor) error {
+	inputBytes, err := hex.DecodeString(tx.Input)
+	if err != nil {
+		// the input of the indexer is not hex, not counted as an invalid payload of the submitter
+		logger.Info("Invalid submitSignatures tx sent by %s: %v, skipping", tx.FromAddress, err)
+		return nil
+	}
+	payload, err := DecodeSubmitterPayload(inputBytes)
+	if err != nil {
+		// if input cannot be decoded, it is not a valid submission and should be skipped
+		logger.Info("Invalid submitSignatures payload sent by %s: %v, skipping", tx.FromAddress, err)
+		return processor.ProcessSubmissionData(submissionListenerResponse{
+			timestamp:      int64(tx.Timestamp),
+			submitter:      common.HexToAddress(tx.FromAddress),
+			invalidPayload: true,
+		})
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	return processor.ProcessSubmissionData(submissionListenerResponse{
+		payload:   payload,
+		timestamp: int64(tx.Timestamp),
+		submitter: common.HexToAddress(tx.FromAddress),
+	})
+}
 
-	selector := submissionABI.Methods["submitSignatures"].ID
-	ticker := time.NewTicker(shared.ListenerInterval)
+func (s *submissionContractClient) SubmissionTxListener(
+	ctx context.Context,
+	db FinalizerDB,
+	startTime time.Time,
+	processor submitterItemProcessor,
+) error {
+	ticker := shared.NewListenerTicker(s.clock, listenerSubmitSignatures, s.listenerIntervals.Interval(listenerSubmitSignatures))
+	defer ticker.Stop()
 	eventRangeStart := startTime.Unix()
+	// txs of the overlapping range that were already processed are skipped
+	var cursor *txCursor
 	for {
-		select {
-		case <-ticker.C:
-			break
-
-		case <-ctx.Done():
+		if !ticker.Wait(ctx) {
 			logger.Info("Submission tx listener stopped")
 			return ctx.Err()
 		}
-		now := time.Now().Unix()
-		txs, err := db.FetchTransactionsByAddressAndSelector(s.address, selector, eventRangeStart, now)
+		select {
+		case from := <-s.rescan:
+			logger.Info("Re-scanning submitSignatures txs from %s", time.Unix(from, 0))
+			eventRangeStart, cursor = from, nil
+		default:
+		}
+		if rewind, ok := s.reorgTracker.PendingRewind(listenerSubmitSignatures); ok && rewind < eventRangeStart {
+			eventRangeStart, cursor = rewind, nil
+		}
+		now := s.clock.Now().Unix()
+		txs, err := db.FetchTransactionsByAddressAndSelector(s.address, s.selector, eventRangeStart, now)
 		if err != nil {
 			logger.Error("Error fetching transactions %v", err)
 			continue
 		}
+		shared.RecordListenerRowsScanned(listenerSubmitSignatures, len(txs))
 		for _, tx := range txs {
-			inputBytes, err := hex.DecodeString(tx.Input)
-			if err != nil {
-				logger.Info("Invalid submitSignatures tx sent by %s: %v, skipping", tx.FromAddress, err)
-			}
-			payload, err := DecodeSubmitterPayload(inputBytes)
-			if err != nil {
-				// if input cannot be decoded, it is not a valid submission and should be skipped
-				logger.Info("Invalid submitSignatures payload sent by %s: %v, skipping", tx.FromAddress, err)
+			position := txPosition(&tx)
+			if cursor != nil && !cursor.before(position) {
+				continue
 			}
-			if len(payload) > 0 {
-				err = processor.ProcessSubmissionData(submissionListenerResponse{
-					payload:   payload,
-					timestamp: int64(tx.Timestamp),
-				})
-				if err != nil {
-					// retry the full range, error occurs when the corresponding signing policy
-					// is not yet available
-					logger.Warn("Error processing submitSignatures payload sent by %s: %v, retrying", tx.FromAddress, err)
-					break
-				}
+			if err := processSubmissionTx(tx, processor); err != nil {
+				// retry from the tx, error occurs when the corresponding signing policy
+				// is not yet available
+				logger.Warn("Error processing submitSignatures payload sent by %s: %v, retrying", tx.FromAddress, err)
+				break
 			}
-			// -1 for overlap in case of an error and retry above
-			// processor should be able to handle duplicates
+			// -1 for overlap, txs with the same timestamp may be indexed later
 			eventRangeStart = int64(tx.Timestamp) - 1
+			cursor = &position
+			shared.RecordListenerEvent(listenerSubmitSignatures, int64(tx.Timestamp))
+			s.reorgTracker.Track(tx.BlockNumber, tx.BlockHash, int64(tx.Timestamp))
 		}
 	}
 }
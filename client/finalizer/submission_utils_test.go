@@ -0,0 +1,109 @@
+package finalizer
+
+import (
+	"context"
+	"encoding/hex"
+	"flare-tlc/client/shared"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Indexer transactions filtered by timestamp
+type testTxsDB struct {
+	testDB
+	mu  sync.Mutex
+	txs []database.Transaction
+}
+
+func (db *testTxsDB) FetchTransactionsByAddressAndSelector(
+	address common.Address, selector []byte, from, to int64,
+) ([]database.Transaction, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var result []database.Transaction
+	for _, tx := range db.txs {
+		if int64(tx.Timestamp) > from && int64(tx.Timestamp) <= to {
+			result = append(result, tx)
+		}
+	}
+	return result, nil
+}
+
+func (db *testTxsDB) add(tx database.Transaction) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.txs = append(db.txs, tx)
+}
+
+type testSubmissionProcessor struct {
+	mu         sync.Mutex
+	submitters []common.Address
+}
+
+func (p *testSubmissionProcessor) ProcessSubmissionData(data submissionListenerResponse) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.submitters = append(p.submitters, data.submitter)
+	return nil
+}
+
+func (p *testSubmissionProcessor) processed() []common.Address {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]common.Address(nil), p.submitters...)
+}
+
+func TestSubmissionTxListenerCursor(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	payload, err := encodeSubmitterPayload(privateKey)
+	require.NoError(t, err)
+	submissionTx := func(blockNumber, transactionIndex uint64, from string) database.Transaction {
+		return database.Transaction{
+			Input:            hex.EncodeToString(payload),
+			BlockNumber:      blockNumber,
+			TransactionIndex: transactionIndex,
+			FromAddress:      from,
+			Timestamp:        10,
+		}
+	}
+	a, b, c := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")
+
+	db := &testTxsDB{txs: []database.Transaction{submissionTx(1, 0, "01"), submissionTx(1, 1, "02")}}
+	clock := utils.NewFakeClock(time.Unix(20, 0))
+	client, err := NewSubmissionContractClient(submissionContractAddress)
+	require.NoError(t, err)
+	client.clock = clock
+	processor := &testSubmissionProcessor{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = client.SubmissionTxListener(ctx, db, time.Unix(0, 0), processor)
+	}()
+	tick := func() {
+		clock.BlockUntilWaiters(1)
+		clock.Advance(shared.ListenerInterval)
+	}
+
+	tick()
+	require.Eventually(t, func() bool { return len(processor.processed()) == 2 }, time.Second, time.Millisecond)
+
+	// a tx with the same timestamp indexed later, the range overlaps the processed txs
+	db.add(submissionTx(2, 0, "03"))
+	tick()
+	require.Eventually(t, func() bool { return len(processor.processed()) == 3 }, time.Second, time.Millisecond)
+
+	client.Rescan(time.Unix(0, 0))
+	tick()
+	require.Eventually(t, func() bool { return len(processor.processed()) == 6 }, time.Second, time.Millisecond)
+	require.Equal(t, []common.Address{a, b, c, a, b, c}, processor.processed())
+}
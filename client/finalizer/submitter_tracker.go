@@ -0,0 +1,210 @@
+package finalizer
+
+import (
+	"flare-tlc/client/admin"
+	"flare-tlc/client/config"
+	"flare-tlc/utils"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Reasons of invalid submitted signatures
+const (
+	invalidSignaturePayload     = "invalid_payload" // payload can not be decoded, e.g. invalid signature
+	invalidSignatureNotInPolicy = "not_in_policy"   // signer is not a voter of the signing policy
+	invalidSignatureDuplicate   = "duplicate"       // message already signed by the same voter, not counted toward bans
+	invalidSignatureSpoofed     = "spoofed"         // signature does not recover to the signer of the payload
+
+	// submitter label of the metrics of the submitters that are not known
+	unknownSubmitter = "unknown"
+)
+
+type submitterStats struct {
+	invalid map[string]int
+
+	// invalid signatures since the last ban
+	sinceBan    int
+	bannedUntil time.Time
+}
+
+// Counts the invalid signatures of the senders of submitSignatures txs. Submitters
+// reaching maxInvalid invalid signatures are banned for banPeriod, their submissions
+// are not processed. maxInvalid 0 disables banning. Duplicates are only counted, a tx
+// processed again after a listener retry, rescan or reorg has duplicate signatures.
+//
+// A nil tracker does not track anything.
+type submitterTracker struct {
+	maxInvalid int
+	banPeriod  time.Duration
+	clock      utils.Clock
+
+	mu         sync.Mutex
+	submitters map[common.Address]*submitterStats
+
+	// reward epoch of the last accepted signature of the known submitters, see label
+	known      map[common.Address]int64
+	knownEpoch int64
+}
+
+func newSubmitterTracker(cfg *config.SubmitterBanConfig) *submitterTracker {
+	return &submitterTracker{
+		maxInvalid: cfg.MaxInvalidSignatures,
+		banPeriod:  cfg.Period,
+		clock:      utils.RealClock,
+		submitters: make(map[common.Address]*submitterStats),
+		known:      make(map[common.Address]int64),
+	}
+}
+
+// Accepted records a signature of the submitter accepted for the signing policy. The
+// submitters of accepted signatures of the current and the previous reward epoch are
+// known, at most as many per reward epoch as the policy has voters, so that the
+// submitter label of the metrics is bounded by the policy size.
+func (t *submitterTracker) Accepted(submitter common.Address, policy *signingPolicy) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	epoch := policy.rewardEpochId
+	if epoch > t.knownEpoch {
+		t.knownEpoch = epoch
+		for address, lastEpoch := range t.known {
+			if lastEpoch < epoch-1 {
+				delete(t.known, address)
+				invalidSignatures.DeletePartialMatch(prometheus.Labels{"submitter": address.Hex()})
+				submitterBans.DeletePartialMatch(prometheus.Labels{"submitter": address.Hex()})
+			}
+		}
+	}
+	if lastEpoch, ok := t.known[submitter]; ok {
+		t.known[submitter] = max(lastEpoch, epoch)
+		return
+	}
+	count := 0
+	for _, lastEpoch := range t.known {
+		if lastEpoch == epoch {
+			count++
+		}
+	}
+	if count < policy.voters.Count() {
+		t.known[submitter] = epoch
+	}
+}
+
+// Submitter label of the metrics, the address of known submitters
+func (t *submitterTracker) label(submitter common.Address) string {
+	if _, ok := t.known[submitter]; ok {
+		return submitter.Hex()
+	}
+	return unknownSubmitter
+}
+
+// Report records an invalid signature of the submitter
+func (t *submitterTracker) Report(submitter common.Address, reason string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	invalidSignatures.WithLabelValues(t.label(submitter), reason).Inc()
+	stats, ok := t.submitters[submitter]
+	if !ok {
+		stats = &submitterStats{invalid: make(map[string]int)}
+		t.submitters[submitter] = stats
+	}
+	stats.invalid[reason]++
+	if reason == invalidSignatureDuplicate {
+		return
+	}
+	stats.sinceBan++
+	if t.maxInvalid > 0 && stats.sinceBan >= t.maxInvalid {
+		stats.sinceBan = 0
+		stats.bannedUntil = t.clock.Now().Add(t.banPeriod)
+		submitterBans.WithLabelValues(t.label(submitter)).Inc()
+		logger.Warn("Submitter %s banned until %v after %d invalid signatures", submitter.Hex(), stats.bannedUntil, t.maxInvalid)
+	}
+}
+
+// Banned returns true if the submissions of the submitter are not processed
+func (t *submitterTracker) Banned(submitter common.Address) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats, ok := t.submitters[submitter]
+	return ok && t.clock.Now().Before(stats.bannedUntil)
+}
+
+// Submitters with invalid signatures, sorted by address
+func (t *submitterTracker) Submitters() []admin.SubmitterInfo {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.clock.Now()
+	result := make([]admin.SubmitterInfo, 0, len(t.submitters))
+	for submitter, stats := range t.submitters {
+		info := admin.SubmitterInfo{
+			Address:           submitter.Hex(),
+			InvalidSignatures: make(map[string]int, len(stats.invalid)),
+		}
+		for reason, count := range stats.invalid {
+			info.InvalidSignatures[reason] = count
+		}
+		if now.Before(stats.bannedUntil) {
+			bannedUntil := stats.bannedUntil
+			info.BannedUntil = &bannedUntil
+		}
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Address < result[j].Address
+	})
+	return result
+}
+
+// Tracked or ignored submitters, a nil filter processes all submissions
+type submitterFilter struct {
+	tracked map[common.Address]bool
+	ignored map[common.Address]bool
+}
+
+func newSubmitterFilter(cfg *config.SubmitterFilterConfig) *submitterFilter {
+	if len(cfg.Tracked) == 0 && len(cfg.Ignored) == 0 {
+		return nil
+	}
+	f := &submitterFilter{}
+	if len(cfg.Tracked) > 0 {
+		f.tracked = make(map[common.Address]bool, len(cfg.Tracked))
+		for _, address := range cfg.Tracked {
+			f.tracked[address] = true
+		}
+	}
+	f.ignored = make(map[common.Address]bool, len(cfg.Ignored))
+	for _, address := range cfg.Ignored {
+		f.ignored[address] = true
+	}
+	return f
+}
+
+// Allowed returns true if the submissions of the submitter are processed
+func (f *submitterFilter) Allowed(submitter common.Address) bool {
+	if f == nil {
+		return true
+	}
+	if f.tracked != nil && !f.tracked[submitter] {
+		return false
+	}
+	return !f.ignored[submitter]
+}
@@ -0,0 +1,88 @@
+package finalizer
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared/voters"
+	"flare-tlc/utils"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitterTracker(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(1000, 0))
+	tracker := newSubmitterTracker(&config.SubmitterBanConfig{MaxInvalidSignatures: 3, Period: time.Minute})
+	tracker.clock = clock
+
+	offender := common.HexToAddress("0x02")
+	other := common.HexToAddress("0x01")
+	for i := 0; i < 5; i++ {
+		// duplicates are not counted toward bans
+		tracker.Report(other, invalidSignatureDuplicate)
+	}
+	tracker.Report(offender, invalidSignatureNotInPolicy)
+	tracker.Report(offender, invalidSignatureNotInPolicy)
+	require.False(t, tracker.Banned(offender))
+	tracker.Report(offender, invalidSignaturePayload)
+	require.True(t, tracker.Banned(offender))
+	require.False(t, tracker.Banned(other))
+
+	submitters := tracker.Submitters()
+	require.Len(t, submitters, 2)
+	require.Equal(t, other.Hex(), submitters[0].Address)
+	require.Nil(t, submitters[0].BannedUntil)
+	require.Equal(t, map[string]int{invalidSignatureNotInPolicy: 2, invalidSignaturePayload: 1}, submitters[1].InvalidSignatures)
+	require.Equal(t, time.Unix(1060, 0), *submitters[1].BannedUntil)
+
+	clock.Advance(time.Minute)
+	require.False(t, tracker.Banned(offender))
+
+	// banning disabled
+	tracker = newSubmitterTracker(&config.SubmitterBanConfig{})
+	for i := 0; i < 10; i++ {
+		tracker.Report(offender, invalidSignatureDuplicate)
+	}
+	require.False(t, tracker.Banned(offender))
+	require.Equal(t, 10, tracker.Submitters()[0].InvalidSignatures[invalidSignatureDuplicate])
+}
+
+func TestSubmitterTrackerLabels(t *testing.T) {
+	tracker := newSubmitterTracker(&config.SubmitterBanConfig{MaxInvalidSignatures: 1, Period: time.Minute})
+	known := common.HexToAddress("0x0a")
+	other := common.HexToAddress("0x0b")
+	policy := &signingPolicy{rewardEpochId: 5, voters: voters.NewVoterSet([]common.Address{common.HexToAddress("0x01")}, []uint16{100})}
+
+	// at most one known submitter per voter of the policy
+	tracker.Accepted(known, policy)
+	tracker.Accepted(other, policy)
+	tracker.Report(known, invalidSignatureSpoofed)
+	tracker.Report(other, invalidSignatureSpoofed)
+	require.Equal(t, 1.0, testutil.ToFloat64(invalidSignatures.WithLabelValues(known.Hex(), invalidSignatureSpoofed)))
+	require.Equal(t, 1.0, testutil.ToFloat64(submitterBans.WithLabelValues(known.Hex())))
+	require.GreaterOrEqual(t, testutil.ToFloat64(invalidSignatures.WithLabelValues(unknownSubmitter, invalidSignatureSpoofed)), 1.0)
+
+	// dropped two reward epochs later
+	tracker.Accepted(other, &signingPolicy{rewardEpochId: 7, voters: policy.voters})
+	require.Equal(t, unknownSubmitter, tracker.label(known))
+	require.Equal(t, other.Hex(), tracker.label(other))
+	require.Equal(t, 0.0, testutil.ToFloat64(submitterBans.WithLabelValues(known.Hex())))
+}
+
+func TestSubmitterFilter(t *testing.T) {
+	a, b := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	var filter *submitterFilter = newSubmitterFilter(&config.SubmitterFilterConfig{})
+	require.Nil(t, filter)
+	require.True(t, filter.Allowed(a))
+
+	filter = newSubmitterFilter(&config.SubmitterFilterConfig{Tracked: []common.Address{a}})
+	require.True(t, filter.Allowed(a))
+	require.False(t, filter.Allowed(b))
+
+	filter = newSubmitterFilter(&config.SubmitterFilterConfig{Ignored: []common.Address{a}})
+	require.False(t, filter.Allowed(a))
+	require.True(t, filter.Allowed(b))
+}
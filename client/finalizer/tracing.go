@@ -0,0 +1,194 @@
+package finalizer
+
+import (
+	"context"
+	"flare-tlc/utils"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "flare-tlc/finalizer"
+
+// Outcomes of a traced voting round, set on the voting_round span
+const (
+	traceOutcomeRelayed        = "relayed"          // relay tx of the finalizer mined
+	traceOutcomeRelayedOnChain = "relayed_on_chain" // relayed by another finalizer
+	traceOutcomeNotSelected    = "not_selected"     // not selected and only_when_selected is set
+	traceOutcomeBudgetExceeded = "budget_exceeded"  // backup finalization skipped, daily budget exceeded
+	traceOutcomeExpired        = "expired"          // removed from the storage before it was relayed
+	traceOutcomeStandby        = "standby"          // not sent, the leader instance relays the message
+)
+
+// Traces the finalization of each voting round of a protocol. The voting_round span
+// starts at the block timestamp of the first submitted signature and ends when the
+// round is relayed or removed from the storage. Its child spans are:
+//   - submission: from the block timestamp of a submitSignatures tx until it is processed
+//   - signature.verify: the voter check of the signer of the signature
+//   - queue: from the threshold reached until the item is relayed, including the
+//     grace period of backup finalizations
+//   - relay.tx: the relay tx, from sending until it is mined, with retries
+//
+// Span timestamps are taken from the clock of the finalizer.
+type roundTracer struct {
+	tracer trace.Tracer
+	clock  utils.Clock
+
+	mu     sync.Mutex
+	rounds map[relayedRoundKey]*roundTrace
+}
+
+type roundTrace struct {
+	ctx   context.Context
+	span  trace.Span
+	queue trace.Span // nil if the threshold is not reached
+
+	// finished rounds are kept until removed, so that late signatures do not start a new trace
+	done bool
+}
+
+func newRoundTracer(clock utils.Clock) *roundTracer {
+	return &roundTracer{
+		tracer: otel.Tracer(tracerName),
+		clock:  clock,
+		rounds: make(map[relayedRoundKey]*roundTrace),
+	}
+}
+
+// Returns the trace of the round, started at start if not traced yet, nil if the round is finished
+func (t *roundTracer) round(key relayedRoundKey, start time.Time) *roundTrace {
+	rt, ok := t.rounds[key]
+	if !ok {
+		ctx, span := t.tracer.Start(context.Background(), "voting_round",
+			trace.WithNewRoot(),
+			trace.WithTimestamp(start),
+			trace.WithAttributes(
+				attribute.Int("protocol_id", int(key.protocolId)),
+				attribute.Int64("voting_round_id", int64(key.votingRoundId)),
+			),
+		)
+		rt = &roundTrace{ctx: ctx, span: span}
+		t.rounds[key] = rt
+	}
+	if rt.done {
+		return nil
+	}
+	return rt
+}
+
+// Records the ingestion of a signature submitted in a tx with the block timestamp and
+// starts its verification, the returned function ends the verification
+func (t *roundTracer) signature(key relayedRoundKey, submitter common.Address, timestamp int64) func(error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	submitted := time.Unix(timestamp, 0)
+	rt := t.round(key, submitted)
+	if rt == nil {
+		return func(error) {}
+	}
+	now := t.clock.Now()
+	submitterAttribute := attribute.String("submitter", submitter.Hex())
+	_, ingestion := t.tracer.Start(rt.ctx, "submission",
+		trace.WithTimestamp(submitted), trace.WithAttributes(submitterAttribute))
+	ingestion.End(trace.WithTimestamp(now))
+
+	_, verification := t.tracer.Start(rt.ctx, "signature.verify",
+		trace.WithTimestamp(now), trace.WithAttributes(submitterAttribute))
+	return func(err error) {
+		if err != nil {
+			verification.SetStatus(codes.Error, err.Error())
+		}
+		verification.End(trace.WithTimestamp(t.clock.Now()))
+	}
+}
+
+// Starts the queue span of the round
+func (t *roundTracer) thresholdReached(key relayedRoundKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rt := t.round(key, t.clock.Now())
+	if rt == nil || rt.queue != nil {
+		return
+	}
+	now := t.clock.Now()
+	rt.span.AddEvent("threshold reached", trace.WithTimestamp(now))
+	_, rt.queue = t.tracer.Start(rt.ctx, "queue", trace.WithTimestamp(now))
+}
+
+// Ends the queue span and starts the relay.tx span of the round, the returned function
+// ends the relay.tx span and, if the tx was mined, the round
+func (t *roundTracer) relay(key relayedRoundKey, backup bool, signatures int) func(mined bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rt, ok := t.rounds[key]
+	if !ok || rt.done {
+		return func(bool) {}
+	}
+	now := t.clock.Now()
+	if rt.queue != nil {
+		rt.queue.End(trace.WithTimestamp(now))
+		rt.queue = nil
+	}
+	_, span := t.tracer.Start(rt.ctx, "relay.tx", trace.WithTimestamp(now), trace.WithAttributes(
+		attribute.Bool("backup", backup),
+		attribute.Int("signatures", signatures),
+	))
+	return func(mined bool) {
+		if !mined {
+			span.SetStatus(codes.Error, "relay tx not mined")
+		}
+		span.End(trace.WithTimestamp(t.clock.Now()))
+		if mined {
+			t.finish(key, traceOutcomeRelayed)
+		}
+	}
+}
+
+// Ends the round with the outcome
+func (t *roundTracer) finish(key relayedRoundKey, outcome string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if rt, ok := t.rounds[key]; ok {
+		t.end(rt, outcome)
+	}
+}
+
+func (t *roundTracer) end(rt *roundTrace, outcome string) {
+	if rt.done {
+		return
+	}
+	now := t.clock.Now()
+	if rt.queue != nil {
+		rt.queue.End(trace.WithTimestamp(now))
+		rt.queue = nil
+	}
+	rt.span.SetAttributes(attribute.String("outcome", outcome))
+	if outcome == traceOutcomeExpired {
+		rt.span.SetStatus(codes.Error, "voting round not relayed")
+	}
+	rt.span.End(trace.WithTimestamp(now))
+	rt.done = true
+}
+
+// Ends the rounds with voting round id <= votingRoundId that are not finished and
+// removes them
+func (t *roundTracer) RemoveUpTo(votingRoundId uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, rt := range t.rounds {
+		if key.votingRoundId <= votingRoundId {
+			t.end(rt, traceOutcomeExpired)
+			delete(t.rounds, key)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package finalizer
+
+import (
+	"errors"
+	"flare-tlc/utils"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestRoundTracer(clock utils.Clock) (*roundTracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t := newRoundTracer(clock)
+	t.tracer = provider.Tracer(tracerName)
+	return t, recorder
+}
+
+func endedSpans(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	return spans
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestRoundTracerRelayed(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := utils.NewFakeClock(start)
+	tracer, recorder := newTestRoundTracer(clock)
+	key := relayedRoundKey{votingRoundId: 10, protocolId: 100}
+
+	clock.Advance(2 * time.Second)
+	verified := tracer.signature(key, common.HexToAddress("0x1"), start.Unix())
+	clock.Advance(time.Second)
+	verified(nil)
+	tracer.thresholdReached(key)
+	clock.Advance(5 * time.Second)
+	relayed := tracer.relay(key, true, 3)
+	clock.Advance(4 * time.Second)
+	relayed(true)
+
+	spans := endedSpans(recorder)
+	require.Len(t, spans, 5)
+
+	round := spans["voting_round"]
+	require.Equal(t, start, round.StartTime())
+	require.Equal(t, start.Add(12*time.Second), round.EndTime())
+	require.Equal(t, int64(10), spanAttribute(round, "voting_round_id").AsInt64())
+	require.Equal(t, traceOutcomeRelayed, spanAttribute(round, "outcome").AsString())
+	require.Len(t, round.Events(), 1)
+
+	submission := spans["submission"]
+	require.Equal(t, start, submission.StartTime())
+	require.Equal(t, start.Add(2*time.Second), submission.EndTime())
+	require.Equal(t, round.SpanContext().SpanID(), submission.Parent().SpanID())
+
+	require.Equal(t, time.Second, spans["signature.verify"].EndTime().Sub(spans["signature.verify"].StartTime()))
+	require.Equal(t, 5*time.Second, spans["queue"].EndTime().Sub(spans["queue"].StartTime()))
+
+	relayTx := spans["relay.tx"]
+	require.Equal(t, 4*time.Second, relayTx.EndTime().Sub(relayTx.StartTime()))
+	require.True(t, spanAttribute(relayTx, "backup").AsBool())
+	require.Equal(t, codes.Unset, relayTx.Status().Code)
+
+	// late signatures of a finished round do not start a new trace
+	tracer.signature(key, common.HexToAddress("0x2"), clock.Now().Unix())(nil)
+	require.Len(t, recorder.Ended(), 5)
+}
+
+func TestRoundTracerExpired(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(1000, 0))
+	tracer, recorder := newTestRoundTracer(clock)
+	first := relayedRoundKey{votingRoundId: 10, protocolId: 100}
+	second := relayedRoundKey{votingRoundId: 11, protocolId: 100}
+
+	tracer.signature(first, common.HexToAddress("0x1"), 1000)(errors.New("not a voter"))
+	tracer.signature(second, common.HexToAddress("0x1"), 1000)(nil)
+	tracer.thresholdReached(first)
+	tracer.finish(second, traceOutcomeRelayedOnChain)
+
+	tracer.RemoveUpTo(10)
+
+	var failed, expired, relayedOnChain int
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "signature.verify":
+			if span.Status().Code == codes.Error {
+				require.Equal(t, "not a voter", span.Status().Description)
+				failed++
+			}
+		case "voting_round":
+			switch spanAttribute(span, "outcome").AsString() {
+			case traceOutcomeExpired:
+				require.Equal(t, codes.Error, span.Status().Code)
+				expired++
+			case traceOutcomeRelayedOnChain:
+				relayedOnChain++
+			}
+		}
+	}
+	require.Equal(t, 1, failed)
+	require.Equal(t, 1, expired)
+	require.Equal(t, 1, relayedOnChain)
+	require.NotContains(t, tracer.rounds, first)
+	require.Contains(t, tracer.rounds, second)
+}
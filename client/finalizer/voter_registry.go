@@ -0,0 +1,205 @@
+package finalizer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// Concurrent eth_calls fetching the weights of the registered voters
+	voterRegistryCallConcurrency = 8
+	// Timeout of each eth_call to the VoterRegistry
+	voterRegistryCallTimeout = 10 * time.Second
+)
+
+// Implemented by registry.RegistryCaller
+type voterRegistryCaller interface {
+	GetRegisteredSigningPolicyAddresses(opts *bind.CallOpts, rewardEpochId *big.Int) ([]common.Address, error)
+	GetVoterWithNormalisedWeight(opts *bind.CallOpts, rewardEpochId *big.Int, signingPolicyAddress common.Address) (struct {
+		Voter            common.Address
+		NormalisedWeight uint16
+	}, error)
+}
+
+type registeredVoter struct {
+	voter  common.Address
+	weight uint16 // normalised weight
+
+	// index of the signing policy address in the signing policy, -1 if it is not included
+	index int
+}
+
+// Registered voters of the reward epochs, by signing policy address. The voters of
+// a reward epoch are fetched from the VoterRegistry when its signing policy is
+// initialized and used to verify the signers of submitted signatures. The voters are
+// fetched by Run, so that the signing policy listener is not blocked by the calls.
+//
+// A nil cache does not verify anything.
+type voterRegistryCache struct {
+	caller voterRegistryCaller
+
+	mu     sync.RWMutex
+	epochs map[int64]map[common.Address]registeredVoter
+
+	// signing policies of the reward epochs to refresh, Run is notified on added ones
+	pending map[int64]*signingPolicy
+	notify  chan struct{}
+}
+
+func newVoterRegistryCache(caller voterRegistryCaller) *voterRegistryCache {
+	return &voterRegistryCache{
+		caller:  caller,
+		epochs:  make(map[int64]map[common.Address]registeredVoter),
+		pending: make(map[int64]*signingPolicy),
+		notify:  make(chan struct{}, 1),
+	}
+}
+
+// Schedule queues the refresh of the voters of the reward epoch of the signing policy
+func (c *voterRegistryCache) Schedule(policy *signingPolicy) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.pending[policy.rewardEpochId] = policy
+	c.mu.Unlock()
+
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run refreshes the scheduled reward epochs, oldest first, until ctx is done. Signers
+// of a reward epoch whose voters could not be fetched are verified only against the
+// signing policy.
+func (c *voterRegistryCache) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-c.notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		for _, policy := range c.takePending() {
+			if err := c.Refresh(ctx, policy); err != nil {
+				logger.Warn("Error refreshing voter registry cache: %v", err)
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+func (c *voterRegistryCache) takePending() []*signingPolicy {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	policies := make([]*signingPolicy, 0, len(c.pending))
+	for _, policy := range c.pending {
+		policies = append(policies, policy)
+	}
+	clear(c.pending)
+	sort.Slice(policies, func(i, j int) bool { return policies[i].rewardEpochId < policies[j].rewardEpochId })
+	return policies
+}
+
+// Refresh fetches the registered voters of the reward epoch of the signing policy,
+// the calls are cancelled when ctx is done
+func (c *voterRegistryCache) Refresh(ctx context.Context, policy *signingPolicy) error {
+	if c == nil {
+		return nil
+	}
+	rewardEpochId := big.NewInt(policy.rewardEpochId)
+	opts, cancel := voterRegistryCallOpts(ctx)
+	addresses, err := c.caller.GetRegisteredSigningPolicyAddresses(opts, rewardEpochId)
+	cancel()
+	if err != nil {
+		return errors.Wrapf(err, "error fetching registered voters of reward epoch %d", policy.rewardEpochId)
+	}
+	registered := make([]registeredVoter, len(addresses))
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(voterRegistryCallConcurrency)
+	for i, address := range addresses {
+		i, address := i, address
+		eg.Go(func() error {
+			opts, cancel := voterRegistryCallOpts(egCtx)
+			defer cancel()
+			voter, err := c.caller.GetVoterWithNormalisedWeight(opts, rewardEpochId, address)
+			if err != nil {
+				return errors.Wrapf(err, "error fetching weight of signing policy address %s", address.Hex())
+			}
+			registered[i] = registeredVoter{
+				voter:  voter.Voter,
+				weight: voter.NormalisedWeight,
+				index:  policy.voters.VoterIndex(address),
+			}
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	voters := make(map[common.Address]registeredVoter, len(addresses))
+	for i, address := range addresses {
+		voters[address] = registered[i]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epochs[policy.rewardEpochId] = voters
+	return nil
+}
+
+func voterRegistryCallOpts(ctx context.Context) (*bind.CallOpts, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, voterRegistryCallTimeout)
+	return &bind.CallOpts{Context: ctx}, cancel
+}
+
+// Remove drops the voters of the reward epochs
+func (c *voterRegistryCache) Remove(rewardEpochIds []uint32) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, rewardEpochId := range rewardEpochIds {
+		delete(c.epochs, int64(rewardEpochId))
+		delete(c.pending, int64(rewardEpochId))
+	}
+}
+
+// Verify checks that the signer is a registered voter of the reward epoch of the
+// signing policy, with the voter index and the weight of the signing policy.
+// Signers of reward epochs that are not cached are not verified.
+func (c *voterRegistryCache) Verify(policy *signingPolicy, signer common.Address, index int) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	voters, ok := c.epochs[policy.rewardEpochId]
+	if !ok {
+		return nil
+	}
+	voter, ok := voters[signer]
+	if !ok {
+		return fmt.Errorf("signer %s is not registered in the voter registry for reward epoch %d", signer.Hex(), policy.rewardEpochId)
+	}
+	if voter.index != index || voter.weight != policy.voters.VoterWeight(index) {
+		return fmt.Errorf("signer %s of voter %s has index %d with weight %d in the voter registry, %d with weight %d in the signing policy",
+			signer.Hex(), voter.voter.Hex(), voter.index, voter.weight, index, policy.voters.VoterWeight(index))
+	}
+	return nil
+}
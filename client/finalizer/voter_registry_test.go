@@ -0,0 +1,106 @@
+package finalizer
+
+import (
+	"context"
+	"flare-tlc/client/shared/voters"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// Registered voters by signing policy address
+type testVoterRegistryCaller struct {
+	voters  map[common.Address]common.Address
+	weights map[common.Address]uint16
+}
+
+func (c *testVoterRegistryCaller) GetRegisteredSigningPolicyAddresses(opts *bind.CallOpts, rewardEpochId *big.Int) ([]common.Address, error) {
+	if err := opts.Context.Err(); err != nil {
+		return nil, err
+	}
+	var addresses []common.Address
+	for address := range c.voters {
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+func (c *testVoterRegistryCaller) GetVoterWithNormalisedWeight(opts *bind.CallOpts, rewardEpochId *big.Int, signingPolicyAddress common.Address) (struct {
+	Voter            common.Address
+	NormalisedWeight uint16
+}, error) {
+	return struct {
+		Voter            common.Address
+		NormalisedWeight uint16
+	}{c.voters[signingPolicyAddress], c.weights[signingPolicyAddress]}, nil
+}
+
+func TestVoterRegistryCache(t *testing.T) {
+	signers := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03")}
+	policy := &signingPolicy{
+		rewardEpochId: 5,
+		voters:        voters.NewVoterSet(signers[:2], []uint16{100, 200}),
+	}
+	caller := &testVoterRegistryCaller{
+		voters: map[common.Address]common.Address{
+			signers[0]: common.HexToAddress("0x11"),
+			signers[1]: common.HexToAddress("0x12"),
+		},
+		weights: map[common.Address]uint16{signers[0]: 100, signers[1]: 150},
+	}
+	cache := newVoterRegistryCache(caller)
+
+	// not cached yet
+	require.NoError(t, cache.Verify(policy, signers[1], 1))
+
+	// the calls are cancelled with ctx
+	cancelled, cancelCalls := context.WithCancel(context.Background())
+	cancelCalls()
+	require.ErrorIs(t, cache.Refresh(cancelled, policy), context.Canceled)
+	require.NoError(t, cache.Verify(policy, signers[1], 1))
+
+	require.NoError(t, cache.Refresh(context.Background(), policy))
+	require.NoError(t, cache.Verify(policy, signers[0], 0))
+	require.ErrorContains(t, cache.Verify(policy, signers[1], 1), "with weight 150 in the voter registry")
+	require.ErrorContains(t, cache.Verify(policy, signers[2], 0), "is not registered in the voter registry")
+
+	cache.Remove([]uint32{5})
+	require.NoError(t, cache.Verify(policy, signers[1], 1))
+
+	// refreshed in the background, removed epochs are not refreshed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache.Schedule(policy)
+	cache.Schedule(&signingPolicy{rewardEpochId: 6, voters: policy.voters})
+	cache.Remove([]uint32{6})
+	require.NotContains(t, cache.pending, int64(6))
+	go cache.Run(ctx)
+	require.Eventually(t, func() bool { return cache.Verify(policy, signers[2], 0) != nil }, time.Second, time.Millisecond)
+
+	var disabled *voterRegistryCache
+	disabled.Schedule(policy)
+	require.NoError(t, disabled.Refresh(context.Background(), policy))
+	require.NoError(t, disabled.Verify(policy, signers[1], 1))
+	disabled.Remove([]uint32{5})
+}
+
+func TestSubmissionStorageVoterRegistry(t *testing.T) {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := crypto.PubkeyToAddress(privateKey.PublicKey)
+	policy := &signingPolicy{
+		rewardEpochId: 5,
+		voters:        voters.NewVoterSet([]common.Address{signer}, []uint16{100}),
+	}
+	storage := newSubmissionStorage(0)
+	storage.voterRegistry = newVoterRegistryCache(&testVoterRegistryCaller{})
+	require.NoError(t, storage.voterRegistry.Refresh(context.Background(), policy))
+
+	_, err = storage.Add(newTestSignedPayload(t, privateKey, 1), policy, 50, 10)
+	require.ErrorContains(t, err, "is not registered in the voter registry")
+}
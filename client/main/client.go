@@ -1,39 +1,141 @@
 package main
 
 import (
-	"context"
-	clientContext "flare-tlc/client/context"
-	"flare-tlc/client/runner"
-	"flare-tlc/client/shared"
-	"flare-tlc/logger"
+	"flag"
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"path/filepath"
+	"strings"
 )
 
+// Subcommand of the client binary, parses its flags from args
+type command struct {
+	name        string
+	description string
+	run         func(args []string) error
+}
+
+var commands = []*command{
+	{
+		name:        "run",
+		description: "run the enabled clients (default)",
+		run:         runClients,
+	},
+	{
+		name:        "register",
+		description: "register the identity as a voter for a reward epoch",
+		run:         registerCommand,
+	},
+	{
+		name:        "sign-policy",
+		description: "sign the signing policy of a reward epoch",
+		run:         signPolicyCommand,
+	},
+	{
+		name:        "finalize",
+		description: "relay the finalization of a voting round",
+		run:         finalizeCommand,
+	},
+	{
+		name:        "generate-sortition-key",
+		description: "generate a sortition key for fast updates",
+		run:         generateSortitionKeyCommand,
+	},
+	{
+		name:        "entity-status",
+		description: "compare the EntityManager registration of the identity with the config",
+		run:         entityStatusCommand,
+	},
+	{
+		name:        "entity-propose",
+		description: "propose the voter addresses of the configured keys, sent by the identity",
+		run:         entityProposeCommand,
+	},
+	{
+		name:        "entity-confirm",
+		description: "confirm the proposed voter addresses, sent by each address",
+		run:         entityConfirmCommand,
+	},
+	{
+		name:        "entity-register-public-key",
+		description: "register the sortition public key, sent by the identity",
+		run:         entityRegisterPublicKeyCommand,
+	},
+	{
+		name:        "validate-config",
+		description: "check the config against the chain, contracts, keys and database",
+		run:         validateConfigCommand,
+	},
+	{
+		name:        "doctor",
+		description: "self-check on Coston or Coston2: self txs, contract calls and indexer lag",
+		run:         doctorCommand,
+	},
+	{
+		name:        "replay-export",
+		description: "write the indexer data of a time range to a recording file",
+		run:         replayExportCommand,
+	},
+	{
+		name:        "replay",
+		description: "print the actions the clients would have taken on a recording",
+		run:         replayCommand,
+	},
+	{
+		name:        "report",
+		description: "summarize the participation of the identity in a reward epoch",
+		run:         reportCommand,
+	},
+	{
+		name:        "finalization-report",
+		description: "print per round statistics of the finalizations of a time range as CSV or JSON",
+		run:         finalizationReportCommand,
+	},
+	{
+		name:        "decode",
+		description: "print the decoded relay, submit1/2/3 or submitSignatures calldata of a tx as JSON",
+		run:         decodeCommand,
+	},
+}
+
 func main() {
-	logger.Info("Starting flare top level client")
+	args := os.Args[1:]
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name = args[0]
+		args = args[1:]
+	}
 
-	clientCtx, err := clientContext.BuildContext()
-	if err != nil {
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage()
+		os.Exit(2)
+	}
+	if err := cmd.run(args); err != nil {
 		fmt.Printf("%v\n", err)
-		return
+		os.Exit(1)
 	}
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func printUsage() {
+	binary := filepath.Base(os.Args[0])
+	fmt.Fprintf(os.Stderr, "Usage: %s [command] [flags]\n\nCommands:\n", binary)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-28s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", binary)
+}
 
-	// Prometheus metrics
-	shared.InitMetricsServer(&clientCtx.Config().Metrics)
-
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
-	ctx, cancel := context.WithCancel(context.Background())
-	go func() {
-		sig := <-signalChan
-		logger.Info("Received %v signal, attempting graceful shutdown", sig)
-		cancel()
-	}()
-
-	wg := runner.Start(ctx, cancel, clientCtx)
-	wg.Wait()
-	logger.Info("Stopped flare top level client")
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ExitOnError)
 }
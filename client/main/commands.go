@@ -0,0 +1,571 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"flare-tlc/client/config"
+	clientContext "flare-tlc/client/context"
+	"flare-tlc/client/diagnostics"
+	"flare-tlc/client/entity"
+	"flare-tlc/client/epoch"
+	"flare-tlc/client/finalizer"
+	"flare-tlc/client/replay"
+	"flare-tlc/client/report"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/logger"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/sortition"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const validateConfigTimeout = time.Minute
+
+// One-off manual operations, using the same config and keys as the run command
+
+func registerCommand(args []string) error {
+	fs := newFlagSet("register")
+	flags := clientContext.RegisterFlags(fs)
+	epochId := fs.Int64("epoch", -1, "Reward epoch id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *epochId < 0 {
+		return errors.New("-epoch is required")
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	if err := epoch.RegisterVoter(clientCtx, big.NewInt(*epochId)); err != nil {
+		return err
+	}
+	logger.Info("Voter registered for reward epoch %d", *epochId)
+	return nil
+}
+
+func signPolicyCommand(args []string) error {
+	fs := newFlagSet("sign-policy")
+	flags := clientContext.RegisterFlags(fs)
+	epochId := fs.Int64("epoch", -1, "Reward epoch id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *epochId < 0 {
+		return errors.New("-epoch is required")
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	if err := epoch.SignPolicy(clientCtx, big.NewInt(*epochId)); err != nil {
+		return err
+	}
+	logger.Info("Signing policy signed for reward epoch %d", *epochId)
+	return nil
+}
+
+func finalizeCommand(args []string) error {
+	fs := newFlagSet("finalize")
+	flags := clientContext.RegisterFlags(fs)
+	votingRoundId := fs.Int64("round", -1, "Voting round id")
+	protocolId := fs.Uint("protocol", 0, "Protocol id")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *votingRoundId < 0 {
+		return errors.New("-round is required")
+	}
+	if *protocolId == 0 || *protocolId > 255 {
+		return errors.New("-protocol must be between 1 and 255")
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	if err := finalizer.FinalizeRound(ctx, clientCtx, uint32(*votingRoundId), byte(*protocolId)); err != nil {
+		return err
+	}
+	logger.Info("Voting round %d of protocol %d finalized", *votingRoundId, *protocolId)
+	return nil
+}
+
+// Generates a sortition key, the key is written to the output file or printed.
+// The public key and, for the identity, the proof of possession are printed for
+// the registration of the public key in the EntityManager.
+func generateSortitionKeyCommand(args []string) error {
+	fs := newFlagSet("generate-sortition-key")
+	out := fs.String("out", "", "File the key is written to, the key is printed if not set")
+	identity := fs.String("identity", "", "Identity address of the voter, the proof of possession is printed if set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*identity) > 0 && !common.IsHexAddress(*identity) {
+		return fmt.Errorf("invalid identity address %s", *identity)
+	}
+
+	key, err := sortition.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	if len(*out) > 0 {
+		if err := globalConfig.WriteSortitionKeyFile(*out, key); err != nil {
+			return err
+		}
+		fmt.Printf("Sortition key written to %s\n", *out)
+	} else {
+		fmt.Printf("Sortition key:         %s\n", key.SecretHex())
+	}
+	return printSortitionPublicKey(key, *identity)
+}
+
+func printSortitionPublicKey(key *sortition.Key, identity string) error {
+	part1, part2 := key.PublicKeyParts()
+	fmt.Printf("Public key part 1:     %s\n", hexutil.Encode(part1[:]))
+	fmt.Printf("Public key part 2:     %s\n", hexutil.Encode(part2[:]))
+	if len(identity) == 0 {
+		return nil
+	}
+	verificationData, err := sortition.ProofOfPossession(key, common.HexToAddress(identity))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Proof of possession:   %s\n", hexutil.Encode(verificationData))
+	return nil
+}
+
+// Prints the EntityManager registration of the identity, compared with the config
+func entityStatusCommand(args []string) error {
+	return entityRolesCommand("entity-status", args, func(clientCtx clientContext.ClientContext, roles []*entity.Role) error {
+		status, err := entity.FetchStatus(clientCtx, roles)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Identity %s\n", status.Identity.Hex())
+		for _, role := range status.Roles {
+			fmt.Printf("  %s\n", role)
+		}
+		if status.PublicKey != nil {
+			fmt.Printf("  %s\n", status.PublicKey)
+		}
+		if !status.Ok() {
+			return errors.New("EntityManager registration diverges from the config")
+		}
+		return nil
+	})
+}
+
+func entityProposeCommand(args []string) error {
+	return entityRolesCommand("entity-propose", args, entity.Propose)
+}
+
+func entityConfirmCommand(args []string) error {
+	return entityRolesCommand("entity-confirm", args, entity.Confirm)
+}
+
+func entityRegisterPublicKeyCommand(args []string) error {
+	fs := newFlagSet("entity-register-public-key")
+	flags := clientContext.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	return entity.RegisterPublicKey(clientCtx)
+}
+
+func entityRolesCommand(
+	name string, args []string, run func(clientCtx clientContext.ClientContext, roles []*entity.Role) error,
+) error {
+	fs := newFlagSet(name)
+	flags := clientContext.RegisterFlags(fs)
+	roleNames := fs.String("role", "", "Comma separated roles (submit, submit_signatures, signing_policy, next_signing_policy), all if not set")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	roles, err := entity.ParseRoles(*roleNames)
+	if err != nil {
+		return err
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	return run(clientCtx, roles)
+}
+
+// Unlike the other commands, the context is not built, so that an unreachable
+// database or a missing contract address is reported instead of aborting
+func validateConfigCommand(args []string) error {
+	fs := newFlagSet("validate-config")
+	flags := clientContext.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.BuildConfig(flags.ConfigFileName)
+	if err != nil {
+		return err
+	}
+	globalConfig.GlobalConfigCallback.Call(cfg)
+
+	validator, err := diagnostics.NewValidator(cfg)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), validateConfigTimeout)
+	defer cancel()
+
+	report := validator.Validate(ctx)
+	report.Print(os.Stdout)
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("config validation failed, %d checks failed", failed)
+	}
+	return nil
+}
+
+// Like validate-config the context is not built. Sends a zero-value transaction
+// from each account, only on the test networks.
+func doctorCommand(args []string) error {
+	fs := newFlagSet("doctor")
+	flags := clientContext.RegisterFlags(fs)
+	maxIndexerLag := fs.Duration("max-indexer-lag", diagnostics.DefaultMaxIndexerLag, "Maximum lag of the indexer database behind the chain head")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := config.BuildConfig(flags.ConfigFileName)
+	if err != nil {
+		return err
+	}
+	globalConfig.GlobalConfigCallback.Call(cfg)
+	chain.SetDryRun(flags.DryRun)
+	chain.SetObserver(flags.Observer)
+
+	doctor, err := diagnostics.NewDoctor(cfg, *maxIndexerLag)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	report := doctor.Run(ctx)
+	report.Print(os.Stdout)
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("not ready, %d checks failed", failed)
+	}
+	fmt.Println("ready")
+	return nil
+}
+
+// Writes the indexer data of a time range to a recording file for the replay command
+func replayExportCommand(args []string) error {
+	fs := newFlagSet("replay-export")
+	flags := clientContext.RegisterFlags(fs)
+	fromFlag := fs.String("from", "", "Start of the range, unix timestamp or RFC 3339 time (exclusive)")
+	toFlag := fs.String("to", "", "End of the range, unix timestamp or RFC 3339 time, now if not set")
+	out := fs.String("out", "", "File the recording is written to")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*out) == 0 {
+		return errors.New("-out is required")
+	}
+	from, err := parseReplayTime(*fromFlag)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	to := time.Now()
+	if len(*toFlag) > 0 {
+		if to, err = parseReplayTime(*toFlag); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+	if !to.After(from) {
+		return errors.New("-to must be after -from")
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	if clientCtx.DB() == nil {
+		return errors.New("replay-export reads the indexer database, not available with the rpc listener source")
+	}
+	cfg := clientCtx.Config()
+	ethClient, err := clientCtx.EthClient()
+	if err != nil {
+		return err
+	}
+	relayContract, err := relay.NewRelay(cfg.ContractAddresses.Relay, ethClient)
+	if err != nil {
+		return err
+	}
+	epochs, err := replay.EpochSettingsFromChain(relayContract)
+	if err != nil {
+		return err
+	}
+	contracts := replay.Contracts{
+		Relay:          cfg.ContractAddresses.Relay,
+		Submission:     cfg.ContractAddresses.Submission,
+		SystemsManager: cfg.ContractAddresses.SystemsManager,
+	}
+	recording, err := replay.Export(clientCtx.DB(), contracts, epochs, from, to, cfg.Listeners.Fetch.FetchOptions())
+	if err != nil {
+		return err
+	}
+	if err := recording.Save(*out); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d logs and %d transactions to %s\n", len(recording.Logs), len(recording.Transactions), *out)
+	return nil
+}
+
+// Prints the actions the enabled clients would have taken on a recording, nothing
+// is sent, the chain and the database are not used
+func replayCommand(args []string) error {
+	fs := newFlagSet("replay")
+	flags := clientContext.RegisterFlags(fs)
+	in := fs.String("in", "", "Recording file written by replay-export")
+	address := fs.String("address", "", "Signing policy address of the replayed client, the address of the configured signing policy key if not set")
+	jsonOutput := fs.Bool("json", false, "Print the actions as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(*in) == 0 {
+		return errors.New("-in is required")
+	}
+	if len(*address) > 0 && !common.IsHexAddress(*address) {
+		return fmt.Errorf("invalid address %s", *address)
+	}
+
+	cfg, err := config.BuildConfig(flags.ConfigFileName)
+	if err != nil {
+		return err
+	}
+	globalConfig.GlobalConfigCallback.Call(cfg)
+
+	signingPolicyAddress := common.HexToAddress(*address)
+	if len(*address) == 0 {
+		signer, err := globalConfig.SignerFromConfig(&cfg.Credentials.SigningPolicySigner,
+			cfg.Credentials.SigningPolicyPrivateKeyFile, cfg.Credentials.SigningPolicyPrivateKey)
+		if err != nil {
+			return fmt.Errorf("-address is required without a signing policy key: %w", err)
+		}
+		signingPolicyAddress = signer.Address()
+	}
+	recording, err := replay.LoadRecording(*in)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	report, err := replay.Run(ctx, cfg, recording, signingPolicyAddress)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	report.Print(os.Stdout)
+	return nil
+}
+
+func parseReplayTime(s string) (time.Time, error) {
+	if len(s) == 0 {
+		return time.Time{}, errors.New("time is required")
+	}
+	if timestamp, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(timestamp, 0), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Prints the participation of the identity in a reward epoch
+func reportCommand(args []string) error {
+	fs := newFlagSet("report")
+	flags := clientContext.RegisterFlags(fs)
+	epochId := fs.Int64("epoch", -1, "Reward epoch id")
+	jsonOutput := fs.Bool("json", false, "Print the summary as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *epochId < 0 {
+		return errors.New("-epoch is required")
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	summary, err := report.BuildRewardEpochSummary(ctx, clientCtx, *epochId)
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+	summary.Print(os.Stdout)
+	return nil
+}
+
+// Prints the statistics of the messages relayed in a time range, read from the indexer database
+func finalizationReportCommand(args []string) error {
+	fs := newFlagSet("finalization-report")
+	flags := clientContext.RegisterFlags(fs)
+	fromFlag := fs.String("from", "", "Start of the range, unix timestamp or RFC 3339 time (exclusive)")
+	toFlag := fs.String("to", "", "End of the range, unix timestamp or RFC 3339 time, now if not set")
+	format := fs.String("format", "csv", "Output format, csv or json")
+	out := fs.String("out", "", "File the report is written to, printed if not set")
+	receipts := fs.Bool("receipts", true, "Fetch the gas used by the relay txs from their receipts")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown -format %s, valid values are csv and json", *format)
+	}
+	from, err := parseReplayTime(*fromFlag)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	to := time.Now()
+	if len(*toFlag) > 0 {
+		if to, err = parseReplayTime(*toFlag); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+	if !to.After(from) {
+		return errors.New("-to must be after -from")
+	}
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	defer clientCtx.Close()
+
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	stats, err := finalizer.FinalizationReport(ctx, clientCtx, from, to, *receipts)
+	if err != nil {
+		return err
+	}
+	w := os.Stdout
+	if len(*out) > 0 {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	if *format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	return finalizer.WriteFinalizationStatsCSV(w, stats)
+}
+
+// Decodes the calldata given as hex or of a tx fetched from the chain of the config
+func decodeCommand(args []string) error {
+	fs := newFlagSet("decode")
+	flags := clientContext.RegisterFlags(fs)
+	txHash := fs.String("tx", "", "Hash of the tx, the calldata is fetched from chain.eth_rpc_url of the config")
+	data := fs.String("data", "", "Hex encoded calldata, including the function selector")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (len(*txHash) == 0) == (len(*data) == 0) {
+		return errors.New("one of -tx and -data is required")
+	}
+
+	var calldata []byte
+	if len(*data) > 0 {
+		var err error
+		if calldata, err = hexutil.Decode(ensureHexPrefix(*data)); err != nil {
+			return fmt.Errorf("invalid -data: %w", err)
+		}
+	} else {
+		hash, err := hexutil.Decode(ensureHexPrefix(*txHash))
+		if err != nil || len(hash) != common.HashLength {
+			return fmt.Errorf("invalid -tx %s", *txHash)
+		}
+		cfg, err := config.BuildConfig(flags.ConfigFileName)
+		if err != nil {
+			return err
+		}
+		chainCfg := cfg.ChainConfig()
+		ethClient, err := chainCfg.DialETH()
+		if err != nil {
+			return err
+		}
+		defer ethClient.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), validateConfigTimeout)
+		defer cancel()
+		tx, _, err := ethClient.TransactionByHash(ctx, common.BytesToHash(hash))
+		if err != nil {
+			return fmt.Errorf("cannot fetch tx %s: %w", *txHash, err)
+		}
+		calldata = tx.Data()
+	}
+
+	decoded, err := finalizer.DecodeCalldata(calldata)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(decoded)
+}
+
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s
+	}
+	return "0x" + s
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	clientConfig "flare-tlc/client/config"
+	clientContext "flare-tlc/client/context"
+	"flare-tlc/client/entity"
+	"flare-tlc/client/epoch"
+	"flare-tlc/client/runner"
+	"flare-tlc/client/shared"
+	"flare-tlc/client/shared/alerts"
+	"flare-tlc/config"
+	"flare-tlc/logger"
+	"flare-tlc/utils"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// pending spans are flushed on shutdown for at most this long
+const tracingShutdownTimeout = 5 * time.Second
+
+func runClients(args []string) error {
+	fs := newFlagSet("run")
+	flags := clientContext.RegisterFlags(fs)
+	watchConfig := fs.Bool("watch-config", false, "Apply changes of the config file that do not require restart (log levels, gas, retry, data provider endpoints) at runtime")
+	resetCheckpoint := fs.Bool("reset-checkpoint", false, "Delete the listener checkpoints, the epoch client listeners start from the default range (backfill)")
+	skipContractCheck := fs.Bool("skip-contract-check", false, "Do not check that the deployed FlareSystemsManager, Relay and Submission contracts have the methods of the bindings")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	logger.Info("Starting flare top level client")
+
+	clientCtx, err := clientContext.BuildContextWithFlags(flags)
+	if err != nil {
+		return err
+	}
+	// shared connections are closed after the clients are stopped
+	defer clientCtx.Close()
+
+	// the events and methods of the enabled clients are resolved before any client starts
+	if err := shared.ValidateContractABIs(&clientCtx.Config().Clients); err != nil {
+		return err
+	}
+
+	if *resetCheckpoint {
+		if clientCtx.DB() == nil {
+			return errors.New("-reset-checkpoint requires the indexer listeners source")
+		}
+		if err := epoch.ResetListenerCheckpoints(clientCtx.DB()); err != nil {
+			return err
+		}
+		logger.Info("Listener checkpoints reset")
+	}
+
+	// Prometheus metrics and health endpoints
+	shared.InitMetricsServer(&clientCtx.Config().Metrics)
+	shutdownTracing, err := shared.InitTracing(context.Background(), &clientCtx.Config().Tracing)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Warn("Error flushing spans: %v", err)
+		}
+	}()
+	ethClient, err := clientCtx.EthClient()
+	if err != nil {
+		return err
+	}
+	shared.RegisterConnectivityChecks(clientCtx.DB(), ethClient)
+
+	// warns if the keys are not the addresses registered for the identity
+	entity.EnsureRegistered(clientCtx)
+
+	cfg := clientCtx.Config()
+	dispatcher, err := alerts.NewDispatcher(&cfg.Alerts, cfg.Identity.Address.Hex())
+	if err != nil {
+		return err
+	}
+	alerts.SetDefault(dispatcher)
+
+	ctx, cancel := signalContext()
+
+	if dispatcher != nil {
+		go func() {
+			_ = dispatcher.Run(ctx)
+		}()
+	}
+	if monitor := clientCtx.IndexerMonitor(); monitor != nil {
+		go monitor.Run(ctx)
+	}
+
+	if *watchConfig {
+		go func() {
+			err := clientConfig.WatchConfigFile(ctx, flags.ConfigFileName, clientCtx.Config())
+			if err != nil && !errors.Is(err, context.Canceled) {
+				logger.Error("Config file watcher stopped: %v", err)
+			}
+		}()
+	}
+
+	for {
+		if !*skipContractCheck {
+			if err := shared.CheckContractBindings(ctx, ethClient, &cfg.ContractAddresses); err != nil {
+				cancel()
+				return err
+			}
+		}
+		restart := runUntilContractsChange(ctx, cancel, clientCtx)
+		if !restart {
+			break
+		}
+		logger.Info("Restarting clients with the new contract addresses")
+	}
+
+	logger.Info("Stopped flare top level client")
+	return nil
+}
+
+// Runs the clients until ctx is done or, if the contract addresses are
+// re-resolved from the registry, until an address changes. Returns true if the
+// clients were stopped because of the change and have to be restarted.
+func runUntilContractsChange(ctx context.Context, cancel context.CancelFunc, clientCtx clientContext.ClientContext) bool {
+	cfg := clientCtx.Config()
+	resolver := clientCtx.ContractResolver()
+	if resolver == nil || cfg.ContractRegistry.RefreshInterval == 0 {
+		runner.Start(ctx, cancel, clientCtx).Wait()
+		return false
+	}
+
+	runCtx, stopRun := context.WithCancel(ctx)
+	defer stopRun()
+
+	changed := make(chan *config.ContractAddresses, 1)
+	go func() {
+		addresses, err := resolver.WaitForChange(runCtx, cfg.ContractAddresses, cfg.ContractRegistry.RefreshInterval, utils.RealClock)
+		if err != nil {
+			changed <- nil
+			return
+		}
+		shared.LogContractAddressChanges(cfg.ContractAddresses, addresses)
+		changed <- &addresses
+		stopRun()
+	}()
+
+	runner.Start(runCtx, cancel, clientCtx).Wait()
+	stopRun()
+
+	// the config is updated after all clients are stopped
+	addresses := <-changed
+	if addresses == nil || ctx.Err() != nil {
+		return false
+	}
+	cfg.ContractAddresses = *addresses
+	return true
+}
+
+// Returns a context cancelled on SIGINT or SIGTERM
+func signalContext() (context.Context, context.CancelFunc) {
+	signalChan := make(chan os.Signal, 1)
+	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		sig := <-signalChan
+		logger.Info("Received %v signal, attempting graceful shutdown", sig)
+		cancel()
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,172 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared"
+	"flare-tlc/utils/merkle"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// FDC data provider API, the responses are encoded as the submit payloads of
+// the generic data providers:
+//   - GET <api_endpoint>/bit-vector/<votingRoundId>/<submitAddress> returns
+//     {"status": "OK", "bitVector": "0x..."}, the bit vector of the attestation
+//     requests of the voting round confirmed by the provider, submitted in submit2
+//   - GET <api_endpoint>/attestations/<votingRoundId> returns {"status": "OK",
+//     "consensusBitVector": "0x...", "responseHashes": ["0x..."]}, the hashes of the
+//     attestation responses selected by the consensus bit vector. The Merkle root of
+//     the hashes is signed in submitSignatures, with the consensus bit vector as
+//     additional data.
+const (
+	fdcBitVectorPath    = "bit-vector"
+	fdcAttestationsPath = "attestations"
+)
+
+type fdcBitVectorResponse struct {
+	Status    string `json:"status"`
+	BitVector string `json:"bitVector"`
+}
+
+type fdcAttestationsResponse struct {
+	Status             string   `json:"status"`
+	ConsensusBitVector string   `json:"consensusBitVector"`
+	ResponseHashes     []string `json:"responseHashes"`
+}
+
+// FDC only submits in submit2 and submitSignatures
+func (sp *SubProtocol) submits(submitName string) bool {
+	return sp.Type != config.ProtocolTypeFDC || submitName == "submit2" || submitName == "submitSignatures"
+}
+
+func (sp *SubProtocol) getFdcData(
+	provider DataProvider, votingRound int64, submitName string, submitAddress string, timeout time.Duration,
+) (*SubProtocolResponse, error) {
+	switch submitName {
+	case "submit2":
+		return sp.getFdcBitVector(provider, votingRound, submitAddress, timeout)
+	case "submitSignatures":
+		return sp.getFdcMerkleRoot(provider, votingRound, timeout)
+	default:
+		return nil, errors.Errorf("FDC does not submit in %s", submitName)
+	}
+}
+
+func (sp *SubProtocol) getFdcBitVector(
+	provider DataProvider, votingRound int64, submitAddress string, timeout time.Duration,
+) (*SubProtocolResponse, error) {
+	var response fdcBitVectorResponse
+	if err := getFdcResponse(provider, votingRound, fdcBitVectorPath, submitAddress, timeout, &response); err != nil {
+		return nil, err
+	}
+	if response.Status != "OK" {
+		return &SubProtocolResponse{Status: response.Status}, nil
+	}
+	bitVector, err := hexutil.Decode(response.BitVector)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode FDC bit vector")
+	}
+	if len(bitVector) > math.MaxUint16 {
+		return nil, errors.New("FDC bit vector too long")
+	}
+
+	// protocol id (1 byte), voting round (4 bytes), length (2 bytes), bit vector
+	buffer := bytes.NewBuffer(nil)
+	votingRoundBytes := shared.Uint32toBytes(uint32(votingRound))
+	lengthBytes := shared.Uint16toBytes(uint16(len(bitVector)))
+	buffer.WriteByte(sp.Id)
+	buffer.Write(votingRoundBytes[:])
+	buffer.Write(lengthBytes[:])
+	buffer.Write(bitVector)
+	return &SubProtocolResponse{Status: response.Status, Data: buffer.Bytes()}, nil
+}
+
+func (sp *SubProtocol) getFdcMerkleRoot(
+	provider DataProvider, votingRound int64, timeout time.Duration,
+) (*SubProtocolResponse, error) {
+	var response fdcAttestationsResponse
+	if err := getFdcResponse(provider, votingRound, fdcAttestationsPath, "", timeout, &response); err != nil {
+		return nil, err
+	}
+	if response.Status != "OK" {
+		return &SubProtocolResponse{Status: response.Status}, nil
+	}
+	consensusBitVector, err := hexutil.Decode(response.ConsensusBitVector)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot decode FDC consensus bit vector")
+	}
+	hashes := make([]common.Hash, len(response.ResponseHashes))
+	for i, hashString := range response.ResponseHashes {
+		hash, err := hexutil.Decode(hashString)
+		if err != nil || len(hash) != common.HashLength {
+			return nil, errors.Errorf("invalid FDC attestation response hash %s", hashString)
+		}
+		hashes[i] = common.BytesToHash(hash)
+	}
+
+	// protocol id (1 byte), voting round (4 bytes), is secure random (1 byte), Merkle root (32 bytes)
+	root := fdcMerkleRoot(hashes)
+	buffer := bytes.NewBuffer(nil)
+	votingRoundBytes := shared.Uint32toBytes(uint32(votingRound))
+	buffer.WriteByte(sp.Id)
+	buffer.Write(votingRoundBytes[:])
+	buffer.WriteByte(0)
+	buffer.Write(root[:])
+	return &SubProtocolResponse{Status: response.Status, Data: buffer.Bytes(), AdditionalData: consensusBitVector}, nil
+}
+
+// Merkle root of the attestation response hashes, zero if there are no attestations
+func fdcMerkleRoot(hashes []common.Hash) common.Hash {
+	if len(hashes) == 0 {
+		return common.Hash{}
+	}
+	root, _ := merkle.Build(hashes, false).Root()
+	return root
+}
+
+func getFdcResponse(
+	provider DataProvider, votingRound int64, path string, submitAddress string, timeout time.Duration, response any,
+) error {
+	url, err := getUrl(votingRound, provider.ApiEndpoint, path, submitAddress)
+	if err != nil {
+		return errors.Wrap(err, "error getting url")
+	}
+
+	logger.Info("Calling FDC provider API: %s", url.String())
+	client := http.Client{
+		Timeout: timeout,
+	}
+	req, err := http.NewRequest("GET", url.String(), nil)
+	if err != nil {
+		return errors.Wrap(err, "error creating FDC provider API request")
+	}
+	if len(provider.XApiKey) > 0 {
+		req.Header.Set("X-API-KEY", provider.XApiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "error calling FDC provider API")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("FDC provider returned http status %v", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "error reading FDC provider response")
+	}
+	if err := json.Unmarshal(body, response); err != nil {
+		return errors.Wrap(err, "cannot parse FDC provider response body")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package protocol
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/utils/merkle"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFdcData(t *testing.T) {
+	hashes := []common.Hash{common.HexToHash("0x02"), common.HexToHash("0x01"), common.HexToHash("0x03")}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fdc/bit-vector/10/0xabc":
+			_, _ = w.Write([]byte(`{"status": "OK", "bitVector": "0x0003a0"}`))
+		case "/fdc/attestations/10":
+			_, _ = w.Write([]byte(`{"status": "OK", "consensusBitVector": "0x000320", "responseHashes": ["` +
+				hashes[0].Hex() + `", "` + hashes[1].Hex() + `", "` + hashes[2].Hex() + `"]}`))
+		case "/fdc/attestations/11":
+			_, _ = w.Write([]byte(`{"status": "OK", "consensusBitVector": "0x0000", "responseHashes": []}`))
+		default:
+			_, _ = w.Write([]byte(`{"status": "NOT_AVAILABLE"}`))
+		}
+	}))
+	defer server.Close()
+
+	sp := NewSubProtocol(config.ProtocolConfig{Id: 200, Type: config.ProtocolTypeFDC, ApiEndpoint: server.URL + "/fdc"})
+	require.False(t, sp.submits("submit1"))
+	require.True(t, sp.submits("submit2"))
+	require.True(t, sp.submits("submitSignatures"))
+
+	data, err := sp.getData(10, "submit2", "0xabc", time.Second, IdentityDataVerifier)
+	require.NoError(t, err)
+	require.Equal(t, "OK", data.Status)
+	require.Equal(t, hexutil.MustDecode("0xc8"+"0000000a"+"0003"+"0003a0"), data.Data)
+
+	data, err = sp.getData(10, "submitSignatures", "0xabc", time.Second, SignatureSubmitterDataVerifier)
+	require.NoError(t, err)
+	root, err := merkle.Build(hashes, false).Root()
+	require.NoError(t, err)
+	require.Equal(t, append(hexutil.MustDecode("0xc8"+"0000000a"+"00"), root[:]...), data.Data)
+	require.Equal(t, hexutil.MustDecode("0x000320"), data.AdditionalData)
+
+	// no consensus attestations
+	data, err = sp.getData(11, "submitSignatures", "0xabc", time.Second, SignatureSubmitterDataVerifier)
+	require.NoError(t, err)
+	require.Equal(t, common.Hash{}.Bytes(), data.Data[6:])
+
+	data, err = sp.getData(12, "submit2", "0xabc", time.Second, IdentityDataVerifier)
+	require.NoError(t, err)
+	require.Equal(t, "NOT_AVAILABLE", data.Status)
+}
@@ -0,0 +1,5 @@
+package protocol
+
+import flarelogger "flare-tlc/logger"
+
+var logger = flarelogger.Module("protocol")
@@ -0,0 +1,18 @@
+package protocol
+
+import (
+	"flare-tlc/utils/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	dataProviderRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "protocol",
+		Name:      "data_provider_request_duration_seconds",
+		Help:      "Duration of protocol data provider requests",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"protocol", "provider", "result"})
+)
@@ -2,26 +2,24 @@ package protocol
 
 import (
 	"context"
+	"flare-tlc/client/config"
 	clientContext "flare-tlc/client/context"
 	"flare-tlc/client/shared"
-	"flare-tlc/logger"
 	"flare-tlc/utils"
 	"flare-tlc/utils/contracts/registry"
 	"flare-tlc/utils/contracts/system"
 	"math/big"
-	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 )
 
 type ProtocolClient struct {
 	subProtocols []*SubProtocol
-	eth          *ethclient.Client
 
 	protocolContext *protocolContext
 
@@ -35,6 +33,11 @@ type ProtocolClient struct {
 	rewardEpoch     *utils.Epoch
 	registry        voterRegistry
 	identityAddress common.Address
+
+	clock utils.Clock
+
+	// removes the endpoints reload callback when the client stops
+	removeReloadCallback func()
 }
 
 type voterRegistry interface {
@@ -58,8 +61,7 @@ func NewProtocolClient(ctx clientContext.ClientContext) (*ProtocolClient, error)
 		return nil, nil
 	}
 
-	chainCfg := cfg.ChainConfig()
-	cl, err := chainCfg.DialETH()
+	cl, err := ctx.EthClient()
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +76,11 @@ func NewProtocolClient(ctx clientContext.ClientContext) (*ProtocolClient, error)
 		return nil, errors.Wrap(err, "error getting voting epoch")
 	}
 
-	protocolContext, err := newProtocolContext(cfg)
+	signer, err := ctx.SigningPolicySigner()
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating signer")
+	}
+	protocolContext, err := newProtocolContext(cfg, signer)
 	if err != nil {
 		return nil, err
 	}
@@ -95,7 +101,6 @@ func NewProtocolClient(ctx clientContext.ClientContext) (*ProtocolClient, error)
 	}
 
 	pc := &ProtocolClient{
-		eth:             cl,
 		protocolContext: protocolContext,
 		subProtocols:    subProtocols,
 		votingEpoch:     votingEpoch,
@@ -103,94 +108,115 @@ func NewProtocolClient(ctx clientContext.ClientContext) (*ProtocolClient, error)
 		rewardEpoch:     rewardEpoch,
 		registry:        voterRegistryImpl{registryClient},
 		identityAddress: cfg.Identity.Address,
+		clock:           utils.RealClock,
 	}
 
-	selectors := newContractSelectors()
+	selectors, err := newContractSelectors()
+	if err != nil {
+		return nil, err
+	}
 
 	if cfg.Submit1.Enabled {
 		pc.submitter1 = newSubmitter(cl, protocolContext, votingEpoch,
-			&cfg.Submit1, &cfg.SubmitGas, selectors.submit1, subProtocols, 0, "submit1")
+			&cfg.Submit1, &cfg.SubmitGas, &cfg.Retry, selectors.submit1, subProtocols, 0, "submit1")
 	} else {
 		logger.Warn("submit1 is disabled")
 	}
 	if cfg.Submit2.Enabled {
 		pc.submitter2 = newSubmitter(cl, protocolContext, votingEpoch,
-			&cfg.Submit2, &cfg.SubmitGas, selectors.submit2, subProtocols, -1, "submit2")
+			&cfg.Submit2, &cfg.SubmitGas, &cfg.Retry, selectors.submit2, subProtocols, -1, "submit2")
 	} else {
 		logger.Warn("submit2 is disabled")
 	}
 	if cfg.SubmitSignatures.Enabled {
 		pc.signatureSubmitter = newSignatureSubmitter(cl, protocolContext, votingEpoch,
-			&cfg.SubmitSignatures, &cfg.SubmitGas, selectors.submitSignatures, subProtocols)
+			&cfg.SubmitSignatures, &cfg.SubmitGas, &cfg.Retry, selectors.submitSignatures, subProtocols)
 	} else {
 		logger.Warn("submitSignatures is disabled")
 	}
+
+	pc.removeReloadCallback = config.ReloadCallback.AddCallback(pc.updateEndpoints)
 	return pc, nil
 }
 
+// Updates the data provider endpoints after a config reload, protocols are
+// matched by id
+func (c *ProtocolClient) updateEndpoints(cfg *config.ClientConfig) {
+	for _, protocol := range cfg.Protocol {
+		for _, sp := range c.subProtocols {
+			if sp.Id == protocol.Id {
+				sp.setEndpoints(protocol)
+			}
+		}
+	}
+}
+
 func (c *ProtocolClient) Run(ctx context.Context) error {
+	if c.removeReloadCallback != nil {
+		defer c.removeReloadCallback()
+	}
 	if err := c.waitUntilRegistered(ctx); err != nil {
 		return err
 	}
 
-	done := make(chan bool, 1)
-	var wg sync.WaitGroup
-
 	logger.Info("Starting submitters, waiting for next voting round start.")
-	ticker := utils.NewEpochTicker(c.votingEpoch)
-L:
-	for {
-		select {
-		case currentEpoch := <-ticker.C:
-			if c.submitter1 != nil {
-				go func() {
-					time.Sleep(c.submitter1.startOffset)
-					if c.submitter2 != nil {
-						// if running submitter1, and submitter2 is enabled,
-						// we need to wait for it to complete before shutdown.
-						wg.Add(1)
-					}
-					c.submitter1.RunEpoch(currentEpoch)
-				}()
-			}
-
-			if c.submitter2 != nil {
-				go func() {
-					// Submit2 processes the current epoch data in the following epoch
-					// so we wait a full epoch duration + offset before invoking.
-					// TODO: this assumes c.submitter2.epochOffset is always -1
-					time.Sleep(ticker.Epoch.Period + c.submitter2.startOffset)
-					c.submitter2.RunEpoch(currentEpoch + 1)
-					if c.submitter1 != nil {
-						wg.Done()
-					}
-				}()
-			}
-			if c.signatureSubmitter != nil {
-				// signatureSubmitter is independent of submit1 and submit2
-				go func() {
-					time.Sleep(c.signatureSubmitter.startOffset)
-					c.signatureSubmitter.RunEpoch(currentEpoch)
-				}()
-			}
-		case <-ctx.Done():
-			if c.submitter1 != nil && c.submitter2 != nil {
-				logger.Warn("Stopping submitters. Making sure both submit1 & submit2 have completed for the voting round. Not running submit2 might result in reward penalties.")
-				wg.Wait()
-			}
 
-			done <- true
-			break L
-		}
+	// voting rounds of the last submit1 and submit2 runs, submit2 following a
+	// submit1 run is completed on shutdown
+	var lastSubmit1, lastSubmit2 atomic.Int64
+	lastSubmit1.Store(-1)
+	lastSubmit2.Store(-1)
+
+	scheduler := utils.NewScheduler(c.clock)
+	if c.submitter1 != nil {
+		scheduler.AtOffset("submit1", c.votingEpoch, c.submitter1.startOffset, func(votingRound int64) {
+			lastSubmit1.Store(votingRound)
+			c.submitter1.RunEpoch(votingRound)
+		})
+	}
+	if c.submitter2 != nil {
+		// Submit2 processes the previous voting round data in the following round,
+		// this assumes c.submitter2.epochOffset is always -1
+		scheduler.AtOffset("submit2", c.votingEpoch, c.submitter2.startOffset, func(votingRound int64) {
+			c.submitter2.RunEpoch(votingRound)
+			lastSubmit2.Store(votingRound)
+		})
 	}
+	if c.signatureSubmitter != nil {
+		// signatureSubmitter is independent of submit1 and submit2
+		scheduler.AtOffset("submitSignatures", c.votingEpoch, c.signatureSubmitter.startOffset, c.signatureSubmitter.RunEpoch)
+	}
+	if c.systemsManager != nil {
+		scheduler.AtStart("refreshEpochs", c.rewardEpoch, func(int64) { c.refreshEpochs() })
+	}
+	_ = scheduler.Run(ctx)
 
-	<-done
+	if c.submitter1 != nil && c.submitter2 != nil && lastSubmit1.Load() >= 0 && lastSubmit2.Load() <= lastSubmit1.Load() {
+		logger.Warn("Stopping submitters. Making sure both submit1 & submit2 have completed for the voting round. Not running submit2 might result in reward penalties.")
+		votingRound := lastSubmit1.Load() + 1
+		time.Sleep(c.clock.Until(c.votingEpoch.StartTime(votingRound).Add(c.submitter2.startOffset)))
+		c.submitter2.RunEpoch(votingRound)
+	}
 	return nil
 }
 
+// Adds the epoch schedule changes announced by the systems manager
+func (c *ProtocolClient) refreshEpochs() {
+	if changed, err := shared.RefreshVotingEpoch(c.systemsManager, c.votingEpoch); err != nil {
+		logger.Warn("Error refreshing voting epoch settings: %v", err)
+	} else if changed {
+		logger.Info("Voting epoch schedule changed: %+v", c.votingEpoch.Changes())
+	}
+	if changed, err := shared.RefreshRewardEpoch(c.systemsManager, c.rewardEpoch); err != nil {
+		logger.Warn("Error refreshing reward epoch settings: %v", err)
+	} else if changed {
+		logger.Info("Reward epoch schedule changed: %+v", c.rewardEpoch.Changes())
+	}
+}
+
 func (c *ProtocolClient) waitUntilRegistered(ctx context.Context) error {
 	for {
-		currentEpoch := c.rewardEpoch.EpochIndex(time.Now())
+		currentEpoch := c.rewardEpoch.EpochIndex(c.clock.Now())
 
 		registered, err := c.isRegistered(ctx, currentEpoch)
 		if err != nil {
@@ -237,7 +263,7 @@ func (c *ProtocolClient) isRegistered(ctx context.Context, epoch int64) (bool, e
 
 func (c *ProtocolClient) waitForNextRewardEpoch(ctx context.Context, currentEpoch int64) error {
 	nextEpochStart := c.rewardEpoch.StartTime(currentEpoch + 1)
-	now := time.Now()
+	now := c.clock.Now()
 
 	// Edge case if the time passed while checking the registration means
 	// we are already in the next epoch - return immediately in that case.
@@ -255,7 +281,7 @@ func (c *ProtocolClient) waitForNextRewardEpoch(ctx context.Context, currentEpoc
 	)
 
 	select {
-	case <-time.After(sleepTime):
+	case <-c.clock.After(sleepTime):
 		return nil
 
 	case <-ctx.Done():
@@ -2,12 +2,12 @@ package protocol
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"encoding/json"
 	clientConfig "flare-tlc/client/config"
 	"flare-tlc/config"
-	"flare-tlc/logger"
+	flarelogger "flare-tlc/logger"
 	"flare-tlc/utils"
+	"flare-tlc/utils/credentials"
 	"net"
 	"net/http"
 	"net/url"
@@ -30,7 +30,7 @@ const (
 )
 
 func TestMain(m *testing.M) {
-	logger.Configure(config.LoggerConfig{
+	flarelogger.Configure(config.LoggerConfig{
 		Level:   "DEBUG",
 		Console: true,
 	})
@@ -59,27 +59,28 @@ func TestSubmitter(t *testing.T) {
 	privKey, err := crypto.HexToECDSA(testPrivateKeyHex)
 	require.NoError(t, err)
 
-	address := crypto.PubkeyToAddress(privKey.PublicKey)
+	signer := credentials.NewPrivateKeySigner(privKey)
+	address := signer.Address()
 
 	base := SubmitterBase{
 		ethClient: &ethClient,
 		gasConfig: &clientConfig.GasConfig{},
 		protocolContext: &protocolContext{
-			submitPrivateKey:           privKey,
-			signerPrivateKey:           privKey,
-			submitSignaturesPrivateKey: privKey,
-			submitContractAddress:      common.HexToAddress(submitContractAddress),
-			signingAddress:             address,
-			submitAddress:              address,
-			submitSignaturesAddress:    address,
+			submitSigner:            signer,
+			signer:                  signer,
+			submitSignaturesSigner:  signer,
+			submitContractAddress:   common.HexToAddress(submitContractAddress),
+			signingAddress:          address,
+			submitAddress:           address,
+			submitSignaturesAddress: address,
 		},
 		epoch:            &utils.Epoch{Start: time.Unix(0, 0), Period: time.Hour},
 		subProtocols:     []*SubProtocol{subProtocol},
-		submitRetries:    1,
+		txSubmitRetries:  1,
 		dataFetchRetries: 1,
 		dataFetchTimeout: 1 * time.Second,
 		name:             "test",
-		submitPrivateKey: privKey,
+		submitSigner:     signer,
 	}
 
 	t.Run("Submitter", func(t *testing.T) {
@@ -115,6 +116,49 @@ func TestSubmitter(t *testing.T) {
 		require.Empty(t, ethClient.sentTxs)
 	})
 
+	t.Run("SubmitterFallbackProvider", func(t *testing.T) {
+		defer ethClient.reset()
+
+		var failingEndpoint testAPIEndpoint
+		require.NoError(t, failingEndpoint.Listen())
+		errorStatus := http.StatusInternalServerError
+		failingEndpoint.errorStatus = &errorStatus
+		failingCtx, cancelFailing := context.WithCancel(ctx)
+		defer cancelFailing()
+		go func() { _ = failingEndpoint.Run(failingCtx) }()
+
+		fallbackBase := base
+		fallbackBase.subProtocols = []*SubProtocol{{
+			Id:          100,
+			ApiEndpoint: failingEndpoint.URL(),
+			Fallbacks:   []DataProvider{{ApiEndpoint: apiEndpointURL}},
+		}}
+		submitter := Submitter{
+			SubmitterBase: fallbackBase,
+		}
+
+		epochID := int64(1)
+		submitter.RunEpoch(epochID)
+
+		require.Len(t, ethClient.sentTxs, 1)
+	})
+
+	t.Run("SubmitterDeadlinePassed", func(t *testing.T) {
+		defer ethClient.reset()
+
+		deadlineBase := base
+		deadlineBase.deadline = time.Second
+		submitter := Submitter{
+			SubmitterBase: deadlineBase,
+		}
+
+		// epoch 1 started at unix time 3600
+		epochID := int64(1)
+		submitter.RunEpoch(epochID)
+
+		require.Empty(t, ethClient.sentTxs)
+	})
+
 	t.Run("SignatureSubmitter", func(t *testing.T) {
 		defer ethClient.reset()
 
@@ -164,18 +208,18 @@ func (c *testEthClient) reset() {
 }
 
 type sentTxInfo struct {
-	privateKey *ecdsa.PrivateKey
-	to         common.Address
-	payload    []byte
+	from    common.Address
+	to      common.Address
+	payload []byte
 }
 
 func (c *testEthClient) SendRawTx(
-	privateKey *ecdsa.PrivateKey, to common.Address, payload []byte, gasConfig *clientConfig.GasConfig,
+	signer credentials.Signer, to common.Address, payload []byte, gasConfig *clientConfig.GasConfig,
 ) error {
 	c.sentTxs = append(c.sentTxs, &sentTxInfo{
-		privateKey: privateKey,
-		to:         to,
-		payload:    payload,
+		from:    signer.Address(),
+		to:      to,
+		payload: payload,
 	})
 	return nil
 }
@@ -275,17 +319,28 @@ func TestWaitUntilRegistered(t *testing.T) {
 		expectedAddress: identityAddress,
 		registeredEpoch: 3,
 	}
+	clock := utils.NewFakeClock(time.Unix(0, 0))
 	client := ProtocolClient{
 		registry:        &registry,
-		rewardEpoch:     utils.NewEpoch(time.Now(), 100*time.Millisecond),
+		rewardEpoch:     utils.NewEpoch(clock.Now(), time.Hour),
 		identityAddress: identityAddress,
+		clock:           clock,
 	}
 
-	err := client.waitUntilRegistered(ctx)
-	require.NoError(t, err)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- client.waitUntilRegistered(ctx)
+	}()
 
-	currentEpoch := client.rewardEpoch.EpochIndex(time.Now())
-	require.GreaterOrEqual(t, currentEpoch, registry.registeredEpoch)
+	// one wait for each reward epoch before the registered one
+	for i := int64(0); i < registry.registeredEpoch; i++ {
+		clock.BlockUntilWaiters(1)
+		clock.Advance(time.Hour)
+	}
+	require.NoError(t, <-errChan)
+
+	currentEpoch := client.rewardEpoch.EpochIndex(clock.Now())
+	require.Equal(t, registry.registeredEpoch, currentEpoch)
 }
 
 func TestWaitUntilRegisteredTransientError(t *testing.T) {
@@ -301,6 +356,7 @@ func TestWaitUntilRegisteredTransientError(t *testing.T) {
 		registry:        &registry,
 		rewardEpoch:     utils.NewEpoch(time.Now(), time.Minute),
 		identityAddress: identityAddress,
+		clock:           utils.RealClock,
 	}
 
 	err := client.waitUntilRegistered(ctx)
@@ -1,12 +1,11 @@
 package protocol
 
 import (
-	"crypto/ecdsa"
-
 	"flare-tlc/client/config"
 	globalConfig "flare-tlc/config"
 	"flare-tlc/utils/chain"
 	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/credentials"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
@@ -14,14 +13,14 @@ import (
 
 // Private keys and addresses needed for protocol voter
 type protocolContext struct {
-	submitPrivateKey           *ecdsa.PrivateKey // sign tx for submit1, submit2, submit3
-	submitSignaturesPrivateKey *ecdsa.PrivateKey // submitSignatures
-	signerPrivateKey           *ecdsa.PrivateKey // sign data for submitSignatures
+	submitSigner           credentials.Signer // sign tx for submit1, submit2, submit3
+	submitSignaturesSigner credentials.Signer // submitSignatures
+	signer                 credentials.Signer // sign data for submitSignatures
 
 	submitContractAddress   common.Address
-	signingAddress          common.Address // address of signerPrivateKey
-	submitAddress           common.Address // address of submitPrivateKey
-	submitSignaturesAddress common.Address // address of submitSignaturesPrivateKey
+	signingAddress          common.Address // address of signer
+	submitAddress           common.Address // address of submitSigner
+	submitSignaturesAddress common.Address // address of submitSignaturesSigner
 }
 
 type contractSelectors struct {
@@ -31,58 +30,49 @@ type contractSelectors struct {
 	submitSignatures []byte
 }
 
-func newProtocolContext(cfg *config.ClientConfig) (*protocolContext, error) {
-	ctx := &protocolContext{}
+func newProtocolContext(cfg *config.ClientConfig, signer credentials.Signer) (*protocolContext, error) {
+	ctx := &protocolContext{signer: signer}
 
 	var err error
 
 	// Credentials
-	ctx.signerPrivateKey, err = globalConfig.PrivateKeyFromConfig(cfg.Credentials.SigningPolicyPrivateKeyFile,
-		cfg.Credentials.SigningPolicyPrivateKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "error creating signer private key")
-	}
-
-	ctx.submitPrivateKey, err = globalConfig.PrivateKeyFromConfig(cfg.Credentials.ProtocolManagerSubmitPrivateKeyFile,
-		cfg.Credentials.ProtocolManagerSubmitPrivateKey)
+	ctx.submitSigner, err = globalConfig.SignerFromConfig(&cfg.Credentials.ProtocolManagerSubmitSigner,
+		cfg.Credentials.ProtocolManagerSubmitPrivateKeyFile, cfg.Credentials.ProtocolManagerSubmitPrivateKey)
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating submit private key")
+		return nil, errors.Wrap(err, "error creating submit signer")
 	}
 
-	ctx.submitSignaturesPrivateKey, err = globalConfig.PrivateKeyFromConfig(cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKeyFile,
-		cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKey)
+	ctx.submitSignaturesSigner, err = globalConfig.SignerFromConfig(&cfg.Credentials.ProtocolManagerSubmitSignaturesSigner,
+		cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKeyFile, cfg.Credentials.ProtocolManagerSubmitSignaturesPrivateKey)
 	if err != nil {
-		return nil, errors.Wrap(err, "error reading submit signatures private key")
+		return nil, errors.Wrap(err, "error creating submit signatures signer")
 	}
 
 	// Addresses
-	ctx.signingAddress, err = chain.PrivateKeyToEthAddress(ctx.signerPrivateKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "error getting signing address")
-	}
-	ctx.submitAddress, err = chain.PrivateKeyToEthAddress(ctx.submitPrivateKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "error getting submit address")
-	}
-	ctx.submitSignaturesAddress, err = chain.PrivateKeyToEthAddress(ctx.submitSignaturesPrivateKey)
-	if err != nil {
-		return nil, errors.Wrap(err, "error getting submit signatures address")
-	}
+	ctx.signingAddress = ctx.signer.Address()
+	ctx.submitAddress = ctx.submitSigner.Address()
+	ctx.submitSignaturesAddress = ctx.submitSignaturesSigner.Address()
 	ctx.submitContractAddress = cfg.ContractAddresses.Submission
 
 	return ctx, nil
 }
 
-func newContractSelectors() contractSelectors {
-	submissionABI, err := submission.SubmissionMetaData.GetAbi()
-	if err != nil {
-		// panic, this error is fatal
-		panic(err)
-	}
-	return contractSelectors{
-		submit1:          submissionABI.Methods["submit1"].ID,
-		submit2:          submissionABI.Methods["submit2"].ID,
-		submit3:          submissionABI.Methods["submit3"].ID,
-		submitSignatures: submissionABI.Methods["submitSignatures"].ID,
+func newContractSelectors() (contractSelectors, error) {
+	var selectors contractSelectors
+	for _, selector := range []struct {
+		method string
+		id     *[]byte
+	}{
+		{"submit1", &selectors.submit1},
+		{"submit2", &selectors.submit2},
+		{"submit3", &selectors.submit3},
+		{"submitSignatures", &selectors.submitSignatures},
+	} {
+		id, err := chain.MethodIDFromMetadata(submission.SubmissionMetaData, selector.method)
+		if err != nil {
+			return contractSelectors{}, err
+		}
+		*selector.id = id
 	}
+	return selectors, nil
 }
@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"flare-tlc/client/config"
 	"flare-tlc/client/shared"
-	"flare-tlc/logger"
 	"fmt"
 	"io"
 	"math"
@@ -13,6 +12,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -22,6 +22,18 @@ type DataVerifier func(*SubProtocolResponse) error
 
 type SubProtocol struct {
 	Id          uint8
+	Type        string // config.ProtocolType*
+	ApiEndpoint string
+	XApiKey     string
+
+	// Queried in order if the primary provider fails
+	Fallbacks []DataProvider
+
+	// guards the endpoints, they are updated on config reload
+	mu sync.RWMutex
+}
+
+type DataProvider struct {
 	ApiEndpoint string
 	XApiKey     string
 }
@@ -39,15 +51,90 @@ type dataProviderResponse struct {
 }
 
 func NewSubProtocol(config config.ProtocolConfig) *SubProtocol {
-	return &SubProtocol{
-		Id:          config.Id,
-		ApiEndpoint: config.ApiEndpoint,
-		XApiKey:     config.XApiKey(),
+	sp := &SubProtocol{Id: config.Id, Type: config.Type}
+	sp.setEndpoints(config)
+	return sp
+}
+
+// Sets the primary and fallback data providers from the config
+func (sp *SubProtocol) setEndpoints(config config.ProtocolConfig) {
+	var fallbacks []DataProvider
+	for i, endpoint := range config.FallbackApiEndpoints {
+		fallbacks = append(fallbacks, DataProvider{
+			ApiEndpoint: endpoint,
+			XApiKey:     config.FallbackXApiKey(i),
+		})
 	}
+
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	sp.ApiEndpoint = config.ApiEndpoint
+	sp.XApiKey = config.XApiKey()
+	sp.Fallbacks = fallbacks
 }
 
-func (sp *SubProtocol) getData(votingRound int64, submitName string, submitAddress string, timeout time.Duration) (*SubProtocolResponse, error) {
-	url, err := getUrl(votingRound, sp, submitName, submitAddress)
+// providers returns the primary and fallback data providers in the order they are queried
+func (sp *SubProtocol) providers() []DataProvider {
+	sp.mu.RLock()
+	defer sp.mu.RUnlock()
+
+	return append([]DataProvider{{ApiEndpoint: sp.ApiEndpoint, XApiKey: sp.XApiKey}}, sp.Fallbacks...)
+}
+
+// getData queries the data providers in order until one returns valid data.
+func (sp *SubProtocol) getData(
+	votingRound int64, submitName string, submitAddress string, timeout time.Duration, dataVerifier DataVerifier,
+) (*SubProtocolResponse, error) {
+	var noData *SubProtocolResponse
+	var err error
+	providers := sp.providers()
+	for _, provider := range providers {
+		var data *SubProtocolResponse
+		data, err = sp.getProviderData(provider, votingRound, submitName, submitAddress, timeout)
+		if err == nil {
+			err = dataVerifier(data)
+		}
+		if err == nil && data.Status == "OK" {
+			return data, nil
+		}
+		if err == nil {
+			// the next provider may have data for the round
+			noData = data
+			err = fmt.Errorf("status %s", data.Status)
+		}
+		if len(providers) > 1 {
+			logger.Warn("Data provider %s for protocol %d failed for voting round %d: %v",
+				provider.ApiEndpoint, sp.Id, votingRound, err)
+		}
+	}
+	if noData != nil {
+		return noData, nil
+	}
+	return nil, err
+}
+
+func (sp *SubProtocol) getProviderData(
+	provider DataProvider, votingRound int64, submitName string, submitAddress string, timeout time.Duration,
+) (result *SubProtocolResponse, err error) {
+	start := time.Now()
+	defer func() {
+		label := "ok"
+		if err != nil {
+			label = "error"
+		} else if result.Status != "OK" {
+			label = "no_data"
+		}
+		dataProviderRequestDuration.WithLabelValues(
+			strconv.Itoa(int(sp.Id)), providerLabel(provider.ApiEndpoint), label,
+		).Observe(time.Since(start).Seconds())
+	}()
+
+	if sp.Type == config.ProtocolTypeFDC {
+		return sp.getFdcData(provider, votingRound, submitName, submitAddress, timeout)
+	}
+
+	url, err := getUrl(votingRound, provider.ApiEndpoint, submitName, submitAddress)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting url")
 	}
@@ -60,8 +147,8 @@ func (sp *SubProtocol) getData(votingRound int64, submitName string, submitAddre
 	if err != nil {
 		return nil, errors.Wrap(err, "error creating protocol client API request")
 	}
-	if len(sp.XApiKey) > 0 {
-		req.Header.Set("X-API-KEY", sp.XApiKey)
+	if len(provider.XApiKey) > 0 {
+		req.Header.Set("X-API-KEY", provider.XApiKey)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -118,13 +205,10 @@ func (sp *SubProtocol) getDataWithRetry(
 	dataVerifier DataVerifier,
 ) <-chan shared.ExecuteStatus[*SubProtocolResponse] {
 	return shared.ExecuteWithRetry(func() (*SubProtocolResponse, error) {
-		data, err := sp.getData(votingRound, endpoint, submitAddress, timeout)
-		if err == nil {
-			err = dataVerifier(data)
-		}
+		data, err := sp.getData(votingRound, endpoint, submitAddress, timeout, dataVerifier)
 		if err != nil {
 			logger.Error("Error getting data from protocol client with id %d, endpoint %s, voting round %d: %v",
-				sp.Id, sp.ApiEndpoint, votingRound, err)
+				sp.Id, sp.providers()[0].ApiEndpoint, votingRound, err)
 			return nil, err
 		}
 		return data, nil
@@ -150,9 +234,18 @@ func IdentityDataVerifier(data *SubProtocolResponse) error {
 	return nil
 }
 
-func getUrl(votingRound int64, protocol *SubProtocol, endpoint string, signingAddress string) (*url.URL, error) {
+// providerLabel returns the host of the endpoint, so that metrics do not include paths or secrets
+func providerLabel(apiEndpoint string) string {
+	u, err := url.Parse(apiEndpoint)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
+
+func getUrl(votingRound int64, apiEndpoint string, endpoint string, signingAddress string) (*url.URL, error) {
 	baseURL, err := url.JoinPath(
-		protocol.ApiEndpoint,
+		apiEndpoint,
 		endpoint,
 		strconv.FormatInt(votingRound, 10),
 		signingAddress,
@@ -2,18 +2,16 @@ package protocol
 
 import (
 	"bytes"
-	"crypto/ecdsa"
 	"flare-tlc/client/config"
 	"flare-tlc/client/shared"
-	"flare-tlc/logger"
 	"flare-tlc/utils"
 	"flare-tlc/utils/chain"
+	"flare-tlc/utils/credentials"
 	"fmt"
 	"time"
 
 	mapset "github.com/deckarep/golang-set/v2"
 
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -30,25 +28,27 @@ type SubmitterBase struct {
 	selector     []byte
 	subProtocols []*SubProtocol
 
-	startOffset      time.Duration
-	submitRetries    int    // number of retries for submitting tx
-	name             string // e.g., "submit1", "submit2", "submit3", "signatureSubmitter"
-	submitPrivateKey *ecdsa.PrivateKey
+	startOffset     time.Duration
+	deadline        time.Duration // offset from the start of the epoch after which no tx is sent, 0 for no deadline
+	retryCfg        *config.RetryConfig
+	txSubmitRetries int    // number of retries for submitting tx
+	name            string // e.g., "submit1", "submit2", "submit3", "signatureSubmitter"
+	submitSigner    credentials.Signer
 
 	dataFetchRetries int           // number of retries for fetching data of each provider
 	dataFetchTimeout time.Duration // timeout for fetching data of each provider
 }
 
 type submitterEthClient interface {
-	SendRawTx(*ecdsa.PrivateKey, common.Address, []byte, *config.GasConfig) error
+	SendRawTx(credentials.Signer, common.Address, []byte, *config.GasConfig) error
 }
 
 type submitterEthClientImpl struct {
 	ethClient *ethclient.Client
 }
 
-func (c submitterEthClientImpl) SendRawTx(privateKey *ecdsa.PrivateKey, to common.Address, payload []byte, gasConfig *config.GasConfig) error {
-	return chain.SendRawTx(c.ethClient, privateKey, to, payload, true, gasConfig)
+func (c submitterEthClientImpl) SendRawTx(signer credentials.Signer, to common.Address, payload []byte, gasConfig *config.GasConfig) error {
+	return chain.SendRawTx(c.ethClient, signer, to, payload, true, gasConfig, config.RetryOpSubmit)
 }
 
 type Submitter struct {
@@ -63,26 +63,54 @@ type SignatureSubmitter struct {
 	maxRounds int // number of rounds for sending submitSignatures tx
 }
 
-func (s *SubmitterBase) submit(payload []byte) bool {
-	sendResult := <-shared.ExecuteWithRetry(func() (any, error) {
-		err := s.ethClient.SendRawTx(s.submitPrivateKey, s.protocolContext.submitContractAddress, payload, s.gasConfig)
+// deadlineTime returns the time after which no tx is sent for the epoch, zero time if there is no deadline
+func (s *SubmitterBase) deadlineTime(currentEpoch int64) time.Time {
+	if s.deadline == 0 {
+		return time.Time{}
+	}
+	return s.epoch.StartTime(currentEpoch).Add(s.deadline)
+}
+
+func pastDeadline(deadline time.Time) bool {
+	return !deadline.IsZero() && time.Now().After(deadline)
+}
+
+func (s *SubmitterBase) submit(payload []byte, deadline time.Time) bool {
+	if chain.Standby() {
+		logger.Debug("Submitter %s on standby, the leader instance sends the tx", s.name)
+		return false
+	}
+	sendResult := <-shared.ExecuteWithRetryPolicy(func() (any, error) {
+		if pastDeadline(deadline) {
+			return nil, shared.Fatal(errors.Errorf("submitter %s deadline %v passed", s.name, deadline))
+		}
+		err := s.ethClient.SendRawTx(s.submitSigner, s.protocolContext.submitContractAddress, payload, s.gasConfig)
 		if err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("error sending submit tx for submitter %s tx", s.name))
 		}
 		return nil, nil
-	}, s.submitRetries, shared.TxRetryInterval)
+	}, submitRetryPolicy(s.retryCfg, s.txSubmitRetries))
 	if sendResult.Success {
 		logger.Info("Submitter %s successfully sent tx", s.name)
 	}
 	return sendResult.Success
 }
 
+// Submit transactions are retried tx_submit_retries times (at least once), the
+// delays and error classification are taken from the submit retry policy.
+func submitRetryPolicy(retryCfg *config.RetryConfig, txSubmitRetries int) config.RetryPolicy {
+	policy := retryCfg.Policy(config.RetryOpSubmit)
+	policy.MaxRetries = max(1, txSubmitRetries)
+	return policy
+}
+
 func newSubmitter(
 	ethClient *ethclient.Client,
 	pc *protocolContext,
 	epoch *utils.Epoch,
 	submitCfg *config.SubmitConfig,
 	gasCfg *config.GasConfig,
+	retryCfg *config.RetryConfig,
 	selector []byte,
 	subProtocols []*SubProtocol,
 	epochOffset int64,
@@ -97,9 +125,11 @@ func newSubmitter(
 			selector:         selector,
 			subProtocols:     subProtocols,
 			startOffset:      submitCfg.StartOffset,
-			submitRetries:    max(1, submitCfg.TxSubmitRetries),
+			deadline:         submitCfg.Deadline,
+			retryCfg:         retryCfg,
+			txSubmitRetries:  submitCfg.TxSubmitRetries,
 			name:             name,
-			submitPrivateKey: pc.submitPrivateKey,
+			submitSigner:     pc.submitSigner,
 			dataFetchRetries: submitCfg.DataFetchRetries,
 			dataFetchTimeout: submitCfg.DataFetchTimeout,
 		},
@@ -110,6 +140,9 @@ func newSubmitter(
 func (s *Submitter) GetPayload(currentEpoch int64) ([]byte, error) {
 	channels := make([]<-chan shared.ExecuteStatus[*SubProtocolResponse], len(s.subProtocols))
 	for i, protocol := range s.subProtocols {
+		if !protocol.submits(s.name) {
+			continue
+		}
 		channels[i] = protocol.getDataWithRetry(
 			currentEpoch+s.epochOffset,
 			s.name,
@@ -125,6 +158,9 @@ func (s *Submitter) GetPayload(currentEpoch int64) ([]byte, error) {
 
 	dataReceived := false
 	for _, channel := range channels {
+		if channel == nil {
+			continue
+		}
 		data := <-channel
 		if !data.Success || data.Value.Status != "OK" {
 			logger.Error("Error getting data for submitter %s: %s", s.name, data.Message)
@@ -151,7 +187,7 @@ func (s *Submitter) RunEpoch(currentEpoch int64) {
 		return
 	}
 	if payload != nil {
-		s.submit(payload)
+		s.submit(payload, s.deadlineTime(currentEpoch))
 	} else {
 		logger.Info("Submitter %s did not get any data, skipping submission", s.name)
 	}
@@ -163,6 +199,7 @@ func newSignatureSubmitter(
 	epoch *utils.Epoch,
 	submitCfg *config.SubmitSignaturesConfig,
 	gasCfg *config.GasConfig,
+	retryCfg *config.RetryConfig,
 	selector []byte,
 	subProtocols []*SubProtocol,
 ) *SignatureSubmitter {
@@ -173,11 +210,13 @@ func newSignatureSubmitter(
 			protocolContext:  pc,
 			epoch:            epoch,
 			startOffset:      submitCfg.StartOffset,
+			deadline:         submitCfg.Deadline,
 			selector:         selector,
 			subProtocols:     subProtocols,
-			submitRetries:    max(1, submitCfg.TxSubmitRetries),
+			retryCfg:         retryCfg,
+			txSubmitRetries:  submitCfg.TxSubmitRetries,
 			name:             "submitSignatures",
-			submitPrivateKey: pc.submitSignaturesPrivateKey,
+			submitSigner:     pc.submitSignaturesSigner,
 			dataFetchTimeout: submitCfg.DataFetchTimeout,
 			dataFetchRetries: submitCfg.DataFetchRetries,
 		},
@@ -189,8 +228,7 @@ func newSignatureSubmitter(
 func (s *SignatureSubmitter) WritePayload(
 	buffer *bytes.Buffer, currentEpoch int64, data *SubProtocolResponse, protocolID uint8,
 ) error {
-	dataHash := accounts.TextHash(crypto.Keccak256(data.Data))
-	signature, err := crypto.Sign(dataHash, s.protocolContext.signerPrivateKey)
+	signature, err := s.protocolContext.signer.SignText(crypto.Keccak256(data.Data))
 	if err != nil {
 		return errors.Wrap(err, "error signing submitSignatures data")
 	}
@@ -223,8 +261,14 @@ func (s *SignatureSubmitter) RunEpoch(currentEpoch int64) {
 	for i := range s.subProtocols {
 		protocolsToSend.Add(i)
 	}
+	deadline := s.deadlineTime(currentEpoch)
 	channels := make([]<-chan shared.ExecuteStatus[*SubProtocolResponse], len(s.subProtocols))
 	for i := 0; i < s.maxRounds && protocolsToSend.Cardinality() > 0; i++ {
+		if pastDeadline(deadline) {
+			logger.Warn("Submitter %s deadline passed for epoch %d, %d protocols not sent", s.name, currentEpoch, protocolsToSend.Cardinality())
+			return
+		}
+
 		for i, protocol := range s.subProtocols {
 			if !protocolsToSend.Contains(i) {
 				continue
@@ -261,7 +305,7 @@ func (s *SignatureSubmitter) RunEpoch(currentEpoch int64) {
 			protocolsToSend.Remove(i)
 		}
 		if protocolsToSendCopy.Cardinality() > protocolsToSend.Cardinality() {
-			if !s.submit(buffer.Bytes()) {
+			if !s.submit(buffer.Bytes(), deadline) {
 				protocolsToSend = protocolsToSendCopy
 			}
 		} else {
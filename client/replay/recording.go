@@ -0,0 +1,162 @@
+package replay
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flare-tlc/client/simulation"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/contracts/system"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Events of the Relay and FlareSystemsManager contracts included in a recording
+var (
+	relayEvents          = []string{"SigningPolicyInitialized", "ProtocolMessageRelayed"}
+	systemsManagerEvents = []string{
+		"VotePowerBlockSelected", "SigningPolicySigned", "SignUptimeVoteEnabled", "UptimeVoteSigned", "RewardsSigned",
+	}
+)
+
+// Indexer logs and submitSignatures txs of the time range (From, To], with the contract
+// addresses and the epoch settings, so that the range is replayed without a chain
+type Recording struct {
+	From      int64         `json:"from"`
+	To        int64         `json:"to"`
+	Contracts Contracts     `json:"contracts"`
+	Epochs    EpochSettings `json:"epochs"`
+
+	simulation.Fixtures
+}
+
+type Contracts struct {
+	Relay          common.Address `json:"relay"`
+	Submission     common.Address `json:"submission"`
+	SystemsManager common.Address `json:"systemsManager"`
+}
+
+type contractEvents struct {
+	address  common.Address
+	metaData *bind.MetaData
+	names    []string
+}
+
+func (c Contracts) events() []contractEvents {
+	return []contractEvents{
+		{c.Relay, relay.RelayMetaData, relayEvents},
+		{c.SystemsManager, system.FlareSystemsManagerMetaData, systemsManagerEvents},
+	}
+}
+
+// Epoch settings of the Relay contract
+type EpochSettings struct {
+	FirstVotingRoundStartTs            int64 `json:"firstVotingRoundStartTs"`
+	VotingEpochDurationSeconds         int64 `json:"votingEpochDurationSeconds"`
+	FirstRewardEpochStartVotingRoundId int64 `json:"firstRewardEpochStartVotingRoundId"`
+	RewardEpochDurationInVotingEpochs  int64 `json:"rewardEpochDurationInVotingEpochs"`
+}
+
+func EpochSettingsFromChain(relayContract *relay.Relay) (EpochSettings, error) {
+	sd, err := relayContract.StateData(nil)
+	if err != nil {
+		return EpochSettings{}, errors.Wrap(err, "error fetching relay state data")
+	}
+	return EpochSettings{
+		FirstVotingRoundStartTs:            int64(sd.FirstVotingRoundStartTs),
+		VotingEpochDurationSeconds:         int64(sd.VotingEpochDurationSeconds),
+		FirstRewardEpochStartVotingRoundId: int64(sd.FirstRewardEpochStartVotingRoundId),
+		RewardEpochDurationInVotingEpochs:  int64(sd.RewardEpochDurationInVotingEpochs),
+	}, nil
+}
+
+func (s EpochSettings) VotingEpoch() *utils.Epoch {
+	return utils.NewEpoch(time.Unix(s.FirstVotingRoundStartTs, 0), time.Duration(s.VotingEpochDurationSeconds)*time.Second)
+}
+
+func (s EpochSettings) RewardEpoch() *utils.IntEpoch {
+	return utils.NewIntEpoch(s.FirstRewardEpochStartVotingRoundId, s.RewardEpochDurationInVotingEpochs)
+}
+
+// Export reads the logs of the Relay and FlareSystemsManager contracts and the
+// submitSignatures txs of the Submission contract in the range (from, to] from the
+// indexer database
+func Export(db *gorm.DB, contracts Contracts, epochs EpochSettings, from, to time.Time, opts database.FetchOptions) (*Recording, error) {
+	recording := &Recording{
+		From:      from.Unix(),
+		To:        to.Unix(),
+		Contracts: contracts,
+		Epochs:    epochs,
+	}
+
+	for _, events := range contracts.events() {
+		for _, name := range events.names {
+			topic0, err := chain.EventIDFromMetadata(events.metaData, name)
+			if err != nil {
+				return nil, err
+			}
+			logs, err := database.FetchAll(recording.From, recording.To, func(from, to int64) ([]database.Log, error) {
+				return database.FetchLogsByAddressAndTopic0(db, events.address.Hex(), topic0, from, to, opts)
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "error fetching %s logs", name)
+			}
+			for i := range logs {
+				logs[i].Transaction = database.Transaction{}
+			}
+			recording.Logs = append(recording.Logs, logs...)
+		}
+	}
+	sort.SliceStable(recording.Logs, func(i, j int) bool {
+		return recording.Logs[i].Timestamp < recording.Logs[j].Timestamp
+	})
+
+	submissionABI, err := submission.SubmissionMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	selector := hex.EncodeToString(submissionABI.Methods["submitSignatures"].ID)
+	txs, err := database.FetchAll(recording.From, recording.To, func(from, to int64) ([]database.Transaction, error) {
+		return database.FetchTransactionsByAddressAndSelector(db, contracts.Submission.Hex(), selector, from, to, opts)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching submitSignatures txs")
+	}
+	recording.Transactions = txs
+	return recording, nil
+}
+
+func (r *Recording) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(os.WriteFile(path, data, 0o644), "error writing recording")
+}
+
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading recording")
+	}
+	var recording Recording
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, errors.Wrapf(err, "error parsing recording %s", path)
+	}
+	if recording.To <= recording.From {
+		return nil, errors.Errorf("invalid recording range (%d, %d]", recording.From, recording.To)
+	}
+	if recording.Epochs.VotingEpochDurationSeconds <= 0 || recording.Epochs.RewardEpochDurationInVotingEpochs <= 0 {
+		return nil, errors.New("recording has no epoch settings")
+	}
+	return &recording, nil
+}
@@ -0,0 +1,296 @@
+package replay
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/client/finalizer"
+	"flare-tlc/client/shared"
+	"flare-tlc/client/simulation"
+	"flare-tlc/database"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/epoch"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Actions of the system client reported by a replay
+const (
+	ActionRegisterVoter  = "register_voter"   // on VotePowerBlockSelected
+	ActionSignPolicy     = "sign_policy"      // on SigningPolicyInitialized
+	ActionSignUptimeVote = "sign_uptime_vote" // on SignUptimeVoteEnabled
+	ActionSignRewards    = "sign_rewards"     // on UptimeVoteSigned with the threshold reached
+	ActionSkipped        = "skipped"          // event for a reward epoch that is not in the future
+	ActionSignedOnChain  = "signed_on_chain"  // signed event of the replayed address in the recording
+)
+
+// Action the system client would have taken on an event of the recording
+type VotingAction struct {
+	Time          time.Time `json:"time"`
+	RewardEpochId int64     `json:"rewardEpochId"`
+	Action        string    `json:"action"`
+	Detail        string    `json:"detail,omitempty"`
+}
+
+func (a VotingAction) String() string {
+	s := fmt.Sprintf("%s %-17s reward epoch %d", a.Time.UTC().Format(time.RFC3339), a.Action, a.RewardEpochId)
+	if len(a.Detail) > 0 {
+		s += ": " + a.Detail
+	}
+	return s
+}
+
+// Actions of the enabled clients, in the order they would have been taken
+type Report struct {
+	Voting    []VotingAction           `json:"voting"`
+	Finalizer []finalizer.ReplayAction `json:"finalizer"`
+}
+
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "System client: %d actions\n", len(r.Voting))
+	for _, action := range r.Voting {
+		fmt.Fprintf(w, "  %v\n", action)
+	}
+	fmt.Fprintf(w, "Finalizer: %d actions\n", len(r.Finalizer))
+	for _, action := range r.Finalizer {
+		fmt.Fprintf(w, "  %v\n", action)
+	}
+}
+
+// Run replays the recording as the client with the signing policy address and the
+// settings of the config. The contract addresses of the recording are used. Nothing
+// is sent or signed, the finalizer runs on an in-memory copy of the recording, see
+// finalizer.Replay, and the events of the system client are mapped to the actions
+// the client takes on them. Uptime vote and rewards hashes are not fetched.
+func Run(ctx context.Context, cfg *config.ClientConfig, recording *Recording, address common.Address) (*Report, error) {
+	replayCfg := *cfg
+	replayCfg.ContractAddresses.Relay = recording.Contracts.Relay
+	replayCfg.ContractAddresses.Submission = recording.Contracts.Submission
+	replayCfg.ContractAddresses.SystemsManager = recording.Contracts.SystemsManager
+
+	report := &Report{}
+	if cfg.Clients.EpochClientEnabled() {
+		actions, err := replayVoting(&replayCfg, recording, address)
+		if err != nil {
+			return nil, err
+		}
+		report.Voting = actions
+	}
+	if cfg.Clients.EnabledFinalizer {
+		db, err := simulation.NewMemoryDB()
+		if err != nil {
+			return nil, err
+		}
+		if err := simulation.LoadFixtures(db, &recording.Fixtures); err != nil {
+			return nil, err
+		}
+		actions, err := finalizer.Replay(ctx, &replayCfg, finalizer.Simulation{
+			DB:          finalizer.NewFinalizerDB(db, database.FetchOptions{}),
+			EthClient:   simulation.NewRelayEthClient(),
+			Signer:      addressSigner{address: address},
+			VotingEpoch: recording.Epochs.VotingEpoch(),
+			RewardEpoch: recording.Epochs.RewardEpoch(),
+		}, time.Unix(recording.From, 0), time.Unix(recording.To, 0))
+		if err != nil {
+			return nil, err
+		}
+		report.Finalizer = actions
+	}
+	return report, nil
+}
+
+type eventKey struct {
+	address common.Address
+	topic0  common.Hash
+}
+
+type votingReplay struct {
+	cfg     *config.ClientConfig
+	address common.Address
+	epochs  *epoch.Schedule
+
+	relay          *relay.Relay
+	systemsManager *system.FlareSystemsManager
+	events         map[eventKey]string
+
+	// actions already taken, each action is taken once per reward epoch
+	taken   map[string]bool
+	actions []VotingAction
+}
+
+func replayVoting(cfg *config.ClientConfig, recording *Recording, address common.Address) ([]VotingAction, error) {
+	relayContract, err := relay.NewRelay(recording.Contracts.Relay, nil)
+	if err != nil {
+		return nil, err
+	}
+	systemsManager, err := system.NewFlareSystemsManager(recording.Contracts.SystemsManager, nil)
+	if err != nil {
+		return nil, err
+	}
+	r := &votingReplay{
+		cfg:            cfg,
+		address:        address,
+		epochs:         epoch.NewSchedule(recording.Epochs.VotingEpoch(), recording.Epochs.RewardEpoch()),
+		relay:          relayContract,
+		systemsManager: systemsManager,
+		events:         make(map[eventKey]string),
+		taken:          make(map[string]bool),
+	}
+	for _, contract := range recording.Contracts.events() {
+		for _, name := range contract.names {
+			topic0, err := chain.EventIDFromMetadata(contract.metaData, name)
+			if err != nil {
+				return nil, err
+			}
+			r.events[eventKey{address: contract.address, topic0: common.HexToHash(topic0)}] = name
+		}
+	}
+
+	logs := append([]database.Log(nil), recording.Logs...)
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].Timestamp < logs[j].Timestamp
+	})
+	for _, log := range logs {
+		if err := r.apply(log); err != nil {
+			return nil, errors.Wrapf(err, "error replaying log %s", log.TransactionHash)
+		}
+	}
+	return r.actions, nil
+}
+
+func (r *votingReplay) apply(dbLog database.Log) error {
+	chainLog, err := shared.ConvertDatabaseLogToChainLog(dbLog)
+	if err != nil {
+		return err
+	}
+	if len(chainLog.Topics) == 0 {
+		return nil
+	}
+	at := time.Unix(int64(dbLog.Timestamp), 0)
+	enabled := &r.cfg.Clients
+	systemsManager := &r.systemsManager.FlareSystemsManagerFilterer
+
+	switch r.events[eventKey{address: common.HexToAddress(dbLog.Address), topic0: chainLog.Topics[0]}] {
+	case "SigningPolicyInitialized":
+		event, err := r.relay.ParseSigningPolicyInitialized(*chainLog)
+		if err != nil {
+			return err
+		}
+		// the reward epoch starts with the voting round of the policy, possibly later than expected
+		r.epochs.SetFirstVotingRound(event.RewardEpochId.Int64(), int64(event.StartVotingRoundId))
+		if !enabled.EnabledRegistration {
+			return nil
+		}
+		detail := fmt.Sprintf("%d voters, threshold %d", len(event.Voters), event.Threshold)
+		if !containsAddress(event.Voters, r.address) {
+			detail += fmt.Sprintf(", %s is not a voter", r.address.Hex())
+		}
+		r.takeFuture(at, event.RewardEpochId, ActionSignPolicy, detail)
+
+	case "VotePowerBlockSelected":
+		event, err := systemsManager.ParseVotePowerBlockSelected(*chainLog)
+		if err != nil {
+			return err
+		}
+		if enabled.EnabledRegistration {
+			r.takeFuture(at, event.RewardEpochId, ActionRegisterVoter, fmt.Sprintf(
+				"identity %s, vote power block %d", r.cfg.Identity.Address.Hex(), event.VotePowerBlock))
+		}
+
+	case "SignUptimeVoteEnabled":
+		event, err := systemsManager.ParseSignUptimeVoteEnabled(*chainLog)
+		if err != nil {
+			return err
+		}
+		if enabled.EnabledUptimeVoting {
+			r.take(at, event.RewardEpochId.Int64(), ActionSignUptimeVote, "")
+		}
+
+	case "UptimeVoteSigned":
+		event, err := systemsManager.ParseUptimeVoteSigned(*chainLog)
+		if err != nil {
+			return err
+		}
+		if event.SigningPolicyAddress == r.address {
+			r.record(at, event.RewardEpochId.Int64(), ActionSignedOnChain, "uptime vote")
+		}
+		if enabled.EnabledRewardSigning && event.ThresholdReached {
+			r.take(at, event.RewardEpochId.Int64(), ActionSignRewards, "")
+		}
+
+	case "SigningPolicySigned":
+		event, err := systemsManager.ParseSigningPolicySigned(*chainLog)
+		if err != nil {
+			return err
+		}
+		if event.SigningPolicyAddress == r.address {
+			r.record(at, event.RewardEpochId.Int64(), ActionSignedOnChain, "signing policy")
+		}
+
+	case "RewardsSigned":
+		event, err := systemsManager.ParseRewardsSigned(*chainLog)
+		if err != nil {
+			return err
+		}
+		if event.SigningPolicyAddress == r.address {
+			r.record(at, event.RewardEpochId.Int64(), ActionSignedOnChain, "rewards")
+		}
+	}
+	return nil
+}
+
+// Registration and signing policy signing are only done for the next reward epoch,
+// the current reward epoch is derived from the epoch settings
+func (r *votingReplay) takeFuture(at time.Time, rewardEpochId *big.Int, action string, detail string) {
+	current := r.epochs.RewardEpochAt(at)
+	if rewardEpochId.Int64() <= current {
+		r.record(at, rewardEpochId.Int64(), ActionSkipped, fmt.Sprintf("%s, current reward epoch is %d", action, current))
+		return
+	}
+	r.take(at, rewardEpochId.Int64(), action, detail)
+}
+
+func (r *votingReplay) take(at time.Time, rewardEpochId int64, action string, detail string) {
+	key := fmt.Sprintf("%s/%d", action, rewardEpochId)
+	if r.taken[key] {
+		return
+	}
+	r.taken[key] = true
+	r.record(at, rewardEpochId, action, detail)
+}
+
+func (r *votingReplay) record(at time.Time, rewardEpochId int64, action string, detail string) {
+	r.actions = append(r.actions, VotingAction{Time: at, RewardEpochId: rewardEpochId, Action: action, Detail: detail})
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+// Signer of the replayed address, relay txs are not signed in a replay
+type addressSigner struct {
+	address common.Address
+}
+
+var errReplaySigning = errors.New("signing is not available in a replay")
+
+func (s addressSigner) Address() common.Address { return s.address }
+
+func (s addressSigner) SignText([]byte) ([]byte, error) { return nil, errReplaySigning }
+
+func (s addressSigner) SignTx(*types.Transaction, *big.Int) (*types.Transaction, error) {
+	return nil, errReplaySigning
+}
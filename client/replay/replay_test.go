@@ -0,0 +1,206 @@
+package replay
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/client/finalizer"
+	"flare-tlc/client/simulation"
+	"flare-tlc/database"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testContracts = Contracts{
+		Relay:          common.HexToAddress("0xb849b93B585eFfb7cE4B522Ff88d9b3B24955f24"),
+		Submission:     common.HexToAddress("0x2F79Dce2375571207a7976148D4468195F89a73e"),
+		SystemsManager: common.HexToAddress("0x919b4b4B561C72c990DC868F751328eF127c45F4"),
+	}
+	testEpochs = EpochSettings{
+		FirstVotingRoundStartTs:            0,
+		VotingEpochDurationSeconds:         3600,
+		FirstRewardEpochStartVotingRoundId: 0,
+		RewardEpochDurationInVotingEpochs:  100,
+	}
+)
+
+func TestRunFinalizer(t *testing.T) {
+	voter, other := newTestSigner(t), newTestSigner(t)
+
+	// both voters sign round 1, only the replayed voter signs round 2
+	policy := &relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      big.NewInt(0),
+		StartVotingRoundId: 0,
+		Threshold:          1,
+		Seed:               big.NewInt(1),
+		Voters:             []common.Address{voter.Address(), other.Address()},
+		Weights:            []uint16{1, 1},
+		Timestamp:          100,
+	}
+	spiLog, err := simulation.SigningPolicyInitializedLog(testContracts.Relay, policy)
+	require.NoError(t, err)
+	recording := &Recording{From: 0, To: 10800, Contracts: testContracts, Epochs: testEpochs}
+	recording.Logs = []database.Log{*spiLog}
+	for i, submission := range []struct {
+		signer    credentials.Signer
+		round     uint32
+		timestamp uint64
+	}{
+		{voter, 1, 3700},
+		{other, 1, 3710},
+		{voter, 2, 7300},
+	} {
+		tx, err := simulation.SubmitSignaturesTx(testContracts.Submission, submission.signer.Address(), submission.signer,
+			submission.timestamp, simulation.SignedMessage{ProtocolId: 100, VotingRoundId: submission.round, MerkleRoot: common.Hash{1}})
+		require.NoError(t, err)
+		tx.ID = uint64(i + 1)
+		recording.Transactions = append(recording.Transactions, *tx)
+	}
+
+	cfg := &config.ClientConfig{}
+	cfg.Clients.EnabledFinalizer = true
+	cfg.Finalizer.StartOffset = 7 * 24 * time.Hour
+	cfg.Finalizer.VoterThresholdBIPS = 5000
+	cfg.Finalizer.GracePeriodEndOffset = 20 * time.Second
+
+	report, err := Run(context.Background(), cfg, recording, voter.Address())
+	require.NoError(t, err)
+	require.Empty(t, report.Voting)
+
+	actions := report.Finalizer
+	require.Equal(t, finalizer.ReplaySigningPolicy, actions[0].Action)
+	require.Equal(t, finalizer.ReplayThresholdReached, actions[1].Action)
+	require.Equal(t, uint32(1), actions[1].VotingRoundId)
+	require.Equal(t, time.Unix(3710, 0), actions[1].Time)
+
+	// selected or backup finalizer, depending on the voter selection
+	relays := 0
+	for _, action := range actions {
+		if action.Action == finalizer.ReplayRelay || action.Action == finalizer.ReplayBackupRelay {
+			require.Equal(t, uint32(1), action.VotingRoundId)
+			require.Equal(t, byte(100), action.ProtocolId)
+			relays++
+		}
+	}
+	require.Equal(t, 1, relays)
+
+	last := actions[len(actions)-1]
+	require.Equal(t, finalizer.ReplayNotFinalized, last.Action)
+	require.Equal(t, uint32(2), last.VotingRoundId)
+	require.Equal(t, time.Unix(10800, 0), last.Time)
+
+	again, err := Run(context.Background(), cfg, recording, voter.Address())
+	require.NoError(t, err)
+	require.Equal(t, report, again)
+}
+
+func TestRunVoting(t *testing.T) {
+	signer := newTestSigner(t)
+	systemsManager := system.FlareSystemsManagerMetaData
+
+	// events of reward epoch 2, emitted in reward epoch 1
+	start := uint64(150 * 3600)
+	spiLog, err := simulation.SigningPolicyInitializedLog(testContracts.Relay, &relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      big.NewInt(2),
+		StartVotingRoundId: 200,
+		Threshold:          1,
+		Seed:               big.NewInt(1),
+		Voters:             []common.Address{signer.Address()},
+		Weights:            []uint16{2},
+		Timestamp:          start + 20,
+	})
+	require.NoError(t, err)
+	recording := &Recording{From: 0, To: int64(start) + 3600, Contracts: testContracts, Epochs: testEpochs}
+	recording.Logs = []database.Log{
+		eventLog(t, testContracts.SystemsManager, systemsManager, "VotePowerBlockSelected", start+10, big.NewInt(2), uint64(1000), start+10),
+		// uptime vote of the previous reward epoch
+		eventLog(t, testContracts.SystemsManager, systemsManager, "SignUptimeVoteEnabled", start+15, big.NewInt(0), start+15),
+		*spiLog,
+		eventLog(t, testContracts.SystemsManager, systemsManager, "SigningPolicySigned", start+30,
+			big.NewInt(2), signer.Address(), signer.Address(), start+30, true),
+		// registration of the current reward epoch is skipped
+		eventLog(t, testContracts.SystemsManager, systemsManager, "VotePowerBlockSelected", start+40, big.NewInt(1), uint64(900), start+40),
+	}
+
+	cfg := &config.ClientConfig{}
+	cfg.Clients.EnabledRegistration = true
+
+	report, err := Run(context.Background(), cfg, recording, signer.Address())
+	require.NoError(t, err)
+	require.Empty(t, report.Finalizer)
+
+	var actions []string
+	for _, action := range report.Voting {
+		actions = append(actions, action.Action)
+	}
+	require.Equal(t, []string{ActionRegisterVoter, ActionSignPolicy, ActionSignedOnChain, ActionSkipped}, actions)
+	require.Equal(t, int64(2), report.Voting[1].RewardEpochId)
+	require.Equal(t, "1 voters, threshold 1", report.Voting[1].Detail)
+}
+
+func TestRecordingSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recording.json")
+	recording := &Recording{From: 10, To: 20, Contracts: testContracts, Epochs: testEpochs}
+	recording.Logs = []database.Log{{Address: "abcd", Topic0: "01", Timestamp: 15}}
+	require.NoError(t, recording.Save(path))
+
+	loaded, err := LoadRecording(path)
+	require.NoError(t, err)
+	require.Equal(t, recording, loaded)
+
+	recording.Epochs = EpochSettings{}
+	require.NoError(t, recording.Save(path))
+	_, err = LoadRecording(path)
+	require.ErrorContains(t, err, "no epoch settings")
+}
+
+func newTestSigner(t *testing.T) credentials.Signer {
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return credentials.NewPrivateKeySigner(privateKey)
+}
+
+// Encodes the event with the values of its inputs as an indexer log
+func eventLog(t *testing.T, address common.Address, metaData *bind.MetaData, name string, timestamp uint64, values ...interface{}) database.Log {
+	contractABI, err := metaData.GetAbi()
+	require.NoError(t, err)
+	event := contractABI.Events[name]
+	require.Len(t, values, len(event.Inputs))
+
+	topics := []string{event.ID.Hex(), "NULL", "NULL", "NULL"}
+	var nonIndexed []interface{}
+	n := 1
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			nonIndexed = append(nonIndexed, values[i])
+			continue
+		}
+		topic, err := abi.MakeTopics([]interface{}{values[i]})
+		require.NoError(t, err)
+		topics[n] = topic[0][0].Hex()
+		n++
+	}
+	data, err := event.Inputs.NonIndexed().Pack(nonIndexed...)
+	require.NoError(t, err)
+	return database.Log{
+		Address:         address.Hex(),
+		Data:            common.Bytes2Hex(data),
+		Topic0:          topics[0],
+		Topic1:          topics[1],
+		Topic2:          topics[2],
+		Topic3:          topics[3],
+		TransactionHash: crypto.Keccak256Hash(data, []byte(name)).Hex(),
+		Timestamp:       timestamp,
+	}
+}
@@ -0,0 +1,389 @@
+// Package report summarizes the participation of the identity in a reward epoch,
+// read from the VoterRegistry, the indexer database and the finalizer statistics
+package report
+
+import (
+	"context"
+	"encoding/hex"
+	clientContext "flare-tlc/client/context"
+	"flare-tlc/client/finalizer"
+	"flare-tlc/client/shared"
+	"flare-tlc/database"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/registry"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/contracts/system"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Submission methods counted in the summary, in the order of the voting round
+var submissionMethods = []string{"submit1", "submit2", "submitSignatures", "submit3"}
+
+// RewardEpochSummary is the participation of the identity in a reward epoch
+type RewardEpochSummary struct {
+	RewardEpochId int64          `json:"reward_epoch_id"`
+	Identity      common.Address `json:"identity"`
+	Registered    bool           `json:"registered"`
+
+	// set if the identity is registered in the VoterRegistry for the reward epoch
+	RegistrationWeight      *big.Int        `json:"registration_weight,omitempty"`
+	NormalisedWeight        uint16          `json:"normalised_weight,omitempty"`
+	SigningPolicyAddress    *common.Address `json:"signing_policy_address,omitempty"`
+	SubmitAddress           *common.Address `json:"submit_address,omitempty"`
+	SubmitSignaturesAddress *common.Address `json:"submit_signatures_address,omitempty"`
+
+	// nil if no signature of the identity is indexed
+	PolicySigned     *SignedEvent `json:"policy_signed,omitempty"`
+	UptimeVoteSigned *SignedEvent `json:"uptime_vote_signed,omitempty"`
+	RewardsSigned    *SignedEvent `json:"rewards_signed,omitempty"`
+
+	// messages of the voting rounds of the reward epoch relayed on chain
+	Finalizations          int `json:"finalizations"`
+	FinalizationsWon       int `json:"finalizations_won"`
+	FinalizationsAttempted int `json:"finalizations_attempted"`
+
+	// per method number of submission txs of the registered addresses, sent in the
+	// voting rounds of the reward epoch
+	Submissions map[string]int64 `json:"submissions,omitempty"`
+
+	// per operation and status number of txs sent by the client in the voting rounds
+	// of the reward epoch, read from the tx audit log if enabled
+	Transactions map[string]map[string]int64 `json:"transactions,omitempty"`
+}
+
+// Signature of the identity on the FlareSystemsManager
+type SignedEvent struct {
+	BlockNumber      uint64    `json:"block_number,omitempty"` // 0 if the tx is not indexed
+	TxHash           string    `json:"tx_hash"`
+	Time             time.Time `json:"time"`
+	ThresholdReached bool      `json:"threshold_reached"`
+}
+
+// Parsed FlareSystemsManager event, the reward epoch and the voter of the signature
+type signedEventFields struct {
+	rewardEpochId    *big.Int
+	voter            common.Address
+	timestamp        uint64
+	thresholdReached bool
+}
+
+// BuildRewardEpochSummary reads the participation of the configured identity in the
+// reward epoch. Signatures of the reward epoch are searched from the start of the
+// previous reward epoch, submissions and finalizations in the voting rounds of the
+// reward epoch.
+func BuildRewardEpochSummary(
+	ctx context.Context, clientCtx clientContext.ClientContext, rewardEpochId int64,
+) (*RewardEpochSummary, error) {
+	if clientCtx.DB() == nil {
+		return nil, errors.New("the reward epoch report reads the indexer database, not available with the rpc listener source")
+	}
+	cfg := clientCtx.Config()
+	identity := cfg.Identity.Address
+	if identity == chain.EmptyAddress {
+		return nil, errors.New("identity address is required")
+	}
+	ethClient, err := clientCtx.EthClient()
+	if err != nil {
+		return nil, err
+	}
+	relayContract, err := relay.NewRelay(cfg.ContractAddresses.Relay, ethClient)
+	if err != nil {
+		return nil, err
+	}
+	epochs, err := shared.ScheduleFromChain(relayContract)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching epoch settings")
+	}
+	start := epochs.RewardEpochStart(rewardEpochId)
+	end := epochs.RewardEpochEnd(rewardEpochId)
+	now := time.Now()
+	if !start.Before(now) {
+		return nil, errors.Errorf("reward epoch %d has not started", rewardEpochId)
+	}
+
+	summary := &RewardEpochSummary{RewardEpochId: rewardEpochId, Identity: identity}
+	if err := summary.fetchRegistration(ctx, ethClient, cfg.ContractAddresses.VoterRegistry); err != nil {
+		return nil, err
+	}
+
+	fetchOpts := cfg.Listeners.Fetch.FetchOptions()
+	fetchOpts.PreloadBlocks = true
+	fsm, err := system.NewFlareSystemsManager(cfg.ContractAddresses.SystemsManager, ethClient)
+	if err != nil {
+		return nil, err
+	}
+	// policies are signed in the previous reward epoch, uptime votes and rewards after the end
+	from := epochs.RewardEpochStart(rewardEpochId - 1)
+	fetchSigned := func(name string, parse func(log types.Log) (*signedEventFields, error)) (*SignedEvent, error) {
+		topic0, err := chain.EventIDFromMetadata(system.FlareSystemsManagerMetaData, name)
+		if err != nil {
+			return nil, err
+		}
+		logs, err := database.FetchAll(from.Unix(), now.Unix(), func(from, to int64) ([]database.Log, error) {
+			return database.FetchLogsByAddressAndTopic0(
+				clientCtx.DB(), cfg.ContractAddresses.SystemsManager.Hex(), topic0, from, to, fetchOpts)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error fetching %s events", name)
+		}
+		return findSignedEvent(logs, rewardEpochId, identity, parse)
+	}
+	summary.PolicySigned, err = fetchSigned("SigningPolicySigned", func(log types.Log) (*signedEventFields, error) {
+		event, err := fsm.ParseSigningPolicySigned(log)
+		if err != nil {
+			return nil, err
+		}
+		return &signedEventFields{event.RewardEpochId, event.Voter, event.Timestamp, event.ThresholdReached}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.UptimeVoteSigned, err = fetchSigned("UptimeVoteSigned", func(log types.Log) (*signedEventFields, error) {
+		event, err := fsm.ParseUptimeVoteSigned(log)
+		if err != nil {
+			return nil, err
+		}
+		return &signedEventFields{event.RewardEpochId, event.Voter, event.Timestamp, event.ThresholdReached}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.RewardsSigned, err = fetchSigned("RewardsSigned", func(log types.Log) (*signedEventFields, error) {
+		event, err := fsm.ParseRewardsSigned(log)
+		if err != nil {
+			return nil, err
+		}
+		return &signedEventFields{event.RewardEpochId, event.Voter, event.Timestamp, event.ThresholdReached}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if end.After(now) {
+		end = now
+	}
+	stats, err := finalizer.FinalizationReport(ctx, clientCtx, start, end, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching finalizations")
+	}
+	for _, s := range stats {
+		summary.Finalizations++
+		if s.Won {
+			summary.FinalizationsWon++
+		}
+		if s.Attempted {
+			summary.FinalizationsAttempted++
+		}
+	}
+
+	if summary.Registered {
+		summary.Submissions, err = countSubmissions(clientCtx, summary, start, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if clientCtx.TxAuditLog() != nil {
+		summary.Transactions, err = countTransactions(clientCtx, start, end)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// Registration weight and addresses of the identity in the VoterRegistry
+func (s *RewardEpochSummary) fetchRegistration(ctx context.Context, ethClient bind.ContractBackend, address common.Address) error {
+	voterRegistry, err := registry.NewRegistry(address, ethClient)
+	if err != nil {
+		return err
+	}
+	opts := &bind.CallOpts{Context: ctx}
+	epochId := big.NewInt(s.RewardEpochId)
+	voters, err := voterRegistry.GetRegisteredVoters(opts, epochId)
+	if err != nil {
+		return errors.Wrap(err, "error fetching registered voters")
+	}
+	index := -1
+	for i, voter := range voters {
+		if voter == s.Identity {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil
+	}
+	s.Registered = true
+
+	signingPolicyAddresses, err := voterRegistry.GetRegisteredSigningPolicyAddresses(opts, epochId)
+	if err != nil {
+		return errors.Wrap(err, "error fetching registered signing policy addresses")
+	}
+	submitAddresses, err := voterRegistry.GetRegisteredSubmitAddresses(opts, epochId)
+	if err != nil {
+		return errors.Wrap(err, "error fetching registered submit addresses")
+	}
+	submitSignaturesAddresses, err := voterRegistry.GetRegisteredSubmitSignaturesAddresses(opts, epochId)
+	if err != nil {
+		return errors.Wrap(err, "error fetching registered submit signatures addresses")
+	}
+	if index >= len(signingPolicyAddresses) || index >= len(submitAddresses) || index >= len(submitSignaturesAddresses) {
+		return errors.Errorf("registered addresses of reward epoch %d do not match the %d voters", s.RewardEpochId, len(voters))
+	}
+	s.SigningPolicyAddress = &signingPolicyAddresses[index]
+	s.SubmitAddress = &submitAddresses[index]
+	s.SubmitSignaturesAddress = &submitSignaturesAddresses[index]
+
+	s.RegistrationWeight, err = voterRegistry.GetVoterRegistrationWeight(opts, s.Identity, epochId)
+	if err != nil {
+		return errors.Wrap(err, "error fetching registration weight")
+	}
+	normalised, err := voterRegistry.GetVoterWithNormalisedWeight(opts, epochId, *s.SigningPolicyAddress)
+	if err != nil {
+		return errors.Wrap(err, "error fetching normalised weight")
+	}
+	s.NormalisedWeight = normalised.NormalisedWeight
+	return nil
+}
+
+// Returns the first signature of the voter for the reward epoch, nil if there is none
+func findSignedEvent(
+	logs []database.Log, rewardEpochId int64, voter common.Address, parse func(log types.Log) (*signedEventFields, error),
+) (*SignedEvent, error) {
+	for _, log := range logs {
+		contractLog, err := shared.ConvertDatabaseLogToChainLog(log)
+		if err != nil {
+			return nil, err
+		}
+		fields, err := parse(*contractLog)
+		if err != nil {
+			return nil, err
+		}
+		if fields.voter != voter || fields.rewardEpochId == nil || fields.rewardEpochId.Int64() != rewardEpochId {
+			continue
+		}
+		return &SignedEvent{
+			BlockNumber:      log.Transaction.BlockNumber,
+			TxHash:           "0x" + log.TransactionHash,
+			Time:             time.Unix(int64(fields.timestamp), 0),
+			ThresholdReached: fields.thresholdReached,
+		}, nil
+	}
+	return nil, nil
+}
+
+func countSubmissions(
+	clientCtx clientContext.ClientContext, summary *RewardEpochSummary, start, end time.Time,
+) (map[string]int64, error) {
+	submissionABI, err := submission.SubmissionMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	contract := clientCtx.Config().ContractAddresses.Submission
+	result := make(map[string]int64, len(submissionMethods))
+	for _, method := range submissionMethods {
+		sender := *summary.SubmitAddress
+		if method == "submitSignatures" {
+			sender = *summary.SubmitSignaturesAddress
+		}
+		count, err := database.CountTransactions(clientCtx.DB(), contract.Hex(),
+			hex.EncodeToString(submissionABI.Methods[method].ID), sender.Hex(), start.Unix(), end.Unix())
+		if err != nil {
+			return nil, errors.Wrapf(err, "error counting %s txs", method)
+		}
+		result[method] = count
+	}
+	return result, nil
+}
+
+// Txs of the tx audit log sent in the range, per operation and status
+func countTransactions(clientCtx clientContext.ClientContext, start, end time.Time) (map[string]map[string]int64, error) {
+	counts, err := database.CountTxAuditEntries(clientCtx.DB(), start.Unix(), end.Unix())
+	if err != nil {
+		return nil, errors.Wrap(err, "error counting audited txs")
+	}
+	result := make(map[string]map[string]int64)
+	for _, count := range counts {
+		if result[count.Operation] == nil {
+			result[count.Operation] = make(map[string]int64)
+		}
+		result[count.Operation][count.Status] = count.Count
+	}
+	return result, nil
+}
+
+// Print writes the summary as one line per item
+func (s *RewardEpochSummary) Print(w io.Writer) {
+	fmt.Fprintf(w, "Reward epoch %d, identity %s\n", s.RewardEpochId, s.Identity.Hex())
+	if !s.Registered {
+		fmt.Fprintln(w, "  registered:          no")
+	} else {
+		fmt.Fprintf(w, "  registered:          yes, weight %v, normalised weight %d\n", s.RegistrationWeight, s.NormalisedWeight)
+		fmt.Fprintf(w, "  signing policy:      %s\n", s.SigningPolicyAddress.Hex())
+		fmt.Fprintf(w, "  submit:              %s\n", s.SubmitAddress.Hex())
+		fmt.Fprintf(w, "  submit signatures:   %s\n", s.SubmitSignaturesAddress.Hex())
+	}
+	fmt.Fprintf(w, "  policy signed:       %s\n", s.PolicySigned)
+	fmt.Fprintf(w, "  uptime vote signed:  %s\n", s.UptimeVoteSigned)
+	fmt.Fprintf(w, "  rewards signed:      %s\n", s.RewardsSigned)
+	fmt.Fprintf(w, "  finalizations won:   %d of %d relayed messages, %d attempted\n",
+		s.FinalizationsWon, s.Finalizations, s.FinalizationsAttempted)
+	if s.Registered {
+		fmt.Fprint(w, "  submissions:        ")
+		for _, method := range submissionMethods {
+			fmt.Fprintf(w, " %s %d", method, s.Submissions[method])
+		}
+		fmt.Fprintln(w)
+	}
+	if s.Transactions != nil {
+		fmt.Fprintf(w, "  sent txs:            %s\n", formatTransactions(s.Transactions))
+	}
+}
+
+// Formats the counts as "operation status count, ..." sorted by operation and status
+func formatTransactions(transactions map[string]map[string]int64) string {
+	if len(transactions) == 0 {
+		return "none"
+	}
+	var items []string
+	for _, operation := range sortedKeys(transactions) {
+		for _, status := range sortedKeys(transactions[operation]) {
+			items = append(items, fmt.Sprintf("%s %s %d", operation, status, transactions[operation][status]))
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (e *SignedEvent) String() string {
+	if e == nil {
+		return "no"
+	}
+	threshold := ""
+	if e.ThresholdReached {
+		threshold = ", threshold reached"
+	}
+	if e.BlockNumber == 0 {
+		return fmt.Sprintf("yes, %s tx %s%s", e.Time.UTC().Format(time.RFC3339), e.TxHash, threshold)
+	}
+	return fmt.Sprintf("yes, block %d at %s tx %s%s", e.BlockNumber, e.Time.UTC().Format(time.RFC3339), e.TxHash, threshold)
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flare-tlc/database"
+	"flare-tlc/utils/contracts/system"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func signingPolicySignedLog(t *testing.T, rewardEpochId int64, voter common.Address, timestamp uint64) database.Log {
+	fsmABI, err := system.FlareSystemsManagerMetaData.GetAbi()
+	require.NoError(t, err)
+	event := fsmABI.Events["SigningPolicySigned"]
+	data, err := event.Inputs.NonIndexed().Pack(timestamp, true)
+	require.NoError(t, err)
+	return database.Log{
+		Data:            hex.EncodeToString(data),
+		Topic0:          event.ID.Hex(),
+		Topic1:          common.BigToHash(big.NewInt(rewardEpochId)).Hex(),
+		Topic2:          common.BytesToHash(common.HexToAddress("0x0a").Bytes()).Hex(),
+		Topic3:          common.BytesToHash(voter.Bytes()).Hex(),
+		TransactionHash: strings.Repeat("ab", 32),
+		Transaction:     database.Transaction{BlockNumber: 100},
+		Timestamp:       timestamp,
+	}
+}
+
+func TestFindSignedEvent(t *testing.T) {
+	fsm, err := system.NewFlareSystemsManager(common.HexToAddress("0x01"), nil)
+	require.NoError(t, err)
+	parse := func(log types.Log) (*signedEventFields, error) {
+		event, err := fsm.ParseSigningPolicySigned(log)
+		if err != nil {
+			return nil, err
+		}
+		return &signedEventFields{event.RewardEpochId, event.Voter, event.Timestamp, event.ThresholdReached}, nil
+	}
+	voter, other := common.HexToAddress("0x02"), common.HexToAddress("0x03")
+	logs := []database.Log{
+		signingPolicySignedLog(t, 5, other, 1000),
+		signingPolicySignedLog(t, 4, voter, 1010),
+		signingPolicySignedLog(t, 5, voter, 1020),
+	}
+
+	event, err := findSignedEvent(logs, 5, voter, parse)
+	require.NoError(t, err)
+	require.Equal(t, &SignedEvent{
+		BlockNumber:      100,
+		TxHash:           "0x" + strings.Repeat("ab", 32),
+		Time:             time.Unix(1020, 0),
+		ThresholdReached: true,
+	}, event)
+
+	event, err = findSignedEvent(logs, 6, voter, parse)
+	require.NoError(t, err)
+	require.Nil(t, event)
+}
+
+func TestPrintSummary(t *testing.T) {
+	address := common.HexToAddress("0x02")
+	summary := &RewardEpochSummary{
+		RewardEpochId:           5,
+		Identity:                common.HexToAddress("0x01"),
+		Registered:              true,
+		RegistrationWeight:      big.NewInt(1000),
+		NormalisedWeight:        300,
+		SigningPolicyAddress:    &address,
+		SubmitAddress:           &address,
+		SubmitSignaturesAddress: &address,
+		PolicySigned:            &SignedEvent{BlockNumber: 100, TxHash: "0xab", Time: time.Unix(1020, 0)},
+		Finalizations:           10,
+		FinalizationsWon:        2,
+		FinalizationsAttempted:  3,
+		Submissions:             map[string]int64{"submit1": 7, "submitSignatures": 6},
+		Transactions: map[string]map[string]int64{
+			"submit":            {"mined": 5},
+			"submit_signatures": {"mined": 3, "timeout": 1},
+		},
+	}
+	var buffer bytes.Buffer
+	summary.Print(&buffer)
+	output := buffer.String()
+	require.Contains(t, output, "registered:          yes, weight 1000, normalised weight 300")
+	require.Contains(t, output, "policy signed:       yes, block 100 at 1970-01-01T00:17:00Z tx 0xab")
+	require.Contains(t, output, "uptime vote signed:  no")
+	require.Contains(t, output, "finalizations won:   2 of 10 relayed messages, 3 attempted")
+	require.Contains(t, output, "submissions:         submit1 7 submit2 0 submitSignatures 6 submit3 0")
+	require.Contains(t, output, "sent txs:            submit mined 5, submit_signatures mined 3, submit_signatures timeout 1")
+}
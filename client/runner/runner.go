@@ -3,13 +3,19 @@ package runner
 import (
 	"context"
 	"errors"
+	"flare-tlc/client/admin"
 	clientContext "flare-tlc/client/context"
 	"flare-tlc/client/epoch"
+	"flare-tlc/client/fastupdates"
 	"flare-tlc/client/finalizer"
 	"flare-tlc/client/protocol"
+	"flare-tlc/client/shared"
 	"flare-tlc/logger"
+	"flare-tlc/utils/contracts/system"
 	"reflect"
 	"sync"
+
+	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 type Runner interface {
@@ -33,7 +39,12 @@ func RunAsync(ctx context.Context, cancel context.CancelFunc, wg *sync.WaitGroup
 }
 
 func Start(ctx context.Context, cancel context.CancelFunc, clientCtx clientContext.ClientContext) *sync.WaitGroup {
-	registrationClient, err := epoch.NewEpochClient(clientCtx)
+	// the instance is on standby before the clients start unless it acquires the lease
+	leaderLease, err := shared.NewLeaderLease(clientCtx.DB(), &clientCtx.Config().LeaderElection, clientCtx.Config().Identity.Address)
+	if err != nil {
+		logger.Fatal("Error creating leader lease: %v", err)
+	}
+	registrationClients, err := epoch.NewEpochClients(clientCtx)
 	if err != nil {
 		logger.Fatal("Error creating registration client: %v", err)
 	}
@@ -46,10 +57,70 @@ func Start(ctx context.Context, cancel context.CancelFunc, clientCtx clientConte
 		logger.Fatal("Error creating finalizer client: %v", err)
 	}
 
+	fastUpdatesClient, err := fastupdates.NewFastUpdatesClient(clientCtx)
+	if err != nil {
+		logger.Fatal("Error creating fast updates client: %v", err)
+	}
+
+	ethClient, err := clientCtx.EthClient()
+	if err != nil {
+		logger.Fatal("Error dialing eth rpc: %v", err)
+	}
+	balanceWatcher, err := shared.NewBalanceWatcher(clientCtx.Config(), ethClient)
+	if err != nil {
+		logger.Fatal("Error creating balance watcher: %v", err)
+	}
+
+	var adminFinalizer admin.Finalizer
+	if finalizerClient != nil {
+		adminFinalizer = finalizerClient
+	}
+	var adminEpochClient admin.EpochClient
+	if registrationClients != nil {
+		adminEpochClient = registrationClients
+	}
+	var adminTxAudit admin.TxAudit
+	if txAuditLog := clientCtx.TxAuditLog(); txAuditLog != nil {
+		adminTxAudit = txAuditLog
+	}
+	adminServer := admin.NewServer(&clientCtx.Config().Admin, adminFinalizer, adminEpochClient, adminTxAudit)
+
+	keyRotationWatcher, err := newKeyRotationWatcher(clientCtx, ethClient)
+	if err != nil {
+		logger.Fatal("Error creating key rotation watcher: %v", err)
+	}
+	if keyRotationWatcher != nil {
+		// the key is switched before the clients start if the rotation epoch already started
+		keyRotationWatcher.Update(ctx)
+	}
+
 	wg := sync.WaitGroup{}
+	RunAsync(ctx, cancel, &wg, leaderLease)
 	RunAsync(ctx, cancel, &wg, protocolClient)
-	RunAsync(ctx, cancel, &wg, registrationClient)
+	for _, registrationClient := range registrationClients {
+		RunAsync(ctx, cancel, &wg, registrationClient)
+	}
 	RunAsync(ctx, cancel, &wg, finalizerClient)
+	RunAsync(ctx, cancel, &wg, fastUpdatesClient)
+	RunAsync(ctx, cancel, &wg, adminServer)
+	RunAsync(ctx, cancel, &wg, balanceWatcher)
+	RunAsync(ctx, cancel, &wg, keyRotationWatcher)
 
 	return &wg
 }
+
+// Returns nil if no signing policy key rotation is staged
+func newKeyRotationWatcher(clientCtx clientContext.ClientContext, ethClient *ethclient.Client) (*shared.KeyRotationWatcher, error) {
+	if !clientCtx.Config().Credentials.NextSigningPolicyKeyConfigured() {
+		return nil, nil
+	}
+	signer, err := clientCtx.SigningPolicySigner()
+	if err != nil {
+		return nil, err
+	}
+	systemsManager, err := system.NewFlareSystemsManagerCaller(clientCtx.Config().ContractAddresses.SystemsManager, ethClient)
+	if err != nil {
+		return nil, err
+	}
+	return shared.NewKeyRotationWatcher(signer, systemsManager), nil
+}
@@ -0,0 +1,281 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"flare-tlc/client/config"
+	flarelogger "flare-tlc/logger"
+	"flare-tlc/utils"
+	"flare-tlc/utils/metrics"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var logger = flarelogger.Module("alerts")
+
+// Kinds of the alerts, the keys of the message templates
+const (
+	NoSigningPolicy           = "no_signing_policy"
+	RegistrationWindowClosing = "registration_window_closing"
+	SigningPolicyDeadline     = "signing_policy_deadline"
+	TxFailures                = "tx_failures"
+	LowBalance                = "low_balance"
+)
+
+// Default message templates, executed with the Alert
+var defaultTemplates = map[string]string{
+	NoSigningPolicy: `{{if .Resolved}}Signing policy of reward epoch {{.Fields.reward_epoch}} received` +
+		`{{else}}No signing policy for the current reward epoch {{.Fields.reward_epoch}}, ` +
+		`the last one is of reward epoch {{.Fields.last_reward_epoch}}{{end}}`,
+	RegistrationWindowClosing: `{{if .Resolved}}Voter {{.Fields.voter}} registered for reward epoch {{.Fields.reward_epoch}}` +
+		`{{else}}Voter {{.Fields.voter}} not registered for reward epoch {{.Fields.reward_epoch}} ` +
+		`{{.Fields.elapsed}} after the vote power block was selected, the registration window is closing{{end}}`,
+	SigningPolicyDeadline: `{{if .Resolved}}Voter {{.Fields.voter}} signed the signing policy of reward epoch {{.Fields.reward_epoch}}` +
+		`{{else}}Voter {{.Fields.voter}} has not signed the signing policy of reward epoch {{.Fields.reward_epoch}}, ` +
+		`the signing window closes at {{.Fields.deadline}}{{end}}`,
+	TxFailures: `{{if .Resolved}}Transactions are mined again` +
+		`{{else}}{{.Fields.failures}} consecutive transactions failed, last error: {{.Fields.error}}{{end}}`,
+	LowBalance: `{{if .Resolved}}Balance of {{.Fields.account}} account {{.Fields.address}} is {{.Fields.balance}} wei, sending transactions again` +
+		`{{else}}Balance of {{.Fields.account}} account {{.Fields.address}} is {{.Fields.balance}} wei, ` +
+		`below the minimum of {{.Fields.min_balance}} wei{{end}}`,
+}
+
+// Notifications are dropped while the queue is full
+const queueSize = 100
+
+var alertsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "alerts_sent_total",
+	Help:      "Alert notifications sent, per kind, notifier and result",
+}, []string{"kind", "notifier", "result"})
+
+type Alert struct {
+	Kind string `json:"kind"`
+	// Instance of the condition, e.g. the account of a low balance, empty if there is one
+	Key string `json:"key,omitempty"`
+	// Identity address of the client
+	Identity string            `json:"identity"`
+	Resolved bool              `json:"resolved"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	Time     time.Time         `json:"time"`
+	// Rendered message template of the kind
+	Message string `json:"message"`
+}
+
+type Notifier interface {
+	Name() string
+	Notify(ctx context.Context, alert *Alert) error
+}
+
+type alertKey struct {
+	kind string
+	key  string
+}
+
+// Dispatcher sends the alerts to the notifiers. A firing alert is sent once and
+// repeated after repeatInterval while it keeps firing, a resolved notification is sent
+// when a firing alert is resolved. Alerts are sent by Run, Fire and Resolve do not block.
+type Dispatcher struct {
+	notifiers      []Notifier
+	templates      map[string]*template.Template
+	identity       string
+	repeatInterval time.Duration
+	timeout        time.Duration
+	clock          utils.Clock
+
+	mu sync.Mutex
+	// last time the firing alerts were sent
+	firing map[alertKey]time.Time
+	queue  chan *Alert
+}
+
+// Returns nil if no notifier is configured
+func NewDispatcher(cfg *config.AlertsConfig, identity string) (*Dispatcher, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	var notifiers []Notifier
+	if len(cfg.WebhookURL) > 0 {
+		notifiers = append(notifiers, &webhookNotifier{url: cfg.WebhookURL, client: client})
+	}
+	if len(cfg.SlackWebhookURL) > 0 {
+		notifiers = append(notifiers, &slackNotifier{url: cfg.SlackWebhookURL, client: client})
+	}
+	if len(cfg.PagerDutyRoutingKey) > 0 {
+		notifiers = append(notifiers, &pagerDutyNotifier{url: pagerDutyEventsURL, routingKey: cfg.PagerDutyRoutingKey, client: client})
+	}
+	return newDispatcher(notifiers, cfg, identity)
+}
+
+func newDispatcher(notifiers []Notifier, cfg *config.AlertsConfig, identity string) (*Dispatcher, error) {
+	templates := make(map[string]*template.Template, len(defaultTemplates))
+	for kind, text := range defaultTemplates {
+		if custom, ok := cfg.Templates[kind]; ok {
+			text = custom
+		}
+		t, err := template.New(kind).Option("missingkey=zero").Parse(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid alerts template %s", kind)
+		}
+		templates[kind] = t
+	}
+	for kind := range cfg.Templates {
+		if _, ok := defaultTemplates[kind]; !ok {
+			return nil, errors.Errorf("unknown alert kind %s in alerts templates", kind)
+		}
+	}
+	return &Dispatcher{
+		notifiers:      notifiers,
+		templates:      templates,
+		identity:       identity,
+		repeatInterval: cfg.RepeatInterval,
+		timeout:        cfg.Timeout,
+		clock:          utils.RealClock,
+		firing:         make(map[alertKey]time.Time),
+		queue:          make(chan *Alert, queueSize),
+	}, nil
+}
+
+// Fire sends the alert unless it was sent less than the repeat interval ago
+func (d *Dispatcher) Fire(kind, key string, fields map[string]string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.clock.Now()
+	k := alertKey{kind: kind, key: key}
+	if last, ok := d.firing[k]; ok && now.Sub(last) < d.repeatInterval {
+		return
+	}
+	d.firing[k] = now
+	d.enqueue(kind, key, false, fields, now)
+}
+
+// Resolve sends the resolved notification if the alert is firing
+func (d *Dispatcher) Resolve(kind, key string, fields map[string]string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	k := alertKey{kind: kind, key: key}
+	if _, ok := d.firing[k]; !ok {
+		return
+	}
+	delete(d.firing, k)
+	d.enqueue(kind, key, true, fields, d.clock.Now())
+}
+
+func (d *Dispatcher) enqueue(kind, key string, resolved bool, fields map[string]string, now time.Time) {
+	alert := &Alert{
+		Kind:     kind,
+		Key:      key,
+		Identity: d.identity,
+		Resolved: resolved,
+		Fields:   fields,
+		Time:     now,
+	}
+	alert.Message = d.message(alert)
+	if resolved {
+		logger.Info("Alert %s resolved: %s", kind, alert.Message)
+	} else {
+		logger.Warn("Alert %s: %s", kind, alert.Message)
+	}
+	select {
+	case d.queue <- alert:
+	default:
+		logger.Warn("Alert queue is full, %s alert not sent", kind)
+	}
+}
+
+func (d *Dispatcher) message(alert *Alert) string {
+	t, ok := d.templates[alert.Kind]
+	if !ok {
+		return fmt.Sprintf("%s %+v", alert.Kind, alert.Fields)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, alert); err != nil {
+		logger.Warn("Error executing %s alert template: %v", alert.Kind, err)
+		return fmt.Sprintf("%s %+v", alert.Kind, alert.Fields)
+	}
+	return buf.String()
+}
+
+// Run sends the queued alerts until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		select {
+		case alert := <-d.queue:
+			d.send(ctx, alert)
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, alert *Alert) {
+	for _, n := range d.notifiers {
+		sendCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		err := n.Notify(sendCtx, alert)
+		cancel()
+		if err != nil {
+			logger.Warn("Error sending %s alert to %s: %v", alert.Kind, n.Name(), err)
+			alertsSent.WithLabelValues(alert.Kind, n.Name(), "error").Inc()
+			continue
+		}
+		alertsSent.WithLabelValues(alert.Kind, n.Name(), "ok").Inc()
+	}
+}
+
+// Process-wide dispatcher of the clients, nil if alerts are disabled
+var defaultDispatcher atomic.Pointer[Dispatcher]
+
+func SetDefault(d *Dispatcher) {
+	defaultDispatcher.Store(d)
+}
+
+// Enabled returns true if the default dispatcher is set
+func Enabled() bool {
+	return defaultDispatcher.Load() != nil
+}
+
+// Fire fires the alert on the default dispatcher, see Dispatcher.Fire
+func Fire(kind, key string, fields map[string]string) {
+	defaultDispatcher.Load().Fire(kind, key, fields)
+}
+
+// Resolve resolves the alert on the default dispatcher, see Dispatcher.Resolve
+func Resolve(kind, key string, fields map[string]string) {
+	defaultDispatcher.Load().Resolve(kind, key, fields)
+}
+
+// TxFailuresHook returns the hook of chain.SetTxFailureHook firing the tx_failures
+// alert after threshold consecutive failed txs, resolved by the next mined tx
+func TxFailuresHook(threshold int) func(failures int64, err error) {
+	return func(failures int64, err error) {
+		if failures < int64(threshold) {
+			return
+		}
+		fields := map[string]string{"failures": strconv.FormatInt(failures, 10)}
+		if err == nil {
+			Resolve(TxFailures, "", fields)
+			return
+		}
+		fields["error"] = err.Error()
+		Fire(TxFailures, "", fields)
+	}
+}
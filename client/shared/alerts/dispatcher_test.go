@@ -0,0 +1,162 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flare-tlc/client/config"
+	"flare-tlc/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testNotifier struct {
+	alerts []*Alert
+}
+
+func (n *testNotifier) Name() string { return "test" }
+
+func (n *testNotifier) Notify(ctx context.Context, alert *Alert) error {
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+func testAlertsConfig() *config.AlertsConfig {
+	return &config.AlertsConfig{RepeatInterval: time.Hour, Timeout: time.Second}
+}
+
+// Sends the queued alerts
+func drain(d *Dispatcher) {
+	for {
+		select {
+		case alert := <-d.queue:
+			d.send(context.Background(), alert)
+		default:
+			return
+		}
+	}
+}
+
+func TestDispatcherRepeatAndResolve(t *testing.T) {
+	notifier := &testNotifier{}
+	d, err := newDispatcher([]Notifier{notifier}, testAlertsConfig(), "0xabc")
+	require.NoError(t, err)
+	clock := utils.NewFakeClock(time.Unix(1000, 0))
+	d.clock = clock
+	fields := map[string]string{"account": "submit", "address": "0x01", "balance": "5", "min_balance": "10"}
+
+	// resolving an alert that is not firing sends nothing
+	d.Resolve(LowBalance, "submit", fields)
+	d.Fire(LowBalance, "submit", fields)
+	d.Fire(LowBalance, "submit", fields)
+	d.Fire(LowBalance, "signing", fields)
+	drain(d)
+	require.Len(t, notifier.alerts, 2)
+	require.Equal(t, "Balance of submit account 0x01 is 5 wei, below the minimum of 10 wei", notifier.alerts[0].Message)
+	require.Equal(t, "0xabc", notifier.alerts[0].Identity)
+
+	// repeated after the repeat interval
+	clock.Advance(time.Hour)
+	d.Fire(LowBalance, "submit", fields)
+	d.Resolve(LowBalance, "submit", fields)
+	d.Resolve(LowBalance, "submit", fields)
+	drain(d)
+	require.Len(t, notifier.alerts, 4)
+	require.True(t, notifier.alerts[3].Resolved)
+	require.Equal(t, "Balance of submit account 0x01 is 5 wei, sending transactions again", notifier.alerts[3].Message)
+}
+
+func TestDispatcherTemplates(t *testing.T) {
+	cfg := testAlertsConfig()
+	cfg.Templates = map[string]string{TxFailures: "{{.Identity}}: {{.Fields.failures}} failed"}
+	notifier := &testNotifier{}
+	d, err := newDispatcher([]Notifier{notifier}, cfg, "0xabc")
+	require.NoError(t, err)
+
+	d.Fire(TxFailures, "", map[string]string{"failures": "3"})
+	drain(d)
+	require.Equal(t, "0xabc: 3 failed", notifier.alerts[0].Message)
+
+	cfg.Templates = map[string]string{"unknown": "text"}
+	_, err = newDispatcher(nil, cfg, "0xabc")
+	require.ErrorContains(t, err, "unknown alert kind")
+
+	cfg.Templates = map[string]string{TxFailures: "{{.Fields"}
+	_, err = newDispatcher(nil, cfg, "0xabc")
+	require.ErrorContains(t, err, "invalid alerts template")
+
+	// nil dispatcher without notifiers
+	var disabled *Dispatcher
+	disabled.Fire(TxFailures, "", nil)
+	disabled.Resolve(TxFailures, "", nil)
+}
+
+func TestTxFailuresHook(t *testing.T) {
+	notifier := &testNotifier{}
+	d, err := newDispatcher([]Notifier{notifier}, testAlertsConfig(), "0xabc")
+	require.NoError(t, err)
+	SetDefault(d)
+	defer SetDefault(nil)
+
+	hook := TxFailuresHook(2)
+	hook(1, errors.New("reverted"))
+	hook(1, nil)
+	drain(d)
+	require.Empty(t, notifier.alerts)
+
+	hook(1, errors.New("reverted"))
+	hook(2, errors.New("reverted"))
+	hook(2, nil)
+	drain(d)
+	require.Len(t, notifier.alerts, 2)
+	require.Equal(t, "reverted", notifier.alerts[0].Fields["error"])
+	require.True(t, notifier.alerts[1].Resolved)
+}
+
+func TestNotifiers(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	alert := &Alert{
+		Kind:     NoSigningPolicy,
+		Identity: "0xabc",
+		Fields:   map[string]string{"reward_epoch": "10"},
+		Time:     time.Unix(1000, 0),
+		Message:  "No signing policy",
+	}
+	ctx := context.Background()
+	client := server.Client()
+
+	require.NoError(t, (&webhookNotifier{url: server.URL, client: client}).Notify(ctx, alert))
+	require.Equal(t, "no_signing_policy", bodies[0]["kind"])
+	require.Equal(t, "No signing policy", bodies[0]["message"])
+
+	require.NoError(t, (&slackNotifier{url: server.URL, client: client}).Notify(ctx, alert))
+	require.Equal(t, ":red_circle: *no_signing_policy* 0xabc: No signing policy", bodies[1]["text"])
+
+	pagerDuty := &pagerDutyNotifier{url: server.URL, routingKey: "key", client: client}
+	require.NoError(t, pagerDuty.Notify(ctx, alert))
+	require.Equal(t, "trigger", bodies[2]["event_action"])
+	require.Equal(t, "0xabc/no_signing_policy/", bodies[2]["dedup_key"])
+	require.Equal(t, "No signing policy", bodies[2]["payload"].(map[string]interface{})["summary"])
+
+	alert.Resolved = true
+	require.NoError(t, pagerDuty.Notify(ctx, alert))
+	require.Equal(t, "resolve", bodies[3]["event_action"])
+	require.NotContains(t, bodies[3], "payload")
+
+	err := (&webhookNotifier{url: server.URL + "/fail", client: client}).Notify(ctx, alert)
+	require.ErrorContains(t, err, "400")
+}
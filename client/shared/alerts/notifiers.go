@@ -0,0 +1,111 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Posts the alert as JSON
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n *webhookNotifier) Name() string { return "webhook" }
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert *Alert) error {
+	return postJSON(ctx, n.client, n.url, alert)
+}
+
+// Posts the message to a Slack incoming webhook
+type slackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func (n *slackNotifier) Name() string { return "slack" }
+
+func (n *slackNotifier) Notify(ctx context.Context, alert *Alert) error {
+	icon := ":red_circle:"
+	if alert.Resolved {
+		icon = ":large_green_circle:"
+	}
+	return postJSON(ctx, n.client, n.url, map[string]string{
+		"text": icon + " *" + alert.Kind + "* " + alert.Identity + ": " + alert.Message,
+	})
+}
+
+// Triggers and resolves PagerDuty incidents with the Events API v2, an incident per
+// kind, key and identity
+type pagerDutyNotifier struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+func (n *pagerDutyNotifier) Name() string { return "pagerduty" }
+
+func (n *pagerDutyNotifier) Notify(ctx context.Context, alert *Alert) error {
+	event := pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "trigger",
+		DedupKey:    alert.Identity + "/" + alert.Kind + "/" + alert.Key,
+	}
+	if alert.Resolved {
+		event.EventAction = "resolve"
+	} else {
+		event.Payload = &pagerDutyPayload{
+			Summary:       alert.Message,
+			Source:        alert.Identity,
+			Severity:      "critical",
+			Timestamp:     alert.Time.UTC().Format(time.RFC3339),
+			CustomDetails: alert.Fields,
+		}
+	}
+	return postJSON(ctx, n.client, n.url, event)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return errors.Wrap(err, "error encoding notification")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return errors.Wrap(err, "error creating notification request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "error sending notification")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("notification rejected with status %s", resp.Status)
+	}
+	return nil
+}
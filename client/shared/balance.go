@@ -0,0 +1,200 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/client/shared/alerts"
+	globalConfig "flare-tlc/config"
+	"flare-tlc/logger"
+	"flare-tlc/utils"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/metrics"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	accountBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "account_balance",
+		Help:      "Native token balance of the sender account, in FLR",
+	}, []string{"account"})
+	accountLowBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "account_low_balance",
+		Help:      "1 if the balance of the sender account is below the minimum and no transactions are sent from it",
+	}, []string{"account"})
+)
+
+// Implemented by ethclient.Client
+type BalanceClient interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+type balanceAccount struct {
+	name    string
+	address common.Address
+}
+
+// BalanceWatcher periodically checks the balances of the sender accounts. Accounts
+// below the minimum balance are marked with chain.SetLowBalance, so that no new
+// transactions are sent from them until they are funded again.
+type BalanceWatcher struct {
+	client     BalanceClient
+	accounts   []balanceAccount
+	minBalance *big.Int
+	interval   time.Duration
+	clock      utils.Clock
+}
+
+// Returns nil if the balance watcher is disabled, the client is not closed by the watcher
+func NewBalanceWatcher(cfg *config.ClientConfig, client BalanceClient) (*BalanceWatcher, error) {
+	if !cfg.Balance.Enabled {
+		return nil, nil
+	}
+	accounts, err := balanceAccounts(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newBalanceWatcher(client, accounts, &cfg.Balance), nil
+}
+
+func newBalanceWatcher(client BalanceClient, accounts []balanceAccount, cfg *config.BalanceConfig) *BalanceWatcher {
+	minBalance := cfg.MinBalance
+	if minBalance == nil {
+		minBalance = big.NewInt(0)
+	}
+	return &BalanceWatcher{
+		client:     client,
+		accounts:   accounts,
+		minBalance: minBalance,
+		interval:   cfg.Interval,
+		clock:      utils.RealClock,
+	}
+}
+
+// Sender accounts of the enabled clients: submit and submit signatures of the protocol
+// client, submit of the fast updates client, signing (registration and signing policy txs) of the epoch client and
+// finalization (relay txs) of the finalizer, also from the senders of the finalized protocols
+func balanceAccounts(cfg *config.ClientConfig) ([]balanceAccount, error) {
+	credentials := &cfg.Credentials
+	var accounts []balanceAccount
+	add := func(name string, signerCfg *globalConfig.SignerConfig, fileName string, envString string) error {
+		signer, err := globalConfig.SignerFromConfig(signerCfg, fileName, envString)
+		if err != nil {
+			return errors.Wrapf(err, "error creating %s signer for the balance watcher", name)
+		}
+		accounts = append(accounts, balanceAccount{name: name, address: signer.Address()})
+		return nil
+	}
+
+	if cfg.Clients.EnabledProtocolVoting || cfg.Clients.EnabledFastUpdates {
+		err := add("submit", &credentials.ProtocolManagerSubmitSigner,
+			credentials.ProtocolManagerSubmitPrivateKeyFile, credentials.ProtocolManagerSubmitPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Clients.EnabledProtocolVoting {
+		err := add("submit_signatures", &credentials.ProtocolManagerSubmitSignaturesSigner,
+			credentials.ProtocolManagerSubmitSignaturesPrivateKeyFile, credentials.ProtocolManagerSubmitSignaturesPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Clients.EpochClientEnabled() {
+		err := add("signing", &credentials.SystemClientSenderSigner,
+			credentials.SystemClientSenderPrivateKeyFile, credentials.SystemClientSenderPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		for i := range cfg.Identities {
+			identity := &cfg.Identities[i]
+			err := add("signing_"+identity.Address.Hex(), &identity.Credentials.SystemClientSenderSigner,
+				identity.Credentials.SystemClientSenderPrivateKeyFile, identity.Credentials.SystemClientSenderPrivateKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cfg.Clients.EnabledFinalizer {
+		err := add("finalization", &credentials.SigningPolicySigner,
+			credentials.SigningPolicyPrivateKeyFile, credentials.SigningPolicyPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		if credentials.NextSigningPolicyKeyConfigured() {
+			err := add("finalization_next", &credentials.NextSigningPolicySigner,
+				credentials.NextSigningPolicyPrivateKeyFile, credentials.NextSigningPolicyPrivateKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for i := range cfg.Finalizer.Senders {
+			sender := &cfg.Finalizer.Senders[i]
+			err := add(fmt.Sprintf("finalization_sender_%d", i+1), &sender.Signer,
+				sender.PrivateKeyFile, config.FinalizerSenderPrivateKey(i))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return accounts, nil
+}
+
+func (w *BalanceWatcher) Run(ctx context.Context) error {
+	ticker := w.clock.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.check(ctx)
+
+		select {
+		case <-ticker.C():
+			break
+
+		case <-ctx.Done():
+			logger.Info("Balance watcher stopped")
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *BalanceWatcher) check(ctx context.Context) {
+	for _, account := range w.accounts {
+		balance, err := w.client.BalanceAt(ctx, account.address, nil)
+		if err != nil {
+			logger.Warn("Error fetching balance of %s account %s: %v", account.name, account.address.Hex(), err)
+			continue
+		}
+		flr, _ := new(big.Float).Quo(new(big.Float).SetInt(balance), big.NewFloat(params.Ether)).Float64()
+		accountBalance.WithLabelValues(account.name).Set(flr)
+
+		low := balance.Cmp(w.minBalance) < 0
+		alertFields := map[string]string{
+			"account":     account.name,
+			"address":     account.address.Hex(),
+			"balance":     balance.String(),
+			"min_balance": w.minBalance.String(),
+		}
+		if low {
+			logger.Error("Balance of %s account %s is %v wei, below the minimum of %v wei, no transactions are sent from the account",
+				account.name, account.address.Hex(), balance, w.minBalance)
+			accountLowBalance.WithLabelValues(account.name).Set(1)
+			alerts.Fire(alerts.LowBalance, account.name, alertFields)
+		} else {
+			if chain.LowBalance(account.address) {
+				logger.Info("Balance of %s account %s is %v wei, sending transactions again", account.name, account.address.Hex(), balance)
+			}
+			accountLowBalance.WithLabelValues(account.name).Set(0)
+			alerts.Resolve(alerts.LowBalance, account.name, alertFields)
+		}
+		chain.SetLowBalance(account.address, low)
+	}
+}
@@ -0,0 +1,45 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/utils/chain"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testBalanceClient struct {
+	balances map[common.Address]*big.Int
+}
+
+func (c *testBalanceClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return c.balances[account], nil
+}
+
+func TestBalanceWatcher(t *testing.T) {
+	submit := common.HexToAddress("0x01")
+	finalization := common.HexToAddress("0x02")
+	client := &testBalanceClient{balances: map[common.Address]*big.Int{
+		submit:       big.NewInt(100),
+		finalization: big.NewInt(1000),
+	}}
+	w := newBalanceWatcher(client, []balanceAccount{
+		{name: "submit", address: submit},
+		{name: "finalization", address: finalization},
+	}, &config.BalanceConfig{MinBalance: big.NewInt(500)})
+	defer chain.SetLowBalance(submit, false)
+
+	w.check(context.Background())
+	require.True(t, chain.LowBalance(submit))
+	require.False(t, chain.LowBalance(finalization))
+
+	err := chain.SendWithNonce(context.Background(), nil, submit, func(uint64) error { return nil })
+	require.ErrorIs(t, err, chain.ErrLowBalance)
+
+	client.balances[submit] = big.NewInt(500)
+	w.check(context.Background())
+	require.False(t, chain.LowBalance(submit))
+}
@@ -0,0 +1,129 @@
+package shared
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/contracts/fastupdater"
+	"flare-tlc/utils/contracts/registry"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/contracts/system"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pkg/errors"
+)
+
+// Events and methods of a contract binding resolved by name by a module, the
+// listeners filter the events by topic0 and the txs are encoded with the methods
+type abiRequirement struct {
+	contract string
+	metaData *bind.MetaData
+	events   []string
+	methods  []string
+}
+
+// Returns the events and methods needed by the enabled clients
+func abiRequirements(clients *config.ClientsConfig) []abiRequirement {
+	var requirements []abiRequirement
+	if clients.EnabledRegistration {
+		requirements = append(requirements,
+			abiRequirement{
+				contract: SystemsManagerContractName,
+				metaData: system.FlareSystemsManagerMetaData,
+				events:   []string{"VotePowerBlockSelected", "SigningPolicySigned"},
+				methods:  []string{"signNewSigningPolicy", "getVoterSigningPolicySignInfo"},
+			},
+			abiRequirement{
+				contract: RelayContractName,
+				metaData: relay.RelayMetaData,
+				events:   []string{"SigningPolicyInitialized"},
+			},
+			abiRequirement{
+				contract: VoterRegistryContractName,
+				metaData: registry.RegistryMetaData,
+				events:   []string{"VoterRegistered"},
+				methods:  []string{"registerVoter"},
+			},
+		)
+	}
+	if clients.EnabledUptimeVoting {
+		requirements = append(requirements, abiRequirement{
+			contract: SystemsManagerContractName,
+			metaData: system.FlareSystemsManagerMetaData,
+			events:   []string{"SignUptimeVoteEnabled", "UptimeVoteSigned"},
+			methods:  []string{"signUptimeVote"},
+		})
+	}
+	if clients.EnabledRewardSigning {
+		requirements = append(requirements, abiRequirement{
+			contract: SystemsManagerContractName,
+			metaData: system.FlareSystemsManagerMetaData,
+			events:   []string{"UptimeVoteSigned", "RewardsSigned"},
+			methods:  []string{"signRewards"},
+		})
+	}
+	if clients.EnabledProtocolVoting {
+		requirements = append(requirements, abiRequirement{
+			contract: SubmissionContractName,
+			metaData: submission.SubmissionMetaData,
+			methods:  []string{"submit1", "submit2", "submit3", "submitSignatures"},
+		})
+	}
+	if clients.EnabledFinalizer {
+		requirements = append(requirements,
+			abiRequirement{
+				contract: RelayContractName,
+				metaData: relay.RelayMetaData,
+				events:   []string{"SigningPolicyInitialized", "ProtocolMessageRelayed"},
+				methods:  []string{"relay"},
+			},
+			abiRequirement{
+				contract: SubmissionContractName,
+				metaData: submission.SubmissionMetaData,
+				methods:  []string{"submitSignatures"},
+			},
+		)
+	}
+	if clients.EnabledFastUpdates {
+		requirements = append(requirements, abiRequirement{
+			contract: "FastUpdater",
+			metaData: fastupdater.FastUpdaterMetaData,
+			methods:  []string{"submitUpdates"},
+		})
+	}
+	return requirements
+}
+
+// ValidateContractABIs checks at startup that the bindings have the events and methods
+// needed by the enabled clients, so that a missing name is reported before the
+// clients start instead of failing in a listener. All missing names are reported.
+func ValidateContractABIs(clients *config.ClientsConfig) error {
+	return validateABIRequirements(abiRequirements(clients))
+}
+
+func validateABIRequirements(requirements []abiRequirement) error {
+	var missing []string
+	checked := make(map[string]bool)
+	add := func(contract, kind, name string, err error) {
+		key := contract + " " + kind + " " + name
+		if err != nil && !checked[key] {
+			missing = append(missing, key)
+		}
+		checked[key] = true
+	}
+	for _, requirement := range requirements {
+		for _, event := range requirement.events {
+			_, err := chain.EventIDFromMetadata(requirement.metaData, event)
+			add(requirement.contract, "event", event, err)
+		}
+		for _, method := range requirement.methods {
+			_, err := chain.MethodIDFromMetadata(requirement.metaData, method)
+			add(requirement.contract, "method", method, err)
+		}
+	}
+	if len(missing) > 0 {
+		return errors.Errorf("contract bindings do not have %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
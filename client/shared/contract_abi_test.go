@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/utils/contracts/relay"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateContractABIs(t *testing.T) {
+	require.NoError(t, ValidateContractABIs(&config.ClientsConfig{
+		EnabledRegistration:   true,
+		EnabledUptimeVoting:   true,
+		EnabledRewardSigning:  true,
+		EnabledProtocolVoting: true,
+		EnabledFinalizer:      true,
+		EnabledFastUpdates:    true,
+	}))
+
+	err := validateABIRequirements([]abiRequirement{
+		{contract: RelayContractName, metaData: relay.RelayMetaData, events: []string{"SigningPolicyInitialized", "Missing"}},
+		{contract: RelayContractName, metaData: relay.RelayMetaData, events: []string{"Missing"}, methods: []string{"relay", "missing"}},
+	})
+	require.EqualError(t, err, "contract bindings do not have Relay event Missing, Relay method missing")
+}
@@ -0,0 +1,132 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"flare-tlc/config"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/contracts/system"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Storage slot of the implementation address of EIP-1967 proxies
+var eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a8a7c1012dfaeda4e3")
+
+// Reads the deployed code, implemented by ethclient.Client
+type ContractCodeReader interface {
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+	StorageAt(ctx context.Context, contract common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
+type contractBinding struct {
+	name     string
+	address  common.Address
+	metaData *bind.MetaData
+}
+
+func contractBindings(addresses *config.ContractAddresses) []contractBinding {
+	return []contractBinding{
+		{SystemsManagerContractName, addresses.SystemsManager, system.FlareSystemsManagerMetaData},
+		{RelayContractName, addresses.Relay, relay.RelayMetaData},
+		{SubmissionContractName, addresses.Submission, submission.SubmissionMetaData},
+	}
+}
+
+// CheckContractBindings checks that the FlareSystemsManager, Relay and Submission
+// contracts have the methods of the generated bindings. The deployed code does not
+// expose a version, the selectors of the methods are searched in the function
+// dispatcher of the code, or of the implementation if the contract is an EIP-1967 proxy.
+func CheckContractBindings(ctx context.Context, reader ContractCodeReader, addresses *config.ContractAddresses) error {
+	for _, binding := range contractBindings(addresses) {
+		if err := CheckContractBinding(ctx, reader, binding.address, binding.metaData); err != nil {
+			return errors.Wrapf(err, "%s contract %s does not match the bindings", binding.name, binding.address.Hex())
+		}
+	}
+	return nil
+}
+
+// CheckContractBinding returns an error listing the methods of the binding missing in
+// the deployed code of the contract
+func CheckContractBinding(ctx context.Context, reader ContractCodeReader, address common.Address, metaData *bind.MetaData) error {
+	abi, err := metaData.GetAbi()
+	if err != nil {
+		return err
+	}
+	code, err := deployedCode(ctx, reader, address)
+	if err != nil {
+		return err
+	}
+	selectors := pushedSelectors(code)
+
+	var missing []string
+	for _, method := range abi.Methods {
+		if !selectors[binary.BigEndian.Uint32(method.ID)] {
+			missing = append(missing, method.Sig)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.Errorf("missing methods %s, the contract version is not supported", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Returns the code of the contract or of its implementation if it is a proxy
+func deployedCode(ctx context.Context, reader ContractCodeReader, address common.Address) ([]byte, error) {
+	code, err := reader.CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching contract code")
+	}
+	if len(code) == 0 {
+		return nil, errors.New("no contract code at the address")
+	}
+	slot, err := reader.StorageAt(ctx, address, eip1967ImplementationSlot, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching proxy implementation")
+	}
+	implementation := common.BytesToAddress(slot)
+	if bytes.Equal(slot, make([]byte, len(slot))) || implementation == address {
+		return code, nil
+	}
+	code, err = reader.CodeAt(ctx, implementation, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching implementation code")
+	}
+	if len(code) == 0 {
+		return nil, errors.Errorf("no contract code at the implementation %s", implementation.Hex())
+	}
+	return code, nil
+}
+
+// Returns the values of the PUSH1 to PUSH4 instructions of the code, the function
+// dispatcher compares the selector of the calldata with them. Selectors with leading
+// zero bytes are pushed with the shorter instructions.
+func pushedSelectors(code []byte) map[uint32]bool {
+	const push1, push4, push32 = 0x60, 0x63, 0x7f
+
+	selectors := make(map[uint32]bool)
+	for i := 0; i < len(code); i++ {
+		op := code[i]
+		if op < push1 || op > push32 {
+			continue
+		}
+		n := int(op-push1) + 1
+		if op <= push4 && i+n < len(code) {
+			var value uint32
+			for _, b := range code[i+1 : i+1+n] {
+				value = value<<8 | uint32(b)
+			}
+			selectors[value] = true
+		}
+		i += n
+	}
+	return selectors
+}
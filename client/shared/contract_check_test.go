@@ -0,0 +1,82 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/config"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/contracts/system"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testCodeReader struct {
+	code    map[common.Address][]byte
+	storage map[common.Address]common.Hash
+}
+
+func (r *testCodeReader) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return r.code[contract], nil
+}
+
+func (r *testCodeReader) StorageAt(ctx context.Context, contract common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
+	value := r.storage[contract]
+	return value[:], nil
+}
+
+// Dispatcher comparing the selector with each method of the binding except skipped:
+// DUP1 PUSH4 <selector> EQ PUSH2 <jump> JUMPI
+func dispatcherCode(t *testing.T, metaData *bind.MetaData, skipped string) []byte {
+	abi, err := metaData.GetAbi()
+	require.NoError(t, err)
+	code := []byte{0x60, 0x80, 0x60, 0x40, 0x52}
+	for name, method := range abi.Methods {
+		if name == skipped {
+			continue
+		}
+		code = append(code, 0x80, 0x63)
+		code = append(code, method.ID...)
+		code = append(code, 0x14, 0x61, 0x01, 0x00, 0x57)
+	}
+	// the selector bytes in PUSH32 data are not instructions
+	return append(code, 0x7f, 0x63, 0x12, 0x34, 0x56, 0x78)
+}
+
+func TestCheckContractBindings(t *testing.T) {
+	addresses := config.ContractAddresses{
+		SystemsManager: common.HexToAddress("0x01"),
+		Relay:          common.HexToAddress("0x02"),
+		Submission:     common.HexToAddress("0x03"),
+	}
+	implementation := common.HexToAddress("0x04")
+	reader := &testCodeReader{
+		code: map[common.Address][]byte{
+			addresses.SystemsManager: dispatcherCode(t, system.FlareSystemsManagerMetaData, ""),
+			addresses.Relay:          dispatcherCode(t, relay.RelayMetaData, ""),
+			addresses.Submission:     {0x36, 0x3d, 0xf3}, // proxy
+			implementation:           dispatcherCode(t, submission.SubmissionMetaData, ""),
+		},
+		storage: map[common.Address]common.Hash{addresses.Submission: common.BytesToHash(implementation.Bytes())},
+	}
+	require.NoError(t, CheckContractBindings(context.Background(), reader, &addresses))
+
+	reader.code[addresses.Relay] = dispatcherCode(t, relay.RelayMetaData, "relay")
+	err := CheckContractBindings(context.Background(), reader, &addresses)
+	require.ErrorContains(t, err, "Relay contract 0x0000000000000000000000000000000000000002 does not match the bindings: "+
+		"missing methods relay(), the contract version is not supported")
+
+	reader.code[addresses.Relay] = dispatcherCode(t, relay.RelayMetaData, "")
+	delete(reader.code, implementation)
+	err = CheckContractBindings(context.Background(), reader, &addresses)
+	require.ErrorContains(t, err, "no contract code at the implementation 0x0000000000000000000000000000000000000004")
+}
+
+func TestPushedSelectors(t *testing.T) {
+	// PUSH2 0x1234, PUSH4 0x00000001, PUSH1 at the end without data
+	selectors := pushedSelectors([]byte{0x61, 0x12, 0x34, 0x63, 0x00, 0x00, 0x00, 0x01, 0x60})
+	require.Equal(t, map[uint32]bool{0x1234: true, 1: true}, selectors)
+}
@@ -0,0 +1,129 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/config"
+	"flare-tlc/logger"
+	"flare-tlc/utils"
+	"flare-tlc/utils/contracts/contractregistry"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// Names of the contracts in the FlareContractRegistry
+const (
+	SubmissionContractName     = "Submission"
+	SystemsManagerContractName = "FlareSystemsManager"
+	VoterRegistryContractName  = "VoterRegistry"
+	RelayContractName          = "Relay"
+)
+
+type contractRegistryCaller interface {
+	GetContractAddressesByName(opts *bind.CallOpts, names []string) ([]common.Address, error)
+}
+
+// ContractResolver resolves the contract addresses from the FlareContractRegistry,
+// addresses set in the config are used as they are
+type ContractResolver struct {
+	registry   contractRegistryCaller
+	configured config.ContractAddresses
+}
+
+func NewContractResolver(
+	caller bind.ContractCaller, registryAddress common.Address, configured config.ContractAddresses,
+) (*ContractResolver, error) {
+	registry, err := contractregistry.NewFlareContractRegistryCaller(registryAddress, caller)
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating contract registry")
+	}
+	return &ContractResolver{registry: registry, configured: configured}, nil
+}
+
+type namedAddress struct {
+	name    string
+	address *common.Address
+}
+
+func contractAddressFields(addresses *config.ContractAddresses) []namedAddress {
+	return []namedAddress{
+		{SubmissionContractName, &addresses.Submission},
+		{SystemsManagerContractName, &addresses.SystemsManager},
+		{VoterRegistryContractName, &addresses.VoterRegistry},
+		{RelayContractName, &addresses.Relay},
+	}
+}
+
+// Returns the configured addresses, with the addresses not set in the config
+// fetched from the registry
+func (r *ContractResolver) Resolve(ctx context.Context) (config.ContractAddresses, error) {
+	addresses := r.configured
+
+	var unresolved []namedAddress
+	var names []string
+	for _, field := range contractAddressFields(&addresses) {
+		if *field.address == (common.Address{}) {
+			unresolved = append(unresolved, field)
+			names = append(names, field.name)
+		}
+	}
+	if len(names) == 0 {
+		return addresses, nil
+	}
+
+	resolved, err := r.registry.GetContractAddressesByName(&bind.CallOpts{Context: ctx}, names)
+	if err != nil {
+		return addresses, errors.Wrap(err, "error fetching contract addresses from the registry")
+	}
+	if len(resolved) != len(names) {
+		return addresses, errors.Errorf("registry returned %d addresses for %d contracts", len(resolved), len(names))
+	}
+	for i, field := range unresolved {
+		if resolved[i] == (common.Address{}) {
+			return addresses, errors.Errorf("contract %s is not in the registry", field.name)
+		}
+		*field.address = resolved[i]
+	}
+	return addresses, nil
+}
+
+// Re-resolves the addresses every interval until an address differs from current
+// and returns the new addresses. Errors are logged and the resolution is retried
+// in the next interval. Returns ctx.Err() if ctx is done first.
+func (r *ContractResolver) WaitForChange(
+	ctx context.Context, current config.ContractAddresses, interval time.Duration, clock utils.Clock,
+) (config.ContractAddresses, error) {
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			break
+
+		case <-ctx.Done():
+			return current, ctx.Err()
+		}
+
+		addresses, err := r.Resolve(ctx)
+		if err != nil {
+			logger.Warn("Error re-resolving contract addresses: %v", err)
+			continue
+		}
+		if addresses != current {
+			return addresses, nil
+		}
+	}
+}
+
+// Logs the addresses that differ between previous and addresses
+func LogContractAddressChanges(previous, addresses config.ContractAddresses) {
+	previousFields := contractAddressFields(&previous)
+	for i, field := range contractAddressFields(&addresses) {
+		if *field.address != *previousFields[i].address {
+			logger.Warn("Contract %s address changed from %s to %s", field.name, previousFields[i].address.Hex(), field.address.Hex())
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/config"
+	"flare-tlc/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+type testContractRegistry struct {
+	addresses map[string]common.Address
+	mu        sync.Mutex
+}
+
+func (r *testContractRegistry) GetContractAddressesByName(opts *bind.CallOpts, names []string) ([]common.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]common.Address, len(names))
+	for i, name := range names {
+		result[i] = r.addresses[name]
+	}
+	return result, nil
+}
+
+func (r *testContractRegistry) set(name string, address common.Address) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addresses[name] = address
+}
+
+func TestContractResolver(t *testing.T) {
+	registry := &testContractRegistry{addresses: map[string]common.Address{
+		SubmissionContractName:     common.HexToAddress("0x01"),
+		SystemsManagerContractName: common.HexToAddress("0x02"),
+		VoterRegistryContractName:  common.HexToAddress("0x03"),
+		RelayContractName:          common.HexToAddress("0x04"),
+	}}
+	resolver := &ContractResolver{
+		registry:   registry,
+		configured: config.ContractAddresses{Relay: common.HexToAddress("0x14")},
+	}
+
+	addresses, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, config.ContractAddresses{
+		Submission:     common.HexToAddress("0x01"),
+		SystemsManager: common.HexToAddress("0x02"),
+		VoterRegistry:  common.HexToAddress("0x03"),
+		Relay:          common.HexToAddress("0x14"),
+	}, addresses)
+
+	registry.set(SubmissionContractName, common.Address{})
+	_, err = resolver.Resolve(context.Background())
+	require.ErrorContains(t, err, "contract Submission is not in the registry")
+}
+
+func TestContractResolverWaitForChange(t *testing.T) {
+	registry := &testContractRegistry{addresses: map[string]common.Address{
+		SubmissionContractName:     common.HexToAddress("0x01"),
+		SystemsManagerContractName: common.HexToAddress("0x02"),
+		VoterRegistryContractName:  common.HexToAddress("0x03"),
+		RelayContractName:          common.HexToAddress("0x04"),
+	}}
+	resolver := &ContractResolver{registry: registry}
+	current, err := resolver.Resolve(context.Background())
+	require.NoError(t, err)
+
+	clock := utils.NewFakeClock(time.Unix(0, 0))
+	type result struct {
+		addresses config.ContractAddresses
+		err       error
+	}
+	results := make(chan result, 1)
+	go func() {
+		addresses, err := resolver.WaitForChange(context.Background(), current, time.Minute, clock)
+		results <- result{addresses, err}
+	}()
+
+	// unchanged addresses are ignored
+	clock.BlockUntilWaiters(1)
+	clock.Advance(time.Minute)
+
+	registry.set(RelayContractName, common.HexToAddress("0x24"))
+	clock.Advance(time.Minute)
+
+	r := <-results
+	require.NoError(t, r.err)
+	require.Equal(t, common.HexToAddress("0x24"), r.addresses.Relay)
+	require.Equal(t, current.Submission, r.addresses.Submission)
+}
@@ -0,0 +1,80 @@
+package shared
+
+import "github.com/ethereum/go-ethereum/common"
+
+// EventID is the identity of an event, the transaction hash and the log index are
+// unique in the indexer database and on chain
+type EventID struct {
+	TxHash   common.Hash
+	LogIndex uint64
+}
+
+// DeliveredEvents are the events delivered by a listener reading the subscription
+// and the indexer database, each event is emitted once whichever source has it first.
+// The events up to the floor were confirmed by the database, of the later events the
+// identities are kept. Only database rows advance the database range, an event
+// received first from the subscription does not skip the rows indexed later. After a
+// restart from the checkpoint all events with its timestamp count as delivered.
+type DeliveredEvents struct {
+	// events with timestamps up to floor were delivered
+	floor int64
+	// timestamp of the last database row, its rows are fetched again
+	confirmed int64
+	// timestamps of the delivered events after floor
+	ids map[EventID]int64
+}
+
+func NewDeliveredEvents(rangeStart int64) *DeliveredEvents {
+	return &DeliveredEvents{floor: rangeStart, confirmed: rangeStart, ids: make(map[EventID]int64)}
+}
+
+// RangeStart returns the start of the database range (exclusive) with the rows that
+// were not confirmed
+func (d *DeliveredEvents) RangeStart() int64 {
+	if d.confirmed > d.floor {
+		return d.confirmed - 1
+	}
+	return d.floor
+}
+
+// IsNew returns true if the event was not delivered yet
+func (d *DeliveredEvents) IsNew(id EventID, timestamp int64) bool {
+	if timestamp <= d.floor {
+		return false
+	}
+	_, ok := d.ids[id]
+	return !ok
+}
+
+// Add returns false if the event was already delivered, otherwise records it
+func (d *DeliveredEvents) Add(id EventID, timestamp int64) bool {
+	if !d.IsNew(id, timestamp) {
+		return false
+	}
+	d.ids[id] = timestamp
+	return true
+}
+
+// Confirm records a database row with the timestamp. The indexer adds the rows in
+// timestamp order, the events before the timestamp are not fetched again.
+func (d *DeliveredEvents) Confirm(timestamp int64) {
+	if timestamp <= d.confirmed {
+		return
+	}
+	d.confirmed = timestamp
+	if timestamp-1 > d.floor {
+		d.floor = timestamp - 1
+		for id, ts := range d.ids {
+			if ts <= d.floor {
+				delete(d.ids, id)
+			}
+		}
+	}
+}
+
+// Rewind moves the database range back to the timestamp, e.g. after a reorg. The
+// events after the floor are still delivered once.
+func (d *DeliveredEvents) Rewind(timestamp int64) {
+	d.floor = min(d.floor, timestamp)
+	d.confirmed = min(d.confirmed, timestamp)
+}
@@ -0,0 +1,47 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliveredEvents(t *testing.T) {
+	a := EventID{common.HexToHash("0x01"), 0}
+	b := EventID{common.HexToHash("0x01"), 1}
+	c := EventID{common.HexToHash("0x02"), 0}
+	d := EventID{common.HexToHash("0x03"), 0}
+
+	delivered := NewDeliveredEvents(100)
+	require.EqualValues(t, 100, delivered.RangeStart())
+	require.False(t, delivered.Add(a, 100))
+	require.True(t, delivered.Add(a, 150))
+	require.False(t, delivered.Add(a, 150))
+
+	// an event from the subscription does not advance the database range, an earlier
+	// row indexed later is delivered
+	require.EqualValues(t, 100, delivered.RangeStart())
+	require.True(t, delivered.Add(c, 120))
+	delivered.Confirm(120)
+	require.EqualValues(t, 119, delivered.RangeStart())
+
+	// rows with the timestamp of the last confirmed one are fetched again
+	require.False(t, delivered.Add(a, 150))
+	delivered.Confirm(150)
+	require.EqualValues(t, 149, delivered.RangeStart())
+	require.True(t, delivered.Add(b, 150))
+	require.False(t, delivered.Add(d, 120))
+	require.False(t, delivered.Add(b, 150))
+
+	// after a rewind the delivered events are not emitted again
+	delivered.Rewind(130)
+	require.EqualValues(t, 130, delivered.RangeStart())
+	require.False(t, delivered.Add(a, 150))
+	require.True(t, delivered.Add(d, 140))
+
+	// after a restart all events with the checkpoint timestamp were delivered
+	restarted := NewDeliveredEvents(200)
+	require.False(t, restarted.Add(c, 200))
+	require.False(t, restarted.Add(d, 200))
+}
@@ -4,6 +4,7 @@ import (
 	"flare-tlc/utils"
 	"flare-tlc/utils/contracts/relay"
 	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/epoch"
 	"time"
 )
 
@@ -37,17 +38,57 @@ func VotingEpochFromChain(fsm *system.FlareSystemsManager) (*utils.Epoch, error)
 	), nil
 }
 
-// Returns the voting and reward epochs from the relay contract
-func EpochsFromChain(relay *relay.Relay) (*utils.Epoch, *utils.IntEpoch, error) {
+// Returns the schedule of the voting rounds and reward epochs from the relay contract
+func ScheduleFromChain(relay *relay.Relay) (*epoch.Schedule, error) {
 	sd, err := relay.StateData(nil)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	return utils.NewEpoch(
+	return epoch.NewSchedule(
+		utils.NewEpoch(
 			time.Unix(int64(sd.FirstVotingRoundStartTs), 0),
 			time.Duration(sd.VotingEpochDurationSeconds)*time.Second,
-		), utils.NewIntEpoch(
+		),
+		utils.NewIntEpoch(
 			int64(sd.FirstRewardEpochStartVotingRoundId),
 			int64(sd.RewardEpochDurationInVotingEpochs),
-		), nil
+		),
+	), nil
+}
+
+// RefreshRewardEpoch adds the duration of the next reward epoch, announced by the
+// expected end of the current one, to the schedule. Returns true if the schedule changed.
+func RefreshRewardEpoch(fsm *system.FlareSystemsManager, epoch *utils.Epoch) (bool, error) {
+	current, err := fsm.GetCurrentRewardEpochId(nil)
+	if err != nil {
+		return false, err
+	}
+	expectedEnd, err := fsm.CurrentRewardEpochExpectedEndTs(nil)
+	if err != nil {
+		return false, err
+	}
+	epochPeriod, err := fsm.RewardEpochDurationSeconds(nil)
+	if err != nil {
+		return false, err
+	}
+	return epoch.AddChange(
+		current.Int64()+1,
+		time.Unix(int64(expectedEnd), 0),
+		time.Duration(epochPeriod)*time.Second,
+	)
+}
+
+// RefreshVotingEpoch adds a changed voting epoch duration to the schedule, it applies
+// from the next voting epoch. Returns true if the schedule changed.
+func RefreshVotingEpoch(fsm *system.FlareSystemsManager, epoch *utils.Epoch) (bool, error) {
+	current, err := fsm.GetCurrentVotingEpochId(nil)
+	if err != nil {
+		return false, err
+	}
+	epochPeriod, err := fsm.VotingEpochDurationSeconds(nil)
+	if err != nil {
+		return false, err
+	}
+	next := int64(current) + 1
+	return epoch.AddChange(next, epoch.StartTime(next), time.Duration(epochPeriod)*time.Second)
 }
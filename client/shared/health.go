@@ -0,0 +1,137 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"flare-tlc/utils/chain"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"gorm.io/gorm"
+)
+
+const (
+	healthCheckTimeout = 5 * time.Second
+
+	healthCheckOk = "ok"
+)
+
+// Checks a subsystem (e.g. db or rpc connectivity), returns nil if the subsystem is healthy
+type HealthCheck func(ctx context.Context) error
+
+var (
+	healthChecks   = make(map[string]HealthCheck)
+	healthChecksMu sync.RWMutex
+
+	// last event timestamp per listener, see RecordListenerEvent
+	listenerEvents   = make(map[string]int64)
+	listenerEventsMu sync.RWMutex
+)
+
+type healthReport struct {
+	Status           string            `json:"status"`
+	Checks           map[string]string `json:"checks"`
+	ListenerEvents   map[string]int64  `json:"listener_last_event,omitempty"`
+	LastSuccessfulTx int64             `json:"last_successful_tx,omitempty"`
+}
+
+// RegisterHealthCheck adds a subsystem check to the /healthz and /readyz endpoints.
+// A check registered under an existing name replaces the previous one.
+func RegisterHealthCheck(name string, check HealthCheck) {
+	healthChecksMu.Lock()
+	defer healthChecksMu.Unlock()
+
+	healthChecks[name] = check
+}
+
+// RegisterConnectivityChecks registers db and eth rpc connectivity checks, the db
+// check only if db is set
+func RegisterConnectivityChecks(db *gorm.DB, ethClient *ethclient.Client) {
+	if db != nil {
+		RegisterHealthCheck("db", func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		})
+	}
+	RegisterHealthCheck("eth_rpc", func(ctx context.Context) error {
+		_, err := ethClient.BlockNumber(ctx)
+		return err
+	})
+}
+
+// Liveness: all subsystem checks pass. A failing check means the client is not able
+// to do its work and should be restarted.
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	report, ok := runHealthChecks(r.Context())
+	writeHealthReport(w, report, ok)
+}
+
+// Readiness: all subsystem checks pass and none of the clients reports an error or
+// is still initializing (see MetricsBase).
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	report, ok := runHealthChecks(r.Context())
+	if ok {
+		statusOk, err := getHealthStatus()
+		if err != nil || !statusOk {
+			ok = false
+			report.Status = "not ready"
+		}
+	}
+	writeHealthReport(w, report, ok)
+}
+
+func runHealthChecks(ctx context.Context) (*healthReport, bool) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	healthChecksMu.RLock()
+	checks := make(map[string]HealthCheck, len(healthChecks))
+	for name, check := range healthChecks {
+		checks[name] = check
+	}
+	healthChecksMu.RUnlock()
+
+	report := &healthReport{
+		Status: healthCheckOk,
+		Checks: make(map[string]string, len(checks)),
+	}
+	ok := true
+	for name, check := range checks {
+		if err := check(ctx); err != nil {
+			report.Checks[name] = err.Error()
+			ok = false
+		} else {
+			report.Checks[name] = healthCheckOk
+		}
+	}
+	if !ok {
+		report.Status = "error"
+	}
+
+	listenerEventsMu.RLock()
+	if len(listenerEvents) > 0 {
+		report.ListenerEvents = make(map[string]int64, len(listenerEvents))
+		for listener, ts := range listenerEvents {
+			report.ListenerEvents[listener] = ts
+		}
+	}
+	listenerEventsMu.RUnlock()
+
+	if lastTx := chain.LastMinedTxTime(); !lastTx.IsZero() {
+		report.LastSuccessfulTx = lastTx.Unix()
+	}
+	return report, ok
+}
+
+func writeHealthReport(w http.ResponseWriter, report *healthReport, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
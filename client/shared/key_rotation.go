@@ -0,0 +1,91 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/logger"
+	"flare-tlc/utils"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/metrics"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const keyRotationPollInterval = 5 * time.Second
+
+var signingPolicyKeyRotated = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: metrics.Namespace,
+	Name:      "signing_policy_key_rotated",
+	Help:      "1 if the staged next signing policy key is active",
+})
+
+// Implemented by the FlareSystemsManager binding
+type CurrentRewardEpochClient interface {
+	GetCurrentRewardEpochId(opts *bind.CallOpts) (*big.Int, error)
+}
+
+// KeyRotationWatcher switches the signing policy signer to the staged next key when
+// the rotation reward epoch starts. All clients share the signer, so every signing
+// code path uses the next key from the same moment on.
+type KeyRotationWatcher struct {
+	signer   *credentials.RotatingSigner
+	client   CurrentRewardEpochClient
+	interval time.Duration
+	clock    utils.Clock
+}
+
+// Returns nil if no rotation is staged
+func NewKeyRotationWatcher(signer *credentials.RotatingSigner, client CurrentRewardEpochClient) *KeyRotationWatcher {
+	if !signer.Staged() {
+		return nil
+	}
+	return &KeyRotationWatcher{
+		signer:   signer,
+		client:   client,
+		interval: keyRotationPollInterval,
+		clock:    utils.RealClock,
+	}
+}
+
+func (w *KeyRotationWatcher) Run(ctx context.Context) error {
+	logger.Info("Waiting for reward epoch %d to switch to signing policy key %s",
+		w.signer.RotationEpoch(), w.signer.Next().Address().Hex())
+
+	ticker := w.clock.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if w.Update(ctx) {
+			return nil
+		}
+
+		select {
+		case <-ticker.C():
+			break
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Update switches to the next key if the rotation reward epoch started, returns true
+// if the next key is active
+func (w *KeyRotationWatcher) Update(ctx context.Context) bool {
+	rewardEpochId, err := w.client.GetCurrentRewardEpochId(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		logger.Warn("Error fetching current reward epoch id for the key rotation: %v", err)
+		return false
+	}
+	if w.signer.SetRewardEpoch(rewardEpochId.Int64()) {
+		logger.Info("Reward epoch %v started, signing with signing policy key %s", rewardEpochId, w.signer.Address().Hex())
+	}
+	if w.signer.Rotated() {
+		signingPolicyKeyRotated.Set(1)
+		return true
+	}
+	signingPolicyKeyRotated.Set(0)
+	return false
+}
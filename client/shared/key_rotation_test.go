@@ -0,0 +1,41 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+type testRewardEpochClient struct {
+	rewardEpochId int64
+}
+
+func (c *testRewardEpochClient) GetCurrentRewardEpochId(*bind.CallOpts) (*big.Int, error) {
+	return big.NewInt(c.rewardEpochId), nil
+}
+
+func TestKeyRotationWatcher(t *testing.T) {
+	currentKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	nextKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	next := credentials.NewPrivateKeySigner(nextKey)
+
+	require.Nil(t, NewKeyRotationWatcher(credentials.NewRotatingSigner(credentials.NewPrivateKeySigner(currentKey), nil, 0), nil))
+
+	signer := credentials.NewRotatingSigner(credentials.NewPrivateKeySigner(currentKey), next, 5)
+	client := &testRewardEpochClient{rewardEpochId: 4}
+	w := NewKeyRotationWatcher(signer, client)
+
+	require.False(t, w.Update(context.Background()))
+	require.NotEqual(t, next.Address(), signer.Address())
+
+	client.rewardEpochId = 5
+	require.True(t, w.Update(context.Background()))
+	require.Equal(t, next.Address(), signer.Address())
+}
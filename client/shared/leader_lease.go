@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/database"
+	"flare-tlc/logger"
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/metrics"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+// The leader stops sending a tenth of the lease duration before the lease expires
+const leaseMarginDivisor = 10
+
+var leaderGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: metrics.Namespace,
+	Name:      "leader",
+	Help:      "1 if the instance holds the leader lease and sends transactions, 0 on standby",
+})
+
+// LeaderLease elects the instance sending the transactions among redundant instances
+// of the same identity, see config.LeaderElectionConfig. The instance is on standby
+// (chain.Standby) while another instance holds the lease. The leader stops sending a
+// tenth of the lease duration before its lease expires, also if the lease can not be
+// renewed, e.g. while the database is unreachable, so the clocks of the instances must
+// be synchronized within the margin. A tx that is still pending when the lease expires
+// may be sent again by the new leader, the contracts reject the duplicate
+// finalizations and registrations.
+type LeaderLease struct {
+	db            *gorm.DB
+	name          string
+	instanceId    string
+	leaseDuration time.Duration
+	renewInterval time.Duration
+	now           func() time.Time
+
+	// end of the lease held by the instance, zero on standby
+	leaderUntil time.Time
+}
+
+// NewLeaderLease returns nil if leader election is disabled. The instance is on
+// standby until the first attempt to acquire the lease, which is made before it
+// returns.
+func NewLeaderLease(db *gorm.DB, cfg *config.LeaderElectionConfig, identity common.Address) (*LeaderLease, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if err := db.AutoMigrate(&database.LeaderLease{}); err != nil {
+		return nil, errors.Wrap(err, "error migrating leader lease table")
+	}
+	l := &LeaderLease{
+		db:            db,
+		name:          cfg.Name,
+		instanceId:    cfg.InstanceId,
+		leaseDuration: cfg.LeaseDuration,
+		renewInterval: cfg.RenewInterval,
+		now:           time.Now,
+	}
+	if len(l.name) == 0 {
+		l.name = identity.Hex()
+	}
+	if len(l.instanceId) == 0 {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading hostname for the leader election instance id")
+		}
+		l.instanceId = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	chain.SetStandby(true)
+	l.update()
+	return l, nil
+}
+
+// Renews or acquires the lease every renew interval until the context is cancelled,
+// the lease is released on shutdown so that a standby instance takes over immediately
+func (l *LeaderLease) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.renewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.update()
+
+		case <-ctx.Done():
+			if l.Leader() {
+				if err := database.ReleaseLeaderLease(l.db, l.name, l.instanceId); err != nil {
+					logger.Warn("Error releasing leader lease %s: %v", l.name, err)
+				}
+			}
+			logger.Info("Leader election stopped")
+			return ctx.Err()
+		}
+	}
+}
+
+// Leader returns true if the instance holds the lease
+func (l *LeaderLease) Leader() bool {
+	return !l.leaderUntil.IsZero()
+}
+
+func (l *LeaderLease) update() {
+	now := l.now()
+	expiresAt := now.Add(l.leaseDuration)
+	acquired, err := database.AcquireLeaderLease(l.db, l.name, l.instanceId, now.UnixMilli(), expiresAt.UnixMilli())
+	switch {
+	case err != nil && l.Leader() && now.Before(l.leaderUntil):
+		logger.Warn("Error renewing leader lease %s, sending transactions until %s: %v",
+			l.name, l.leaderUntil.UTC().Format(time.RFC3339), err)
+		return
+	case err != nil:
+		logger.Warn("Error acquiring leader lease %s: %v", l.name, err)
+		l.setLeader(time.Time{})
+	case acquired:
+		l.setLeader(expiresAt)
+	default:
+		l.setLeader(time.Time{})
+	}
+}
+
+func (l *LeaderLease) setLeader(until time.Time) {
+	wasLeader := l.Leader()
+	l.leaderUntil = until
+	switch {
+	case l.Leader() && !wasLeader:
+		logger.Info("Instance %s acquired leader lease %s, sending transactions", l.instanceId, l.name)
+	case !l.Leader() && wasLeader:
+		logger.Warn("Instance %s lost leader lease %s, on standby", l.instanceId, l.name)
+	case !l.Leader():
+		if lease, err := database.FetchLeaderLease(l.db, l.name); err == nil && lease != nil {
+			logger.Debug("Leader lease %s is held by %s until %s, on standby", l.name, lease.Holder,
+				time.UnixMilli(lease.ExpiresAt).UTC().Format(time.RFC3339))
+		}
+	}
+	if l.Leader() {
+		chain.SetLeaderUntil(l.leaderUntil.Add(-l.leaseDuration/leaseMarginDivisor), l.now)
+		leaderGauge.Set(1)
+	} else {
+		chain.SetStandby(true)
+		leaderGauge.Set(0)
+	}
+}
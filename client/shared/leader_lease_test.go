@@ -0,0 +1,95 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/database"
+	"flare-tlc/utils/chain"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestLeaderLease(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	defer chain.SetStandby(false)
+
+	now := time.Unix(1000, 0)
+	newLease := func(instanceId string) *LeaderLease {
+		cfg := &config.LeaderElectionConfig{
+			Enabled:       true,
+			InstanceId:    instanceId,
+			LeaseDuration: 30 * time.Second,
+			RenewInterval: 10 * time.Second,
+		}
+		lease, err := NewLeaderLease(db, cfg, common.HexToAddress("0x01"))
+		require.NoError(t, err)
+		lease.now = func() time.Time { return now }
+		lease.update()
+		return lease
+	}
+
+	first := newLease("first")
+	require.True(t, first.Leader())
+	require.False(t, chain.Standby())
+
+	// the leader stops sending before the lease expires without a renewal
+	now = now.Add(26 * time.Second)
+	require.False(t, chain.Standby())
+	now = now.Add(time.Second)
+	require.True(t, chain.Standby())
+	now = now.Add(-27 * time.Second)
+
+	// the lease is held by the first instance
+	lease := &LeaderLease{
+		db:            db,
+		name:          first.name,
+		instanceId:    "second",
+		leaseDuration: 30 * time.Second,
+		renewInterval: 10 * time.Second,
+		now:           func() time.Time { return now },
+	}
+	lease.update()
+	require.False(t, lease.Leader())
+	require.True(t, chain.Standby())
+	err = chain.NewNonceManager().Send(context.Background(), nil, common.HexToAddress("0x02"), func(uint64) error { return nil })
+	require.ErrorIs(t, err, chain.ErrStandby)
+
+	// renewed by the leader
+	now = now.Add(20 * time.Second)
+	first.update()
+	require.True(t, first.Leader())
+	now = now.Add(20 * time.Second)
+	lease.update()
+	require.False(t, lease.Leader())
+
+	// taken over when the lease expires
+	now = now.Add(11 * time.Second)
+	lease.update()
+	require.True(t, lease.Leader())
+	first.update()
+	require.False(t, first.Leader())
+	stored, err := database.FetchLeaderLease(db, first.name)
+	require.NoError(t, err)
+	require.Equal(t, "second", stored.Holder)
+
+	// released on shutdown, acquired by the other instance immediately
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, lease.Run(ctx), context.Canceled)
+	first.update()
+	require.True(t, first.Leader())
+
+	disabled, err := NewLeaderLease(db, &config.LeaderElectionConfig{}, common.HexToAddress("0x01"))
+	require.NoError(t, err)
+	require.Nil(t, disabled)
+}
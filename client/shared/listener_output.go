@@ -0,0 +1,95 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/logger"
+	"flare-tlc/utils/metrics"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	listenerSendLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "listener_send_lag_seconds",
+		Help:      "Time the last event of the listener waited for the consumer",
+	}, []string{"listener"})
+	listenerDroppedEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "listener_dropped_events_total",
+		Help:      "Buffered events of the listener dropped since the consumer did not keep up",
+	}, []string{"listener"})
+)
+
+// Buffers of the event channels of the listeners by listener name, see config.ListenersConfig.Buffers
+type ListenerBuffers map[string]config.ListenerBuffer
+
+// Buffer returns the buffer of the listener, defaultSize if the size is not configured
+func (b ListenerBuffers) Buffer(listener string, defaultSize int) config.ListenerBuffer {
+	buffer := b[listener]
+	if buffer.Size == 0 {
+		buffer.Size = defaultSize
+	}
+	return buffer
+}
+
+// ListenerOutput is the event channel of a listener. If the buffer is full, Send waits
+// for the consumer or drops the oldest buffered event.
+type ListenerOutput[T any] struct {
+	listener   string
+	ch         chan T
+	dropOldest bool
+}
+
+func NewListenerOutput[T any](listener string, buffer config.ListenerBuffer) *ListenerOutput[T] {
+	return &ListenerOutput[T]{
+		listener:   listener,
+		ch:         make(chan T, buffer.Size),
+		dropOldest: buffer.DropOldest && buffer.Size > 0,
+	}
+}
+
+// C returns the channel the events are received from
+func (o *ListenerOutput[T]) C() <-chan T {
+	return o.ch
+}
+
+// Send sends the event to the consumer, unless ctx is done first. Returns false if
+// ctx is done.
+func (o *ListenerOutput[T]) Send(ctx context.Context, value T) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case o.ch <- value:
+		listenerSendLag.WithLabelValues(o.listener).Set(0)
+		return true
+	default:
+	}
+
+	if o.dropOldest {
+		for {
+			select {
+			case <-o.ch:
+				listenerDroppedEvents.WithLabelValues(o.listener).Inc()
+				logger.Warn("Buffer of the %s listener is full, dropped the oldest event", o.listener)
+			default:
+			}
+			select {
+			case o.ch <- value:
+				return true
+			case <-ctx.Done():
+				return false
+			default:
+			}
+		}
+	}
+
+	start := time.Now()
+	sent := SendWithContext(ctx, o.ch, value)
+	listenerSendLag.WithLabelValues(o.listener).Set(time.Since(start).Seconds())
+	return sent
+}
@@ -0,0 +1,46 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerOutputDropOldest(t *testing.T) {
+	out := NewListenerOutput[int]("test_drop_oldest", config.ListenerBuffer{Size: 2, DropOldest: true})
+	for i := 1; i <= 4; i++ {
+		require.True(t, out.Send(context.Background(), i))
+	}
+	require.Equal(t, 3, <-out.C())
+	require.Equal(t, 4, <-out.C())
+	require.Equal(t, 2.0, testutil.ToFloat64(listenerDroppedEvents.WithLabelValues("test_drop_oldest")))
+}
+
+func TestListenerOutputBlock(t *testing.T) {
+	out := NewListenerOutput[int]("test_block", config.ListenerBuffer{Size: 1})
+	require.True(t, out.Send(context.Background(), 1))
+
+	// the full buffer blocks until the consumer receives
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		<-out.C()
+	}()
+	require.True(t, out.Send(context.Background(), 2))
+	require.Equal(t, 2, <-out.C())
+	require.Greater(t, testutil.ToFloat64(listenerSendLag.WithLabelValues("test_block")), 0.0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require.True(t, out.Send(ctx, 3))
+	cancel()
+	require.False(t, out.Send(ctx, 4))
+}
+
+func TestListenerBuffers(t *testing.T) {
+	buffers := ListenerBuffers{config.ListenerUptimeVoteSigned: {Size: 5, DropOldest: true}}
+	require.Equal(t, config.ListenerBuffer{Size: 5, DropOldest: true}, buffers.Buffer(config.ListenerUptimeVoteSigned, 10))
+	require.Equal(t, config.ListenerBuffer{Size: 10}, buffers.Buffer(config.ListenerSigningPolicyInitialized, 10))
+}
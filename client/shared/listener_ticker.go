@@ -0,0 +1,102 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"flare-tlc/utils"
+	"flare-tlc/utils/metrics"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	listenerSkippedTicks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "listener_skipped_ticks_total",
+		Help:      "Ticks of the polling listener skipped because the previous poll was still running",
+	}, []string{"listener"})
+	listenerPollOverrun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "listener_poll_overrun_seconds",
+		Help:      "Time the last poll of the listener ran longer than the listener interval",
+	}, []string{"listener"})
+)
+
+// Polling intervals of the listeners by listener name, see config.ListenersConfig.Intervals
+type ListenerIntervals map[string]time.Duration
+
+// Interval returns the interval of the listener, without one the submitted signatures
+// are polled every ListenerInterval and the events every EventListenerInterval
+func (i ListenerIntervals) Interval(listener string) time.Duration {
+	if interval, ok := i[listener]; ok && interval > 0 {
+		return interval
+	}
+	if listener == config.ListenerSubmitSignatures {
+		return ListenerInterval
+	}
+	return EventListenerInterval
+}
+
+// ListenerTicker delivers the ticks of a polling listener. A tick that fired while the
+// previous poll was still running is skipped instead of starting the next poll right
+// away, so that a slow database is not queried back to back.
+type ListenerTicker struct {
+	listener string
+	interval time.Duration
+	clock    utils.Clock
+	ticker   utils.Ticker
+
+	// time of the tick of the running poll, zero before the first tick
+	lastTick time.Time
+}
+
+func NewListenerTicker(clock utils.Clock, listener string, interval time.Duration) *ListenerTicker {
+	return &ListenerTicker{
+		listener: listener,
+		interval: interval,
+		clock:    clock,
+		ticker:   clock.NewTicker(interval),
+	}
+}
+
+// Wait is called when the previous poll is done and blocks until the next tick.
+// Returns false if ctx is done.
+func (t *ListenerTicker) Wait(ctx context.Context) bool {
+	return t.WaitLogs(ctx, nil, nil)
+}
+
+// WaitLogs is Wait that calls handle for the logs of the subscription received until
+// the next tick, see SubscribeLogsInBackground. Returns false if ctx is done or handle
+// returns false.
+func (t *ListenerTicker) WaitLogs(ctx context.Context, logs <-chan types.Log, handle func(types.Log) bool) bool {
+	pollEnd := t.clock.Now()
+	if !t.lastTick.IsZero() {
+		listenerPollOverrun.WithLabelValues(t.listener).Set(max(0, (pollEnd.Sub(t.lastTick) - t.interval).Seconds()))
+	}
+	for {
+		select {
+		case tick := <-t.ticker.C():
+			if !t.lastTick.IsZero() && tick.Before(pollEnd) {
+				listenerSkippedTicks.WithLabelValues(t.listener).Inc()
+				continue
+			}
+			t.lastTick = tick
+			return true
+
+		case log := <-logs:
+			if !handle(log) {
+				return false
+			}
+
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (t *ListenerTicker) Stop() {
+	t.ticker.Stop()
+}
@@ -0,0 +1,121 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"flare-tlc/client/config"
+	"flare-tlc/utils"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenerTickerSkipsTicksDuringPoll(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(1000, 0))
+	ticker := NewListenerTicker(clock, "test_skip", time.Second)
+	defer ticker.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	skipped := listenerSkippedTicks.WithLabelValues("test_skip")
+	initial := testutil.ToFloat64(skipped)
+
+	clock.Advance(time.Second)
+	require.True(t, ticker.Wait(ctx))
+
+	// the poll runs for several intervals, the tick fired meanwhile is skipped
+	clock.Advance(3 * time.Second)
+	clock.Advance(100 * time.Millisecond)
+	done := make(chan bool)
+	go func() { done <- ticker.Wait(ctx) }()
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(skipped) == initial+1
+	}, time.Second, time.Millisecond)
+	require.InDelta(t, 2.1, testutil.ToFloat64(listenerPollOverrun.WithLabelValues("test_skip")), 1e-9)
+
+	clock.Advance(time.Second)
+	require.True(t, <-done)
+
+	// a poll within the interval does not skip the next tick
+	clock.Advance(time.Second)
+	require.True(t, ticker.Wait(ctx))
+	require.Equal(t, initial+1, testutil.ToFloat64(skipped))
+	require.Equal(t, float64(0), testutil.ToFloat64(listenerPollOverrun.WithLabelValues("test_skip")))
+
+	cancel()
+	require.False(t, ticker.Wait(ctx))
+}
+
+func TestListenerIntervals(t *testing.T) {
+	var defaults ListenerIntervals
+	require.Equal(t, ListenerInterval, defaults.Interval(config.ListenerSubmitSignatures))
+	require.Equal(t, EventListenerInterval, defaults.Interval(config.ListenerSigningPolicyInitialized))
+
+	cfg := config.ListenersConfig{}
+	cfg.SigningPolicyInitialized.Interval = time.Minute
+	intervals := ListenerIntervals(cfg.Intervals(14))
+	require.Equal(t, 500*time.Millisecond, intervals.Interval(config.ListenerSubmitSignatures))
+	require.Equal(t, time.Minute, intervals.Interval(config.ListenerSigningPolicyInitialized))
+	require.Equal(t, 30*time.Second, intervals.Interval(config.ListenerVotePowerBlockSelected))
+
+	intervals = cfg.Intervals(114)
+	require.Equal(t, time.Second, intervals.Interval(config.ListenerSubmitSignatures))
+	require.Equal(t, EventListenerInterval, intervals.Interval(config.ListenerVotePowerBlockSelected))
+}
+
+// fails the first subscription
+type failingSubscriber struct {
+	logs       chan types.Log
+	subscribed bool
+}
+
+func (s *failingSubscriber) SubscribeFilterLogs(ctx context.Context, _ ethereum.FilterQuery, logs chan<- types.Log) (ethereum.Subscription, error) {
+	if !s.subscribed {
+		s.subscribed = true
+		return nil, errors.New("connection refused")
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			select {
+			case log := <-s.logs:
+				logs <- log
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func TestListenerTickerWaitLogs(t *testing.T) {
+	clock := utils.NewFakeClock(time.Unix(1000, 0))
+	ticker := NewListenerTicker(clock, "test_logs", 2*WSResubscribeInterval)
+	defer ticker.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscriber := &failingSubscriber{logs: make(chan types.Log)}
+	wsLogs := SubscribeLogsInBackground(ctx, clock, subscriber, common.HexToAddress("0x01"), "0x02", "test")
+	var handled []uint
+	done := make(chan bool)
+	go func() {
+		done <- ticker.WaitLogs(ctx, wsLogs, func(log types.Log) bool {
+			handled = append(handled, log.Index)
+			return log.Index < 2
+		})
+	}()
+
+	// the failed subscription is retried, the logs are handled while waiting for the tick
+	clock.BlockUntilWaiters(2)
+	clock.Advance(WSResubscribeInterval)
+	subscriber.logs <- types.Log{Index: 1}
+	subscriber.logs <- types.Log{Index: 2}
+	require.False(t, <-done)
+	require.Equal(t, []uint{1, 2}, handled)
+
+	require.Nil(t, SubscribeLogsInBackground(ctx, clock, nil, common.Address{}, "", "test"))
+}
@@ -2,9 +2,11 @@ package shared
 
 import (
 	"flare-tlc/client/config"
+	"flare-tlc/utils/metrics"
 	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -21,6 +23,39 @@ const (
 	HealthStatusSyncing      HealthStatus = -2
 )
 
+var (
+	listenerLastEvent = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "listener_last_event_timestamp_seconds",
+		Help:      "Timestamp of the last event received by the listener",
+	}, []string{"listener"})
+	listenerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "listener_lag_seconds",
+		Help:      "Delay between the block timestamp of the last event and the time it was received by the listener",
+	}, []string{"listener"})
+	listenerRowsScanned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "listener_rows_scanned_total",
+		Help:      "Number of indexer db rows fetched by the listener",
+	}, []string{"listener"})
+)
+
+// RecordListenerEvent updates the listener metrics for an event with the given block timestamp
+func RecordListenerEvent(listener string, eventTimestamp int64) {
+	listenerLastEvent.WithLabelValues(listener).Set(float64(eventTimestamp))
+	listenerLag.WithLabelValues(listener).Set(float64(time.Now().Unix() - eventTimestamp))
+
+	listenerEventsMu.Lock()
+	listenerEvents[listener] = eventTimestamp
+	listenerEventsMu.Unlock()
+}
+
+// RecordListenerRowsScanned adds the number of indexer db rows fetched by the listener in a tick
+func RecordListenerRowsScanned(listener string, rows int) {
+	listenerRowsScanned.WithLabelValues(listener).Add(float64(rows))
+}
+
 type MetricsBase struct {
 	// status onf the client, see HealthStatus constants
 	status prometheus.Gauge
@@ -51,6 +86,8 @@ func InitMetricsServer(cfg *config.MetricsConfig) {
 
 	r.Path("/metrics").Handler(promhttp.Handler())
 	r.Path("/health").HandlerFunc(healthHandler)
+	r.Path("/healthz").HandlerFunc(livenessHandler)
+	r.Path("/readyz").HandlerFunc(readinessHandler)
 
 	srv := &http.Server{
 		Addr:    cfg.PrometheusAddress,
@@ -0,0 +1,170 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/utils/metrics"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var reorgsDetected = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "reorgs_detected_total",
+	Help:      "Number of reorgs of blocks with processed events, the state of the affected range is rolled back",
+}, []string{"client"})
+
+// Implemented by rpc.Client
+type BlockHashClient interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+type trackedBlock struct {
+	number    uint64
+	hash      common.Hash
+	timestamp int64
+}
+
+// Reorg is the first block with processed events that is no longer canonical
+type Reorg struct {
+	BlockNumber uint64
+	Timestamp   int64
+}
+
+// ReorgTracker records the blocks of the events processed by the listeners and
+// checks that their hashes still match the canonical chain, i.e. that the events
+// were not reorged out. Blocks are tracked until they are depth blocks deep.
+//
+// Canonical hashes are fetched with eth_getBlockByNumber and not computed from the
+// headers, since the hash of Flare blocks covers fields unknown to go-ethereum.
+//
+// A nil tracker does not track anything.
+type ReorgTracker struct {
+	client BlockHashClient
+	name   string
+	depth  uint64
+
+	mu      sync.Mutex
+	blocks  map[uint64]trackedBlock
+	rewinds map[string]int64
+}
+
+func NewReorgTracker(client BlockHashClient, name string, depth uint64) *ReorgTracker {
+	return &ReorgTracker{
+		client:  client,
+		name:    name,
+		depth:   depth,
+		blocks:  make(map[uint64]trackedBlock),
+		rewinds: make(map[string]int64),
+	}
+}
+
+// Track records the block of a processed event, blockHash is the hex hash stored
+// by the indexer (without 0x). Events without a block hash are not tracked.
+func (t *ReorgTracker) Track(blockNumber uint64, blockHash string, timestamp int64) {
+	if t == nil || len(blockHash) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.blocks[blockNumber] = trackedBlock{
+		number:    blockNumber,
+		hash:      common.HexToHash(blockHash),
+		timestamp: timestamp,
+	}
+}
+
+// Check compares the tracked blocks with the canonical chain. Returns the first
+// reorged block, or nil if there is none. The reorged block and all later blocks
+// are no longer tracked, they are tracked again when the events are processed again.
+func (t *ReorgTracker) Check(ctx context.Context) (*Reorg, error) {
+	if t == nil {
+		return nil, nil
+	}
+	var head hexutil.Uint64
+	if err := t.client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return nil, errors.Wrap(err, "error fetching block number")
+	}
+
+	for _, block := range t.trackedBlocks() {
+		if uint64(head) >= block.number+t.depth {
+			t.untrack(block.number, false)
+			continue
+		}
+		var header *struct {
+			Hash common.Hash `json:"hash"`
+		}
+		err := t.client.CallContext(ctx, &header, "eth_getBlockByNumber", hexutil.EncodeUint64(block.number), false)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error fetching block %d", block.number)
+		}
+		if header == nil || header.Hash == block.hash {
+			// the node may lag behind the indexer
+			continue
+		}
+		t.untrack(block.number, true)
+		reorgsDetected.WithLabelValues(t.name).Inc()
+		return &Reorg{BlockNumber: block.number, Timestamp: block.timestamp}, nil
+	}
+	return nil, nil
+}
+
+// Tracked blocks, order by number
+func (t *ReorgTracker) trackedBlocks() []trackedBlock {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	blocks := make([]trackedBlock, 0, len(t.blocks))
+	for _, block := range t.blocks {
+		blocks = append(blocks, block)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].number < blocks[j].number })
+	return blocks
+}
+
+// Stops tracking the block, and all later blocks if later is true
+func (t *ReorgTracker) untrack(number uint64, later bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for n := range t.blocks {
+		if n == number || later && n > number {
+			delete(t.blocks, n)
+		}
+	}
+}
+
+// Rewind makes the listener continue with the range starting at timestamp, see
+// PendingRewind. Of several pending rewinds the earliest one is used.
+func (t *ReorgTracker) Rewind(listener string, timestamp int64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if pending, ok := t.rewinds[listener]; !ok || timestamp < pending {
+		t.rewinds[listener] = timestamp
+	}
+}
+
+// PendingRewind returns and clears the pending rewind of the listener. The listener
+// should continue with the range starting at the returned timestamp if it is before
+// its current range start.
+func (t *ReorgTracker) PendingRewind(listener string) (int64, bool) {
+	if t == nil {
+		return 0, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timestamp, ok := t.rewinds[listener]
+	delete(t.rewinds, listener)
+	return timestamp, ok
+}
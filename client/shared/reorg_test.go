@@ -0,0 +1,89 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// Chain of blocks with the hashes in the map, missing blocks are not found
+type testBlockHashClient struct {
+	head   uint64
+	hashes map[uint64]common.Hash
+}
+
+func (c *testBlockHashClient) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var value interface{}
+	if method == "eth_blockNumber" {
+		value = hexutil.Uint64(c.head)
+	} else {
+		number, err := hexutil.DecodeUint64(args[0].(string))
+		if err != nil {
+			return err
+		}
+		if hash, ok := c.hashes[number]; ok {
+			value = map[string]interface{}{"hash": hash}
+		}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func TestReorgTracker(t *testing.T) {
+	client := &testBlockHashClient{
+		head: 20,
+		hashes: map[uint64]common.Hash{
+			10: common.HexToHash("0x10"),
+			12: common.HexToHash("0x12"),
+			14: common.HexToHash("0x14"),
+		},
+	}
+	tracker := NewReorgTracker(client, "test", 9)
+	tracker.Track(10, "10", 100)
+	tracker.Track(12, "12", 120)
+	tracker.Track(14, "14", 140)
+	tracker.Track(16, "16", 160) // not yet known by the node
+	tracker.Track(18, "", 180)   // no block hash
+
+	reorg, err := tracker.Check(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, reorg)
+	require.Len(t, tracker.trackedBlocks(), 3, "block 10 is 10 blocks deep")
+
+	client.hashes[12] = common.HexToHash("0x1200")
+	reorg, err = tracker.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &Reorg{BlockNumber: 12, Timestamp: 120}, reorg)
+	require.Empty(t, tracker.trackedBlocks())
+
+	reorg, err = tracker.Check(context.Background())
+	require.NoError(t, err)
+	require.Nil(t, reorg)
+}
+
+func TestReorgTrackerRewind(t *testing.T) {
+	tracker := NewReorgTracker(&testBlockHashClient{}, "test", 8)
+	tracker.Rewind("listener", 200)
+	tracker.Rewind("listener", 100)
+	tracker.Rewind("listener", 150)
+
+	timestamp, ok := tracker.PendingRewind("listener")
+	require.True(t, ok)
+	require.EqualValues(t, 100, timestamp)
+	_, ok = tracker.PendingRewind("listener")
+	require.False(t, ok)
+
+	// nil tracker is disabled
+	var disabled *ReorgTracker
+	disabled.Track(1, "01", 1)
+	disabled.Rewind("listener", 1)
+	_, ok = disabled.PendingRewind("listener")
+	require.False(t, ok)
+}
@@ -0,0 +1,123 @@
+package rpcsource
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	flarelogger "flare-tlc/logger"
+	"flare-tlc/utils"
+	"flare-tlc/utils/metrics"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var logger = flarelogger.Module("rpc_source")
+
+var (
+	indexerLag = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "indexer_lag_seconds",
+		Help:      "Time the latest indexed block is behind the chain head, checked by the indexer fallback",
+	})
+	indexerDegraded = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "indexer_degraded",
+		Help:      "1 while the listeners read from the rpc node because the indexer db lags behind the chain head, 0 otherwise",
+	})
+)
+
+// IndexerMonitor compares the timestamp of the latest indexed block with the chain
+// head. While the indexer is more than maxLag behind, or the indexer db cannot be
+// queried, the listeners read from the rpc source instead, see Degraded. They switch
+// back when the indexer catches up.
+//
+// The listeners advance only up to the timestamp of the last processed event, so no
+// events are lost when the source changes between two ticks.
+type IndexerMonitor struct {
+	source *Source
+	// timestamp of the latest indexed block, 0 if nothing is indexed
+	indexed  func() (int64, error)
+	maxLag   time.Duration
+	interval time.Duration
+	clock    utils.Clock
+
+	degraded atomic.Bool
+}
+
+func NewIndexerMonitor(source *Source, indexed func() (int64, error), cfg *config.IndexerFallbackConfig) *IndexerMonitor {
+	return &IndexerMonitor{
+		source:   source,
+		indexed:  indexed,
+		maxLag:   cfg.MaxLag,
+		interval: cfg.CheckInterval,
+		clock:    utils.RealClock,
+	}
+}
+
+// Source of the listeners while the indexer lags
+func (m *IndexerMonitor) Source() *Source {
+	return m.source
+}
+
+// Degraded returns true while the listeners read from the rpc source, false for a
+// nil monitor
+func (m *IndexerMonitor) Degraded() bool {
+	return m != nil && m.degraded.Load()
+}
+
+// Run checks the indexer lag right away and then every interval until ctx is done
+func (m *IndexerMonitor) Run(ctx context.Context) {
+	ticker := m.clock.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.check(ctx)
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (m *IndexerMonitor) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	head, err := m.source.HeadTimestamp(ctx)
+	if err != nil {
+		// the rpc source would fail as well, keep the current source
+		logger.Warn("Error fetching chain head for the indexer lag check: %v", err)
+		return
+	}
+	indexed, err := m.indexed()
+	if err != nil {
+		logger.Warn("Error fetching latest indexed block: %v", err)
+		if m.setDegraded(true) {
+			logger.Warn("Indexer db not available, listeners switched to the rpc node")
+		}
+		return
+	}
+	lag := time.Duration(max(0, head-indexed)) * time.Second
+	indexerLag.Set(lag.Seconds())
+	degraded := lag > m.maxLag
+	if !m.setDegraded(degraded) {
+		return
+	}
+	if degraded {
+		logger.Warn("Indexer is %s behind the chain head (max %s), listeners switched to the rpc node", lag, m.maxLag)
+	} else {
+		logger.Info("Indexer caught up with the chain head (%s behind), listeners switched back to the indexer db", lag)
+	}
+}
+
+// Returns true if the state changed
+func (m *IndexerMonitor) setDegraded(degraded bool) bool {
+	if degraded {
+		indexerDegraded.Set(1)
+	} else {
+		indexerDegraded.Set(0)
+	}
+	return m.degraded.Swap(degraded) != degraded
+}
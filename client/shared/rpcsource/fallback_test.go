@@ -0,0 +1,50 @@
+package rpcsource
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexerMonitor(t *testing.T) {
+	// head block 99 has timestamp 1198
+	chain := newTestChain(100)
+	indexed := int64(1198)
+	var indexedErr error
+	monitor := NewIndexerMonitor(NewSource(chain, &config.RPCSourceConfig{}), func() (int64, error) {
+		return indexed, indexedErr
+	}, &config.IndexerFallbackConfig{MaxLag: 30 * time.Second, CheckInterval: time.Second})
+	ctx := context.Background()
+
+	monitor.check(ctx)
+	require.False(t, monitor.Degraded())
+
+	indexed = 1168
+	monitor.check(ctx)
+	require.False(t, monitor.Degraded())
+
+	indexed = 1167
+	monitor.check(ctx)
+	require.True(t, monitor.Degraded())
+
+	indexed = 1190
+	monitor.check(ctx)
+	require.False(t, monitor.Degraded())
+
+	// the indexer db is not available
+	indexedErr = errors.New("connection refused")
+	monitor.check(ctx)
+	require.True(t, monitor.Degraded())
+
+	indexedErr = nil
+	monitor.check(ctx)
+	require.False(t, monitor.Degraded())
+
+	// nil monitor without the fallback
+	var disabled *IndexerMonitor
+	require.False(t, disabled.Degraded())
+}
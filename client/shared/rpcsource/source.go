@@ -0,0 +1,495 @@
+package rpcsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"flare-tlc/client/config"
+	"flare-tlc/database"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultChunkBlocks = 1000
+
+	// Max number of blocks fetched in one batch request when scanning transactions
+	maxBatchBlocks = 100
+
+	// Blocks scanned by a call before a prefix of the rows is returned, see scanRange
+	defaultCallBlocks = 5000
+
+	// The header cache is cleared when it holds more headers
+	maxCachedHeaders = 10000
+
+	// Timeout of each chunk of a scan
+	fetchTimeout = 1 * time.Minute
+
+	// Value of the unset topics, as stored by the indexer
+	nullTopic = "NULL"
+)
+
+// Implemented by rpc.Client
+type rpcClient interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+	BatchCallContext(ctx context.Context, b []rpc.BatchElem) error
+}
+
+// Source fetches logs and transactions directly from the chain node, as an
+// alternative to the indexer db. It implements the log and transaction queries
+// of the listeners: the timestamp range (from, to] is mapped to the range of
+// confirmed blocks with timestamps in it.
+//
+// Block hashes are taken from the node and not computed from the headers, since
+// the hash of Flare blocks covers fields unknown to go-ethereum.
+type Source struct {
+	client        rpcClient
+	chunkBlocks   uint64
+	callBlocks    uint64
+	confirmations uint64
+
+	mu       sync.Mutex
+	headers  map[uint64]*header
+	progress map[scanKey]scanProgress
+}
+
+type header struct {
+	Number    hexutil.Uint64 `json:"number"`
+	Hash      common.Hash    `json:"hash"`
+	Timestamp hexutil.Uint64 `json:"timestamp"`
+}
+
+type block struct {
+	header
+	Transactions []transaction `json:"transactions"`
+}
+
+type transaction struct {
+	Hash             common.Hash     `json:"hash"`
+	From             common.Address  `json:"from"`
+	To               *common.Address `json:"to"`
+	Input            hexutil.Bytes   `json:"input"`
+	Value            *hexutil.Big    `json:"value"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+}
+
+func NewSource(client rpcClient, cfg *config.RPCSourceConfig) *Source {
+	chunkBlocks := cfg.ChunkBlocks
+	if chunkBlocks == 0 {
+		chunkBlocks = defaultChunkBlocks
+	}
+	return &Source{
+		client:        client,
+		chunkBlocks:   chunkBlocks,
+		callBlocks:    defaultCallBlocks,
+		confirmations: cfg.Confirmations,
+		headers:       make(map[uint64]*header),
+		progress:      make(map[scanKey]scanProgress),
+	}
+}
+
+// Fetch the logs matching address and topic0 from timestamp range (from, to], order by
+// block and log index. Logs are fetched with eth_getLogs in chunks of blocks, a long
+// range may return a prefix of the logs, see scanRange.
+func (s *Source) FetchLogsByAddressAndTopic0(
+	address common.Address, topic0 string, from, to int64,
+) ([]database.Log, error) {
+	var result []database.Log
+	key := scanKey{method: "eth_getLogs", address: address, filter: topic0}
+	err := s.scanRange(key, from, to, s.chunkBlocks, func(ctx context.Context, start, end uint64) (int, error) {
+		var logs []types.Log
+		err := s.client.CallContext(ctx, &logs, "eth_getLogs", map[string]interface{}{
+			"address":   address,
+			"topics":    [][]common.Hash{{common.HexToHash(topic0)}},
+			"fromBlock": hexutil.EncodeUint64(start),
+			"toBlock":   hexutil.EncodeUint64(end),
+		})
+		if err != nil {
+			return 0, errors.Wrapf(err, "error fetching logs of blocks %d-%d", start, end)
+		}
+		count := 0
+		for _, log := range logs {
+			if log.Removed {
+				continue
+			}
+			h, err := s.checkBlock(ctx, log.BlockNumber, log.BlockHash)
+			if err != nil {
+				return 0, err
+			}
+			result = append(result, convertLog(&log, uint64(h.Timestamp)))
+			count++
+		}
+		return count, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Fetch the transactions matching toAddress and the function selector from timestamp
+// range (from, to], order by block and transaction index. The blocks of the range are
+// fetched with all transactions, in batches. A long range may return a prefix of the
+// transactions, see scanRange. Status of the transactions is not set.
+func (s *Source) FetchTransactionsByAddressAndSelector(
+	address common.Address, selector []byte, from, to int64,
+) ([]database.Transaction, error) {
+	var result []database.Transaction
+	key := scanKey{method: "eth_getBlockByNumber", address: address, filter: hexutil.Encode(selector)}
+	err := s.scanRange(key, from, to, min(s.chunkBlocks, maxBatchBlocks), func(ctx context.Context, start, end uint64) (int, error) {
+		blocks := make([]*block, end-start+1)
+		batch := make([]rpc.BatchElem, len(blocks))
+		for i := range batch {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(start + uint64(i)), true},
+				Result: &blocks[i],
+			}
+		}
+		if err := s.client.BatchCallContext(ctx, batch); err != nil {
+			return 0, errors.Wrapf(err, "error fetching blocks %d-%d", start, end)
+		}
+		count := 0
+		for i, b := range blocks {
+			if batch[i].Error != nil {
+				return 0, errors.Wrapf(batch[i].Error, "error fetching block %d", start+uint64(i))
+			}
+			if b == nil {
+				return 0, errors.Errorf("block %d not found", start+uint64(i))
+			}
+			s.addHeader(&b.header)
+			if _, err := s.checkBlock(ctx, uint64(b.Number), b.Hash); err != nil {
+				return 0, err
+			}
+			for j := range b.Transactions {
+				tx := &b.Transactions[j]
+				if tx.To != nil && *tx.To == address && len(tx.Input) >= len(selector) && bytes.Equal(tx.Input[:len(selector)], selector) {
+					result = append(result, convertTransaction(tx, &b.header, selector))
+					count++
+				}
+			}
+		}
+		return count, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Scans the confirmed blocks of the timestamp range (from, to] in chunks of chunkBlocks
+// blocks with scan, which returns the number of matching rows of the chunk. Each chunk
+// has its own timeout. Once rows were found and callBlocks blocks were scanned, the
+// scan stops after the last block with the timestamp of the current block: the rows
+// are a prefix of the range including all rows with the timestamp of the last row, as
+// with database.FetchOptions.MaxRows, and the listener continues after it. Chunks
+// without rows are recorded for the key, a scan of the same range start continues
+// after them, so a long range without rows also advances.
+func (s *Source) scanRange(
+	key scanKey, from, to int64, chunkBlocks uint64, scan func(ctx context.Context, start, end uint64) (int, error),
+) error {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	first, last, ok, err := s.blockRange(ctx, from, to)
+	cancel()
+	if err != nil || !ok {
+		return err
+	}
+	first = s.resumeBlock(key, from, first)
+
+	rows := 0
+	for start := first; start <= last; start += chunkBlocks {
+		end := min(start+chunkBlocks-1, last)
+		done, err := s.scanChunk(key, from, first, start, end, last, &rows, scan)
+		if err != nil || done {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scans the chunk [start, end], done is true if the scan of the range ends with it
+func (s *Source) scanChunk(
+	key scanKey, from int64, first, start, end, last uint64, rows *int, scan func(ctx context.Context, start, end uint64) (int, error),
+) (done bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	count, err := scan(ctx, start, end)
+	if err != nil {
+		return false, err
+	}
+	*rows += count
+	if *rows == 0 {
+		s.saveProgress(key, from, end+1)
+	}
+	if end == last {
+		return true, s.checkCanonical(ctx, last)
+	}
+	if *rows == 0 || end+1-first < s.callBlocks {
+		return false, nil
+	}
+	endHeader, err := s.header(ctx, end)
+	if err != nil {
+		return false, err
+	}
+	next, err := s.header(ctx, end+1)
+	if err != nil {
+		return false, err
+	}
+	if next.Timestamp == endHeader.Timestamp {
+		// the rows with the timestamp of the last block are included
+		return false, nil
+	}
+	return true, s.checkCanonical(ctx, end)
+}
+
+// Query of a scan, the progress of the scans without rows is kept per query
+type scanKey struct {
+	method  string
+	address common.Address
+	filter  string
+}
+
+// Blocks before next were scanned without rows by the scan of the range starting at from
+type scanProgress struct {
+	from int64
+	next uint64
+}
+
+// Returns the first block of the scan of the range starting at from
+func (s *Source) resumeBlock(key scanKey, from int64, first uint64) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if progress, ok := s.progress[key]; ok && progress.from == from && progress.next > first {
+		return progress.next
+	}
+	return first
+}
+
+func (s *Source) saveProgress(key scanKey, from int64, next uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.progress[key] = scanProgress{from: from, next: next}
+}
+
+// HeadTimestamp returns the timestamp of the latest block, confirmed or not
+func (s *Source) HeadTimestamp(ctx context.Context) (int64, error) {
+	var head hexutil.Uint64
+	if err := s.client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return 0, errors.Wrap(err, "error fetching block number")
+	}
+	h, err := s.header(ctx, uint64(head))
+	if err != nil {
+		return 0, err
+	}
+	return int64(h.Timestamp), nil
+}
+
+// Returns the first and the last confirmed block with timestamps in range (from, to],
+// ok is false if there are none
+func (s *Source) blockRange(ctx context.Context, from, to int64) (first, last uint64, ok bool, err error) {
+	if from >= to {
+		return 0, 0, false, nil
+	}
+	var head hexutil.Uint64
+	if err := s.client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+		return 0, 0, false, errors.Wrap(err, "error fetching block number")
+	}
+	if uint64(head) < s.confirmations {
+		return 0, 0, false, nil
+	}
+	latest := uint64(head) - s.confirmations
+
+	latestHeader, err := s.header(ctx, latest)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	if int64(latestHeader.Timestamp) <= from {
+		return 0, 0, false, nil
+	}
+	first, err = s.searchTimestamp(ctx, latest, from)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	last = latest
+	if int64(latestHeader.Timestamp) > to {
+		next, err := s.searchTimestamp(ctx, latest, to)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if next == 0 {
+			return 0, 0, false, nil
+		}
+		last = next - 1
+	}
+	return first, last, first <= last, nil
+}
+
+// Returns the first block up to latest with timestamp after timestamp, the timestamp
+// of latest must be after it
+func (s *Source) searchTimestamp(ctx context.Context, latest uint64, timestamp int64) (uint64, error) {
+	lo, hi := uint64(0), latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		h, err := s.header(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if int64(h.Timestamp) > timestamp {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// Checks that the block hash of a log or transaction matches the header of the
+// canonical chain. On mismatch the cached headers are dropped and an error is
+// returned, the listener retries on the next tick.
+func (s *Source) checkBlock(ctx context.Context, number uint64, hash common.Hash) (*header, error) {
+	h, err := s.header(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if h.Hash == hash {
+		return h, nil
+	}
+	s.resetHeaders()
+	h, err = s.header(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if h.Hash != hash {
+		return nil, errors.Errorf("reorg detected at block %d: hash %s, canonical hash %s", number, hash.Hex(), h.Hash.Hex())
+	}
+	return h, nil
+}
+
+// Checks that the cached header of the block is still canonical, the block range is
+// based on the cached timestamps
+func (s *Source) checkCanonical(ctx context.Context, number uint64) error {
+	h, err := s.fetchHeader(ctx, number)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	cached := s.headers[number]
+	s.mu.Unlock()
+	if cached != nil && cached.Hash != h.Hash {
+		s.resetHeaders()
+		return errors.Errorf("reorg detected at block %d: hash %s, canonical hash %s", number, cached.Hash.Hex(), h.Hash.Hex())
+	}
+	return nil
+}
+
+func (s *Source) header(ctx context.Context, number uint64) (*header, error) {
+	s.mu.Lock()
+	h, ok := s.headers[number]
+	s.mu.Unlock()
+	if ok {
+		return h, nil
+	}
+
+	h, err := s.fetchHeader(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return s.addHeader(h), nil
+}
+
+// Caches the header unless a header of the block is cached, returns the cached header
+func (s *Source) addHeader(h *header) *header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if cached, ok := s.headers[uint64(h.Number)]; ok {
+		return cached
+	}
+	if len(s.headers) >= maxCachedHeaders {
+		s.headers = make(map[uint64]*header)
+	}
+	s.headers[uint64(h.Number)] = h
+	return h
+}
+
+func (s *Source) fetchHeader(ctx context.Context, number uint64) (*header, error) {
+	var h *header
+	err := s.client.CallContext(ctx, &h, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching block %d", number)
+	}
+	if h == nil {
+		return nil, errors.Errorf("block %d not found", number)
+	}
+	return h, nil
+}
+
+// Drops the cached headers and the progress of the scans on a reorg
+func (s *Source) resetHeaders() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.headers = make(map[uint64]*header)
+	s.progress = make(map[scanKey]scanProgress)
+}
+
+// Converts the log to the indexer format: lowercase hex without 0x, unset topics NULL.
+// Transaction holds the block of the log, as loaded with FetchOptions.PreloadBlocks.
+func convertLog(log *types.Log, timestamp uint64) database.Log {
+	topics := [4]string{nullTopic, nullTopic, nullTopic, nullTopic}
+	for i := 0; i < len(log.Topics) && i < len(topics); i++ {
+		topics[i] = hex.EncodeToString(log.Topics[i][:])
+	}
+	return database.Log{
+		Address:         hex.EncodeToString(log.Address[:]),
+		Data:            hex.EncodeToString(log.Data),
+		Topic0:          topics[0],
+		Topic1:          topics[1],
+		Topic2:          topics[2],
+		Topic3:          topics[3],
+		TransactionHash: hex.EncodeToString(log.TxHash[:]),
+		LogIndex:        uint64(log.Index),
+		Timestamp:       timestamp,
+		Transaction: database.Transaction{
+			Hash:        hex.EncodeToString(log.TxHash[:]),
+			BlockNumber: log.BlockNumber,
+			BlockHash:   hex.EncodeToString(log.BlockHash[:]),
+			Timestamp:   timestamp,
+		},
+	}
+}
+
+func convertTransaction(tx *transaction, h *header, selector []byte) database.Transaction {
+	return database.Transaction{
+		Hash:             hex.EncodeToString(tx.Hash[:]),
+		FunctionSig:      hex.EncodeToString(selector),
+		Input:            hex.EncodeToString(tx.Input),
+		BlockNumber:      uint64(h.Number),
+		BlockHash:        hex.EncodeToString(h.Hash[:]),
+		TransactionIndex: uint64(tx.TransactionIndex),
+		FromAddress:      hex.EncodeToString(tx.From[:]),
+		ToAddress:        hex.EncodeToString(tx.To[:]),
+		Value:            bigString(tx.Value),
+		GasPrice:         bigString(tx.GasPrice),
+		Gas:              uint64(tx.Gas),
+		Timestamp:        uint64(h.Timestamp),
+	}
+}
+
+func bigString(b *hexutil.Big) string {
+	if b == nil {
+		return "0"
+	}
+	return b.ToInt().String()
+}
@@ -0,0 +1,229 @@
+package rpcsource
+
+import (
+	"context"
+	"encoding/json"
+	"flare-tlc/client/config"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testAddress  = common.HexToAddress("0x1000")
+	testTopic0   = common.HexToHash("0xaa")
+	testSelector = []byte{1, 2, 3, 4}
+)
+
+// Chain with a block per 2 seconds, block n has timestamp 1000 + 2n
+type testChain struct {
+	blocks []*block
+	logs   []types.Log
+}
+
+func newTestChain(n int) *testChain {
+	c := &testChain{}
+	for i := 0; i < n; i++ {
+		c.blocks = append(c.blocks, &block{header: header{
+			Number:    hexutil.Uint64(i),
+			Hash:      common.BigToHash(big.NewInt(int64(i + 1))),
+			Timestamp: hexutil.Uint64(1000 + 2*i),
+		}})
+	}
+	return c
+}
+
+func (c *testChain) addLog(number uint64, topic0 common.Hash) {
+	c.logs = append(c.logs, types.Log{
+		Address:     testAddress,
+		Topics:      []common.Hash{topic0, common.HexToHash("0x01")},
+		Data:        []byte{byte(number)},
+		BlockNumber: number,
+		BlockHash:   c.blocks[number].Hash,
+		TxHash:      common.BigToHash(big.NewInt(int64(number))),
+	})
+}
+
+func (c *testChain) addTx(number uint64, to common.Address, input []byte) {
+	b := c.blocks[number]
+	b.Transactions = append(b.Transactions, transaction{
+		Hash:             common.BigToHash(big.NewInt(int64(len(b.Transactions)))),
+		From:             common.HexToAddress("0x2000"),
+		To:               &to,
+		Input:            input,
+		Value:            (*hexutil.Big)(big.NewInt(0)),
+		GasPrice:         (*hexutil.Big)(big.NewInt(25)),
+		TransactionIndex: hexutil.Uint64(len(b.Transactions)),
+	})
+}
+
+func (c *testChain) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	var value interface{}
+	switch method {
+	case "eth_blockNumber":
+		value = hexutil.Uint64(len(c.blocks) - 1)
+	case "eth_getBlockByNumber":
+		number, err := hexutil.DecodeUint64(args[0].(string))
+		if err != nil {
+			return err
+		}
+		if number >= uint64(len(c.blocks)) {
+			value = nil
+		} else if args[1].(bool) {
+			value = c.blocks[number]
+		} else {
+			value = c.blocks[number].header
+		}
+	case "eth_getLogs":
+		filter := args[0].(map[string]interface{})
+		from, _ := hexutil.DecodeUint64(filter["fromBlock"].(string))
+		to, _ := hexutil.DecodeUint64(filter["toBlock"].(string))
+		topic0 := filter["topics"].([][]common.Hash)[0][0]
+		logs := []types.Log{}
+		for _, log := range c.logs {
+			if log.Address == filter["address"] && log.Topics[0] == topic0 && log.BlockNumber >= from && log.BlockNumber <= to {
+				logs = append(logs, log)
+			}
+		}
+		value = logs
+	default:
+		return errors.Errorf("unexpected method %s", method)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (c *testChain) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	for i := range b {
+		b[i].Error = c.CallContext(ctx, b[i].Result, b[i].Method, b[i].Args...)
+	}
+	return nil
+}
+
+func TestFetchLogs(t *testing.T) {
+	chain := newTestChain(100)
+	chain.addLog(10, testTopic0)
+	chain.addLog(11, common.HexToHash("0xbb"))
+	chain.addLog(20, testTopic0)
+	chain.addLog(40, testTopic0)
+	chain.addLog(99, testTopic0)
+	source := NewSource(chain, &config.RPCSourceConfig{ChunkBlocks: 7, Confirmations: 1})
+
+	// block 10 has timestamp 1020, block 40 1080
+	logs, err := source.FetchLogsByAddressAndTopic0(testAddress, testTopic0.Hex(), 1020, 1080)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, uint64(1040), logs[0].Timestamp)
+	require.Equal(t, uint64(1080), logs[1].Timestamp)
+
+	log := logs[0]
+	require.Equal(t, "0000000000000000000000000000000000001000", log.Address)
+	require.Equal(t, "00000000000000000000000000000000000000000000000000000000000000aa", log.Topic0)
+	require.Equal(t, "0000000000000000000000000000000000000000000000000000000000000001", log.Topic1)
+	require.Equal(t, "NULL", log.Topic2)
+	require.Equal(t, "14", log.Data)
+
+	// the last block is not confirmed
+	logs, err = source.FetchLogsByAddressAndTopic0(testAddress, testTopic0.Hex(), 1080, 2000)
+	require.NoError(t, err)
+	require.Empty(t, logs)
+
+	logs, err = source.FetchLogsByAddressAndTopic0(testAddress, testTopic0.Hex(), 2000, 3000)
+	require.NoError(t, err)
+	require.Empty(t, logs)
+}
+
+func TestFetchLogsReorg(t *testing.T) {
+	chain := newTestChain(50)
+	chain.addLog(10, testTopic0)
+	source := NewSource(chain, &config.RPCSourceConfig{})
+
+	logs, err := source.FetchLogsByAddressAndTopic0(testAddress, testTopic0.Hex(), 0, 2000)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	// log of a block that is no longer canonical
+	chain.logs[0].BlockHash = common.HexToHash("0xff")
+	_, err = source.FetchLogsByAddressAndTopic0(testAddress, testTopic0.Hex(), 0, 2000)
+	require.ErrorContains(t, err, "reorg detected at block 10")
+
+	// the cached header of the block was replaced
+	chain.blocks[10].Hash = common.HexToHash("0xff")
+	logs, err = source.FetchLogsByAddressAndTopic0(testAddress, testTopic0.Hex(), 0, 2000)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+}
+
+func TestFetchTransactions(t *testing.T) {
+	chain := newTestChain(300)
+	input := append(append([]byte{}, testSelector...), 0xab)
+	chain.addTx(5, testAddress, input)
+	chain.addTx(150, common.HexToAddress("0x3000"), input)
+	chain.addTx(150, testAddress, []byte{1, 2})
+	chain.addTx(150, testAddress, input)
+	chain.addTx(299, testAddress, input)
+	source := NewSource(chain, &config.RPCSourceConfig{})
+
+	txs, err := source.FetchTransactionsByAddressAndSelector(testAddress, testSelector, 1010, 1600)
+	require.NoError(t, err)
+	require.Len(t, txs, 2)
+	require.Equal(t, uint64(150), txs[0].BlockNumber)
+	require.Equal(t, uint64(2), txs[0].TransactionIndex)
+	require.Equal(t, uint64(1300), txs[0].Timestamp)
+	require.Equal(t, "01020304ab", txs[0].Input)
+	require.Equal(t, "01020304", txs[0].FunctionSig)
+	require.Equal(t, "0000000000000000000000000000000000002000", txs[0].FromAddress)
+	require.Equal(t, "25", txs[0].GasPrice)
+	require.Equal(t, uint64(299), txs[1].BlockNumber)
+}
+
+// Counts the full blocks fetched
+type countingChain struct {
+	*testChain
+	fullBlocks int
+}
+
+func (c *countingChain) BatchCallContext(ctx context.Context, b []rpc.BatchElem) error {
+	c.fullBlocks += len(b)
+	return c.testChain.BatchCallContext(ctx, b)
+}
+
+func TestFetchTransactionsPrefix(t *testing.T) {
+	chain := &countingChain{testChain: newTestChain(300)}
+	input := append(append([]byte{}, testSelector...), 0xab)
+	chain.addTx(5, testAddress, input)
+	chain.addTx(250, testAddress, input)
+	source := NewSource(chain, &config.RPCSourceConfig{ChunkBlocks: 10})
+	source.callBlocks = 50
+
+	// a prefix of the range is returned once callBlocks blocks were scanned
+	txs, err := source.FetchTransactionsByAddressAndSelector(testAddress, testSelector, 999, 2000)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, uint64(5), txs[0].BlockNumber)
+	require.Equal(t, 50, chain.fullBlocks)
+
+	// the listener continues after the last tx, the blocks without txs are not scanned again
+	txs, err = source.FetchTransactionsByAddressAndSelector(testAddress, testSelector, int64(txs[0].Timestamp), 2000)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, uint64(250), txs[0].BlockNumber)
+	fullBlocks := chain.fullBlocks
+	txs, err = source.FetchTransactionsByAddressAndSelector(testAddress, testSelector, int64(txs[0].Timestamp), 2000)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+	txs, err = source.FetchTransactionsByAddressAndSelector(testAddress, testSelector, 1500, 2000)
+	require.NoError(t, err)
+	require.Empty(t, txs)
+	require.Equal(t, fullBlocks+49, chain.fullBlocks)
+}
@@ -0,0 +1,95 @@
+package shared
+
+import (
+	"context"
+	"flare-tlc/logger"
+	"flare-tlc/utils"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// How often a listener retries the websocket subscription after falling back to db polling
+	WSResubscribeInterval time.Duration = 1 * time.Minute
+	wsLogBufferSize                     = 16
+)
+
+// Implemented by ethclient.Client connected to a websocket endpoint
+type LogSubscriber interface {
+	SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error)
+}
+
+// SubscribeLogs subscribes to logs of the contract at address with the given topic0 and
+// calls handler for each received log. Blocks until the subscription fails or ctx is done and
+// returns the subscription error. Logs removed due to a reorg are skipped.
+func SubscribeLogs(ctx context.Context, client LogSubscriber, address common.Address, topic0 string, handler func(types.Log)) error {
+	logs := make(chan types.Log, wsLogBufferSize)
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{address},
+		Topics:    [][]common.Hash{{common.HexToHash(topic0)}},
+	}
+	sub, err := client.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case log := <-logs:
+			if log.Removed {
+				continue
+			}
+			handler(log)
+
+		case err := <-sub.Err():
+			return err
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SubscribeLogsInBackground runs SubscribeLogs in its own goroutine, so that the
+// subscription does not block the db polling of the listener, and retries it every
+// WSResubscribeInterval after it failed. The logs are delivered on the returned channel
+// for the listener to handle them in its polling goroutine, see ListenerTicker.WaitLogs.
+// Returns nil if client is nil.
+func SubscribeLogsInBackground(ctx context.Context, clock utils.Clock, client LogSubscriber, address common.Address, topic0 string, event string) <-chan types.Log {
+	if client == nil {
+		return nil
+	}
+	out := make(chan types.Log, wsLogBufferSize)
+	go func() {
+		for {
+			err := SubscribeLogs(ctx, client, address, topic0, func(log types.Log) {
+				SendWithContext(ctx, out, log)
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn("%s subscription failed, falling back to db polling: %v", event, err)
+			select {
+			case <-clock.After(WSResubscribeInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// SendWithContext sends value to out, unless ctx is done first. Returns false if ctx is done.
+func SendWithContext[T any](ctx context.Context, out chan<- T, value T) bool {
+	select {
+	case out <- value:
+		return true
+
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,89 @@
+package shared
+
+import (
+	"flare-tlc/client/admin"
+	"flare-tlc/client/config"
+	"flare-tlc/database"
+	"flare-tlc/logger"
+	"flare-tlc/utils/chain"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// Audit log of the sent txs in the database, see config.TxAuditConfig
+type TxAuditLogDB struct {
+	db *gorm.DB
+}
+
+// NewTxAuditLogDB migrates the audit log table and deletes the entries older than the
+// retention
+func NewTxAuditLogDB(db *gorm.DB, cfg *config.TxAuditConfig) (*TxAuditLogDB, error) {
+	if err := db.AutoMigrate(&database.TxAuditEntry{}); err != nil {
+		return nil, errors.Wrap(err, "error migrating tx audit table")
+	}
+	if cfg.Retention > 0 {
+		if err := database.DeleteTxAuditEntriesBefore(db, time.Now().Add(-cfg.Retention).Unix()); err != nil {
+			return nil, errors.Wrap(err, "error deleting expired tx audit entries")
+		}
+	}
+	return &TxAuditLogDB{db: db}, nil
+}
+
+// Record persists the sent tx, errors are logged as the tx was sent regardless
+func (l *TxAuditLogDB) Record(record *chain.TxAuditRecord) {
+	entry := &database.TxAuditEntry{
+		Operation:    record.Operation,
+		FromAddress:  record.From.Hex(),
+		TxHash:       record.TxHash.Hex(),
+		CalldataHash: record.CalldataHash.Hex(),
+		Nonce:        record.Nonce,
+		GasLimit:     record.GasLimit,
+		GasUsed:      record.GasUsed,
+		Status:       record.Status,
+		BlockNumber:  record.BlockNumber,
+		Error:        record.Error,
+		Timestamp:    uint64(record.Time.Unix()),
+	}
+	if record.To != nil {
+		entry.ToAddress = record.To.Hex()
+	}
+	if record.GasPrice != nil {
+		entry.GasPrice = record.GasPrice.String()
+	}
+	if err := database.CreateTxAuditEntry(l.db, entry); err != nil {
+		logger.Warn("Error recording %s tx %s in the tx audit log: %v", record.Operation, entry.TxHash, err)
+	}
+}
+
+// Transactions returns the txs sent in the time range [from, to), see admin.TxAudit
+func (l *TxAuditLogDB) Transactions(from, to time.Time, operation string, limit int) ([]admin.TxInfo, error) {
+	entries, err := database.FetchTxAuditEntries(l.db, from.Unix(), to.Unix(), operation, limit)
+	if err != nil {
+		return nil, err
+	}
+	txs := make([]admin.TxInfo, len(entries))
+	for i, entry := range entries {
+		txs[i] = txInfoFromAuditEntry(&entry)
+	}
+	return txs, nil
+}
+
+func txInfoFromAuditEntry(entry *database.TxAuditEntry) admin.TxInfo {
+	return admin.TxInfo{
+		Operation:    entry.Operation,
+		From:         entry.FromAddress,
+		To:           entry.ToAddress,
+		TxHash:       entry.TxHash,
+		CalldataHash: entry.CalldataHash,
+		Nonce:        entry.Nonce,
+		GasLimit:     entry.GasLimit,
+		GasPrice:     entry.GasPrice,
+		GasUsed:      entry.GasUsed,
+		Status:       entry.Status,
+		BlockNumber:  entry.BlockNumber,
+		Error:        entry.Error,
+		SentAt:       time.Unix(int64(entry.Timestamp), 0).UTC(),
+	}
+}
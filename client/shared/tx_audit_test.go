@@ -0,0 +1,70 @@
+package shared
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/database"
+	"flare-tlc/utils/chain"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestTxAuditLogDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	txLog, err := NewTxAuditLogDB(db, &config.TxAuditConfig{})
+	require.NoError(t, err)
+	to := common.HexToAddress("0x02")
+	txLog.Record(&chain.TxAuditRecord{
+		Operation:   config.RetryOpRelay,
+		From:        common.HexToAddress("0x01"),
+		To:          &to,
+		TxHash:      common.HexToHash("0xaa"),
+		Nonce:       7,
+		GasLimit:    100_000,
+		GasPrice:    big.NewInt(25_000_000_000),
+		GasUsed:     80_000,
+		Status:      chain.TxStatusMined,
+		BlockNumber: 12,
+		Time:        time.Unix(1000, 0),
+	})
+	txLog.Record(&chain.TxAuditRecord{
+		Operation: config.RetryOpSubmit,
+		TxHash:    common.HexToHash("0xbb"),
+		Status:    chain.TxStatusTimeout,
+		Error:     "waiting for tx to be mined",
+		Time:      time.Unix(1010, 0),
+	})
+
+	txs, err := txLog.Transactions(time.Unix(1000, 0), time.Unix(1010, 0), "", 0)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, to.Hex(), txs[0].To)
+	require.Equal(t, "25000000000", txs[0].GasPrice)
+	require.EqualValues(t, 12, txs[0].BlockNumber)
+	require.Equal(t, time.Unix(1000, 0).UTC(), txs[0].SentAt)
+
+	txs, err = txLog.Transactions(time.Unix(0, 0), time.Unix(2000, 0), config.RetryOpSubmit, 0)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, chain.TxStatusTimeout, txs[0].Status)
+	require.Empty(t, txs[0].To)
+
+	// entries older than the retention are deleted on startup
+	_, err = NewTxAuditLogDB(db, &config.TxAuditConfig{Retention: time.Since(time.Unix(1005, 0))})
+	require.NoError(t, err)
+	var entries []database.TxAuditEntry
+	require.NoError(t, db.Find(&entries).Error)
+	require.Len(t, entries, 1)
+	require.Equal(t, config.RetryOpSubmit, entries[0].Operation)
+}
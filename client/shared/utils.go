@@ -1,7 +1,13 @@
 package shared
 
 import (
+	"context"
+	"errors"
+	"flare-tlc/client/config"
 	"flare-tlc/logger"
+	"flare-tlc/utils/chain"
+	"math"
+	"math/rand"
 	"strings"
 	"time"
 )
@@ -12,24 +18,120 @@ type ExecuteStatus[T any] struct {
 	Value   T
 }
 
+type fatalError struct{ error }
+
+func (e fatalError) Unwrap() error { return e.error }
+
+type alreadyDoneError struct{ error }
+
+func (e alreadyDoneError) Unwrap() error { return e.error }
+
+// Fatal marks err as not retryable.
+func Fatal(err error) error {
+	return fatalError{err}
+}
+
+// AlreadyDone marks err as a sign that the operation was already completed (e.g., by
+// a previous attempt or another client), ExecuteWithRetryPolicy reports success.
+func AlreadyDone(err error) error {
+	return alreadyDoneError{err}
+}
+
+// ExecuteWithRetry executes f up to maxRetries times, with a fixed delay between attempts.
 func ExecuteWithRetry[T any](f func() (T, error), maxRetries int, delay time.Duration) <-chan ExecuteStatus[T] {
+	return ExecuteWithRetryPolicy(f, config.RetryPolicy{
+		MaxRetries:   maxRetries,
+		InitialDelay: delay,
+		MaxDelay:     delay,
+		Multiplier:   1,
+	})
+}
+
+// ExecuteWithRetryPolicy executes f until it succeeds, returns a fatal or already done
+// error, or the retries of the policy are exhausted. A tx not sent on standby
+// (chain.ErrStandby) is reported as done, it is sent by the leader instance.
+func ExecuteWithRetryPolicy[T any](f func() (T, error), policy config.RetryPolicy) <-chan ExecuteStatus[T] {
+	return ExecuteWithRetryPolicyContext(context.Background(), f, policy)
+}
+
+// ExecuteWithRetryPolicyContext is ExecuteWithRetryPolicy that stops retrying when ctx
+// is done
+func ExecuteWithRetryPolicyContext[T any](ctx context.Context, f func() (T, error), policy config.RetryPolicy) <-chan ExecuteStatus[T] {
 	out := make(chan ExecuteStatus[T])
 	go func() {
-		for ri := 0; ri < maxRetries; ri++ {
+		for ri := 0; ri < policy.MaxRetries; ri++ {
+			if ctx.Err() != nil {
+				out <- ExecuteStatus[T]{Success: false, Message: ctx.Err().Error()}
+				return
+			}
 			result, err := f()
 			if err == nil {
 				out <- ExecuteStatus[T]{Success: true, Value: result}
 				return
-			} else {
-				logger.Error("error executing in retry no. %d: %v", ri, err)
 			}
-			time.Sleep(delay)
+
+			switch classifyError(err, &policy) {
+			case errorAlreadyDone:
+				logger.Info("Operation already done: %v", err)
+				out <- ExecuteStatus[T]{Success: true, Value: result}
+				return
+			case errorStandby:
+				logger.Debug("Operation skipped: %v", err)
+				out <- ExecuteStatus[T]{Success: true, Value: result}
+				return
+			case errorFatal:
+				logger.Error("fatal error executing in retry no. %d: %v", ri, err)
+				out <- ExecuteStatus[T]{Success: false, Message: err.Error()}
+				return
+			}
+
+			logger.Error("error executing in retry no. %d: %v", ri, err)
+			if ri < policy.MaxRetries-1 {
+				select {
+				case <-time.After(retryDelay(&policy, ri)):
+				case <-ctx.Done():
+				}
+			}
 		}
 		out <- ExecuteStatus[T]{Success: false, Message: "max retries reached"}
 	}()
 	return out
 }
 
+type errorClass int
+
+const (
+	errorRetryable errorClass = iota
+	errorFatal
+	errorAlreadyDone
+	errorStandby
+)
+
+func classifyError(err error, policy *config.RetryPolicy) errorClass {
+	if errors.Is(err, chain.ErrStandby) {
+		return errorStandby
+	}
+	if errors.As(err, &alreadyDoneError{}) || ExistsAsSubstring(policy.AlreadyDoneErrors, err.Error()) {
+		return errorAlreadyDone
+	}
+	if errors.As(err, &fatalError{}) || ExistsAsSubstring(policy.FatalErrors, err.Error()) {
+		return errorFatal
+	}
+	return errorRetryable
+}
+
+func retryDelay(policy *config.RetryPolicy, retry int) time.Duration {
+	multiplier := math.Max(policy.Multiplier, 1)
+	delay := float64(policy.InitialDelay) * math.Pow(multiplier, float64(retry))
+	if policy.MaxDelay > 0 {
+		delay = math.Min(delay, float64(policy.MaxDelay))
+	}
+	if policy.Jitter > 0 {
+		delay *= 1 + policy.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
 // ExistsAsSubstring returns true if any of the strings in the slice is a substring of s
 func ExistsAsSubstring(slice []string, s string) bool {
 	for _, item := range slice {
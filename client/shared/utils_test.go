@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"errors"
+	"flare-tlc/client/config"
+	"flare-tlc/utils/chain"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteWithRetryPolicy(t *testing.T) {
+	policy := config.RetryPolicy{
+		MaxRetries:        5,
+		InitialDelay:      time.Millisecond,
+		Multiplier:        1,
+		AlreadyDoneErrors: []string{"already signed"},
+		FatalErrors:       []string{"invalid signature"},
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		success  bool
+		attempts int
+	}{
+		{"retryable", errors.New("connection refused"), false, 5},
+		{"already done", errors.New("voter already signed"), true, 1},
+		{"already done wrapped", AlreadyDone(errors.New("done")), true, 1},
+		{"fatal", errors.New("invalid signature"), false, 1},
+		{"fatal wrapped", Fatal(errors.New("bad input")), false, 1},
+		{"standby", fmt.Errorf("not sending tx: %w", chain.ErrStandby), true, 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			status := <-ExecuteWithRetryPolicy(func() (any, error) {
+				attempts++
+				return nil, test.err
+			}, policy)
+			require.Equal(t, test.success, status.Success)
+			require.Equal(t, test.attempts, attempts)
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	policy := config.RetryPolicy{
+		InitialDelay: time.Second,
+		MaxDelay:     5 * time.Second,
+		Multiplier:   2,
+	}
+	require.Equal(t, time.Second, retryDelay(&policy, 0))
+	require.Equal(t, 4*time.Second, retryDelay(&policy, 2))
+	require.Equal(t, 5*time.Second, retryDelay(&policy, 3))
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := retryDelay(&policy, 0)
+		require.GreaterOrEqual(t, delay, 500*time.Millisecond)
+		require.LessOrEqual(t, delay, 1500*time.Millisecond)
+	}
+}
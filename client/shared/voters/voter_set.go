@@ -147,6 +147,10 @@ func (vs *VoterSet) VoterWeight(index int) uint16 {
 	return vs.weights[index]
 }
 
+func (vs *VoterSet) VoterAddress(index int) common.Address {
+	return vs.voters[index]
+}
+
 func (vs *VoterSet) Count() int {
 	return len(vs.voters)
 }
@@ -0,0 +1,102 @@
+package simulation
+
+import (
+	"encoding/json"
+	"flare-tlc/database"
+	"os"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Indexer transactions and logs loaded into the database of a simulation. The JSON
+// fixture files use the field names of database.Transaction and database.Log, e.g.
+// {"logs": [{"ID": 1, "Address": "...", "Topic0": "...", "Data": "...", "Timestamp": 100}]}.
+// Addresses, topics, hashes and data are hex encoded, with or without the 0x prefix.
+type Fixtures struct {
+	Transactions []database.Transaction `json:"transactions"`
+	Logs         []database.Log         `json:"logs"`
+}
+
+// NewMemoryDB opens an in-memory sqlite database with the indexer tables. The
+// database lives as long as the returned handle, the client tables are created by
+// the clients.
+func NewMemoryDB() (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "error opening sqlite database")
+	}
+	// each connection of an in-memory database is a separate database
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(&database.Transaction{}, &database.Log{}); err != nil {
+		return nil, errors.Wrap(err, "error creating indexer tables")
+	}
+	return db, nil
+}
+
+// LoadFixtures inserts the transactions and logs, the hex fields are stored in the
+// format of the indexer, lowercase without the 0x prefix
+func LoadFixtures(db *gorm.DB, fixtures *Fixtures) error {
+	for i := range fixtures.Transactions {
+		tx := fixtures.Transactions[i]
+		tx.Hash = indexerHex(tx.Hash)
+		tx.FunctionSig = indexerHex(tx.FunctionSig)
+		tx.Input = indexerHex(tx.Input)
+		tx.BlockHash = indexerHex(tx.BlockHash)
+		tx.FromAddress = indexerHex(tx.FromAddress)
+		tx.ToAddress = indexerHex(tx.ToAddress)
+		if err := db.Create(&tx).Error; err != nil {
+			return errors.Wrapf(err, "error inserting transaction %d", i)
+		}
+	}
+	for i := range fixtures.Logs {
+		log := fixtures.Logs[i]
+		log.Address = indexerHex(log.Address)
+		log.Data = indexerHex(log.Data)
+		log.Topic0 = indexerHex(log.Topic0)
+		log.Topic1 = indexerTopic(log.Topic1)
+		log.Topic2 = indexerTopic(log.Topic2)
+		log.Topic3 = indexerTopic(log.Topic3)
+		log.TransactionHash = indexerHex(log.TransactionHash)
+		log.Transaction = database.Transaction{}
+		if err := db.Omit("Transaction").Create(&log).Error; err != nil {
+			return errors.Wrapf(err, "error inserting log %d", i)
+		}
+	}
+	return nil
+}
+
+// LoadFixtureFile inserts the transactions and logs of the JSON fixture file, see Fixtures
+func LoadFixtureFile(db *gorm.DB, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "error reading fixture file")
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return errors.Wrapf(err, "error parsing fixture file %s", path)
+	}
+	return LoadFixtures(db, &fixtures)
+}
+
+func indexerHex(s string) string {
+	return strings.ToLower(strings.TrimPrefix(s, "0x"))
+}
+
+// Unused topics are stored as NULL by the indexer
+func indexerTopic(s string) string {
+	if len(s) == 0 || s == "NULL" {
+		return "NULL"
+	}
+	return indexerHex(s)
+}
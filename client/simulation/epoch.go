@@ -0,0 +1,265 @@
+package simulation
+
+import (
+	"context"
+	"flare-tlc/client/epoch"
+	"flare-tlc/client/shared"
+	"flare-tlc/utils"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/system"
+	"flare-tlc/utils/signingpolicy"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// buffered events sent by the simulation before the listeners read them
+const eventBufferSize = 10
+
+// SystemsManagerClient implements epoch.SystemsManagerContractClient without a chain:
+// the events are sent by the simulation and the signatures are recorded
+type SystemsManagerClient struct {
+	rewardEpoch *utils.Epoch
+
+	votePowerBlockSelected chan *system.FlareSystemsManagerVotePowerBlockSelected
+	signUptimeVoteEnabled  chan *system.FlareSystemsManagerSignUptimeVoteEnabled
+	uptimeVoteSigned       chan *system.FlareSystemsManagerUptimeVoteSigned
+
+	mu                   sync.Mutex
+	currentRewardEpochId int64
+	signedPolicies       map[int64][]byte
+	uptimeVotes          map[int64]common.Hash
+	rewardsHashes        map[int64]common.Hash
+
+	// if set, returned by the signing txs and the signatures are not recorded
+	SignErr error
+}
+
+var _ epoch.SystemsManagerContractClient = (*SystemsManagerClient)(nil)
+
+func NewSystemsManagerClient(rewardEpoch *utils.Epoch, currentRewardEpochId int64) *SystemsManagerClient {
+	return &SystemsManagerClient{
+		rewardEpoch:            rewardEpoch,
+		votePowerBlockSelected: make(chan *system.FlareSystemsManagerVotePowerBlockSelected, eventBufferSize),
+		signUptimeVoteEnabled:  make(chan *system.FlareSystemsManagerSignUptimeVoteEnabled, eventBufferSize),
+		uptimeVoteSigned:       make(chan *system.FlareSystemsManagerUptimeVoteSigned, eventBufferSize),
+		currentRewardEpochId:   currentRewardEpochId,
+		signedPolicies:         make(map[int64][]byte),
+		uptimeVotes:            make(map[int64]common.Hash),
+		rewardsHashes:          make(map[int64]common.Hash),
+	}
+}
+
+func (c *SystemsManagerClient) RewardEpochFromChain() (*utils.Epoch, error) {
+	return c.rewardEpoch, nil
+}
+
+// The simulated schedule does not change
+func (c *SystemsManagerClient) RefreshRewardEpoch(epoch *utils.Epoch) (bool, error) {
+	return false, nil
+}
+
+func (c *SystemsManagerClient) VotePowerBlockSelectedListener(
+	context.Context, epoch.EpochClientDB, *utils.Epoch,
+) <-chan *system.FlareSystemsManagerVotePowerBlockSelected {
+	return c.votePowerBlockSelected
+}
+
+func (c *SystemsManagerClient) SignUptimeVoteEnabledListener(
+	context.Context, epoch.EpochClientDB, *utils.Epoch, int64,
+) <-chan *system.FlareSystemsManagerSignUptimeVoteEnabled {
+	return c.signUptimeVoteEnabled
+}
+
+func (c *SystemsManagerClient) UptimeVoteSignedListener(
+	context.Context, epoch.EpochClientDB, *utils.Epoch, int64,
+) <-chan *system.FlareSystemsManagerUptimeVoteSigned {
+	return c.uptimeVoteSigned
+}
+
+func (c *SystemsManagerClient) SignNewSigningPolicy(rewardEpochId *big.Int, policy []byte, _ time.Time) <-chan shared.ExecuteStatus[any] {
+	return c.sign(func() error {
+		if _, ok := c.signedPolicies[rewardEpochId.Int64()]; ok {
+			return errors.New("new signing policy already signed")
+		}
+		c.signedPolicies[rewardEpochId.Int64()] = policy
+		return nil
+	})
+}
+
+func (c *SystemsManagerClient) SignUptimeVote(rewardEpochId *big.Int, hash common.Hash) <-chan shared.ExecuteStatus[any] {
+	return c.sign(func() error {
+		c.uptimeVotes[rewardEpochId.Int64()] = hash
+		return nil
+	})
+}
+
+func (c *SystemsManagerClient) SignRewards(_ context.Context, rewardEpochId *big.Int, hash *common.Hash, _ int) <-chan shared.ExecuteStatus[any] {
+	return c.sign(func() error {
+		c.rewardsHashes[rewardEpochId.Int64()] = *hash
+		return nil
+	})
+}
+
+func (c *SystemsManagerClient) sign(record func() error) <-chan shared.ExecuteStatus[any] {
+	return shared.ExecuteWithRetry(func() (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.SignErr != nil {
+			return nil, c.SignErr
+		}
+		return nil, record()
+	}, 1, 0)
+}
+
+func (c *SystemsManagerClient) GetCurrentRewardEpochId() <-chan shared.ExecuteStatus[*big.Int] {
+	return shared.ExecuteWithRetry(func() (*big.Int, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		return big.NewInt(c.currentRewardEpochId), nil
+	}, 1, 0)
+}
+
+func (c *SystemsManagerClient) SetCurrentRewardEpochId(rewardEpochId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.currentRewardEpochId = rewardEpochId
+}
+
+func (c *SystemsManagerClient) SendVotePowerBlockSelected(event *system.FlareSystemsManagerVotePowerBlockSelected) {
+	c.votePowerBlockSelected <- event
+}
+
+func (c *SystemsManagerClient) SendSignUptimeVoteEnabled(event *system.FlareSystemsManagerSignUptimeVoteEnabled) {
+	c.signUptimeVoteEnabled <- event
+}
+
+func (c *SystemsManagerClient) SendUptimeVoteSigned(event *system.FlareSystemsManagerUptimeVoteSigned) {
+	c.uptimeVoteSigned <- event
+}
+
+// SignedPolicy returns the signing policy signed for the reward epoch
+func (c *SystemsManagerClient) SignedPolicy(rewardEpochId int64) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	policy, ok := c.signedPolicies[rewardEpochId]
+	return policy, ok
+}
+
+// UptimeVote returns the uptime vote hash signed for the reward epoch
+func (c *SystemsManagerClient) UptimeVote(rewardEpochId int64) (common.Hash, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash, ok := c.uptimeVotes[rewardEpochId]
+	return hash, ok
+}
+
+// RewardsHash returns the rewards hash signed for the reward epoch
+func (c *SystemsManagerClient) RewardsHash(rewardEpochId int64) (common.Hash, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash, ok := c.rewardsHashes[rewardEpochId]
+	return hash, ok
+}
+
+// RelayClient implements epoch.RelayContractClient without a chain, the signing
+// policies are sent by the simulation
+type RelayClient struct {
+	signingPolicyInitialized chan *relay.RelaySigningPolicyInitialized
+
+	mu       sync.Mutex
+	policies map[int64]*relay.RelaySigningPolicyInitialized
+}
+
+var _ epoch.RelayContractClient = (*RelayClient)(nil)
+
+func NewRelayClient() *RelayClient {
+	return &RelayClient{
+		signingPolicyInitialized: make(chan *relay.RelaySigningPolicyInitialized, eventBufferSize),
+		policies:                 make(map[int64]*relay.RelaySigningPolicyInitialized),
+	}
+}
+
+func (c *RelayClient) SigningPolicyInitializedListener(
+	context.Context, epoch.EpochClientDB, *utils.Epoch,
+) <-chan *relay.RelaySigningPolicyInitialized {
+	return c.signingPolicyInitialized
+}
+
+func (c *RelayClient) FetchSigningPolicy(
+	_ epoch.EpochClientDB, rewardEpochId *big.Int, _, _ int64,
+) (*relay.RelaySigningPolicyInitialized, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.policies[rewardEpochId.Int64()], nil
+}
+
+// The hash of the sent policies, as stored by the Relay contract
+func (c *RelayClient) SigningPolicyHash(rewardEpochId *big.Int) <-chan shared.ExecuteStatus[common.Hash] {
+	return shared.ExecuteWithRetry(func() (common.Hash, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		policy, ok := c.policies[rewardEpochId.Int64()]
+		if !ok {
+			return common.Hash{}, nil
+		}
+		return signingpolicy.Hash(policy.SigningPolicyBytes), nil
+	}, 1, 0)
+}
+
+// SendSigningPolicy initializes the signing policy and sends the event to the listener
+func (c *RelayClient) SendSigningPolicy(policy *relay.RelaySigningPolicyInitialized) {
+	c.mu.Lock()
+	c.policies[policy.RewardEpochId.Int64()] = policy
+	c.mu.Unlock()
+
+	c.signingPolicyInitialized <- policy
+}
+
+// RegistryClient implements epoch.RegistryContractClient without a chain, the voter
+// registrations are recorded
+type RegistryClient struct {
+	mu         sync.Mutex
+	registered map[int64][]common.Address
+
+	// if set, returned by RegisterVoter and the registration is not recorded
+	RegisterErr error
+}
+
+var _ epoch.RegistryContractClient = (*RegistryClient)(nil)
+
+func NewRegistryClient() *RegistryClient {
+	return &RegistryClient{registered: make(map[int64][]common.Address)}
+}
+
+func (c *RegistryClient) RegisterVoter(rewardEpochId *big.Int, address common.Address) <-chan shared.ExecuteStatus[any] {
+	return shared.ExecuteWithRetry(func() (any, error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.RegisterErr != nil {
+			return nil, c.RegisterErr
+		}
+		c.registered[rewardEpochId.Int64()] = append(c.registered[rewardEpochId.Int64()], address)
+		return nil, nil
+	}, 1, 0)
+}
+
+// RegisteredVoters returns the voters registered for the reward epoch
+func (c *RegistryClient) RegisteredVoters(rewardEpochId int64) []common.Address {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]common.Address(nil), c.registered[rewardEpochId]...)
+}
@@ -0,0 +1,122 @@
+package simulation
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"flare-tlc/database"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/contracts/submission"
+	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/signingpolicy"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// signature [R || S || V] of the submitted message
+const signedPayloadTypeRSV byte = 2
+
+// SigningPolicyInitializedLog encodes the event as an indexer log of the Relay contract
+// with the timestamp of the event. The signing policy bytes are encoded from the policy
+// if not set.
+func SigningPolicyInitializedLog(relayAddress common.Address, policy *relay.RelaySigningPolicyInitialized) (*database.Log, error) {
+	if len(policy.SigningPolicyBytes) == 0 {
+		policyBytes, err := signingpolicy.EncodeEvent(policy)
+		if err != nil {
+			return nil, err
+		}
+		policy.SigningPolicyBytes = policyBytes
+	}
+
+	relayABI, err := relay.RelayMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	event := relayABI.Events["SigningPolicyInitialized"]
+	data, err := event.Inputs.NonIndexed().Pack(
+		policy.StartVotingRoundId,
+		policy.Threshold,
+		policy.Seed,
+		policy.Voters,
+		policy.Weights,
+		policy.SigningPolicyBytes,
+		policy.Timestamp,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "error packing SigningPolicyInitialized event")
+	}
+	return &database.Log{
+		Address:         relayAddress.Hex(),
+		Data:            hex.EncodeToString(data),
+		Topic0:          event.ID.Hex(),
+		Topic1:          common.BigToHash(policy.RewardEpochId).Hex(),
+		Topic2:          "NULL",
+		Topic3:          "NULL",
+		TransactionHash: crypto.Keccak256Hash(data).Hex(),
+		Timestamp:       policy.Timestamp,
+	}, nil
+}
+
+// Message of the protocol signed by a voter in submitSignatures
+type SignedMessage struct {
+	ProtocolId         byte
+	VotingRoundId      uint32
+	RandomQualityScore bool
+	MerkleRoot         common.Hash
+}
+
+// SubmitSignaturesTx encodes the signatures of the messages, signed by the signing
+// policy key, as an indexer submitSignatures tx to the Submission contract sent by from
+func SubmitSignaturesTx(
+	submissionAddress common.Address, from common.Address, signer credentials.Signer, timestamp uint64, messages ...SignedMessage,
+) (*database.Transaction, error) {
+	submissionABI, err := submission.SubmissionMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	selector := submissionABI.Methods["submitSignatures"].ID
+
+	input := bytes.NewBuffer(append([]byte(nil), selector...))
+	for _, message := range messages {
+		payload, err := signedPayload(signer, &message)
+		if err != nil {
+			return nil, err
+		}
+		// protocol id (1 byte), voting round (4 bytes), length (2 bytes), payload
+		input.WriteByte(message.ProtocolId)
+		_ = binary.Write(input, binary.BigEndian, message.VotingRoundId)
+		_ = binary.Write(input, binary.BigEndian, uint16(len(payload)))
+		input.Write(payload)
+	}
+	return &database.Transaction{
+		Hash:        crypto.Keccak256Hash(input.Bytes()).Hex(),
+		FunctionSig: hex.EncodeToString(selector),
+		Input:       hex.EncodeToString(input.Bytes()),
+		FromAddress: from.Hex(),
+		ToAddress:   submissionAddress.Hex(),
+		Status:      1,
+		Timestamp:   timestamp,
+	}, nil
+}
+
+// type (1 byte), message (38 bytes), signature (65 bytes)
+func signedPayload(signer credentials.Signer, message *SignedMessage) ([]byte, error) {
+	encoded := bytes.NewBuffer(nil)
+	encoded.WriteByte(message.ProtocolId)
+	_ = binary.Write(encoded, binary.BigEndian, message.VotingRoundId)
+	if message.RandomQualityScore {
+		encoded.WriteByte(1)
+	} else {
+		encoded.WriteByte(0)
+	}
+	encoded.Write(message.MerkleRoot[:])
+
+	signature, err := signer.SignText(crypto.Keccak256(encoded.Bytes()))
+	if err != nil {
+		return nil, errors.Wrap(err, "error signing message")
+	}
+	payload := append([]byte{signedPayloadTypeRSV}, encoded.Bytes()...)
+	return append(payload, signature...), nil
+}
@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"flare-tlc/utils/chain"
+	"flare-tlc/utils/credentials"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Relay tx sent by the finalizer
+type RelayTx struct {
+	From common.Address
+	To   common.Address
+	Data []byte
+}
+
+type relayedRound struct {
+	protocolId    byte
+	votingRoundId uint32
+}
+
+// RelayEthClient implements finalizer.RelayEthClient without a chain: relay txs are
+// recorded instead of sent and the Relay contract state is set by the simulation
+type RelayEthClient struct {
+	mu sync.RWMutex
+
+	txs                 []RelayTx
+	merkleRoots         map[relayedRound]common.Hash
+	signingPolicyHashes map[int64]common.Hash
+
+	// if set, returned by SendRawTx and the tx is not recorded
+	SendErr error
+}
+
+func NewRelayEthClient() *RelayEthClient {
+	return &RelayEthClient{
+		merkleRoots:         make(map[relayedRound]common.Hash),
+		signingPolicyHashes: make(map[int64]common.Hash),
+	}
+}
+
+func (c *RelayEthClient) SendRawTx(
+	signer credentials.Signer, to common.Address, data []byte, _ bool, _ ...chain.ExpectedEvent,
+) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.SendErr != nil {
+		return c.SendErr
+	}
+	c.txs = append(c.txs, RelayTx{From: signer.Address(), To: to, Data: data})
+	return nil
+}
+
+func (c *RelayEthClient) MerkleRoot(protocolId byte, votingRoundId uint32) (common.Hash, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.merkleRoots[relayedRound{protocolId: protocolId, votingRoundId: votingRoundId}], nil
+}
+
+func (c *RelayEthClient) SigningPolicyHash(rewardEpochId int64) (common.Hash, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.signingPolicyHashes[rewardEpochId], nil
+}
+
+// SetMerkleRoot marks the voting round of the protocol as relayed
+func (c *RelayEthClient) SetMerkleRoot(protocolId byte, votingRoundId uint32, root common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.merkleRoots[relayedRound{protocolId: protocolId, votingRoundId: votingRoundId}] = root
+}
+
+// SetSigningPolicyHash marks the signing policy of the reward epoch as initialized
+func (c *RelayEthClient) SetSigningPolicyHash(rewardEpochId int64, hash common.Hash) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.signingPolicyHashes[rewardEpochId] = hash
+}
+
+// Txs returns the recorded relay txs in the order they were sent
+func (c *RelayEthClient) Txs() []RelayTx {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]RelayTx(nil), c.txs...)
+}
@@ -0,0 +1,131 @@
+package simulation
+
+import (
+	"context"
+	clientConfig "flare-tlc/client/config"
+	"flare-tlc/client/finalizer"
+	"flare-tlc/database"
+	"flare-tlc/utils"
+	"flare-tlc/utils/contracts/relay"
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+)
+
+const testPrivateKeyHex = "4f65bffe3c8ed6c0b812e84d35402e949feea042061cc1635fe6ae83ed84df4a"
+
+var (
+	relayAddress      = common.HexToAddress("0xb849b93B585eFfb7cE4B522Ff88d9b3B24955f24")
+	submissionAddress = common.HexToAddress("0x2F79Dce2375571207a7976148D4468195F89a73e")
+)
+
+func TestFinalizerRound(t *testing.T) {
+	privateKey, err := crypto.HexToECDSA(testPrivateKeyHex)
+	require.NoError(t, err)
+	signer := credentials.NewPrivateKeySigner(privateKey)
+
+	db, err := NewMemoryDB()
+	require.NoError(t, err)
+	loadRoundFixtures(t, db, signer)
+
+	cfg := &clientConfig.ClientConfig{}
+	cfg.ContractAddresses.Relay = relayAddress
+	cfg.ContractAddresses.Submission = submissionAddress
+	cfg.Finalizer.StartingVotingRound = 1
+	cfg.Finalizer.StartOffset = 10000 * time.Second
+	cfg.Finalizer.VoterThresholdBIPS = 5000
+
+	eth := NewRelayEthClient()
+	clock := utils.NewFakeClock(time.Unix(10000, 0))
+	client, err := finalizer.NewSimulatedFinalizerClient(cfg, finalizer.Simulation{
+		DB:          finalizer.NewFinalizerDB(db, database.FetchOptions{}),
+		EthClient:   eth,
+		Signer:      signer,
+		VotingEpoch: &utils.Epoch{Start: time.Unix(0, 0), Period: time.Hour},
+		RewardEpoch: &utils.IntEpoch{Start: 0, Period: 100},
+		Clock:       clock,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	eg, ctx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return client.Run(ctx)
+	})
+
+	require.Eventually(t, func() bool {
+		clock.Advance(time.Second)
+		return len(eth.Txs()) > 0
+	}, 10*time.Second, 10*time.Millisecond)
+
+	cancel()
+	err = eg.Wait()
+	require.True(t, errors.Is(err, context.Canceled), "unexpected error: %v", err)
+
+	txs := eth.Txs()
+	require.Len(t, txs, 1)
+	require.Equal(t, signer.Address(), txs[0].From)
+	require.Equal(t, relayAddress, txs[0].To)
+}
+
+func TestLoadFixtureFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	fixtures := `{
+		"transactions": [{"ID": 1, "Hash": "0xAB", "FunctionSig": "0x57eed580", "ToAddress": "0x2F79Dce2375571207a7976148D4468195F89a73e", "Timestamp": 10}],
+		"logs": [{"ID": 1, "Address": "0xB849b93B585eFfb7cE4B522Ff88d9b3B24955f24", "Topic0": "0x91D0", "Timestamp": 20}]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(fixtures), 0o600))
+
+	db, err := NewMemoryDB()
+	require.NoError(t, err)
+	require.NoError(t, LoadFixtureFile(db, path))
+
+	txs, err := database.FetchTransactionsByAddressAndSelector(
+		db, submissionAddress.Hex(), "57eed580", 0, 10, database.FetchOptions{},
+	)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, "ab", txs[0].Hash)
+
+	logs, err := database.FetchLogsByAddressAndTopic0(db, relayAddress.Hex(), "91d0", 0, 20, database.FetchOptions{})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, "NULL", logs[0].Topic1)
+}
+
+func loadRoundFixtures(t *testing.T, db *gorm.DB, signer credentials.Signer) {
+	spiLog, err := SigningPolicyInitializedLog(relayAddress, &relay.RelaySigningPolicyInitialized{
+		RewardEpochId:      big.NewInt(1),
+		StartVotingRoundId: 1,
+		Threshold:          1,
+		Seed:               big.NewInt(1),
+		Voters:             []common.Address{signer.Address()},
+		Weights:            []uint16{2},
+		Timestamp:          100,
+	})
+	require.NoError(t, err)
+
+	tx, err := SubmitSignaturesTx(submissionAddress, signer.Address(), signer, 3700, SignedMessage{
+		ProtocolId:         1,
+		VotingRoundId:      1,
+		RandomQualityScore: true,
+		MerkleRoot:         common.HexToHash("0xff"),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, LoadFixtures(db, &Fixtures{
+		Transactions: []database.Transaction{*tx},
+		Logs:         []database.Log{*spiLog},
+	}))
+}
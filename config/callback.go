@@ -1,15 +1,41 @@
 package config
 
+import "sync"
+
 type ConfigCallback[T any] struct {
-	callbacks []func(T)
+	mu        sync.Mutex
+	callbacks []*callbackEntry[T]
+}
+
+type callbackEntry[T any] struct {
+	f func(T)
 }
 
-func (cc *ConfigCallback[T]) AddCallback(f func(T)) {
-	cc.callbacks = append(cc.callbacks, f)
+// Adds the callback, the returned function removes it. Clients created more than
+// once must remove their callbacks when they stop.
+func (cc *ConfigCallback[T]) AddCallback(f func(T)) (remove func()) {
+	entry := &callbackEntry[T]{f: f}
+	cc.mu.Lock()
+	cc.callbacks = append(cc.callbacks, entry)
+	cc.mu.Unlock()
+
+	return func() {
+		cc.mu.Lock()
+		defer cc.mu.Unlock()
+		for i, e := range cc.callbacks {
+			if e == entry {
+				cc.callbacks = append(cc.callbacks[:i:i], cc.callbacks[i+1:]...)
+				return
+			}
+		}
+	}
 }
 
 func (cc *ConfigCallback[T]) Call(config T) {
-	for _, gc := range cc.callbacks {
-		gc(config)
+	cc.mu.Lock()
+	callbacks := cc.callbacks
+	cc.mu.Unlock()
+	for _, gc := range callbacks {
+		gc.f(config)
 	}
 }
@@ -1,18 +1,25 @@
 package config
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"errors"
 	"flare-tlc/utils/credentials"
+	"flare-tlc/utils/failover"
+	"flare-tlc/utils/sortition"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/kelseyhightower/envconfig"
+	"golang.org/x/term"
 )
 
 const (
@@ -35,37 +42,115 @@ type LoggerConfig struct {
 	File        string `toml:"file"`
 	MaxFileSize int    `toml:"max_file_size"` // In megabytes
 	Console     bool   `toml:"console"`
+
+	// Output format, "console" (default) or "json"
+	Format string `toml:"format"`
+
+	// Per-module log levels overriding Level, e.g. finalizer = "DEBUG"
+	Modules map[string]string `toml:"modules"`
 }
 
+// Drivers of the indexer database
+const (
+	DBDriverMySQL    = "mysql"
+	DBDriverPostgres = "postgres"
+)
+
 type DBConfig struct {
+	// DBDriverMySQL (default) or DBDriverPostgres
+	Driver     string `toml:"driver" envconfig:"DB_DRIVER"`
 	Host       string `toml:"host" envconfig:"DB_HOST"`
 	Port       int    `toml:"port" envconfig:"DB_PORT"`
 	Database   string `toml:"database" envconfig:"DB_DATABASE"`
 	Username   string `toml:"username" envconfig:"DB_USERNAME"`
 	Password   string `toml:"password" envconfig:"DB_PASSWORD"`
 	LogQueries bool   `toml:"log_queries"`
+
+	// sslmode of the PostgreSQL connection, e.g. "disable" or "verify-full", the
+	// default of the driver (prefer) if not set
+	SSLMode string `toml:"ssl_mode" envconfig:"DB_SSL_MODE"`
+
+	// Connection pool of the database handle, zero values keep the defaults of
+	// database/sql (unlimited open, 2 idle and no lifetime limit)
+	MaxOpenConns    int           `toml:"max_open_conns"`
+	MaxIdleConns    int           `toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `toml:"conn_max_lifetime"`
+
+	// Backoff of the reconnection attempts after the connection dropped, doubled
+	// from ReconnectMinBackoff up to ReconnectMaxBackoff
+	ReconnectMinBackoff time.Duration `toml:"reconnect_min_backoff"`
+	ReconnectMaxBackoff time.Duration `toml:"reconnect_max_backoff"`
 }
 
 type ChainConfig struct {
 	ChainID   int    `toml:"chain_id" envconfig:"CHAIN_ID"`
 	EthRPCURL string `toml:"eth_rpc_url" envconfig:"ETH_RPC_URL"`
+	EthWSURL  string `toml:"eth_ws_url" envconfig:"ETH_WS_URL"`
 	ApiKey    string `toml:"api_key" envconfig:"API_KEY"`
+
+	// Additional RPC endpoints used for failover, tried after EthRPCURL
+	EthRPCURLs    []string `toml:"eth_rpc_urls" envconfig:"ETH_RPC_URLS"`
+	RPCRoundRobin bool     `toml:"rpc_round_robin" envconfig:"RPC_ROUND_ROBIN"`
 }
 
-// Dial the chain node and return an ethclient.Client.
+// Dial the chain node and return an ethclient.Client. If multiple RPC endpoints
+// are configured, requests are sent through a failover transport.
 func (chain *ChainConfig) DialETH() (*ethclient.Client, error) {
-	rpcURL, err := chain.getRPCURL()
+	rpcClient, err := chain.DialRPC()
 	if err != nil {
 		return nil, err
 	}
+	return ethclient.NewClient(rpcClient), nil
+}
 
-	return ethclient.Dial(rpcURL)
+// Dial the chain node and return the raw rpc.Client, for requests not covered by
+// ethclient.Client
+func (chain *ChainConfig) DialRPC() (*rpc.Client, error) {
+	rawURLs := chain.EthRPCURLs
+	if len(chain.EthRPCURL) > 0 {
+		rawURLs = append([]string{chain.EthRPCURL}, rawURLs...)
+	}
+	if len(rawURLs) == 0 {
+		return nil, errors.New("eth_rpc_url is not set")
+	}
+
+	rpcURLs := make([]string, len(rawURLs))
+	for i, rawURL := range rawURLs {
+		rpcURL, err := chain.getRPCURL(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		rpcURLs[i] = rpcURL
+	}
+	if len(rpcURLs) == 1 {
+		return rpc.DialContext(context.Background(), rpcURLs[0])
+	}
+
+	transport, err := failover.NewTransport(rpcURLs, chain.RPCRoundRobin)
+	if err != nil {
+		return nil, err
+	}
+	// The url is only a placeholder, the transport selects the endpoint for each request
+	return rpc.DialHTTPWithClient(rpcURLs[0], &http.Client{Transport: transport})
+}
+
+// Dial the chain node websocket endpoint, needed for event subscriptions.
+func (chain *ChainConfig) DialWS() (*ethclient.Client, error) {
+	if len(chain.EthWSURL) == 0 {
+		return nil, errors.New("websocket listener enabled but eth_ws_url is not set")
+	}
+	wsURL, err := chain.getRPCURL(chain.EthWSURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return ethclient.Dial(wsURL)
 }
 
 // Get the full RPC URL which may be passed to ethclient.Dial. Includes API key
 // as query param if it is configured.
-func (chain *ChainConfig) getRPCURL() (string, error) {
-	u, err := url.Parse(chain.EthRPCURL)
+func (chain *ChainConfig) getRPCURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
 	if err != nil {
 		return "", err
 	}
@@ -86,6 +171,9 @@ type ContractAddresses struct {
 	SystemsManager common.Address `toml:"systems_manager" envconfig:"SYSTEMS_MANAGER_CONTRACT_ADDRESS"`
 	VoterRegistry  common.Address `toml:"voter_registry" envconfig:"VOTER_REGISTRY_CONTRACT_ADDRESS"`
 	Relay          common.Address `toml:"relay" envconfig:"RELAY_CONTRACT_ADDRESS"`
+
+	// Only used by the fast updates client, not resolved from the contract registry
+	FastUpdater common.Address `toml:"fast_updater" envconfig:"FAST_UPDATER_CONTRACT_ADDRESS"`
 }
 
 func ParseConfigFile(cfg interface{}, fileName string, allowMissing bool) error {
@@ -124,28 +212,173 @@ func ReadFileToString(fileName string) (string, error) {
 // Read private key from env variable or file if insecure private key handling
 // is enabled (INSECURE_PRIVATE_KEYS)
 func PrivateKeyFromConfig(fileName string, envString string) (pk *ecdsa.PrivateKey, err error) {
+	pkString, err := privateKeyString(fileName, envString)
+	if err != nil {
+		return nil, err
+	}
+	pk, err = credentials.PrivateKeyFromHex(pkString)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+	return pk, nil
+}
+
+// Read the sortition key of fast updates like PrivateKeyFromConfig
+func SortitionKeyFromConfig(fileName string, envString string) (*sortition.Key, error) {
+	keyString, err := privateKeyString(fileName, envString)
+	if err != nil {
+		return nil, err
+	}
+	return sortition.KeyFromHex(keyString)
+}
+
+// Write the hex encoded sortition key to a new file readable only by the owner,
+// an existing file is not overwritten
+func WriteSortitionKeyFile(fileName string, key *sortition.Key) error {
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating sortition key file: %w", err)
+	}
+	if _, err := file.WriteString(key.SecretHex() + "\n"); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing sortition key file: %w", err)
+	}
+	return file.Close()
+}
+
+func privateKeyString(fileName string, envString string) (string, error) {
 	envString = strings.TrimSpace(envString)
 	fileName = strings.TrimSpace(fileName)
 
-	var pkString string
 	if len(envString) > 0 {
-		pkString = envString
-	} else if len(fileName) > 0 {
-		allowInsecureEnv := strings.ToLower(os.Getenv("INSECURE_PRIVATE_KEYS"))
-		if allowInsecureEnv == "true" {
-			pkString, err = ReadFileToString(fileName)
-			if err != nil {
-				return nil, fmt.Errorf("error reading private key from file: %w", err)
-			}
-		} else {
-			return nil, errors.New("private keys in files are disabled")
+		return envString, nil
+	}
+	if len(fileName) == 0 {
+		return "", errors.New("no private key specified")
+	}
+	allowInsecureEnv := strings.ToLower(os.Getenv("INSECURE_PRIVATE_KEYS"))
+	if allowInsecureEnv != "true" {
+		return "", errors.New("private keys in files are disabled")
+	}
+	pkString, err := ReadFileToString(fileName)
+	if err != nil {
+		return "", fmt.Errorf("error reading private key from file: %w", err)
+	}
+	return pkString, nil
+}
+
+const (
+	SignerTypeLocal    = "local"
+	SignerTypeKeystore = "keystore"
+	SignerTypeAWSKMS   = "aws_kms"
+	SignerTypeGCPKMS   = "gcp_kms"
+	SignerTypeRemote   = "remote"
+)
+
+type SignerConfig struct {
+	// local (default) - private key from env variable or file, keystore, aws_kms, gcp_kms or
+	// remote (JSON-RPC signer with eth_sign and eth_signTransaction, e.g. web3signer)
+	Type string `toml:"type"`
+
+	// Encrypted geth JSON keystore file. The passphrase is read from PassphraseFile, the env
+	// variable named by PassphraseEnv or, if neither is set, prompted for on the terminal.
+	KeystoreFile   string `toml:"keystore_file"`
+	PassphraseFile string `toml:"passphrase_file"`
+	PassphraseEnv  string `toml:"passphrase_env"`
+
+	// AWS KMS key id or ARN, Google Cloud KMS crypto key version resource name
+	KeyID string `toml:"key_id"`
+
+	// AWS region, optional
+	Region string `toml:"region"`
+
+	// Remote signer URL and the address of the account to sign with
+	URL     string         `toml:"url"`
+	Address common.Address `toml:"address"`
+}
+
+// Create a signer as configured in signerCfg. For local signers the private key
+// is read from env variable or file, see PrivateKeyFromConfig.
+func SignerFromConfig(signerCfg *SignerConfig, fileName string, envString string) (credentials.Signer, error) {
+	ctx := context.Background()
+	switch signerCfg.Type {
+	case "", SignerTypeLocal:
+		pk, err := PrivateKeyFromConfig(fileName, envString)
+		if err != nil {
+			return nil, err
+		}
+		return credentials.NewPrivateKeySigner(pk), nil
+	case SignerTypeKeystore:
+		pk, err := PrivateKeyFromKeystore(signerCfg)
+		if err != nil {
+			return nil, err
+		}
+		return credentials.NewPrivateKeySigner(pk), nil
+	case SignerTypeAWSKMS:
+		if len(signerCfg.KeyID) == 0 {
+			return nil, errors.New("key_id not set for aws_kms signer")
 		}
-	} else {
-		return nil, errors.New("no private key specified")
+		return credentials.NewAWSKMSSigner(ctx, signerCfg.KeyID, signerCfg.Region)
+	case SignerTypeGCPKMS:
+		if len(signerCfg.KeyID) == 0 {
+			return nil, errors.New("key_id not set for gcp_kms signer")
+		}
+		return credentials.NewGCPKMSSigner(ctx, signerCfg.KeyID)
+	case SignerTypeRemote:
+		if len(signerCfg.URL) == 0 || signerCfg.Address == (common.Address{}) {
+			return nil, errors.New("url and address must be set for remote signer")
+		}
+		return credentials.NewRemoteSigner(ctx, signerCfg.URL, signerCfg.Address)
+	default:
+		return nil, fmt.Errorf("unknown signer type %s", signerCfg.Type)
 	}
-	pk, err = credentials.PrivateKeyFromHex(pkString)
+}
+
+// Read and decrypt the private key from the keystore file configured in signerCfg
+func PrivateKeyFromKeystore(signerCfg *SignerConfig) (*ecdsa.PrivateKey, error) {
+	if len(signerCfg.KeystoreFile) == 0 {
+		return nil, errors.New("keystore_file not set for keystore signer")
+	}
+	keyJSON, err := os.ReadFile(signerCfg.KeystoreFile)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing private key: %w", err)
+		return nil, fmt.Errorf("error reading keystore file: %w", err)
+	}
+	passphrase, err := keystorePassphrase(signerCfg)
+	if err != nil {
+		return nil, err
+	}
+	pk, err := credentials.PrivateKeyFromKeystore(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting keystore %s: %w", signerCfg.KeystoreFile, err)
 	}
 	return pk, nil
 }
+
+func keystorePassphrase(signerCfg *SignerConfig) (string, error) {
+	if len(signerCfg.PassphraseFile) > 0 {
+		passphrase, err := ReadFileToString(signerCfg.PassphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("error reading keystore passphrase: %w", err)
+		}
+		return passphrase, nil
+	}
+	if len(signerCfg.PassphraseEnv) > 0 {
+		passphrase, ok := os.LookupEnv(signerCfg.PassphraseEnv)
+		if !ok {
+			return "", fmt.Errorf("keystore passphrase env variable %s is not set", signerCfg.PassphraseEnv)
+		}
+		return passphrase, nil
+	}
+
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) {
+		return "", errors.New("no keystore passphrase configured and stdin is not a terminal")
+	}
+	fmt.Printf("Passphrase for %s: ", signerCfg.KeystoreFile)
+	passphrase, err := term.ReadPassword(stdin)
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("error reading keystore passphrase: %w", err)
+	}
+	return string(passphrase), nil
+}
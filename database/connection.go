@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"flare-tlc/config"
+	"flare-tlc/utils/metrics"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const (
+	connectionCheckInterval = 10 * time.Second
+	connectionCheckTimeout  = 5 * time.Second
+
+	// idle connections of database/sql if SetMaxIdleConns is not called
+	defaultMaxIdleConns = 2
+)
+
+var (
+	dbConnected = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "db_connected",
+		Help:      "1 if the last database ping succeeded, 0 while the connection is being re-established",
+	})
+	dbReconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "db_reconnects_total",
+		Help:      "Number of times the database connection was re-established after it dropped",
+	})
+)
+
+// Pool of the database handle, implemented by sql.DB
+type connectionPool interface {
+	PingContext(ctx context.Context) error
+	SetMaxOpenConns(n int)
+	SetMaxIdleConns(n int)
+	SetConnMaxLifetime(d time.Duration)
+}
+
+// Applies the pool settings of the config, zero values keep the defaults
+func configurePool(pool connectionPool, cfg *config.DBConfig) {
+	if cfg.MaxOpenConns > 0 {
+		pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+}
+
+// ConnectionMonitor pings the database and re-establishes the connection with an
+// exponential backoff when it drops. database/sql dials new connections on demand,
+// but the idle connections of the pool are the ones of the dropped server and fail
+// the queries that get them, they are closed before each attempt.
+type ConnectionMonitor struct {
+	pool       connectionPool
+	maxIdle    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	// sleeps between the checks, time.After if not set
+	after func(time.Duration) <-chan time.Time
+}
+
+// NewConnectionMonitor returns the monitor of the connection opened by Connect with
+// the config
+func NewConnectionMonitor(db *gorm.DB, cfg *config.DBConfig) (*ConnectionMonitor, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return newConnectionMonitor(sqlDB, cfg), nil
+}
+
+func newConnectionMonitor(pool connectionPool, cfg *config.DBConfig) *ConnectionMonitor {
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	return &ConnectionMonitor{
+		pool:       pool,
+		maxIdle:    maxIdle,
+		minBackoff: cfg.ReconnectMinBackoff,
+		maxBackoff: cfg.ReconnectMaxBackoff,
+		after:      time.After,
+	}
+}
+
+// Run checks the connection until ctx is done
+func (m *ConnectionMonitor) Run(ctx context.Context) {
+	dbConnected.Set(1)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.after(connectionCheckInterval):
+		}
+		err := m.ping(ctx)
+		if err == nil {
+			continue
+		}
+		dbConnected.Set(0)
+		logger.Warn("Database connection lost: %v, reconnecting", err)
+		attempts, ok := m.reconnect(ctx)
+		if !ok {
+			return
+		}
+		dbConnected.Set(1)
+		dbReconnects.Inc()
+		logger.Info("Database connection re-established after %d attempts", attempts)
+	}
+}
+
+// Retries until the ping succeeds, returns the number of attempts and false if ctx
+// is done first
+func (m *ConnectionMonitor) reconnect(ctx context.Context) (int, bool) {
+	backoff := m.minBackoff
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return attempt, false
+		case <-m.after(backoff):
+		}
+		// closes the idle connections, the next ones are dialed again
+		m.pool.SetMaxIdleConns(0)
+		m.pool.SetMaxIdleConns(m.maxIdle)
+		err := m.ping(ctx)
+		if err == nil {
+			return attempt, true
+		}
+		backoff = nextBackoff(backoff, m.maxBackoff)
+		logger.Debug("Database reconnection attempt %d failed: %v, next attempt in %v", attempt, err, backoff)
+	}
+}
+
+func (m *ConnectionMonitor) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, connectionCheckTimeout)
+	defer cancel()
+	return m.pool.PingContext(ctx)
+}
+
+func nextBackoff(backoff, max time.Duration) time.Duration {
+	backoff *= 2
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
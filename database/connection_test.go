@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"flare-tlc/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Fails the pings while down, records the idle connection limits
+type testConnectionPool struct {
+	mu       sync.Mutex
+	failures int
+	pings    int
+	maxIdle  []int
+	maxOpen  int
+	lifetime time.Duration
+}
+
+func (p *testConnectionPool) PingContext(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pings++
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (p *testConnectionPool) SetMaxOpenConns(n int) { p.maxOpen = n }
+
+func (p *testConnectionPool) SetMaxIdleConns(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.maxIdle = append(p.maxIdle, n)
+}
+
+func (p *testConnectionPool) SetConnMaxLifetime(d time.Duration) { p.lifetime = d }
+
+func TestConfigurePool(t *testing.T) {
+	pool := &testConnectionPool{}
+	configurePool(pool, &config.DBConfig{MaxOpenConns: 20, ConnMaxLifetime: 5 * time.Minute})
+	require.Equal(t, 20, pool.maxOpen)
+	require.Empty(t, pool.maxIdle)
+	require.Equal(t, 5*time.Minute, pool.lifetime)
+}
+
+func TestConnectionMonitorReconnects(t *testing.T) {
+	// the first check and the next 4 attempts fail
+	pool := &testConnectionPool{failures: 5}
+	monitor := newConnectionMonitor(pool, &config.DBConfig{
+		ReconnectMinBackoff: time.Second,
+		ReconnectMaxBackoff: 5 * time.Second,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	var waits []time.Duration
+	monitor.after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		c := make(chan time.Time, 1)
+		pool.mu.Lock()
+		if pool.pings == 6 && d == connectionCheckInterval {
+			// the connection is re-established
+			cancel()
+		} else {
+			c <- time.Time{}
+		}
+		pool.mu.Unlock()
+		return c
+	}
+	monitor.Run(ctx)
+
+	require.Equal(t, []time.Duration{
+		connectionCheckInterval,
+		time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+		connectionCheckInterval,
+	}, waits)
+	require.Equal(t, []int{0, 2, 0, 2, 0, 2, 0, 2, 0, 2}, pool.maxIdle)
+}
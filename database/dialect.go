@@ -0,0 +1,67 @@
+package database
+
+import (
+	"flare-tlc/config"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	timestampColumn = clause.Column{Name: "timestamp"}
+	idColumn        = clause.Column{Name: "id"}
+)
+
+// Returns the gorm dialector of the configured driver. The queries are built with gorm
+// clauses, so that the identifiers are quoted by the dialect (e.g. the timestamp
+// column, a keyword in PostgreSQL).
+func dialector(cfg *config.DBConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "", config.DBDriverMySQL:
+		dbConfig := mysql.Config{
+			User:                 cfg.Username,
+			Passwd:               cfg.Password,
+			Net:                  "tcp",
+			Addr:                 fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			DBName:               cfg.Database,
+			AllowNativePasswords: true,
+			ParseTime:            true,
+		}
+		return gormMysql.Open(dbConfig.FormatDSN()), nil
+	case config.DBDriverPostgres:
+		return postgres.Open(postgresDSN(cfg)), nil
+	default:
+		return nil, fmt.Errorf("unknown db driver %s, valid values are %s and %s", cfg.Driver, config.DBDriverMySQL, config.DBDriverPostgres)
+	}
+}
+
+// URL connection string of pgx, the credentials are escaped
+func postgresDSN(cfg *config.DBConfig) string {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(cfg.Username, cfg.Password),
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:   "/" + cfg.Database,
+	}
+	if len(cfg.SSLMode) > 0 {
+		dsn.RawQuery = url.Values{"sslmode": {cfg.SSLMode}}.Encode()
+	}
+	return dsn.String()
+}
+
+// Condition of the timestamp range (from, to]
+func timestampRange(from, to int64) clause.Expression {
+	return clause.And(clause.Gt{Column: timestampColumn, Value: from}, clause.Lte{Column: timestampColumn, Value: to})
+}
+
+// Condition of the timestamp range [from, to), of the tx audit log
+func sendTimeRange(from, to int64) clause.Expression {
+	return clause.And(clause.Gte{Column: timestampColumn, Value: from}, clause.Lt{Column: timestampColumn, Value: to})
+}
@@ -38,3 +38,79 @@ type Log struct {
 	LogIndex        uint64      `gorm:"uniqueIndex:hash_index_unique"`
 	Timestamp       uint64      `gorm:"index"`
 }
+
+// Pending finalization of the finalizer client, persisted so that it survives
+// restarts. Not part of the flare-ftso-indexer schema.
+type FinalizerQueueItem struct {
+	BaseEntity
+	VotingRoundId uint32 `gorm:"uniqueIndex:finalizer_queue_item_unique"`
+	ProtocolId    uint8  `gorm:"uniqueIndex:finalizer_queue_item_unique"`
+	MessageHash   string `gorm:"type:varchar(64);uniqueIndex:finalizer_queue_item_unique"`
+	Seed          string `gorm:"type:varchar(80)"` // decimal representation of the reward epoch seed
+}
+
+// Signing policy parsed from a SigningPolicyInitialized event, persisted by the
+// finalizer client so that it does not have to re-scan the logs on restart.
+// Not part of the flare-ftso-indexer schema.
+type SigningPolicy struct {
+	BaseEntity
+	RewardEpochId      uint32 `gorm:"uniqueIndex"`
+	StartVotingRoundId uint32
+	Threshold          uint16
+	Seed               string `gorm:"type:varchar(80)"` // decimal representation
+	Voters             string `gorm:"type:text"`        // comma separated hex addresses
+	Weights            string `gorm:"type:text"`        // comma separated weights
+	SigningPolicyBytes string `gorm:"type:text"`        // hex encoded
+	Timestamp          uint64
+}
+
+// Timestamp of the last event processed by a listener of the epoch client, the
+// listener resumes after it on restart. Identity is empty for the identity of the
+// [identity] section and the address of the additional identities. Not part of the
+// flare-ftso-indexer schema.
+type ListenerCheckpoint struct {
+	BaseEntity
+	Listener  string `gorm:"type:varchar(64);uniqueIndex:idx_listener_checkpoints_identity"`
+	Identity  string `gorm:"type:varchar(42);not null;default:'';uniqueIndex:idx_listener_checkpoints_identity"`
+	Timestamp uint64
+}
+
+// Lifecycle state of a reward epoch in the epoch client, the client resumes from it
+// on restart. Identity is empty for the identity of the [identity] section and the
+// address of the additional identities. Not part of the flare-ftso-indexer schema.
+type RewardEpochState struct {
+	BaseEntity
+	RewardEpochId uint32 `gorm:"uniqueIndex:idx_reward_epoch_states_identity"`
+	Identity      string `gorm:"type:varchar(42);not null;default:'';uniqueIndex:idx_reward_epoch_states_identity"`
+	State         string `gorm:"type:varchar(32)"`
+	LastError     string `gorm:"type:text"`
+	UpdatedAt     uint64 // unix timestamp
+}
+
+// Transaction sent by the clients, recorded with its outcome for the audit log of the
+// client. Not part of the flare-ftso-indexer schema.
+type TxAuditEntry struct {
+	BaseEntity
+	Operation    string `gorm:"type:varchar(64);index"`
+	FromAddress  string `gorm:"type:varchar(42)"`
+	ToAddress    string `gorm:"type:varchar(42)"`
+	TxHash       string `gorm:"type:varchar(66)"`
+	CalldataHash string `gorm:"type:varchar(66)"` // keccak256 of the calldata
+	Nonce        uint64
+	GasLimit     uint64
+	GasPrice     string `gorm:"type:varchar(80)"` // decimal representation, the fee cap of dynamic fee txs
+	GasUsed      uint64
+	Status       string `gorm:"type:varchar(32)"`
+	BlockNumber  uint64 // 0 if not mined
+	Error        string `gorm:"type:text"`
+	Timestamp    uint64 `gorm:"index"` // unix timestamp of the send
+}
+
+// Leader lease of replicated client instances, only the holder of an unexpired lease
+// sends transactions. Not part of the flare-ftso-indexer schema.
+type LeaderLease struct {
+	BaseEntity
+	Name      string `gorm:"type:varchar(64);uniqueIndex"`
+	Holder    string `gorm:"type:varchar(128)"`
+	ExpiresAt int64  // unix timestamp in milliseconds
+}
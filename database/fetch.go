@@ -0,0 +1,141 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Limits of the log and transaction queries over a timestamp range, zero values
+// disable the limit
+type FetchOptions struct {
+	// Length of the timestamp range of a query, the range is fetched in consecutive
+	// chunks. Timestamps are block timestamps, so a chunk covers a range of blocks.
+	ChunkSize time.Duration
+
+	// Max number of rows of a query, a chunk with more rows is fetched in pages
+	PageSize int
+
+	// Max number of rows returned, checked after each page. All rows with the
+	// timestamp of the last row are included, so the remaining rows can be fetched
+	// with the range starting at the last timestamp.
+	MaxRows int
+
+	// Load the block number and hash of the transaction of each log, into Log.Transaction
+	PreloadBlocks bool
+
+	// Each page query waits for the limiter, nil does not limit the queries
+	Limiter *QueryLimiter
+
+	// Cache of the logs shared by the listeners, nil does not cache the logs
+	LogCache *LogCache
+}
+
+type timestampedRow interface {
+	Log | Transaction
+
+	key() (timestamp uint64, id uint64)
+}
+
+func (l Log) key() (uint64, uint64) { return l.Timestamp, l.ID }
+
+func (t Transaction) key() (uint64, uint64) { return t.Timestamp, t.ID }
+
+// Fetches the rows matching query from timestamp range (from, to], order by
+// timestamp and id. Pages are selected by the (timestamp, id) of the last row of
+// the previous page, so rows inserted by the indexer meanwhile are not skipped.
+func fetchChunked[T timestampedRow](query *gorm.DB, from, to int64, opts FetchOptions) ([]T, error) {
+	return fetchPaged(func(from, to int64, last *T, limit int) ([]T, error) {
+		return fetchPage(query, from, to, last, limit)
+	}, from, to, opts)
+}
+
+type pageFetcher[T timestampedRow] func(from, to int64, last *T, limit int) ([]T, error)
+
+func fetchPaged[T timestampedRow](fetchPage pageFetcher[T], from, to int64, opts FetchOptions) ([]T, error) {
+	chunkSize := int64(opts.ChunkSize / time.Second)
+
+	var result []T
+	for chunkStart := from; chunkStart < to; {
+		chunkEnd := to
+		if chunkSize > 0 && chunkStart+chunkSize < to {
+			chunkEnd = chunkStart + chunkSize
+		}
+
+		var last *T
+		for {
+			release := opts.Limiter.Acquire()
+			page, err := fetchPage(chunkStart, chunkEnd, last, opts.PageSize)
+			release()
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, page...)
+			if opts.PageSize == 0 || len(page) < opts.PageSize {
+				break
+			}
+			last = &page[len(page)-1]
+
+			if opts.MaxRows > 0 && len(result) >= opts.MaxRows {
+				// the rest of the rows with the last timestamp
+				timestamp, _ := (*last).key()
+				release := opts.Limiter.Acquire()
+				rest, err := fetchPage(int64(timestamp)-1, int64(timestamp), last, 0)
+				release()
+				if err != nil {
+					return nil, err
+				}
+				return append(result, rest...), nil
+			}
+		}
+		if opts.MaxRows > 0 && len(result) >= opts.MaxRows {
+			break
+		}
+		chunkStart = chunkEnd
+	}
+	return result, nil
+}
+
+// Fetches the rows of the range (from, to] after the row last (if set), at most limit rows if limit > 0
+func fetchPage[T timestampedRow](query *gorm.DB, from, to int64, last *T, limit int) ([]T, error) {
+	q := query.Session(&gorm.Session{}).Where(timestampRange(from, to))
+	if last != nil {
+		timestamp, id := (*last).key()
+		q = q.Where(clause.Or(
+			clause.Gt{Column: timestampColumn, Value: timestamp},
+			clause.And(clause.Eq{Column: timestampColumn, Value: timestamp}, clause.Gt{Column: idColumn, Value: id}),
+		))
+	}
+	q = q.Order(clause.OrderByColumn{Column: timestampColumn}).Order(clause.OrderByColumn{Column: idColumn})
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	var rows []T
+	if err := q.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// FetchAll fetches the full timestamp range (from, to] with fetch, which may
+// return a limited number of rows, see FetchOptions.MaxRows
+func FetchAll[T timestampedRow](from, to int64, fetch func(from, to int64) ([]T, error)) ([]T, error) {
+	var result []T
+	for {
+		rows, err := fetch(from, to)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, rows...)
+		if len(rows) == 0 {
+			return result, nil
+		}
+		timestamp, _ := rows[len(rows)-1].key()
+		if int64(timestamp) <= from || int64(timestamp) >= to {
+			return result, nil
+		}
+		from = int64(timestamp)
+	}
+}
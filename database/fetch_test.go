@@ -0,0 +1,84 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// In-memory rows ordered by timestamp and id, counts the queries
+type testRows struct {
+	rows    []Log
+	queries int
+}
+
+func (r *testRows) fetchPage(from, to int64, last *Log, limit int) ([]Log, error) {
+	r.queries++
+	var page []Log
+	for _, row := range r.rows {
+		if int64(row.Timestamp) <= from || int64(row.Timestamp) > to {
+			continue
+		}
+		if last != nil && (row.Timestamp < last.Timestamp || row.Timestamp == last.Timestamp && row.ID <= last.ID) {
+			continue
+		}
+		page = append(page, row)
+		if limit > 0 && len(page) == limit {
+			break
+		}
+	}
+	return page, nil
+}
+
+func testLogs(timestamps ...uint64) []Log {
+	logs := make([]Log, len(timestamps))
+	for i, timestamp := range timestamps {
+		logs[i] = Log{BaseEntity: BaseEntity{ID: uint64(i + 1)}, Timestamp: timestamp}
+	}
+	return logs
+}
+
+func logIDs(logs []Log) []uint64 {
+	ids := make([]uint64, len(logs))
+	for i, log := range logs {
+		ids[i] = log.ID
+	}
+	return ids
+}
+
+func TestFetchPaged(t *testing.T) {
+	rows := &testRows{rows: testLogs(10, 20, 20, 20, 30, 150, 160)}
+
+	logs, err := fetchPaged(rows.fetchPage, 0, 200, FetchOptions{})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7}, logIDs(logs))
+	require.Equal(t, 1, rows.queries)
+
+	// chunks (0, 100], (100, 200], pages of 2 rows
+	rows.queries = 0
+	logs, err = fetchPaged(rows.fetchPage, 0, 200, FetchOptions{ChunkSize: 100 * time.Second, PageSize: 2})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7}, logIDs(logs))
+	require.Equal(t, 5, rows.queries)
+
+	// the result includes all rows of the last timestamp
+	logs, err = fetchPaged(rows.fetchPage, 0, 200, FetchOptions{PageSize: 2, MaxRows: 2})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3, 4}, logIDs(logs))
+
+	logs, err = fetchPaged(rows.fetchPage, 20, 200, FetchOptions{ChunkSize: 100 * time.Second, PageSize: 2, MaxRows: 1})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{5}, logIDs(logs))
+}
+
+func TestFetchAll(t *testing.T) {
+	rows := &testRows{rows: testLogs(10, 20, 20, 20, 30, 150, 160)}
+	opts := FetchOptions{PageSize: 2, MaxRows: 2}
+
+	logs, err := FetchAll(0, 200, func(from, to int64) ([]Log, error) {
+		return fetchPaged(rows.fetchPage, from, to, opts)
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7}, logIDs(logs))
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"flare-tlc/utils/metrics"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+)
+
+var queryLimiterWait = promauto.NewHistogram(prometheus.HistogramOpts{
+	Namespace: metrics.Namespace,
+	Name:      "db_query_limiter_wait_seconds",
+	Help:      "Time queries of the listeners waited for the shared query limiter",
+	Buckets:   prometheus.DefBuckets,
+})
+
+// Limits the number of concurrent queries and the query rate. A nil limiter does not
+// limit the queries.
+type QueryLimiter struct {
+	slots chan struct{} // nil if the concurrent queries are not limited
+	rate  *rate.Limiter // nil if the rate is not limited
+}
+
+// NewQueryLimiter returns a limiter of at most maxConcurrent queries at a time and
+// perSecond queries per second, zero values disable the limit. Returns nil if both
+// limits are disabled.
+func NewQueryLimiter(maxConcurrent int, perSecond float64) *QueryLimiter {
+	if maxConcurrent <= 0 && perSecond <= 0 {
+		return nil
+	}
+	l := &QueryLimiter{}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	if perSecond > 0 {
+		l.rate = rate.NewLimiter(rate.Limit(perSecond), 1)
+	}
+	return l
+}
+
+type queryLimits struct {
+	maxConcurrent int
+	perSecond     float64
+}
+
+var (
+	sharedLimiters      = make(map[queryLimits]*QueryLimiter)
+	sharedLimitersMutex sync.Mutex
+)
+
+// SharedQueryLimiter returns the limiter with the limits shared by all callers, so
+// that the queries of all listeners are limited together
+func SharedQueryLimiter(maxConcurrent int, perSecond float64) *QueryLimiter {
+	sharedLimitersMutex.Lock()
+	defer sharedLimitersMutex.Unlock()
+
+	limits := queryLimits{maxConcurrent: maxConcurrent, perSecond: perSecond}
+	if l, ok := sharedLimiters[limits]; ok {
+		return l
+	}
+	l := NewQueryLimiter(maxConcurrent, perSecond)
+	sharedLimiters[limits] = l
+	return l
+}
+
+// Acquire blocks until the query may run, the returned function has to be called
+// when the query is done
+func (l *QueryLimiter) Acquire() func() {
+	if l == nil {
+		return func() {}
+	}
+	start := time.Now()
+	if l.rate != nil {
+		// does not fail without a deadline and with a burst of 1
+		_ = l.rate.Wait(context.Background())
+	}
+	if l.slots == nil {
+		queryLimiterWait.Observe(time.Since(start).Seconds())
+		return func() {}
+	}
+	l.slots <- struct{}{}
+	queryLimiterWait.Observe(time.Since(start).Seconds())
+	return func() { <-l.slots }
+}
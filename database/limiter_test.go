@@ -0,0 +1,58 @@
+package database
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLimiterConcurrency(t *testing.T) {
+	limiter := NewQueryLimiter(2, 0)
+
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := limiter.Acquire()
+			defer release()
+
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(2), maxRunning.Load())
+}
+
+func TestQueryLimiterRate(t *testing.T) {
+	limiter := NewQueryLimiter(0, 100)
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		limiter.Acquire()()
+	}
+	// the first query runs immediately, then one every 10ms
+	require.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+}
+
+func TestSharedQueryLimiter(t *testing.T) {
+	require.Nil(t, SharedQueryLimiter(0, 0))
+	require.Same(t, SharedQueryLimiter(3, 0), SharedQueryLimiter(3, 0))
+	require.NotSame(t, SharedQueryLimiter(3, 0), SharedQueryLimiter(4, 0))
+
+	// a nil limiter does not block
+	var limiter *QueryLimiter
+	limiter.Acquire()()
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"flare-tlc/utils/metrics"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var logCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "db_log_cache_requests_total",
+	Help:      "Log queries of the listeners by result of the shared log cache: hit, partial (only the newer logs fetched) or miss",
+}, []string{"result"})
+
+// Logs of an address and topic0 fetched by the listeners, the cache is shared by all
+// listeners so that the overlapping ranges of the ticks are scanned once. A nil cache
+// does not cache the logs.
+//
+// An entry holds the logs of the timestamp range (from, to] fetched at most TTL ago.
+// A query of a range starting in the entry is served from it, the logs after the end
+// of the entry are fetched and appended. The indexer may add logs to the range of an
+// entry after it is fetched if it lags, they are returned after the entry expires, so
+// the TTL bounds the delay of such logs.
+type LogCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[logCacheKey]*logCacheEntry
+}
+
+type logCacheKey struct {
+	address       string
+	topic0        string
+	preloadBlocks bool
+}
+
+type logCacheEntry struct {
+	// held while the entry is fetched, the concurrent queries of the key wait for it
+	mu        sync.Mutex
+	from, to  int64
+	logs      []Log // ordered by timestamp and id
+	fetchedAt time.Time
+}
+
+// NewLogCache returns a cache of the logs fetched at most ttl ago, nil if ttl is zero
+func NewLogCache(ttl time.Duration) *LogCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &LogCache{ttl: ttl, now: time.Now, entries: make(map[logCacheKey]*logCacheEntry)}
+}
+
+var (
+	sharedLogCaches      = make(map[time.Duration]*LogCache)
+	sharedLogCachesMutex sync.Mutex
+)
+
+// SharedLogCache returns the cache with the TTL shared by all callers, so that the
+// listeners of all clients use the same cache
+func SharedLogCache(ttl time.Duration) *LogCache {
+	sharedLogCachesMutex.Lock()
+	defer sharedLogCachesMutex.Unlock()
+
+	cache, ok := sharedLogCaches[ttl]
+	if !ok {
+		cache = NewLogCache(ttl)
+		sharedLogCaches[ttl] = cache
+	}
+	return cache
+}
+
+// Returns the logs of the range (from, to], fetch fetches the missing logs of a range.
+// If fetch returned at least maxRows logs (maxRows > 0), the entry only covers the
+// range up to the last log, see FetchOptions.MaxRows.
+func (c *LogCache) fetch(key logCacheKey, from, to int64, maxRows int, fetch func(from, to int64) ([]Log, error)) ([]Log, error) {
+	if c == nil {
+		return fetch(from, to)
+	}
+	entry := c.entry(key)
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	now := c.now()
+	if !entry.fetchedAt.IsZero() && now.Sub(entry.fetchedAt) < c.ttl && entry.from <= from && from <= entry.to {
+		if to <= entry.to {
+			logCacheRequests.WithLabelValues("hit").Inc()
+			return entry.between(from, to), nil
+		}
+		logs, err := fetch(entry.to, to)
+		if err != nil {
+			return nil, err
+		}
+		logCacheRequests.WithLabelValues("partial").Inc()
+		entry.logs = append(entry.logs, logs...)
+		entry.to = coveredTo(logs, to, maxRows)
+		return entry.between(from, entry.to), nil
+	}
+
+	logs, err := fetch(from, to)
+	if err != nil {
+		return nil, err
+	}
+	logCacheRequests.WithLabelValues("miss").Inc()
+	entry.from, entry.to = from, coveredTo(logs, to, maxRows)
+	entry.logs = logs
+	entry.fetchedAt = now
+	return entry.between(from, entry.to), nil
+}
+
+// Returns the entry of the key, the expired entries are removed
+func (c *LogCache) entry(key logCacheKey) *logCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		// entries are only read under their lock, a fetched entry has fetchedAt set
+		if k != key && entry.mu.TryLock() {
+			if !entry.fetchedAt.IsZero() && now.Sub(entry.fetchedAt) >= c.ttl {
+				delete(c.entries, k)
+			}
+			entry.mu.Unlock()
+		}
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &logCacheEntry{}
+		c.entries[key] = entry
+	}
+	return entry
+}
+
+// End of the range covered by the fetched logs, the timestamp of the last log if the
+// rows were limited
+func coveredTo(logs []Log, to int64, maxRows int) int64 {
+	if maxRows > 0 && len(logs) >= maxRows {
+		return int64(logs[len(logs)-1].Timestamp)
+	}
+	return to
+}
+
+// Copy of the logs of the range (from, to]
+func (e *logCacheEntry) between(from, to int64) []Log {
+	start := sort.Search(len(e.logs), func(i int) bool { return int64(e.logs[i].Timestamp) > from })
+	end := sort.Search(len(e.logs), func(i int) bool { return int64(e.logs[i].Timestamp) > to })
+	return append([]Log(nil), e.logs[start:end]...)
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Logs at the timestamps of the indexer, records the fetched ranges
+type testLogSource struct {
+	mu         sync.Mutex
+	timestamps []uint64
+	fetches    [][2]int64
+}
+
+func (s *testLogSource) fetch(from, to int64) ([]Log, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches = append(s.fetches, [2]int64{from, to})
+	var logs []Log
+	for i, timestamp := range s.timestamps {
+		if int64(timestamp) > from && int64(timestamp) <= to {
+			logs = append(logs, Log{BaseEntity: BaseEntity{ID: uint64(i + 1)}, Timestamp: timestamp})
+		}
+	}
+	return logs, nil
+}
+
+func logTimestamps(logs []Log) []uint64 {
+	timestamps := make([]uint64, len(logs))
+	for i, log := range logs {
+		timestamps[i] = log.Timestamp
+	}
+	return timestamps
+}
+
+func TestLogCache(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cache := NewLogCache(5 * time.Second)
+	cache.now = func() time.Time { return now }
+	source := &testLogSource{timestamps: []uint64{10, 20, 30}}
+	key := logCacheKey{address: "aa", topic0: "bb"}
+
+	logs, err := cache.fetch(key, 0, 25, 0, source.fetch)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{10, 20}, logTimestamps(logs))
+
+	// served from the entry
+	logs, err = cache.fetch(key, 10, 25, 0, source.fetch)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20}, logTimestamps(logs))
+
+	// only the logs after the entry are fetched
+	logs, err = cache.fetch(key, 15, 40, 0, source.fetch)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20, 30}, logTimestamps(logs))
+	require.Equal(t, [][2]int64{{0, 25}, {25, 40}}, source.fetches)
+
+	// a range starting before the entry and other keys are fetched
+	_, err = cache.fetch(key, -5, 40, 0, source.fetch)
+	require.NoError(t, err)
+	_, err = cache.fetch(logCacheKey{address: "aa", topic0: "cc"}, 0, 40, 0, source.fetch)
+	require.NoError(t, err)
+	require.Len(t, source.fetches, 4)
+
+	// the expired entry is fetched again, with the log indexed meanwhile
+	source.timestamps = []uint64{10, 15, 20, 30}
+	now = now.Add(5 * time.Second)
+	logs, err = cache.fetch(key, 0, 40, 0, source.fetch)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{10, 15, 20, 30}, logTimestamps(logs))
+	require.Equal(t, [2]int64{0, 40}, source.fetches[4])
+}
+
+func TestLogCacheMaxRows(t *testing.T) {
+	cache := NewLogCache(time.Minute)
+	source := &testLogSource{timestamps: []uint64{10, 20, 30}}
+	key := logCacheKey{address: "aa", topic0: "bb"}
+	limited := func(from, to int64) ([]Log, error) {
+		logs, err := source.fetch(from, to)
+		if len(logs) > 2 {
+			logs = logs[:2]
+		}
+		return logs, err
+	}
+
+	// the entry ends at the last log of the limited rows
+	logs, err := cache.fetch(key, 0, 40, 2, limited)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{10, 20}, logTimestamps(logs))
+	logs, err = cache.fetch(key, 20, 40, 2, limited)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{30}, logTimestamps(logs))
+	require.Equal(t, [][2]int64{{0, 40}, {20, 40}}, source.fetches)
+}
+
+func TestLogCacheConcurrentQueries(t *testing.T) {
+	cache := NewLogCache(time.Minute)
+	var fetches atomic.Int32
+	fetch := func(from, to int64) ([]Log, error) {
+		fetches.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return []Log{{Timestamp: 10}}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logs, err := cache.fetch(logCacheKey{address: "aa"}, 0, 20, 0, fetch)
+			require.NoError(t, err)
+			require.Len(t, logs, 1)
+		}()
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, fetches.Load())
+
+	var nilCache *LogCache
+	_, err := nilCache.fetch(logCacheKey{address: "aa"}, 0, 20, 0, fetch)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, fetches.Load())
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"errors"
+	flarelogger "flare-tlc/logger"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var logger = flarelogger.Module("database")
+
+// Writes gorm logs to the database module logger if log_queries is set, queries
+// are logged at the info level and failed queries as errors.
+type gormLogger struct {
+	level gormlogger.LogLevel
+}
+
+func newGormLogger(logQueries bool) gormlogger.Interface {
+	level := gormlogger.Silent
+	if logQueries {
+		level = gormlogger.Info
+	}
+	return &gormLogger{level: level}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &gormLogger{level: level}
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		logger.Info(msg, args...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		logger.Warn(msg, args...)
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		logger.Error(msg, args...)
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		logger.With("elapsed", elapsed, "rows", rows).Error("Query %s failed: %v", sql, err)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		logger.With("elapsed", elapsed, "rows", rows).Info("Query %s", sql)
+	}
+}
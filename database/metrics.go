@@ -0,0 +1,20 @@
+package database
+
+import (
+	"flare-tlc/utils/metrics"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: metrics.Namespace,
+	Name:      "db_query_duration_seconds",
+	Help:      "Duration of indexer database queries",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"query"})
+
+func observeQueryDuration(query string, start time.Time) {
+	queryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+}
@@ -2,38 +2,254 @@ package database
 
 import (
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// Fetch all logs matching address and topic0 from timestamp range (from, to], order by timestamp
+// Fetch all logs matching address and topic0 from timestamp range (from, to], order by timestamp,
+// chunked and limited by opts, served from opts.LogCache if set
 func FetchLogsByAddressAndTopic0(db *gorm.DB, address string, topic0 string,
-	from int64, to int64) ([]Log, error) {
-	var logs []Log
-	err := db.Where(
-		"address = ? AND topic0 = ? AND timestamp > ? AND timestamp <= ?",
-		strings.ToLower(strings.TrimPrefix(address, "0x")),
-		strings.ToLower(strings.TrimPrefix(topic0, "0x")),
-		from, to,
-	).Order("timestamp").Find(&logs).Error
-	if err != nil {
-		return nil, err
+	from int64, to int64, opts FetchOptions) ([]Log, error) {
+	defer observeQueryDuration("fetch_logs", time.Now())
+
+	address = strings.ToLower(strings.TrimPrefix(address, "0x"))
+	topic0 = strings.ToLower(strings.TrimPrefix(topic0, "0x"))
+	query := db.Where("address = ? AND topic0 = ?", address, topic0)
+	if opts.PreloadBlocks {
+		query = query.Preload("Transaction", func(db *gorm.DB) *gorm.DB {
+			return db.Select("id", "hash", "block_number", "block_hash")
+		})
 	}
-	return logs, nil
+	key := logCacheKey{address: address, topic0: topic0, preloadBlocks: opts.PreloadBlocks}
+	return opts.LogCache.fetch(key, from, to, opts.MaxRows, func(from, to int64) ([]Log, error) {
+		return fetchChunked[Log](query, from, to, opts)
+	})
 }
 
-// Fetch all transactions matching toAddress and functionSig from timestamp range (from, to], order by timestamp
+// Fetch all transactions matching toAddress and functionSig from timestamp range (from, to], order by timestamp,
+// chunked and limited by opts
 func FetchTransactionsByAddressAndSelector(db *gorm.DB, toAddress string, functionSig string,
-	from int64, to int64) ([]Transaction, error) {
-	var transactions []Transaction
-	err := db.Where(
-		"to_address = ? AND function_sig = ? AND timestamp > ? AND timestamp <= ?",
+	from int64, to int64, opts FetchOptions) ([]Transaction, error) {
+	defer observeQueryDuration("fetch_transactions", time.Now())
+
+	return fetchChunked[Transaction](db.Where(
+		"to_address = ? AND function_sig = ?",
+		strings.ToLower(strings.TrimPrefix(toAddress, "0x")),
+		strings.ToLower(strings.TrimPrefix(functionSig, "0x")),
+	), from, to, opts)
+}
+
+// Count the transactions of fromAddress matching toAddress and functionSig in the timestamp range (from, to]
+func CountTransactions(db *gorm.DB, toAddress string, functionSig string, fromAddress string,
+	from int64, to int64) (int64, error) {
+	defer observeQueryDuration("count_transactions", time.Now())
+
+	var count int64
+	err := db.Model(&Transaction{}).Where(
+		"to_address = ? AND function_sig = ? AND from_address = ?",
 		strings.ToLower(strings.TrimPrefix(toAddress, "0x")),
 		strings.ToLower(strings.TrimPrefix(functionSig, "0x")),
-		from, to,
-	).Order("timestamp").Find(&transactions).Error
+		strings.ToLower(strings.TrimPrefix(fromAddress, "0x")),
+	).Where(timestampRange(from, to)).Count(&count).Error
+	return count, err
+}
+
+// Fetch the latest indexed transaction, nil if there are no transactions
+func FetchLatestTransaction(db *gorm.DB) (*Transaction, error) {
+	defer observeQueryDuration("fetch_latest_transaction", time.Now())
+
+	var transactions []Transaction
+	err := db.Select("id", "hash", "block_number", "timestamp").
+		Order(clause.OrderByColumn{Column: timestampColumn, Desc: true}).Limit(1).Find(&transactions).Error
+	if err != nil || len(transactions) == 0 {
+		return nil, err
+	}
+	return &transactions[0], nil
+}
+
+// Fetch all persisted finalizer queue items, order by voting round id
+func FetchFinalizerQueueItems(db *gorm.DB) ([]FinalizerQueueItem, error) {
+	defer observeQueryDuration("fetch_finalizer_queue_items", time.Now())
+
+	var items []FinalizerQueueItem
+	err := db.Order("voting_round_id").Find(&items).Error
 	if err != nil {
 		return nil, err
 	}
-	return transactions, nil
+	return items, nil
+}
+
+// Persist finalizer queue item, existing items are left unchanged
+func CreateFinalizerQueueItem(db *gorm.DB, item *FinalizerQueueItem) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(item).Error
+}
+
+func DeleteFinalizerQueueItem(db *gorm.DB, votingRoundId uint32, protocolId uint8, messageHash string) error {
+	return db.Where(
+		"voting_round_id = ? AND protocol_id = ? AND message_hash = ?",
+		votingRoundId, protocolId, strings.ToLower(strings.TrimPrefix(messageHash, "0x")),
+	).Delete(&FinalizerQueueItem{}).Error
+}
+
+// Delete all finalizer queue items with voting round id <= votingRoundId
+func DeleteFinalizerQueueItemsUpTo(db *gorm.DB, votingRoundId uint32) error {
+	return db.Where("voting_round_id <= ?", votingRoundId).Delete(&FinalizerQueueItem{}).Error
+}
+
+// Fetch all persisted signing policies, order by reward epoch id
+func FetchSigningPolicies(db *gorm.DB) ([]SigningPolicy, error) {
+	defer observeQueryDuration("fetch_signing_policies", time.Now())
+
+	var policies []SigningPolicy
+	err := db.Order("reward_epoch_id").Find(&policies).Error
+	if err != nil {
+		return nil, err
+	}
+	return policies, nil
+}
+
+// Persist signing policy, existing policies are left unchanged
+func CreateSigningPolicy(db *gorm.DB, policy *SigningPolicy) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(policy).Error
+}
+
+// Delete all signing policies with reward epoch id <= rewardEpochId
+func DeleteSigningPoliciesUpTo(db *gorm.DB, rewardEpochId uint32) error {
+	return db.Where("reward_epoch_id <= ?", rewardEpochId).Delete(&SigningPolicy{}).Error
+}
+
+// Delete all signing policies with reward epoch id >= rewardEpochId
+func DeleteSigningPoliciesFrom(db *gorm.DB, rewardEpochId uint32) error {
+	return db.Where("reward_epoch_id >= ?", rewardEpochId).Delete(&SigningPolicy{}).Error
+}
+
+// Fetch the checkpoint of the listener, nil if there is none
+func FetchListenerCheckpoint(db *gorm.DB, listener string, identity string) (*ListenerCheckpoint, error) {
+	defer observeQueryDuration("fetch_listener_checkpoint", time.Now())
+
+	var checkpoints []ListenerCheckpoint
+	err := db.Where("listener = ? AND identity = ?", listener, identity).Limit(1).Find(&checkpoints).Error
+	if err != nil || len(checkpoints) == 0 {
+		return nil, err
+	}
+	return &checkpoints[0], nil
+}
+
+// Persist the checkpoint of the listener, replacing the existing one of the identity
+func SaveListenerCheckpoint(db *gorm.DB, listener string, identity string, timestamp uint64) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "listener"}, {Name: "identity"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timestamp"}),
+	}).Create(&ListenerCheckpoint{Listener: listener, Identity: identity, Timestamp: timestamp}).Error
+}
+
+// Delete the checkpoints of all listeners
+func DeleteListenerCheckpoints(db *gorm.DB) error {
+	return db.Where("1 = 1").Delete(&ListenerCheckpoint{}).Error
+}
+
+// Fetch the states of the most recent reward epochs of the identity, by reward epoch id descending
+func FetchRewardEpochStates(db *gorm.DB, identity string, limit int) ([]RewardEpochState, error) {
+	defer observeQueryDuration("fetch_reward_epoch_states", time.Now())
+
+	var states []RewardEpochState
+	err := db.Where("identity = ?", identity).Order("reward_epoch_id desc").Limit(limit).Find(&states).Error
+	return states, err
+}
+
+// Persist the state of the reward epoch, replacing the existing one of the identity
+func SaveRewardEpochState(db *gorm.DB, state *RewardEpochState) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "reward_epoch_id"}, {Name: "identity"}},
+		DoUpdates: clause.AssignmentColumns([]string{"state", "last_error", "updated_at"}),
+	}).Create(state).Error
+}
+
+// Persist the audit log entry of a sent tx
+func CreateTxAuditEntry(db *gorm.DB, entry *TxAuditEntry) error {
+	return db.Create(entry).Error
+}
+
+// Fetch the audit log entries of the txs sent in the timestamp range [from, to), of the
+// operation if not empty, order by timestamp. At most limit entries if limit > 0.
+func FetchTxAuditEntries(db *gorm.DB, from, to int64, operation string, limit int) ([]TxAuditEntry, error) {
+	defer observeQueryDuration("fetch_tx_audit_entries", time.Now())
+
+	query := db.Where(sendTimeRange(from, to))
+	if len(operation) > 0 {
+		query = query.Where("operation = ?", operation)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	var entries []TxAuditEntry
+	err := query.Order(clause.OrderByColumn{Column: timestampColumn}).Order(clause.OrderByColumn{Column: idColumn}).Find(&entries).Error
+	return entries, err
+}
+
+// Number of audited txs of an operation with the status
+type TxAuditCount struct {
+	Operation string
+	Status    string
+	Count     int64
+}
+
+// Count the audit log entries of the txs sent in the timestamp range [from, to) per
+// operation and status
+func CountTxAuditEntries(db *gorm.DB, from, to int64) ([]TxAuditCount, error) {
+	defer observeQueryDuration("count_tx_audit_entries", time.Now())
+
+	var counts []TxAuditCount
+	err := db.Model(&TxAuditEntry{}).
+		Select("operation, status, count(*) AS count").
+		Where(sendTimeRange(from, to)).
+		Group("operation, status").
+		Order("operation, status").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// Delete the audit log entries of the txs sent before the timestamp
+func DeleteTxAuditEntriesBefore(db *gorm.DB, timestamp int64) error {
+	return db.Where(clause.Lt{Column: timestampColumn, Value: timestamp}).Delete(&TxAuditEntry{}).Error
+}
+
+// Acquire or renew the lease for the holder until expiresAt, returns false if another
+// holder has a lease that has not expired at now (unix timestamps in milliseconds)
+func AcquireLeaderLease(db *gorm.DB, name, holder string, now, expiresAt int64) (bool, error) {
+	result := db.Model(&LeaderLease{}).
+		Where("name = ? AND (holder = ? OR expires_at <= ?)", name, holder, now).
+		Updates(map[string]any{"holder": holder, "expires_at": expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+	// the lease is held by another holder or does not exist yet
+	result = db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&LeaderLease{Name: name, Holder: holder, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Release the lease of the holder, another holder can acquire it immediately
+func ReleaseLeaderLease(db *gorm.DB, name, holder string) error {
+	return db.Model(&LeaderLease{}).
+		Where("name = ? AND holder = ?", name, holder).
+		Update("expires_at", 0).Error
+}
+
+// Fetch the lease, nil if it was never acquired
+func FetchLeaderLease(db *gorm.DB, name string) (*LeaderLease, error) {
+	var leases []LeaderLease
+	err := db.Where("name = ?", name).Limit(1).Find(&leases).Error
+	if err != nil || len(leases) == 0 {
+		return nil, err
+	}
+	return &leases[0], nil
 }
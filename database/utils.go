@@ -2,36 +2,29 @@ package database
 
 import (
 	"flare-tlc/config"
-	"fmt"
 
-	"github.com/go-sql-driver/mysql"
-	gormMysql "gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// Connect opens the indexer database of the configured driver, MySQL or PostgreSQL
 func Connect(cfg *config.DBConfig) (*gorm.DB, error) {
-	// Connect to the database
-	dbConfig := mysql.Config{
-		User:                 cfg.Username,
-		Passwd:               cfg.Password,
-		Net:                  "tcp",
-		Addr:                 fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		DBName:               cfg.Database,
-		AllowNativePasswords: true,
-		ParseTime:            true,
-	}
-
-	var gormLogLevel logger.LogLevel
-	if cfg.LogQueries {
-		gormLogLevel = logger.Info
-	} else {
-		gormLogLevel = logger.Silent
+	dialector, err := dialector(cfg)
+	if err != nil {
+		return nil, err
 	}
 	gormConfig := gorm.Config{
-		Logger: logger.Default.LogMode(gormLogLevel),
+		Logger: newGormLogger(cfg.LogQueries),
+	}
+	db, err := gorm.Open(dialector, &gormConfig)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
 	}
-	return gorm.Open(gormMysql.Open(dbConfig.FormatDSN()), &gormConfig)
+	configurePool(sqlDB, cfg)
+	return db, nil
 }
 
 func DoInTransaction(db *gorm.DB, operations ...func(db *gorm.DB) error) error {
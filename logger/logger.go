@@ -4,6 +4,7 @@ import (
 	"flare-tlc/config"
 	"log"
 	"os"
+	"sync"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -12,10 +13,21 @@ import (
 
 var (
 	sugar *zap.SugaredLogger
+
+	// base logger without level filtering, module loggers are derived from it
+	base         *zap.Logger
+	levels       moduleLevels
+	modules      = make(map[string]*zap.SugaredLogger)
+	modulesMutex sync.RWMutex
 )
 
 const (
 	timeFormat = "[01-02|15:04:05.000]"
+
+	FormatConsole = "console"
+	FormatJSON    = "json"
+
+	moduleField = "module"
 )
 
 func init() {
@@ -32,14 +44,27 @@ func Configure(config config.LoggerConfig) {
 	sugar = createSugaredLogger(config)
 }
 
+type moduleLevels struct {
+	level   zapcore.Level
+	modules map[string]zapcore.Level
+}
+
+func (l moduleLevels) forModule(module string) zapcore.Level {
+	if level, ok := l.modules[module]; ok {
+		return level
+	}
+	return l.level
+}
+
+// Creates the base logger and recreates the loggers of all modules,
+// returns the default logger
 func createSugaredLogger(config config.LoggerConfig) *zap.SugaredLogger {
-	atom := zap.NewAtomicLevel()
 	cores := make([]zapcore.Core, 0)
 	if config.Console {
-		cores = append(cores, createConsoleLoggerCore(config, atom))
+		cores = append(cores, createConsoleLoggerCore(config))
 	}
 	if len(config.File) > 0 {
-		cores = append(cores, createFileLoggerCore(config, atom))
+		cores = append(cores, createFileLoggerCore(config))
 	}
 
 	core := zapcore.NewTee(cores...)
@@ -55,17 +80,71 @@ func createSugaredLogger(config config.LoggerConfig) *zap.SugaredLogger {
 		}
 	}()
 
-	sugar = logger.Sugar()
-
+	var invalidLevels []string
+	newLevels := moduleLevels{modules: make(map[string]zapcore.Level, len(config.Modules))}
 	level, err := zapcore.ParseLevel(config.Level)
 	if err != nil {
-		sugar.Errorf("Wrong level %s", config.Level)
+		invalidLevels = append(invalidLevels, config.Level)
+	}
+	newLevels.level = level
+	for module, moduleLevel := range config.Modules {
+		level, err := zapcore.ParseLevel(moduleLevel)
+		if err != nil {
+			invalidLevels = append(invalidLevels, moduleLevel)
+			continue
+		}
+		newLevels.modules[module] = level
+	}
+
+	modulesMutex.Lock()
+	base = logger
+	levels = newLevels
+	for module := range modules {
+		modules[module] = newModuleSugar(module)
+	}
+	modulesMutex.Unlock()
+
+	sugar = withLevel(logger, newLevels.level).Sugar()
+	for _, invalidLevel := range invalidLevels {
+		sugar.Errorf("Wrong level %s", invalidLevel)
 	}
-	atom.SetLevel(level)
 	return sugar
 }
 
-func createFileLoggerCore(config config.LoggerConfig, atom zap.AtomicLevel) zapcore.Core {
+// Should be called with modulesMutex locked
+func newModuleSugar(module string) *zap.SugaredLogger {
+	return withLevel(base, levels.forModule(module)).With(zap.String(moduleField, module)).Sugar()
+}
+
+func withLevel(logger *zap.Logger, level zapcore.Level) *zap.Logger {
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelFilterCore{Core: core, level: level}
+	}))
+}
+
+// Core with its own minimal level. Cores of the base logger accept all
+// levels, so a module can log at a lower level than the default one.
+type levelFilterCore struct {
+	zapcore.Core
+	level zapcore.Level
+}
+
+func (c *levelFilterCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *levelFilterCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(entry.Level) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+func createFileLoggerCore(config config.LoggerConfig) zapcore.Core {
 	w := zapcore.AddSync(&lumberjack.Logger{
 		Filename: config.File,
 		MaxSize:  config.MaxFileSize,
@@ -74,23 +153,34 @@ func createFileLoggerCore(config config.LoggerConfig, atom zap.AtomicLevel) zapc
 	encoderCfg.EncodeLevel = fileLevelEncoder
 	encoderCfg.EncodeTime = zapcore.TimeEncoderOfLayout(timeFormat)
 	return zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoderCfg),
+		newEncoder(config, encoderCfg),
 		w,
-		atom,
+		zapcore.DebugLevel,
 	)
 }
 
-func createConsoleLoggerCore(config config.LoggerConfig, atom zap.AtomicLevel) zapcore.Core {
+func createConsoleLoggerCore(config config.LoggerConfig) zapcore.Core {
 	encoderCfg := zap.NewProductionEncoderConfig()
 	encoderCfg.EncodeLevel = consoleColorLevelEncoder
 	encoderCfg.EncodeTime = zapcore.TimeEncoderOfLayout(timeFormat)
 	return zapcore.NewCore(
-		zapcore.NewConsoleEncoder(encoderCfg),
+		newEncoder(config, encoderCfg),
 		zapcore.AddSync(os.Stdout),
-		atom,
+		zapcore.DebugLevel,
 	)
 }
 
+// JSON output uses plain levels and ISO8601 timestamps, so that logs can be
+// ingested by log collectors
+func newEncoder(config config.LoggerConfig, encoderCfg zapcore.EncoderConfig) zapcore.Encoder {
+	if config.Format == FormatJSON {
+		encoderCfg.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+		return zapcore.NewJSONEncoder(encoderCfg)
+	}
+	return zapcore.NewConsoleEncoder(encoderCfg)
+}
+
 func consoleColorLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
 	s, ok := levelToCapitalColorString[l]
 	if !ok {
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"encoding/json"
+	"flare-tlc/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleLevelsJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "test.log")
+	finalizerLogger := Module("finalizer")
+	chainLogger := Module("chain")
+
+	Configure(config.LoggerConfig{
+		Level:   "INFO",
+		File:    file,
+		Format:  FormatJSON,
+		Modules: map[string]string{"finalizer": "DEBUG"},
+	})
+	defer Configure(DefaultLoggerConfig())
+
+	Debug("default debug")
+	chainLogger.Debug("chain debug")
+	chainLogger.Info("chain info")
+	finalizerLogger.With("votingRoundId", 10).Debug("finalizer debug %d", 1)
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 2)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	require.Equal(t, "chain info", entry["msg"])
+	require.Equal(t, "chain", entry["module"])
+
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	require.Equal(t, "finalizer debug 1", entry["msg"])
+	require.Equal(t, "finalizer", entry["module"])
+	require.Equal(t, "DEBUG", entry["level"])
+	require.EqualValues(t, 10, entry["votingRoundId"])
+}
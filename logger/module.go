@@ -0,0 +1,62 @@
+package logger
+
+import "go.uber.org/zap"
+
+// Logger of a module (e.g. finalizer, system_manager, chain, database). The
+// log level of a module can be set in the logger.modules config, every entry
+// has the module field and the contextual fields added with With.
+type Logger struct {
+	module string
+	fields []interface{}
+}
+
+// Returns the logger of the module, usually assigned to a package variable
+func Module(module string) *Logger {
+	modulesMutex.Lock()
+	defer modulesMutex.Unlock()
+
+	if _, ok := modules[module]; !ok {
+		modules[module] = newModuleSugar(module)
+	}
+	return &Logger{module: module}
+}
+
+// Returns a logger adding the key-value pairs to every entry, e.g.
+// With("votingRoundId", 10, "protocolId", 100)
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	fields := make([]interface{}, 0, len(l.fields)+len(keysAndValues))
+	fields = append(fields, l.fields...)
+	fields = append(fields, keysAndValues...)
+	return &Logger{module: l.module, fields: fields}
+}
+
+func (l *Logger) sugar() *zap.SugaredLogger {
+	modulesMutex.RLock()
+	s := modules[l.module]
+	modulesMutex.RUnlock()
+
+	if len(l.fields) > 0 {
+		return s.With(l.fields...)
+	}
+	return s
+}
+
+func (l *Logger) Warn(msg string, args ...interface{}) {
+	l.sugar().Warnf(msg, args...)
+}
+
+func (l *Logger) Error(msg string, args ...interface{}) {
+	l.sugar().Errorf(msg, args...)
+}
+
+func (l *Logger) Info(msg string, args ...interface{}) {
+	l.sugar().Infof(msg, args...)
+}
+
+func (l *Logger) Debug(msg string, args ...interface{}) {
+	l.sugar().Debugf(msg, args...)
+}
+
+func (l *Logger) Fatal(msg string, args ...interface{}) {
+	l.sugar().Fatalf(msg, args...)
+}
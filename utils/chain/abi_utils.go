@@ -3,15 +3,35 @@ package chain
 import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
 )
 
+// EventIDFromMetadata returns the topic0 of the event, an error if the ABI has no event
+// with the name
 func EventIDFromMetadata(metaData *bind.MetaData, eventName string) (string, error) {
 	abi, err := metaData.GetAbi()
 	if err != nil {
 		return "", err
 	}
-	id := abi.Events[eventName].ID
-	return id.String(), nil
+	event, ok := abi.Events[eventName]
+	if !ok {
+		return "", errors.Errorf("event %s not found in the ABI", eventName)
+	}
+	return event.ID.String(), nil
+}
+
+// MethodIDFromMetadata returns the selector of the method, an error if the ABI has no
+// method with the name
+func MethodIDFromMetadata(metaData *bind.MetaData, methodName string) ([]byte, error) {
+	abi, err := metaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, ok := abi.Methods[methodName]
+	if !ok {
+		return nil, errors.Errorf("method %s not found in the ABI", methodName)
+	}
+	return method.ID, nil
 }
 
 func FunctionSelector(signature string) (selector [4]byte) {
@@ -0,0 +1,27 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var ErrLowBalance = errors.New("sender balance is below the configured minimum")
+
+// Sender accounts with a balance below the configured minimum, set by the balance
+// watcher. No new transactions are sent from these accounts.
+var lowBalanceAccounts sync.Map
+
+func SetLowBalance(address common.Address, low bool) {
+	if low {
+		lowBalanceAccounts.Store(address, true)
+	} else {
+		lowBalanceAccounts.Delete(address)
+	}
+}
+
+func LowBalance(address common.Address) bool {
+	_, ok := lowBalanceAccounts.Load(address)
+	return ok
+}
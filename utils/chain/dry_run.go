@@ -0,0 +1,89 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Process-wide simulation mode, set by the --dry-run command line flag. Transactions
+// are simulated with eth_call and eth_estimateGas instead of being broadcast.
+var dryRun atomic.Bool
+
+func SetDryRun(enabled bool) {
+	dryRun.Store(enabled)
+}
+
+func DryRun() bool {
+	return dryRun.Load()
+}
+
+// Client needed to simulate a transaction
+type SimulationClient interface {
+	ethereum.ContractCaller
+	ethereum.GasEstimator
+}
+
+// Simulates the transaction at the latest block and logs the calldata, the expected gas
+// and the revert reason. Returns an error if the transaction would be reverted.
+func simulateTx(ctx context.Context, client SimulationClient, from common.Address, to common.Address, value *big.Int, data []byte) error {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	}
+	txLogger := logger.With("from", from.Hex(), "to", to.Hex())
+
+	if _, err := client.CallContract(ctx, msg, nil); err != nil {
+		reason := revertReason(err)
+		txLogger.Warn("Dry run: tx would revert: %s, calldata 0x%s", reason, hex.EncodeToString(data))
+		return errors.Errorf("dry run: tx would revert: %s", reason)
+	}
+	gas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		txLogger.Warn("Dry run: unable to estimate gas: %v, calldata 0x%s", err, hex.EncodeToString(data))
+		return errors.Wrap(err, "dry run: unable to estimate gas")
+	}
+	txLogger.Info("Dry run: tx not sent, expected gas %d, calldata 0x%s", gas, hex.EncodeToString(data))
+	return nil
+}
+
+// Simulates a transaction signed by a contract binding with NoSend set
+func simulateSignedTx(ctx context.Context, client SimulationClient, from common.Address, tx *types.Transaction) error {
+	if tx.To() == nil {
+		return errors.New("dry run: contract creation is not supported")
+	}
+	return simulateTx(ctx, client, from, *tx.To(), tx.Value(), tx.Data())
+}
+
+// Returns the revert reason of a failed eth_call. Nodes return the revert data in the
+// error data, the Error(string) reason is decoded if present.
+func revertReason(err error) string {
+	var dataErr interface{ ErrorData() interface{} }
+	if errors.As(err, &dataErr) {
+		if data, ok := dataErr.ErrorData().(string); ok {
+			result, decodeErr := hex.DecodeString(trimHexPrefix(data))
+			if decodeErr == nil {
+				if reason, unpackErr := unpackError(result); unpackErr == nil {
+					return reason
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("%v", err)
+}
+
+func trimHexPrefix(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
@@ -0,0 +1,60 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testDataError struct {
+	data string
+}
+
+func (e testDataError) Error() string { return "execution reverted" }
+
+func (e testDataError) ErrorData() interface{} { return e.data }
+
+type testSimulationClient struct {
+	callErr error
+	gas     uint64
+}
+
+func (c *testSimulationClient) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return nil, c.callErr
+}
+
+func (c *testSimulationClient) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return c.gas, nil
+}
+
+func encodeRevertReason(t *testing.T, reason string) string {
+	packed, err := abi.Arguments{{Type: abiString}}.Pack(reason)
+	require.NoError(t, err)
+	return "0x" + hex.EncodeToString(append(errorSig, packed...))
+}
+
+func TestRevertReason(t *testing.T) {
+	err := testDataError{data: encodeRevertReason(t, "Already relayed")}
+	require.Equal(t, "Already relayed", revertReason(errors.Wrap(err, "call")))
+
+	require.Equal(t, "execution reverted", revertReason(testDataError{data: "0x1234"}))
+	require.Equal(t, "out of gas", revertReason(errors.New("out of gas")))
+}
+
+func TestSimulateTx(t *testing.T) {
+	to := common.HexToAddress("0x01")
+
+	client := &testSimulationClient{gas: 21000}
+	require.NoError(t, simulateTx(context.Background(), client, common.Address{}, to, big.NewInt(0), []byte{1}))
+
+	client.callErr = testDataError{data: encodeRevertReason(t, "Already signed")}
+	err := simulateTx(context.Background(), client, common.Address{}, to, big.NewInt(0), []byte{1})
+	require.ErrorContains(t, err, "Already signed")
+}
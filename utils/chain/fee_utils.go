@@ -0,0 +1,160 @@
+package chain
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBaseFeeMultiplier = 2
+)
+
+// Fee parameters of a transaction. GasPrice is set for legacy transactions,
+// GasFeeCap and GasTipCap for EIP-1559 dynamic fee transactions.
+type TxFees struct {
+	GasPrice  *big.Int
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+}
+
+func (f *TxFees) IsDynamic() bool {
+	return f.GasFeeCap != nil
+}
+
+// Apply sets the fee parameters to the transact opts. The bind package creates
+// a dynamic fee transaction if GasFeeCap and GasTipCap are set.
+func (f *TxFees) Apply(opts *bind.TransactOpts) {
+	opts.GasPrice = f.GasPrice
+	opts.GasFeeCap = f.GasFeeCap
+	opts.GasTipCap = f.GasTipCap
+}
+
+// Scaled returns the fees multiplied by multiplier. The gas price of legacy txs is
+// limited by gasConfig.GasPriceCap, the fee cap of dynamic fee txs by MaxFeePerGas.
+func (f *TxFees) Scaled(multiplier float32, gasConfig *config.GasConfig) *TxFees {
+	gasConfig = gasConfig.Snapshot()
+	if f.IsDynamic() {
+		feeCap := scaledPrice(f.GasFeeCap, multiplier, gasConfig.MaxFeePerGas)
+		return &TxFees{
+			GasFeeCap: feeCap,
+			GasTipCap: scaledPrice(f.GasTipCap, multiplier, feeCap),
+		}
+	}
+	return &TxFees{GasPrice: scaledPrice(f.GasPrice, multiplier, gasConfig.GasPriceCap)}
+}
+
+func scaledPrice(price *big.Int, multiplier float32, priceCap *big.Int) *big.Int {
+	scaled, _ := new(big.Float).Mul(new(big.Float).SetInt(price), big.NewFloat(float64(multiplier))).Int(nil)
+	if priceCap != nil && priceCap.Sign() > 0 && scaled.Cmp(priceCap) > 0 {
+		scaled.Set(priceCap)
+	}
+	return scaled
+}
+
+func (f *TxFees) NewTx(
+	chainID *big.Int, nonce uint64, to common.Address, value *big.Int, gasLimit uint64, data []byte,
+) *types.Transaction {
+	if f.IsDynamic() {
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: f.GasTipCap,
+			GasFeeCap: f.GasFeeCap,
+			Gas:       gasLimit,
+			To:        &to,
+			Value:     value,
+			Data:      data,
+		})
+	}
+	return types.NewTx(&types.LegacyTx{
+		Nonce:    nonce,
+		GasPrice: f.GasPrice,
+		Gas:      gasLimit,
+		To:       &to,
+		Value:    value,
+		Data:     data,
+	})
+}
+
+// GetTxFees returns the fee parameters for the gas strategy selected in the gas config.
+func GetTxFees(gasConfig *config.GasConfig, client *ethclient.Client) (*TxFees, error) {
+	// the values are read once, the config may be reloaded meanwhile
+	gasConfig = gasConfig.Snapshot()
+	switch gasConfig.Strategy() {
+	case config.GasStrategyOracle:
+		blocks, percentile := gasConfig.OracleSettings()
+		gasPrice, err := SharedGasPriceOracle(client, blocks, percentile).SuggestGasPrice(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return &TxFees{GasPrice: multipliedPrice(gasPrice, gasConfig.GasPriceMultiplier)}, nil
+	case config.GasStrategyEIP1559:
+	default:
+		gasPrice, err := GetGasPrice(gasConfig, client)
+		if err != nil {
+			return nil, err
+		}
+		return &TxFees{GasPrice: gasPrice}, nil
+	}
+
+	tip := gasConfig.MaxPriorityFeePerGas
+	if tip == nil || tip.Sign() == 0 {
+		suggestedTip, err := client.SuggestGasTipCap(context.Background())
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to estimate gas tip cap")
+		}
+		tip = suggestedTip
+	}
+
+	header, err := client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to obtain latest block header")
+	}
+	if header.BaseFee == nil {
+		return nil, errors.New("chain does not support dynamic fee transactions")
+	}
+	feeCap := DynamicFeeCap(header.BaseFee, tip, gasConfig)
+	if tip.Cmp(feeCap) > 0 {
+		// tip cannot exceed the fee cap
+		tip = feeCap
+	}
+	return &TxFees{
+		GasFeeCap: feeCap,
+		GasTipCap: tip,
+	}, nil
+}
+
+// DynamicFeeCap returns BaseFeeMultiplier * baseFee + tip, limited by MaxFeePerGas (if nonzero).
+// The multiplier gives headroom for base fee increases while the transaction is pending.
+func DynamicFeeCap(baseFee *big.Int, tip *big.Int, gasConfig *config.GasConfig) *big.Int {
+	gasConfig = gasConfig.Snapshot()
+	multiplier := gasConfig.BaseFeeMultiplier
+	if multiplier == 0 {
+		multiplier = defaultBaseFeeMultiplier
+	}
+	feeCapFloat := new(big.Float).SetInt(baseFee)
+	feeCapFloat.Mul(feeCapFloat, new(big.Float).SetFloat64(float64(multiplier)))
+	feeCap, _ := feeCapFloat.Int(nil)
+	feeCap.Add(feeCap, tip)
+
+	maxFee := gasConfig.MaxFeePerGas
+	if maxFee != nil && maxFee.Sign() > 0 && feeCap.Cmp(maxFee) > 0 {
+		feeCap.Set(maxFee)
+	}
+	return feeCap
+}
+
+func multipliedPrice(price *big.Int, multiplier float32) *big.Int {
+	if multiplier == 0 {
+		return price
+	}
+	scaled, _ := new(big.Float).Mul(new(big.Float).SetInt(price), big.NewFloat(float64(multiplier))).Int(nil)
+	return scaled
+}
@@ -0,0 +1,86 @@
+package chain
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// GasLimit returns the gas limit of the tx of the operation. The fixed gas limit of the
+// config is used if set, otherwise the estimated gas times the gas limit multiplier,
+// limited by the cap of the operation. Returns an error with the decoded revert reason if
+// the estimation fails with a revert, or if the estimated gas exceeds the cap.
+func GasLimit(ctx context.Context, client ethereum.GasEstimator, gasConfig *config.GasConfig, operation string, msg ethereum.CallMsg) (uint64, error) {
+	gasConfig = gasConfig.Snapshot()
+	if gasConfig.GasLimit != 0 {
+		return uint64(gasConfig.GasLimit), nil
+	}
+	limitCap := gasConfig.GasLimitCapFor(operation)
+
+	estimatedGas, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		if isRevert(err) {
+			return 0, errors.Errorf("tx would revert: %s", revertReason(err))
+		}
+		gasLimit := uint64(DefaultGasLimit)
+		if limitCap != 0 && gasLimit > limitCap {
+			gasLimit = limitCap
+		}
+		logger.Warn("Unable to estimate gas for %s: %v, using default gas limit: %d", operation, err, gasLimit)
+		return gasLimit, nil
+	}
+	logger.Debug("Estimated gas for %s: %d", operation, estimatedGas)
+
+	if limitCap != 0 && estimatedGas > limitCap {
+		return 0, errors.Errorf("estimated gas %d for %s exceeds the gas limit cap %d", estimatedGas, operation, limitCap)
+	}
+	return applyGasLimitMargin(estimatedGas, gasConfig.GasLimitMultiplier, limitCap), nil
+}
+
+// ContractGasLimit returns the gas limit of a call of the contract method, see GasLimit
+func ContractGasLimit(
+	ctx context.Context,
+	client ethereum.GasEstimator,
+	gasConfig *config.GasConfig,
+	operation string,
+	from common.Address,
+	to common.Address,
+	metadata *bind.MetaData,
+	method string,
+	args ...interface{},
+) (uint64, error) {
+	contractABI, err := metadata.GetAbi()
+	if err != nil {
+		return 0, errors.Wrap(err, "GetAbi")
+	}
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return 0, errors.Wrapf(err, "packing %s call", method)
+	}
+	return GasLimit(ctx, client, gasConfig, operation, ethereum.CallMsg{From: from, To: &to, Data: data})
+}
+
+func applyGasLimitMargin(estimatedGas uint64, multiplier float32, limitCap uint64) uint64 {
+	gasLimit := estimatedGas
+	if multiplier > 1 {
+		gasLimit = uint64(float64(estimatedGas) * float64(multiplier))
+	}
+	if limitCap != 0 && gasLimit > limitCap {
+		gasLimit = limitCap
+	}
+	return gasLimit
+}
+
+// Estimation errors of reverting txs include revert data or the execution reverted message
+func isRevert(err error) bool {
+	var dataErr interface{ ErrorData() interface{} }
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return true
+	}
+	return strings.Contains(err.Error(), "execution reverted")
+}
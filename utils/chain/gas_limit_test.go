@@ -0,0 +1,57 @@
+package chain
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testGasEstimator struct {
+	gas uint64
+	err error
+}
+
+func (e *testGasEstimator) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return e.gas, e.err
+}
+
+func TestGasLimit(t *testing.T) {
+	gasCfg := &config.GasConfig{
+		GasLimitMultiplier: 1.5,
+		GasLimitCap:        1_000_000,
+		GasLimitCaps:       map[string]uint64{config.RetryOpRelay: 400_000},
+	}
+	tests := []struct {
+		name      string
+		operation string
+		estimator *testGasEstimator
+		expected  uint64
+		errMsg    string
+	}{
+		{"margin", config.RetryOpRegisterVoter, &testGasEstimator{gas: 200_000}, 300_000, ""},
+		{"margin limited by operation cap", config.RetryOpRelay, &testGasEstimator{gas: 300_000}, 400_000, ""},
+		{"estimate above cap", config.RetryOpRelay, &testGasEstimator{gas: 500_000}, 0, "exceeds the gas limit cap"},
+		{"revert", config.RetryOpRelay, &testGasEstimator{err: testDataError{data: encodeRevertReason(t, "Already relayed")}}, 0, "Already relayed"},
+		{"estimation error", config.RetryOpRelay, &testGasEstimator{err: errors.New("timeout")}, 400_000, ""},
+		{"default limited by cap", config.RetryOpSignRewards, &testGasEstimator{err: errors.New("timeout")}, 1_000_000, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gasLimit, err := GasLimit(context.Background(), test.estimator, gasCfg, test.operation, ethereum.CallMsg{})
+			if test.errMsg != "" {
+				require.ErrorContains(t, err, test.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, gasLimit)
+		})
+	}
+
+	gasLimit, err := GasLimit(context.Background(), &testGasEstimator{gas: 1}, &config.GasConfig{GasLimit: 50_000}, config.RetryOpRelay, ethereum.CallMsg{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(50_000), gasLimit)
+}
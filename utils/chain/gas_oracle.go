@@ -0,0 +1,142 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Chain methods used by the gas price oracle, implemented by ethclient.Client
+type gasOracleClient interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// GasPriceOracle suggests the percentile of the gas prices paid by the txs of the
+// last blocks. The price follows the load of the chain better than the node's
+// suggested gas price, which is the minimum price on some networks. The gas prices of
+// the sampled blocks are kept, so only the new blocks are fetched on the next call.
+type GasPriceOracle struct {
+	client     gasOracleClient
+	blocks     int
+	percentile int
+
+	mu sync.Mutex
+	// gas prices of the txs by block number
+	samples   map[uint64][]*big.Int
+	lastBlock uint64
+	lastPrice *big.Int
+}
+
+func NewGasPriceOracle(client gasOracleClient, blocks int, percentile int) *GasPriceOracle {
+	return &GasPriceOracle{
+		client:     client,
+		blocks:     blocks,
+		percentile: percentile,
+		samples:    make(map[uint64][]*big.Int),
+	}
+}
+
+type gasOracleKey struct {
+	client     gasOracleClient
+	blocks     int
+	percentile int
+}
+
+var (
+	sharedGasOracles      = make(map[gasOracleKey]*GasPriceOracle)
+	sharedGasOraclesMutex sync.Mutex
+)
+
+// SharedGasPriceOracle returns the oracle of the client with the settings shared by all
+// callers, so that the blocks are sampled once for all txs
+func SharedGasPriceOracle(client gasOracleClient, blocks int, percentile int) *GasPriceOracle {
+	sharedGasOraclesMutex.Lock()
+	defer sharedGasOraclesMutex.Unlock()
+
+	key := gasOracleKey{client: client, blocks: blocks, percentile: percentile}
+	oracle, ok := sharedGasOracles[key]
+	if !ok {
+		oracle = NewGasPriceOracle(client, blocks, percentile)
+		sharedGasOracles[key] = oracle
+	}
+	return oracle
+}
+
+// SuggestGasPrice returns the percentile of the gas prices of the txs in the last
+// blocks, or the node's suggested gas price if the blocks have no txs
+func (o *GasPriceOracle) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	latest, err := o.client.BlockNumber(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to obtain latest block number")
+	}
+	if o.lastPrice != nil && latest == o.lastBlock {
+		return new(big.Int).Set(o.lastPrice), nil
+	}
+
+	first := uint64(0)
+	if latest+1 > uint64(o.blocks) {
+		first = latest + 1 - uint64(o.blocks)
+	}
+	var prices []*big.Int
+	for number := first; number <= latest; number++ {
+		samples, ok := o.samples[number]
+		if !ok {
+			block, err := o.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+			if err != nil {
+				return nil, errors.Wrapf(err, "Unable to obtain block %d", number)
+			}
+			samples = blockGasPrices(block)
+			o.samples[number] = samples
+		}
+		prices = append(prices, samples...)
+	}
+	for number := range o.samples {
+		if number < first || number > latest {
+			delete(o.samples, number)
+		}
+	}
+
+	var price *big.Int
+	if len(prices) == 0 {
+		price, err = o.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "Unable to estimate gas price")
+		}
+	} else {
+		sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+		price = prices[(len(prices)-1)*o.percentile/100]
+	}
+	logger.Debug("Gas price oracle: %d-th percentile of %d txs in blocks %d-%d: %v", o.percentile, len(prices), first, latest, price)
+	o.lastBlock = latest
+	o.lastPrice = price
+	return new(big.Int).Set(price), nil
+}
+
+// Returns the gas prices paid by the txs of the block, the base fee and the effective
+// tip for dynamic fee txs
+func blockGasPrices(block *types.Block) []*big.Int {
+	baseFee := block.BaseFee()
+	prices := make([]*big.Int, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		if baseFee == nil {
+			prices = append(prices, tx.GasPrice())
+			continue
+		}
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			// fee cap below the base fee, not includable
+			continue
+		}
+		prices = append(prices, tip.Add(tip, baseFee))
+	}
+	return prices
+}
@@ -0,0 +1,79 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// Blocks with the txs of the gas prices, records the fetched block numbers
+type testGasOracleClient struct {
+	baseFee   *big.Int
+	blocks    [][]int64
+	fetched   []uint64
+	suggested *big.Int
+}
+
+func (c *testGasOracleClient) BlockNumber(context.Context) (uint64, error) {
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *testGasOracleClient) BlockByNumber(_ context.Context, number *big.Int) (*types.Block, error) {
+	c.fetched = append(c.fetched, number.Uint64())
+	var txs []*types.Transaction
+	for _, price := range c.blocks[number.Int64()] {
+		if c.baseFee == nil {
+			txs = append(txs, types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(price)}))
+		} else {
+			// the fee cap limits the tip above the base fee
+			txs = append(txs, types.NewTx(&types.DynamicFeeTx{GasFeeCap: big.NewInt(price), GasTipCap: big.NewInt(price)}))
+		}
+	}
+	header := &types.Header{Number: new(big.Int).Set(number), BaseFee: c.baseFee}
+	return types.NewBlockWithHeader(header).WithBody(txs, nil), nil
+}
+
+func (c *testGasOracleClient) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return c.suggested, nil
+}
+
+func TestGasPriceOracle(t *testing.T) {
+	client := &testGasOracleClient{blocks: [][]int64{{100}, {30, 10}, {50}, {20, 40}}}
+	oracle := NewGasPriceOracle(client, 3, 50)
+
+	// prices 10, 20, 30, 40, 50 of blocks 1-3
+	price, err := oracle.SuggestGasPrice(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(30), price)
+	require.Equal(t, []uint64{1, 2, 3}, client.fetched)
+
+	// cached until the next block, then only the new block is fetched
+	_, err = oracle.SuggestGasPrice(context.Background())
+	require.NoError(t, err)
+	require.Len(t, client.fetched, 3)
+	client.blocks = append(client.blocks, []int64{60, 70})
+	price, err = oracle.SuggestGasPrice(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(50), price)
+	require.Equal(t, []uint64{1, 2, 3, 4}, client.fetched)
+
+	// the suggested price is used for blocks without txs
+	client = &testGasOracleClient{blocks: [][]int64{{}, {}}, suggested: big.NewInt(25)}
+	price, err = NewGasPriceOracle(client, 20, 60).SuggestGasPrice(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(25), price)
+}
+
+func TestGasPriceOracleBaseFee(t *testing.T) {
+	// dynamic fee txs pay the base fee and the tip up to the fee cap
+	client := &testGasOracleClient{baseFee: big.NewInt(25), blocks: [][]int64{{20, 30, 40, 60}}}
+	price, err := NewGasPriceOracle(client, 1, 100).SuggestGasPrice(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(60), price)
+	price, err = NewGasPriceOracle(client, 1, 0).SuggestGasPrice(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(30), price)
+}
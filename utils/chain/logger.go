@@ -0,0 +1,5 @@
+package chain
+
+import flarelogger "flare-tlc/logger"
+
+var logger = flarelogger.Module("chain")
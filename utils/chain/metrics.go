@@ -0,0 +1,98 @@
+package chain
+
+import (
+	"flare-tlc/utils/metrics"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	txSentCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_sent_total",
+		Help:      "Number of transactions sent, including replacement transactions",
+	})
+	txMinedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_mined_total",
+		Help:      "Number of transactions mined successfully",
+	})
+	txFailedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_failed_total",
+		Help:      "Number of transactions that reverted or were not mined in time",
+	})
+	txMineDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_mine_duration_seconds",
+		Help:      "Time from sending a transaction until it is mined",
+		Buckets:   []float64{1, 2, 5, 10, 20, 30, 60},
+	})
+	txTimeoutCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_timeouts_total",
+		Help:      "Number of transactions not mined within the tx timeout, per operation",
+	}, []string{"operation"})
+	txMissingEventsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_missing_events_total",
+		Help:      "Number of mined transactions without the expected events in the receipt logs, per operation",
+	}, []string{"operation"})
+)
+
+// unix time of the last successfully mined transaction
+var lastMinedTx atomic.Int64
+
+// LastMinedTxTime returns the time the last transaction sent by this process was mined,
+// zero time if no transaction was mined yet.
+func LastMinedTxTime() time.Time {
+	ts := lastMinedTx.Load()
+	if ts == 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0)
+}
+
+// TxFailureHook is called with the number of consecutive failed txs and the error
+// after a failed tx, and with the number of the failed txs before and a nil error
+// after a mined tx following failures
+type TxFailureHook func(failures int64, err error)
+
+var (
+	// failed txs since the last mined tx
+	consecutiveTxFailures atomic.Int64
+	txFailureHook         atomic.Pointer[TxFailureHook]
+)
+
+// SetTxFailureHook sets the hook called on the failed txs, e.g. to fire an alert, nil
+// removes it
+func SetTxFailureHook(hook TxFailureHook) {
+	if hook == nil {
+		txFailureHook.Store(nil)
+		return
+	}
+	txFailureHook.Store(&hook)
+}
+
+func callTxFailureHook(failures int64, err error) {
+	if hook := txFailureHook.Load(); hook != nil {
+		(*hook)(failures, err)
+	}
+}
+
+func observeTxResult(start time.Time, err error) {
+	if err != nil {
+		txFailedCounter.Inc()
+		callTxFailureHook(consecutiveTxFailures.Add(1), err)
+		return
+	}
+	if failures := consecutiveTxFailures.Swap(0); failures > 0 {
+		callTxFailureHook(failures, nil)
+	}
+	lastMinedTx.Store(time.Now().Unix())
+	txMinedCounter.Inc()
+	txMineDuration.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,245 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// Consecutive sends for which the node's pending nonce stays below the local nonce
+// before the nonce is resynced from the node. A tx of the gap was then dropped from
+// the mempool, the later txs are queued by the node until the gap is filled.
+const nonceGapSends = 3
+
+var (
+	// Shared by all clients in the process, so that clients using the same sender
+	// account do not assign the same nonce to concurrent transactions.
+	defaultNonceManager = NewNonceManager()
+
+	nonceErrors = []string{
+		"nonce too low",
+		"nonce too high",
+		"replacement transaction underpriced",
+	}
+
+	// Errors of a node that already has the tx with the same hash in its pool, e.g. a
+	// tx sent again after a failover to another node that received it before
+	alreadyKnownErrors = []string{
+		"already known",
+		"known transaction",
+	}
+)
+
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+type TxSender interface {
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// Client of TransactWithNonce
+type TransactClient interface {
+	NonceSource
+	TxSender
+}
+
+// NonceManager assigns nonces to transactions per sender address. Sends from the
+// same address are serialized until the transaction is accepted by the node. The next
+// nonce is tracked locally, the node's pending nonce is only used if it is higher: a
+// node behind the others, e.g. after a failover to another rpc node, may not know the
+// last sent txs yet. The nonce is resynced from the node after a nonce error, or if
+// the pending nonce stays lower for nonceGapSends sends.
+type NonceManager struct {
+	senders map[common.Address]*senderNonce
+	mu      sync.Mutex
+}
+
+type senderNonce struct {
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+
+	// consecutive sends with the node's pending nonce below next
+	behind int
+}
+
+func NewNonceManager() *NonceManager {
+	return &NonceManager{senders: make(map[common.Address]*senderNonce)}
+}
+
+// DefaultNonceManager returns the process-wide nonce manager
+func DefaultNonceManager() *NonceManager {
+	return defaultNonceManager
+}
+
+// SendWithNonce calls send with the next nonce of the sender, using the process-wide nonce manager.
+func SendWithNonce(ctx context.Context, client NonceSource, from common.Address, send func(nonce uint64) error) error {
+	return defaultNonceManager.Send(ctx, client, from, send)
+}
+
+// Send calls send with the next nonce of from, the higher of the local and the node's
+// pending nonce. The nonce is consumed if send succeeds. If send fails with a nonce
+// related error, the nonce is resynced from the node before the next transaction.
+// Returns ErrLowBalance without calling send if the balance of from is below the
+// minimum of the balance watcher, ErrStandby if the instance is on standby.
+func (m *NonceManager) Send(ctx context.Context, client NonceSource, from common.Address, send func(nonce uint64) error) error {
+	if Standby() {
+		return errors.Wrapf(ErrStandby, "not sending tx from %s", from.Hex())
+	}
+	if LowBalance(from) {
+		return errors.Wrapf(ErrLowBalance, "not sending tx from %s", from.Hex())
+	}
+	s := m.sender(from)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pendingNonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return errors.Wrap(err, "PendingNonceAt")
+	}
+
+	switch {
+	case !s.synced || pendingNonce > s.next:
+		// first transaction, after a nonce error or transactions were sent from the
+		// account outside this process
+		s.next = pendingNonce
+		s.synced = true
+		s.behind = 0
+	case pendingNonce < s.next:
+		s.behind++
+		if s.behind >= nonceGapSends {
+			logger.Warn("Pending nonce %d of %s stayed below the local nonce %d, resyncing", pendingNonce, from.Hex(), s.next)
+			s.next = pendingNonce
+			s.behind = 0
+		}
+	default:
+		s.behind = 0
+	}
+
+	nonce := s.next
+	if err := send(nonce); err != nil {
+		if isNonceError(err) {
+			logger.Warn("Nonce %d rejected for %s, resyncing: %v", nonce, from.Hex(), err)
+			s.synced = false
+		}
+		return err
+	}
+	s.next = nonce + 1
+	return nil
+}
+
+func (m *NonceManager) sender(from common.Address) *senderNonce {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.senders[from]
+	if !ok {
+		s = &senderNonce{}
+		m.senders[from] = s
+	}
+	return s
+}
+
+func isNonceError(err error) bool {
+	return errorContains(err, nonceErrors)
+}
+
+func isAlreadyKnown(err error) bool {
+	return errorContains(err, alreadyKnownErrors)
+}
+
+func errorContains(err error, messages []string) bool {
+	msg := strings.ToLower(err.Error())
+	for _, m := range messages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// SendTx sends the signed tx. A node that already has the tx in its pool rejects it as
+// known, the tx was then sent.
+func SendTx(ctx context.Context, client TxSender, tx *types.Transaction) error {
+	err := client.SendTransaction(ctx, tx)
+	if err != nil && isAlreadyKnown(err) {
+		logger.Info("Tx %s is already known to the node: %v", tx.Hash().Hex(), err)
+		return nil
+	}
+	return err
+}
+
+// TransactWithNonce calls a contract binding method with a copy of opts, with the nonce
+// assigned by the process-wide nonce manager. The binding signs the tx, it is sent with
+// SendTx. In dry run mode the signed tx is only simulated, client must then also
+// implement SimulationClient. In observer mode the tx is built unsigned and not sent.
+func TransactWithNonce(
+	client TransactClient, opts *bind.TransactOpts, transact func(*bind.TransactOpts) (*types.Transaction, error),
+) (*types.Transaction, error) {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if Observer() {
+		return observeTransact(opts, transact)
+	}
+	if DryRun() {
+		return simulateTransact(ctx, client, opts, transact)
+	}
+
+	var tx *types.Transaction
+	err := SendWithNonce(ctx, client, opts.From, func(nonce uint64) error {
+		nonceOpts := *opts
+		nonceOpts.Nonce = new(big.Int).SetUint64(nonce)
+		nonceOpts.NoSend = true
+
+		signedTx, err := transact(&nonceOpts)
+		if err != nil {
+			return err
+		}
+		if err := SendTx(ctx, client, signedTx); err != nil {
+			return err
+		}
+		tx = signedTx
+		return nil
+	})
+	return tx, err
+}
+
+// The nonce is not consumed, the binding signs the tx without sending it
+func simulateTransact(
+	ctx context.Context, client TransactClient, opts *bind.TransactOpts, transact func(*bind.TransactOpts) (*types.Transaction, error),
+) (*types.Transaction, error) {
+	simClient, ok := client.(SimulationClient)
+	if !ok {
+		return nil, errors.New("dry run: client does not support simulation")
+	}
+	nonce, err := client.PendingNonceAt(ctx, opts.From)
+	if err != nil {
+		return nil, errors.Wrap(err, "PendingNonceAt")
+	}
+
+	simOpts := *opts
+	simOpts.Nonce = new(big.Int).SetUint64(nonce)
+	simOpts.NoSend = true
+	if simOpts.GasLimit == 0 {
+		// the binding would fail on gas estimation of a reverting tx before the revert reason is logged
+		simOpts.GasLimit = DefaultGasLimit
+	}
+	tx, err := transact(&simOpts)
+	if err != nil {
+		return nil, err
+	}
+	if err := simulateSignedTx(ctx, simClient, opts.From, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testTxSender struct {
+	err error
+}
+
+func (s testTxSender) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return s.err
+}
+
+type testNonceSource struct {
+	pendingNonce uint64
+}
+
+func (s *testNonceSource) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return s.pendingNonce, nil
+}
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	from := common.HexToAddress("0x01")
+	source := &testNonceSource{pendingNonce: 5}
+	m := NewNonceManager()
+
+	sendWithNonce := func(sendErr error) uint64 {
+		var nonce uint64
+		_ = m.Send(ctx, source, from, func(n uint64) error {
+			nonce = n
+			return sendErr
+		})
+		return nonce
+	}
+
+	require.Equal(t, uint64(5), sendWithNonce(nil))
+	source.pendingNonce = 6
+	require.Equal(t, uint64(6), sendWithNonce(nil))
+
+	// failed send does not consume the nonce
+	source.pendingNonce = 7
+	require.Equal(t, uint64(7), sendWithNonce(errors.New("execution reverted")))
+	require.Equal(t, uint64(7), sendWithNonce(nil))
+
+	// tx sent by another process
+	source.pendingNonce = 10
+	require.Equal(t, uint64(10), sendWithNonce(nil))
+
+	// node behind the last sent txs, the local nonce is used
+	source.pendingNonce = 9
+	require.Equal(t, uint64(11), sendWithNonce(nil))
+
+	// nonce error triggers resync
+	require.Equal(t, uint64(12), sendWithNonce(errors.New("nonce too high")))
+	require.Equal(t, uint64(9), sendWithNonce(nil))
+
+	// a dropped tx leaves a gap, the node's pending nonce stays behind and is used after nonceGapSends sends
+	source.pendingNonce = 10
+	require.Equal(t, uint64(10), sendWithNonce(nil))
+	require.Equal(t, uint64(11), sendWithNonce(nil))
+	require.Equal(t, uint64(12), sendWithNonce(nil))
+	require.Equal(t, uint64(10), sendWithNonce(nil))
+
+	// the node catching up resets the count
+	source.pendingNonce = 11
+	require.Equal(t, uint64(11), sendWithNonce(nil))
+	require.Equal(t, uint64(12), sendWithNonce(nil))
+	source.pendingNonce = 13
+	require.Equal(t, uint64(13), sendWithNonce(nil))
+	source.pendingNonce = 12
+	require.Equal(t, uint64(14), sendWithNonce(nil))
+	require.Equal(t, uint64(15), sendWithNonce(nil))
+}
+
+func TestSendTxAlreadyKnown(t *testing.T) {
+	ctx := context.Background()
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1})
+
+	require.NoError(t, SendTx(ctx, testTxSender{}, tx))
+	// the tx sent again after a failover is in the node's pool
+	require.NoError(t, SendTx(ctx, testTxSender{errors.New("already known")}, tx))
+	require.NoError(t, SendTx(ctx, testTxSender{errors.New("known transaction: 0x01")}, tx))
+	require.EqualError(t, SendTx(ctx, testTxSender{errors.New("nonce too low")}, tx), "nonce too low")
+	require.False(t, isNonceError(errors.New("already known")))
+}
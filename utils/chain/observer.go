@@ -0,0 +1,68 @@
+package chain
+
+import (
+	"encoding/hex"
+	"flare-tlc/utils/metrics"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Process-wide read-only mode, set by the --observer command line flag. The clients
+// run as usual, but transactions are not signed, sent or simulated. The
+// transactions the client would have sent are logged and counted in tx_observed_total.
+var observer atomic.Bool
+
+var txObservedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Name:      "tx_observed_total",
+	Help:      "Number of transactions not sent in observer mode, per operation",
+}, []string{"operation"})
+
+func SetObserver(enabled bool) {
+	observer.Store(enabled)
+}
+
+func Observer() bool {
+	return observer.Load()
+}
+
+// Broadcasting returns false if the transactions are not sent, in dry run and
+// observer mode
+func Broadcasting() bool {
+	return !DryRun() && !Observer()
+}
+
+// Logs and counts a transaction the client would have sent
+func recordObservedTx(operation string, from common.Address, to *common.Address, data []byte) {
+	toAddress := "<none>"
+	if to != nil {
+		toAddress = to.Hex()
+	}
+	logger.With("from", from.Hex(), "to", toAddress).
+		Info("Observer: %s tx not sent, calldata 0x%s", operation, hex.EncodeToString(data))
+	txObservedCounter.WithLabelValues(operation).Inc()
+}
+
+// Builds the tx of a contract binding without signing or sending it, the nonce is not
+// consumed. The tx is recorded by TxVerifier when the caller waits for it.
+func observeTransact(
+	opts *bind.TransactOpts, transact func(*bind.TransactOpts) (*types.Transaction, error),
+) (*types.Transaction, error) {
+	observeOpts := *opts
+	observeOpts.Nonce = new(big.Int)
+	observeOpts.NoSend = true
+	observeOpts.Signer = func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return tx, nil
+	}
+	if observeOpts.GasLimit == 0 {
+		// gas estimation fails if the tx would revert, e.g. for an unregistered sender
+		observeOpts.GasLimit = DefaultGasLimit
+	}
+	return transact(&observeOpts)
+}
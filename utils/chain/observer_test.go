@@ -0,0 +1,48 @@
+package chain
+
+import (
+	"flare-tlc/utils/credentials"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserver(t *testing.T) {
+	SetObserver(true)
+	defer SetObserver(false)
+	require.False(t, Broadcasting())
+
+	from := common.HexToAddress("0x01")
+	to := common.HexToAddress("0x02")
+	opts := &bind.TransactOpts{
+		From: from,
+		Signer: func(common.Address, *types.Transaction) (*types.Transaction, error) {
+			return nil, errors.New("observer must not sign")
+		},
+	}
+	tx, err := TransactWithNonce(nil, opts, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		require.True(t, opts.NoSend)
+		tx := types.NewTransaction(opts.Nonce.Uint64(), to, big.NewInt(0), opts.GasLimit, big.NewInt(1), []byte{1, 2})
+		return opts.Signer(opts.From, tx)
+	})
+	require.NoError(t, err)
+	require.Equal(t, uint64(DefaultGasLimit), tx.Gas())
+
+	observed := testutil.ToFloat64(txObservedCounter.WithLabelValues("test_observer"))
+	minedTx, err := TxVerifier{}.WaitUntilMinedWithEscalation(from, tx, opts.Signer, nil, "test_observer", DefaultTxTimeout)
+	require.NoError(t, err)
+	require.Equal(t, tx, minedTx)
+	require.Equal(t, observed+1, testutil.ToFloat64(txObservedCounter.WithLabelValues("test_observer")))
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, SendRawTx(nil, credentials.NewPrivateKeySigner(key), to, []byte{3}, true, nil, "test_observer"))
+	require.Equal(t, observed+2, testutil.ToFloat64(txObservedCounter.WithLabelValues("test_observer")))
+}
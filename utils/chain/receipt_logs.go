@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+var ErrExpectedEventMissing = errors.New("expected event not found in tx receipt logs")
+
+// Process-wide receipt log verification, set by tx_verification.receipt_logs. If
+// enabled, a mined tx without the expected events is treated as failed.
+var verifyReceiptLogs atomic.Bool
+
+func SetVerifyReceiptLogs(enabled bool) {
+	verifyReceiptLogs.Store(enabled)
+}
+
+// Event expected in the logs of a mined tx, e.g. SigningPolicySigned of a
+// signNewSigningPolicy tx. A log matches if it is emitted by Address, has the
+// topic0 of the event and, if Match is set, Match returns true for it.
+type ExpectedEvent struct {
+	Address  common.Address
+	MetaData *bind.MetaData
+	Name     string
+	Match    func(log types.Log) bool
+}
+
+// Returns an error wrapping ErrExpectedEventMissing if one of the events is not
+// in the receipt logs
+func checkReceiptLogs(receipt *types.Receipt, expected []ExpectedEvent) error {
+	for _, event := range expected {
+		found, err := event.find(receipt.Logs)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return errors.Wrapf(ErrExpectedEventMissing, "%s of %s in tx %s", event.Name, event.Address.Hex(), receipt.TxHash.Hex())
+		}
+	}
+	return nil
+}
+
+func (e ExpectedEvent) find(logs []*types.Log) (bool, error) {
+	contractAbi, err := e.MetaData.GetAbi()
+	if err != nil {
+		return false, errors.Wrap(err, "error parsing contract abi")
+	}
+	abiEvent, ok := contractAbi.Events[e.Name]
+	if !ok {
+		return false, errors.Errorf("unknown event %s", e.Name)
+	}
+	for _, log := range logs {
+		if log.Address != e.Address || len(log.Topics) == 0 || log.Topics[0] != abiEvent.ID {
+			continue
+		}
+		if e.Match == nil || e.Match(*log) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,47 @@
+package chain
+
+import (
+	"flare-tlc/utils/contracts/relay"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReceiptLogs(t *testing.T) {
+	relayAbi, err := relay.RelayMetaData.GetAbi()
+	require.NoError(t, err)
+	topic0 := relayAbi.Events["ProtocolMessageRelayed"].ID
+	address := common.HexToAddress("0x1")
+
+	receipt := &types.Receipt{Logs: []*types.Log{
+		{Address: common.HexToAddress("0x2"), Topics: []common.Hash{topic0, common.BigToHash(common.Big1)}},
+		{Address: address, Topics: []common.Hash{topic0, common.BigToHash(common.Big2)}},
+	}}
+	expected := func(protocolId int64) ExpectedEvent {
+		return ExpectedEvent{
+			Address:  address,
+			MetaData: relay.RelayMetaData,
+			Name:     "ProtocolMessageRelayed",
+			Match: func(log types.Log) bool {
+				return log.Topics[1].Big().Int64() == protocolId
+			},
+		}
+	}
+
+	require.NoError(t, checkReceiptLogs(receipt, nil))
+	require.NoError(t, checkReceiptLogs(receipt, []ExpectedEvent{expected(2)}))
+	// the log of the other contract does not match
+	err = checkReceiptLogs(receipt, []ExpectedEvent{expected(1)})
+	require.True(t, errors.Is(err, ErrExpectedEventMissing))
+
+	event := expected(2)
+	event.Name = "SigningPolicyInitialized"
+	require.True(t, errors.Is(checkReceiptLogs(receipt, []ExpectedEvent{event}), ErrExpectedEventMissing))
+	event.Name = "Unknown"
+	err = checkReceiptLogs(receipt, []ExpectedEvent{event})
+	require.Error(t, err)
+	require.False(t, errors.Is(err, ErrExpectedEventMissing))
+}
@@ -0,0 +1,236 @@
+package chain
+
+import (
+	"flare-tlc/client/config"
+	"flare-tlc/utils/metrics"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Operation types of the spend accounting
+const (
+	SpendFinalization = "finalization"
+	SpendRegistration = "registration"
+	SpendSigning      = "signing"
+	SpendSubmission   = "submission"
+	SpendOther        = "other"
+)
+
+var (
+	txGasUsedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_gas_used_total",
+		Help:      "Gas used by mined transactions, including reverted ones, per operation type",
+	}, []string{"operation"})
+	txSpendCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_spend_total",
+		Help:      "Fees paid for mined transactions, in FLR, per operation type",
+	}, []string{"operation"})
+	dailySpendGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_daily_spend",
+		Help:      "Fees paid for transactions mined during the current UTC day, in FLR, per operation type",
+	}, []string{"operation"})
+	dailyBudgetExceededGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Name:      "tx_daily_budget_exceeded",
+		Help:      "1 if the fees paid during the current UTC day exceed the daily budget and non-critical transactions are not sent",
+	})
+)
+
+// SpendOperation returns the operation type of the retry operation of a transaction
+func SpendOperation(retryOperation string) string {
+	switch retryOperation {
+	case config.RetryOpRelay:
+		return SpendFinalization
+	case config.RetryOpRegisterVoter, config.RetryOpEntityRegistration:
+		return SpendRegistration
+	case config.RetryOpSignNewSigningPolicy, config.RetryOpSignUptimeVote, config.RetryOpSignRewards:
+		return SpendSigning
+	case config.RetryOpSubmit, config.RetryOpSubmitUpdates:
+		return SpendSubmission
+	default:
+		return SpendOther
+	}
+}
+
+type OperationSpend struct {
+	Operation string `json:"operation"`
+	GasUsed   uint64 `json:"gas_used"`
+	Fees      string `json:"fees"` // in wei
+	Txs       int    `json:"txs"`
+}
+
+type SpendReport struct {
+	Day            string           `json:"day"` // UTC day of the daily values, YYYY-MM-DD
+	DailyBudget    string           `json:"daily_budget,omitempty"`
+	BudgetExceeded bool             `json:"budget_exceeded"`
+	Daily          []OperationSpend `json:"daily"`
+	Total          []OperationSpend `json:"total"` // since the client started
+}
+
+type operationSpend struct {
+	gasUsed uint64
+	fees    *big.Int
+	txs     int
+}
+
+func (s *operationSpend) add(gasUsed uint64, fees *big.Int) {
+	s.gasUsed += gasUsed
+	s.fees.Add(s.fees, fees)
+	s.txs++
+}
+
+// Fees paid per operation type, since the client started and during the current
+// UTC day. If the daily fees exceed the budget, non-critical operations are skipped
+// until the next day.
+type spendTracker struct {
+	now func() time.Time
+
+	mu     sync.Mutex
+	budget *big.Int // nil or 0 disables the budget
+	day    time.Time
+	daily  map[string]*operationSpend
+	total  map[string]*operationSpend
+}
+
+func newSpendTracker(now func() time.Time) *spendTracker {
+	return &spendTracker{
+		now:   now,
+		daily: make(map[string]*operationSpend),
+		total: make(map[string]*operationSpend),
+	}
+}
+
+// Process-wide spend accounting of the txs sent by this client
+var spend = newSpendTracker(time.Now)
+
+// SetDailyBudget sets the maximum fees in wei paid per UTC day, nil or 0 disables the budget
+func SetDailyBudget(budget *big.Int) {
+	spend.setBudget(budget)
+}
+
+// DailyBudgetExceeded returns true if non-critical operations, e.g. backup
+// finalizations, should not send transactions
+func DailyBudgetExceeded() bool {
+	return spend.budgetExceeded()
+}
+
+func Spend() SpendReport {
+	return spend.report()
+}
+
+func (t *spendTracker) setBudget(budget *big.Int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.budget = budget
+	t.updateBudgetGauge()
+}
+
+func (t *spendTracker) record(operation string, gasUsed uint64, gasPrice *big.Int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover()
+	fees := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), gasPrice)
+	for _, spends := range []map[string]*operationSpend{t.daily, t.total} {
+		s, ok := spends[operation]
+		if !ok {
+			s = &operationSpend{fees: big.NewInt(0)}
+			spends[operation] = s
+		}
+		s.add(gasUsed, fees)
+	}
+
+	txGasUsedCounter.WithLabelValues(operation).Add(float64(gasUsed))
+	txSpendCounter.WithLabelValues(operation).Add(weiToFLR(fees))
+	dailySpendGauge.WithLabelValues(operation).Set(weiToFLR(t.daily[operation].fees))
+	t.updateBudgetGauge()
+}
+
+func (t *spendTracker) budgetExceeded() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover()
+	return t.exceeded()
+}
+
+func (t *spendTracker) report() SpendReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollover()
+	report := SpendReport{
+		Day:            t.day.Format(time.DateOnly),
+		BudgetExceeded: t.exceeded(),
+		Daily:          operationSpends(t.daily),
+		Total:          operationSpends(t.total),
+	}
+	if t.budget != nil && t.budget.Sign() > 0 {
+		report.DailyBudget = t.budget.String()
+	}
+	return report
+}
+
+// Resets the daily values when a new UTC day starts
+func (t *spendTracker) rollover() {
+	day := t.now().UTC().Truncate(24 * time.Hour)
+	if day.Equal(t.day) {
+		return
+	}
+	t.day = day
+	for operation := range t.daily {
+		dailySpendGauge.WithLabelValues(operation).Set(0)
+	}
+	t.daily = make(map[string]*operationSpend)
+	t.updateBudgetGauge()
+}
+
+func (t *spendTracker) exceeded() bool {
+	if t.budget == nil || t.budget.Sign() <= 0 {
+		return false
+	}
+	fees := big.NewInt(0)
+	for _, s := range t.daily {
+		fees.Add(fees, s.fees)
+	}
+	return fees.Cmp(t.budget) > 0
+}
+
+func (t *spendTracker) updateBudgetGauge() {
+	if t.exceeded() {
+		dailyBudgetExceededGauge.Set(1)
+	} else {
+		dailyBudgetExceededGauge.Set(0)
+	}
+}
+
+func operationSpends(spends map[string]*operationSpend) []OperationSpend {
+	result := make([]OperationSpend, 0, len(spends))
+	for operation, s := range spends {
+		result = append(result, OperationSpend{
+			Operation: operation,
+			GasUsed:   s.gasUsed,
+			Fees:      s.fees.String(),
+			Txs:       s.txs,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Operation < result[j].Operation
+	})
+	return result
+}
+
+func weiToFLR(wei *big.Int) float64 {
+	flr, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(params.Ether)).Float64()
+	return flr
+}
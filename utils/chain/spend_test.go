@@ -0,0 +1,52 @@
+package chain
+
+import (
+	"flare-tlc/client/config"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendTracker(t *testing.T) {
+	now := time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)
+	tracker := newSpendTracker(func() time.Time { return now })
+	tracker.setBudget(big.NewInt(1000))
+
+	tracker.record(SpendOperation(config.RetryOpRelay), 10, big.NewInt(50))
+	tracker.record(SpendOperation(config.RetryOpSignRewards), 5, big.NewInt(100))
+	require.False(t, tracker.budgetExceeded())
+
+	tracker.record(SpendOperation(config.RetryOpRelay), 1, big.NewInt(1))
+	require.True(t, tracker.budgetExceeded())
+
+	report := tracker.report()
+	require.Equal(t, "2024-03-01", report.Day)
+	require.Equal(t, "1000", report.DailyBudget)
+	require.True(t, report.BudgetExceeded)
+	require.Equal(t, []OperationSpend{
+		{Operation: SpendFinalization, GasUsed: 11, Fees: "501", Txs: 2},
+		{Operation: SpendSigning, GasUsed: 5, Fees: "500", Txs: 1},
+	}, report.Daily)
+
+	// daily values are reset on the next UTC day
+	now = now.Add(time.Hour)
+	require.False(t, tracker.budgetExceeded())
+	report = tracker.report()
+	require.Equal(t, "2024-03-02", report.Day)
+	require.Empty(t, report.Daily)
+	require.Len(t, report.Total, 2)
+
+	// no budget
+	tracker.setBudget(nil)
+	tracker.record(SpendRegistration, 1_000_000, big.NewInt(1_000_000))
+	require.False(t, tracker.budgetExceeded())
+}
+
+func TestSpendOperation(t *testing.T) {
+	require.Equal(t, SpendRegistration, SpendOperation(config.RetryOpRegisterVoter))
+	require.Equal(t, SpendSigning, SpendOperation(config.RetryOpSignNewSigningPolicy))
+	require.Equal(t, SpendSubmission, SpendOperation(config.RetryOpSubmit))
+	require.Equal(t, SpendOther, SpendOperation(""))
+}
@@ -0,0 +1,44 @@
+package chain
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrStandby = errors.New("instance is on standby, the leader instance sends the transactions")
+
+// Process-wide standby mode of a replicated instance that does not hold the leader
+// lease, set by the leader election. The clients run as usual, but no new
+// transactions are sent.
+var (
+	standby atomic.Bool
+	leader  atomic.Pointer[leaderDeadline]
+)
+
+// End of the sending of the leader, the instance is on standby from then on
+type leaderDeadline struct {
+	until time.Time
+	now   func() time.Time
+}
+
+func SetStandby(enabled bool) {
+	leader.Store(nil)
+	standby.Store(enabled)
+}
+
+// SetLeaderUntil ends the standby mode until the time until of the clock now, the
+// instance is on standby from then on unless the deadline is extended
+func SetLeaderUntil(until time.Time, now func() time.Time) {
+	leader.Store(&leaderDeadline{until: until, now: now})
+	standby.Store(false)
+}
+
+func Standby() bool {
+	if standby.Load() {
+		return true
+	}
+	deadline := leader.Load()
+	return deadline != nil && !deadline.now().Before(deadline.until)
+}
@@ -0,0 +1,125 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// Outcomes of the sent txs in the audit log
+const (
+	TxStatusMined      = "mined"
+	TxStatusReverted   = "reverted"
+	TxStatusFailed     = "failed" // mined, but the expected events are missing
+	TxStatusTimeout    = "timeout"
+	TxStatusError      = "error" // waiting for the receipt failed
+	TxStatusSendFailed = "send_failed"
+)
+
+// Sent tx with its outcome, see SetTxAuditLog
+type TxAuditRecord struct {
+	Operation    string
+	From         common.Address
+	To           *common.Address
+	TxHash       common.Hash // the mined replacement tx if the tx was replaced
+	CalldataHash common.Hash
+	Nonce        uint64
+	GasLimit     uint64
+	GasPrice     *big.Int // the fee cap of dynamic fee txs
+	GasUsed      uint64
+	Status       string
+	BlockNumber  uint64 // 0 if not mined
+	Error        string
+	Time         time.Time // time of the send
+}
+
+// Durable record of the txs sent by all clients
+type TxAuditLog interface {
+	Record(record *TxAuditRecord)
+}
+
+type txAuditLogHolder struct {
+	log TxAuditLog
+}
+
+// Process-wide audit log, nil if disabled
+var txAuditLog atomic.Pointer[txAuditLogHolder]
+
+// SetTxAuditLog sets the log the outcome of every sent tx is recorded to, nil
+// disables the audit log. Txs are not recorded in dry run and observer mode.
+func SetTxAuditLog(log TxAuditLog) {
+	if log == nil {
+		txAuditLog.Store(nil)
+		return
+	}
+	txAuditLog.Store(&txAuditLogHolder{log: log})
+}
+
+// Records the outcome of the tx sent at start, minedTx and receipt are nil if the tx
+// was not mined
+func recordTxAudit(
+	operation string, from common.Address, tx *types.Transaction, minedTx *types.Transaction, receipt *types.Receipt, start time.Time, err error,
+) {
+	holder := txAuditLog.Load()
+	if holder == nil {
+		return
+	}
+	if minedTx == nil {
+		minedTx = tx
+	}
+	record := newTxAuditRecord(operation, from, minedTx, start)
+	switch {
+	case receipt != nil && receipt.Status != types.ReceiptStatusSuccessful:
+		record.Status = TxStatusReverted
+	case receipt != nil && err != nil:
+		record.Status = TxStatusFailed
+	case receipt != nil:
+		record.Status = TxStatusMined
+	case errors.Is(err, context.DeadlineExceeded):
+		record.Status = TxStatusTimeout
+	default:
+		record.Status = TxStatusError
+	}
+	if receipt != nil {
+		record.GasUsed = receipt.GasUsed
+		if receipt.BlockNumber != nil {
+			record.BlockNumber = receipt.BlockNumber.Uint64()
+		}
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	holder.log.Record(record)
+}
+
+// Records a tx that was signed, but not accepted by the node
+func recordTxAuditSendFailed(operation string, from common.Address, tx *types.Transaction, err error) {
+	holder := txAuditLog.Load()
+	if holder == nil {
+		return
+	}
+	record := newTxAuditRecord(operation, from, tx, time.Now())
+	record.Status = TxStatusSendFailed
+	record.Error = err.Error()
+	holder.log.Record(record)
+}
+
+func newTxAuditRecord(operation string, from common.Address, tx *types.Transaction, start time.Time) *TxAuditRecord {
+	return &TxAuditRecord{
+		Operation:    operation,
+		From:         from,
+		To:           tx.To(),
+		TxHash:       tx.Hash(),
+		CalldataHash: crypto.Keccak256Hash(tx.Data()),
+		Nonce:        tx.Nonce(),
+		GasLimit:     tx.Gas(),
+		GasPrice:     tx.GasPrice(),
+		Time:         start,
+	}
+}
@@ -0,0 +1,66 @@
+package chain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type testTxAuditLog struct {
+	records []*TxAuditRecord
+}
+
+func (l *testTxAuditLog) Record(record *TxAuditRecord) {
+	l.records = append(l.records, record)
+}
+
+func TestRecordTxAudit(t *testing.T) {
+	txLog := &testTxAuditLog{}
+	SetTxAuditLog(txLog)
+	defer SetTxAuditLog(nil)
+
+	from := common.HexToAddress("0x01")
+	tx := types.NewTransaction(3, common.HexToAddress("0x02"), big.NewInt(0), 50_000, big.NewInt(10), []byte{1, 2})
+	replacement := types.NewTransaction(3, common.HexToAddress("0x02"), big.NewInt(0), 50_000, big.NewInt(11), []byte{1, 2})
+	start := time.Unix(1000, 0)
+	mined := &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 40_000, BlockNumber: big.NewInt(12)}
+	reverted := &types.Receipt{Status: types.ReceiptStatusFailed, GasUsed: 30_000, BlockNumber: big.NewInt(12)}
+
+	recordTxAudit("relay", from, tx, replacement, mined, start, nil)
+	recordTxAudit("relay", from, tx, tx, reverted, start, errors.New("tx failed: Already relayed"))
+	recordTxAudit("relay", from, tx, tx, mined, start, ErrExpectedEventMissing)
+	recordTxAudit("relay", from, tx, nil, nil, start, errors.Wrap(context.DeadlineExceeded, "waiting for tx to be mined"))
+	recordTxAudit("relay", from, tx, nil, nil, start, errors.New("connection refused"))
+	recordTxAuditSendFailed("relay", from, tx, errors.New("insufficient funds"))
+
+	require.Len(t, txLog.records, 6)
+	require.Equal(t, &TxAuditRecord{
+		Operation:    "relay",
+		From:         from,
+		To:           replacement.To(),
+		TxHash:       replacement.Hash(),
+		CalldataHash: crypto.Keccak256Hash([]byte{1, 2}),
+		Nonce:        3,
+		GasLimit:     50_000,
+		GasPrice:     big.NewInt(11),
+		GasUsed:      40_000,
+		Status:       TxStatusMined,
+		BlockNumber:  12,
+		Time:         start,
+	}, txLog.records[0])
+	var statuses []string
+	for _, record := range txLog.records {
+		statuses = append(statuses, record.Status)
+	}
+	require.Equal(t, []string{TxStatusMined, TxStatusReverted, TxStatusFailed, TxStatusTimeout, TxStatusError, TxStatusSendFailed}, statuses)
+	require.Equal(t, "tx failed: Already relayed", txLog.records[1].Error)
+	require.Equal(t, tx.Hash(), txLog.records[3].TxHash)
+	require.Zero(t, txLog.records[3].BlockNumber)
+}
@@ -0,0 +1,39 @@
+package chain
+
+import (
+	"context"
+	"flare-tlc/client/config"
+	"maps"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Process-wide tx timeouts of the config, a copy replaced on config reload so that
+// the reloaded timeouts are applied to the next txs
+var txTimeouts atomic.Pointer[config.TxTimeoutConfig]
+
+// SetTxTimeouts stores a copy of the timeouts, later changes of cfg are not applied
+func SetTxTimeouts(cfg *config.TxTimeoutConfig) {
+	if cfg == nil {
+		txTimeouts.Store(nil)
+		return
+	}
+	txTimeouts.Store(&config.TxTimeoutConfig{Default: cfg.Default, Operations: maps.Clone(cfg.Operations)})
+}
+
+// TxTimeout returns the configured timeout of the operation, timeout if the
+// operation has no configured timeout.
+func TxTimeout(operation string, timeout time.Duration) time.Duration {
+	if configured := txTimeouts.Load().Timeout(operation); configured > 0 {
+		return configured
+	}
+	return timeout
+}
+
+func observeTxTimeout(operation string, err error) {
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		txTimeoutCounter.WithLabelValues(operation).Inc()
+	}
+}
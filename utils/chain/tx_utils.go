@@ -3,9 +3,8 @@ package chain
 import (
 	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"flare-tlc/client/config"
-	"flare-tlc/logger"
+	"flare-tlc/utils/credentials"
 	"math/big"
 	"time"
 
@@ -14,7 +13,6 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/pkg/errors"
 )
@@ -23,6 +21,10 @@ const (
 	// default timeout for waiting for a tx to be mined.
 	DefaultTxTimeout = 60 * time.Second
 	DefaultGasLimit  = 2_500_000
+
+	// nodes reject replacement transactions with a smaller gas price increase
+	minGasPriceBumpPercent = 10
+	txReceiptPollInterval  = 1 * time.Second
 )
 
 type TxVerifier struct {
@@ -33,14 +35,223 @@ func NewTxVerifier(eth *ethclient.Client) *TxVerifier {
 	return &TxVerifier{eth: eth}
 }
 
-func (t TxVerifier) WaitUntilMined(from common.Address, tx *types.Transaction, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+// WaitUntilMined waits until the tx is mined. The fees of the tx are accounted to the
+// operation type, see SpendOperation. The timeout configured for the operation in
+// tx_timeout overrides the given timeout. If receipt log verification is enabled, the
+// tx fails if one of the expected events is not in the receipt logs.
+func (t TxVerifier) WaitUntilMined(
+	from common.Address, tx *types.Transaction, operation string, timeout time.Duration, expected ...ExpectedEvent,
+) error {
+	if Observer() {
+		recordObservedTx(operation, from, tx.To(), tx.Data())
+		return nil
+	}
+	if DryRun() {
+		// simulated txs are not broadcast
+		return nil
+	}
+	start := time.Now()
+	txSentCounter.Inc()
+	receipt, err := t.waitUntilMined(context.Background(), from, tx, operation, TxTimeout(operation, timeout), expected)
+	observeTxResult(start, err)
+	observeTxTimeout(operation, err)
+	recordTxAudit(operation, from, tx, nil, receipt, start, err)
+	return err
+}
+
+// Returns the receipt of the tx, nil if it was not mined
+func (t TxVerifier) waitUntilMined(
+	ctx context.Context, from common.Address, tx *types.Transaction, operation string, timeout time.Duration, expected []ExpectedEvent,
+) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	receipt, err := bind.WaitMined(ctx, t.eth, tx)
 	if err != nil {
-		return errors.Wrap(err, "bind.WaitMined")
+		return nil, errors.Wrap(err, "bind.WaitMined")
+	}
+	return receipt, t.checkReceipt(ctx, from, tx, receipt, operation, expected)
+}
+
+// WaitUntilMinedWithEscalation waits until the tx or one of its replacements is mined.
+// If no tx is included after gasCfg.BumpAfterBlocks blocks, the last tx is re-signed
+// with the same nonce and a gas price increased by gasCfg.GasPriceBumpPercent (capped
+// at gasCfg.GasPriceCap) and broadcast again. Returns the transaction that was mined.
+// Escalation is disabled if BumpAfterBlocks is 0. The fees of the mined tx are
+// accounted to the operation type, see SpendOperation. The timeout configured for the
+// operation in tx_timeout overrides the given timeout. If receipt log verification is
+// enabled, the tx fails if one of the expected events is not in the receipt logs.
+func (t TxVerifier) WaitUntilMinedWithEscalation(
+	from common.Address,
+	tx *types.Transaction,
+	signer bind.SignerFn,
+	gasCfg *config.GasConfig,
+	operation string,
+	timeout time.Duration,
+	expected ...ExpectedEvent,
+) (*types.Transaction, error) {
+	return t.WaitUntilMinedWithEscalationContext(context.Background(), from, tx, signer, gasCfg, operation, timeout, expected...)
+}
+
+// WaitUntilMinedWithEscalationContext is WaitUntilMinedWithEscalation that stops
+// waiting when ctx is done
+func (t TxVerifier) WaitUntilMinedWithEscalationContext(
+	ctx context.Context,
+	from common.Address,
+	tx *types.Transaction,
+	signer bind.SignerFn,
+	gasCfg *config.GasConfig,
+	operation string,
+	timeout time.Duration,
+	expected ...ExpectedEvent,
+) (*types.Transaction, error) {
+	if Observer() {
+		recordObservedTx(operation, from, tx.To(), tx.Data())
+		return tx, nil
+	}
+	if DryRun() {
+		// simulated txs are not broadcast
+		return tx, nil
+	}
+	start := time.Now()
+	txSentCounter.Inc()
+	minedTx, receipt, err := t.waitUntilMinedWithEscalation(ctx, from, tx, signer, gasCfg, operation, TxTimeout(operation, timeout), expected)
+	observeTxResult(start, err)
+	observeTxTimeout(operation, err)
+	recordTxAudit(operation, from, tx, minedTx, receipt, start, err)
+	return minedTx, err
+}
+
+// Returns the mined tx and its receipt, nil if no tx was mined
+func (t TxVerifier) waitUntilMinedWithEscalation(
+	ctx context.Context,
+	from common.Address,
+	tx *types.Transaction,
+	signer bind.SignerFn,
+	gasCfg *config.GasConfig,
+	operation string,
+	timeout time.Duration,
+	expected []ExpectedEvent,
+) (*types.Transaction, *types.Receipt, error) {
+	if gasCfg != nil {
+		gasCfg = gasCfg.Snapshot()
+	}
+	if gasCfg == nil || gasCfg.BumpAfterBlocks == 0 {
+		receipt, err := t.waitUntilMined(ctx, from, tx, operation, timeout, expected)
+		return tx, receipt, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	lastBroadcastBlock, err := t.eth.BlockNumber(ctx)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "BlockNumber")
+	}
+
+	sentTxs := []*types.Transaction{tx}
+	ticker := time.NewTicker(txReceiptPollInterval)
+	defer ticker.Stop()
+	for {
+		for _, sentTx := range sentTxs {
+			receipt, err := t.eth.TransactionReceipt(ctx, sentTx.Hash())
+			if err == nil {
+				return sentTx, receipt, t.checkReceipt(ctx, from, sentTx, receipt, operation, expected)
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				logger.Debug("Error fetching receipt for tx %s: %v", sentTx.Hash().Hex(), err)
+			}
+		}
+
+		blockNumber, err := t.eth.BlockNumber(ctx)
+		if err == nil && blockNumber >= lastBroadcastBlock+gasCfg.BumpAfterBlocks {
+			lastBroadcastBlock = blockNumber
+			replacementTx, err := t.sendReplacementTx(ctx, from, sentTxs[len(sentTxs)-1], signer, gasCfg)
+			if err != nil {
+				logger.Warn("Unable to replace tx %s: %v", sentTxs[len(sentTxs)-1].Hash().Hex(), err)
+			} else {
+				sentTxs = append(sentTxs, replacementTx)
+			}
+		}
+
+		select {
+		case <-ticker.C:
+			break
+
+		case <-ctx.Done():
+			return nil, nil, errors.Wrap(ctx.Err(), "waiting for tx to be mined")
+		}
+	}
+}
+
+func (t TxVerifier) sendReplacementTx(
+	ctx context.Context, from common.Address, tx *types.Transaction, signer bind.SignerFn, gasCfg *config.GasConfig,
+) (*types.Transaction, error) {
+	var replacementTx *types.Transaction
+	if tx.Type() == types.DynamicFeeTxType {
+		// both the fee cap and the tip need to be increased for a valid replacement
+		feeCap, ok := BumpedGasPrice(tx.GasFeeCap(), gasCfg.GasPriceBumpPercent, gasCfg.GasPriceCap)
+		if !ok {
+			return nil, errors.Errorf("gas price cap %v reached", gasCfg.GasPriceCap)
+		}
+		tip, _ := BumpedGasPrice(tx.GasTipCap(), gasCfg.GasPriceBumpPercent, feeCap)
+		replacementTx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   tx.ChainId(),
+			Nonce:     tx.Nonce(),
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Gas:       tx.Gas(),
+			To:        tx.To(),
+			Value:     tx.Value(),
+			Data:      tx.Data(),
+		})
+	} else {
+		gasPrice, ok := BumpedGasPrice(tx.GasPrice(), gasCfg.GasPriceBumpPercent, gasCfg.GasPriceCap)
+		if !ok {
+			return nil, errors.Errorf("gas price cap %v reached", gasCfg.GasPriceCap)
+		}
+		replacementTx = types.NewTx(&types.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: gasPrice,
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		})
+	}
+	signedTx, err := signer(from, replacementTx)
+	if err != nil {
+		return nil, errors.Wrap(err, "signing replacement tx")
+	}
+	if err := SendTx(ctx, t.eth, signedTx); err != nil {
+		return nil, errors.Wrap(err, "sending replacement tx")
+	}
+	txSentCounter.Inc()
+	logger.Info("Tx %s not mined in time, sent replacement tx %s with gas fee cap %v",
+		tx.Hash().Hex(), signedTx.Hash().Hex(), signedTx.GasFeeCap())
+	return signedTx, nil
+}
+
+// BumpedGasPrice returns the gas price increased by bumpPercent (at least 10%) and
+// limited by gasPriceCap (if nonzero). Returns false if the price cannot be increased.
+func BumpedGasPrice(gasPrice *big.Int, bumpPercent uint64, gasPriceCap *big.Int) (*big.Int, bool) {
+	if bumpPercent < minGasPriceBumpPercent {
+		bumpPercent = minGasPriceBumpPercent
 	}
+	bumped := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(100+bumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	if gasPriceCap != nil && gasPriceCap.Sign() > 0 && bumped.Cmp(gasPriceCap) > 0 {
+		bumped.Set(gasPriceCap)
+	}
+	return bumped, bumped.Cmp(gasPrice) > 0
+}
+
+func (t TxVerifier) checkReceipt(
+	ctx context.Context, from common.Address, tx *types.Transaction, receipt *types.Receipt, operation string, expected []ExpectedEvent,
+) error {
+	// reverted txs pay fees as well
+	spend.record(SpendOperation(operation), receipt.GasUsed, t.effectiveGasPrice(ctx, tx, receipt))
+
 	if receipt.Status != types.ReceiptStatusSuccessful {
 		reason, err := errorReason(ctx, t.eth, from, tx, receipt.BlockNumber)
 		if err != nil {
@@ -48,9 +259,30 @@ func (t TxVerifier) WaitUntilMined(from common.Address, tx *types.Transaction, t
 		}
 		return errors.Errorf("tx failed: %s", reason)
 	}
+	if verifyReceiptLogs.Load() {
+		if err := checkReceiptLogs(receipt, expected); err != nil {
+			txMissingEventsCounter.WithLabelValues(operation).Inc()
+			return err
+		}
+	}
 	return nil
 }
 
+// Gas price paid by the mined tx. Receipts of this geth version do not include the
+// effective gas price, for dynamic fee txs it is computed from the base fee of the
+// block, falling back to the fee cap if the block header is not available.
+func (t TxVerifier) effectiveGasPrice(ctx context.Context, tx *types.Transaction, receipt *types.Receipt) *big.Int {
+	if tx.Type() != types.DynamicFeeTxType {
+		return tx.GasPrice()
+	}
+	header, err := t.eth.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil || header.BaseFee == nil {
+		logger.Debug("Unable to fetch base fee of block %v, accounting fee cap for tx %s", receipt.BlockNumber, tx.Hash().Hex())
+		return tx.GasFeeCap()
+	}
+	return new(big.Int).Add(header.BaseFee, tx.EffectiveGasTipValue(header.BaseFee))
+}
+
 // Taken from: https://ethereum.stackexchange.com/questions/48383/how-to-retrieve-revert-reason-for-past-transactions
 func errorReason(ctx context.Context, b ethereum.ContractCaller, from common.Address, tx *types.Transaction, blockNum *big.Int) (string, error) {
 	msg := ethereum.CallMsg{
@@ -74,7 +306,7 @@ var (
 )
 
 func unpackError(result []byte) (string, error) {
-	if !bytes.Equal(result[:4], errorSig) {
+	if len(result) < 4 || !bytes.Equal(result[:4], errorSig) {
 		return "<tx result not Error(string)>", errors.New("tx result not of type Error(string)")
 	}
 	vs, err := abi.Arguments{{Type: abiString}}.UnpackValues(result[4:])
@@ -84,70 +316,98 @@ func unpackError(result []byte) (string, error) {
 	return vs[0].(string), nil
 }
 
-func SendRawTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, toAddress common.Address, data []byte, dryRun bool, gasConfig *config.GasConfig) error {
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return errors.New("cannot assert type: publicKey is not of type *ecdsa.PublicKey")
-	}
+// SendRawTx signs and sends the tx and waits until it is mined. If preflight is set, the
+// tx is not sent if gas estimation fails. In dry run mode the tx is only simulated, in
+// observer mode it is only recorded.
+// The expected events are checked as in TxVerifier.WaitUntilMinedWithEscalation.
+func SendRawTx(
+	client *ethclient.Client,
+	signer credentials.Signer,
+	toAddress common.Address,
+	data []byte,
+	preflight bool,
+	gasConfig *config.GasConfig,
+	operation string,
+	expected ...ExpectedEvent,
+) error {
+	fromAddress := signer.Address()
+	value := big.NewInt(0) // in wei (1 eth)
 
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-	nonce, err := client.NonceAt(context.Background(), fromAddress, nil)
-	if err != nil {
-		return err
+	if Observer() {
+		recordObservedTx(operation, fromAddress, &toAddress, data)
+		return nil
+	}
+	if DryRun() {
+		return simulateTx(context.Background(), client, fromAddress, toAddress, value, data)
 	}
 
-	value := big.NewInt(0) // in wei (1 eth)
-
-	if dryRun {
-		err = dryRunTx(client, fromAddress, toAddress, value, data)
+	if preflight {
+		err := preflightTx(client, fromAddress, toAddress, value, data)
 		if err != nil {
-			return errors.Wrap(err, "dry run failed")
+			return errors.Wrap(err, "preflight check failed")
 		}
 	}
 
-	gasLimit := getGasLimit(gasConfig, client, fromAddress, toAddress, value, data)
-	gasPrice, err := GetGasPrice(gasConfig, client)
+	gasLimit, err := GasLimit(context.Background(), client, gasConfig, operation, ethereum.CallMsg{
+		From:  fromAddress,
+		To:    &toAddress,
+		Value: value,
+		Data:  data,
+	})
 	if err != nil {
 		return err
 	}
-
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, gasPrice, data)
-
-	chainID, err := client.NetworkID(context.Background())
+	fees, err := GetTxFees(gasConfig, client)
 	if err != nil {
 		return err
 	}
 
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	chainID, err := client.NetworkID(context.Background())
 	if err != nil {
 		return err
 	}
 
-	logger.Debug("Sending signed tx: %s", signedTx.Hash().Hex())
-	err = client.SendTransaction(context.Background(), signedTx)
+	var signedTx *types.Transaction
+	err = SendWithNonce(context.Background(), client, fromAddress, func(nonce uint64) error {
+		tx := fees.NewTx(chainID, nonce, toAddress, value, gasLimit, data)
+
+		signedTx, err = signer.SignTx(tx, chainID)
+		if err != nil {
+			return err
+		}
+
+		logger.Debug("Sending signed tx: %s", signedTx.Hash().Hex())
+		return SendTx(context.Background(), client, signedTx)
+	})
 	if err != nil {
+		if signedTx != nil {
+			recordTxAuditSendFailed(operation, fromAddress, signedTx, err)
+		}
 		return err
 	}
+	txLogger := logger.With("txHash", signedTx.Hash().Hex(), "from", fromAddress.Hex())
 
 	verifier := NewTxVerifier(client)
+	signerFn := func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		return signer.SignTx(tx, chainID)
+	}
 
-	logger.Debug("Waiting for tx to be mined...")
-	err = verifier.WaitUntilMined(fromAddress, signedTx, DefaultTxTimeout)
+	txLogger.Debug("Waiting for tx to be mined...")
+	minedTx, err := verifier.WaitUntilMinedWithEscalation(fromAddress, signedTx, signerFn, gasConfig, operation, DefaultTxTimeout, expected...)
 	if err != nil {
 		return err
 	}
 
-	logger.Debug("Tx mined, getting receipt %s", signedTx.Hash().Hex())
-	rec, err := client.TransactionReceipt(context.Background(), signedTx.Hash())
+	txLogger.Debug("Tx mined, getting receipt %s", minedTx.Hash().Hex())
+	rec, err := client.TransactionReceipt(context.Background(), minedTx.Hash())
 	if err != nil {
 		return err
 	}
-	logger.Debug("Receipt status: %v", rec.Status)
+	txLogger.Debug("Receipt status: %v", rec.Status)
 	return nil
 }
 
-func dryRunTx(client *ethclient.Client, fromAddress common.Address, toAddress common.Address, value *big.Int, data []byte) error {
+func preflightTx(client *ethclient.Client, fromAddress common.Address, toAddress common.Address, value *big.Int, data []byte) error {
 	_, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
 		From:  fromAddress,
 		To:    &toAddress,
@@ -157,29 +417,8 @@ func dryRunTx(client *ethclient.Client, fromAddress common.Address, toAddress co
 	return err
 }
 
-func getGasLimit(gasConfig *config.GasConfig, client *ethclient.Client, fromAddress common.Address, toAddress common.Address, value *big.Int, data []byte) uint64 {
-	var gasLimit uint64
-	if gasConfig.GasLimit == 0 {
-		estimatedGas, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
-			From:  fromAddress,
-			To:    &toAddress,
-			Value: value,
-			Data:  data,
-		})
-		if err != nil {
-			logger.Warn("Unable to estimate gas: %v, using default gas limit: %d", err, DefaultGasLimit)
-			gasLimit = DefaultGasLimit
-		} else {
-			logger.Debug("Estimated gas: %d", estimatedGas)
-			gasLimit = estimatedGas
-		}
-	} else {
-		gasLimit = uint64(gasConfig.GasLimit)
-	}
-	return gasLimit
-}
-
 func GetGasPrice(gasConfig *config.GasConfig, client *ethclient.Client) (*big.Int, error) {
+	gasConfig = gasConfig.Snapshot()
 	var gasPrice *big.Int
 	if gasConfig.GasPriceFixed.Cmp(common.Big0) != 0 {
 		gasPrice = gasConfig.GasPriceFixed
@@ -0,0 +1,84 @@
+package chain
+
+import (
+	"flare-tlc/client/config"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpedGasPrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		gasPrice    int64
+		bumpPercent uint64
+		gasPriceCap *big.Int
+		expected    int64
+		ok          bool
+	}{
+		{"bump", 100, 20, nil, 120, true},
+		{"minimum bump", 100, 5, nil, 110, true},
+		{"capped", 100, 50, big.NewInt(130), 130, true},
+		{"zero cap is ignored", 100, 50, big.NewInt(0), 150, true},
+		{"cap reached", 130, 50, big.NewInt(130), 130, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bumped, ok := BumpedGasPrice(big.NewInt(test.gasPrice), test.bumpPercent, test.gasPriceCap)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.expected, bumped.Int64())
+		})
+	}
+}
+
+func TestDynamicFeeCap(t *testing.T) {
+	baseFee := big.NewInt(100)
+	tip := big.NewInt(5)
+
+	feeCap := DynamicFeeCap(baseFee, tip, &config.GasConfig{})
+	require.Equal(t, int64(205), feeCap.Int64())
+
+	feeCap = DynamicFeeCap(baseFee, tip, &config.GasConfig{BaseFeeMultiplier: 1.5})
+	require.Equal(t, int64(155), feeCap.Int64())
+
+	feeCap = DynamicFeeCap(baseFee, tip, &config.GasConfig{MaxFeePerGas: big.NewInt(120)})
+	require.Equal(t, int64(120), feeCap.Int64())
+}
+
+func TestScaledTxFees(t *testing.T) {
+	fees := (&TxFees{GasPrice: big.NewInt(100)}).Scaled(2, &config.GasConfig{})
+	require.Equal(t, int64(200), fees.GasPrice.Int64())
+
+	fees = (&TxFees{GasPrice: big.NewInt(100)}).Scaled(2, &config.GasConfig{GasPriceCap: big.NewInt(150)})
+	require.Equal(t, int64(150), fees.GasPrice.Int64())
+
+	fees = (&TxFees{GasFeeCap: big.NewInt(100), GasTipCap: big.NewInt(40)}).Scaled(1.5, &config.GasConfig{MaxFeePerGas: big.NewInt(120)})
+	require.Nil(t, fees.GasPrice)
+	require.Equal(t, int64(120), fees.GasFeeCap.Int64())
+	require.Equal(t, int64(60), fees.GasTipCap.Int64())
+}
+
+func TestTxTimeout(t *testing.T) {
+	defer SetTxTimeouts(nil)
+
+	require.Equal(t, DefaultTxTimeout, TxTimeout(config.RetryOpRelay, DefaultTxTimeout))
+
+	SetTxTimeouts(&config.TxTimeoutConfig{
+		Default:    2 * time.Minute,
+		Operations: map[string]time.Duration{config.RetryOpRelay: 15 * time.Second},
+	})
+	require.Equal(t, 15*time.Second, TxTimeout(config.RetryOpRelay, DefaultTxTimeout))
+	require.Equal(t, 2*time.Minute, TxTimeout(config.RetryOpRegisterVoter, DefaultTxTimeout))
+
+	cfg := &config.TxTimeoutConfig{Operations: map[string]time.Duration{config.RetryOpRelay: 15 * time.Second}}
+	SetTxTimeouts(cfg)
+	require.Equal(t, DefaultTxTimeout, TxTimeout(config.RetryOpRegisterVoter, DefaultTxTimeout))
+
+	// a copy is stored, changes are applied with the next SetTxTimeouts
+	cfg.Operations[config.RetryOpRelay] = 30 * time.Second
+	require.Equal(t, 15*time.Second, TxTimeout(config.RetryOpRelay, DefaultTxTimeout))
+	SetTxTimeouts(cfg)
+	require.Equal(t, 30*time.Second, TxTimeout(config.RetryOpRelay, DefaultTxTimeout))
+}
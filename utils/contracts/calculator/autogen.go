@@ -0,0 +1,325 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package calculator
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// FlareSystemsCalculatorMetaData contains all meta data concerning the FlareSystemsCalculator contract.
+var FlareSystemsCalculatorMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"name\":\"wNatCapPPM\",\"outputs\":[{\"internalType\":\"uint24\",\"name\":\"\",\"type\":\"uint24\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"wNat\",\"outputs\":[{\"internalType\":\"contractIWNat\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"pChainStakeMirror\",\"outputs\":[{\"internalType\":\"contractIPChainStakeMirror\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"pChainStakeEnabled\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"_delegationAddress\",\"type\":\"address\"},{\"internalType\":\"uint24\",\"name\":\"_rewardEpochId\",\"type\":\"uint24\"},{\"internalType\":\"uint256\",\"name\":\"_votePowerBlockNumber\",\"type\":\"uint256\"}],\"name\":\"calculateRegistrationWeight\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"_registrationWeight\",\"type\":\"uint256\"}],\"stateMutability\":\"nonpayable\",\"type\":\"function\"}]",
+}
+
+// FlareSystemsCalculatorABI is the input ABI used to generate the binding from.
+// Deprecated: Use FlareSystemsCalculatorMetaData.ABI instead.
+var FlareSystemsCalculatorABI = FlareSystemsCalculatorMetaData.ABI
+
+// FlareSystemsCalculator is an auto generated Go binding around an Ethereum contract.
+type FlareSystemsCalculator struct {
+	FlareSystemsCalculatorCaller     // Read-only binding to the contract
+	FlareSystemsCalculatorTransactor // Write-only binding to the contract
+	FlareSystemsCalculatorFilterer   // Log filterer for contract events
+}
+
+// FlareSystemsCalculatorCaller is an auto generated read-only Go binding around an Ethereum contract.
+type FlareSystemsCalculatorCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FlareSystemsCalculatorTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type FlareSystemsCalculatorTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FlareSystemsCalculatorFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type FlareSystemsCalculatorFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FlareSystemsCalculatorSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type FlareSystemsCalculatorSession struct {
+	Contract     *FlareSystemsCalculator // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts           // Call options to use throughout this session
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// FlareSystemsCalculatorCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type FlareSystemsCalculatorCallerSession struct {
+	Contract *FlareSystemsCalculatorCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts                 // Call options to use throughout this session
+}
+
+// FlareSystemsCalculatorTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type FlareSystemsCalculatorTransactorSession struct {
+	Contract     *FlareSystemsCalculatorTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts                 // Transaction auth options to use throughout this session
+}
+
+// FlareSystemsCalculatorRaw is an auto generated low-level Go binding around an Ethereum contract.
+type FlareSystemsCalculatorRaw struct {
+	Contract *FlareSystemsCalculator // Generic contract binding to access the raw methods on
+}
+
+// FlareSystemsCalculatorCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type FlareSystemsCalculatorCallerRaw struct {
+	Contract *FlareSystemsCalculatorCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// FlareSystemsCalculatorTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type FlareSystemsCalculatorTransactorRaw struct {
+	Contract *FlareSystemsCalculatorTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewFlareSystemsCalculator creates a new instance of FlareSystemsCalculator, bound to a specific deployed contract.
+func NewFlareSystemsCalculator(address common.Address, backend bind.ContractBackend) (*FlareSystemsCalculator, error) {
+	contract, err := bindFlareSystemsCalculator(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &FlareSystemsCalculator{FlareSystemsCalculatorCaller: FlareSystemsCalculatorCaller{contract: contract}, FlareSystemsCalculatorTransactor: FlareSystemsCalculatorTransactor{contract: contract}, FlareSystemsCalculatorFilterer: FlareSystemsCalculatorFilterer{contract: contract}}, nil
+}
+
+// NewFlareSystemsCalculatorCaller creates a new read-only instance of FlareSystemsCalculator, bound to a specific deployed contract.
+func NewFlareSystemsCalculatorCaller(address common.Address, caller bind.ContractCaller) (*FlareSystemsCalculatorCaller, error) {
+	contract, err := bindFlareSystemsCalculator(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FlareSystemsCalculatorCaller{contract: contract}, nil
+}
+
+// NewFlareSystemsCalculatorTransactor creates a new write-only instance of FlareSystemsCalculator, bound to a specific deployed contract.
+func NewFlareSystemsCalculatorTransactor(address common.Address, transactor bind.ContractTransactor) (*FlareSystemsCalculatorTransactor, error) {
+	contract, err := bindFlareSystemsCalculator(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FlareSystemsCalculatorTransactor{contract: contract}, nil
+}
+
+// NewFlareSystemsCalculatorFilterer creates a new log filterer instance of FlareSystemsCalculator, bound to a specific deployed contract.
+func NewFlareSystemsCalculatorFilterer(address common.Address, filterer bind.ContractFilterer) (*FlareSystemsCalculatorFilterer, error) {
+	contract, err := bindFlareSystemsCalculator(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &FlareSystemsCalculatorFilterer{contract: contract}, nil
+}
+
+// bindFlareSystemsCalculator binds a generic wrapper to an already deployed contract.
+func bindFlareSystemsCalculator(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(FlareSystemsCalculatorABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_FlareSystemsCalculator *FlareSystemsCalculatorRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _FlareSystemsCalculator.Contract.FlareSystemsCalculatorCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_FlareSystemsCalculator *FlareSystemsCalculatorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _FlareSystemsCalculator.Contract.FlareSystemsCalculatorTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_FlareSystemsCalculator *FlareSystemsCalculatorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _FlareSystemsCalculator.Contract.FlareSystemsCalculatorTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _FlareSystemsCalculator.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_FlareSystemsCalculator *FlareSystemsCalculatorTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _FlareSystemsCalculator.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_FlareSystemsCalculator *FlareSystemsCalculatorTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _FlareSystemsCalculator.Contract.contract.Transact(opts, method, params...)
+}
+
+// PChainStakeEnabled is a free data retrieval call binding the contract method 0x2f5229c1.
+//
+// Solidity: function pChainStakeEnabled() view returns(bool)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCaller) PChainStakeEnabled(opts *bind.CallOpts) (bool, error) {
+	var out []interface{}
+	err := _FlareSystemsCalculator.contract.Call(opts, &out, "pChainStakeEnabled")
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// PChainStakeEnabled is a free data retrieval call binding the contract method 0x2f5229c1.
+//
+// Solidity: function pChainStakeEnabled() view returns(bool)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorSession) PChainStakeEnabled() (bool, error) {
+	return _FlareSystemsCalculator.Contract.PChainStakeEnabled(&_FlareSystemsCalculator.CallOpts)
+}
+
+// PChainStakeEnabled is a free data retrieval call binding the contract method 0x2f5229c1.
+//
+// Solidity: function pChainStakeEnabled() view returns(bool)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCallerSession) PChainStakeEnabled() (bool, error) {
+	return _FlareSystemsCalculator.Contract.PChainStakeEnabled(&_FlareSystemsCalculator.CallOpts)
+}
+
+// PChainStakeMirror is a free data retrieval call binding the contract method 0x62d9c89a.
+//
+// Solidity: function pChainStakeMirror() view returns(address)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCaller) PChainStakeMirror(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _FlareSystemsCalculator.contract.Call(opts, &out, "pChainStakeMirror")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// PChainStakeMirror is a free data retrieval call binding the contract method 0x62d9c89a.
+//
+// Solidity: function pChainStakeMirror() view returns(address)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorSession) PChainStakeMirror() (common.Address, error) {
+	return _FlareSystemsCalculator.Contract.PChainStakeMirror(&_FlareSystemsCalculator.CallOpts)
+}
+
+// PChainStakeMirror is a free data retrieval call binding the contract method 0x62d9c89a.
+//
+// Solidity: function pChainStakeMirror() view returns(address)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCallerSession) PChainStakeMirror() (common.Address, error) {
+	return _FlareSystemsCalculator.Contract.PChainStakeMirror(&_FlareSystemsCalculator.CallOpts)
+}
+
+// WNat is a free data retrieval call binding the contract method 0x9edbf007.
+//
+// Solidity: function wNat() view returns(address)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCaller) WNat(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _FlareSystemsCalculator.contract.Call(opts, &out, "wNat")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// WNat is a free data retrieval call binding the contract method 0x9edbf007.
+//
+// Solidity: function wNat() view returns(address)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorSession) WNat() (common.Address, error) {
+	return _FlareSystemsCalculator.Contract.WNat(&_FlareSystemsCalculator.CallOpts)
+}
+
+// WNat is a free data retrieval call binding the contract method 0x9edbf007.
+//
+// Solidity: function wNat() view returns(address)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCallerSession) WNat() (common.Address, error) {
+	return _FlareSystemsCalculator.Contract.WNat(&_FlareSystemsCalculator.CallOpts)
+}
+
+// WNatCapPPM is a free data retrieval call binding the contract method 0x5edf7596.
+//
+// Solidity: function wNatCapPPM() view returns(uint24)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCaller) WNatCapPPM(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _FlareSystemsCalculator.contract.Call(opts, &out, "wNatCapPPM")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// WNatCapPPM is a free data retrieval call binding the contract method 0x5edf7596.
+//
+// Solidity: function wNatCapPPM() view returns(uint24)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorSession) WNatCapPPM() (*big.Int, error) {
+	return _FlareSystemsCalculator.Contract.WNatCapPPM(&_FlareSystemsCalculator.CallOpts)
+}
+
+// WNatCapPPM is a free data retrieval call binding the contract method 0x5edf7596.
+//
+// Solidity: function wNatCapPPM() view returns(uint24)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorCallerSession) WNatCapPPM() (*big.Int, error) {
+	return _FlareSystemsCalculator.Contract.WNatCapPPM(&_FlareSystemsCalculator.CallOpts)
+}
+
+// CalculateRegistrationWeight is a paid mutator transaction binding the contract method 0x57b58c5f.
+//
+// Solidity: function calculateRegistrationWeight(address _voter, address _delegationAddress, uint24 _rewardEpochId, uint256 _votePowerBlockNumber) returns(uint256 _registrationWeight)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorTransactor) CalculateRegistrationWeight(opts *bind.TransactOpts, _voter common.Address, _delegationAddress common.Address, _rewardEpochId *big.Int, _votePowerBlockNumber *big.Int) (*types.Transaction, error) {
+	return _FlareSystemsCalculator.contract.Transact(opts, "calculateRegistrationWeight", _voter, _delegationAddress, _rewardEpochId, _votePowerBlockNumber)
+}
+
+// CalculateRegistrationWeight is a paid mutator transaction binding the contract method 0x57b58c5f.
+//
+// Solidity: function calculateRegistrationWeight(address _voter, address _delegationAddress, uint24 _rewardEpochId, uint256 _votePowerBlockNumber) returns(uint256 _registrationWeight)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorSession) CalculateRegistrationWeight(_voter common.Address, _delegationAddress common.Address, _rewardEpochId *big.Int, _votePowerBlockNumber *big.Int) (*types.Transaction, error) {
+	return _FlareSystemsCalculator.Contract.CalculateRegistrationWeight(&_FlareSystemsCalculator.TransactOpts, _voter, _delegationAddress, _rewardEpochId, _votePowerBlockNumber)
+}
+
+// CalculateRegistrationWeight is a paid mutator transaction binding the contract method 0x57b58c5f.
+//
+// Solidity: function calculateRegistrationWeight(address _voter, address _delegationAddress, uint24 _rewardEpochId, uint256 _votePowerBlockNumber) returns(uint256 _registrationWeight)
+func (_FlareSystemsCalculator *FlareSystemsCalculatorTransactorSession) CalculateRegistrationWeight(_voter common.Address, _delegationAddress common.Address, _rewardEpochId *big.Int, _votePowerBlockNumber *big.Int) (*types.Transaction, error) {
+	return _FlareSystemsCalculator.Contract.CalculateRegistrationWeight(&_FlareSystemsCalculator.TransactOpts, _voter, _delegationAddress, _rewardEpochId, _votePowerBlockNumber)
+}
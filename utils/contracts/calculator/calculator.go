@@ -0,0 +1,2 @@
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=calculator.abi --pkg=calculator --type=FlareSystemsCalculator --out=autogen.go
+package calculator
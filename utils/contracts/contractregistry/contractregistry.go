@@ -0,0 +1,2 @@
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=contractregistry.abi --pkg=contractregistry --type=FlareContractRegistry --out=autogen.go
+package contractregistry
@@ -0,0 +1,552 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package entitymanager
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// IEntityManagerVoterAddresses is an auto generated low-level Go binding around an user-defined struct.
+type IEntityManagerVoterAddresses struct {
+	SubmitAddress           common.Address
+	SubmitSignaturesAddress common.Address
+	SigningPolicyAddress    common.Address
+}
+
+// EntityManagerMetaData contains all meta data concerning the EntityManager contract.
+var EntityManagerMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"}],\"name\":\"getVoterAddresses\",\"outputs\":[{\"components\":[{\"internalType\":\"address\",\"name\":\"submitAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"submitSignaturesAddress\",\"type\":\"address\"},{\"internalType\":\"address\",\"name\":\"signingPolicyAddress\",\"type\":\"address\"}],\"internalType\":\"structIEntityManager.VoterAddresses\",\"name\":\"\",\"type\":\"tuple\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_submitAddress\",\"type\":\"address\"}],\"name\":\"proposeSubmitAddress\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"}],\"name\":\"confirmSubmitAddressRegistration\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"submitAddressRegistrationQueue\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_submitSignaturesAddress\",\"type\":\"address\"}],\"name\":\"proposeSubmitSignaturesAddress\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"}],\"name\":\"confirmSubmitSignaturesAddressRegistration\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"submitSignaturesAddressRegistrationQueue\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_signingPolicyAddress\",\"type\":\"address\"}],\"name\":\"proposeSigningPolicyAddress\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"}],\"name\":\"confirmSigningPolicyAddressRegistration\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"signingPolicyAddressRegistrationQueue\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"_part1\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"_part2\",\"type\":\"bytes32\"},{\"internalType\":\"bytes\",\"name\":\"_verificationData\",\"type\":\"bytes\"}],\"name\":\"registerPublicKey\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"}],\"name\":\"getPublicKeyOf\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_blockNumber\",\"type\":\"uint256\"}],\"name\":\"getDelegationAddressOfAt\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_voter\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_blockNumber\",\"type\":\"uint256\"}],\"name\":\"getNodeIdsOfAt\",\"outputs\":[{\"internalType\":\"bytes20[]\",\"name\":\"\",\"type\":\"bytes20[]\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// EntityManagerABI is the input ABI used to generate the binding from.
+// Deprecated: Use EntityManagerMetaData.ABI instead.
+var EntityManagerABI = EntityManagerMetaData.ABI
+
+// EntityManager is an auto generated Go binding around an Ethereum contract.
+type EntityManager struct {
+	EntityManagerCaller     // Read-only binding to the contract
+	EntityManagerTransactor // Write-only binding to the contract
+	EntityManagerFilterer   // Log filterer for contract events
+}
+
+// EntityManagerCaller is an auto generated read-only Go binding around an Ethereum contract.
+type EntityManagerCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// EntityManagerTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type EntityManagerTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// EntityManagerFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type EntityManagerFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// EntityManagerSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type EntityManagerSession struct {
+	Contract     *EntityManager    // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// EntityManagerCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type EntityManagerCallerSession struct {
+	Contract *EntityManagerCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts        // Call options to use throughout this session
+}
+
+// EntityManagerTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type EntityManagerTransactorSession struct {
+	Contract     *EntityManagerTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts        // Transaction auth options to use throughout this session
+}
+
+// EntityManagerRaw is an auto generated low-level Go binding around an Ethereum contract.
+type EntityManagerRaw struct {
+	Contract *EntityManager // Generic contract binding to access the raw methods on
+}
+
+// EntityManagerCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type EntityManagerCallerRaw struct {
+	Contract *EntityManagerCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// EntityManagerTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type EntityManagerTransactorRaw struct {
+	Contract *EntityManagerTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewEntityManager creates a new instance of EntityManager, bound to a specific deployed contract.
+func NewEntityManager(address common.Address, backend bind.ContractBackend) (*EntityManager, error) {
+	contract, err := bindEntityManager(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &EntityManager{EntityManagerCaller: EntityManagerCaller{contract: contract}, EntityManagerTransactor: EntityManagerTransactor{contract: contract}, EntityManagerFilterer: EntityManagerFilterer{contract: contract}}, nil
+}
+
+// NewEntityManagerCaller creates a new read-only instance of EntityManager, bound to a specific deployed contract.
+func NewEntityManagerCaller(address common.Address, caller bind.ContractCaller) (*EntityManagerCaller, error) {
+	contract, err := bindEntityManager(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &EntityManagerCaller{contract: contract}, nil
+}
+
+// NewEntityManagerTransactor creates a new write-only instance of EntityManager, bound to a specific deployed contract.
+func NewEntityManagerTransactor(address common.Address, transactor bind.ContractTransactor) (*EntityManagerTransactor, error) {
+	contract, err := bindEntityManager(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &EntityManagerTransactor{contract: contract}, nil
+}
+
+// NewEntityManagerFilterer creates a new log filterer instance of EntityManager, bound to a specific deployed contract.
+func NewEntityManagerFilterer(address common.Address, filterer bind.ContractFilterer) (*EntityManagerFilterer, error) {
+	contract, err := bindEntityManager(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &EntityManagerFilterer{contract: contract}, nil
+}
+
+// bindEntityManager binds a generic wrapper to an already deployed contract.
+func bindEntityManager(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(EntityManagerABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_EntityManager *EntityManagerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _EntityManager.Contract.EntityManagerCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_EntityManager *EntityManagerRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _EntityManager.Contract.EntityManagerTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_EntityManager *EntityManagerRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _EntityManager.Contract.EntityManagerTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_EntityManager *EntityManagerCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _EntityManager.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_EntityManager *EntityManagerTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _EntityManager.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_EntityManager *EntityManagerTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _EntityManager.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetDelegationAddressOfAt is a free data retrieval call binding the contract method 0x2bedbf7a.
+//
+// Solidity: function getDelegationAddressOfAt(address _voter, uint256 _blockNumber) view returns(address)
+func (_EntityManager *EntityManagerCaller) GetDelegationAddressOfAt(opts *bind.CallOpts, _voter common.Address, _blockNumber *big.Int) (common.Address, error) {
+	var out []interface{}
+	err := _EntityManager.contract.Call(opts, &out, "getDelegationAddressOfAt", _voter, _blockNumber)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// GetDelegationAddressOfAt is a free data retrieval call binding the contract method 0x2bedbf7a.
+//
+// Solidity: function getDelegationAddressOfAt(address _voter, uint256 _blockNumber) view returns(address)
+func (_EntityManager *EntityManagerSession) GetDelegationAddressOfAt(_voter common.Address, _blockNumber *big.Int) (common.Address, error) {
+	return _EntityManager.Contract.GetDelegationAddressOfAt(&_EntityManager.CallOpts, _voter, _blockNumber)
+}
+
+// GetDelegationAddressOfAt is a free data retrieval call binding the contract method 0x2bedbf7a.
+//
+// Solidity: function getDelegationAddressOfAt(address _voter, uint256 _blockNumber) view returns(address)
+func (_EntityManager *EntityManagerCallerSession) GetDelegationAddressOfAt(_voter common.Address, _blockNumber *big.Int) (common.Address, error) {
+	return _EntityManager.Contract.GetDelegationAddressOfAt(&_EntityManager.CallOpts, _voter, _blockNumber)
+}
+
+// GetNodeIdsOfAt is a free data retrieval call binding the contract method 0x5b4be5f2.
+//
+// Solidity: function getNodeIdsOfAt(address _voter, uint256 _blockNumber) view returns(bytes20[])
+func (_EntityManager *EntityManagerCaller) GetNodeIdsOfAt(opts *bind.CallOpts, _voter common.Address, _blockNumber *big.Int) ([][20]byte, error) {
+	var out []interface{}
+	err := _EntityManager.contract.Call(opts, &out, "getNodeIdsOfAt", _voter, _blockNumber)
+
+	if err != nil {
+		return *new([][20]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([][20]byte)).(*[][20]byte)
+
+	return out0, err
+
+}
+
+// GetNodeIdsOfAt is a free data retrieval call binding the contract method 0x5b4be5f2.
+//
+// Solidity: function getNodeIdsOfAt(address _voter, uint256 _blockNumber) view returns(bytes20[])
+func (_EntityManager *EntityManagerSession) GetNodeIdsOfAt(_voter common.Address, _blockNumber *big.Int) ([][20]byte, error) {
+	return _EntityManager.Contract.GetNodeIdsOfAt(&_EntityManager.CallOpts, _voter, _blockNumber)
+}
+
+// GetNodeIdsOfAt is a free data retrieval call binding the contract method 0x5b4be5f2.
+//
+// Solidity: function getNodeIdsOfAt(address _voter, uint256 _blockNumber) view returns(bytes20[])
+func (_EntityManager *EntityManagerCallerSession) GetNodeIdsOfAt(_voter common.Address, _blockNumber *big.Int) ([][20]byte, error) {
+	return _EntityManager.Contract.GetNodeIdsOfAt(&_EntityManager.CallOpts, _voter, _blockNumber)
+}
+
+// GetPublicKeyOf is a free data retrieval call binding the contract method 0x75e68605.
+//
+// Solidity: function getPublicKeyOf(address _voter) view returns(bytes32, bytes32)
+func (_EntityManager *EntityManagerCaller) GetPublicKeyOf(opts *bind.CallOpts, _voter common.Address) ([32]byte, [32]byte, error) {
+	var out []interface{}
+	err := _EntityManager.contract.Call(opts, &out, "getPublicKeyOf", _voter)
+
+	if err != nil {
+		return *new([32]byte), *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+	out1 := *abi.ConvertType(out[1], new([32]byte)).(*[32]byte)
+
+	return out0, out1, err
+
+}
+
+// GetPublicKeyOf is a free data retrieval call binding the contract method 0x75e68605.
+//
+// Solidity: function getPublicKeyOf(address _voter) view returns(bytes32, bytes32)
+func (_EntityManager *EntityManagerSession) GetPublicKeyOf(_voter common.Address) ([32]byte, [32]byte, error) {
+	return _EntityManager.Contract.GetPublicKeyOf(&_EntityManager.CallOpts, _voter)
+}
+
+// GetPublicKeyOf is a free data retrieval call binding the contract method 0x75e68605.
+//
+// Solidity: function getPublicKeyOf(address _voter) view returns(bytes32, bytes32)
+func (_EntityManager *EntityManagerCallerSession) GetPublicKeyOf(_voter common.Address) ([32]byte, [32]byte, error) {
+	return _EntityManager.Contract.GetPublicKeyOf(&_EntityManager.CallOpts, _voter)
+}
+
+// GetVoterAddresses is a free data retrieval call binding the contract method 0xe5771dbc.
+//
+// Solidity: function getVoterAddresses(address _voter) view returns((address,address,address))
+func (_EntityManager *EntityManagerCaller) GetVoterAddresses(opts *bind.CallOpts, _voter common.Address) (IEntityManagerVoterAddresses, error) {
+	var out []interface{}
+	err := _EntityManager.contract.Call(opts, &out, "getVoterAddresses", _voter)
+
+	if err != nil {
+		return *new(IEntityManagerVoterAddresses), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(IEntityManagerVoterAddresses)).(*IEntityManagerVoterAddresses)
+
+	return out0, err
+
+}
+
+// GetVoterAddresses is a free data retrieval call binding the contract method 0xe5771dbc.
+//
+// Solidity: function getVoterAddresses(address _voter) view returns((address,address,address))
+func (_EntityManager *EntityManagerSession) GetVoterAddresses(_voter common.Address) (IEntityManagerVoterAddresses, error) {
+	return _EntityManager.Contract.GetVoterAddresses(&_EntityManager.CallOpts, _voter)
+}
+
+// GetVoterAddresses is a free data retrieval call binding the contract method 0xe5771dbc.
+//
+// Solidity: function getVoterAddresses(address _voter) view returns((address,address,address))
+func (_EntityManager *EntityManagerCallerSession) GetVoterAddresses(_voter common.Address) (IEntityManagerVoterAddresses, error) {
+	return _EntityManager.Contract.GetVoterAddresses(&_EntityManager.CallOpts, _voter)
+}
+
+// SigningPolicyAddressRegistrationQueue is a free data retrieval call binding the contract method 0x26adb931.
+//
+// Solidity: function signingPolicyAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerCaller) SigningPolicyAddressRegistrationQueue(opts *bind.CallOpts, arg0 common.Address) (common.Address, error) {
+	var out []interface{}
+	err := _EntityManager.contract.Call(opts, &out, "signingPolicyAddressRegistrationQueue", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// SigningPolicyAddressRegistrationQueue is a free data retrieval call binding the contract method 0x26adb931.
+//
+// Solidity: function signingPolicyAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerSession) SigningPolicyAddressRegistrationQueue(arg0 common.Address) (common.Address, error) {
+	return _EntityManager.Contract.SigningPolicyAddressRegistrationQueue(&_EntityManager.CallOpts, arg0)
+}
+
+// SigningPolicyAddressRegistrationQueue is a free data retrieval call binding the contract method 0x26adb931.
+//
+// Solidity: function signingPolicyAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerCallerSession) SigningPolicyAddressRegistrationQueue(arg0 common.Address) (common.Address, error) {
+	return _EntityManager.Contract.SigningPolicyAddressRegistrationQueue(&_EntityManager.CallOpts, arg0)
+}
+
+// SubmitAddressRegistrationQueue is a free data retrieval call binding the contract method 0x743ff1ff.
+//
+// Solidity: function submitAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerCaller) SubmitAddressRegistrationQueue(opts *bind.CallOpts, arg0 common.Address) (common.Address, error) {
+	var out []interface{}
+	err := _EntityManager.contract.Call(opts, &out, "submitAddressRegistrationQueue", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// SubmitAddressRegistrationQueue is a free data retrieval call binding the contract method 0x743ff1ff.
+//
+// Solidity: function submitAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerSession) SubmitAddressRegistrationQueue(arg0 common.Address) (common.Address, error) {
+	return _EntityManager.Contract.SubmitAddressRegistrationQueue(&_EntityManager.CallOpts, arg0)
+}
+
+// SubmitAddressRegistrationQueue is a free data retrieval call binding the contract method 0x743ff1ff.
+//
+// Solidity: function submitAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerCallerSession) SubmitAddressRegistrationQueue(arg0 common.Address) (common.Address, error) {
+	return _EntityManager.Contract.SubmitAddressRegistrationQueue(&_EntityManager.CallOpts, arg0)
+}
+
+// SubmitSignaturesAddressRegistrationQueue is a free data retrieval call binding the contract method 0x8dda5bdf.
+//
+// Solidity: function submitSignaturesAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerCaller) SubmitSignaturesAddressRegistrationQueue(opts *bind.CallOpts, arg0 common.Address) (common.Address, error) {
+	var out []interface{}
+	err := _EntityManager.contract.Call(opts, &out, "submitSignaturesAddressRegistrationQueue", arg0)
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// SubmitSignaturesAddressRegistrationQueue is a free data retrieval call binding the contract method 0x8dda5bdf.
+//
+// Solidity: function submitSignaturesAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerSession) SubmitSignaturesAddressRegistrationQueue(arg0 common.Address) (common.Address, error) {
+	return _EntityManager.Contract.SubmitSignaturesAddressRegistrationQueue(&_EntityManager.CallOpts, arg0)
+}
+
+// SubmitSignaturesAddressRegistrationQueue is a free data retrieval call binding the contract method 0x8dda5bdf.
+//
+// Solidity: function submitSignaturesAddressRegistrationQueue(address ) view returns(address)
+func (_EntityManager *EntityManagerCallerSession) SubmitSignaturesAddressRegistrationQueue(arg0 common.Address) (common.Address, error) {
+	return _EntityManager.Contract.SubmitSignaturesAddressRegistrationQueue(&_EntityManager.CallOpts, arg0)
+}
+
+// ConfirmSigningPolicyAddressRegistration is a paid mutator transaction binding the contract method 0xdb8d5125.
+//
+// Solidity: function confirmSigningPolicyAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerTransactor) ConfirmSigningPolicyAddressRegistration(opts *bind.TransactOpts, _voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.contract.Transact(opts, "confirmSigningPolicyAddressRegistration", _voter)
+}
+
+// ConfirmSigningPolicyAddressRegistration is a paid mutator transaction binding the contract method 0xdb8d5125.
+//
+// Solidity: function confirmSigningPolicyAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerSession) ConfirmSigningPolicyAddressRegistration(_voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ConfirmSigningPolicyAddressRegistration(&_EntityManager.TransactOpts, _voter)
+}
+
+// ConfirmSigningPolicyAddressRegistration is a paid mutator transaction binding the contract method 0xdb8d5125.
+//
+// Solidity: function confirmSigningPolicyAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerTransactorSession) ConfirmSigningPolicyAddressRegistration(_voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ConfirmSigningPolicyAddressRegistration(&_EntityManager.TransactOpts, _voter)
+}
+
+// ConfirmSubmitAddressRegistration is a paid mutator transaction binding the contract method 0x5f1fb56a.
+//
+// Solidity: function confirmSubmitAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerTransactor) ConfirmSubmitAddressRegistration(opts *bind.TransactOpts, _voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.contract.Transact(opts, "confirmSubmitAddressRegistration", _voter)
+}
+
+// ConfirmSubmitAddressRegistration is a paid mutator transaction binding the contract method 0x5f1fb56a.
+//
+// Solidity: function confirmSubmitAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerSession) ConfirmSubmitAddressRegistration(_voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ConfirmSubmitAddressRegistration(&_EntityManager.TransactOpts, _voter)
+}
+
+// ConfirmSubmitAddressRegistration is a paid mutator transaction binding the contract method 0x5f1fb56a.
+//
+// Solidity: function confirmSubmitAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerTransactorSession) ConfirmSubmitAddressRegistration(_voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ConfirmSubmitAddressRegistration(&_EntityManager.TransactOpts, _voter)
+}
+
+// ConfirmSubmitSignaturesAddressRegistration is a paid mutator transaction binding the contract method 0xbda1a84e.
+//
+// Solidity: function confirmSubmitSignaturesAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerTransactor) ConfirmSubmitSignaturesAddressRegistration(opts *bind.TransactOpts, _voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.contract.Transact(opts, "confirmSubmitSignaturesAddressRegistration", _voter)
+}
+
+// ConfirmSubmitSignaturesAddressRegistration is a paid mutator transaction binding the contract method 0xbda1a84e.
+//
+// Solidity: function confirmSubmitSignaturesAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerSession) ConfirmSubmitSignaturesAddressRegistration(_voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ConfirmSubmitSignaturesAddressRegistration(&_EntityManager.TransactOpts, _voter)
+}
+
+// ConfirmSubmitSignaturesAddressRegistration is a paid mutator transaction binding the contract method 0xbda1a84e.
+//
+// Solidity: function confirmSubmitSignaturesAddressRegistration(address _voter) returns()
+func (_EntityManager *EntityManagerTransactorSession) ConfirmSubmitSignaturesAddressRegistration(_voter common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ConfirmSubmitSignaturesAddressRegistration(&_EntityManager.TransactOpts, _voter)
+}
+
+// ProposeSigningPolicyAddress is a paid mutator transaction binding the contract method 0x69b5fed9.
+//
+// Solidity: function proposeSigningPolicyAddress(address _signingPolicyAddress) returns()
+func (_EntityManager *EntityManagerTransactor) ProposeSigningPolicyAddress(opts *bind.TransactOpts, _signingPolicyAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.contract.Transact(opts, "proposeSigningPolicyAddress", _signingPolicyAddress)
+}
+
+// ProposeSigningPolicyAddress is a paid mutator transaction binding the contract method 0x69b5fed9.
+//
+// Solidity: function proposeSigningPolicyAddress(address _signingPolicyAddress) returns()
+func (_EntityManager *EntityManagerSession) ProposeSigningPolicyAddress(_signingPolicyAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ProposeSigningPolicyAddress(&_EntityManager.TransactOpts, _signingPolicyAddress)
+}
+
+// ProposeSigningPolicyAddress is a paid mutator transaction binding the contract method 0x69b5fed9.
+//
+// Solidity: function proposeSigningPolicyAddress(address _signingPolicyAddress) returns()
+func (_EntityManager *EntityManagerTransactorSession) ProposeSigningPolicyAddress(_signingPolicyAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ProposeSigningPolicyAddress(&_EntityManager.TransactOpts, _signingPolicyAddress)
+}
+
+// ProposeSubmitAddress is a paid mutator transaction binding the contract method 0x87607e41.
+//
+// Solidity: function proposeSubmitAddress(address _submitAddress) returns()
+func (_EntityManager *EntityManagerTransactor) ProposeSubmitAddress(opts *bind.TransactOpts, _submitAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.contract.Transact(opts, "proposeSubmitAddress", _submitAddress)
+}
+
+// ProposeSubmitAddress is a paid mutator transaction binding the contract method 0x87607e41.
+//
+// Solidity: function proposeSubmitAddress(address _submitAddress) returns()
+func (_EntityManager *EntityManagerSession) ProposeSubmitAddress(_submitAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ProposeSubmitAddress(&_EntityManager.TransactOpts, _submitAddress)
+}
+
+// ProposeSubmitAddress is a paid mutator transaction binding the contract method 0x87607e41.
+//
+// Solidity: function proposeSubmitAddress(address _submitAddress) returns()
+func (_EntityManager *EntityManagerTransactorSession) ProposeSubmitAddress(_submitAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ProposeSubmitAddress(&_EntityManager.TransactOpts, _submitAddress)
+}
+
+// ProposeSubmitSignaturesAddress is a paid mutator transaction binding the contract method 0x36edd357.
+//
+// Solidity: function proposeSubmitSignaturesAddress(address _submitSignaturesAddress) returns()
+func (_EntityManager *EntityManagerTransactor) ProposeSubmitSignaturesAddress(opts *bind.TransactOpts, _submitSignaturesAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.contract.Transact(opts, "proposeSubmitSignaturesAddress", _submitSignaturesAddress)
+}
+
+// ProposeSubmitSignaturesAddress is a paid mutator transaction binding the contract method 0x36edd357.
+//
+// Solidity: function proposeSubmitSignaturesAddress(address _submitSignaturesAddress) returns()
+func (_EntityManager *EntityManagerSession) ProposeSubmitSignaturesAddress(_submitSignaturesAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ProposeSubmitSignaturesAddress(&_EntityManager.TransactOpts, _submitSignaturesAddress)
+}
+
+// ProposeSubmitSignaturesAddress is a paid mutator transaction binding the contract method 0x36edd357.
+//
+// Solidity: function proposeSubmitSignaturesAddress(address _submitSignaturesAddress) returns()
+func (_EntityManager *EntityManagerTransactorSession) ProposeSubmitSignaturesAddress(_submitSignaturesAddress common.Address) (*types.Transaction, error) {
+	return _EntityManager.Contract.ProposeSubmitSignaturesAddress(&_EntityManager.TransactOpts, _submitSignaturesAddress)
+}
+
+// RegisterPublicKey is a paid mutator transaction binding the contract method 0xb1863cff.
+//
+// Solidity: function registerPublicKey(bytes32 _part1, bytes32 _part2, bytes _verificationData) returns()
+func (_EntityManager *EntityManagerTransactor) RegisterPublicKey(opts *bind.TransactOpts, _part1 [32]byte, _part2 [32]byte, _verificationData []byte) (*types.Transaction, error) {
+	return _EntityManager.contract.Transact(opts, "registerPublicKey", _part1, _part2, _verificationData)
+}
+
+// RegisterPublicKey is a paid mutator transaction binding the contract method 0xb1863cff.
+//
+// Solidity: function registerPublicKey(bytes32 _part1, bytes32 _part2, bytes _verificationData) returns()
+func (_EntityManager *EntityManagerSession) RegisterPublicKey(_part1 [32]byte, _part2 [32]byte, _verificationData []byte) (*types.Transaction, error) {
+	return _EntityManager.Contract.RegisterPublicKey(&_EntityManager.TransactOpts, _part1, _part2, _verificationData)
+}
+
+// RegisterPublicKey is a paid mutator transaction binding the contract method 0xb1863cff.
+//
+// Solidity: function registerPublicKey(bytes32 _part1, bytes32 _part2, bytes _verificationData) returns()
+func (_EntityManager *EntityManagerTransactorSession) RegisterPublicKey(_part1 [32]byte, _part2 [32]byte, _verificationData []byte) (*types.Transaction, error) {
+	return _EntityManager.Contract.RegisterPublicKey(&_EntityManager.TransactOpts, _part1, _part2, _verificationData)
+}
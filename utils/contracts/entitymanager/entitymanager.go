@@ -0,0 +1,2 @@
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=entitymanager.abi --pkg=entitymanager --type=EntityManager --out=autogen.go
+package entitymanager
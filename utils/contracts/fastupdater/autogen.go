@@ -0,0 +1,538 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package fastupdater
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// G1Point is an auto generated low-level Go binding around an user-defined struct.
+type G1Point struct {
+	X *big.Int
+	Y *big.Int
+}
+
+// IFastUpdaterFastUpdates is an auto generated low-level Go binding around an user-defined struct.
+type IFastUpdaterFastUpdates struct {
+	SortitionBlock      *big.Int
+	SortitionCredential SortitionCredential
+	Deltas              []byte
+	Signature           IFastUpdaterSignature
+}
+
+// IFastUpdaterSignature is an auto generated low-level Go binding around an user-defined struct.
+type IFastUpdaterSignature struct {
+	V uint8
+	R [32]byte
+	S [32]byte
+}
+
+// SortitionCredential is an auto generated low-level Go binding around an user-defined struct.
+type SortitionCredential struct {
+	Replicate *big.Int
+	Gamma     G1Point
+	C         *big.Int
+	S         *big.Int
+}
+
+// FastUpdaterMetaData contains all meta data concerning the FastUpdater contract.
+var FastUpdaterMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"function\",\"name\":\"blockScoreCutoff\",\"inputs\":[{\"name\":\"_blockNum\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"outputs\":[{\"name\":\"_cutoff\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"currentRewardEpochId\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint24\",\"internalType\":\"uint24\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"currentScoreCutoff\",\"inputs\":[],\"outputs\":[{\"name\":\"_cutoff\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"currentSortitionWeight\",\"inputs\":[{\"name\":\"_signingPolicyAddress\",\"type\":\"address\",\"internalType\":\"address\"}],\"outputs\":[{\"name\":\"_weight\",\"type\":\"uint256\",\"internalType\":\"uint256\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"submissionWindow\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"uint8\",\"internalType\":\"uint8\"}],\"stateMutability\":\"view\"},{\"type\":\"function\",\"name\":\"submitUpdates\",\"inputs\":[{\"name\":\"_updates\",\"type\":\"tuple\",\"internalType\":\"structIFastUpdater.FastUpdates\",\"components\":[{\"name\":\"sortitionBlock\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"sortitionCredential\",\"type\":\"tuple\",\"internalType\":\"structSortitionCredential\",\"components\":[{\"name\":\"replicate\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"gamma\",\"type\":\"tuple\",\"internalType\":\"structG1Point\",\"components\":[{\"name\":\"x\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"y\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"name\":\"c\",\"type\":\"uint256\",\"internalType\":\"uint256\"},{\"name\":\"s\",\"type\":\"uint256\",\"internalType\":\"uint256\"}]},{\"name\":\"deltas\",\"type\":\"bytes\",\"internalType\":\"bytes\"},{\"name\":\"signature\",\"type\":\"tuple\",\"internalType\":\"structIFastUpdater.Signature\",\"components\":[{\"name\":\"v\",\"type\":\"uint8\",\"internalType\":\"uint8\"},{\"name\":\"r\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"},{\"name\":\"s\",\"type\":\"bytes32\",\"internalType\":\"bytes32\"}]}]}],\"outputs\":[],\"stateMutability\":\"nonpayable\"},{\"type\":\"event\",\"name\":\"FastUpdateFeedsSubmitted\",\"anonymous\":false,\"inputs\":[{\"name\":\"votingRoundId\",\"type\":\"uint32\",\"indexed\":true,\"internalType\":\"uint32\"},{\"name\":\"signingPolicyAddress\",\"type\":\"address\",\"indexed\":true,\"internalType\":\"address\"}]}]",
+}
+
+// FastUpdaterABI is the input ABI used to generate the binding from.
+// Deprecated: Use FastUpdaterMetaData.ABI instead.
+var FastUpdaterABI = FastUpdaterMetaData.ABI
+
+// FastUpdater is an auto generated Go binding around an Ethereum contract.
+type FastUpdater struct {
+	FastUpdaterCaller     // Read-only binding to the contract
+	FastUpdaterTransactor // Write-only binding to the contract
+	FastUpdaterFilterer   // Log filterer for contract events
+}
+
+// FastUpdaterCaller is an auto generated read-only Go binding around an Ethereum contract.
+type FastUpdaterCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FastUpdaterTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type FastUpdaterTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FastUpdaterFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type FastUpdaterFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FastUpdaterSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type FastUpdaterSession struct {
+	Contract     *FastUpdater      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// FastUpdaterCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type FastUpdaterCallerSession struct {
+	Contract *FastUpdaterCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// FastUpdaterTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type FastUpdaterTransactorSession struct {
+	Contract     *FastUpdaterTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// FastUpdaterRaw is an auto generated low-level Go binding around an Ethereum contract.
+type FastUpdaterRaw struct {
+	Contract *FastUpdater // Generic contract binding to access the raw methods on
+}
+
+// FastUpdaterCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type FastUpdaterCallerRaw struct {
+	Contract *FastUpdaterCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// FastUpdaterTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type FastUpdaterTransactorRaw struct {
+	Contract *FastUpdaterTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewFastUpdater creates a new instance of FastUpdater, bound to a specific deployed contract.
+func NewFastUpdater(address common.Address, backend bind.ContractBackend) (*FastUpdater, error) {
+	contract, err := bindFastUpdater(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &FastUpdater{FastUpdaterCaller: FastUpdaterCaller{contract: contract}, FastUpdaterTransactor: FastUpdaterTransactor{contract: contract}, FastUpdaterFilterer: FastUpdaterFilterer{contract: contract}}, nil
+}
+
+// NewFastUpdaterCaller creates a new read-only instance of FastUpdater, bound to a specific deployed contract.
+func NewFastUpdaterCaller(address common.Address, caller bind.ContractCaller) (*FastUpdaterCaller, error) {
+	contract, err := bindFastUpdater(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FastUpdaterCaller{contract: contract}, nil
+}
+
+// NewFastUpdaterTransactor creates a new write-only instance of FastUpdater, bound to a specific deployed contract.
+func NewFastUpdaterTransactor(address common.Address, transactor bind.ContractTransactor) (*FastUpdaterTransactor, error) {
+	contract, err := bindFastUpdater(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FastUpdaterTransactor{contract: contract}, nil
+}
+
+// NewFastUpdaterFilterer creates a new log filterer instance of FastUpdater, bound to a specific deployed contract.
+func NewFastUpdaterFilterer(address common.Address, filterer bind.ContractFilterer) (*FastUpdaterFilterer, error) {
+	contract, err := bindFastUpdater(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &FastUpdaterFilterer{contract: contract}, nil
+}
+
+// bindFastUpdater binds a generic wrapper to an already deployed contract.
+func bindFastUpdater(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(FastUpdaterABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_FastUpdater *FastUpdaterRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _FastUpdater.Contract.FastUpdaterCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_FastUpdater *FastUpdaterRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _FastUpdater.Contract.FastUpdaterTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_FastUpdater *FastUpdaterRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _FastUpdater.Contract.FastUpdaterTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_FastUpdater *FastUpdaterCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _FastUpdater.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_FastUpdater *FastUpdaterTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _FastUpdater.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_FastUpdater *FastUpdaterTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _FastUpdater.Contract.contract.Transact(opts, method, params...)
+}
+
+// BlockScoreCutoff is a free data retrieval call binding the contract method 0xdcb1476e.
+//
+// Solidity: function blockScoreCutoff(uint256 _blockNum) view returns(uint256 _cutoff)
+func (_FastUpdater *FastUpdaterCaller) BlockScoreCutoff(opts *bind.CallOpts, _blockNum *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _FastUpdater.contract.Call(opts, &out, "blockScoreCutoff", _blockNum)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// BlockScoreCutoff is a free data retrieval call binding the contract method 0xdcb1476e.
+//
+// Solidity: function blockScoreCutoff(uint256 _blockNum) view returns(uint256 _cutoff)
+func (_FastUpdater *FastUpdaterSession) BlockScoreCutoff(_blockNum *big.Int) (*big.Int, error) {
+	return _FastUpdater.Contract.BlockScoreCutoff(&_FastUpdater.CallOpts, _blockNum)
+}
+
+// BlockScoreCutoff is a free data retrieval call binding the contract method 0xdcb1476e.
+//
+// Solidity: function blockScoreCutoff(uint256 _blockNum) view returns(uint256 _cutoff)
+func (_FastUpdater *FastUpdaterCallerSession) BlockScoreCutoff(_blockNum *big.Int) (*big.Int, error) {
+	return _FastUpdater.Contract.BlockScoreCutoff(&_FastUpdater.CallOpts, _blockNum)
+}
+
+// CurrentRewardEpochId is a free data retrieval call binding the contract method 0x8e0e9f7c.
+//
+// Solidity: function currentRewardEpochId() view returns(uint24)
+func (_FastUpdater *FastUpdaterCaller) CurrentRewardEpochId(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _FastUpdater.contract.Call(opts, &out, "currentRewardEpochId")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CurrentRewardEpochId is a free data retrieval call binding the contract method 0x8e0e9f7c.
+//
+// Solidity: function currentRewardEpochId() view returns(uint24)
+func (_FastUpdater *FastUpdaterSession) CurrentRewardEpochId() (*big.Int, error) {
+	return _FastUpdater.Contract.CurrentRewardEpochId(&_FastUpdater.CallOpts)
+}
+
+// CurrentRewardEpochId is a free data retrieval call binding the contract method 0x8e0e9f7c.
+//
+// Solidity: function currentRewardEpochId() view returns(uint24)
+func (_FastUpdater *FastUpdaterCallerSession) CurrentRewardEpochId() (*big.Int, error) {
+	return _FastUpdater.Contract.CurrentRewardEpochId(&_FastUpdater.CallOpts)
+}
+
+// CurrentScoreCutoff is a free data retrieval call binding the contract method 0x0799fe75.
+//
+// Solidity: function currentScoreCutoff() view returns(uint256 _cutoff)
+func (_FastUpdater *FastUpdaterCaller) CurrentScoreCutoff(opts *bind.CallOpts) (*big.Int, error) {
+	var out []interface{}
+	err := _FastUpdater.contract.Call(opts, &out, "currentScoreCutoff")
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CurrentScoreCutoff is a free data retrieval call binding the contract method 0x0799fe75.
+//
+// Solidity: function currentScoreCutoff() view returns(uint256 _cutoff)
+func (_FastUpdater *FastUpdaterSession) CurrentScoreCutoff() (*big.Int, error) {
+	return _FastUpdater.Contract.CurrentScoreCutoff(&_FastUpdater.CallOpts)
+}
+
+// CurrentScoreCutoff is a free data retrieval call binding the contract method 0x0799fe75.
+//
+// Solidity: function currentScoreCutoff() view returns(uint256 _cutoff)
+func (_FastUpdater *FastUpdaterCallerSession) CurrentScoreCutoff() (*big.Int, error) {
+	return _FastUpdater.Contract.CurrentScoreCutoff(&_FastUpdater.CallOpts)
+}
+
+// CurrentSortitionWeight is a free data retrieval call binding the contract method 0xa14634a7.
+//
+// Solidity: function currentSortitionWeight(address _signingPolicyAddress) view returns(uint256 _weight)
+func (_FastUpdater *FastUpdaterCaller) CurrentSortitionWeight(opts *bind.CallOpts, _signingPolicyAddress common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _FastUpdater.contract.Call(opts, &out, "currentSortitionWeight", _signingPolicyAddress)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// CurrentSortitionWeight is a free data retrieval call binding the contract method 0xa14634a7.
+//
+// Solidity: function currentSortitionWeight(address _signingPolicyAddress) view returns(uint256 _weight)
+func (_FastUpdater *FastUpdaterSession) CurrentSortitionWeight(_signingPolicyAddress common.Address) (*big.Int, error) {
+	return _FastUpdater.Contract.CurrentSortitionWeight(&_FastUpdater.CallOpts, _signingPolicyAddress)
+}
+
+// CurrentSortitionWeight is a free data retrieval call binding the contract method 0xa14634a7.
+//
+// Solidity: function currentSortitionWeight(address _signingPolicyAddress) view returns(uint256 _weight)
+func (_FastUpdater *FastUpdaterCallerSession) CurrentSortitionWeight(_signingPolicyAddress common.Address) (*big.Int, error) {
+	return _FastUpdater.Contract.CurrentSortitionWeight(&_FastUpdater.CallOpts, _signingPolicyAddress)
+}
+
+// SubmissionWindow is a free data retrieval call binding the contract method 0xe621dbc7.
+//
+// Solidity: function submissionWindow() view returns(uint8)
+func (_FastUpdater *FastUpdaterCaller) SubmissionWindow(opts *bind.CallOpts) (uint8, error) {
+	var out []interface{}
+	err := _FastUpdater.contract.Call(opts, &out, "submissionWindow")
+
+	if err != nil {
+		return *new(uint8), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(uint8)).(*uint8)
+
+	return out0, err
+
+}
+
+// SubmissionWindow is a free data retrieval call binding the contract method 0xe621dbc7.
+//
+// Solidity: function submissionWindow() view returns(uint8)
+func (_FastUpdater *FastUpdaterSession) SubmissionWindow() (uint8, error) {
+	return _FastUpdater.Contract.SubmissionWindow(&_FastUpdater.CallOpts)
+}
+
+// SubmissionWindow is a free data retrieval call binding the contract method 0xe621dbc7.
+//
+// Solidity: function submissionWindow() view returns(uint8)
+func (_FastUpdater *FastUpdaterCallerSession) SubmissionWindow() (uint8, error) {
+	return _FastUpdater.Contract.SubmissionWindow(&_FastUpdater.CallOpts)
+}
+
+// SubmitUpdates is a paid mutator transaction binding the contract method 0x470e91df.
+//
+// Solidity: function submitUpdates((uint256,(uint256,(uint256,uint256),uint256,uint256),bytes,(uint8,bytes32,bytes32)) _updates) returns()
+func (_FastUpdater *FastUpdaterTransactor) SubmitUpdates(opts *bind.TransactOpts, _updates IFastUpdaterFastUpdates) (*types.Transaction, error) {
+	return _FastUpdater.contract.Transact(opts, "submitUpdates", _updates)
+}
+
+// SubmitUpdates is a paid mutator transaction binding the contract method 0x470e91df.
+//
+// Solidity: function submitUpdates((uint256,(uint256,(uint256,uint256),uint256,uint256),bytes,(uint8,bytes32,bytes32)) _updates) returns()
+func (_FastUpdater *FastUpdaterSession) SubmitUpdates(_updates IFastUpdaterFastUpdates) (*types.Transaction, error) {
+	return _FastUpdater.Contract.SubmitUpdates(&_FastUpdater.TransactOpts, _updates)
+}
+
+// SubmitUpdates is a paid mutator transaction binding the contract method 0x470e91df.
+//
+// Solidity: function submitUpdates((uint256,(uint256,(uint256,uint256),uint256,uint256),bytes,(uint8,bytes32,bytes32)) _updates) returns()
+func (_FastUpdater *FastUpdaterTransactorSession) SubmitUpdates(_updates IFastUpdaterFastUpdates) (*types.Transaction, error) {
+	return _FastUpdater.Contract.SubmitUpdates(&_FastUpdater.TransactOpts, _updates)
+}
+
+// FastUpdaterFastUpdateFeedsSubmittedIterator is returned from FilterFastUpdateFeedsSubmitted and is used to iterate over the raw logs and unpacked data for FastUpdateFeedsSubmitted events raised by the FastUpdater contract.
+type FastUpdaterFastUpdateFeedsSubmittedIterator struct {
+	Event *FastUpdaterFastUpdateFeedsSubmitted // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FastUpdaterFastUpdateFeedsSubmittedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FastUpdaterFastUpdateFeedsSubmitted)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FastUpdaterFastUpdateFeedsSubmitted)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FastUpdaterFastUpdateFeedsSubmittedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FastUpdaterFastUpdateFeedsSubmittedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FastUpdaterFastUpdateFeedsSubmitted represents a FastUpdateFeedsSubmitted event raised by the FastUpdater contract.
+type FastUpdaterFastUpdateFeedsSubmitted struct {
+	VotingRoundId        uint32
+	SigningPolicyAddress common.Address
+	Raw                  types.Log // Blockchain specific contextual infos
+}
+
+// FilterFastUpdateFeedsSubmitted is a free log retrieval operation binding the contract event 0x63db91b14b3d088c677f046180aefcea7a236649704d90ce810cde455d38d936.
+//
+// Solidity: event FastUpdateFeedsSubmitted(uint32 indexed votingRoundId, address indexed signingPolicyAddress)
+func (_FastUpdater *FastUpdaterFilterer) FilterFastUpdateFeedsSubmitted(opts *bind.FilterOpts, votingRoundId []uint32, signingPolicyAddress []common.Address) (*FastUpdaterFastUpdateFeedsSubmittedIterator, error) {
+
+	var votingRoundIdRule []interface{}
+	for _, votingRoundIdItem := range votingRoundId {
+		votingRoundIdRule = append(votingRoundIdRule, votingRoundIdItem)
+	}
+	var signingPolicyAddressRule []interface{}
+	for _, signingPolicyAddressItem := range signingPolicyAddress {
+		signingPolicyAddressRule = append(signingPolicyAddressRule, signingPolicyAddressItem)
+	}
+
+	logs, sub, err := _FastUpdater.contract.FilterLogs(opts, "FastUpdateFeedsSubmitted", votingRoundIdRule, signingPolicyAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FastUpdaterFastUpdateFeedsSubmittedIterator{contract: _FastUpdater.contract, event: "FastUpdateFeedsSubmitted", logs: logs, sub: sub}, nil
+}
+
+// WatchFastUpdateFeedsSubmitted is a free log subscription operation binding the contract event 0x63db91b14b3d088c677f046180aefcea7a236649704d90ce810cde455d38d936.
+//
+// Solidity: event FastUpdateFeedsSubmitted(uint32 indexed votingRoundId, address indexed signingPolicyAddress)
+func (_FastUpdater *FastUpdaterFilterer) WatchFastUpdateFeedsSubmitted(opts *bind.WatchOpts, sink chan<- *FastUpdaterFastUpdateFeedsSubmitted, votingRoundId []uint32, signingPolicyAddress []common.Address) (event.Subscription, error) {
+
+	var votingRoundIdRule []interface{}
+	for _, votingRoundIdItem := range votingRoundId {
+		votingRoundIdRule = append(votingRoundIdRule, votingRoundIdItem)
+	}
+	var signingPolicyAddressRule []interface{}
+	for _, signingPolicyAddressItem := range signingPolicyAddress {
+		signingPolicyAddressRule = append(signingPolicyAddressRule, signingPolicyAddressItem)
+	}
+
+	logs, sub, err := _FastUpdater.contract.WatchLogs(opts, "FastUpdateFeedsSubmitted", votingRoundIdRule, signingPolicyAddressRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FastUpdaterFastUpdateFeedsSubmitted)
+				if err := _FastUpdater.contract.UnpackLog(event, "FastUpdateFeedsSubmitted", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseFastUpdateFeedsSubmitted is a log parse operation binding the contract event 0x63db91b14b3d088c677f046180aefcea7a236649704d90ce810cde455d38d936.
+//
+// Solidity: event FastUpdateFeedsSubmitted(uint32 indexed votingRoundId, address indexed signingPolicyAddress)
+func (_FastUpdater *FastUpdaterFilterer) ParseFastUpdateFeedsSubmitted(log types.Log) (*FastUpdaterFastUpdateFeedsSubmitted, error) {
+	event := new(FastUpdaterFastUpdateFeedsSubmitted)
+	if err := _FastUpdater.contract.UnpackLog(event, "FastUpdateFeedsSubmitted", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,2 @@
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=fastupdater.abi --pkg=fastupdater --type=FastUpdater --out=autogen.go
+package fastupdater
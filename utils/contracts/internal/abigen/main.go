@@ -0,0 +1,122 @@
+// Command abigen generates the Go binding of a contract ABI, the go:generate step
+// of the packages in utils/contracts. It runs the binding generator of the
+// go-ethereum version in go.mod, so that the bindings do not depend on the abigen
+// binary installed by the contributor.
+//
+// With --new it adds the package of a new contract to --dir: the ABI file, the file
+// with the go:generate directive and the binding, see make new-binding.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+const bindingFile = "autogen.go"
+
+func main() {
+	abiFile := flag.String("abi", "", "ABI JSON file of the contract")
+	pkg := flag.String("pkg", "", "package name of the binding")
+	typeName := flag.String("type", "", "type name of the binding, default: the package name")
+	out := flag.String("out", "", "output file of the binding")
+	newPackage := flag.Bool("new", false, "add the package of a new contract to --dir")
+	dir := flag.String("dir", "", "directory of the new package")
+	flag.Parse()
+
+	if len(*typeName) == 0 {
+		*typeName = *pkg
+	}
+	var err error
+	if *newPackage {
+		err = addPackage(*abiFile, *pkg, *typeName, *dir)
+	} else {
+		err = generate(*abiFile, *pkg, *typeName, *out)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "abigen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(abiFile, pkg, typeName, out string) error {
+	if len(abiFile) == 0 || len(pkg) == 0 || len(out) == 0 {
+		return fmt.Errorf("--abi, --pkg and --out are required")
+	}
+	contractABI, err := os.ReadFile(abiFile)
+	if err != nil {
+		return fmt.Errorf("error reading ABI: %w", err)
+	}
+	code, err := bind.Bind(
+		[]string{typeName}, []string{string(contractABI)}, []string{""}, nil, pkg, bind.LangGo, nil, nil,
+	)
+	if err != nil {
+		return fmt.Errorf("error generating binding: %w", err)
+	}
+	return os.WriteFile(out, []byte(code), 0o600)
+}
+
+func addPackage(abiFile, pkg, typeName, dir string) error {
+	if len(abiFile) == 0 || len(pkg) == 0 || len(dir) == 0 {
+		return fmt.Errorf("--abi, --pkg and --dir are required")
+	}
+	content, err := os.ReadFile(abiFile)
+	if err != nil {
+		return fmt.Errorf("error reading ABI: %w", err)
+	}
+	contractABI, err := abiJSON(content)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, pkg+".go")); err == nil {
+		return fmt.Errorf("package %s already exists in %s", pkg, dir)
+	}
+
+	abiName := pkg + ".abi"
+	if err := os.WriteFile(filepath.Join(dir, abiName), contractABI, 0o644); err != nil {
+		return err
+	}
+	directive := fmt.Sprintf(
+		"//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=%s --pkg=%s --type=%s --out=%s\npackage %s\n",
+		abiName, pkg, typeName, bindingFile, pkg,
+	)
+	if err := os.WriteFile(filepath.Join(dir, pkg+".go"), []byte(directive), 0o644); err != nil {
+		return err
+	}
+	return generate(filepath.Join(dir, abiName), pkg, typeName, filepath.Join(dir, bindingFile))
+}
+
+// Returns the ABI of the ABI JSON file or of the compiler artifact (e.g. of Hardhat or
+// Foundry) with an abi field, indented as the committed ABI files
+func abiJSON(content []byte) ([]byte, error) {
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &artifact); err != nil {
+			return nil, fmt.Errorf("error parsing artifact: %w", err)
+		}
+		if len(artifact.ABI) == 0 {
+			return nil, fmt.Errorf("artifact has no abi field")
+		}
+		content = artifact.ABI
+	}
+	if _, err := abi.JSON(bytes.NewReader(content)); err != nil {
+		return nil, fmt.Errorf("invalid ABI: %w", err)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, bytes.TrimSpace(content), "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
@@ -0,0 +1,242 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package pchainstakemirror
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// PChainStakeMirrorMetaData contains all meta data concerning the PChainStakeMirror contract.
+var PChainStakeMirrorMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"bytes20\",\"name\":\"_nodeId\",\"type\":\"bytes20\"},{\"internalType\":\"uint256\",\"name\":\"_blockNumber\",\"type\":\"uint256\"}],\"name\":\"votePowerOfAt\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_blockNumber\",\"type\":\"uint256\"}],\"name\":\"totalVotePowerAt\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// PChainStakeMirrorABI is the input ABI used to generate the binding from.
+// Deprecated: Use PChainStakeMirrorMetaData.ABI instead.
+var PChainStakeMirrorABI = PChainStakeMirrorMetaData.ABI
+
+// PChainStakeMirror is an auto generated Go binding around an Ethereum contract.
+type PChainStakeMirror struct {
+	PChainStakeMirrorCaller     // Read-only binding to the contract
+	PChainStakeMirrorTransactor // Write-only binding to the contract
+	PChainStakeMirrorFilterer   // Log filterer for contract events
+}
+
+// PChainStakeMirrorCaller is an auto generated read-only Go binding around an Ethereum contract.
+type PChainStakeMirrorCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PChainStakeMirrorTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type PChainStakeMirrorTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PChainStakeMirrorFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type PChainStakeMirrorFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// PChainStakeMirrorSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type PChainStakeMirrorSession struct {
+	Contract     *PChainStakeMirror // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts      // Call options to use throughout this session
+	TransactOpts bind.TransactOpts  // Transaction auth options to use throughout this session
+}
+
+// PChainStakeMirrorCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type PChainStakeMirrorCallerSession struct {
+	Contract *PChainStakeMirrorCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts            // Call options to use throughout this session
+}
+
+// PChainStakeMirrorTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type PChainStakeMirrorTransactorSession struct {
+	Contract     *PChainStakeMirrorTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts            // Transaction auth options to use throughout this session
+}
+
+// PChainStakeMirrorRaw is an auto generated low-level Go binding around an Ethereum contract.
+type PChainStakeMirrorRaw struct {
+	Contract *PChainStakeMirror // Generic contract binding to access the raw methods on
+}
+
+// PChainStakeMirrorCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type PChainStakeMirrorCallerRaw struct {
+	Contract *PChainStakeMirrorCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// PChainStakeMirrorTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type PChainStakeMirrorTransactorRaw struct {
+	Contract *PChainStakeMirrorTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewPChainStakeMirror creates a new instance of PChainStakeMirror, bound to a specific deployed contract.
+func NewPChainStakeMirror(address common.Address, backend bind.ContractBackend) (*PChainStakeMirror, error) {
+	contract, err := bindPChainStakeMirror(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &PChainStakeMirror{PChainStakeMirrorCaller: PChainStakeMirrorCaller{contract: contract}, PChainStakeMirrorTransactor: PChainStakeMirrorTransactor{contract: contract}, PChainStakeMirrorFilterer: PChainStakeMirrorFilterer{contract: contract}}, nil
+}
+
+// NewPChainStakeMirrorCaller creates a new read-only instance of PChainStakeMirror, bound to a specific deployed contract.
+func NewPChainStakeMirrorCaller(address common.Address, caller bind.ContractCaller) (*PChainStakeMirrorCaller, error) {
+	contract, err := bindPChainStakeMirror(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PChainStakeMirrorCaller{contract: contract}, nil
+}
+
+// NewPChainStakeMirrorTransactor creates a new write-only instance of PChainStakeMirror, bound to a specific deployed contract.
+func NewPChainStakeMirrorTransactor(address common.Address, transactor bind.ContractTransactor) (*PChainStakeMirrorTransactor, error) {
+	contract, err := bindPChainStakeMirror(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PChainStakeMirrorTransactor{contract: contract}, nil
+}
+
+// NewPChainStakeMirrorFilterer creates a new log filterer instance of PChainStakeMirror, bound to a specific deployed contract.
+func NewPChainStakeMirrorFilterer(address common.Address, filterer bind.ContractFilterer) (*PChainStakeMirrorFilterer, error) {
+	contract, err := bindPChainStakeMirror(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &PChainStakeMirrorFilterer{contract: contract}, nil
+}
+
+// bindPChainStakeMirror binds a generic wrapper to an already deployed contract.
+func bindPChainStakeMirror(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(PChainStakeMirrorABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PChainStakeMirror *PChainStakeMirrorRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PChainStakeMirror.Contract.PChainStakeMirrorCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PChainStakeMirror *PChainStakeMirrorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PChainStakeMirror.Contract.PChainStakeMirrorTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PChainStakeMirror *PChainStakeMirrorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PChainStakeMirror.Contract.PChainStakeMirrorTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_PChainStakeMirror *PChainStakeMirrorCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _PChainStakeMirror.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_PChainStakeMirror *PChainStakeMirrorTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _PChainStakeMirror.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_PChainStakeMirror *PChainStakeMirrorTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _PChainStakeMirror.Contract.contract.Transact(opts, method, params...)
+}
+
+// TotalVotePowerAt is a free data retrieval call binding the contract method 0x3e5aa26a.
+//
+// Solidity: function totalVotePowerAt(uint256 _blockNumber) view returns(uint256)
+func (_PChainStakeMirror *PChainStakeMirrorCaller) TotalVotePowerAt(opts *bind.CallOpts, _blockNumber *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _PChainStakeMirror.contract.Call(opts, &out, "totalVotePowerAt", _blockNumber)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalVotePowerAt is a free data retrieval call binding the contract method 0x3e5aa26a.
+//
+// Solidity: function totalVotePowerAt(uint256 _blockNumber) view returns(uint256)
+func (_PChainStakeMirror *PChainStakeMirrorSession) TotalVotePowerAt(_blockNumber *big.Int) (*big.Int, error) {
+	return _PChainStakeMirror.Contract.TotalVotePowerAt(&_PChainStakeMirror.CallOpts, _blockNumber)
+}
+
+// TotalVotePowerAt is a free data retrieval call binding the contract method 0x3e5aa26a.
+//
+// Solidity: function totalVotePowerAt(uint256 _blockNumber) view returns(uint256)
+func (_PChainStakeMirror *PChainStakeMirrorCallerSession) TotalVotePowerAt(_blockNumber *big.Int) (*big.Int, error) {
+	return _PChainStakeMirror.Contract.TotalVotePowerAt(&_PChainStakeMirror.CallOpts, _blockNumber)
+}
+
+// VotePowerOfAt is a free data retrieval call binding the contract method 0x46431374.
+//
+// Solidity: function votePowerOfAt(bytes20 _nodeId, uint256 _blockNumber) view returns(uint256)
+func (_PChainStakeMirror *PChainStakeMirrorCaller) VotePowerOfAt(opts *bind.CallOpts, _nodeId [20]byte, _blockNumber *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _PChainStakeMirror.contract.Call(opts, &out, "votePowerOfAt", _nodeId, _blockNumber)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// VotePowerOfAt is a free data retrieval call binding the contract method 0x46431374.
+//
+// Solidity: function votePowerOfAt(bytes20 _nodeId, uint256 _blockNumber) view returns(uint256)
+func (_PChainStakeMirror *PChainStakeMirrorSession) VotePowerOfAt(_nodeId [20]byte, _blockNumber *big.Int) (*big.Int, error) {
+	return _PChainStakeMirror.Contract.VotePowerOfAt(&_PChainStakeMirror.CallOpts, _nodeId, _blockNumber)
+}
+
+// VotePowerOfAt is a free data retrieval call binding the contract method 0x46431374.
+//
+// Solidity: function votePowerOfAt(bytes20 _nodeId, uint256 _blockNumber) view returns(uint256)
+func (_PChainStakeMirror *PChainStakeMirrorCallerSession) VotePowerOfAt(_nodeId [20]byte, _blockNumber *big.Int) (*big.Int, error) {
+	return _PChainStakeMirror.Contract.VotePowerOfAt(&_PChainStakeMirror.CallOpts, _nodeId, _blockNumber)
+}
@@ -0,0 +1,2 @@
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=pchainstakemirror.abi --pkg=pchainstakemirror --type=PChainStakeMirror --out=autogen.go
+package pchainstakemirror
@@ -1,2 +1,2 @@
-//go:generate  abigen --abi=registry.abi --pkg=registry --type=Registry --out=autogen.go
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=registry.abi --pkg=registry --type=Registry --out=autogen.go
 package registry
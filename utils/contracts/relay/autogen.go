@@ -26,7 +26,6 @@ var (
 	_ = common.Big1
 	_ = types.BloomLookup
 	_ = event.NewSubscription
-	_ = abi.ConvertType
 )
 
 // IIRelaySigningPolicy is an auto generated low-level Go binding around an user-defined struct.
@@ -145,11 +144,11 @@ func NewRelayFilterer(address common.Address, filterer bind.ContractFilterer) (*
 
 // bindRelay binds a generic wrapper to an already deployed contract.
 func bindRelay(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
-	parsed, err := RelayMetaData.GetAbi()
+	parsed, err := abi.JSON(strings.NewReader(RelayABI))
 	if err != nil {
 		return nil, err
 	}
-	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
 }
 
 // Call invokes the (constant) contract method with params as input values and
@@ -1,2 +1,2 @@
-//go:generate  abigen --abi=relay.abi --pkg=relay --type=Relay --out=autogen.go
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=relay.abi --pkg=relay --type=Relay --out=autogen.go
 package relay
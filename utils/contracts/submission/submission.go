@@ -1,2 +1,2 @@
-//go:generate  abigen --abi=submission.abi --pkg=submission --type=Submission --out=autogen.go
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=submission.abi --pkg=submission --type=Submission --out=autogen.go
 package submission
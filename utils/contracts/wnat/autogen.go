@@ -0,0 +1,242 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package wnat
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// WNatMetaData contains all meta data concerning the WNat contract.
+var WNatMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"_owner\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"_blockNumber\",\"type\":\"uint256\"}],\"name\":\"votePowerOfAt\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"uint256\",\"name\":\"_blockNumber\",\"type\":\"uint256\"}],\"name\":\"totalVotePowerAt\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// WNatABI is the input ABI used to generate the binding from.
+// Deprecated: Use WNatMetaData.ABI instead.
+var WNatABI = WNatMetaData.ABI
+
+// WNat is an auto generated Go binding around an Ethereum contract.
+type WNat struct {
+	WNatCaller     // Read-only binding to the contract
+	WNatTransactor // Write-only binding to the contract
+	WNatFilterer   // Log filterer for contract events
+}
+
+// WNatCaller is an auto generated read-only Go binding around an Ethereum contract.
+type WNatCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// WNatTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type WNatTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// WNatFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type WNatFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// WNatSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type WNatSession struct {
+	Contract     *WNat             // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// WNatCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type WNatCallerSession struct {
+	Contract *WNatCaller   // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// WNatTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type WNatTransactorSession struct {
+	Contract     *WNatTransactor   // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// WNatRaw is an auto generated low-level Go binding around an Ethereum contract.
+type WNatRaw struct {
+	Contract *WNat // Generic contract binding to access the raw methods on
+}
+
+// WNatCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type WNatCallerRaw struct {
+	Contract *WNatCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// WNatTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type WNatTransactorRaw struct {
+	Contract *WNatTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewWNat creates a new instance of WNat, bound to a specific deployed contract.
+func NewWNat(address common.Address, backend bind.ContractBackend) (*WNat, error) {
+	contract, err := bindWNat(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &WNat{WNatCaller: WNatCaller{contract: contract}, WNatTransactor: WNatTransactor{contract: contract}, WNatFilterer: WNatFilterer{contract: contract}}, nil
+}
+
+// NewWNatCaller creates a new read-only instance of WNat, bound to a specific deployed contract.
+func NewWNatCaller(address common.Address, caller bind.ContractCaller) (*WNatCaller, error) {
+	contract, err := bindWNat(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &WNatCaller{contract: contract}, nil
+}
+
+// NewWNatTransactor creates a new write-only instance of WNat, bound to a specific deployed contract.
+func NewWNatTransactor(address common.Address, transactor bind.ContractTransactor) (*WNatTransactor, error) {
+	contract, err := bindWNat(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &WNatTransactor{contract: contract}, nil
+}
+
+// NewWNatFilterer creates a new log filterer instance of WNat, bound to a specific deployed contract.
+func NewWNatFilterer(address common.Address, filterer bind.ContractFilterer) (*WNatFilterer, error) {
+	contract, err := bindWNat(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &WNatFilterer{contract: contract}, nil
+}
+
+// bindWNat binds a generic wrapper to an already deployed contract.
+func bindWNat(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(WNatABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_WNat *WNatRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _WNat.Contract.WNatCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_WNat *WNatRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _WNat.Contract.WNatTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_WNat *WNatRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _WNat.Contract.WNatTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_WNat *WNatCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _WNat.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_WNat *WNatTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _WNat.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_WNat *WNatTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _WNat.Contract.contract.Transact(opts, method, params...)
+}
+
+// TotalVotePowerAt is a free data retrieval call binding the contract method 0x3e5aa26a.
+//
+// Solidity: function totalVotePowerAt(uint256 _blockNumber) view returns(uint256)
+func (_WNat *WNatCaller) TotalVotePowerAt(opts *bind.CallOpts, _blockNumber *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _WNat.contract.Call(opts, &out, "totalVotePowerAt", _blockNumber)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// TotalVotePowerAt is a free data retrieval call binding the contract method 0x3e5aa26a.
+//
+// Solidity: function totalVotePowerAt(uint256 _blockNumber) view returns(uint256)
+func (_WNat *WNatSession) TotalVotePowerAt(_blockNumber *big.Int) (*big.Int, error) {
+	return _WNat.Contract.TotalVotePowerAt(&_WNat.CallOpts, _blockNumber)
+}
+
+// TotalVotePowerAt is a free data retrieval call binding the contract method 0x3e5aa26a.
+//
+// Solidity: function totalVotePowerAt(uint256 _blockNumber) view returns(uint256)
+func (_WNat *WNatCallerSession) TotalVotePowerAt(_blockNumber *big.Int) (*big.Int, error) {
+	return _WNat.Contract.TotalVotePowerAt(&_WNat.CallOpts, _blockNumber)
+}
+
+// VotePowerOfAt is a free data retrieval call binding the contract method 0x92bfe6d8.
+//
+// Solidity: function votePowerOfAt(address _owner, uint256 _blockNumber) view returns(uint256)
+func (_WNat *WNatCaller) VotePowerOfAt(opts *bind.CallOpts, _owner common.Address, _blockNumber *big.Int) (*big.Int, error) {
+	var out []interface{}
+	err := _WNat.contract.Call(opts, &out, "votePowerOfAt", _owner, _blockNumber)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// VotePowerOfAt is a free data retrieval call binding the contract method 0x92bfe6d8.
+//
+// Solidity: function votePowerOfAt(address _owner, uint256 _blockNumber) view returns(uint256)
+func (_WNat *WNatSession) VotePowerOfAt(_owner common.Address, _blockNumber *big.Int) (*big.Int, error) {
+	return _WNat.Contract.VotePowerOfAt(&_WNat.CallOpts, _owner, _blockNumber)
+}
+
+// VotePowerOfAt is a free data retrieval call binding the contract method 0x92bfe6d8.
+//
+// Solidity: function votePowerOfAt(address _owner, uint256 _blockNumber) view returns(uint256)
+func (_WNat *WNatCallerSession) VotePowerOfAt(_owner common.Address, _blockNumber *big.Int) (*big.Int, error) {
+	return _WNat.Contract.VotePowerOfAt(&_WNat.CallOpts, _owner, _blockNumber)
+}
@@ -0,0 +1,2 @@
+//go:generate go run flare-tlc/utils/contracts/internal/abigen --abi=wnat.abi --pkg=wnat --type=WNat --out=autogen.go
+package wnat
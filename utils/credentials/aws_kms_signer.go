@@ -0,0 +1,47 @@
+package credentials
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/pkg/errors"
+)
+
+// NewAWSKMSSigner returns a signer for an AWS KMS key with ECC_SECG_P256K1 key spec.
+// Credentials are read from the default AWS credential chain, region is optional.
+func NewAWSKMSSigner(ctx context.Context, keyID string, region string) (Signer, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if len(region) > 0 {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading AWS config")
+	}
+	client := kms.NewFromConfig(cfg)
+
+	pubKeyOut, err := client.GetPublicKey(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching AWS KMS public key")
+	}
+	publicKey, err := publicKeyFromDER(pubKeyOut.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDigestSigner(publicKey, func(ctx context.Context, digest []byte) ([]byte, error) {
+		out, err := client.Sign(ctx, &kms.SignInput{
+			KeyId:            aws.String(keyID),
+			Message:          digest,
+			MessageType:      kmstypes.MessageTypeDigest,
+			SigningAlgorithm: kmstypes.SigningAlgorithmSpecEcdsaSha256,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "AWS KMS sign")
+		}
+		return out.Signature, nil
+	}), nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 )
@@ -39,3 +40,12 @@ func PrivateKeyFromHex(privateKey string) (*ecdsa.PrivateKey, error) {
 	}
 	return pk, nil
 }
+
+// PrivateKeyFromKeystore decrypts a geth JSON keystore (Web3 Secret Storage) file
+func PrivateKeyFromKeystore(keyJSON []byte, passphrase string) (*ecdsa.PrivateKey, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "keystore.DecryptKey")
+	}
+	return key.PrivateKey, nil
+}
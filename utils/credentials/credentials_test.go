@@ -0,0 +1,30 @@
+package credentials
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateKeyFromKeystore(t *testing.T) {
+	pk, err := crypto.HexToECDSA(testPrivateKeyHex)
+	require.NoError(t, err)
+
+	key := &keystore.Key{
+		Id:         uuid.New(),
+		Address:    crypto.PubkeyToAddress(pk.PublicKey),
+		PrivateKey: pk,
+	}
+	keyJSON, err := keystore.EncryptKey(key, "passphrase", keystore.LightScryptN, keystore.LightScryptP)
+	require.NoError(t, err)
+
+	decrypted, err := PrivateKeyFromKeystore(keyJSON, "passphrase")
+	require.NoError(t, err)
+	require.Equal(t, pk.D, decrypted.D)
+
+	_, err = PrivateKeyFromKeystore(keyJSON, "wrong")
+	require.Error(t, err)
+}
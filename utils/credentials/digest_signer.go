@@ -0,0 +1,112 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/asn1"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1HalfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// Signs a 32 byte digest and returns a DER encoded ECDSA signature, as returned by KMS services
+type derSignFunc func(ctx context.Context, digest []byte) ([]byte, error)
+
+// Signer for keys held by a service that only signs raw digests. The recovery id
+// is not part of DER signatures, it is computed from the known public key.
+type digestSigner struct {
+	publicKey *ecdsa.PublicKey
+	address   common.Address
+	sign      derSignFunc
+}
+
+func newDigestSigner(publicKey *ecdsa.PublicKey, sign derSignFunc) *digestSigner {
+	return &digestSigner{
+		publicKey: publicKey,
+		address:   crypto.PubkeyToAddress(*publicKey),
+		sign:      sign,
+	}
+}
+
+func (s *digestSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *digestSigner) SignText(data []byte) ([]byte, error) {
+	return s.signDigest(accounts.TextHash(data))
+}
+
+func (s *digestSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	signature, err := s.signDigest(signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, signature)
+}
+
+func (s *digestSigner) signDigest(digest []byte) ([]byte, error) {
+	der, err := s.sign(context.Background(), digest)
+	if err != nil {
+		return nil, err
+	}
+	return signatureFromDER(der, digest, s.publicKey)
+}
+
+type derSignature struct {
+	R, S *big.Int
+}
+
+// Converts a DER encoded signature to [R || S || V] format. S is normalized to the lower
+// half of the curve order (required by Ethereum) and V is found by public key recovery.
+func signatureFromDER(der []byte, digest []byte, publicKey *ecdsa.PublicKey) ([]byte, error) {
+	var sig derSignature
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, errors.Wrap(err, "invalid DER signature")
+	}
+	if sig.S.Cmp(secp256k1HalfN) > 0 {
+		sig.S = new(big.Int).Sub(secp256k1N, sig.S)
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	sig.R.FillBytes(signature[0:32])
+	sig.S.FillBytes(signature[32:64])
+
+	expected := crypto.FromECDSAPub(publicKey)
+	for v := byte(0); v < 2; v++ {
+		signature[64] = v
+		recovered, err := crypto.Ecrecover(digest, signature)
+		if err == nil && bytes.Equal(recovered, expected) {
+			return signature, nil
+		}
+	}
+	return nil, errors.New("signature does not match the signer public key")
+}
+
+type subjectPublicKeyInfo struct {
+	Algorithm struct {
+		Algorithm  asn1.ObjectIdentifier
+		Parameters asn1.ObjectIdentifier
+	}
+	PublicKey asn1.BitString
+}
+
+// Parses a DER encoded SubjectPublicKeyInfo with a secp256k1 key, the standard library
+// x509 package does not support this curve.
+func publicKeyFromDER(der []byte) (*ecdsa.PublicKey, error) {
+	var info subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, errors.Wrap(err, "invalid DER public key")
+	}
+	return crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+}
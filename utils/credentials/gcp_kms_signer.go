@@ -0,0 +1,90 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	gcpKMSEndpoint = "https://cloudkms.googleapis.com/v1/"
+	gcpKMSScope    = "https://www.googleapis.com/auth/cloudkms"
+)
+
+// NewGCPKMSSigner returns a signer for a Google Cloud KMS key version with the
+// EC_SIGN_SECP256K1_SHA256 algorithm. keyVersion is the full resource name:
+// projects/<p>/locations/<l>/keyRings/<r>/cryptoKeys/<k>/cryptoKeyVersions/<v>.
+// Credentials are read from Application Default Credentials.
+func NewGCPKMSSigner(ctx context.Context, keyVersion string) (Signer, error) {
+	client, err := google.DefaultClient(ctx, gcpKMSScope)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading Google Cloud credentials")
+	}
+
+	var pubKeyResp struct {
+		Pem string `json:"pem"`
+	}
+	if err := gcpKMSCall(ctx, client, http.MethodGet, keyVersion+"/publicKey", nil, &pubKeyResp); err != nil {
+		return nil, errors.Wrap(err, "error fetching Google Cloud KMS public key")
+	}
+	block, _ := pem.Decode([]byte(pubKeyResp.Pem))
+	if block == nil {
+		return nil, errors.New("invalid Google Cloud KMS public key")
+	}
+	publicKey, err := publicKeyFromDER(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDigestSigner(publicKey, func(ctx context.Context, digest []byte) ([]byte, error) {
+		req := map[string]any{
+			"digest": map[string]string{"sha256": base64.StdEncoding.EncodeToString(digest)},
+		}
+		var resp struct {
+			Signature string `json:"signature"`
+		}
+		if err := gcpKMSCall(ctx, client, http.MethodPost, keyVersion+":asymmetricSign", req, &resp); err != nil {
+			return nil, errors.Wrap(err, "Google Cloud KMS sign")
+		}
+		return base64.StdEncoding.DecodeString(resp.Signature)
+	}), nil
+}
+
+func gcpKMSCall(ctx context.Context, client *http.Client, method string, path string, body any, result any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, gcpKMSEndpoint+path, reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, respBody)
+	}
+	return json.Unmarshal(respBody, result)
+}
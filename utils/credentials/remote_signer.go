@@ -0,0 +1,92 @@
+package credentials
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// Signer using a remote signing service with the eth_sign and eth_signTransaction
+// JSON-RPC methods, e.g. web3signer or clef.
+type remoteSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+type remoteSignTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to,omitempty"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+func NewRemoteSigner(ctx context.Context, url string, address common.Address) (Signer, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, errors.Wrap(err, "error connecting to remote signer")
+	}
+	return &remoteSigner{client: client, address: address}, nil
+}
+
+func (s *remoteSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *remoteSigner) SignText(data []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	if err := s.client.CallContext(context.Background(), &signature, "eth_sign", s.address, hexutil.Bytes(data)); err != nil {
+		return nil, errors.Wrap(err, "remote signer eth_sign")
+	}
+	if len(signature) != crypto.SignatureLength {
+		return nil, errors.Errorf("invalid signature length %d", len(signature))
+	}
+	// eth_sign returns V as 27 or 28
+	if signature[64] >= 27 {
+		signature[64] -= 27
+	}
+	return signature, nil
+}
+
+func (s *remoteSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := remoteSignTxArgs{
+		From:    s.address,
+		To:      tx.To(),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   (*hexutil.Big)(tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    tx.Data(),
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+
+	var raw hexutil.Bytes
+	if err := s.client.CallContext(context.Background(), &raw, "eth_signTransaction", args); err != nil {
+		return nil, errors.Wrap(err, "remote signer eth_signTransaction")
+	}
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return nil, errors.Wrap(err, "invalid signed transaction returned by remote signer")
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+	if err != nil || sender != s.address {
+		return nil, errors.New("transaction returned by remote signer is not signed by the signer address")
+	}
+	return signedTx, nil
+}
@@ -0,0 +1,90 @@
+package credentials
+
+import (
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RotatingSigner is the signing policy signer with a staged next key. The current key
+// signs until the rotation reward epoch starts, then the next key signs. Signatures
+// checked against the signing policy of a specific reward epoch, e.g. the voter
+// registration for the next reward epoch, use ForRewardEpoch.
+type RotatingSigner struct {
+	current Signer
+	next    Signer // nil if no rotation is staged
+
+	rotationEpoch int64
+	rotated       atomic.Bool
+}
+
+func NewRotatingSigner(current Signer, next Signer, rotationEpoch int64) *RotatingSigner {
+	return &RotatingSigner{current: current, next: next, rotationEpoch: rotationEpoch}
+}
+
+// Staged returns true if a next key is configured
+func (s *RotatingSigner) Staged() bool {
+	return s.next != nil
+}
+
+func (s *RotatingSigner) RotationEpoch() int64 {
+	return s.rotationEpoch
+}
+
+// Next returns the staged next key, nil if no rotation is staged
+func (s *RotatingSigner) Next() Signer {
+	return s.next
+}
+
+// Rotated returns true if the next key is active
+func (s *RotatingSigner) Rotated() bool {
+	return s.rotated.Load()
+}
+
+// SetRewardEpoch activates the next key if the reward epoch is the rotation epoch or
+// later. Returns true if the key was switched by this call.
+func (s *RotatingSigner) SetRewardEpoch(rewardEpochId int64) bool {
+	if s.next == nil || rewardEpochId < s.rotationEpoch {
+		return false
+	}
+	return s.rotated.CompareAndSwap(false, true)
+}
+
+// Active returns the key signing in the current reward epoch
+func (s *RotatingSigner) Active() Signer {
+	if s.rotated.Load() {
+		return s.next
+	}
+	return s.current
+}
+
+// ForRewardEpoch returns the key of the signing policy address of the reward epoch
+func (s *RotatingSigner) ForRewardEpoch(rewardEpochId int64) Signer {
+	if s.next != nil && rewardEpochId >= s.rotationEpoch {
+		return s.next
+	}
+	return s.current
+}
+
+func (s *RotatingSigner) Address() common.Address {
+	return s.Active().Address()
+}
+
+func (s *RotatingSigner) SignText(data []byte) ([]byte, error) {
+	return s.Active().SignText(data)
+}
+
+func (s *RotatingSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return s.Active().SignTx(tx, chainID)
+}
+
+// SignerForRewardEpoch returns the key of the signing policy address of the reward
+// epoch if s is a RotatingSigner, otherwise s.
+func SignerForRewardEpoch(s Signer, rewardEpochId int64) Signer {
+	if rotating, ok := s.(*RotatingSigner); ok {
+		return rotating.ForRewardEpoch(rewardEpochId)
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package credentials
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingSigner(t *testing.T) {
+	currentKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	nextKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	current := NewPrivateKeySigner(currentKey)
+	next := NewPrivateKeySigner(nextKey)
+
+	s := NewRotatingSigner(current, next, 10)
+	require.True(t, s.Staged())
+	require.Equal(t, current.Address(), s.Address())
+	require.Equal(t, current, s.ForRewardEpoch(9))
+	require.Equal(t, next, s.ForRewardEpoch(10))
+	require.Equal(t, next, SignerForRewardEpoch(s, 11))
+	require.Equal(t, current, SignerForRewardEpoch(current, 11))
+
+	require.False(t, s.SetRewardEpoch(9))
+	require.False(t, s.Rotated())
+	require.True(t, s.SetRewardEpoch(10))
+	require.False(t, s.SetRewardEpoch(11))
+	require.True(t, s.Rotated())
+	require.Equal(t, next.Address(), s.Address())
+
+	signature, err := s.SignText([]byte("data"))
+	require.NoError(t, err)
+	expected, err := next.SignText([]byte("data"))
+	require.NoError(t, err)
+	require.Equal(t, expected, signature)
+
+	// without a staged key the current key is always used
+	s = NewRotatingSigner(current, nil, 0)
+	require.False(t, s.Staged())
+	require.False(t, s.SetRewardEpoch(100))
+	require.Equal(t, current, s.ForRewardEpoch(100))
+	require.Equal(t, current.Address(), s.Address())
+}
@@ -0,0 +1,65 @@
+package credentials
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs messages and transactions for a single account. The key may be held
+// in memory or by an external service (KMS, remote signer).
+type Signer interface {
+	Address() common.Address
+
+	// SignText signs accounts.TextHash(data) and returns the signature in
+	// [R || S || V] format, where V is 0 or 1.
+	SignText(data []byte) ([]byte, error)
+
+	// SignTx returns the transaction signed with the latest signer for chainID.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+type privateKeySigner struct {
+	privateKey *ecdsa.PrivateKey
+	address    common.Address
+}
+
+func NewPrivateKeySigner(pk *ecdsa.PrivateKey) Signer {
+	return &privateKeySigner{
+		privateKey: pk,
+		address:    crypto.PubkeyToAddress(pk.PublicKey),
+	}
+}
+
+func (s *privateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *privateKeySigner) SignText(data []byte) ([]byte, error) {
+	return crypto.Sign(accounts.TextHash(data), s.privateKey)
+}
+
+func (s *privateKeySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.privateKey)
+}
+
+// TransactOptsFromSigner returns transact opts for contract bindings, transactions are signed by s.
+func TransactOptsFromSigner(s Signer, chainID int) *bind.TransactOpts {
+	chainIDBig := big.NewInt(int64(chainID))
+	return &bind.TransactOpts{
+		From: s.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(tx, chainIDBig)
+		},
+		Context: context.Background(),
+	}
+}
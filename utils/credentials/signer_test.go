@@ -0,0 +1,75 @@
+package credentials
+
+import (
+	"context"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testPrivateKeyHex = "4f65bffe3c8ed6c0b812e84d35402e949feea042061cc1635fe6ae83ed84df4a"
+
+// Emulates a KMS service: signs with the private key and returns a DER
+// signature with a high S value.
+func testDERSignFunc(t *testing.T) derSignFunc {
+	pk, err := crypto.HexToECDSA(testPrivateKeyHex)
+	require.NoError(t, err)
+
+	return func(_ context.Context, digest []byte) ([]byte, error) {
+		sig, err := crypto.Sign(digest, pk)
+		if err != nil {
+			return nil, err
+		}
+		s := new(big.Int).SetBytes(sig[32:64])
+		return asn1.Marshal(derSignature{
+			R: new(big.Int).SetBytes(sig[0:32]),
+			S: new(big.Int).Sub(secp256k1N, s),
+		})
+	}
+}
+
+func TestDigestSigner(t *testing.T) {
+	pk, err := crypto.HexToECDSA(testPrivateKeyHex)
+	require.NoError(t, err)
+
+	localSigner := NewPrivateKeySigner(pk)
+	kmsSigner := newDigestSigner(&pk.PublicKey, testDERSignFunc(t))
+	require.Equal(t, localSigner.Address(), kmsSigner.Address())
+
+	data := crypto.Keccak256([]byte("test"))
+	expected, err := localSigner.SignText(data)
+	require.NoError(t, err)
+	signature, err := kmsSigner.SignText(data)
+	require.NoError(t, err)
+	require.Equal(t, expected, signature)
+
+	chainID := big.NewInt(16)
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, To: &common.Address{}})
+	signedTx, err := kmsSigner.SignTx(tx, chainID)
+	require.NoError(t, err)
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+	require.NoError(t, err)
+	require.Equal(t, localSigner.Address(), sender)
+}
+
+func TestPublicKeyFromDER(t *testing.T) {
+	pk, err := crypto.HexToECDSA(testPrivateKeyHex)
+	require.NoError(t, err)
+
+	var info subjectPublicKeyInfo
+	info.Algorithm.Algorithm = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	info.Algorithm.Parameters = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+	pubKeyBytes := crypto.FromECDSAPub(&pk.PublicKey)
+	info.PublicKey = asn1.BitString{Bytes: pubKeyBytes, BitLength: len(pubKeyBytes) * 8}
+	der, err := asn1.Marshal(info)
+	require.NoError(t, err)
+
+	publicKey, err := publicKeyFromDER(der)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(pk.PublicKey), crypto.PubkeyToAddress(*publicKey))
+}
@@ -1,10 +1,29 @@
 package utils
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
 
+// Epoch converts between times and epoch indices. Start and Period describe the
+// schedule from epoch 0, changes announced on-chain are added with AddChange and
+// apply from their epoch on, so conversions of earlier epochs remain correct.
 type Epoch struct {
 	Start  time.Time
 	Period time.Duration
+
+	mu sync.RWMutex
+	// sorted by epoch, start times are increasing
+	changes []EpochChange
+}
+
+// EpochChange is a schedule change, epochs from Epoch on start at Start and last Period
+type EpochChange struct {
+	Epoch  int64
+	Start  time.Time
+	Period time.Duration
 }
 
 func NewEpoch(start time.Time, duration time.Duration) *Epoch {
@@ -14,22 +33,93 @@ func NewEpoch(start time.Time, duration time.Duration) *Epoch {
 	}
 }
 
-func (e Epoch) EpochIndex(t time.Time) int64 {
-	return int64(t.Sub(e.Start) / e.Period)
+func (e *Epoch) EpochIndex(t time.Time) int64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	i := sort.Search(len(e.changes), func(i int) bool { return e.changes[i].Start.After(t) })
+	var index int64
+	if i == 0 {
+		index = int64(t.Sub(e.Start) / e.Period)
+	} else {
+		c := e.changes[i-1]
+		index = c.Epoch + int64(t.Sub(c.Start)/c.Period)
+	}
+	// the last epoch before a change is longer if the change starts later
+	if i < len(e.changes) && index >= e.changes[i].Epoch {
+		index = e.changes[i].Epoch - 1
+	}
+	return index
 }
 
-func (e Epoch) StartTime(epoch int64) time.Time {
-	return e.Start.Add(time.Duration(epoch) * e.Period)
+func (e *Epoch) StartTime(epoch int64) time.Time {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	c := e.segment(epoch)
+	return c.Start.Add(time.Duration(epoch-c.Epoch) * c.Period)
 }
 
-func (e Epoch) EndTime(epoch int64) time.Time {
+func (e *Epoch) EndTime(epoch int64) time.Time {
 	return e.StartTime(epoch + 1)
 }
 
-func (e Epoch) TimeRange(epoch int64) (time.Time, time.Time) {
+func (e *Epoch) TimeRange(epoch int64) (time.Time, time.Time) {
 	return e.StartTime(epoch), e.EndTime(epoch)
 }
 
+// Duration returns the period of the epoch
+func (e *Epoch) Duration(epoch int64) time.Duration {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.segment(epoch).Period
+}
+
+// Returns the schedule the epoch belongs to, the caller holds the lock
+func (e *Epoch) segment(epoch int64) EpochChange {
+	i := sort.Search(len(e.changes), func(i int) bool { return e.changes[i].Epoch > epoch })
+	if i == 0 {
+		return EpochChange{Start: e.Start, Period: e.Period}
+	}
+	return e.changes[i-1]
+}
+
+// AddChange sets the schedule from the epoch on, replacing the changes announced
+// for the same or later epochs. Returns false if the schedule already matches.
+func (e *Epoch) AddChange(epoch int64, start time.Time, period time.Duration) (bool, error) {
+	if period <= 0 {
+		return false, fmt.Errorf("invalid period %v of epoch %d", period, epoch)
+	}
+	if epoch <= 0 {
+		return false, fmt.Errorf("epoch %d of the schedule change must be positive", epoch)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	current := e.segment(epoch)
+	if current.Period == period && current.Start.Add(time.Duration(epoch-current.Epoch)*current.Period).Equal(start) {
+		return false, nil
+	}
+
+	i := sort.Search(len(e.changes), func(i int) bool { return e.changes[i].Epoch >= epoch })
+	previous := e.segment(epoch - 1)
+	if previousStart := previous.Start.Add(time.Duration(epoch-1-previous.Epoch) * previous.Period); !start.After(previousStart) {
+		return false, fmt.Errorf("epoch %d starting at %v does not start after epoch %d", epoch, start, epoch-1)
+	}
+	e.changes = append(e.changes[:i], EpochChange{Epoch: epoch, Start: start, Period: period})
+	return true, nil
+}
+
+// Changes returns the schedule changes sorted by epoch
+func (e *Epoch) Changes() []EpochChange {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return append([]EpochChange(nil), e.changes...)
+}
+
 type IntEpoch struct {
 	Start  int64
 	Period int64
@@ -0,0 +1,106 @@
+// Package epoch converts between timestamps, voting round ids and reward epoch ids.
+//
+// Voting rounds start at the first voting round start timestamp and last the voting
+// epoch duration of the on-chain schedule. Reward epochs are expected to start every
+// reward epoch duration (in voting rounds) from the first voting round of reward
+// epoch 0. A reward epoch starts later than expected if its signing policy is
+// initialized late, the signing policy carries the actual first voting round
+// (startVotingRoundId), which is used once it is set with SetFirstVotingRound.
+package epoch
+
+import (
+	"flare-tlc/utils"
+	"sync"
+	"time"
+)
+
+// Schedule of the voting rounds and reward epochs
+type Schedule struct {
+	VotingRounds *utils.Epoch
+	// in voting rounds, Start is the first voting round of reward epoch 0
+	RewardEpochs utils.IntEpoch
+
+	mu sync.RWMutex
+	// first voting rounds of the reward epochs from the signing policies
+	firstVotingRounds map[int64]int64
+}
+
+func NewSchedule(votingEpoch *utils.Epoch, rewardEpoch *utils.IntEpoch) *Schedule {
+	return &Schedule{
+		VotingRounds:      votingEpoch,
+		RewardEpochs:      *rewardEpoch,
+		firstVotingRounds: make(map[int64]int64),
+	}
+}
+
+// VotingRound returns the voting round at the time
+func (s *Schedule) VotingRound(t time.Time) int64 {
+	return s.VotingRounds.EpochIndex(t)
+}
+
+// VotingRoundStart returns the start time of the voting round
+func (s *Schedule) VotingRoundStart(votingRoundId int64) time.Time {
+	return s.VotingRounds.StartTime(votingRoundId)
+}
+
+// VotingRoundEnd returns the end time of the voting round, the start of the next one
+func (s *Schedule) VotingRoundEnd(votingRoundId int64) time.Time {
+	return s.VotingRounds.EndTime(votingRoundId)
+}
+
+// ExpectedFirstVotingRound returns the first voting round of the reward epoch by the
+// schedule, the reward epoch does not start earlier
+func (s *Schedule) ExpectedFirstVotingRound(rewardEpochId int64) int64 {
+	return s.RewardEpochs.Start + rewardEpochId*s.RewardEpochs.Period
+}
+
+// FirstVotingRound returns the first voting round of the reward epoch, the one of its
+// signing policy if set, otherwise the expected one
+func (s *Schedule) FirstVotingRound(rewardEpochId int64) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if votingRoundId, ok := s.firstVotingRounds[rewardEpochId]; ok {
+		return votingRoundId
+	}
+	return s.ExpectedFirstVotingRound(rewardEpochId)
+}
+
+// SetFirstVotingRound sets the first voting round of the reward epoch, the
+// startVotingRoundId of its signing policy
+func (s *Schedule) SetFirstVotingRound(rewardEpochId int64, votingRoundId int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.firstVotingRounds[rewardEpochId] = votingRoundId
+}
+
+// RewardEpoch returns the reward epoch of the voting round. Voting rounds before the
+// first voting round of reward epoch 0 belong to reward epoch 0.
+func (s *Schedule) RewardEpoch(votingRoundId int64) int64 {
+	if votingRoundId < s.RewardEpochs.Start {
+		return 0
+	}
+	rewardEpochId := s.RewardEpochs.EpochIndex(votingRoundId)
+	// the reward epoch started late, the voting round belongs to the previous one
+	for rewardEpochId > 0 && s.FirstVotingRound(rewardEpochId) > votingRoundId {
+		rewardEpochId--
+	}
+	return rewardEpochId
+}
+
+// RewardEpochAt returns the reward epoch of the voting round at the time
+func (s *Schedule) RewardEpochAt(t time.Time) int64 {
+	return s.RewardEpoch(s.VotingRound(t))
+}
+
+// RewardEpochStart returns the start time of the first voting round of the reward epoch
+func (s *Schedule) RewardEpochStart(rewardEpochId int64) time.Time {
+	return s.VotingRoundStart(s.FirstVotingRound(rewardEpochId))
+}
+
+// RewardEpochEnd returns the start time of the first voting round of the next reward
+// epoch, the expected end while the next signing policy is not initialized
+func (s *Schedule) RewardEpochEnd(rewardEpochId int64) time.Time {
+	return s.RewardEpochStart(rewardEpochId + 1)
+}
@@ -0,0 +1,44 @@
+package epoch
+
+import (
+	"flare-tlc/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	start := time.Unix(1000, 0)
+	// reward epoch 0 starts with voting round 10, reward epochs last 100 voting rounds of 90s
+	s := NewSchedule(utils.NewEpoch(start, 90*time.Second), utils.NewIntEpoch(10, 100))
+
+	require.Equal(t, int64(0), s.VotingRound(start.Add(89*time.Second)))
+	require.Equal(t, int64(2), s.VotingRound(start.Add(180*time.Second)))
+	require.Equal(t, start.Add(180*time.Second), s.VotingRoundStart(2))
+	require.Equal(t, start.Add(270*time.Second), s.VotingRoundEnd(2))
+
+	require.Equal(t, int64(0), s.RewardEpoch(5))
+	require.Equal(t, int64(0), s.RewardEpoch(109))
+	require.Equal(t, int64(1), s.RewardEpoch(110))
+	require.Equal(t, int64(3), s.RewardEpoch(350))
+	require.Equal(t, int64(210), s.FirstVotingRound(2))
+	require.Equal(t, int64(1), s.RewardEpochAt(start.Add(110*90*time.Second)))
+	require.Equal(t, start.Add(110*90*time.Second), s.RewardEpochStart(1))
+	require.Equal(t, start.Add(210*90*time.Second), s.RewardEpochEnd(1))
+}
+
+func TestScheduleSigningPolicyStart(t *testing.T) {
+	s := NewSchedule(utils.NewEpoch(time.Unix(0, 0), 90*time.Second), utils.NewIntEpoch(0, 100))
+
+	// the signing policy of reward epoch 2 was initialized late
+	s.SetFirstVotingRound(2, 215)
+	require.Equal(t, int64(200), s.ExpectedFirstVotingRound(2))
+	require.Equal(t, int64(215), s.FirstVotingRound(2))
+	require.Equal(t, int64(1), s.RewardEpoch(214))
+	require.Equal(t, int64(2), s.RewardEpoch(215))
+	require.Equal(t, int64(2), s.RewardEpoch(299))
+	require.Equal(t, int64(3), s.RewardEpoch(300))
+	require.Equal(t, time.Unix(215*90, 0), s.RewardEpochEnd(1))
+	require.Equal(t, time.Unix(300*90, 0), s.RewardEpochEnd(2))
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEpochChanges(t *testing.T) {
+	start := time.Unix(1000, 0)
+	epoch := NewEpoch(start, 10*time.Second)
+
+	changed, err := epoch.AddChange(5, start.Add(50*time.Second), 10*time.Second)
+	require.NoError(t, err)
+	require.False(t, changed, "schedule already matches")
+
+	// epoch 4 is 5s longer, epochs from 5 on last 20s
+	changed, err = epoch.AddChange(5, start.Add(55*time.Second), 20*time.Second)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	require.Equal(t, start.Add(30*time.Second), epoch.StartTime(3))
+	require.Equal(t, start.Add(55*time.Second), epoch.EndTime(4))
+	require.Equal(t, start.Add(75*time.Second), epoch.StartTime(6))
+	require.Equal(t, 10*time.Second, epoch.Duration(4))
+	require.Equal(t, 20*time.Second, epoch.Duration(5))
+
+	require.Equal(t, int64(3), epoch.EpochIndex(start.Add(39*time.Second)))
+	require.Equal(t, int64(4), epoch.EpochIndex(start.Add(52*time.Second)))
+	require.Equal(t, int64(5), epoch.EpochIndex(start.Add(55*time.Second)))
+	require.Equal(t, int64(6), epoch.EpochIndex(start.Add(80*time.Second)))
+
+	// a later change for the same epoch replaces the earlier one
+	changed, err = epoch.AddChange(5, start.Add(50*time.Second), 30*time.Second)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Len(t, epoch.Changes(), 1)
+	require.Equal(t, start.Add(80*time.Second), epoch.StartTime(6))
+
+	_, err = epoch.AddChange(3, start.Add(20*time.Second), 10*time.Second)
+	require.ErrorContains(t, err, "does not start after epoch 2")
+	_, err = epoch.AddChange(3, start.Add(30*time.Second), 0)
+	require.ErrorContains(t, err, "invalid period")
+	_, err = epoch.AddChange(0, start, 10*time.Second)
+	require.ErrorContains(t, err, "must be positive")
+}
@@ -0,0 +1,151 @@
+// Package failover provides an HTTP transport that spreads JSON-RPC requests over
+// multiple node endpoints, so that the ethclient keeps working if a node goes down.
+package failover
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// How long an endpoint is skipped after a failed request
+	DefaultCooldown = 30 * time.Second
+)
+
+type endpoint struct {
+	url       *url.URL
+	downUntil time.Time
+}
+
+// Transport sends each request to a healthy endpoint and retries it on the next
+// endpoint if the node is unreachable or responds with a server error. Endpoints are
+// used in priority order, or in round-robin order if enabled. Raw tx sends are only
+// retried if the node did not receive them, e.g. after a timeout the tx may have been
+// accepted and sending it to the next node could be reported as a nonce error.
+type Transport struct {
+	endpoints  []*endpoint
+	mu         sync.Mutex
+	next       int
+	roundRobin bool
+	cooldown   time.Duration
+	base       http.RoundTripper
+}
+
+func NewTransport(urls []string, roundRobin bool) (*Transport, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no rpc endpoints configured")
+	}
+	t := &Transport{
+		roundRobin: roundRobin,
+		cooldown:   DefaultCooldown,
+		base:       http.DefaultTransport,
+	}
+	for _, rawURL := range urls {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rpc url %s: %w", rawURL, err)
+		}
+		t.endpoints = append(t.endpoints, &endpoint{url: u})
+	}
+	return t, nil
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rawTx := isRawTxSend(body)
+	var lastErr error
+	for _, e := range t.candidates() {
+		attempt := req.Clone(req.Context())
+		attempt.URL = e.url
+		attempt.Host = e.url.Host
+		attempt.Body = io.NopCloser(bytes.NewReader(body))
+
+		resp, err := t.base.RoundTrip(attempt)
+		if err == nil && !isServerFailure(resp.StatusCode) {
+			return resp, nil
+		}
+		notReceived := isDialError(err) || (err == nil && resp.StatusCode == http.StatusTooManyRequests)
+		if err == nil {
+			err = fmt.Errorf("status %s", resp.Status)
+			resp.Body.Close()
+		}
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+		t.markDown(e)
+		lastErr = fmt.Errorf("%s: %w", e.url.Host, err)
+		if rawTx && !notReceived {
+			return nil, fmt.Errorf("raw tx send failed, not retried on other rpc endpoints: %w", lastErr)
+		}
+	}
+	return nil, fmt.Errorf("all rpc endpoints failed, last error: %w", lastErr)
+}
+
+// Healthy endpoints in the order they should be tried, followed by endpoints
+// in cooldown as a last resort.
+func (t *Transport) candidates() []*endpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(t.endpoints)
+	start := 0
+	if t.roundRobin {
+		start = t.next % n
+		t.next++
+	}
+
+	now := time.Now()
+	healthy := make([]*endpoint, 0, n)
+	var down []*endpoint
+	for i := 0; i < n; i++ {
+		e := t.endpoints[(start+i)%n]
+		if e.downUntil.After(now) {
+			down = append(down, e)
+		} else {
+			healthy = append(healthy, e)
+		}
+	}
+	return append(healthy, down...)
+}
+
+func (t *Transport) markDown(e *endpoint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e.downUntil = time.Now().Add(t.cooldown)
+}
+
+// Returns true if the request body is an eth_sendRawTransaction call
+func isRawTxSend(body []byte) bool {
+	var call struct {
+		Method string `json:"method"`
+	}
+	return json.Unmarshal(body, &call) == nil && call.Method == "eth_sendRawTransaction"
+}
+
+// Returns true if the connection to the node failed, the request was not sent
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func isServerFailure(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
@@ -0,0 +1,116 @@
+package failover
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportFailover(t *testing.T) {
+	var downHits, upHits int
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downHits++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upHits++
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer up.Close()
+
+	transport, err := NewTransport([]string{down.URL, up.URL}, false)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Post(down.URL, "application/json", strings.NewReader("request"))
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+		require.Equal(t, "request", string(body))
+	}
+
+	// the failed endpoint is skipped while in cooldown
+	require.Equal(t, 1, downHits)
+	require.Equal(t, 3, upHits)
+}
+
+func TestTransportRoundRobin(t *testing.T) {
+	hits := make([]int, 2)
+	var urls []string
+	for i := range hits {
+		i := i
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits[i]++
+		}))
+		defer server.Close()
+		urls = append(urls, server.URL)
+	}
+
+	transport, err := NewTransport(urls, true)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 4; i++ {
+		resp, err := client.Post(urls[0], "application/json", strings.NewReader("request"))
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	require.Equal(t, []int{2, 2}, hits)
+}
+
+func TestTransportAllEndpointsDown(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	transport, err := NewTransport([]string{down.URL}, false)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+
+	_, err = client.Post(down.URL, "application/json", strings.NewReader("request"))
+	require.Error(t, err)
+}
+
+func TestTransportRawTxSend(t *testing.T) {
+	var downHits, upHits int
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downHits++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upHits++
+	}))
+	defer up.Close()
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x01"]}`
+
+	// the node may have received the tx, it is not sent to the next one
+	transport, err := NewTransport([]string{down.URL, up.URL}, false)
+	require.NoError(t, err)
+	client := &http.Client{Transport: transport}
+	_, err = client.Post(down.URL, "application/json", strings.NewReader(request))
+	require.Error(t, err)
+	require.Equal(t, 1, downHits)
+	require.Zero(t, upHits)
+
+	// the tx was not sent to the unreachable node
+	transport, err = NewTransport([]string{closed.URL, up.URL}, false)
+	require.NoError(t, err)
+	client = &http.Client{Transport: transport}
+	resp, err := client.Post(closed.URL, "application/json", strings.NewReader(request))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, 1, upHits)
+}
@@ -0,0 +1,6 @@
+// Package metrics defines the namespace of the prometheus metrics of the client. It
+// has no dependencies, the client modules and the packages they use import it. The
+// metrics are registered with promauto when they are defined.
+package metrics
+
+const Namespace = "flare_tlc"
@@ -12,25 +12,37 @@ type DelayedQueueManager[T any] struct {
 	timeMap map[time.Time][]T
 
 	processor QueueProcessorFunc[T]
+	clock     Clock
+
+	done      chan struct{}
+	closeOnce sync.Once
 
 	sync.Mutex
 }
 
-func NewDelayedQueueManager[T any](processor QueueProcessorFunc[T]) *DelayedQueueManager[T] {
+func NewDelayedQueueManager[T any](processor QueueProcessorFunc[T], clock Clock) *DelayedQueueManager[T] {
 	return &DelayedQueueManager[T]{
 		timeMap:   make(map[time.Time][]T),
 		processor: processor,
+		clock:     clock,
+		done:      make(chan struct{}),
 	}
 }
 
 func (l *DelayedQueueManager[T]) Add(t time.Time, item T) {
-	if t.Before(time.Now()) {
+	if t.Before(l.clock.Now()) {
 		return
 	}
 
 	l.Lock()
 	defer l.Unlock()
 
+	select {
+	case <-l.done:
+		return
+	default:
+	}
+
 	if _, ok := l.timeMap[t]; !ok {
 		l.createTimer(t)
 	}
@@ -48,11 +60,44 @@ func (l *DelayedQueueManager[T]) Get(t time.Time) []T {
 
 func (l *DelayedQueueManager[T]) createTimer(t time.Time) {
 	go func() {
-		timer := time.NewTimer(time.Until(t))
-		<-timer.C
+		select {
+		case <-l.clock.After(l.clock.Until(t)):
+			break
+
+		case <-l.done:
+			return
+		}
 		items := l.Get(t)
 		if err := l.processor(items); err != nil {
 			logger.Error("DelayedQueueManager processor error: %s", err)
 		}
 	}()
 }
+
+// Items returns a copy of the items waiting to be processed, by processing time
+func (l *DelayedQueueManager[T]) Items() map[time.Time][]T {
+	l.Lock()
+	defer l.Unlock()
+
+	items := make(map[time.Time][]T, len(l.timeMap))
+	for t, timeItems := range l.timeMap {
+		items[t] = append([]T(nil), timeItems...)
+	}
+	return items
+}
+
+// Close stops all timers and returns the items that were not processed yet.
+// Items added after Close are ignored.
+func (l *DelayedQueueManager[T]) Close() []T {
+	l.Lock()
+	defer l.Unlock()
+
+	l.closeOnce.Do(func() { close(l.done) })
+
+	var items []T
+	for t, timeItems := range l.timeMap {
+		items = append(items, timeItems...)
+		delete(l.timeMap, t)
+	}
+	return items
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDelayedQueueManagerClose(t *testing.T) {
+	var processed atomic.Int32
+	manager := NewDelayedQueueManager[int](func(items []int) error {
+		processed.Add(int32(len(items)))
+		return nil
+	}, RealClock)
+
+	at := time.Now().Add(50 * time.Millisecond)
+	manager.Add(at, 1)
+	manager.Add(at, 2)
+
+	pending := manager.Close()
+	if len(pending) != 2 {
+		t.Fatalf("Expected 2 pending items, got %d", len(pending))
+	}
+
+	manager.Add(at, 3)
+	time.Sleep(100 * time.Millisecond)
+	if processed.Load() != 0 {
+		t.Fatalf("Expected no items to be processed after close, got %d", processed.Load())
+	}
+}
+
+func TestDelayedQueueManagerFakeClock(t *testing.T) {
+	clock := NewFakeClock(time.Unix(1000, 0))
+	processed := make(chan []int, 1)
+	manager := NewDelayedQueueManager[int](func(items []int) error {
+		processed <- items
+		return nil
+	}, clock)
+	defer manager.Close()
+
+	manager.Add(clock.Now().Add(-time.Second), 0)
+	manager.Add(clock.Now().Add(10*time.Second), 1)
+	manager.Add(clock.Now().Add(10*time.Second), 2)
+
+	clock.BlockUntilWaiters(1)
+	clock.Advance(5 * time.Second)
+	select {
+	case items := <-processed:
+		t.Fatalf("Expected no items to be processed before the time, got %v", items)
+	default:
+	}
+
+	clock.Advance(5 * time.Second)
+	select {
+	case items := <-processed:
+		if len(items) != 2 {
+			t.Fatalf("Expected 2 processed items, got %v", items)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected items to be processed")
+	}
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"flare-tlc/logger"
+	"sync"
+	"time"
+)
+
+// Scheduler calls hooks at fixed offsets within every epoch of an epoch sequence,
+// e.g. 1s after the voting epoch start or 30s before the reward epoch end.
+// Hooks run in their own goroutines, so a slow hook does not delay other hooks.
+type Scheduler struct {
+	clock Clock
+	hooks []*epochHook
+	wg    sync.WaitGroup
+}
+
+type epochHook struct {
+	name   string
+	epoch  *Epoch
+	offset time.Duration
+	run    func(epochIndex int64)
+
+	next int64 // index of the next epoch the hook is called for
+}
+
+func NewScheduler(clock Clock) *Scheduler {
+	return &Scheduler{clock: clock}
+}
+
+// AtOffset registers fn to be called for every epoch at offset from the epoch start.
+// A negative offset is relative to the epoch end, e.g. -30s calls fn 30s before the
+// epoch ends. fn receives the index of the epoch. Hooks must be registered before Run.
+func (s *Scheduler) AtOffset(name string, epoch *Epoch, offset time.Duration, fn func(epochIndex int64)) {
+	s.hooks = append(s.hooks, &epochHook{
+		name:   name,
+		epoch:  epoch,
+		offset: offset,
+		run:    fn,
+	})
+}
+
+// AtStart registers fn to be called at the start of every epoch
+func (s *Scheduler) AtStart(name string, epoch *Epoch, fn func(epochIndex int64)) {
+	s.AtOffset(name, epoch, 0, fn)
+}
+
+func (h *epochHook) fireTime(epochIndex int64) time.Time {
+	if h.offset < 0 {
+		return h.epoch.EndTime(epochIndex).Add(h.offset)
+	}
+	return h.epoch.StartTime(epochIndex).Add(h.offset)
+}
+
+// Index of the first epoch with the fire time not before t
+func (h *epochHook) firstEpochFrom(t time.Time) int64 {
+	offset := h.offset
+	if offset < 0 {
+		offset = -offset
+	}
+	epochIndex := h.epoch.EpochIndex(t.Add(-offset)) - 1
+	for h.fireTime(epochIndex).Before(t) {
+		epochIndex++
+	}
+	return epochIndex
+}
+
+// Run calls the hooks until ctx is done. Starting in the middle of an epoch, the hooks
+// with the fire time not yet passed are called for the current epoch. Returns ctx.Err()
+// after the running hooks complete.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if len(s.hooks) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	now := s.clock.Now()
+	for _, h := range s.hooks {
+		h.next = h.firstEpochFrom(now)
+	}
+
+	for {
+		next := s.nextFireTime()
+		select {
+		case <-s.clock.After(next.Sub(s.clock.Now())):
+			s.fireDue(s.clock.Now())
+
+		case <-ctx.Done():
+			s.wg.Wait()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Scheduler) nextFireTime() time.Time {
+	next := s.hooks[0].fireTime(s.hooks[0].next)
+	for _, h := range s.hooks[1:] {
+		if t := h.fireTime(h.next); t.Before(next) {
+			next = t
+		}
+	}
+	return next
+}
+
+// Calls the hooks with the fire time not after now. If several epochs of a
+// hook were missed, e.g. after a system suspend, only the latest one is called.
+func (s *Scheduler) fireDue(now time.Time) {
+	for _, h := range s.hooks {
+		if h.fireTime(h.next).After(now) {
+			continue
+		}
+		latest := h.firstEpochFrom(now.Add(time.Nanosecond)) - 1
+		if latest > h.next {
+			logger.Warn("Scheduler hook %s missed epochs %d to %d", h.name, h.next, latest-1)
+		}
+		h.next = latest + 1
+
+		s.wg.Add(1)
+		go func(h *epochHook, epochIndex int64) {
+			defer s.wg.Done()
+			h.run(epochIndex)
+		}(h, latest)
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type hookCall struct {
+	name  string
+	epoch int64
+}
+
+func TestScheduler(t *testing.T) {
+	start := time.Unix(1000, 0)
+	epoch := NewEpoch(start, 90*time.Second)
+	clock := NewFakeClock(start.Add(10 * time.Second))
+
+	calls := make(chan hookCall, 10)
+	s := NewScheduler(clock)
+	s.AtStart("start", epoch, func(e int64) { calls <- hookCall{"start", e} })
+	s.AtOffset("reveal", epoch, 45*time.Second, func(e int64) { calls <- hookCall{"reveal", e} })
+	s.AtOffset("before end", epoch, -30*time.Second, func(e int64) { calls <- hookCall{"before end", e} })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	// the start of epoch 0 has passed, the other hooks are called for epoch 0
+	clock.BlockUntilWaiters(1)
+	clock.Advance(35 * time.Second)
+	require.Equal(t, hookCall{"reveal", 0}, <-calls)
+	clock.BlockUntilWaiters(1)
+	clock.Advance(15 * time.Second)
+	require.Equal(t, hookCall{"before end", 0}, <-calls)
+	clock.BlockUntilWaiters(1)
+	clock.Advance(30 * time.Second)
+	require.Equal(t, hookCall{"start", 1}, <-calls)
+
+	// missed epochs are skipped
+	clock.BlockUntilWaiters(1)
+	clock.Advance(10 * 90 * time.Second)
+	received := map[hookCall]bool{}
+	for i := 0; i < 3; i++ {
+		received[<-calls] = true
+	}
+	require.Equal(t, map[hookCall]bool{{"start", 11}: true, {"reveal", 10}: true, {"before end", 10}: true}, received)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+	require.Empty(t, calls)
+}
@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/pkg/errors"
+)
+
+// AWS Secrets Manager secret with a JSON object value
+type awsSecretsManagerStore struct {
+	client   *secretsmanager.Client
+	secretID string
+}
+
+// NewAWSSecretsManagerStore returns a store reading the secret with the name or ARN
+// secretID. Credentials are read from the default AWS credential chain, region is optional.
+func NewAWSSecretsManagerStore(ctx context.Context, secretID string, region string) (Store, error) {
+	if len(secretID) == 0 {
+		return nil, errors.New("secret path must be set")
+	}
+	var opts []func(*awsconfig.LoadOptions) error
+	if len(region) > 0 {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading AWS config")
+	}
+	return &awsSecretsManagerStore{client: secretsmanager.NewFromConfig(cfg), secretID: secretID}, nil
+}
+
+func (s *awsSecretsManagerStore) Fetch(ctx context.Context) (map[string]string, error) {
+	out, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(s.secretID)})
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading AWS secret")
+	}
+	if out.SecretString == nil {
+		return nil, errors.Errorf("AWS secret %s has no string value", s.secretID)
+	}
+	var data map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(*out.SecretString), &data); err != nil {
+		return nil, errors.Wrapf(err, "AWS secret %s is not a JSON object", s.secretID)
+	}
+	return stringValues(data)
+}
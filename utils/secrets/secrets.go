@@ -0,0 +1,10 @@
+package secrets
+
+import "context"
+
+// Store is a secrets backend holding a set of named secrets, e.g. a Vault KV
+// secret or an AWS Secrets Manager secret with a JSON object value
+type Store interface {
+	// Returns the secrets by name
+	Fetch(ctx context.Context) (map[string]string, error)
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const vaultRequestTimeout = 10 * time.Second
+
+// Secret of a Vault KV secrets engine, read with the HTTP API
+type vaultStore struct {
+	address string
+	token   string
+	path    string
+	client  *http.Client
+}
+
+// NewVaultStore returns a store reading the KV secret at path, e.g.
+// secret/data/flare-system-client for the KV version 2 engine mounted at secret
+func NewVaultStore(address, token, path string) (Store, error) {
+	if len(address) == 0 || len(token) == 0 || len(path) == 0 {
+		return nil, errors.New("vault address, token and secret path must be set")
+	}
+	return &vaultStore{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		path:    strings.Trim(path, "/"),
+		client:  &http.Client{Timeout: vaultRequestTimeout},
+	}, nil
+}
+
+type vaultResponse struct {
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+func (s *vaultStore) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s", s.address, s.path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading vault secret")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("vault returned http status %s for secret %s", resp.Status, s.path)
+	}
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.Wrap(err, "error decoding vault response")
+	}
+	data := body.Data
+	// KV version 2 nests the secret in data.data, next to data.metadata
+	if nested, ok := data["data"]; ok {
+		if _, ok := data["metadata"]; ok {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return nil, errors.Wrap(err, "error decoding vault secret")
+			}
+		}
+	}
+	return stringValues(data)
+}
+
+// Secrets must be strings, other JSON values are rejected
+func stringValues(data map[string]json.RawMessage) (map[string]string, error) {
+	result := make(map[string]string, len(data))
+	for name, raw := range data {
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, errors.Errorf("secret %s is not a string", name)
+		}
+		result[name] = value
+	}
+	return result, nil
+}
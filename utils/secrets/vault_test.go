@@ -0,0 +1,57 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/client":
+			_, _ = w.Write([]byte(`{"data": {"data": {"DB_PASSWORD": "pass"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/client":
+			_, _ = w.Write([]byte(`{"data": {"DB_PASSWORD": "pass"}}`))
+		case "/v1/kv/invalid":
+			_, _ = w.Write([]byte(`{"data": {"DB_PORT": 3306}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		err   string
+	}{
+		{name: "kv v2", path: "secret/data/client", token: "token"},
+		{name: "kv v1", path: "/kv/client", token: "token"},
+		{name: "not a string", path: "kv/invalid", token: "token", err: "secret DB_PORT is not a string"},
+		{name: "missing", path: "kv/missing", token: "token", err: "404"},
+		{name: "wrong token", path: "kv/client", token: "other", err: "403"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, err := NewVaultStore(server.URL+"/", test.token, test.path)
+			require.NoError(t, err)
+
+			values, err := store.Fetch(context.Background())
+			if test.err != "" {
+				require.ErrorContains(t, err, test.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{"DB_PASSWORD": "pass"}, values)
+		})
+	}
+}
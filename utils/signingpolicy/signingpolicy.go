@@ -0,0 +1,142 @@
+// Package signingpolicy encodes, decodes and hashes signing policies in the layout of
+// the Relay contract: number of voters (2 bytes), reward epoch id (3 bytes), start
+// voting round id (4 bytes), threshold (2 bytes), random seed (32 bytes) and for each
+// voter its address (20 bytes) and weight (2 bytes). The encoded policy is the
+// SigningPolicyBytes of the SigningPolicyInitialized event and the prefix of the
+// relay calldata.
+package signingpolicy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flare-tlc/utils/contracts/relay"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+const (
+	HeaderLength = 43 // number of voters (2), reward epoch id (3), start voting round id (4), threshold (2), seed (32)
+	VoterLength  = 22 // address (20), weight (2)
+
+	MaxRewardEpochId = 1<<24 - 1 // encoded in 3 bytes
+	MaxVoters        = 1<<16 - 1
+)
+
+type SigningPolicy struct {
+	RewardEpochId      uint32
+	StartVotingRoundId uint32
+	Threshold          uint16
+	Seed               *big.Int
+	Voters             []common.Address
+	Weights            []uint16
+}
+
+// FromEvent returns the signing policy of the SigningPolicyInitialized event fields
+func FromEvent(event *relay.RelaySigningPolicyInitialized) (*SigningPolicy, error) {
+	if event.RewardEpochId == nil || event.RewardEpochId.Sign() < 0 || event.RewardEpochId.Cmp(big.NewInt(MaxRewardEpochId)) > 0 {
+		return nil, errors.Errorf("invalid reward epoch id %v", event.RewardEpochId)
+	}
+	return &SigningPolicy{
+		RewardEpochId:      uint32(event.RewardEpochId.Uint64()),
+		StartVotingRoundId: event.StartVotingRoundId,
+		Threshold:          event.Threshold,
+		Seed:               event.Seed,
+		Voters:             event.Voters,
+		Weights:            event.Weights,
+	}, nil
+}
+
+// Encode encodes the signing policy in the layout of the Relay contract
+func Encode(policy *SigningPolicy) ([]byte, error) {
+	if policy.RewardEpochId > MaxRewardEpochId {
+		return nil, errors.Errorf("invalid reward epoch id %d", policy.RewardEpochId)
+	}
+	if len(policy.Voters) != len(policy.Weights) {
+		return nil, errors.Errorf("signing policy has %d voters and %d weights", len(policy.Voters), len(policy.Weights))
+	}
+	if len(policy.Voters) > MaxVoters {
+		return nil, errors.Errorf("too many voters %d", len(policy.Voters))
+	}
+	if policy.Seed == nil || policy.Seed.Sign() < 0 || policy.Seed.BitLen() > 256 {
+		return nil, errors.Errorf("invalid seed %v", policy.Seed)
+	}
+
+	buffer := bytes.NewBuffer(make([]byte, 0, HeaderLength+VoterLength*len(policy.Voters)))
+	buffer.Write(binary.BigEndian.AppendUint16(nil, uint16(len(policy.Voters))))
+	buffer.Write(binary.BigEndian.AppendUint32(nil, policy.RewardEpochId)[1:])
+	buffer.Write(binary.BigEndian.AppendUint32(nil, policy.StartVotingRoundId))
+	buffer.Write(binary.BigEndian.AppendUint16(nil, policy.Threshold))
+	buffer.Write(common.LeftPadBytes(policy.Seed.Bytes(), 32))
+	for i, voter := range policy.Voters {
+		buffer.Write(voter.Bytes())
+		buffer.Write(binary.BigEndian.AppendUint16(nil, policy.Weights[i]))
+	}
+	return buffer.Bytes(), nil
+}
+
+// EncodeEvent encodes the signing policy of the SigningPolicyInitialized event fields
+func EncodeEvent(event *relay.RelaySigningPolicyInitialized) ([]byte, error) {
+	policy, err := FromEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	return Encode(policy)
+}
+
+// Decode decodes the encoded signing policy, data must not contain other bytes
+func Decode(data []byte) (*SigningPolicy, error) {
+	policy, n, err := DecodePrefix(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(data) {
+		return nil, errors.Errorf("%d bytes after the signing policy of %d voters", len(data)-n, len(policy.Voters))
+	}
+	return policy, nil
+}
+
+// DecodePrefix decodes the signing policy at the start of data, e.g. of the relay
+// calldata without the selector. Returns the policy and its encoded length.
+func DecodePrefix(data []byte) (*SigningPolicy, int, error) {
+	if len(data) < HeaderLength {
+		return nil, 0, errors.Errorf("%d bytes, header is %d bytes", len(data), HeaderLength)
+	}
+	size := int(binary.BigEndian.Uint16(data[0:2]))
+	length := HeaderLength + size*VoterLength
+	if len(data) < length {
+		return nil, 0, errors.Errorf("%d bytes, %d voters need %d bytes", len(data), size, length)
+	}
+
+	policy := &SigningPolicy{
+		RewardEpochId:      uint32(data[2])<<16 | uint32(data[3])<<8 | uint32(data[4]),
+		StartVotingRoundId: binary.BigEndian.Uint32(data[5:9]),
+		Threshold:          binary.BigEndian.Uint16(data[9:11]),
+		Seed:               new(big.Int).SetBytes(data[11:HeaderLength]),
+		Voters:             make([]common.Address, size),
+		Weights:            make([]uint16, size),
+	}
+	for i := 0; i < size; i++ {
+		offset := HeaderLength + i*VoterLength
+		policy.Voters[i] = common.BytesToAddress(data[offset : offset+20])
+		policy.Weights[i] = binary.BigEndian.Uint16(data[offset+20 : offset+VoterLength])
+	}
+	return policy, length, nil
+}
+
+// Hash returns the hash of the encoded signing policy as computed by the Relay
+// contract: the data is zero padded to 32 byte words and hashed word by word.
+func Hash(data []byte) common.Hash {
+	// encoded policies have at least 43 bytes, shorter input is invalid but must not panic
+	padded := make([]byte, max(64, (len(data)+31)/32*32))
+	copy(padded, data)
+	data = padded
+
+	hash := crypto.Keccak256(data[:32], data[32:64])
+	for i := 2; i < len(data)/32; i++ {
+		hash = crypto.Keccak256(hash, data[i*32:(i+1)*32])
+	}
+	return common.BytesToHash(hash)
+}
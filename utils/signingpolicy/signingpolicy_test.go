@@ -0,0 +1,129 @@
+package signingpolicy
+
+import (
+	"encoding/hex"
+	"flag"
+	"flare-tlc/utils/contracts/relay"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// go test ./utils/signingpolicy -update rewrites the golden files
+var update = flag.Bool("update", false, "update the golden files")
+
+var testPolicies = map[string]*SigningPolicy{
+	"two_voters": {
+		RewardEpochId:      0x030201,
+		StartVotingRoundId: 1000,
+		Threshold:          500,
+		Seed:               big.NewInt(1),
+		Voters:             []common.Address{common.HexToAddress("0x123456"), common.HexToAddress("0xabcdef")},
+		Weights:            []uint16{600, 400},
+	},
+	"no_voters": {
+		RewardEpochId:      1,
+		StartVotingRoundId: 5,
+		Threshold:          0,
+		Seed:               big.NewInt(0),
+		Voters:             []common.Address{},
+		Weights:            []uint16{},
+	},
+	"max_values": {
+		RewardEpochId:      MaxRewardEpochId,
+		StartVotingRoundId: 1<<32 - 1,
+		Threshold:          1<<16 - 1,
+		Seed:               new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+		Voters:             []common.Address{common.HexToAddress("0xffffffffffffffffffffffffffffffffffffffff")},
+		Weights:            []uint16{1<<16 - 1},
+	},
+}
+
+// Golden files contain the hex encoded policy and its hash on separate lines
+func TestGolden(t *testing.T) {
+	for name, policy := range testPolicies {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := Encode(policy)
+			require.NoError(t, err)
+			hash := Hash(encoded)
+
+			path := filepath.Join("testdata", name+".golden")
+			if *update {
+				content := hex.EncodeToString(encoded) + "\n" + hash.Hex() + "\n"
+				require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+			}
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			require.Len(t, lines, 2)
+			require.Equal(t, lines[0], hex.EncodeToString(encoded))
+			require.Equal(t, lines[1], hash.Hex())
+
+			decoded, err := Decode(encoded)
+			require.NoError(t, err)
+			require.Zero(t, policy.Seed.Cmp(decoded.Seed))
+			decoded.Seed = policy.Seed
+			require.Equal(t, policy, decoded)
+		})
+	}
+}
+
+func TestEncodeLayout(t *testing.T) {
+	encoded, err := Encode(testPolicies["two_voters"])
+	require.NoError(t, err)
+
+	expected := "0002" + "030201" + "000003e8" + "01f4" +
+		"0000000000000000000000000000000000000000000000000000000000000001" +
+		"0000000000000000000000000000000000123456" + "0258" +
+		"0000000000000000000000000000000000abcdef" + "0190"
+	require.Equal(t, expected, hex.EncodeToString(encoded))
+}
+
+func TestEncodeErrors(t *testing.T) {
+	policy := *testPolicies["two_voters"]
+	policy.Weights = policy.Weights[:1]
+	_, err := Encode(&policy)
+	require.ErrorContains(t, err, "2 voters and 1 weights")
+
+	policy = *testPolicies["two_voters"]
+	policy.RewardEpochId = MaxRewardEpochId + 1
+	_, err = Encode(&policy)
+	require.ErrorContains(t, err, "invalid reward epoch id")
+
+	policy = *testPolicies["two_voters"]
+	policy.Seed = nil
+	_, err = Encode(&policy)
+	require.ErrorContains(t, err, "invalid seed")
+
+	_, err = EncodeEvent(&relay.RelaySigningPolicyInitialized{RewardEpochId: big.NewInt(-1)})
+	require.ErrorContains(t, err, "invalid reward epoch id")
+}
+
+func TestDecodeErrors(t *testing.T) {
+	encoded, err := Encode(testPolicies["two_voters"])
+	require.NoError(t, err)
+
+	_, err = Decode(encoded[:HeaderLength-1])
+	require.ErrorContains(t, err, "header is 43 bytes")
+	_, err = Decode(encoded[:len(encoded)-1])
+	require.ErrorContains(t, err, "2 voters need 87 bytes")
+	_, err = Decode(append(encoded, 0))
+	require.ErrorContains(t, err, "1 bytes after the signing policy")
+
+	// the prefix of longer data, e.g. of the relay calldata
+	policy, n, err := DecodePrefix(append(encoded, 1, 2, 3))
+	require.NoError(t, err)
+	require.Equal(t, len(encoded), n)
+	require.Equal(t, testPolicies["two_voters"], policy)
+}
+
+func TestHashDoesNotModifyInput(t *testing.T) {
+	data := make([]byte, 10, 64)
+	Hash(data)
+	require.Equal(t, make([]byte, 64), data[:64])
+}
@@ -0,0 +1,234 @@
+// Package sortition implements the verifiable random function of the Fast Updates
+// sortition on the alt_bn128 curve. A provider is eligible to submit updates in a
+// block for each replicate (up to its sortition weight) whose VRF score is at most
+// the score cutoff of the block.
+package sortition
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/pkg/errors"
+)
+
+var (
+	curveB       = big.NewInt(3)
+	sqrtExponent = new(big.Int).Rsh(new(big.Int).Add(bn256.P, big.NewInt(1)), 2) // (P + 1) / 4, P = 3 mod 4
+)
+
+// Sortition key: secret scalar x and public key x·G
+type Key struct {
+	Secret *big.Int
+	Public *bn256.G1
+}
+
+// Verifiable randomness of a replicate in a block, Gamma = x·H(seed, block, replicate)
+// with a proof (C, S) of the equality of the discrete logarithms of Gamma and the
+// public key
+type Proof struct {
+	Gamma *bn256.G1
+	C     *big.Int
+	S     *big.Int
+}
+
+func NewKey(secret *big.Int) (*Key, error) {
+	if secret.Sign() <= 0 || secret.Cmp(bn256.Order) >= 0 {
+		return nil, errors.New("sortition key must be in the range [1, curve order)")
+	}
+	return &Key{
+		Secret: new(big.Int).Set(secret),
+		Public: new(bn256.G1).ScalarBaseMult(secret),
+	}, nil
+}
+
+// KeyFromHex parses the hex encoded secret of the key, with or without the 0x prefix
+func KeyFromHex(secret string) (*Key, error) {
+	bytes, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(secret), "0x"))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid sortition key")
+	}
+	return NewKey(new(big.Int).SetBytes(bytes))
+}
+
+func GenerateKey(r io.Reader) (*Key, error) {
+	secret, err := randomScalar(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(secret)
+}
+
+// SecretHex returns the 0x prefixed 32 byte hex encoding of the secret
+func (k *Key) SecretHex() string {
+	return hexutil.Encode(common.LeftPadBytes(k.Secret.Bytes(), 32))
+}
+
+// PublicXY returns the coordinates of the public key
+func (k *Key) PublicXY() (*big.Int, *big.Int) {
+	return PointXY(k.Public)
+}
+
+// VerifiableRandomness computes the randomness of the replicate in the block with
+// the proof that it was computed with the key
+func VerifiableRandomness(key *Key, seed, blockNumber, replicate *big.Int) (*Proof, error) {
+	u, err := hashToG1(sortitionMessage(seed, blockNumber, replicate))
+	if err != nil {
+		return nil, err
+	}
+	gamma := new(bn256.G1).ScalarMult(u, key.Secret)
+
+	k, err := randomScalar(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	c := challenge(u, key.Public, gamma, new(bn256.G1).ScalarBaseMult(k), new(bn256.G1).ScalarMult(u, k))
+
+	// s = k - c·x mod n
+	s := new(big.Int).Mul(c, key.Secret)
+	s.Sub(k, s).Mod(s, bn256.Order)
+	return &Proof{Gamma: gamma, C: c, S: s}, nil
+}
+
+// VerifyProof checks that the randomness of the proof was computed with the key of
+// the public key for the replicate in the block
+func VerifyProof(public *bn256.G1, seed, blockNumber, replicate *big.Int, proof *Proof) bool {
+	u, err := hashToG1(sortitionMessage(seed, blockNumber, replicate))
+	if err != nil {
+		return false
+	}
+	// k·G = s·G + c·pk, k·u = s·u + c·gamma
+	kG := new(bn256.G1).Add(new(bn256.G1).ScalarBaseMult(proof.S), new(bn256.G1).ScalarMult(public, proof.C))
+	ku := new(bn256.G1).Add(new(bn256.G1).ScalarMult(u, proof.S), new(bn256.G1).ScalarMult(proof.Gamma, proof.C))
+	return challenge(u, public, proof.Gamma, kG, ku).Cmp(proof.C) == 0
+}
+
+// Score of the proof, the replicate is eligible if the score is at most the cutoff
+func (p *Proof) Score() *big.Int {
+	x, _ := PointXY(p.Gamma)
+	return x
+}
+
+// Eligible returns the proofs of the replicates below weight that are eligible
+// to submit updates in the block
+func Eligible(key *Key, seed, blockNumber *big.Int, weight uint64, cutoff *big.Int) ([]*Proof, []uint64, error) {
+	var proofs []*Proof
+	var replicates []uint64
+	for replicate := uint64(0); replicate < weight; replicate++ {
+		proof, err := VerifiableRandomness(key, seed, blockNumber, new(big.Int).SetUint64(replicate))
+		if err != nil {
+			return nil, nil, err
+		}
+		if proof.Score().Cmp(cutoff) <= 0 {
+			proofs = append(proofs, proof)
+			replicates = append(replicates, replicate)
+		}
+	}
+	return proofs, replicates, nil
+}
+
+// PointXY returns the affine coordinates of the point, zero for the point at infinity
+func PointXY(p *bn256.G1) (*big.Int, *big.Int) {
+	m := p.Marshal()
+	return new(big.Int).SetBytes(m[:32]), new(big.Int).SetBytes(m[32:])
+}
+
+// seed || blockNumber || replicate, each as a 32 byte word
+func sortitionMessage(seed, blockNumber, replicate *big.Int) []byte {
+	return crypto.Keccak256(
+		common.LeftPadBytes(seed.Bytes(), 32),
+		common.LeftPadBytes(blockNumber.Bytes(), 32),
+		common.LeftPadBytes(replicate.Bytes(), 32),
+	)
+}
+
+// Try-and-increment hash to the curve y^2 = x^3 + 3, starting with x = H(message) mod P
+func hashToG1(message []byte) (*bn256.G1, error) {
+	x := new(big.Int).SetBytes(crypto.Keccak256(message))
+	x.Mod(x, bn256.P)
+	for {
+		y2 := new(big.Int).Exp(x, big.NewInt(3), bn256.P)
+		y2.Add(y2, curveB).Mod(y2, bn256.P)
+		y := new(big.Int).Exp(y2, sqrtExponent, bn256.P)
+		if new(big.Int).Exp(y, big.NewInt(2), bn256.P).Cmp(y2) == 0 {
+			point := new(bn256.G1)
+			if _, err := point.Unmarshal(append(common.LeftPadBytes(x.Bytes(), 32), common.LeftPadBytes(y.Bytes(), 32)...)); err != nil {
+				return nil, errors.Wrap(err, "error hashing to curve")
+			}
+			return point, nil
+		}
+		x.Add(x, big.NewInt(1)).Mod(x, bn256.P)
+	}
+}
+
+// keccak256(G || u || pk || gamma || kG || ku) mod n, with the points encoded as x || y
+func challenge(points ...*bn256.G1) *big.Int {
+	g := new(bn256.G1).ScalarBaseMult(big.NewInt(1))
+	data := g.Marshal()
+	for _, p := range points {
+		data = append(data, p.Marshal()...)
+	}
+	c := new(big.Int).SetBytes(crypto.Keccak256(data))
+	return c.Mod(c, bn256.Order)
+}
+
+func randomScalar(r io.Reader) (*big.Int, error) {
+	for {
+		k, err := rand.Int(r, bn256.Order)
+		if err != nil {
+			return nil, errors.Wrap(err, "error generating random scalar")
+		}
+		if k.Sign() > 0 {
+			return k, nil
+		}
+	}
+}
+
+// PublicKeyParts returns the public key as the two parts registered in the
+// EntityManager, the x and y coordinates as 32 byte words
+func (k *Key) PublicKeyParts() ([32]byte, [32]byte) {
+	x, y := k.PublicXY()
+	return [32]byte(common.LeftPadBytes(x.Bytes(), 32)), [32]byte(common.LeftPadBytes(y.Bytes(), 32))
+}
+
+// ProofOfPossession returns the verification data of the public key registration
+// of the voter, a Schnorr signature (e, s) of the voter address proving the
+// knowledge of the secret, encoded as two 32 byte words
+func ProofOfPossession(key *Key, voter common.Address) ([]byte, error) {
+	k, err := randomScalar(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	e := possessionChallenge(key.Public, new(bn256.G1).ScalarBaseMult(k), voter)
+
+	// s = k - e·x mod n
+	s := new(big.Int).Mul(e, key.Secret)
+	s.Sub(k, s).Mod(s, bn256.Order)
+	return append(common.LeftPadBytes(e.Bytes(), 32), common.LeftPadBytes(s.Bytes(), 32)...), nil
+}
+
+// VerifyProofOfPossession checks the verification data of the public key
+// registration of the voter
+func VerifyProofOfPossession(public *bn256.G1, voter common.Address, data []byte) bool {
+	if len(data) != 64 {
+		return false
+	}
+	e := new(big.Int).SetBytes(data[:32])
+	s := new(big.Int).SetBytes(data[32:])
+	// k·G = s·G + e·pk
+	kG := new(bn256.G1).Add(new(bn256.G1).ScalarBaseMult(s), new(bn256.G1).ScalarMult(public, e))
+	return possessionChallenge(public, kG, voter).Cmp(e) == 0
+}
+
+// keccak256(G || pk || kG || voter) mod n
+func possessionChallenge(public *bn256.G1, kG *bn256.G1, voter common.Address) *big.Int {
+	g := new(bn256.G1).ScalarBaseMult(big.NewInt(1))
+	e := new(big.Int).SetBytes(crypto.Keccak256(g.Marshal(), public.Marshal(), kG.Marshal(), voter.Bytes()))
+	return e.Mod(e, bn256.Order)
+}
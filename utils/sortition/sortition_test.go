@@ -0,0 +1,70 @@
+package sortition
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	bn256 "github.com/ethereum/go-ethereum/crypto/bn256/cloudflare"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifiableRandomness(t *testing.T) {
+	key, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	seed, block, replicate := big.NewInt(1234), big.NewInt(100), big.NewInt(2)
+
+	proof, err := VerifiableRandomness(key, seed, block, replicate)
+	require.NoError(t, err)
+	require.True(t, VerifyProof(key.Public, seed, block, replicate, proof))
+
+	// the randomness does not depend on the proof nonce
+	other, err := VerifiableRandomness(key, seed, block, replicate)
+	require.NoError(t, err)
+	require.Equal(t, proof.Score(), other.Score())
+
+	require.False(t, VerifyProof(key.Public, seed, big.NewInt(101), replicate, proof))
+	otherKey, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.False(t, VerifyProof(otherKey.Public, seed, block, replicate, proof))
+}
+
+func TestEligible(t *testing.T) {
+	key, err := KeyFromHex("0x1f")
+	require.NoError(t, err)
+	require.Equal(t, "0x000000000000000000000000000000000000000000000000000000000000001f", key.SecretHex())
+
+	proofs, replicates, err := Eligible(key, big.NewInt(1), big.NewInt(10), 8, bn256.P)
+	require.NoError(t, err)
+	require.Len(t, proofs, 8)
+	require.Equal(t, []uint64{0, 1, 2, 3, 4, 5, 6, 7}, replicates)
+
+	proofs, _, err = Eligible(key, big.NewInt(1), big.NewInt(10), 8, big.NewInt(0))
+	require.NoError(t, err)
+	require.Empty(t, proofs)
+
+	_, err = KeyFromHex("0x00")
+	require.Error(t, err)
+}
+
+func TestProofOfPossession(t *testing.T) {
+	key, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	voter := common.HexToAddress("0xd7de703d9bbc4602242d0f3149e5ffcd30eb3adf")
+
+	data, err := ProofOfPossession(key, voter)
+	require.NoError(t, err)
+	require.Len(t, data, 64)
+	require.True(t, VerifyProofOfPossession(key.Public, voter, data))
+
+	require.False(t, VerifyProofOfPossession(key.Public, common.HexToAddress("0x01"), data))
+	otherKey, err := GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.False(t, VerifyProofOfPossession(otherKey.Public, voter, data))
+
+	part1, part2 := key.PublicKeyParts()
+	x, y := key.PublicXY()
+	require.Equal(t, x, new(big.Int).SetBytes(part1[:]))
+	require.Equal(t, y, new(big.Int).SetBytes(part2[:]))
+}
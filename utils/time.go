@@ -5,24 +5,6 @@ import (
 	"time"
 )
 
-type TimeProvider interface {
-	Now() time.Time
-}
-
-type RealTimeProvider struct{}
-
-func (RealTimeProvider) Now() time.Time {
-	return time.Now()
-}
-
-type FixedTimeProvider struct {
-	Time time.Time
-}
-
-func (f FixedTimeProvider) Now() time.Time {
-	return f.Time
-}
-
 func NewRandomizedTicker(interval time.Duration, randomDelta time.Duration) <-chan time.Time {
 	deltaIntervalMs := int(randomDelta.Milliseconds())
 	ch := make(chan time.Time)
@@ -36,6 +18,11 @@ func NewRandomizedTicker(interval time.Duration, randomDelta time.Duration) <-ch
 	return ch
 }
 
+// RandomDuration returns a random duration in [0, max) with millisecond precision
+func RandomDuration(max time.Duration) time.Duration {
+	return randomDuration(int(max.Milliseconds()))
+}
+
 func randomDuration(deltaMs int) time.Duration {
 	delta := int64(0)
 	if deltaMs > 0 {
@@ -43,42 +30,3 @@ func randomDuration(deltaMs int) time.Duration {
 	}
 	return time.Duration(delta * int64(time.Millisecond))
 }
-
-type EpochTicker struct {
-	Epoch        *Epoch
-	timeProvider TimeProvider
-
-	// C is the channel on which the epoch index is sent
-	C <-chan int64
-}
-
-// NewEpochTicker creates a ticker that sends the epoch index on the channel C
-// at the start of the epoch
-func NewEpochTicker(epoch *Epoch) *EpochTicker {
-	c := make(chan int64)
-	ticker := &EpochTicker{
-		Epoch:        epoch,
-		timeProvider: RealTimeProvider{},
-		C:            c,
-	}
-	ticker.start(c)
-	return ticker
-}
-
-func (t *EpochTicker) start(c chan int64) {
-	go func() {
-		now := t.timeProvider.Now()
-		currentEpoch := t.Epoch.EpochIndex(now)
-
-		epoch := currentEpoch + 1
-		epochStart := t.Epoch.StartTime(epoch)
-
-		for {
-			<-time.NewTimer(epochStart.Sub(now)).C
-			c <- epoch
-			epoch += 1
-			epochStart = t.Epoch.StartTime(epoch)
-			now = t.timeProvider.Now()
-		}
-	}()
-}
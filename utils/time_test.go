@@ -0,0 +1,19 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomDuration(t *testing.T) {
+	require.Zero(t, RandomDuration(0))
+	require.Zero(t, RandomDuration(time.Microsecond))
+
+	for i := 0; i < 100; i++ {
+		d := RandomDuration(10 * time.Millisecond)
+		require.GreaterOrEqual(t, d, time.Duration(0))
+		require.Less(t, d, 10*time.Millisecond)
+	}
+}